@@ -47,6 +47,10 @@ type App struct {
 	fileHandler     *handlers.FileHandler
 	imageHandler    *handlers.ImageHandler
 	archiveHandler  *handlers.ArchiveHandler
+	storageHandler  *handlers.StorageHandler
+
+	// 全量重建索引的上下文，应用关闭时取消，避免退出时仍在调用嵌入接口
+	cancelReindex context.CancelFunc
 
 	pendingExternalOpensMu sync.Mutex
 	pendingExternalOpens   []string
@@ -94,15 +98,19 @@ func NewApp() *App {
 
 	// 初始化 Handlers (services are injected but not stored in App)
 	app.documentHandler = handlers.NewDocumentHandler(
-		baseHandler, docRepo, docStorage, searchService, ragService,
+		baseHandler, docRepo, docStorage, searchService, ragService, settingsService,
 	)
-	app.searchHandler = handlers.NewSearchHandler(baseHandler, docRepo, searchService, ragService)
+	app.searchHandler = handlers.NewSearchHandler(baseHandler, docRepo, searchService, ragService, settingsService)
 	app.ragHandler = handlers.NewRAGHandler(baseHandler, docRepo, ragService)
+	reindexCtx, cancelReindex := context.WithCancel(context.Background())
+	app.cancelReindex = cancelReindex
+	app.ragHandler.SetReindexContext(reindexCtx)
 	app.settingsHandler = handlers.NewSettingsHandler(baseHandler, settingsService)
 	app.tagHandler = handlers.NewTagHandler(baseHandler, tagService)
-	app.fileHandler = handlers.NewFileHandler(baseHandler, markdownService)
+	app.fileHandler = handlers.NewFileHandler(baseHandler, markdownService, docRepo, docStorage)
 	app.imageHandler = handlers.NewImageHandler(baseHandler)
-	app.archiveHandler = handlers.NewArchiveHandler(baseHandler)
+	app.archiveHandler = handlers.NewArchiveHandler(baseHandler, docRepo, docStorage)
+	app.storageHandler = handlers.NewStorageHandler(baseHandler)
 
 	return app
 }
@@ -140,10 +148,22 @@ func (a *App) startup(ctx context.Context) {
 
 	// 异步构建搜索索引
 	a.searchHandler.BuildSearchIndex()
+
+	// 异步校验文档能否解析，尽早提示损坏的文档
+	a.documentHandler.StartValidation()
+
+	// 清理上次遗留的过期临时文件
+	go a.cleanupTempFiles()
+
+	// 后台定期重建过期文档索引（配置中启用时生效）
+	a.ragHandler.StartAutoReindex()
 }
 
 // shutdown 应用关闭时调用
 func (a *App) shutdown(ctx context.Context) {
+	if a.cancelReindex != nil {
+		a.cancelReindex()
+	}
 	if a.watcherService != nil {
 		a.watcherService.Stop()
 	}
@@ -332,14 +352,55 @@ func (a *App) CreateDocument(title string) (document.Meta, error) {
 	return a.documentHandler.CreateDocument(title)
 }
 
+func (a *App) CreateDocumentWithTags(title string, tags []string) (document.Meta, error) {
+	return a.documentHandler.CreateDocumentWithTags(title, tags)
+}
+
 func (a *App) DeleteDocument(id string) error {
 	return a.documentHandler.DeleteDocument(id, a.cleanupUnusedImages)
 }
 
+func (a *App) GetTrash() ([]document.TrashEntry, error) {
+	return a.documentHandler.GetTrash()
+}
+
+func (a *App) RestoreDocument(id string) (document.Meta, error) {
+	return a.documentHandler.RestoreDocument(id)
+}
+
+func (a *App) EmptyTrash() (int, error) {
+	return a.documentHandler.EmptyTrash(a.cleanupUnusedImages)
+}
+
+func (a *App) ListVersions(id string) ([]document.Version, error) {
+	return a.documentHandler.ListVersions(id)
+}
+
+func (a *App) RestoreVersion(id string, timestamp int64) (string, error) {
+	return a.documentHandler.RestoreVersion(id, timestamp)
+}
+
+func (a *App) GetBacklinks(id string) ([]handlers.BacklinkRef, error) {
+	return a.documentHandler.GetBacklinks(id)
+}
+
+func (a *App) GetDocumentStats(id string) (search.DocStats, error) {
+	return a.documentHandler.GetDocumentStats(id)
+}
+
+// MergeDocuments 合并多个文档（deleteSources 为 true 时删除来源文档）
+func (a *App) MergeDocuments(sourceIDs []string, targetTitle string, deleteSources bool) (handlers.MergeResult, error) {
+	return a.documentHandler.MergeDocuments(sourceIDs, targetTitle, deleteSources, a.archiveHandler.RetainDocumentArchives)
+}
+
 func (a *App) RenameDocument(id string, newTitle string) error {
 	return a.documentHandler.RenameDocument(id, newTitle)
 }
 
+func (a *App) SetDocumentEmbedModel(id string, model string) error {
+	return a.documentHandler.SetDocumentEmbedModel(id, model)
+}
+
 func (a *App) SetActiveDocument(id string) error {
 	return a.documentHandler.SetActiveDocument(id)
 }
@@ -348,7 +409,7 @@ func (a *App) LoadDocumentContent(id string) (string, error) {
 	return a.documentHandler.LoadDocumentContent(id)
 }
 
-func (a *App) SaveDocumentContent(id string, content string) error {
+func (a *App) SaveDocumentContent(id string, content string) (bool, error) {
 	return a.documentHandler.SaveDocumentContent(id, content)
 }
 
@@ -356,6 +417,10 @@ func (a *App) ReorderDocuments(ids []string) error {
 	return a.documentHandler.ReorderDocuments(ids)
 }
 
+func (a *App) GetStartupValidationReport() document.ValidationReport {
+	return a.documentHandler.GetStartupValidationReport()
+}
+
 // ========== 搜索 API (委托给 SearchHandler) ==========
 
 func (a *App) SearchDocuments(query string) ([]handlers.SearchResult, error) {
@@ -366,27 +431,57 @@ func (a *App) SemanticSearchDocuments(query string, limit int, excludeDocID stri
 	return a.searchHandler.SemanticSearchDocuments(query, limit, excludeDocID)
 }
 
+func (a *App) SemanticSearchWithFilter(query string, limit int, filter handlers.SearchFilter) ([]handlers.DocumentSearchResult, error) {
+	return a.searchHandler.SemanticSearchWithFilter(query, limit, filter)
+}
+
+func (a *App) SearchFaceted(query string, limit int) (*handlers.FacetedSearchResult, error) {
+	return a.searchHandler.SearchFaceted(query, limit)
+}
+
+func (a *App) SemanticSearchDocumentsPaged(query string, limit, offset int, filter handlers.SearchFilter) (*handlers.DocumentSearchPage, error) {
+	return a.searchHandler.SemanticSearchDocumentsPaged(query, limit, offset, filter)
+}
+
+// ExportSearchResults 将语义搜索结果导出为 JSON 或 CSV
+func (a *App) ExportSearchResults(query string, limit int, format string) (*handlers.SearchExportResult, error) {
+	return a.searchHandler.ExportSearchResults(query, limit, format)
+}
+
+func (a *App) GetRecentSearches(limit int) ([]handlers.SearchHistoryEntry, error) {
+	return a.searchHandler.GetRecentSearches(limit)
+}
+
+func (a *App) ClearSearchHistory() error {
+	return a.searchHandler.ClearSearchHistory()
+}
+
 // ========== RAG API (委托给 RAGHandler) ==========
 
 func (a *App) GetRAGConfig() (handlers.EmbeddingConfig, error) {
 	return a.ragHandler.GetRAGConfig()
 }
 
-func (a *App) SaveRAGConfig(config handlers.EmbeddingConfig) error {
-	return a.ragHandler.SaveRAGConfig(config)
+func (a *App) SaveRAGConfig(original, config handlers.EmbeddingConfig) error {
+	return a.ragHandler.SaveRAGConfig(original, config)
 }
 
 func (a *App) GetRAGStatus() handlers.RAGStatus {
 	return a.ragHandler.GetRAGStatus()
 }
 
-func (a *App) RebuildIndex() (int, error) {
+func (a *App) RebuildIndex() (*handlers.RebuildIndexResult, error) {
 	return a.ragHandler.RebuildIndex()
 }
 
 // GetDocumentGraph 获取文档关系图谱
-func (a *App) GetDocumentGraph(threshold float32) (*handlers.GraphData, error) {
-	return a.ragHandler.GetDocumentGraph(threshold)
+func (a *App) GetDocumentGraph(threshold float32, includeUnembedded bool) (*handlers.GraphData, error) {
+	return a.ragHandler.GetDocumentGraph(threshold, includeUnembedded)
+}
+
+// GetDocumentGraphANN 使用近邻索引获取文档关系图谱（适用于大型知识库）
+func (a *App) GetDocumentGraphANN(threshold float32, k int) (*handlers.GraphData, error) {
+	return a.ragHandler.GetDocumentGraphANN(threshold, k)
 }
 
 // GetDocumentVectors 获取文档向量（供前端 UMAP 降维）
@@ -394,14 +489,64 @@ func (a *App) GetDocumentVectors() (*handlers.VectorGraphData, error) {
 	return a.ragHandler.GetDocumentVectors()
 }
 
+// GetTopicClusters 获取文档主题聚类（k <= 0 时自动选择聚类数）
+func (a *App) GetTopicClusters(k int) ([]handlers.TopicCluster, error) {
+	return a.ragHandler.GetTopicClusters(k)
+}
+
+// RecordSearchFeedback 记录用户对搜索结果的反馈，用于相似查询的排序微调
+func (a *App) RecordSearchFeedback(query, blockID string, helpful bool) error {
+	return a.ragHandler.RecordSearchFeedback(query, blockID, helpful)
+}
+
+// ClearSearchFeedback 清空所有搜索反馈
+func (a *App) ClearSearchFeedback() error {
+	return a.ragHandler.ClearSearchFeedback()
+}
+
+// ExportVectorData 导出所有 chunk 向量及元数据（供外部工具分析）
+func (a *App) ExportVectorData(path string) (*handlers.VectorExportResult, error) {
+	return a.ragHandler.ExportVectorData(path)
+}
+
+// CompactIndex 清理孤儿向量并收缩向量库文件
+func (a *App) CompactIndex() (*handlers.CompactResult, error) {
+	return a.ragHandler.CompactIndex()
+}
+
+// PlanReindex 预估重建索引的变化和嵌入开销（不调用嵌入服务）
+func (a *App) PlanReindex() (*handlers.ReindexPlan, error) {
+	return a.ragHandler.PlanReindex()
+}
+
+// VerifyIndex 检查向量库元数据与向量是否一致
+func (a *App) VerifyIndex() (*handlers.VerifyReport, error) {
+	return a.ragHandler.VerifyIndex()
+}
+
+// RepairIndex 删除向量库中悬空的元数据和向量
+func (a *App) RepairIndex() (*handlers.VerifyReport, error) {
+	return a.ragHandler.RepairIndex()
+}
+
+// ListVectorBackups 列出向量库备份
+func (a *App) ListVectorBackups() ([]handlers.VectorBackup, error) {
+	return a.ragHandler.ListVectorBackups()
+}
+
+// RestoreVectorBackup 从备份恢复向量库
+func (a *App) RestoreVectorBackup(name string) error {
+	return a.ragHandler.RestoreVectorBackup(name)
+}
+
 // WarmupRAG 预热 RAG 服务（用于空闲时初始化，减少冷启动延迟）
 func (a *App) WarmupRAG() error {
 	return a.ragHandler.Warmup()
 }
 
-// IndexBookmarkContent 索引书签网页内容
-func (a *App) IndexBookmarkContent(url, sourceDocID, blockID string) error {
-	return a.ragHandler.IndexBookmarkContent(url, sourceDocID, blockID)
+// IndexBookmarkContent 索引书签网页内容（timeoutSeconds 为 0 时使用配置的超时）
+func (a *App) IndexBookmarkContent(url, sourceDocID, blockID string, timeoutSeconds int) error {
+	return a.ragHandler.IndexBookmarkContent(url, sourceDocID, blockID, timeoutSeconds)
 }
 
 // ========== FileBlock API (委托给 FileHandler/RAGHandler) ==========
@@ -431,6 +576,16 @@ func (a *App) RevealInFinder(relativePath string) error {
 	return a.fileHandler.RevealInFinder(relativePath)
 }
 
+// RevealDocument 在文件管理器中显示文档的存储文件
+func (a *App) RevealDocument(id string) error {
+	return a.fileHandler.RevealDocument(id)
+}
+
+// OpenURL 使用系统默认浏览器打开网址
+func (a *App) OpenURL(url string) error {
+	return a.fileHandler.OpenURL(url)
+}
+
 // IndexFileContent 索引文件内容
 func (a *App) IndexFileContent(filePath, sourceDocID, blockID, fileName string) error {
 	return a.ragHandler.IndexFileContent(filePath, sourceDocID, blockID, fileName)
@@ -456,6 +611,11 @@ func (a *App) TestConnection(config handlers.EmbeddingConfig) handlers.TestConne
 	return a.ragHandler.TestConnection(config)
 }
 
+// TestRAGConnection 检查已保存配置的嵌入服务健康状态
+func (a *App) TestRAGConnection() handlers.ConnectionStatus {
+	return a.ragHandler.TestRAGConnection()
+}
+
 // SelectFolderDialog 文件夹选择对话框
 func (a *App) SelectFolderDialog() (string, error) {
 	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
@@ -490,6 +650,28 @@ func (a *App) GetEffectiveFilePath(originalPath, archivedPath string, archived b
 	return a.archiveHandler.GetEffectiveFilePath(originalPath, archivedPath, archived)
 }
 
+// CheckArchiveStaleness 检查文档中原文件已在归档后被修改的文件块
+func (a *App) CheckArchiveStaleness(docID string) ([]handlers.StaleArchive, error) {
+	return a.archiveHandler.CheckArchiveStaleness(docID)
+}
+
+// IsArchiveStale 原文件是否在归档后被修改
+func (a *App) IsArchiveStale(originalPath, archivedPath string, archivedAt int64) bool {
+	return a.archiveHandler.IsArchiveStale(originalPath, archivedPath, archivedAt)
+}
+
+// FindBrokenFileReferences 查找引用路径已失效的文件/文件夹块
+func (a *App) FindBrokenFileReferences() ([]handlers.BrokenRef, error) {
+	return a.archiveHandler.FindBrokenFileReferences()
+}
+
+// ========== 存储 API (委托给 StorageHandler) ==========
+
+// GetStorageUsage 获取存储占用明细
+func (a *App) GetStorageUsage() handlers.StorageUsage {
+	return a.storageHandler.GetStorageUsage()
+}
+
 // ========== 设置 API (委托给 SettingsHandler) ==========
 
 func (a *App) GetSettings() (handlers.Settings, error) {
@@ -554,12 +736,42 @@ func (a *App) SuggestTags(docId string) ([]handlers.TagSuggestion, error) {
 	return a.tagHandler.SuggestTags(docId)
 }
 
+// GetUntaggedDocuments 获取没有任何标签的文档
+func (a *App) GetUntaggedDocuments() ([]document.Meta, error) {
+	return a.tagHandler.GetUntaggedDocuments()
+}
+
+// AutoTagDocument 根据相似文档自动为文档打标签，返回添加的标签
+func (a *App) AutoTagDocument(docId string) ([]string, error) {
+	return a.tagHandler.AutoTagDocument(docId)
+}
+
 // ========== 文件 API (委托给 FileHandler) ==========
 
 func (a *App) ImportMarkdownFile() (*markdown.ImportResult, error) {
 	return a.fileHandler.ImportMarkdownFile()
 }
 
+// ExportVault 将全部文档导出为 Markdown + 资源的 zip（固定标签组映射为子目录）
+func (a *App) ExportVault(destPath string) (*markdown.VaultExportResult, error) {
+	pinned := a.tagHandler.GetPinnedTags()
+	groups := make([]string, len(pinned))
+	for i, t := range pinned {
+		groups[i] = t.Name
+	}
+	return a.fileHandler.ExportVault(destPath, groups)
+}
+
+// ExportDocumentsToSite 将选中的文档导出为静态 HTML 网站
+func (a *App) ExportDocumentsToSite(docIDs []string, outputDir string) (*markdown.SiteExportResult, error) {
+	return a.fileHandler.ExportDocumentsToSite(docIDs, outputDir)
+}
+
+// ImportMarkdownFolder 批量导入文件夹中的 Markdown 文件（子目录作为固定标签组）
+func (a *App) ImportMarkdownFolder() (*handlers.FolderImportResult, error) {
+	return a.documentHandler.ImportMarkdownFolder(a.tagHandler.PinTag)
+}
+
 func (a *App) ExportMarkdownFile(content string, defaultName string) error {
 	return a.fileHandler.ExportMarkdownFile(content, defaultName)
 }
@@ -568,6 +780,10 @@ func (a *App) ExportHTMLFile(content string, defaultName string) error {
 	return a.fileHandler.ExportHTMLFile(content, defaultName)
 }
 
+func (a *App) ExportPDFFile(content string, defaultName string) (string, error) {
+	return a.fileHandler.ExportPDFFile(content, defaultName)
+}
+
 func (a *App) OpenExternalFile() (handlers.ExternalFile, error) {
 	return a.fileHandler.OpenExternalFile()
 }
@@ -604,6 +820,17 @@ func (a *App) FetchLinkMetadata(url string) (*opengraph.LinkMetadata, error) {
 	return a.fileHandler.FetchLinkMetadata(url)
 }
 
+// CleanupTempFiles 清理超过指定小时数的临时文件，返回删除数量
+func (a *App) CleanupTempFiles(olderThanHours int) (int, error) {
+	defer a.storageHandler.InvalidateStorageUsage()
+	return a.fileHandler.CleanupTempFiles(time.Duration(olderThanHours) * time.Hour)
+}
+
+// GetTempDirSize 获取临时目录大小（字节）
+func (a *App) GetTempDirSize() (int64, error) {
+	return a.fileHandler.GetTempDirSize()
+}
+
 // ========== MCP API ==========
 
 // MCPInfo MCP 配置信息
@@ -4,7 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"time"
+
+	"notion-lite/handlers"
 )
 
 // ========== 清理功能 ==========
@@ -38,11 +39,26 @@ func (a *App) cleanupUnusedImages() {
 		return
 	}
 
+	var contents []string
 	for _, doc := range index.Documents {
 		content, err := a.documentHandler.LoadDocumentContent(doc.ID)
 		if err != nil {
 			continue
 		}
+		contents = append(contents, content)
+	}
+	// 回收站中的文档可能被恢复，其引用的图像同样保留
+	trash, err := a.documentHandler.GetTrash()
+	if err != nil {
+		return
+	}
+	for _, entry := range trash {
+		if content, err := a.documentHandler.LoadTrashedContent(entry.ID); err == nil {
+			contents = append(contents, content)
+		}
+	}
+
+	for _, content := range contents {
 		// 查找所有 /images/xxx 引用
 		matches := imagePattern.FindAllStringSubmatch(content, -1)
 		for _, match := range matches {
@@ -66,26 +82,5 @@ func (a *App) cleanupUnusedImages() {
 
 // cleanupTempFiles 清理超过 24 小时的临时文件
 func (a *App) cleanupTempFiles() {
-	tempDir := a.paths.TempDir()
-
-	entries, err := os.ReadDir(tempDir)
-	if err != nil {
-		return // 目录不存在或无法读取
-	}
-
-	cutoff := time.Now().Add(-24 * time.Hour)
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-		if info.ModTime().Before(cutoff) {
-			filePath := filepath.Join(tempDir, entry.Name())
-			_ = os.Remove(filePath) // 忽略错误
-		}
-	}
+	_, _ = a.fileHandler.CleanupTempFiles(handlers.TempFileMaxAge)
 }
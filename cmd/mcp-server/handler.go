@@ -1,36 +1,76 @@
 package main
 
+import "encoding/json"
+
+// JSON-RPC 错误码
+const (
+	errCodeInvalidParams    = -32602
+	errCodeInternal         = -32603
+	errCodeMethodNotFound   = -32601
+	errCodeNotInitialized   = -32000 // 在 initialize 握手之前调用了需要初始化的方法
+	errCodeResourceNotFound = -32002 // MCP 规范定义的资源不存在错误码
+)
+
+// supportedProtocolVersions 支持的 MCP 协议版本（按发布时间排序，最后一个为最新版本）
+var supportedProtocolVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
 func (s *MCPServer) handleRequest(req *JSONRPCRequest) *JSONRPCResponse {
 	switch req.Method {
 	case "initialize":
 		return s.handleInitialize(req)
-	case "initialized":
+	case "notifications/initialized", "initialized":
 		return nil // Notification, no response
-	case "tools/list":
-		return s.handleToolsList(req)
-	case "tools/call":
-		return s.handleToolCall(req)
+	case "ping":
+		// 握手前后都可调用，客户端用于检测连接是否存活
+		return &JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: struct{}{}}
+	case "tools/list", "tools/call", "resources/list", "resources/read":
+		if !s.initialized {
+			return rpcError(req, errCodeNotInitialized, "Server not initialized")
+		}
+		switch req.Method {
+		case "tools/list":
+			return s.handleToolsList(req)
+		case "tools/call":
+			return s.handleToolCall(req)
+		case "resources/list":
+			return s.handleResourcesList(req)
+		default:
+			return s.handleResourcesRead(req)
+		}
 	default:
-		return &JSONRPCResponse{
-			JSONRPC: "2.0",
-			ID:      req.ID,
-			Error: &RPCError{
-				Code:    -32601,
-				Message: "Method not found",
-			},
+		// 通知没有 ID，不需要响应
+		if req.ID == nil {
+			return nil
 		}
+		return rpcError(req, errCodeMethodNotFound, "Method not found")
 	}
 }
 
 func (s *MCPServer) handleInitialize(req *JSONRPCRequest) *JSONRPCResponse {
+	var params InitializeParams
+	if len(req.Params) > 0 {
+		_ = json.Unmarshal(req.Params, &params)
+	}
+
+	// 客户端请求的版本受支持时沿用，否则按规范返回支持的最新版本，由客户端决定是否继续
+	protocolVersion := supportedProtocolVersions[len(supportedProtocolVersions)-1]
+	for _, v := range supportedProtocolVersions {
+		if v == params.ProtocolVersion {
+			protocolVersion = v
+			break
+		}
+	}
+
+	s.initialized = true
 	result := InitializeResult{
-		ProtocolVersion: "2024-11-05",
+		ProtocolVersion: protocolVersion,
 		ServerInfo: ServerInfo{
-			Name:    "nook-mcp",
-			Version: "1.0.0",
+			Name:    "nook",
+			Version: Version,
 		},
 		Capabilities: Capabilities{
-			Tools: &ToolsCapability{},
+			Tools:     &ToolsCapability{},
+			Resources: &ResourcesCapability{},
 		},
 	}
 	return &JSONRPCResponse{
@@ -39,3 +79,15 @@ func (s *MCPServer) handleInitialize(req *JSONRPCRequest) *JSONRPCResponse {
 		Result:  result,
 	}
 }
+
+// rpcError 构造 JSON-RPC 错误响应
+func rpcError(req *JSONRPCRequest, code int, message string) *JSONRPCResponse {
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Error: &RPCError{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestServe_InitializeHandshake(t *testing.T) {
+	server := &MCPServer{}
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serve(serverIn, serverOut)
+		_ = serverOut.Close()
+	}()
+
+	responses := bufio.NewScanner(clientIn)
+	send := func(msg string) {
+		t.Helper()
+		if _, err := io.WriteString(clientOut, msg+"\n"); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+	receive := func() JSONRPCResponse {
+		t.Helper()
+		if !responses.Scan() {
+			t.Fatalf("expected a response, got EOF: %v", responses.Err())
+		}
+		var resp JSONRPCResponse
+		if err := json.Unmarshal(responses.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", responses.Text(), err)
+		}
+		return resp
+	}
+
+	// ping 在握手前也应响应空结果
+	send(`{"jsonrpc":"2.0","id":"p1","method":"ping"}`)
+	if resp := receive(); resp.Error != nil || resp.ID != "p1" || resp.Result == nil {
+		t.Fatalf("Expected empty ping result, got %+v", resp)
+	}
+
+	// 握手前调用工具应被拒绝
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list_tags","arguments":{}}}`)
+	if resp := receive(); resp.Error == nil || resp.Error.Code != errCodeNotInitialized {
+		t.Fatalf("Expected not-initialized error, got %+v", resp)
+	}
+
+	send(`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	resp := receive()
+	if resp.Error != nil {
+		t.Fatalf("initialize failed: %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var result InitializeResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ProtocolVersion != "2025-03-26" {
+		t.Errorf("Expected negotiated protocol version 2025-03-26, got %s", result.ProtocolVersion)
+	}
+	if result.ServerInfo.Name != "nook" || result.ServerInfo.Version == "" {
+		t.Errorf("Unexpected server info: %+v", result.ServerInfo)
+	}
+	if result.Capabilities.Tools == nil {
+		t.Error("Expected tools capability")
+	}
+
+	// 通知不产生响应：下一条响应应对应 tools/list
+	send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	send(`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	resp = receive()
+	if resp.Error != nil || resp.ID != float64(3) {
+		t.Fatalf("Expected tools/list result for id 3, got %+v", resp)
+	}
+
+	_ = clientOut.Close()
+	if err := <-done; err != nil {
+		t.Errorf("serve returned error: %v", err)
+	}
+}
+
+func TestHandleInitialize_UnsupportedVersion(t *testing.T) {
+	s := &MCPServer{}
+	resp := s.handleInitialize(&JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "initialize",
+		Params:  json.RawMessage(`{"protocolVersion":"1999-01-01"}`),
+	})
+	result, ok := resp.Result.(InitializeResult)
+	if !ok {
+		t.Fatalf("Unexpected initialize result: %+v", resp)
+	}
+	if latest := supportedProtocolVersions[len(supportedProtocolVersions)-1]; result.ProtocolVersion != latest {
+		t.Errorf("Expected latest protocol version %s for an unsupported request, got %s", latest, result.ProtocolVersion)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"notion-lite/internal/document"
+	"notion-lite/internal/folder"
 	"notion-lite/internal/rag"
 	"notion-lite/internal/search"
 	"notion-lite/internal/settings"
@@ -15,6 +17,9 @@ import (
 	"notion-lite/internal/utils"
 )
 
+// Version MCP 服务器版本号，在编译时通过 -ldflags 注入（与应用版本一致）
+var Version = "dev"
+
 // JSON-RPC 2.0 structures
 type JSONRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -41,10 +46,14 @@ type MCPServer struct {
 	docRepo         *document.Repository
 	docStorage      *document.Storage
 	tagStore        *tag.Store
+	tagService      *tag.Service
 	searchService   *search.Service
 	ragService      *rag.Service
 	settingsService *settings.Service
 	paths           *utils.PathBuilder
+	jsonStyle       string // 工具输出 JSON 格式覆盖（NOOK_MCP_JSON），空表示按工具默认
+
+	initialized bool // 是否已完成 initialize 握手
 }
 
 func NewMCPServer() *MCPServer {
@@ -58,21 +67,32 @@ func NewMCPServer() *MCPServer {
 	docStorage := document.NewStorage(paths)
 	tagStore := tag.NewStore(paths)
 	settingsService := settings.NewService(paths)
+	ragService := rag.NewService(paths, docRepo, docStorage)
 
 	return &MCPServer{
 		docRepo:         docRepo,
 		docStorage:      docStorage,
 		tagStore:        tagStore,
+		tagService:      tag.NewService(docRepo, tagStore, folder.NewRepository(paths), &ragAdapter{ragService}),
 		searchService:   search.NewService(docRepo, docStorage),
-		ragService:      rag.NewService(paths, docRepo, docStorage),
+		ragService:      ragService,
 		settingsService: settingsService,
 		paths:           paths,
+		jsonStyle:       os.Getenv("NOOK_MCP_JSON"),
 	}
 }
 
 func main() {
 	server := NewMCPServer()
-	scanner := bufio.NewScanner(os.Stdin)
+	if err := server.serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// serve 逐行读取 JSON-RPC 消息并写回响应（通知没有响应）
+func (s *MCPServer) serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
 	// Increase buffer size for large messages
 	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
 
@@ -84,28 +104,25 @@ func main() {
 
 		var req JSONRPCRequest
 		if err := json.Unmarshal([]byte(line), &req); err != nil {
-			sendError(nil, -32700, "Parse error", err.Error())
+			sendError(w, nil, -32700, "Parse error", err.Error())
 			continue
 		}
 
-		response := server.handleRequest(&req)
+		response := s.handleRequest(&req)
 		if response != nil {
-			sendResponse(response)
+			sendResponse(w, response)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
-		os.Exit(1)
-	}
+	return scanner.Err()
 }
 
-func sendResponse(resp *JSONRPCResponse) {
+func sendResponse(w io.Writer, resp *JSONRPCResponse) {
 	data, _ := json.Marshal(resp)
-	fmt.Println(string(data))
+	_, _ = fmt.Fprintln(w, string(data))
 }
 
-func sendError(id interface{}, code int, message string, data interface{}) {
+func sendError(w io.Writer, id interface{}, code int, message string, data interface{}) {
 	resp := &JSONRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
@@ -115,5 +132,5 @@ func sendError(id interface{}, code int, message string, data interface{}) {
 			Data:    data,
 		},
 	}
-	sendResponse(resp)
+	sendResponse(w, resp)
 }
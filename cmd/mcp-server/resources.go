@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// docResourcePrefix 文档资源 URI 前缀（nook://doc/{id}）
+const docResourcePrefix = "nook://doc/"
+
+// blockNoteMimeType 文档内容（BlockNote JSON）的 MIME 类型
+const blockNoteMimeType = "application/json"
+
+// handleResourcesList 将所有文档列为资源
+func (s *MCPServer) handleResourcesList(req *JSONRPCRequest) *JSONRPCResponse {
+	index, err := s.docRepo.GetAll()
+	if err != nil {
+		return rpcError(req, errCodeInternal, "Failed to list documents: "+err.Error())
+	}
+
+	resources := make([]Resource, 0, len(index.Documents))
+	for _, doc := range index.Documents {
+		name := doc.Title
+		if name == "" {
+			name = "Untitled"
+		}
+		resource := Resource{
+			URI:      docResourcePrefix + doc.ID,
+			Name:     name,
+			MimeType: blockNoteMimeType,
+		}
+		if len(doc.Tags) > 0 {
+			resource.Description = "Tags: " + strings.Join(doc.Tags, ", ")
+		}
+		resources = append(resources, resource)
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  ResourcesListResult{Resources: resources},
+	}
+}
+
+// handleResourcesRead 读取文档资源，返回 BlockNote JSON
+func (s *MCPServer) handleResourcesRead(req *JSONRPCRequest) *JSONRPCResponse {
+	var params ResourceReadParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return rpcError(req, errCodeInvalidParams, "Invalid params")
+	}
+
+	// 只接受索引中存在的文档（Storage.Load 对不存在的文件返回空数组）
+	id, ok := strings.CutPrefix(params.URI, docResourcePrefix)
+	if !ok || !s.documentExists(id) {
+		return rpcError(req, errCodeResourceNotFound, "Resource not found: "+params.URI)
+	}
+	content, err := s.docStorage.Load(id)
+	if err != nil {
+		return rpcError(req, errCodeInternal, "Failed to load document: "+err.Error())
+	}
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: ResourcesReadResult{Contents: []ResourceContent{{
+			URI:      params.URI,
+			MimeType: blockNoteMimeType,
+			Text:     content,
+		}}},
+	}
+}
+
+// documentExists 检查文档是否在索引中
+func (s *MCPServer) documentExists(id string) bool {
+	index, err := s.docRepo.GetAll()
+	if err != nil {
+		return false
+	}
+	for _, doc := range index.Documents {
+		if doc.ID == id {
+			return true
+		}
+	}
+	return false
+}
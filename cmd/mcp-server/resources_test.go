@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/utils"
+)
+
+func TestResources(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	server := &MCPServer{
+		docRepo:     document.NewRepository(paths),
+		docStorage:  document.NewStorage(paths),
+		initialized: true,
+	}
+	if _, err := server.docRepo.CreateWithID("doc1", "Meeting notes"); err != nil {
+		t.Fatal(err)
+	}
+	content := `[{"id":"b1","type":"paragraph","content":"hello"}]`
+	if err := server.docStorage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(method, params string) *JSONRPCResponse {
+		t.Helper()
+		return server.handleRequest(&JSONRPCRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: json.RawMessage(params)})
+	}
+
+	list := call("resources/list", `{}`)
+	resources := list.Result.(ResourcesListResult).Resources
+	if len(resources) != 1 || resources[0].URI != "nook://doc/doc1" || resources[0].Name != "Meeting notes" {
+		t.Fatalf("Unexpected resources: %+v", resources)
+	}
+
+	read := call("resources/read", `{"uri":"nook://doc/doc1"}`)
+	if read.Error != nil {
+		t.Fatalf("resources/read failed: %+v", read.Error)
+	}
+	contents := read.Result.(ResourcesReadResult).Contents
+	if len(contents) != 1 || contents[0].Text != content || contents[0].MimeType != "application/json" {
+		t.Errorf("Unexpected contents: %+v", contents)
+	}
+
+	for _, uri := range []string{"nook://doc/missing", "nook://doc/../settings", "file:///etc/passwd"} {
+		resp := call("resources/read", `{"uri":"`+uri+`"}`)
+		if resp.Error == nil || resp.Error.Code != errCodeResourceNotFound {
+			t.Errorf("Expected resource-not-found for %s, got %+v", uri, resp)
+		}
+	}
+}
@@ -25,13 +25,19 @@ type ServerInfo struct {
 }
 
 type Capabilities struct {
-	Tools *ToolsCapability `json:"tools,omitempty"`
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
 }
 
 type ToolsCapability struct {
 	ListChanged bool `json:"listChanged,omitempty"`
 }
 
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
 type Tool struct {
 	Name        string      `json:"name"`
 	Description string      `json:"description"`
@@ -45,8 +51,9 @@ type InputSchema struct {
 }
 
 type Property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Items       *Property `json:"items,omitempty"` // 数组元素类型（仅 type 为 array 时）
 }
 
 type ToolsListResult struct {
@@ -67,3 +74,29 @@ type ContentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text"`
 }
+
+// Resource 可读取的资源（文档）
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ResourcesListResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+type ResourceReadParams struct {
+	URI string `json:"uri"`
+}
+
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+type ResourcesReadResult struct {
+	Contents []ResourceContent `json:"contents"`
+}
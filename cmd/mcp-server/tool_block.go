@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"notion-lite/internal/blocknote"
+)
+
+// toolAppendBlocks 将块追加到文档末尾（无需发送整个文档）
+func (s *MCPServer) toolAppendBlocks(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID   string `json:"doc_id"`
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	return s.addBlocks(params.DocID, params.Content, "")
+}
+
+// toolInsertBlocks 在指定块之后插入块（可定位嵌套块）
+func (s *MCPServer) toolInsertBlocks(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID        string `json:"doc_id"`
+		AfterBlockID string `json:"after_block_id"`
+		Content      string `json:"content"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	if params.AfterBlockID == "" {
+		return errorResult("after_block_id cannot be empty (use append_blocks to add to the end)")
+	}
+	return s.addBlocks(params.DocID, params.Content, params.AfterBlockID)
+}
+
+// addBlocks 校验新块并插入到 afterBlockID 之后（为空时追加到末尾）
+// 新块（含子块）一律使用新 ID：调用方提供的 ID 可能属于其他文档，而块 ID 同时是向量索引的主键
+func (s *MCPServer) addBlocks(docID, content, afterBlockID string) ToolCallResult {
+	if content == "" {
+		return errorResult("content cannot be empty")
+	}
+	if err := validateBlockNoteContent(content); err != nil {
+		return errorResult("Invalid BlockNote content: " + err.Error())
+	}
+	var newBlocks []interface{}
+	if err := json.Unmarshal([]byte(content), &newBlocks); err != nil {
+		return errorResult("Invalid BlockNote content: " + err.Error())
+	}
+	if len(newBlocks) == 0 {
+		return errorResult("content must contain at least one block")
+	}
+
+	blocks, errResult := s.loadBlocks(docID)
+	if errResult != nil {
+		return *errResult
+	}
+
+	for _, block := range newBlocks {
+		if blockMap, ok := block.(map[string]interface{}); ok {
+			blocknote.RegenerateIDs(blockMap)
+		}
+	}
+	if afterBlockID == "" {
+		blocks = append(blocks, newBlocks...)
+	} else {
+		var found bool
+		if blocks, found = insertBlockAfter(blocks, afterBlockID, newBlocks...); !found {
+			return errorResult("after_block_id not found: " + afterBlockID)
+		}
+	}
+	if err := s.saveBlocks(docID, blocks); err != nil {
+		return errorResult("Failed to save document: " + err.Error())
+	}
+
+	ids := make([]string, 0, len(newBlocks))
+	for _, block := range newBlocks {
+		ids = append(ids, block.(map[string]interface{})["id"].(string))
+	}
+	return textResult(s.jsonText(map[string]interface{}{
+		"added":    len(newBlocks),
+		"blockIds": ids,
+	}, true))
+}
+
+// toolMoveBlock 移动文档中的块（可在任意嵌套层级间移动，子块随之移动）
+func (s *MCPServer) toolMoveBlock(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID        string `json:"doc_id"`
+		BlockID      string `json:"block_id"`
+		AfterBlockID string `json:"after_block_id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	if params.BlockID == "" {
+		return errorResult("block_id cannot be empty")
+	}
+	if params.AfterBlockID == params.BlockID {
+		return errorResult("after_block_id cannot be the block being moved")
+	}
+
+	blocks, errResult := s.loadBlocks(params.DocID)
+	if errResult != nil {
+		return *errResult
+	}
+
+	blocks, err := moveBlock(blocks, params.BlockID, params.AfterBlockID)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	if err := s.saveBlocks(params.DocID, blocks); err != nil {
+		return errorResult("Failed to save document: " + err.Error())
+	}
+
+	if params.AfterBlockID == "" {
+		return textResult(fmt.Sprintf("Block %s moved to the end of the document", params.BlockID))
+	}
+	return textResult(fmt.Sprintf("Block %s moved after %s", params.BlockID, params.AfterBlockID))
+}
+
+// toolDeleteBlock 删除文档中的块（连同其子块）
+func (s *MCPServer) toolDeleteBlock(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID   string `json:"doc_id"`
+		BlockID string `json:"block_id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	if params.BlockID == "" {
+		return errorResult("block_id cannot be empty")
+	}
+
+	blocks, errResult := s.loadBlocks(params.DocID)
+	if errResult != nil {
+		return *errResult
+	}
+
+	blocks, removed := removeBlock(blocks, params.BlockID)
+	if removed == nil {
+		return errorResult("Block not found: " + params.BlockID)
+	}
+	if err := s.saveBlocks(params.DocID, blocks); err != nil {
+		return errorResult("Failed to save document: " + err.Error())
+	}
+
+	return textResult(fmt.Sprintf("Block %s deleted (%d nested blocks removed with it)", params.BlockID, countBlocks(blockChildren(removed))))
+}
+
+// loadBlocks 加载并解析文档的 BlockNote 块
+func (s *MCPServer) loadBlocks(docID string) ([]interface{}, *ToolCallResult) {
+	content, err := s.docStorage.Load(docID)
+	if err != nil {
+		result := errorResult("Document not found: " + docID)
+		return nil, &result
+	}
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		result := errorResult("Failed to parse document: " + err.Error())
+		return nil, &result
+	}
+	return blocks, nil
+}
+
+// saveBlocks 保存修改后的块，更新时间戳并触发 RAG 索引
+func (s *MCPServer) saveBlocks(docID string, blocks []interface{}) error {
+	newContent, err := json.Marshal(blocks)
+	if err != nil {
+		return err
+	}
+	if err := s.saveDocument(docID, string(newContent)); err != nil {
+		return err
+	}
+	_ = s.docRepo.UpdateTimestamp(docID)
+
+	// 触发 RAG 索引
+	if s.ragService != nil {
+		go func() { _ = s.ragService.IndexDocument(docID) }()
+	}
+	return nil
+}
+
+// moveBlock 将 blockID 块移动到 afterBlockID 块之后，成为其同级块
+// afterBlockID 为空时移动到文档末尾；afterBlockID 不能是被移动块自身的子块
+func moveBlock(blocks []interface{}, blockID, afterBlockID string) ([]interface{}, error) {
+	block := findBlock(blocks, blockID)
+	if block == nil {
+		return nil, fmt.Errorf("block not found: %s", blockID)
+	}
+	if afterBlockID != "" {
+		if findBlock(blocks, afterBlockID) == nil {
+			return nil, fmt.Errorf("after_block_id not found: %s", afterBlockID)
+		}
+		if findBlock(blockChildren(block), afterBlockID) != nil {
+			return nil, fmt.Errorf("cannot move block %s after its own nested block %s", blockID, afterBlockID)
+		}
+	}
+
+	blocks, removed := removeBlock(blocks, blockID)
+	if afterBlockID == "" {
+		return append(blocks, removed), nil
+	}
+	blocks, _ = insertBlockAfter(blocks, afterBlockID, removed)
+	return blocks, nil
+}
+
+// findBlock 递归查找指定 ID 的块
+func findBlock(blocks []interface{}, blockID string) map[string]interface{} {
+	for _, block := range blocks {
+		blockMap, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _ := blockMap["id"].(string); id == blockID {
+			return blockMap
+		}
+		if found := findBlock(blockChildren(blockMap), blockID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// removeBlock 递归移除指定 ID 的块，返回新的块列表和被移除的块（未找到时为 nil）
+func removeBlock(blocks []interface{}, blockID string) ([]interface{}, map[string]interface{}) {
+	for i, block := range blocks {
+		blockMap, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _ := blockMap["id"].(string); id == blockID {
+			result := make([]interface{}, 0, len(blocks)-1)
+			result = append(result, blocks[:i]...)
+			result = append(result, blocks[i+1:]...)
+			return result, blockMap
+		}
+		if children, removed := removeBlock(blockChildren(blockMap), blockID); removed != nil {
+			blockMap["children"] = children
+			return blocks, removed
+		}
+	}
+	return blocks, nil
+}
+
+// insertBlockAfter 递归查找 afterBlockID 块，并在其后依次插入同级块（与 insertBlock 不同，可定位嵌套块）
+func insertBlockAfter(blocks []interface{}, afterBlockID string, newBlocks ...interface{}) ([]interface{}, bool) {
+	for i, block := range blocks {
+		blockMap, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if id, _ := blockMap["id"].(string); id == afterBlockID {
+			result := make([]interface{}, 0, len(blocks)+len(newBlocks))
+			result = append(result, blocks[:i+1]...)
+			result = append(result, newBlocks...)
+			result = append(result, blocks[i+1:]...)
+			return result, true
+		}
+		if children, ok := insertBlockAfter(blockChildren(blockMap), afterBlockID, newBlocks...); ok {
+			blockMap["children"] = children
+			return blocks, true
+		}
+	}
+	return blocks, false
+}
+
+// blockChildren 返回块的子块列表
+func blockChildren(block map[string]interface{}) []interface{} {
+	children, _ := block["children"].([]interface{})
+	return children
+}
+
+// countBlocks 递归统计块数
+func countBlocks(blocks []interface{}) int {
+	count := 0
+	for _, block := range blocks {
+		count++
+		if blockMap, ok := block.(map[string]interface{}); ok {
+			count += countBlocks(blockChildren(blockMap))
+		}
+	}
+	return count
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/settings"
+	"notion-lite/internal/utils"
+)
+
+// blockIDs 以 "id(child,child)" 的形式描述块结构，便于断言
+func blockIDs(blocks []interface{}) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		blockMap := block.(map[string]interface{})
+		part := blockMap["id"].(string)
+		if children := blockChildren(blockMap); len(children) > 0 {
+			part += "(" + blockIDs(children) + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseTestBlocks(t *testing.T) []interface{} {
+	t.Helper()
+	content := `[
+		{"id": "a", "type": "paragraph", "children": []},
+		{"id": "b", "type": "bulletListItem", "children": [
+			{"id": "b1", "type": "bulletListItem", "children": [
+				{"id": "b1x", "type": "paragraph", "children": []}
+			]},
+			{"id": "b2", "type": "bulletListItem", "children": []}
+		]},
+		{"id": "c", "type": "paragraph", "children": []}
+	]`
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		t.Fatal(err)
+	}
+	return blocks
+}
+
+func TestMoveBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		blockID string
+		after   string
+		want    string
+	}{
+		{"top level", "a", "c", "b(b1(b1x),b2),c,a"},
+		{"to end", "a", "", "b(b1(b1x),b2),c,a"},
+		{"nested out", "b1", "c", "a,b(b2),c,b1(b1x)"},
+		{"into nested", "c", "b1x", "a,b(b1(b1x,c),b2)"},
+		{"within siblings", "b2", "a", "a,b2,b(b1(b1x)),c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := moveBlock(parseTestBlocks(t), tt.blockID, tt.after)
+			if err != nil {
+				t.Fatalf("moveBlock failed: %v", err)
+			}
+			if got := blockIDs(blocks); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMoveBlock_Errors(t *testing.T) {
+	if _, err := moveBlock(parseTestBlocks(t), "missing", "a"); err == nil {
+		t.Error("Expected an error for a missing block")
+	}
+	if _, err := moveBlock(parseTestBlocks(t), "a", "missing"); err == nil {
+		t.Error("Expected an error for a missing after_block_id")
+	}
+	// 不能移动到自己的子块之后（会形成环）
+	if _, err := moveBlock(parseTestBlocks(t), "b", "b1x"); err == nil {
+		t.Error("Expected an error when moving a block after its own descendant")
+	}
+}
+
+func TestRemoveBlock_Nested(t *testing.T) {
+	blocks, removed := removeBlock(parseTestBlocks(t), "b1")
+	if removed == nil {
+		t.Fatal("Expected b1 to be removed")
+	}
+	if got := blockIDs(blocks); got != "a,b(b2),c" {
+		t.Errorf("Unexpected blocks after removal: %s", got)
+	}
+	if n := countBlocks(blockChildren(removed)); n != 1 {
+		t.Errorf("Expected 1 nested block removed with b1, got %d", n)
+	}
+
+	if _, removed := removeBlock(parseTestBlocks(t), "missing"); removed != nil {
+		t.Error("Expected nothing removed for a missing block")
+	}
+}
+
+func TestInsertBlockAfter_KeepsOrder(t *testing.T) {
+	newBlocks := []interface{}{
+		map[string]interface{}{"id": "n1", "type": "paragraph"},
+		map[string]interface{}{"id": "n2", "type": "paragraph"},
+	}
+	blocks, ok := insertBlockAfter(parseTestBlocks(t), "b1", newBlocks...)
+	if !ok {
+		t.Fatal("Expected b1 to be found")
+	}
+	if got := blockIDs(blocks); got != "a,b(b1(b1x),n1,n2,b2),c" {
+		t.Errorf("Unexpected blocks after insertion: %s", got)
+	}
+	if _, ok := insertBlockAfter(parseTestBlocks(t), "missing", newBlocks...); ok {
+		t.Error("Expected insertion after a missing block to fail")
+	}
+}
+
+func TestAppendBlocks_FreshIDs(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	for _, dir := range []string{paths.DocumentsDir(), paths.VersionsDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := &MCPServer{
+		docRepo:         document.NewRepository(paths),
+		docStorage:      document.NewStorage(paths),
+		settingsService: settings.NewService(paths),
+	}
+	meta, err := server.docRepo.Create("Notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.docStorage.Save(meta.ID, `[{"id": "a", "type": "paragraph"}]`); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新块的 ID 无论与文档重复、来自其他文档还是缺失，都应被替换
+	content := `[
+		{"id": "a", "type": "paragraph"},
+		{"id": "other-doc-block", "type": "bulletListItem", "children": [
+			{"type": "paragraph"}
+		]}
+	]`
+	args, _ := json.Marshal(map[string]string{"doc_id": meta.ID, "content": content})
+	if result := server.toolAppendBlocks(args); result.IsError {
+		t.Fatalf("append_blocks failed: %s", result.Content[0].Text)
+	}
+
+	blocks, errResult := server.loadBlocks(meta.ID)
+	if errResult != nil {
+		t.Fatal(errResult.Content[0].Text)
+	}
+	seen := map[string]bool{}
+	var check func(blocks []interface{})
+	check = func(blocks []interface{}) {
+		for _, block := range blocks {
+			blockMap := block.(map[string]interface{})
+			id, _ := blockMap["id"].(string)
+			if id == "" || seen[id] {
+				t.Errorf("Expected a unique ID, got %q", id)
+			}
+			seen[id] = true
+			check(blockChildren(blockMap))
+		}
+	}
+	check(blocks)
+	if len(seen) != 4 || !seen["a"] || seen["other-doc-block"] {
+		t.Errorf("Expected only the existing block to keep its ID, got %v", seen)
+	}
+}
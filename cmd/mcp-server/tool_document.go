@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"notion-lite/internal/blocknote"
 	"notion-lite/internal/document"
 )
 
@@ -13,9 +14,11 @@ const maxContentLength = 10000
 
 func (s *MCPServer) toolListDocuments(args json.RawMessage) ToolCallResult {
 	var params struct {
-		Offset int    `json:"offset"`
-		Limit  int    `json:"limit"`
-		Tag    string `json:"tag"`
+		Offset   int      `json:"offset"`
+		Limit    int      `json:"limit"`
+		Tag      string   `json:"tag"`
+		Untagged bool     `json:"untagged"`
+		Fields   []string `json:"fields"`
 	}
 	// 解析参数（可选）
 	if len(args) > 0 {
@@ -49,6 +52,12 @@ func (s *MCPServer) toolListDocuments(args json.RawMessage) ToolCallResult {
 			}
 		}
 		documents = filtered
+	} else if params.Untagged {
+		// 仅返回没有标签的文档
+		documents, err = s.docRepo.GetUntaggedDocuments()
+		if err != nil {
+			return errorResult(err.Error())
+		}
 	}
 
 	// 分页处理
@@ -74,10 +83,10 @@ func (s *MCPServer) toolListDocuments(args json.RawMessage) ToolCallResult {
 	}
 
 	type paginatedResult struct {
-		Documents []documentResponse `json:"documents"`
-		Total     int                `json:"total"`
-		Offset    int                `json:"offset"`
-		Limit     int                `json:"limit"`
+		Documents interface{} `json:"documents"`
+		Total     int         `json:"total"`
+		Offset    int         `json:"offset"`
+		Limit     int         `json:"limit"`
 	}
 
 	docs := make([]documentResponse, 0, len(documents[start:end]))
@@ -94,14 +103,13 @@ func (s *MCPServer) toolListDocuments(args json.RawMessage) ToolCallResult {
 	}
 
 	result := paginatedResult{
-		Documents: docs,
+		Documents: projectFields(docs, params.Fields),
 		Total:     total,
 		Offset:    params.Offset,
 		Limit:     params.Limit,
 	}
 
-	data, _ := json.MarshalIndent(result, "", "  ")
-	return textResult(string(data))
+	return textResult(s.jsonText(result, true))
 }
 
 func (s *MCPServer) toolGetDocument(args json.RawMessage) ToolCallResult {
@@ -122,6 +130,29 @@ func (s *MCPServer) toolGetDocument(args json.RawMessage) ToolCallResult {
 	return textResult(content)
 }
 
+// toolGetDocumentMarkdown 以 Markdown 格式获取文档内容（比 BlockNote JSON 更省 token、更易读）
+func (s *MCPServer) toolGetDocumentMarkdown(args json.RawMessage) ToolCallResult {
+	var params struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	content, err := s.docStorage.Load(params.ID)
+	if err != nil {
+		return errorResult("Failed to load document: " + err.Error())
+	}
+	markdown, err := blocknote.ToMarkdown([]byte(content))
+	if err != nil {
+		return errorResult("Failed to render document: " + err.Error())
+	}
+	// 内容截断
+	if len(markdown) > maxContentLength {
+		markdown = markdown[:maxContentLength] + "\n... (truncated, total " + formatSize(len(markdown)) + ")"
+	}
+	return textResult(markdown)
+}
+
 // formatSize 格式化字节大小
 func formatSize(bytes int) string {
 	if bytes < 1024 {
@@ -156,19 +187,18 @@ func (s *MCPServer) toolUpdateDocument(args json.RawMessage) ToolCallResult {
 		if err != nil {
 			return errorResult("Failed to create document: " + err.Error())
 		}
-		if err := s.docStorage.Save(doc.ID, params.Content); err != nil {
+		if err := s.saveDocument(doc.ID, params.Content); err != nil {
 			return errorResult("Created but failed to save content: " + err.Error())
 		}
 		// 触发 RAG 索引
 		if s.ragService != nil {
 			go func() { _ = s.ragService.IndexDocument(doc.ID) }()
 		}
-		data, _ := json.MarshalIndent(doc, "", "  ")
-		return textResult("Document created:\n" + string(data))
+		return textResult("Document created:\n" + s.jsonText(doc, false))
 	}
 
 	// 更新现有文档
-	if err := s.docStorage.Save(params.ID, params.Content); err != nil {
+	if err := s.saveDocument(params.ID, params.Content); err != nil {
 		return errorResult("Failed to update: " + err.Error())
 	}
 	_ = s.docRepo.UpdateTimestamp(params.ID)
@@ -179,6 +209,32 @@ func (s *MCPServer) toolUpdateDocument(args json.RawMessage) ToolCallResult {
 	return textResult("Document updated successfully")
 }
 
+// toolCreateDocumentFromMarkdown 从 Markdown 创建文档（由服务端转换为 BlockNote JSON）
+func (s *MCPServer) toolCreateDocumentFromMarkdown(args json.RawMessage) ToolCallResult {
+	var params struct {
+		Title    string   `json:"title"`
+		Markdown string   `json:"markdown"`
+		Tags     []string `json:"tags"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+
+	content, err := blocknote.FromMarkdown(params.Markdown)
+	if err != nil {
+		return errorResult("Failed to convert markdown: " + err.Error())
+	}
+	doc, err := s.docRepo.CreateWithTags(params.Title, content, params.Tags)
+	if err != nil {
+		return errorResult("Failed to create document: " + err.Error())
+	}
+	// 触发 RAG 索引
+	if s.ragService != nil {
+		go func() { _ = s.ragService.IndexDocument(doc.ID) }()
+	}
+	return textResult("Document created:\n" + s.jsonText(doc, false))
+}
+
 func (s *MCPServer) toolDeleteDocument(args json.RawMessage) ToolCallResult {
 	var params struct {
 		ID string `json:"id"`
@@ -193,7 +249,92 @@ func (s *MCPServer) toolDeleteDocument(args json.RawMessage) ToolCallResult {
 	if s.ragService != nil {
 		go func() { _ = s.ragService.DeleteDocument(params.ID) }()
 	}
-	return textResult("Document deleted successfully")
+	return textResult("Document moved to trash")
+}
+
+// toolRestoreDocument 从回收站恢复文档（未指定 ID 时列出回收站内容）
+func (s *MCPServer) toolRestoreDocument(args json.RawMessage) ToolCallResult {
+	var params struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	if params.ID == "" {
+		trash, err := s.docRepo.GetTrash()
+		if err != nil {
+			return errorResult("Failed to load trash: " + err.Error())
+		}
+		if len(trash) == 0 {
+			return textResult("Trash is empty")
+		}
+		return textResult("Documents in trash:\n" + s.jsonText(trash, true))
+	}
+
+	doc, err := s.docRepo.Restore(params.ID)
+	if err != nil {
+		return errorResult("Failed to restore: " + err.Error())
+	}
+	// 删除时已清除向量索引，恢复后重新索引
+	if s.ragService != nil {
+		go func() {
+			_ = s.ragService.IndexDocument(doc.ID)
+			_, _ = s.ragService.ReindexDocumentExternal(doc.ID)
+		}()
+	}
+	return textResult("Document restored:\n" + s.jsonText(doc, false))
+}
+
+// toolMergeDocuments 合并多个文档为新文档
+func (s *MCPServer) toolMergeDocuments(args json.RawMessage) ToolCallResult {
+	var params struct {
+		SourceIDs     []string `json:"source_ids"`
+		Title         string   `json:"title"`
+		DeleteSources bool     `json:"delete_sources"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+
+	sourceIDs := document.UniqueIDs(params.SourceIDs)
+	if len(sourceIDs) < 2 {
+		return errorResult("source_ids must contain at least two distinct documents")
+	}
+
+	// Merge 返回时新文档已保存，之后才删除来源文档
+	doc, err := document.Merge(s.docRepo, s.docStorage, sourceIDs, params.Title)
+	if err != nil {
+		return errorResult("Failed to merge documents: " + err.Error())
+	}
+	var deleted []string
+	failed := make(map[string]string)
+	if params.DeleteSources {
+		for _, id := range sourceIDs {
+			if err := s.docRepo.Delete(id); err != nil {
+				failed[id] = err.Error()
+				continue
+			}
+			deleted = append(deleted, id)
+		}
+	}
+	// 触发 RAG 索引（外部块的 ID 已改变，需要在新文档下重新索引）
+	if s.ragService != nil {
+		go func() {
+			_ = s.ragService.IndexDocument(doc.ID)
+			_, _ = s.ragService.ReindexDocumentExternal(doc.ID)
+			for _, id := range deleted {
+				_ = s.ragService.DeleteDocument(id)
+			}
+		}()
+	}
+	if len(failed) > 0 {
+		return textResult("Documents merged, but some sources could not be deleted:\n" + s.jsonText(map[string]interface{}{
+			"document":      doc,
+			"deleted":       deleted,
+			"failedDeletes": failed,
+		}, false))
+	}
+	return textResult("Documents merged:\n" + s.jsonText(doc, false))
 }
 
 func (s *MCPServer) toolRenameDocument(args json.RawMessage) ToolCallResult {
@@ -207,6 +348,10 @@ func (s *MCPServer) toolRenameDocument(args json.RawMessage) ToolCallResult {
 	if err := s.docRepo.Rename(params.ID, params.Title); err != nil {
 		return errorResult("Failed to rename: " + err.Error())
 	}
+	// 触发 RAG 索引（启用标题索引时重新嵌入标题）
+	if s.ragService != nil {
+		go func() { _ = s.ragService.IndexDocument(params.ID) }()
+	}
 	return textResult("Document renamed successfully")
 }
 
@@ -257,7 +402,7 @@ func (s *MCPServer) toolEditDocument(args json.RawMessage) ToolCallResult {
 		return errorResult("Edit resulted in invalid content: " + err.Error())
 	}
 
-	if err := s.docStorage.Save(params.ID, string(newContent)); err != nil {
+	if err := s.saveDocument(params.ID, string(newContent)); err != nil {
 		return errorResult("Failed to save: " + err.Error())
 	}
 	_ = s.docRepo.UpdateTimestamp(params.ID)
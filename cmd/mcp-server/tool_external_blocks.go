@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"notion-lite/internal/opengraph"
+	"notion-lite/internal/rag"
+
 	"github.com/google/uuid"
 	"golang.org/x/net/html"
 )
@@ -44,7 +47,7 @@ func (s *MCPServer) toolAddBookmark(args json.RawMessage) ToolCallResult {
 	}
 
 	// 获取书签元数据
-	metadata, err := fetchBookmarkMetadata(params.URL)
+	metadata, err := fetchBookmarkMetadata(params.URL, s.fetchTimeout())
 	if err != nil {
 		return errorResult("Failed to fetch bookmark metadata: " + err.Error())
 	}
@@ -76,7 +79,7 @@ func (s *MCPServer) toolAddBookmark(args json.RawMessage) ToolCallResult {
 
 	// 保存文档
 	newContent, _ := json.Marshal(blocks)
-	if err := s.docStorage.Save(params.DocID, string(newContent)); err != nil {
+	if err := s.saveDocument(params.DocID, string(newContent)); err != nil {
 		return errorResult("Failed to save document: " + err.Error())
 	}
 	_ = s.docRepo.UpdateTimestamp(params.DocID)
@@ -166,7 +169,7 @@ func (s *MCPServer) toolAddFileReference(args json.RawMessage) ToolCallResult {
 
 	// 保存文档
 	newContent, _ := json.Marshal(blocks)
-	if err := s.docStorage.Save(params.DocID, string(newContent)); err != nil {
+	if err := s.saveDocument(params.DocID, string(newContent)); err != nil {
 		return errorResult("Failed to save document: " + err.Error())
 	}
 	_ = s.docRepo.UpdateTimestamp(params.DocID)
@@ -248,7 +251,7 @@ func (s *MCPServer) toolAddFolderReference(args json.RawMessage) ToolCallResult
 
 	// 保存文档
 	newContent, _ := json.Marshal(blocks)
-	if err := s.docStorage.Save(params.DocID, string(newContent)); err != nil {
+	if err := s.saveDocument(params.DocID, string(newContent)); err != nil {
 		return errorResult("Failed to save document: " + err.Error())
 	}
 	_ = s.docRepo.UpdateTimestamp(params.DocID)
@@ -297,16 +300,28 @@ type BookmarkMetadata struct {
 	SiteName    string
 }
 
+// fetchTimeout 从 RAG 配置读取书签抓取超时
+func (s *MCPServer) fetchTimeout() time.Duration {
+	config, err := rag.LoadConfig(s.paths)
+	if err != nil {
+		return opengraph.DefaultFetchTimeout
+	}
+	return config.GetFetchTimeout()
+}
+
 // fetchBookmarkMetadata 获取书签元数据
-func fetchBookmarkMetadata(urlStr string) (*BookmarkMetadata, error) {
+func fetchBookmarkMetadata(urlStr string, timeout time.Duration) (*BookmarkMetadata, error) {
 	// 创建HTTP客户端，设置超时
 	client := &http.Client{
-		Timeout: 10 * time.Second,
+		Timeout: timeout,
 	}
 
 	// 发送HTTP请求
 	resp, err := client.Get(urlStr)
 	if err != nil {
+		if opengraph.IsTimeout(err) {
+			return nil, fmt.Errorf("request timed out after %s: %w", timeout, err)
+		}
 		return nil, fmt.Errorf("failed to fetch URL: %w", err)
 	}
 	defer resp.Body.Close()
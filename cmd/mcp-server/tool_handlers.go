@@ -18,12 +18,28 @@ func (s *MCPServer) handleToolCall(req *JSONRPCRequest) *JSONRPCResponse {
 		result = s.toolListDocuments(params.Arguments)
 	case "get_document":
 		result = s.toolGetDocument(params.Arguments)
+	case "get_document_markdown":
+		result = s.toolGetDocumentMarkdown(params.Arguments)
 	case "update_document":
 		result = s.toolUpdateDocument(params.Arguments)
+	case "create_document_from_markdown":
+		result = s.toolCreateDocumentFromMarkdown(params.Arguments)
 	case "edit_document":
 		result = s.toolEditDocument(params.Arguments)
+	case "append_blocks":
+		result = s.toolAppendBlocks(params.Arguments)
+	case "insert_blocks":
+		result = s.toolInsertBlocks(params.Arguments)
+	case "move_block":
+		result = s.toolMoveBlock(params.Arguments)
+	case "delete_block":
+		result = s.toolDeleteBlock(params.Arguments)
 	case "delete_document":
 		result = s.toolDeleteDocument(params.Arguments)
+	case "restore_document":
+		result = s.toolRestoreDocument(params.Arguments)
+	case "merge_documents":
+		result = s.toolMergeDocuments(params.Arguments)
 	case "rename_document":
 		result = s.toolRenameDocument(params.Arguments)
 	case "search_documents":
@@ -32,14 +48,16 @@ func (s *MCPServer) handleToolCall(req *JSONRPCRequest) *JSONRPCResponse {
 		result = s.toolGetContentGuide()
 	// Tag tools
 	case "list_tags":
-		result = s.toolListTags()
+		result = s.toolListTags(params.Arguments)
 	case "add_tag":
 		result = s.toolAddTag(params.Arguments)
 	case "remove_tag":
 		result = s.toolRemoveTag(params.Arguments)
+	case "auto_tag_document":
+		result = s.toolAutoTagDocument(params.Arguments)
 	// Pinned Tag tools
 	case "list_pinned_tags":
-		result = s.toolListPinnedTags()
+		result = s.toolListPinnedTags(params.Arguments)
 	case "pin_tag":
 		result = s.toolPinTag(params.Arguments)
 	case "unpin_tag":
@@ -60,6 +78,14 @@ func (s *MCPServer) handleToolCall(req *JSONRPCRequest) *JSONRPCResponse {
 		result = s.toolSemanticSearch(params.Arguments)
 	case "get_block_content":
 		result = s.toolGetBlockContent(params.Arguments)
+	case "get_external_content":
+		result = s.toolGetExternalContent(params.Arguments)
+	case "reindex":
+		result = s.toolReindex(params.Arguments)
+	case "compact_index":
+		result = s.toolCompactIndex()
+	case "verify_index":
+		result = s.toolVerifyIndex(params.Arguments)
 
 	default:
 		result = ToolCallResult{
@@ -1,23 +1,40 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"notion-lite/internal/rag"
+	"strings"
+	"time"
 )
 
 func (s *MCPServer) toolSemanticSearch(args json.RawMessage) ToolCallResult {
 	var params struct {
-		Query       string `json:"query"`
-		Limit       int    `json:"limit"`
-		Granularity string `json:"granularity"`
-		DocID       string `json:"doc_id"`
-		BlockID     string `json:"block_id"`
+		Query            string   `json:"query"`
+		Limit            int      `json:"limit"`
+		Granularity      string   `json:"granularity"`
+		DocID            string   `json:"doc_id"`
+		BlockID          string   `json:"block_id"`
+		Tags             []string `json:"tags"`
+		DocIDs           []string `json:"doc_ids"`
+		BlockTypes       []string `json:"block_types"`
+		ExcludeDocIDs    []string `json:"exclude_doc_ids"`
+		ExcludeBookmarks bool     `json:"exclude_bookmarks"`
+		Diversify        bool     `json:"diversify"`
+		Fields           []string `json:"fields"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return errorResult("Invalid arguments: " + err.Error())
 	}
 
+	// 空查询直接返回空结果（不初始化 RAG 服务）
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return textResult(s.jsonText([]interface{}{}, true))
+	}
+
 	if params.Limit <= 0 {
 		params.Limit = 5
 	}
@@ -32,10 +49,17 @@ func (s *MCPServer) toolSemanticSearch(args json.RawMessage) ToolCallResult {
 
 	// Build filter from parameters
 	var filter *rag.SearchFilter
-	if params.DocID != "" || params.BlockID != "" {
+	if params.DocID != "" || params.BlockID != "" || len(params.Tags) > 0 || len(params.DocIDs) > 0 ||
+		len(params.BlockTypes) > 0 || len(params.ExcludeDocIDs) > 0 || params.ExcludeBookmarks || params.Diversify {
 		filter = &rag.SearchFilter{
-			DocID:         params.DocID,
-			SourceBlockID: params.BlockID,
+			DocID:            params.DocID,
+			SourceBlockID:    params.BlockID,
+			Tags:             params.Tags,
+			DocIDs:           params.DocIDs,
+			BlockTypes:       params.BlockTypes,
+			ExcludeDocIDs:    params.ExcludeDocIDs,
+			ExcludeBookmarks: params.ExcludeBookmarks,
+			Diversify:        params.Diversify,
 		}
 	}
 
@@ -44,8 +68,7 @@ func (s *MCPServer) toolSemanticSearch(args json.RawMessage) ToolCallResult {
 		if err != nil {
 			return errorResult("Semantic search failed: " + err.Error())
 		}
-		data, _ := json.MarshalIndent(results, "", "  ")
-		return textResult(string(data))
+		return textResult(s.jsonText(projectFields(results, params.Fields), true))
 	}
 
 	// Default: document-level search
@@ -53,8 +76,7 @@ func (s *MCPServer) toolSemanticSearch(args json.RawMessage) ToolCallResult {
 	if err != nil {
 		return errorResult("Semantic search failed: " + err.Error())
 	}
-	data, _ := json.MarshalIndent(results, "", "  ")
-	return textResult(string(data))
+	return textResult(s.jsonText(projectFields(results, params.Fields), true))
 }
 
 func (s *MCPServer) toolGetBlockContent(args json.RawMessage) ToolCallResult {
@@ -78,6 +100,119 @@ func (s *MCPServer) toolGetBlockContent(args json.RawMessage) ToolCallResult {
 		return errorResult("Failed to get block content: " + err.Error())
 	}
 
-	data, _ := json.MarshalIndent(content, "", "  ")
-	return textResult(string(data))
+	return textResult(s.jsonText(content, false))
+}
+
+func (s *MCPServer) toolGetExternalContent(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID   string `json:"doc_id"`
+		BlockID string `json:"block_id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+
+	if params.DocID == "" || params.BlockID == "" {
+		return errorResult("doc_id and block_id are required")
+	}
+
+	content, err := s.ragService.GetExternalBlockContent(params.DocID, params.BlockID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errorResult("No extracted content found for block " + params.BlockID + " in document " + params.DocID + ". The block may not be a bookmark/file/folder, or it has not been indexed yet.")
+		}
+		return errorResult("Failed to get external content: " + err.Error())
+	}
+
+	type externalContent struct {
+		BlockType   string `json:"blockType"`
+		Title       string `json:"title"`
+		URL         string `json:"url,omitempty"`
+		FilePath    string `json:"filePath,omitempty"`
+		Content     string `json:"content"`
+		Truncated   bool   `json:"truncated,omitempty"` // 文件超过提取上限，content 只是开头部分
+		ExtractedAt string `json:"extractedAt"`
+	}
+
+	output := externalContent{
+		BlockType:   content.BlockType,
+		Title:       content.Title,
+		URL:         content.URL,
+		FilePath:    content.FilePath,
+		Content:     content.RawContent,
+		Truncated:   content.Truncated,
+		ExtractedAt: time.Unix(content.ExtractedAt, 0).Format(time.RFC3339),
+	}
+	return textResult(s.jsonText(output, false))
+}
+
+func (s *MCPServer) toolReindex(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID string `json:"doc_id"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &params); err != nil {
+			return errorResult("Invalid arguments: " + err.Error())
+		}
+	}
+
+	if params.DocID != "" {
+		if _, err := s.docStorage.Load(params.DocID); err != nil {
+			return errorResult("Document not found: " + params.DocID)
+		}
+		chunks, err := s.ragService.ForceReindexDocument(params.DocID)
+		if err != nil {
+			return errorResult("Reindex failed: " + err.Error())
+		}
+		output := map[string]interface{}{
+			"scope":  "document",
+			"doc_id": params.DocID,
+			"chunks": chunks,
+		}
+		return textResult(s.jsonText(output, false))
+	}
+
+	report, chunks, err := s.ragService.ReindexAllDocuments(context.Background())
+	if err != nil {
+		return errorResult("Reindex failed: " + err.Error())
+	}
+	output := map[string]interface{}{
+		"scope":     "all",
+		"documents": report.Total,
+		"indexed":   report.Indexed,
+		"failed":    report.Failed,
+		"chunks":    chunks,
+	}
+	return textResult(s.jsonText(output, false))
+}
+
+// toolCompactIndex 清理孤儿向量并收缩向量库文件
+func (s *MCPServer) toolCompactIndex() ToolCallResult {
+	result, err := s.ragService.CompactIndex()
+	if err != nil {
+		return errorResult("Compact failed: " + err.Error())
+	}
+	return textResult(s.jsonText(result, false))
+}
+
+// toolVerifyIndex 检查向量库一致性（可选修复）
+func (s *MCPServer) toolVerifyIndex(args json.RawMessage) ToolCallResult {
+	var params struct {
+		Repair bool `json:"repair"`
+	}
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &params); err != nil {
+			return errorResult("Invalid arguments: " + err.Error())
+		}
+	}
+
+	verify := s.ragService.VerifyIndex
+	if params.Repair {
+		verify = s.ragService.RepairIndex
+	}
+	report, err := verify()
+	if err != nil {
+		return errorResult("Verify failed: " + err.Error())
+	}
+	return textResult(s.jsonText(report, false))
 }
@@ -1,11 +1,32 @@
 package main
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"notion-lite/internal/constant"
+	"notion-lite/internal/rag"
+	"notion-lite/internal/search"
+)
+
+// semanticSnippetMaxRunes 语义命中片段的最大字符数
+const semanticSnippetMaxRunes = 200
+
+// searchHit 合并关键词与语义搜索的统一结果
+type searchHit struct {
+	ID      string  `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Source  string  `json:"source"`            // "keyword" | "semantic" | "both"
+	Score   float32 `json:"score,omitempty"`   // 语义相关性分数
+	BlockID string  `json:"blockId,omitempty"` // 语义命中的块 ID（用于定位）
+}
 
 func (s *MCPServer) toolSearchDocuments(args json.RawMessage) ToolCallResult {
 	var params struct {
-		Query string `json:"query"`
-		Limit int    `json:"limit"`
+		Query  string   `json:"query"`
+		Limit  int      `json:"limit"`
+		Mode   string   `json:"mode"`
+		Fields []string `json:"fields"`
 	}
 	if err := json.Unmarshal(args, &params); err != nil {
 		return errorResult("Invalid arguments: " + err.Error())
@@ -18,31 +39,137 @@ func (s *MCPServer) toolSearchDocuments(args json.RawMessage) ToolCallResult {
 	if params.Limit > 50 {
 		params.Limit = 50
 	}
+	if params.Mode == "" {
+		params.Mode = "keyword"
+	}
 
-	results, err := s.searchService.Search(params.Query)
-	if err != nil {
-		return errorResult("Search failed: " + err.Error())
+	// 构建结果
+	type searchResult struct {
+		Results       interface{} `json:"results"`
+		Total         int         `json:"total"`
+		Limit         int         `json:"limit"`
+		Mode          string      `json:"mode"`
+		SemanticError string      `json:"semanticError,omitempty"` // hybrid 模式下语义搜索失败时的原因
 	}
+	output := searchResult{Limit: params.Limit, Mode: params.Mode}
 
-	// 限制结果数量
-	total := len(results)
-	if len(results) > params.Limit {
-		results = results[:params.Limit]
+	switch params.Mode {
+	case "keyword":
+		results, err := s.searchService.Search(params.Query)
+		if err != nil {
+			return errorResult("Search failed: " + err.Error())
+		}
+		output.Total = len(results)
+		if len(results) > params.Limit {
+			results = results[:params.Limit]
+		}
+		output.Results = results
+
+	case "semantic":
+		semantic, err := s.ragService.SearchDocuments(params.Query, params.Limit, nil)
+		if err != nil {
+			return errorResult("Semantic search failed: " + err.Error())
+		}
+		hits := mergeSearchHits(nil, semantic)
+		output.Total = len(hits)
+		output.Results = hits
+
+	case "hybrid":
+		keyword, err := s.searchService.Search(params.Query)
+		if err != nil {
+			return errorResult("Search failed: " + err.Error())
+		}
+		// 语义搜索失败（如嵌入服务不可达）时降级为仅关键词结果
+		semantic, err := s.ragService.SearchDocuments(params.Query, params.Limit, nil)
+		if err != nil {
+			output.SemanticError = err.Error()
+		}
+		hits := mergeSearchHits(keyword, semantic)
+		output.Total = len(hits)
+		if len(hits) > params.Limit {
+			hits = hits[:params.Limit]
+		}
+		output.Results = hits
+
+	default:
+		return errorResult("Invalid mode: " + params.Mode + " (expected keyword, semantic or hybrid)")
 	}
 
-	// 构建结果
-	type searchResult struct {
-		Results interface{} `json:"results"`
-		Total   int         `json:"total"`
-		Limit   int         `json:"limit"`
+	output.Results = projectFields(output.Results, params.Fields)
+	return textResult(s.jsonText(output, true))
+}
+
+// mergeSearchHits 合并关键词与语义结果，按文档 ID 去重
+// 排序：标题命中 > 其他关键词命中 > 仅语义命中（按相关性）
+func mergeSearchHits(keyword []search.Result, semantic []rag.DocumentSearchResult) []searchHit {
+	semanticByID := make(map[string]rag.DocumentSearchResult, len(semantic))
+	for _, result := range semantic {
+		semanticByID[result.DocID] = result
 	}
 
-	output := searchResult{
-		Results: results,
-		Total:   total,
-		Limit:   params.Limit,
+	var titleHits, keywordHits, semanticHits []searchHit
+	seen := make(map[string]bool)
+
+	for _, result := range keyword {
+		if seen[result.ID] {
+			continue
+		}
+		seen[result.ID] = true
+
+		hit := searchHit{ID: result.ID, Title: result.Title, Snippet: result.Snippet, Source: "keyword"}
+		if sem, ok := semanticByID[result.ID]; ok {
+			hit.Source = "both"
+			hit.Score = sem.MaxScore
+			hit.BlockID = semanticBlockID(sem)
+		}
+		if result.Snippet == constant.SearchTitleMatch {
+			titleHits = append(titleHits, hit)
+		} else {
+			keywordHits = append(keywordHits, hit)
+		}
 	}
 
-	data, _ := json.MarshalIndent(output, "", "  ")
-	return textResult(string(data))
+	for _, result := range semantic {
+		if seen[result.DocID] {
+			continue
+		}
+		seen[result.DocID] = true
+		semanticHits = append(semanticHits, searchHit{
+			ID:      result.DocID,
+			Title:   result.DocTitle,
+			Snippet: semanticSnippet(result),
+			Source:  "semantic",
+			Score:   result.MaxScore,
+			BlockID: semanticBlockID(result),
+		})
+	}
+
+	hits := make([]searchHit, 0, len(titleHits)+len(keywordHits)+len(semanticHits))
+	hits = append(hits, titleHits...)
+	hits = append(hits, keywordHits...)
+	return append(hits, semanticHits...)
+}
+
+// semanticSnippet 取最相关 chunk 的内容作为片段
+func semanticSnippet(result rag.DocumentSearchResult) string {
+	if len(result.MatchedChunks) == 0 {
+		return ""
+	}
+	runes := []rune(result.MatchedChunks[0].Content)
+	if len(runes) > semanticSnippetMaxRunes {
+		return string(runes[:semanticSnippetMaxRunes]) + "..."
+	}
+	return string(runes)
+}
+
+// semanticBlockID 取最相关 chunk 对应的原始块 ID
+func semanticBlockID(result rag.DocumentSearchResult) string {
+	if len(result.MatchedChunks) == 0 {
+		return ""
+	}
+	chunk := result.MatchedChunks[0]
+	if chunk.SourceBlockId != "" {
+		return chunk.SourceBlockId
+	}
+	return chunk.BlockID
 }
@@ -1,6 +1,11 @@
 package main
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"notion-lite/internal/rag"
+	"notion-lite/internal/tag"
+)
 
 func (s *MCPServer) toolAddTag(args json.RawMessage) ToolCallResult {
 	var params struct {
@@ -36,7 +41,57 @@ func (s *MCPServer) toolRemoveTag(args json.RawMessage) ToolCallResult {
 	return textResult("Tag removed successfully")
 }
 
-func (s *MCPServer) toolListTags() ToolCallResult {
+// autoTagLimit auto_tag_document 最多考虑的推荐标签数（与应用内自动打标签一致）
+const autoTagLimit = 3
+
+// toolAutoTagDocument 根据相似文档的标签为文档自动打标签
+func (s *MCPServer) toolAutoTagDocument(args json.RawMessage) ToolCallResult {
+	var params struct {
+		DocID string `json:"doc_id"`
+	}
+	if err := json.Unmarshal(args, &params); err != nil {
+		return errorResult("Invalid arguments: " + err.Error())
+	}
+	if params.DocID == "" {
+		return errorResult("doc_id is required")
+	}
+	added, err := s.tagService.AutoTagDocument(params.DocID, autoTagLimit)
+	if err != nil {
+		return errorResult("Failed to auto-tag document: " + err.Error())
+	}
+	return textResult(s.jsonText(map[string]interface{}{
+		"docId": params.DocID,
+		"added": added,
+	}, true))
+}
+
+// ragAdapter 让 rag.Service 实现 tag.RAGSearcher 接口
+type ragAdapter struct {
+	ragService *rag.Service
+}
+
+// SearchSimilarDocuments 实现 tag.RAGSearcher 接口
+func (a *ragAdapter) SearchSimilarDocuments(docId string, limit int) ([]tag.RAGDocumentResult, error) {
+	results, err := a.ragService.SearchSimilarDocuments(docId, limit)
+	if err != nil {
+		return nil, err
+	}
+	tagResults := make([]tag.RAGDocumentResult, len(results))
+	for i, r := range results {
+		tagResults[i] = tag.RAGDocumentResult{DocID: r.DocID}
+	}
+	return tagResults, nil
+}
+
+func (s *MCPServer) toolListTags(args json.RawMessage) ToolCallResult {
+	var params struct {
+		Fields []string `json:"fields"`
+	}
+	// 解析参数（可选）
+	if len(args) > 0 {
+		_ = json.Unmarshal(args, &params)
+	}
+
 	index, err := s.docRepo.GetAll()
 	if err != nil {
 		return errorResult("Failed to get documents: " + err.Error())
@@ -60,16 +115,20 @@ func (s *MCPServer) toolListTags() ToolCallResult {
 		tags = append(tags, tagInfo{Name: name, Count: count})
 	}
 
-	data, _ := json.MarshalIndent(tags, "", "  ")
-	return textResult(string(data))
+	return textResult(s.jsonText(projectFields(tags, params.Fields), true))
 }
 
 // ========== Pinned Tag tools ==========
 
-func (s *MCPServer) toolListPinnedTags() ToolCallResult {
+func (s *MCPServer) toolListPinnedTags(args json.RawMessage) ToolCallResult {
+	var params struct {
+		Fields []string `json:"fields"`
+	}
+	if len(args) > 0 {
+		_ = json.Unmarshal(args, &params)
+	}
 	pinned := s.tagStore.GetAllPinnedTags()
-	data, _ := json.MarshalIndent(pinned, "", "  ")
-	return textResult(string(data))
+	return textResult(s.jsonText(projectFields(pinned, params.Fields), true))
 }
 
 func (s *MCPServer) toolPinTag(args json.RawMessage) ToolCallResult {
@@ -4,13 +4,15 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 	tools := []Tool{
 		{
 			Name:        "list_documents",
-			Description: "List all documents in Nook with their metadata (id, title, tags, timestamps). Optionally filter by tag.",
+			Description: "List all documents in Nook with their metadata (id, title, tags, timestamps). Optionally filter by tag, or list only untagged documents.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"offset": {Type: "number", Description: "Skip first N documents (default: 0)"},
-					"limit":  {Type: "number", Description: "Maximum documents to return (default: 50, max: 100)"},
-					"tag":    {Type: "string", Description: "Optional: filter documents by tag name"},
+					"offset":   {Type: "number", Description: "Skip first N documents (default: 0)"},
+					"limit":    {Type: "number", Description: "Maximum documents to return (default: 50, max: 100)"},
+					"tag":      {Type: "string", Description: "Optional: filter documents by tag name"},
+					"untagged": {Type: "boolean", Description: "Optional: only return documents without any tags (ignored when tag is set)"},
+					"fields":   {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only return these fields of each document (e.g. [\"id\", \"title\"]) to save context"},
 				},
 			},
 		},
@@ -25,6 +27,17 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"id"},
 			},
 		},
+		{
+			Name:        "get_document_markdown",
+			Description: "Get the content of a document by ID rendered as Markdown. More compact and readable than get_document; use get_document when you need block IDs or exact formatting for editing.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"id": {Type: "string", Description: "Document ID"},
+				},
+				Required: []string{"id"},
+			},
+		},
 		{
 			Name:        "update_document",
 			Description: "Create or update a document. If the document ID exists, replaces its content; if not, creates a new document. Use get_content_guide to get the correct JSON format.",
@@ -37,6 +50,19 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"id", "content"},
 			},
 		},
+		{
+			Name:        "create_document_from_markdown",
+			Description: "Create a new document from Markdown. The server converts it to BlockNote JSON, so prefer this over update_document when writing new content. Supports headings, bullet/numbered/check lists, code fences with language, quotes, images, bold/italic/strike/inline code and links.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"title":    {Type: "string", Description: "Document title"},
+					"markdown": {Type: "string", Description: "Document content as Markdown"},
+					"tags":     {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: tags (or pinned tag groups) to file the new document under"},
+				},
+				Required: []string{"title", "markdown"},
+			},
+		},
 		{
 			Name:        "edit_document",
 			Description: "Edit a document using str_replace. Finds old_text in the document and replaces it with new_text. The old_text must be unique in the document.",
@@ -50,9 +76,59 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"id", "old_text", "new_text"},
 			},
 		},
+		{
+			Name:        "append_blocks",
+			Description: "Append blocks to the end of a document without sending the whole document. Prefer this over update_document for adding notes. Inserted blocks always get fresh IDs; the resulting IDs are returned.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id":  {Type: "string", Description: "Document ID"},
+					"content": {Type: "string", Description: "BlockNote JSON array of blocks to append (call get_content_guide first)"},
+				},
+				Required: []string{"doc_id", "content"},
+			},
+		},
+		{
+			Name:        "insert_blocks",
+			Description: "Insert blocks right after an existing block (at any nesting level) without sending the whole document. Inserted blocks always get fresh IDs; the resulting IDs are returned.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id":         {Type: "string", Description: "Document ID"},
+					"after_block_id": {Type: "string", Description: "Insert after this block ID"},
+					"content":        {Type: "string", Description: "BlockNote JSON array of blocks to insert (call get_content_guide first)"},
+				},
+				Required: []string{"doc_id", "after_block_id", "content"},
+			},
+		},
+		{
+			Name:        "move_block",
+			Description: "Move a block (with its nested children) within a document. The block is placed right after after_block_id as its sibling, at whatever nesting level after_block_id is. Use get_document to find block IDs.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id":         {Type: "string", Description: "Document ID"},
+					"block_id":       {Type: "string", Description: "ID of the block to move"},
+					"after_block_id": {Type: "string", Description: "Optional: Move after this block ID. If not provided, moves to the end of the document."},
+				},
+				Required: []string{"doc_id", "block_id"},
+			},
+		},
+		{
+			Name:        "delete_block",
+			Description: "Delete a block and its nested children from a document. A version snapshot is kept, so the change can be undone from the document history.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id":   {Type: "string", Description: "Document ID"},
+					"block_id": {Type: "string", Description: "ID of the block to delete"},
+				},
+				Required: []string{"doc_id", "block_id"},
+			},
+		},
 		{
 			Name:        "delete_document",
-			Description: "Delete a document by ID",
+			Description: "Delete a document by ID (moves it to the trash; use restore_document to undo)",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
@@ -61,6 +137,29 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"id"},
 			},
 		},
+		{
+			Name:        "restore_document",
+			Description: "Restore a deleted document from the trash. Omit id to list the documents currently in the trash.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"id": {Type: "string", Description: "Optional: ID of the deleted document to restore"},
+				},
+			},
+		},
+		{
+			Name:        "merge_documents",
+			Description: "Merge several documents into a new one. Content is concatenated in the given order with each source's title as a separator heading; tags are combined. Bookmarks and files are carried over and re-indexed under the new document.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"source_ids":     {Type: "array", Items: &Property{Type: "string"}, Description: "IDs of the documents to merge (at least two), in order"},
+					"title":          {Type: "string", Description: "Optional: title of the merged document (defaults to the first source's title)"},
+					"delete_sources": {Type: "boolean", Description: "Optional: delete the source documents after merging (default false)"},
+				},
+				Required: []string{"source_ids"},
+			},
+		},
 		{
 			Name:        "rename_document",
 			Description: "Rename a document",
@@ -75,12 +174,14 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 		},
 		{
 			Name:        "search_documents",
-			Description: "Search documents by keyword in title, content, and tags",
+			Description: "Search documents by keyword in title, content, and tags. Use mode='semantic' to match by meaning when exact phrases may not appear, or mode='hybrid' to combine both (keyword title matches ranked first, semantic hits include the most relevant block snippet).",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"query": {Type: "string", Description: "Search query"},
-					"limit": {Type: "number", Description: "Maximum results to return (default: 20, max: 50)"},
+					"query":  {Type: "string", Description: "Search query"},
+					"limit":  {Type: "number", Description: "Maximum results to return (default: 20, max: 50)"},
+					"mode":   {Type: "string", Description: "Search mode: 'keyword' (default), 'semantic', or 'hybrid'"},
+					"fields": {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only return these fields of each result (e.g. [\"id\", \"title\"]) to save context"},
 				},
 				Required: []string{"query"},
 			},
@@ -94,7 +195,12 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 		{
 			Name:        "list_tags",
 			Description: "List all existing tags with usage counts. IMPORTANT: Call this BEFORE using add_tag to check for existing tags with similar meaning (e.g., '项目管理' vs 'Project Management'). Always prefer reusing existing tags over creating new ones to maintain consistency.",
-			InputSchema: InputSchema{Type: "object"},
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"fields": {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only return these fields of each tag (e.g. [\"name\"]) to save context"},
+				},
+			},
 		},
 		{
 			Name:        "add_tag",
@@ -120,11 +226,27 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"doc_id", "tag"},
 			},
 		},
+		{
+			Name:        "auto_tag_document",
+			Description: "Automatically tag a document with existing tags shared by its semantically similar documents (requires the RAG index). Only reuses existing tags; returns the tags that were added. Combine with list_documents(untagged=true) to organize untagged documents.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id": {Type: "string", Description: "Document ID"},
+				},
+				Required: []string{"doc_id"},
+			},
+		},
 		// Pinned Tag tools
 		{
 			Name:        "list_pinned_tags",
 			Description: "List all pinned tags. Pinned tags are shown in the sidebar for quick access.",
-			InputSchema: InputSchema{Type: "object"},
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"fields": {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only return these fields of each tag (e.g. [\"name\"]) to save context"},
+				},
+			},
 		},
 		{
 			Name:        "pin_tag",
@@ -187,7 +309,7 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 		},
 		{
 			Name:        "add_file_reference",
-			Description: "Add a file reference block to a document. The file content can be indexed for RAG search. ⚠️ Note: File content will be indexed, so only reference files that are relevant to avoid cluttering the search index. Supports PDF, DOCX, TXT, MD and other text-based formats.",
+			Description: "Add a file reference block to a document. The file content can be indexed for RAG search. ⚠️ Note: File content will be indexed, so only reference files that are relevant to avoid cluttering the search index. Supports PDF, DOCX, PPTX, RTF, CSV, TSV, TXT, MD and other text-based formats.",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
@@ -214,15 +336,22 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 		// RAG tools
 		{
 			Name:        "semantic_search",
-			Description: "Search by semantic similarity using natural language. Use granularity='documents' to find relevant documents, or 'chunks' to find specific text blocks within documents. Use doc_id to search within a specific document, or block_id to search within a specific bookmark/file/folder block (e.g., search within a specific PDF).",
+			Description: "Search by semantic similarity using natural language. Use granularity='documents' to find relevant documents, or 'chunks' to find specific text blocks within documents. Use doc_id to search within a specific document, or block_id to search within a specific bookmark/file/folder block (e.g., search within a specific PDF). Use tags, doc_ids, block_types and exclude_doc_ids to narrow the scope (e.g., only documents tagged 'research').",
 			InputSchema: InputSchema{
 				Type: "object",
 				Properties: map[string]Property{
-					"query":       {Type: "string", Description: "Natural language search query"},
-					"limit":       {Type: "number", Description: "Maximum results to return (default: 5)"},
-					"granularity": {Type: "string", Description: "Result granularity: 'documents' for document-level results (default), 'chunks' for text blocks"},
-					"doc_id":      {Type: "string", Description: "Optional: limit search to a specific document"},
-					"block_id":    {Type: "string", Description: "Optional: limit search to a specific block (e.g., a FileBlock containing a PDF, or a FolderBlock)"},
+					"query":             {Type: "string", Description: "Natural language search query"},
+					"limit":             {Type: "number", Description: "Maximum results to return (default: 5)"},
+					"granularity":       {Type: "string", Description: "Result granularity: 'documents' for document-level results (default), 'chunks' for text blocks"},
+					"doc_id":            {Type: "string", Description: "Optional: limit search to a specific document"},
+					"block_id":          {Type: "string", Description: "Optional: limit search to a specific block (e.g., a FileBlock containing a PDF, or a FolderBlock)"},
+					"tags":              {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only search documents having any of these tags"},
+					"doc_ids":           {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only search these documents"},
+					"block_types":       {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only match these block types (e.g., paragraph, heading, bookmark, file)"},
+					"exclude_doc_ids":   {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: exclude these documents from results"},
+					"exclude_bookmarks": {Type: "boolean", Description: "Optional: skip bookmarked web page content"},
+					"diversify":         {Type: "boolean", Description: "Optional: re-rank results for diversity so near-duplicate chunks don't crowd the top"},
+					"fields":            {Type: "array", Items: &Property{Type: "string"}, Description: "Optional: only return these fields of each result (e.g. [\"docId\", \"title\", \"score\"]) to save context"},
 				},
 				Required: []string{"query"},
 			},
@@ -239,6 +368,43 @@ func (s *MCPServer) handleToolsList(req *JSONRPCRequest) *JSONRPCResponse {
 				Required: []string{"doc_id", "block_id"},
 			},
 		},
+		{
+			Name:        "get_external_content",
+			Description: "Read the saved text of a bookmark, file, or folder block without re-fetching it. Returns the title, source URL or file path, the full extracted content (truncated=true when a large file was only partially extracted), and when it was extracted. Use this to cite saved web content offline.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id":   {Type: "string", Description: "Document ID containing the block"},
+					"block_id": {Type: "string", Description: "Block ID of the bookmark, file, or folder block"},
+				},
+				Required: []string{"doc_id", "block_id"},
+			},
+		},
+		{
+			Name:        "reindex",
+			Description: "Rebuild the semantic search index so recent edits become searchable immediately. Pass doc_id to reindex a single document, or omit it to reindex all documents. Returns the number of chunks indexed.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"doc_id": {Type: "string", Description: "Optional: reindex only this document; omit to reindex all documents"},
+				},
+			},
+		},
+		{
+			Name:        "compact_index",
+			Description: "Remove orphaned vectors and VACUUM the semantic search database to reclaim disk space. Returns the number of bytes reclaimed.",
+			InputSchema: InputSchema{Type: "object"},
+		},
+		{
+			Name:        "verify_index",
+			Description: "Check the semantic search database for metadata rows without vectors, vectors without metadata and vectors with the wrong dimension. Use when search returns odd results. Set repair to delete the dangling rows.",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"repair": {Type: "boolean", Description: "Optional: delete dangling rows after checking (default: false)"},
+				},
+			},
+		},
 	}
 
 	return &JSONRPCResponse{
@@ -3,7 +3,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+
+	"notion-lite/internal/settings"
 )
 
 func textResult(text string) ToolCallResult {
@@ -19,6 +22,71 @@ func errorResult(msg string) ToolCallResult {
 	}
 }
 
+// 工具输出 JSON 格式（环境变量 NOOK_MCP_JSON）
+const (
+	jsonStyleCompact = "compact" // 全部工具输出紧凑 JSON
+	jsonStylePretty  = "pretty"  // 全部工具输出缩进 JSON
+)
+
+// jsonText 序列化工具输出
+// 列表类工具（compact=true）默认输出紧凑 JSON，节省助手读取的上下文；其余工具默认缩进。
+// 设置 NOOK_MCP_JSON=compact|pretty 可统一覆盖
+func (s *MCPServer) jsonText(v interface{}, compact bool) string {
+	switch s.jsonStyle {
+	case jsonStyleCompact:
+		compact = true
+	case jsonStylePretty:
+		compact = false
+	}
+	var data []byte
+	if compact {
+		data, _ = json.Marshal(v)
+	} else {
+		data, _ = json.MarshalIndent(v, "", "  ")
+	}
+	return string(data)
+}
+
+// projectFields 只保留列表中每个对象的指定字段（按 JSON 字段名），fields 为空时原样返回
+// 不存在的字段被忽略
+func projectFields(list interface{}, fields []string) interface{} {
+	if len(fields) == 0 {
+		return list
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return list
+	}
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return list
+	}
+	projected := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		kept := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if value, ok := item[f]; ok {
+				kept[f] = value
+			}
+		}
+		projected = append(projected, kept)
+	}
+	return projected
+}
+
+// saveDocument 保存文档内容，覆盖前为旧内容创建快照
+// AI 的每次修改都保留快照（不按时间分桶，内容未变化时按哈希去重），便于撤销错误的编辑
+func (s *MCPServer) saveDocument(id string, content string) error {
+	keep := settings.DefaultVersionRetention
+	if cfg, err := s.settingsService.Get(); err == nil {
+		keep = cfg.VersionsToKeep()
+	}
+	if err := s.docStorage.Snapshot(id, content, keep, 0); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to snapshot document %s: %v\n", id, err)
+	}
+	return s.docStorage.Save(id, content)
+}
+
 // validateBlockNoteContent validates that content is a valid BlockNote JSON array
 // 采用分层验证策略：
 // 1. 验证 JSON 格式和必需字段（严格）
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/utils"
+)
+
+func TestListDocuments_CompactWithFields(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	server := &MCPServer{docRepo: document.NewRepository(paths)}
+	if _, err := server.docRepo.CreateWithTags("Meeting notes", nil, []string{"work"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := server.toolListDocuments(json.RawMessage(`{"fields":["id","title"]}`))
+	text := result.Content[0].Text
+	if strings.Contains(text, "\n") {
+		t.Errorf("Expected compact JSON for list tools, got %q", text)
+	}
+	var parsed struct {
+		Documents []map[string]interface{} `json:"documents"`
+		Total     int                      `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Total != 1 || len(parsed.Documents) != 1 {
+		t.Fatalf("Unexpected result: %s", text)
+	}
+	doc := parsed.Documents[0]
+	if len(doc) != 2 || doc["title"] != "Meeting notes" || doc["id"] == nil {
+		t.Errorf("Expected only id and title, got %v", doc)
+	}
+
+	// 环境变量覆盖为缩进格式，未指定 fields 时返回全部字段
+	server.jsonStyle = jsonStylePretty
+	text = server.toolListDocuments(nil).Content[0].Text
+	if !strings.Contains(text, "\n  ") || !strings.Contains(text, `"tags"`) {
+		t.Errorf("Expected pretty JSON with all fields, got %q", text)
+	}
+}
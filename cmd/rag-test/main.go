@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,7 +28,7 @@ func main() {
 	count, _ := ragService.GetIndexedCount()
 	if count == 0 {
 		fmt.Println("📦 索引为空，开始重建...")
-		indexed, err := ragService.ReindexAll()
+		indexed, err := ragService.ReindexAll(context.Background())
 		if err != nil {
 			fmt.Printf("❌ 重建失败: %v\n", err)
 			return
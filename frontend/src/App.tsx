@@ -21,8 +21,10 @@ import { useExternalLinks } from "./hooks/app/useExternalLinks";
 import { useFileWatcher } from "./hooks/file/useFileWatcher";
 import { useKeyboardNavigation, useFocusZone } from "./hooks/ui/useKeyboardNavigation";
 import { useExternalFileHandler } from "./hooks/file/useExternalFileHandler";
-import { WarmupRAG } from "../wailsjs/go/main/App";
+import { useConfirmModal } from "./hooks/ui/useConfirmModal";
+import { WarmupRAG, ExportSearchResults, ExportVault, GetTrash, RestoreDocument, EmptyTrash } from "../wailsjs/go/main/App";
 import { useUpdateCheck } from "./hooks/app/useUpdateCheck";
+import { useStartupValidation } from "./hooks/app/useStartupValidation";
 
 import { getStrings } from "./constants/strings";
 import "./App.css";
@@ -63,6 +65,7 @@ function AppContent() {
 
   // 启动时自动检查更新
   useUpdateCheck();
+  useStartupValidation();
 
   const {
     documents,
@@ -82,7 +85,9 @@ function AppContent() {
 
   const {
     pinTag,
+    selectedTag,
     setSelectedTag,
+    refreshTags,
   } = useTagContext();
 
   const [status, setStatus] = useState<string>("");
@@ -172,7 +177,7 @@ function AppContent() {
   }, [activeId, resetTitleSync]);
 
   // 搜索上下文 - 用于 Cmd+K 选中文本填充
-  const { setQueryWithFocus } = useSearchContext();
+  const { query: searchQuery, setQueryWithFocus } = useSearchContext();
 
   // 区域 refs（用于焦点管理）
   const sidebarRef = useRef<HTMLElement>(null);
@@ -181,10 +186,11 @@ function AppContent() {
   // === 回调函数定义（必须在 hooks 之前定义） ===
 
   // 菜单事件处理
+  // 在标签筛选视图中新建时，文档直接带上当前标签
   const handleCreateInternalDocument = useCallback(() => {
     deactivateExternal();
-    createDoc();
-  }, [createDoc, deactivateExternal]);
+    createDoc(undefined, selectedTag ?? undefined);
+  }, [createDoc, deactivateExternal, selectedTag]);
 
   const handleToggleSidebar = useCallback(() => {
     setSidebarCollapsed((prev) => !prev);
@@ -229,11 +235,17 @@ function AppContent() {
     enabled: !settingsOpen,
   });
 
-  const { handleImport } = useImport({
+  const { handleImport, handleImportFolder } = useImport({
     editorRef,
     createDoc,
     saveContent,
+    addTag,
     onContentChange: setContent,
+    onFolderImported: (result) => {
+      refreshDocuments();
+      refreshTags();
+      setStatus(`${STRINGS.STATUS.FOLDER_IMPORTED} ${result.imported} · ${STRINGS.STATUS.IMPORT_SKIPPED} ${result.skipped} · ${STRINGS.STATUS.IMPORT_FAILED} ${result.failed}`);
+    },
   });
 
   // 当前文档标题（需要在 useExport 之前计算）
@@ -243,7 +255,7 @@ function AppContent() {
     : activeDoc?.title || "";
 
   // 统一导出功能
-  const { handleExportMarkdown, handleExportHTML, handleCopyImage, handleSaveImage, handlePrint } = useExport({
+  const { handleExportMarkdown, handleExportHTML, handleExportPDF, handleCopyImage, handleSaveImage, handlePrint } = useExport({
     editorRef,
     documentTitle: currentTitle,
     documents,
@@ -252,6 +264,68 @@ function AppContent() {
     onError: (err) => console.error('Export failed:', err),
   });
 
+  // 导出当前语义搜索结果（JSON/CSV，由保存对话框决定）
+  const handleExportSearchResults = useCallback(async () => {
+    if (!searchQuery.trim()) {
+      setStatus(STRINGS.STATUS.EXPORT_SEARCH_EMPTY);
+      return;
+    }
+    try {
+      const result = await ExportSearchResults(searchQuery, 50, '');
+      if (result) {
+        setStatus(`${STRINGS.STATUS.SEARCH_EXPORTED} (${result.count})`);
+      }
+    } catch (err) {
+      console.error('Export search results failed:', err);
+    }
+  }, [searchQuery, setStatus, STRINGS]);
+
+  // 导出全部文档为 Markdown zip（备份/迁移）
+  const handleExportVault = useCallback(async () => {
+    try {
+      const result = await ExportVault('');
+      if (result) {
+        setStatus(`${STRINGS.STATUS.VAULT_EXPORTED} (${result.documents})`);
+      }
+    } catch (err) {
+      console.error('Export vault failed:', err);
+    }
+  }, [setStatus, STRINGS]);
+
+  // 回收站：恢复最近删除的文档
+  const handleRestoreDocument = useCallback(async () => {
+    try {
+      const trash = await GetTrash();
+      if (!trash || trash.length === 0) {
+        setStatus(STRINGS.STATUS.TRASH_EMPTY);
+        return;
+      }
+      const doc = await RestoreDocument(trash[0].id);
+      await refreshDocuments();
+      refreshTags();
+      await switchDoc(doc.id);
+      setStatus(`${STRINGS.STATUS.DOCUMENT_RESTORED}: ${doc.title}`);
+    } catch (err) {
+      console.error('Restore document failed:', err);
+    }
+  }, [refreshDocuments, refreshTags, switchDoc, setStatus, STRINGS]);
+
+  const { openModal: openConfirm, ConfirmModalComponent } = useConfirmModal();
+
+  const handleEmptyTrash = useCallback(() => {
+    openConfirm(
+      { title: STRINGS.MODALS.EMPTY_TRASH_TITLE, message: STRINGS.MODALS.EMPTY_TRASH_MESSAGE },
+      async () => {
+        try {
+          const count = await EmptyTrash();
+          setStatus(`${STRINGS.STATUS.TRASH_EMPTIED} (${count})`);
+        } catch (err) {
+          console.error('Empty trash failed:', err);
+        }
+      }
+    );
+  }, [openConfirm, setStatus, STRINGS]);
+
   // 外部文件操作
   const { handleOpenExternal, handleSwitchToExternal } = useExternalFileHandler({
     externalFiles,
@@ -291,14 +365,20 @@ function AppContent() {
     onNewDocument: handleCreateInternalDocument,
     onNewFolder: () => pinTag(STRINGS.DEFAULTS.NEW_PINNED_TAG),
     onImport: handleImport,
+    onImportFolder: handleImportFolder,
     onExport: handleExportMarkdown,
+    onExportVault: handleExportVault,
     onCopyImage: handleCopyImage,
     onSaveImage: handleSaveImage,
     onExportHTML: handleExportHTML,
+    onExportPDF: handleExportPDF,
+    onExportSearchResults: handleExportSearchResults,
     onPrint: handlePrint,
     onToggleSidebar: handleToggleSidebar,
     onAbout: handleAbout,
     onOpenExternal: handleOpenExternal,
+    onRestoreDocument: handleRestoreDocument,
+    onEmptyTrash: handleEmptyTrash,
     onSettings: handleSettings,
   });
 
@@ -371,6 +451,7 @@ function AppContent() {
         onClose={() => setSettingsOpen(false)}
         initialTab={settingsTab}
       />
+      <ConfirmModalComponent />
     </div>
   );
 }
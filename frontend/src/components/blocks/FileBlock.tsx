@@ -2,7 +2,7 @@ import { createReactBlockSpec } from "@blocknote/react";
 import { defaultProps } from "@blocknote/core";
 import { useCallback, useState, useEffect } from "react";
 import { FileText, File, Loader2, Check, AlertCircle, RefreshCw, ExternalLink, Eye, Replace, Archive, ArchiveRestore, RefreshCcw, Link, AlertTriangle, FolderOpen } from "lucide-react";
-import { OpenFileWithSystem, IndexFileContent, GetExternalBlockContent, OpenFileDialog, ArchiveFile, UnarchiveFile, SyncArchivedFile, CheckFileExists, RevealInFinder, GetEffectiveFilePath } from "../../../wailsjs/go/main/App";
+import { OpenFileWithSystem, IndexFileContent, GetExternalBlockContent, OpenFileDialog, ArchiveFile, UnarchiveFile, SyncArchivedFile, CheckFileExists, IsArchiveStale, RevealInFinder, GetEffectiveFilePath } from "../../../wailsjs/go/main/App";
 import { useDocumentContext } from "../../contexts/DocumentContext";
 import { ContentViewerModal } from "../modals/ContentViewerModal";
 import "../../styles/ExternalBlock.css";
@@ -58,6 +58,8 @@ const FileBlockComponent = (props: { block: any, editor: any }) => {
     // 归档操作状态
     const [archiving, setArchiving] = useState(false);
     const [syncing, setSyncing] = useState(false);
+    // 原文件在归档后是否被修改
+    const [archiveStale, setArchiveStale] = useState(false);
     // Windows 平台检测（Windows 上文件拖拽已自动归档，无需手动归档/取消归档按钮）
     const isWindows = navigator.userAgent.includes("Windows");
 
@@ -81,6 +83,17 @@ const FileBlockComponent = (props: { block: any, editor: any }) => {
         checkFile();
     }, [effectivePath, isLegacyData, block.id, editor]);
 
+    // 检查归档副本是否落后于原文件
+    useEffect(() => {
+        if (!archived || !effectivePath || !archivedPath) {
+            setArchiveStale(false);
+            return;
+        }
+        IsArchiveStale(effectivePath, archivedPath, archivedAt || 0)
+            .then(setArchiveStale)
+            .catch(() => setArchiveStale(false));
+    }, [archived, effectivePath, archivedPath, archivedAt]);
+
     // 选择新文件替换
     const handleSelectFile = useCallback(async () => {
         try {
@@ -260,10 +273,12 @@ const FileBlockComponent = (props: { block: any, editor: any }) => {
                     editor.updateBlock(currentBlock, {
                         props: {
                             ...currentBlock.props,
+                            archivedPath: result.archivedPath,
                             archivedAt: result.archivedAt,
                         },
                     });
                 }
+                setArchiveStale(false);
             }
         } catch (err) {
             console.error("Failed to sync archived file:", err);
@@ -393,8 +408,8 @@ const FileBlockComponent = (props: { block: any, editor: any }) => {
                     ) : (
                         <>
                             <button
-                                className="external-action-btn"
-                                title={syncing ? "Syncing..." : "Sync from original"}
+                                className={`external-action-btn ${archiveStale ? "stale" : ""}`}
+                                title={syncing ? "Syncing..." : archiveStale ? "Original changed - sync archive" : "Sync from original"}
                                 disabled={syncing || fileMissing}
                                 onClick={(e) => {
                                     e.preventDefault();
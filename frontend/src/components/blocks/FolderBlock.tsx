@@ -1,12 +1,21 @@
 import { createReactBlockSpec } from "@blocknote/react";
 import { defaultProps } from "@blocknote/core";
-import { useCallback } from "react";
+import { useCallback, useEffect, useState } from "react";
 import { Folder, Loader2, Check, AlertCircle, RefreshCw, Replace, Plus, ExternalLink, Eye } from "lucide-react";
 import { IndexFolderContent, SelectFolderDialog, RevealInFinder, OpenFileWithSystem } from "../../../wailsjs/go/main/App";
+import { EventsOn } from "../../../wailsjs/runtime/runtime";
 import { useDocumentContext } from "../../contexts/DocumentContext";
 import "../../styles/ExternalBlock.css";
 import "../../styles/FolderBlock.css";
 
+// 文件夹索引进度（rag:folder-index-progress 事件）
+interface FolderIndexProgress {
+    blockId: string;
+    extracted: number;
+    indexed: number;
+    total: number;
+}
+
 // eslint-disable-next-line @typescript-eslint/no-explicit-any
 const FolderBlockComponent = (props: { block: any, editor: any }) => {
     const { block, editor } = props;
@@ -22,6 +31,21 @@ const FolderBlockComponent = (props: { block: any, editor: any }) => {
         indexError
     } = block.props;
     const { activeId } = useDocumentContext();
+    const [progress, setProgress] = useState<FolderIndexProgress | null>(null);
+
+    // 监听索引进度（仅本块）
+    useEffect(() => {
+        if (!indexing) {
+            setProgress(null);
+            return;
+        }
+        const unsubscribe = EventsOn('rag:folder-index-progress', (p: FolderIndexProgress) => {
+            if (p.blockId === block.id) {
+                setProgress(p);
+            }
+        });
+        return () => unsubscribe();
+    }, [indexing, block.id]);
 
     // 选择文件夹
     const handleSelectFolder = useCallback(async () => {
@@ -160,7 +184,7 @@ const FolderBlockComponent = (props: { block: any, editor: any }) => {
             <div className="external-actions">
                 <button
                     className={`external-action-btn ${indexed ? "indexed" : ""} ${indexError ? "index-error" : ""}`}
-                    title={indexing ? "Indexing..." : indexed ? "Re-index" : indexError ? "Indexing failed, retry?" : "Index folder"}
+                    title={indexing ? (progress && progress.total > 0 ? `Indexing ${progress.indexed}/${progress.total} (extracted ${progress.extracted})...` : "Indexing...") : indexed ? "Re-index" : indexError ? "Indexing failed, retry?" : "Index folder"}
                     disabled={indexing}
                     onClick={(e) => {
                         e.preventDefault();
@@ -1,5 +1,5 @@
 import { Pencil, ExternalLink, RefreshCw, Check, Loader2, AlertCircle, Eye } from "lucide-react";
-import { BrowserOpenURL } from "../../../../wailsjs/runtime/runtime";
+import { OpenURL } from "../../../../wailsjs/go/main/App";
 
 interface BookmarkCardProps {
     title?: string;
@@ -34,7 +34,7 @@ export const BookmarkCard = ({
         <div
             className={`external-block external-card ${indexed ? 'indexed' : ''} ${indexError ? 'index-error' : ''} bookmark-card-custom`}
             contentEditable={false}
-            onDoubleClick={() => BrowserOpenURL(url)}
+            onDoubleClick={() => OpenURL(url)}
         >
             <div className="bookmark-content">
                 <div className="bookmark-title">{title || url}</div>
@@ -118,7 +118,7 @@ export const BookmarkCard = ({
                     onClick={(e) => {
                         e.preventDefault();
                         e.stopPropagation();
-                        BrowserOpenURL(url);
+                        OpenURL(url);
                     }}
                 >
                     <ExternalLink size={14} />
@@ -76,7 +76,7 @@ export const useBookmarkState = (block: any, editor: any, activeId: string | nul
                                     props: { ...blockForIndex.props, indexing: true, indexError: "" },
                                 });
                             }
-                            await IndexBookmarkContent(url, activeId, block.id);
+                            await IndexBookmarkContent(url, activeId, block.id, 0);
                             const latestBlock = editor.getBlock(block.id);
                             if (latestBlock) {
                                 editor.updateBlock(latestBlock, {
@@ -128,7 +128,7 @@ export const useBookmarkState = (block: any, editor: any, activeId: string | nul
         });
 
         try {
-            await IndexBookmarkContent(urlToIndex, activeId, block.id);
+            await IndexBookmarkContent(urlToIndex, activeId, block.id, 0);
             const latestBlock = editor.getBlock(block.id);
             if (latestBlock) {
                 editor.updateBlock(latestBlock, {
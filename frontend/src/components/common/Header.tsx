@@ -1,19 +1,24 @@
+import { ReactNode } from 'react';
 import { CheckCircle } from 'lucide-react';
 
 interface HeaderProps {
     title: string;
     status?: string;
     showTitle?: boolean;
+    /** 左侧的文档操作按钮 */
+    actions?: ReactNode;
 }
 
 export function Header({
     title,
     status,
     showTitle = true,
+    actions,
 }: HeaderProps) {
     return (
         <header className={`app-header ${showTitle ? '' : 'header-transparent'}`}>
             <div className="header-left">
+                {actions}
             </div>
             <div className="header-center">
                 <h1 className={`header-title ${showTitle ? '' : 'header-title-hidden'}`}>
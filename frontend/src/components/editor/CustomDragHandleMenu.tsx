@@ -18,11 +18,11 @@ import {
     Pencil,
 } from "lucide-react";
 import { ReactNode } from "react";
-import { BrowserOpenURL } from "../../../wailsjs/runtime/runtime";
 import {
     OpenFileWithSystem,
     RevealInFinder,
     OpenFileDialog,
+    OpenURL,
 } from "../../../wailsjs/go/main/App";
 
 // ========== 通用菜单项组件 ==========
@@ -154,7 +154,7 @@ function OpenLinkItem({ children }: { children: ReactNode }) {
 
     return (
         <Components.Generic.Menu.Item
-            onClick={() => BrowserOpenURL(url)}
+            onClick={() => OpenURL(url)}
         >
             <ExternalLink size={16} style={{ marginRight: 8 }} />
             {children}
@@ -15,13 +15,13 @@ import {
     Sparkles,
     Replace,
 } from "lucide-react";
-import { BrowserOpenURL } from "../../../wailsjs/runtime/runtime";
 import {
     OpenFileWithSystem,
     RevealInFinder,
     GetExternalBlockContent,
     OpenFileDialog,
     SelectFolderDialog,
+    OpenURL,
 } from "../../../wailsjs/go/main/App";
 import { useDocumentContext } from "../../contexts/DocumentContext";
 import { useSearchContext } from "../../contexts/SearchContext";
@@ -300,7 +300,7 @@ function OpenLinkButton({ url }: { url: string }) {
     const Components = useComponentsContext()!;
 
     const handleClick = () => {
-        if (url) BrowserOpenURL(url);
+        if (url) OpenURL(url);
     };
 
     return (
@@ -0,0 +1,117 @@
+import { memo, useEffect, useRef, useState } from 'react';
+import { Cpu, FolderOpen } from 'lucide-react';
+import { DocumentMeta } from '../../types/document';
+import { useAppStore } from '../../store/store';
+import { STRINGS } from '../../constants/strings';
+import { useToast } from '../common/Toast';
+import { RevealDocument } from '../../../wailsjs/go/main/App';
+import './DocumentActions.css';
+
+interface DocumentActionsProps {
+    doc: DocumentMeta;
+}
+
+/**
+ * 当前文档的操作按钮（显示在 Header 左侧）
+ * 在文件管理器中显示：定位文档的存储文件
+ * 嵌入模型覆盖：为单个文档指定 RAG 嵌入模型（如代码笔记使用代码模型），留空恢复默认模型
+ */
+export const DocumentActions = memo(function DocumentActions({ doc }: DocumentActionsProps) {
+    const setDocEmbedModel = useAppStore((state) => state.setDocEmbedModel);
+    const { showToast } = useToast();
+    const [isEditingModel, setIsEditingModel] = useState(false);
+    const [modelInput, setModelInput] = useState(doc.embedModel || '');
+    const wrapperRef = useRef<HTMLDivElement>(null);
+
+    // 切换文档或外部修改后同步输入框
+    useEffect(() => {
+        setModelInput(doc.embedModel || '');
+        setIsEditingModel(false);
+    }, [doc.id, doc.embedModel]);
+
+    // 点击外部关闭
+    useEffect(() => {
+        if (!isEditingModel) return;
+        const handlePointerDown = (e: PointerEvent) => {
+            if (wrapperRef.current && !wrapperRef.current.contains(e.target as Node)) {
+                setIsEditingModel(false);
+            }
+        };
+        document.addEventListener('pointerdown', handlePointerDown);
+        return () => document.removeEventListener('pointerdown', handlePointerDown);
+    }, [isEditingModel]);
+
+    const saveModel = async (model: string) => {
+        setIsEditingModel(false);
+        if (model.trim() === (doc.embedModel || '')) return;
+        try {
+            await setDocEmbedModel(doc.id, model);
+        } catch (e) {
+            showToast(`Failed to set embedding model: ${e}`, 'error');
+            setModelInput(doc.embedModel || '');
+        }
+    };
+
+    const handleReveal = async () => {
+        try {
+            await RevealDocument(doc.id);
+        } catch (e) {
+            showToast(`Failed to reveal document: ${e}`, 'error');
+        }
+    };
+
+    const handleKeyDown = (e: React.KeyboardEvent<HTMLInputElement>) => {
+        if (e.key === 'Enter') {
+            e.preventDefault();
+            saveModel(modelInput);
+        } else if (e.key === 'Escape') {
+            e.preventDefault();
+            setModelInput(doc.embedModel || '');
+            setIsEditingModel(false);
+        }
+    };
+
+    const modelTitle = `${STRINGS.TOOLTIPS.EMBED_MODEL}: ${doc.embedModel || STRINGS.LABELS.EMBED_MODEL_DEFAULT}`;
+
+    return (
+        <div className="document-actions" ref={wrapperRef}>
+            <button
+                className="document-action-btn"
+                onClick={handleReveal}
+                title={STRINGS.TOOLTIPS.REVEAL_DOCUMENT}
+                aria-label={STRINGS.TOOLTIPS.REVEAL_DOCUMENT}
+            >
+                <FolderOpen size={14} aria-hidden="true" />
+            </button>
+            <button
+                className={`document-action-btn ${doc.embedModel ? 'active' : ''}`}
+                onClick={() => setIsEditingModel((open) => !open)}
+                title={modelTitle}
+                aria-label={modelTitle}
+                aria-expanded={isEditingModel}
+            >
+                <Cpu size={14} aria-hidden="true" />
+                {doc.embedModel && <span className="document-action-label">{doc.embedModel}</span>}
+            </button>
+            {isEditingModel && (
+                <div className="document-actions-popover" role="dialog" aria-label={STRINGS.TOOLTIPS.EMBED_MODEL}>
+                    <input
+                        autoFocus
+                        value={modelInput}
+                        onChange={(e) => setModelInput(e.target.value)}
+                        onKeyDown={handleKeyDown}
+                        placeholder={STRINGS.LABELS.EMBED_MODEL_DEFAULT}
+                        aria-label={STRINGS.TOOLTIPS.EMBED_MODEL}
+                    />
+                    <p className="document-actions-hint">{STRINGS.LABELS.EMBED_MODEL_HINT}</p>
+                    <div className="document-actions-buttons">
+                        {doc.embedModel && (
+                            <button onClick={() => saveModel('')}>{STRINGS.LABELS.EMBED_MODEL_DEFAULT}</button>
+                        )}
+                        <button className="primary" onClick={() => saveModel(modelInput)}>{STRINGS.BUTTONS.SAVE}</button>
+                    </div>
+                </div>
+            )}
+        </div>
+    );
+});
@@ -1,6 +1,7 @@
 import { useMemo } from "react";
 import { Editor } from "./Editor";
 import { Header } from "../common/Header";
+import { DocumentActions } from "./DocumentActions";
 import { Block, BlockNoteEditor } from "@blocknote/core";
 import { DocumentMeta } from "../../types/document";
 import { getStrings } from "../../constants/strings";
@@ -77,6 +78,7 @@ export function EditorContainer({
         title={currentTitle}
         status={status}
         showTitle={showTitle}
+        actions={!isExternalMode && activeDoc ? <DocumentActions doc={activeDoc} /> : undefined}
       />
       <main className="editor-container">
         {isLoading || contentLoading ? (
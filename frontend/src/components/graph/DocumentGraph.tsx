@@ -104,7 +104,7 @@ export const DocumentGraph: React.FC<DocumentGraphProps> = ({
     const loadGraphData = useCallback(async () => {
         setLoading(true);
         try {
-            const data = await GetDocumentGraph(threshold);
+            const data = await GetDocumentGraph(threshold, false);
             if (data) {
                 // 为节点添加颜色
                 const nodes = (data.nodes || []).map((node: { id: string; type: string; title: string; tags?: string[]; val: number; parentDocId?: string; parentBlockId?: string }) => ({
@@ -8,9 +8,11 @@ interface AppearancePanelProps {
     themeSetting: ThemeSetting;
     sidebarWidth: number;
     fontSize: number;
+    defaultDocTemplate: string;
     onThemeChange: (theme: ThemeSetting) => void;
     onSidebarWidthChange: (width: number) => void;
     onFontSizeChange: (size: number) => void;
+    onDefaultDocTemplateChange: (template: string) => void;
     strings: ReturnType<typeof getStrings>;
 }
 
@@ -18,9 +20,11 @@ export const AppearancePanel: React.FC<AppearancePanelProps> = ({
     themeSetting,
     sidebarWidth,
     fontSize,
+    defaultDocTemplate,
     onThemeChange,
     onSidebarWidthChange,
     onFontSizeChange,
+    onDefaultDocTemplateChange,
     strings,
 }) => {
     const themeOptions: { value: ThemeSetting; label: string; icon: React.ReactNode }[] = [
@@ -108,6 +112,19 @@ export const AppearancePanel: React.FC<AppearancePanelProps> = ({
                         }}
                     />
                 </div>
+
+                {/* 新文档模板 */}
+                <div className="form-group">
+                    <label>{strings.SETTINGS.DEFAULT_DOC_TEMPLATE}</label>
+                    <textarea
+                        className="writing-style-textarea"
+                        value={defaultDocTemplate}
+                        onChange={(e) => onDefaultDocTemplateChange(e.target.value)}
+                        placeholder={strings.SETTINGS.DEFAULT_DOC_TEMPLATE_PLACEHOLDER}
+                        rows={8}
+                    />
+                    <p className="form-hint">{strings.SETTINGS.DEFAULT_DOC_TEMPLATE_HINT}</p>
+                </div>
             </div>
         </div>
     );
@@ -6,10 +6,13 @@ import type { EmbeddingConfig } from '../../types/settings';
 
 interface EmbeddingPanelProps {
     config: EmbeddingConfig;
-    onChange: (field: keyof EmbeddingConfig, value: string) => void;
+    onChange: (field: keyof EmbeddingConfig, value: string | string[]) => void;
     strings: ReturnType<typeof getStrings>;
 }
 
+// 可在设置中排除索引的块类型
+const EXCLUDABLE_BLOCK_TYPES = ['codeBlock', 'table', 'quote', 'checkListItem'] as const;
+
 export const EmbeddingPanel: React.FC<EmbeddingPanelProps> = ({
     config,
     onChange,
@@ -48,6 +51,13 @@ export const EmbeddingPanel: React.FC<EmbeddingPanelProps> = ({
         onChange('model', value);
     };
 
+    const handleExcludedTypeToggle = (blockType: string, excluded: boolean) => {
+        const current = config.excludedBlockTypes || [];
+        onChange('excludedBlockTypes', excluded
+            ? [...current.filter(t => t !== blockType), blockType]
+            : current.filter(t => t !== blockType));
+    };
+
     const handleTestConnection = useCallback(async () => {
         setIsTesting(true);
         setTestResult(null);
@@ -174,6 +184,23 @@ export const EmbeddingPanel: React.FC<EmbeddingPanelProps> = ({
                         </span>
                     )}
                 </div>
+
+                <div className="form-group">
+                    <label>{strings.SETTINGS.EXCLUDED_BLOCK_TYPES}</label>
+                    <div className="block-type-options">
+                        {EXCLUDABLE_BLOCK_TYPES.map((blockType) => (
+                            <label key={blockType} className="block-type-option">
+                                <input
+                                    type="checkbox"
+                                    checked={(config.excludedBlockTypes || []).includes(blockType)}
+                                    onChange={(e) => handleExcludedTypeToggle(blockType, e.target.checked)}
+                                />
+                                {strings.SETTINGS.BLOCK_TYPE_NAMES[blockType]}
+                            </label>
+                        ))}
+                    </div>
+                    <p className="form-hint">{strings.SETTINGS.EXCLUDED_BLOCK_TYPES_HINT}</p>
+                </div>
             </div>
         </div>
     );
@@ -1,12 +1,15 @@
 import React from 'react';
-import { RefreshCw } from 'lucide-react';
+import { RefreshCw, Minimize2, ShieldCheck } from 'lucide-react';
 import { getStrings } from '../../constants/strings';
-import type { RAGStatus } from '../../types/settings';
+import type { RAGStatus, ReindexPlan } from '../../types/settings';
 
 export interface ReindexProgress {
-    phase: 'documents' | 'external';
+    phase: 'documents' | 'external' | 'stale';
     current: number;
     total: number;
+    title?: string;
+    overallDone?: number;
+    overallTotal?: number;
 }
 
 interface KnowledgePanelProps {
@@ -14,6 +17,11 @@ interface KnowledgePanelProps {
     isRebuilding: boolean;
     progress: ReindexProgress | null;
     onRebuild: () => void;
+    plan: ReindexPlan | null;
+    isCompacting: boolean;
+    onCompact: () => void;
+    isVerifying: boolean;
+    onVerify: () => void;
     strings: ReturnType<typeof getStrings>;
 }
 
@@ -22,6 +30,11 @@ export const KnowledgePanel: React.FC<KnowledgePanelProps> = ({
     isRebuilding,
     progress,
     onRebuild,
+    plan,
+    isCompacting,
+    onCompact,
+    isVerifying,
+    onVerify,
     strings,
 }) => {
     // 获取进度显示文本
@@ -30,12 +43,17 @@ export const KnowledgePanel: React.FC<KnowledgePanelProps> = ({
         const phaseText = progress.phase === 'documents'
             ? (strings.SETTINGS.INDEXING_DOCUMENTS || 'Indexing documents')
             : (strings.SETTINGS.INDEXING_EXTERNAL || 'Indexing external content');
-        return `${phaseText} ${progress.current}/${progress.total}...`;
+        const title = progress.title ? ` · ${progress.title}` : '';
+        return `${phaseText} ${progress.current}/${progress.total}${title}...`;
     };
 
-    // 计算进度百分比
+    // 计算进度百分比（按所有阶段的合计进度，避免进入外部内容阶段时进度条回退）
     const getProgressPercent = () => {
-        if (!progress || progress.total === 0) return 0;
+        if (!progress) return 0;
+        if (progress.overallTotal) {
+            return Math.round(((progress.overallDone ?? 0) / progress.overallTotal) * 100);
+        }
+        if (progress.total === 0) return 0;
         return Math.round((progress.current / progress.total) * 100);
     };
 
@@ -68,6 +86,22 @@ export const KnowledgePanel: React.FC<KnowledgePanelProps> = ({
                         {status.indexedFolders || 0}
                     </span>
                 </div>
+                {plan && (
+                    <>
+                        <div className="status-row">
+                            <span className="status-label">{strings.SETTINGS.PENDING_CHANGES}</span>
+                            <span className="status-value">
+                                +{plan.add} / ~{plan.update} / -{plan.delete} {strings.SETTINGS.CHUNKS}
+                            </span>
+                        </div>
+                        <div className="status-row">
+                            <span className="status-label">{strings.SETTINGS.REBUILD_COST}</span>
+                            <span className="status-value">
+                                {plan.rebuildEmbeddings} {strings.SETTINGS.EMBEDDINGS} ({plan.rebuildEmbedRequests} {strings.SETTINGS.REQUESTS})
+                            </span>
+                        </div>
+                    </>
+                )}
                 {status.lastIndexTime && (
                     <div className="status-row">
                         <span className="status-label">{strings.SETTINGS.LAST_UPDATE}</span>
@@ -102,6 +136,30 @@ export const KnowledgePanel: React.FC<KnowledgePanelProps> = ({
                             : strings.SETTINGS.REBUILD_INDEX}
                     </span>
                 </button>
+                <button
+                    className="settings-action-btn"
+                    onClick={onCompact}
+                    disabled={isRebuilding || isCompacting}
+                >
+                    <Minimize2 size={16} />
+                    <span>
+                        {isCompacting
+                            ? strings.SETTINGS.COMPACTING
+                            : strings.SETTINGS.COMPACT_INDEX}
+                    </span>
+                </button>
+                <button
+                    className="settings-action-btn"
+                    onClick={onVerify}
+                    disabled={isRebuilding || isVerifying}
+                >
+                    <ShieldCheck size={16} />
+                    <span>
+                        {isVerifying
+                            ? strings.SETTINGS.VERIFYING
+                            : strings.SETTINGS.VERIFY_INDEX}
+                    </span>
+                </button>
             </div>
         </div>
     );
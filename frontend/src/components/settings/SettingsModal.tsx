@@ -1,10 +1,10 @@
 import React, { useState, useEffect, useRef } from 'react';
 import { useSettings } from '../../contexts/SettingsContext';
 import { X, Database, Bot, Palette, Terminal, Info, Network } from 'lucide-react';
-import { GetRAGConfig, SaveRAGConfig, GetRAGStatus, RebuildIndex, GetMCPInfo } from '../../../wailsjs/go/main/App';
+import { GetRAGConfig, SaveRAGConfig, GetRAGStatus, RebuildIndex, CompactIndex, VerifyIndex, RepairIndex, PlanReindex, GetMCPInfo } from '../../../wailsjs/go/main/App';
 import { EventsOn } from '../../../wailsjs/runtime/runtime';
 import { getStrings } from '../../constants/strings';
-import type { EmbeddingConfig, RAGStatus, MCPInfo } from '../../types/settings';
+import type { EmbeddingConfig, RAGStatus, MCPInfo, ReindexPlan } from '../../types/settings';
 import { AppearancePanel } from './AppearancePanel';
 import { KnowledgePanel, ReindexProgress } from './KnowledgePanel';
 import { EmbeddingPanel } from './EmbeddingPanel';
@@ -12,6 +12,7 @@ import { MCPPanel } from './MCPPanel';
 import { AboutPanel } from './AboutPanel';
 import { DocumentGraph } from '../graph/DocumentGraph';
 import { useToast } from '../common/Toast';
+import { useConfirmModal } from '../../hooks/ui/useConfirmModal';
 import './SettingsModal.css';
 
 interface SettingsModalProps {
@@ -23,8 +24,9 @@ interface SettingsModalProps {
 export type SettingsTab = 'appearance' | 'embedding' | 'knowledge' | 'graph' | 'mcp' | 'about';
 
 export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, initialTab }) => {
-    const { theme, themeSetting, setThemeSetting, language, sidebarWidth, setSidebarWidth, fontSize, setFontSize, writingStyle, setWritingStyle } = useSettings();
+    const { theme, themeSetting, setThemeSetting, language, sidebarWidth, setSidebarWidth, fontSize, setFontSize, writingStyle, setWritingStyle, defaultDocTemplate, setDefaultDocTemplate } = useSettings();
     const { showToast } = useToast();
+    const { isOpen: isConfirmOpen, openModal, ConfirmModalComponent } = useConfirmModal();
     const STRINGS = getStrings(language);
     const modalRef = useRef<HTMLDivElement>(null);
 
@@ -36,6 +38,29 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         apiKey: '',
         maxChunkSize: 512,
         overlap: 50,
+        shortBlockThreshold: 0,
+        maxMergedLength: 0,
+        chunkUnit: 'chars',
+        sentenceDelimiters: '',
+        fetchTimeout: 10,
+        usePrefixes: false,
+        autoReindex: false,
+        autoReindexInterval: 30,
+        reindexWorkers: 0,
+        extractWorkers: 0,
+        pdfOcr: false,
+        maxExtractBytes: 0,
+        csvMaxRows: 0,
+        excludedBlockTypes: [],
+        mmrLambda: 0.7,
+        embedTitles: false,
+        titleBoost: 0.1,
+        retryMaxAttempts: 3,
+        retryBaseDelayMs: 500,
+        retryJitter: 0.2,
+        preprocessLowercase: false,
+        preprocessNormalizeWhitespace: false,
+        preprocessStripMarkdown: false,
     });
     const [status, setStatus] = useState<RAGStatus>({
         enabled: false,
@@ -47,7 +72,10 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         lastIndexTime: '',
     });
     const [isRebuilding, setIsRebuilding] = useState(false);
+    const [isCompacting, setIsCompacting] = useState(false);
+    const [isVerifying, setIsVerifying] = useState(false);
     const [rebuildProgress, setRebuildProgress] = useState<ReindexProgress | null>(null);
+    const [reindexPlan, setReindexPlan] = useState<ReindexPlan | null>(null);
     const [isSaving, setIsSaving] = useState(false);
     const [hasChanges, setHasChanges] = useState(false);
     const [originalConfig, setOriginalConfig] = useState<EmbeddingConfig | null>(null);
@@ -92,6 +120,17 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         } catch (err) {
             console.error('Failed to load settings:', err);
         }
+        loadReindexPlan();
+    };
+
+    // 预估重建开销（不调用嵌入服务，单独加载以免拖慢设置面板）
+    const loadReindexPlan = async () => {
+        try {
+            setReindexPlan(await PlanReindex());
+        } catch (err) {
+            console.warn('Failed to plan reindex:', err);
+            setReindexPlan(null);
+        }
     };
 
     // 键盘事件处理
@@ -99,17 +138,18 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         if (!isOpen) return;
 
         const handleKeyDown = (e: KeyboardEvent) => {
-            if (e.key === 'Escape') {
+            // 确认框打开时 Escape 只关闭确认框
+            if (e.key === 'Escape' && !isConfirmOpen) {
                 onClose();
             }
         };
 
         document.addEventListener('keydown', handleKeyDown);
         return () => document.removeEventListener('keydown', handleKeyDown);
-    }, [isOpen, onClose]);
+    }, [isOpen, onClose, isConfirmOpen]);
 
     // 配置变更检测
-    const handleConfigChange = (field: keyof EmbeddingConfig, value: string) => {
+    const handleConfigChange = (field: keyof EmbeddingConfig, value: string | string[]) => {
         setConfig(prev => ({ ...prev, [field]: value }));
         setHasChanges(true);
     };
@@ -119,7 +159,8 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         setIsSaving(true);
         const modelChanged = originalConfig && originalConfig.model !== config.model;
         try {
-            await SaveRAGConfig(config);
+            // 传入加载时的配置，后端只保存修改过的字段
+            await SaveRAGConfig(originalConfig ?? config, config);
             setOriginalConfig(config);
             setHasChanges(false);
 
@@ -149,10 +190,16 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         });
 
         try {
-            await RebuildIndex();
+            const result = await RebuildIndex();
+            const failed = result.documents.failed;
+            if (failed.length > 0) {
+                const first = failed[0];
+                showToast(`${failed.length} ${STRINGS.SETTINGS.REBUILD_FAILED_DOCS} ${first.title || first.docId}: ${first.error}`, 'warning');
+            }
             // 刷新状态
             const statusData = await GetRAGStatus();
             setStatus(statusData);
+            loadReindexPlan();
         } catch (err) {
             console.error('Failed to rebuild index:', err);
             const errorMessage = err instanceof Error ? err.message : String(err);
@@ -164,149 +211,217 @@ export const SettingsModal: React.FC<SettingsModalProps> = ({ isOpen, onClose, i
         }
     };
 
+    // 压缩向量库（清理孤儿向量并 VACUUM）
+    const handleCompact = async () => {
+        setIsCompacting(true);
+        try {
+            const result = await CompactIndex();
+            const mb = (result.bytesReclaimed / (1024 * 1024)).toFixed(1);
+            showToast(`${STRINGS.SETTINGS.COMPACT_DONE} ${mb} MB`, 'success');
+        } catch (err) {
+            console.error('Failed to compact index:', err);
+            const errorMessage = err instanceof Error ? err.message : String(err);
+            showToast(`Compact database failed: ${errorMessage}`, 'error');
+        } finally {
+            setIsCompacting(false);
+        }
+    };
+
+    // 检查向量库一致性，发现悬空记录时确认后再修复（修复会删除索引记录）
+    const handleVerify = async () => {
+        setIsVerifying(true);
+        try {
+            const report = await VerifyIndex();
+            const issues = report.orphanedMetadata + report.orphanedVectors + report.dimensionMismatch;
+            if (issues === 0) {
+                showToast(STRINGS.SETTINGS.VERIFY_OK, 'success');
+                return;
+            }
+            openModal(
+                {
+                    title: STRINGS.SETTINGS.REPAIR_INDEX_TITLE,
+                    message: `${STRINGS.SETTINGS.REPAIR_INDEX_FOUND} ${issues} (${report.orphanedMetadata} / ${report.orphanedVectors} / ${report.dimensionMismatch}). ${STRINGS.SETTINGS.REPAIR_INDEX_MESSAGE}`,
+                },
+                handleRepair
+            );
+        } catch (err) {
+            console.error('Failed to verify index:', err);
+            const errorMessage = err instanceof Error ? err.message : String(err);
+            showToast(`Verify index failed: ${errorMessage}`, 'error');
+        } finally {
+            setIsVerifying(false);
+        }
+    };
+
+    // 删除不一致的索引记录
+    const handleRepair = async () => {
+        setIsVerifying(true);
+        try {
+            const repaired = await RepairIndex();
+            showToast(`${STRINGS.SETTINGS.VERIFY_REPAIRED} ${repaired.repaired}`, 'warning');
+            setStatus(await GetRAGStatus());
+        } catch (err) {
+            console.error('Failed to repair index:', err);
+            const errorMessage = err instanceof Error ? err.message : String(err);
+            showToast(`Repair index failed: ${errorMessage}`, 'error');
+        } finally {
+            setIsVerifying(false);
+        }
+    };
+
     if (!isOpen) return null;
 
     return (
-        <div className={`settings-overlay ${theme}`} onClick={onClose}>
-            <div
-                ref={modalRef}
-                className={`settings-modal ${theme}`}
-                onClick={(e) => e.stopPropagation()}
-                role="dialog"
-                aria-modal="true"
-                aria-labelledby="settings-title"
-            >
-                {/* 标题栏 */}
-                <div className="settings-header">
-                    <h2 id="settings-title">
-                        {STRINGS.SETTINGS.TITLE}
-                    </h2>
-                    <button className="settings-close" onClick={onClose} aria-label="Close">
-                        <X size={18} />
-                    </button>
-                </div>
-
-                <div className="settings-body">
-                    {/* 侧边栏 */}
-                    <nav className="settings-sidebar">
-                        <button
-                            className={`settings-nav-item ${activeTab === 'appearance' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('appearance')}
-                        >
-                            <Palette size={18} />
-                            <span>{STRINGS.SETTINGS.APPEARANCE}</span>
-                        </button>
-                        <button
-                            className={`settings-nav-item ${activeTab === 'embedding' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('embedding')}
-                        >
-                            <Bot size={18} />
-                            <span>{STRINGS.SETTINGS.EMBEDDING_MODEL}</span>
-                        </button>
-                        <button
-                            className={`settings-nav-item ${activeTab === 'knowledge' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('knowledge')}
-                        >
-                            <Database size={18} />
-                            <span>{STRINGS.SETTINGS.KNOWLEDGE_BASE}</span>
-                        </button>
-                        <button
-                            className={`settings-nav-item ${activeTab === 'graph' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('graph')}
-                        >
-                            <Network size={18} />
-                            <span>Graph</span>
-                        </button>
-                        <button
-                            className={`settings-nav-item ${activeTab === 'mcp' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('mcp')}
-                        >
-                            <Terminal size={18} />
-                            <span>{STRINGS.MCP.TITLE}</span>
-                        </button>
-                        <button
-                            className={`settings-nav-item ${activeTab === 'about' ? 'active' : ''}`}
-                            onClick={() => setActiveTab('about')}
-                        >
-                            <Info size={18} />
-                            <span>{STRINGS.ABOUT.TITLE}</span>
+        <>
+            <div className={`settings-overlay ${theme}`} onClick={onClose}>
+                <div
+                    ref={modalRef}
+                    className={`settings-modal ${theme}`}
+                    onClick={(e) => e.stopPropagation()}
+                    role="dialog"
+                    aria-modal="true"
+                    aria-labelledby="settings-title"
+                >
+                    {/* 标题栏 */}
+                    <div className="settings-header">
+                        <h2 id="settings-title">
+                            {STRINGS.SETTINGS.TITLE}
+                        </h2>
+                        <button className="settings-close" onClick={onClose} aria-label="Close">
+                            <X size={18} />
                         </button>
-                    </nav>
+                    </div>
 
-                    {/* 主内容区域 (包含内容和底部按钮) */}
-                    <div className="settings-main">
-                        {/* 内容区 */}
-                        <div className="settings-content">
-                            {activeTab === 'appearance' && (
-                                <AppearancePanel
-                                    themeSetting={themeSetting}
-                                    sidebarWidth={sidebarWidth}
-                                    fontSize={fontSize}
-                                    onThemeChange={setThemeSetting}
-                                    onSidebarWidthChange={setSidebarWidth}
-                                    onFontSizeChange={setFontSize}
-                                    strings={STRINGS}
-                                />
-                            )}
-                            {activeTab === 'knowledge' && (
-                                <KnowledgePanel
-                                    status={status}
-                                    isRebuilding={isRebuilding}
-                                    progress={rebuildProgress}
-                                    onRebuild={handleRebuild}
-                                    strings={STRINGS}
-                                />
-                            )}
-                            {activeTab === 'graph' && (
-                                <DocumentGraph
-                                    onNodeClick={(docId, blockId) => {
-                                        onClose();
-                                        window.dispatchEvent(new CustomEvent('navigate-to-doc', {
-                                            detail: { docId, blockId }
-                                        }));
-                                    }}
-                                />
-                            )}
+                    <div className="settings-body">
+                        {/* 侧边栏 */}
+                        <nav className="settings-sidebar">
+                            <button
+                                className={`settings-nav-item ${activeTab === 'appearance' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('appearance')}
+                            >
+                                <Palette size={18} />
+                                <span>{STRINGS.SETTINGS.APPEARANCE}</span>
+                            </button>
+                            <button
+                                className={`settings-nav-item ${activeTab === 'embedding' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('embedding')}
+                            >
+                                <Bot size={18} />
+                                <span>{STRINGS.SETTINGS.EMBEDDING_MODEL}</span>
+                            </button>
+                            <button
+                                className={`settings-nav-item ${activeTab === 'knowledge' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('knowledge')}
+                            >
+                                <Database size={18} />
+                                <span>{STRINGS.SETTINGS.KNOWLEDGE_BASE}</span>
+                            </button>
+                            <button
+                                className={`settings-nav-item ${activeTab === 'graph' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('graph')}
+                            >
+                                <Network size={18} />
+                                <span>Graph</span>
+                            </button>
+                            <button
+                                className={`settings-nav-item ${activeTab === 'mcp' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('mcp')}
+                            >
+                                <Terminal size={18} />
+                                <span>{STRINGS.MCP.TITLE}</span>
+                            </button>
+                            <button
+                                className={`settings-nav-item ${activeTab === 'about' ? 'active' : ''}`}
+                                onClick={() => setActiveTab('about')}
+                            >
+                                <Info size={18} />
+                                <span>{STRINGS.ABOUT.TITLE}</span>
+                            </button>
+                        </nav>
+
+                        {/* 主内容区域 (包含内容和底部按钮) */}
+                        <div className="settings-main">
+                            {/* 内容区 */}
+                            <div className="settings-content">
+                                {activeTab === 'appearance' && (
+                                    <AppearancePanel
+                                        themeSetting={themeSetting}
+                                        sidebarWidth={sidebarWidth}
+                                        fontSize={fontSize}
+                                        defaultDocTemplate={defaultDocTemplate}
+                                        onThemeChange={setThemeSetting}
+                                        onSidebarWidthChange={setSidebarWidth}
+                                        onFontSizeChange={setFontSize}
+                                        onDefaultDocTemplateChange={setDefaultDocTemplate}
+                                        strings={STRINGS}
+                                    />
+                                )}
+                                {activeTab === 'knowledge' && (
+                                    <KnowledgePanel
+                                        status={status}
+                                        isRebuilding={isRebuilding}
+                                        progress={rebuildProgress}
+                                        onRebuild={handleRebuild}
+                                        plan={reindexPlan}
+                                        isCompacting={isCompacting}
+                                        onCompact={handleCompact}
+                                        isVerifying={isVerifying}
+                                        onVerify={handleVerify}
+                                        strings={STRINGS}
+                                    />
+                                )}
+                                {activeTab === 'graph' && (
+                                    <DocumentGraph
+                                        onNodeClick={(docId, blockId) => {
+                                            onClose();
+                                            window.dispatchEvent(new CustomEvent('navigate-to-doc', {
+                                                detail: { docId, blockId }
+                                            }));
+                                        }}
+                                    />
+                                )}
+                                {activeTab === 'embedding' && (
+                                    <EmbeddingPanel
+                                        config={config}
+                                        onChange={handleConfigChange}
+                                        strings={STRINGS}
+                                    />
+                                )}
+                                {activeTab === 'mcp' && (
+                                    <MCPPanel
+                                        mcpInfo={mcpInfo}
+                                        writingStyle={writingStyle}
+                                        onWritingStyleChange={setWritingStyle}
+                                        strings={STRINGS}
+                                    />
+                                )}
+                                {activeTab === 'about' && (
+                                    <AboutPanel
+                                        strings={STRINGS}
+                                    />
+                                )}
+                            </div>
+
+                            {/* 底部按钮 - 仅 Embedding 面板需要手动保存 */}
                             {activeTab === 'embedding' && (
-                                <EmbeddingPanel
-                                    config={config}
-                                    onChange={handleConfigChange}
-                                    strings={STRINGS}
-                                />
-                            )}
-                            {activeTab === 'mcp' && (
-                                <MCPPanel
-                                    mcpInfo={mcpInfo}
-                                    writingStyle={writingStyle}
-                                    onWritingStyleChange={setWritingStyle}
-                                    strings={STRINGS}
-                                />
-                            )}
-                            {activeTab === 'about' && (
-                                <AboutPanel
-                                    strings={STRINGS}
-                                />
+                                <div className="settings-footer">
+                                    <button className="settings-btn cancel" onClick={onClose}>
+                                        {STRINGS.BUTTONS.CANCEL}
+                                    </button>
+                                    <button
+                                        className="settings-btn save"
+                                        onClick={handleSave}
+                                        disabled={!hasChanges || isSaving}
+                                    >
+                                        {isSaving ? STRINGS.SETTINGS.SAVING : STRINGS.BUTTONS.SAVE}
+                                    </button>
+                                </div>
                             )}
                         </div>
-
-                        {/* 底部按钮 - 仅 Embedding 面板需要手动保存 */}
-                        {activeTab === 'embedding' && (
-                            <div className="settings-footer">
-                                <button className="settings-btn cancel" onClick={onClose}>
-                                    {STRINGS.BUTTONS.CANCEL}
-                                </button>
-                                <button
-                                    className="settings-btn save"
-                                    onClick={handleSave}
-                                    disabled={!hasChanges || isSaving}
-                                >
-                                    {isSaving ? STRINGS.SETTINGS.SAVING : STRINGS.BUTTONS.SAVE}
-                                </button>
-                            </div>
-                        )}
                     </div>
                 </div>
             </div>
-        </div>
+            <ConfirmModalComponent />
+        </>
     );
 };
@@ -177,8 +177,8 @@ export function Sidebar({
   }, [onSelectInternal, switchDoc]);
 
   const handleCreate = useCallback(() => {
-    createDoc();
-  }, [createDoc]);
+    createDoc(undefined, selectedTag ?? undefined);
+  }, [createDoc, selectedTag]);
 
   const handleCreateInPinnedTag = useCallback(async (tagName: string) => {
     await createDoc(STRINGS.DEFAULTS.UNTITLED, tagName);
@@ -2,6 +2,7 @@ import { useRef, forwardRef, useImperativeHandle, useEffect } from 'react';
 import { Search, X } from 'lucide-react';
 import { STRINGS } from '../../constants/strings';
 import { useSearchContext } from '../../contexts/SearchContext';
+import { useSelectedTag } from '../../store/store';
 
 interface SidebarSearchProps {
     onQueryChange: (query: string) => void;
@@ -13,7 +14,12 @@ export interface SidebarSearchRef {
 
 export const SidebarSearch = forwardRef<SidebarSearchRef, SidebarSearchProps>(({ onQueryChange }, ref) => {
     const inputRef = useRef<HTMLInputElement>(null);
-    const { query, setQuery, registerSearchRef, excludeCurrentDoc, setExcludeCurrentDoc } = useSearchContext();
+    const {
+        query, setQuery, registerSearchRef,
+        excludeCurrentDoc, setExcludeCurrentDoc,
+        scopeToSelectedTag, setScopeToSelectedTag,
+    } = useSearchContext();
+    const selectedTag = useSelectedTag();
 
     // 注册到 SearchContext
     useImperativeHandle(ref, () => ({
@@ -95,6 +101,16 @@ export const SidebarSearch = forwardRef<SidebarSearchRef, SidebarSearchProps>(({
                     <span>Exclude current doc</span>
                 </label>
             )}
+            {query && selectedTag && (
+                <label className="search-exclude-checkbox">
+                    <input
+                        type="checkbox"
+                        checked={scopeToSelectedTag}
+                        onChange={(e) => setScopeToSelectedTag(e.target.checked)}
+                    />
+                    <span>Only in #{selectedTag}</span>
+                </label>
+            )}
         </div>
     );
 });
@@ -17,7 +17,19 @@ export const EN = {
         SAVED: "Saved",
         IMAGE_COPIED: "Image copied",
         HTML_EXPORTED: "HTML exported",
+        SEARCH_EXPORTED: "Search results exported",
+        VAULT_EXPORTED: "All documents exported",
+        PDF_EXPORTED: "PDF exported",
+        PDF_PRINT_FALLBACK: "No PDF renderer found, opened print dialog",
+        DOCUMENT_RESTORED: "Restored",
+        TRASH_EMPTY: "Trash is empty",
+        TRASH_EMPTIED: "Trash emptied",
+        EXPORT_SEARCH_EMPTY: "Enter a search query first",
         EXPORT_IMAGE_FAILED: "Export image failed:",
+        FOLDER_IMPORTED: "Imported",
+        IMPORT_SKIPPED: "skipped",
+        IMPORT_FAILED: "failed",
+        UNREADABLE_DOCUMENTS: "Some documents could not be read and may be corrupted. Restore them from a backup:",
     },
 
     BUTTONS: {
@@ -56,6 +68,8 @@ export const EN = {
         PINNED_TAG_ADD_DOC: "Add Document",
         PIN_TAG: "Pin to Sidebar",
         UNPIN_TAG: "Unpin from Sidebar",
+        EMBED_MODEL: "Embedding model for this document",
+        REVEAL_DOCUMENT: "Show in File Manager",
     },
 
     LABELS: {
@@ -73,14 +87,18 @@ export const EN = {
         NO_MATCH: "No matching documents found",
         EMPTY_LIST: "No documents yet, click + to create",
         EMPTY_APP: "No documents yet",
+        EMBED_MODEL_DEFAULT: "Default model",
+        EMBED_MODEL_HINT: "Uses the current provider; the model must produce vectors of the same dimension.",
     },
 
     MODALS: {
         DELETE_TITLE: "Delete Document",
-        DELETE_MESSAGE: "Are you sure you want to delete this document? This action cannot be undone.",
+        DELETE_MESSAGE: "Are you sure you want to delete this document? It will be moved to the trash.",
         DELETE_TAG_TITLE: "Delete Tag",
         DELETE_TAG_MESSAGE: "Are you sure you want to delete this tag? The tag will be removed from all documents.",
         RENAME_TAG_TITLE: "Rename Tag",
+        EMPTY_TRASH_TITLE: "Empty Trash",
+        EMPTY_TRASH_MESSAGE: "Permanently delete all documents in the trash? This action cannot be undone.",
     },
 
     MENU: {
@@ -105,6 +123,22 @@ export const EN = {
         LAST_UPDATE: "Last Update",
         REBUILD_INDEX: "Rebuild Index",
         REBUILDING: "Rebuilding...",
+        PENDING_CHANGES: "Pending Changes",
+        CHUNKS: "chunks",
+        REBUILD_COST: "Rebuild Cost",
+        EMBEDDINGS: "embeddings",
+        REQUESTS: "requests",
+        COMPACT_INDEX: "Compact Database",
+        COMPACTING: "Compacting...",
+        COMPACT_DONE: "Database compacted, reclaimed",
+        VERIFY_INDEX: "Verify Index",
+        VERIFYING: "Verifying...",
+        VERIFY_OK: "Index is consistent",
+        VERIFY_REPAIRED: "Removed inconsistent index entries, rebuild the index to restore them:",
+        REPAIR_INDEX_TITLE: "Repair Index",
+        REPAIR_INDEX_FOUND: "Inconsistent index entries found (missing vectors / orphaned vectors / wrong dimension):",
+        REPAIR_INDEX_MESSAGE: "Remove them? Affected documents need an index rebuild to become searchable again.",
+        REBUILD_FAILED_DOCS: "documents could not be indexed. First error:",
         INDEXING_DOCUMENTS: "Indexing documents",
         INDEXING_EXTERNAL: "Indexing external content",
         SAVING: "Saving...",
@@ -123,6 +157,14 @@ export const EN = {
         TESTING_CONNECTION: "Testing...",
         CONNECTION_SUCCESS: "Connected",
         CONNECTION_FAILED: "Connection failed",
+        EXCLUDED_BLOCK_TYPES: "Excluded Block Types",
+        EXCLUDED_BLOCK_TYPES_HINT: "Checked block types are not indexed. Existing documents are re-extracted in the background after saving.",
+        BLOCK_TYPE_NAMES: {
+            codeBlock: "Code blocks",
+            table: "Tables",
+            quote: "Quotes",
+            checkListItem: "Checklists",
+        },
         // Appearance settings
         APPEARANCE: "Appearance",
         THEME_SETTING: "Theme",
@@ -134,6 +176,9 @@ export const EN = {
         WRITING_STYLE: "Writing Style Guide",
         WRITING_STYLE_PLACEHOLDER: "Example:\n- Use concise and professional language\n- Prefer functional programming patterns\n- Always add type definitions in TypeScript\n- Avoid using third-party libraries unless necessary",
         WRITING_STYLE_HINT: "This guide will be used by AI assistants (like Claude Code MCP) when creating or editing content.",
+        DEFAULT_DOC_TEMPLATE: "New Document Template",
+        DEFAULT_DOC_TEMPLATE_PLACEHOLDER: '[\n  {"type": "heading", "props": {"level": 2}, "content": "{{date}}"},\n  {"type": "checkListItem", "content": ""}\n]',
+        DEFAULT_DOC_TEMPLATE_HINT: "BlockNote JSON applied to new documents. Supports {{date}} and {{title}}. Leave empty for blank documents; invalid templates are ignored.",
     },
 
     MCP: {
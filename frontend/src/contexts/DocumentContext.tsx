@@ -24,7 +24,7 @@ interface DocumentContextType {
   isLoading: boolean;
 
   // Document operations
-  createDoc: (title?: string, pinnedTagName?: string) => Promise<DocumentMeta>;
+  createDoc: (title?: string, tagName?: string) => Promise<DocumentMeta>;
   deleteDoc: (id: string) => Promise<void>;
   renameDoc: (id: string, title: string) => Promise<void>;
   switchDoc: (id: string) => Promise<void>;
@@ -66,8 +66,8 @@ export function DocumentProvider({ children }: { children: ReactNode }) {
   }, []);
 
   // Create wrapper functions that use default title
-  const createDoc = async (title?: string, pinnedTagName?: string) => {
-    return store.getState().createDoc(title || STRINGS.DEFAULTS.UNTITLED, pinnedTagName);
+  const createDoc = async (title?: string, tagName?: string) => {
+    return store.getState().createDoc(title || STRINGS.DEFAULTS.UNTITLED, tagName);
   };
 
   const value: DocumentContextType = {
@@ -14,6 +14,9 @@ interface SearchContextValue {
     // 排除当前文档
     excludeCurrentDoc: boolean;
     setExcludeCurrentDoc: (exclude: boolean) => void;
+    // 语义搜索仅在带侧边栏选中标签的文档内进行
+    scopeToSelectedTag: boolean;
+    setScopeToSelectedTag: (scope: boolean) => void;
     // 设置查询并聚焦，同时启用排除当前文档
     setQueryWithExclude: (query: string) => void;
 }
@@ -23,6 +26,7 @@ const SearchContext = createContext<SearchContextValue | null>(null);
 export function SearchProvider({ children }: { children: ReactNode }) {
     const [query, setQueryState] = useState('');
     const [excludeCurrentDoc, setExcludeCurrentDoc] = useState(false);
+    const [scopeToSelectedTag, setScopeToSelectedTag] = useState(false);
     const searchRef = useRef<SearchInputRef | null>(null);
 
     const setQuery = useCallback((newQuery: string) => {
@@ -64,6 +68,8 @@ export function SearchProvider({ children }: { children: ReactNode }) {
                 registerSearchRef,
                 excludeCurrentDoc,
                 setExcludeCurrentDoc,
+                scopeToSelectedTag,
+                setScopeToSelectedTag,
                 setQueryWithExclude,
             }}
         >
@@ -20,12 +20,14 @@ interface SettingsContextType {
     sidebarWidth: number;
     fontSize: number;
     writingStyle: string;
+    defaultDocTemplate: string;
     toggleTheme: () => void;
     setThemeSetting: (theme: ThemeSetting) => void;
     setLanguage: (lang: LanguageSetting) => void;
     setSidebarWidth: (width: number) => void;
     setFontSize: (size: number) => void;
     setWritingStyle: (style: string) => void;
+    setDefaultDocTemplate: (template: string) => void;
 }
 
 const SettingsContext = createContext<SettingsContextType | undefined>(undefined);
@@ -54,6 +56,7 @@ export function SettingsProvider({ children }: { children: ReactNode }) {
     const sidebarWidth = (settings.sidebarWidth > 0) ? settings.sidebarWidth : DEFAULT_SIDEBAR_WIDTH;
     const fontSize = (settings.fontSize > 0) ? settings.fontSize : DEFAULT_FONT_SIZE;
     const writingStyle = settings.writingStyle || '';
+    const defaultDocTemplate = settings.defaultDocTemplate || '';
 
     // Resolve theme based on setting and system preference
     useEffect(() => {
@@ -122,6 +125,10 @@ export function SettingsProvider({ children }: { children: ReactNode }) {
         updateSettings({ writingStyle: style });
     };
 
+    const handleSetDefaultDocTemplate = (template: string) => {
+        updateSettings({ defaultDocTemplate: template });
+    };
+
     if (!isLoaded) {
         return null; // or a loading spinner? returning null prevents flashing default styles incorrectly
     }
@@ -134,12 +141,14 @@ export function SettingsProvider({ children }: { children: ReactNode }) {
             sidebarWidth,
             fontSize,
             writingStyle,
+            defaultDocTemplate,
             toggleTheme,
             setThemeSetting,
             setLanguage: handleSetLanguage,
             setSidebarWidth: handleSetSidebarWidth,
             setFontSize: handleSetFontSize,
-            setWritingStyle: handleSetWritingStyle
+            setWritingStyle: handleSetWritingStyle,
+            setDefaultDocTemplate: handleSetDefaultDocTemplate
         }}>
             {children}
         </SettingsContext.Provider>
@@ -4,15 +4,21 @@ interface MenuEventsOptions {
     onNewDocument: () => void;
     onNewFolder?: () => void;
     onImport: () => void;
+    onImportFolder?: () => void;
     onExport: () => void;
+    onExportVault?: () => void;
     onCopyImage?: () => void;
     onSaveImage?: () => void;
     onExportHTML?: () => void;
+    onExportPDF?: () => void;
+    onExportSearchResults?: () => void;
     onPrint?: () => void;
     onToggleSidebar: () => void;
     onToggleTheme?: () => void;
     onAbout: () => void;
     onOpenExternal?: () => void;
+    onRestoreDocument?: () => void;
+    onEmptyTrash?: () => void;
     onSettings?: () => void;
 }
 
@@ -20,15 +26,21 @@ export function useMenuEvents({
     onNewDocument,
     onNewFolder,
     onImport,
+    onImportFolder,
     onExport,
+    onExportVault,
     onCopyImage,
     onSaveImage,
     onExportHTML,
+    onExportPDF,
+    onExportSearchResults,
     onPrint,
     onToggleSidebar,
     onToggleTheme,
     onAbout,
     onOpenExternal,
+    onRestoreDocument,
+    onEmptyTrash,
     onSettings,
 }: MenuEventsOptions) {
     useWailsEvents(
@@ -36,17 +48,23 @@ export function useMenuEvents({
             'menu:new-document': onNewDocument,
             'menu:new-folder': onNewFolder,
             'menu:import': onImport,
+            'menu:import-folder': onImportFolder,
             'menu:export': onExport,
+            'menu:export-vault': onExportVault,
             'menu:copy-image': onCopyImage,
             'menu:save-image': onSaveImage,
             'menu:export-html': onExportHTML,
+            'menu:export-pdf': onExportPDF,
+            'menu:export-search-results': onExportSearchResults,
             'menu:print': onPrint,
             'menu:toggle-sidebar': onToggleSidebar,
             'menu:toggle-theme': onToggleTheme,
             'menu:about': onAbout,
             'menu:open-external': onOpenExternal,
+            'menu:restore-document': onRestoreDocument,
+            'menu:empty-trash': onEmptyTrash,
             'menu:settings': onSettings,
         },
-        [onNewDocument, onNewFolder, onImport, onExport, onCopyImage, onSaveImage, onExportHTML, onPrint, onToggleSidebar, onToggleTheme, onAbout, onOpenExternal, onSettings]
+        [onNewDocument, onNewFolder, onImport, onImportFolder, onExport, onExportVault, onCopyImage, onSaveImage, onExportHTML, onExportPDF, onExportSearchResults, onPrint, onToggleSidebar, onToggleTheme, onAbout, onOpenExternal, onRestoreDocument, onEmptyTrash, onSettings]
     );
 }
@@ -11,6 +11,9 @@ export function usePersistentSettings() {
         sidebarWidth: 0,
         fontSize: 0,
         writingStyle: '',
+        defaultDocTemplate: '',
+        disableSearchHistory: false,
+        versionRetention: 0,
     });
     const [isLoaded, setIsLoaded] = useState(false);
 
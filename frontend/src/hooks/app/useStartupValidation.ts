@@ -0,0 +1,40 @@
+import { useEffect } from 'react';
+import { GetStartupValidationReport } from '../../../wailsjs/go/main/App';
+import { EventsOn } from '../../../wailsjs/runtime/runtime';
+import { document } from '../../../wailsjs/go/models';
+import { useToast } from '../../components/common/Toast';
+import { STRINGS } from '../../constants/strings';
+
+const MAX_LISTED_DOCUMENTS = 5;
+
+/**
+ * 提示启动校验中发现的损坏文档
+ * - 校验在后端异步进行，可能早于或晚于前端加载完成，因此既主动查询也监听事件
+ * - 仅提示，不阻塞使用
+ */
+export function useStartupValidation() {
+    const { showToast } = useToast();
+
+    useEffect(() => {
+        let notified = false;
+
+        const notify = (report: document.ValidationReport) => {
+            if (notified || !report.done || !report.invalid?.length) return;
+            notified = true;
+
+            const titles = report.invalid
+                .slice(0, MAX_LISTED_DOCUMENTS)
+                .map(issue => issue.title || issue.docId);
+            const more = report.invalid.length - titles.length;
+            const list = titles.join(', ') + (more > 0 ? ` (+${more})` : '');
+            showToast(`${STRINGS.STATUS.UNREADABLE_DOCUMENTS} ${list}`, 'warning', { duration: 15000 });
+        };
+
+        const unsubscribe = EventsOn('documents:validation-failed', notify);
+        GetStartupValidationReport()
+            .then(notify)
+            .catch(err => console.warn('[StartupValidation] Failed to get report:', err));
+
+        return () => unsubscribe();
+    }, [showToast]);
+}
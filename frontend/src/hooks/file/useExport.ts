@@ -3,6 +3,7 @@ import { BlockNoteEditor } from '@blocknote/core';
 import {
     ExportMarkdownFile,
     ExportHTMLFile,
+    ExportPDFFile,
     PrintHTML,
     CopyImageToClipboard,
     SaveImageFile
@@ -25,6 +26,7 @@ interface UseExportOptions {
 interface UseExportReturn {
     handleExportMarkdown: () => Promise<void>;
     handleExportHTML: () => Promise<void>;
+    handleExportPDF: () => Promise<void>;
     handleCopyImage: () => Promise<void>;
     handleSaveImage: () => Promise<void>;
     handlePrint: () => Promise<void>;
@@ -129,6 +131,7 @@ ${autoPrint ? `<script>window.onload = function() { window.print(); };</script>`
  * Unified hook for all export functionality
  * - Markdown export
  * - HTML export  
+ * - PDF export
  * - Image export (clipboard + save)
  * - Print
  */
@@ -188,6 +191,29 @@ export function useExport({
         }
     }, [editorRef, getTitle, onSuccess, onError]);
 
+    // Export as PDF (headless renderer, or browser print dialog as fallback)
+    const handleExportPDF = useCallback(async () => {
+        const editor = editorRef.current;
+        if (!editor) {
+            onError?.(new Error('Editor not available'));
+            return;
+        }
+
+        try {
+            const html = await editor.blocksToFullHTML(editor.document);
+            const fullHTML = generatePrintHTML(html, getTitle(), false);
+            const method = await ExportPDFFile(fullHTML, getTitle());
+            if (method === 'saved') {
+                onSuccess?.(STRINGS.STATUS.PDF_EXPORTED);
+            } else if (method === 'print') {
+                onSuccess?.(STRINGS.STATUS.PDF_PRINT_FALLBACK);
+            }
+        } catch (error) {
+            console.error('[Export] PDF export failed:', error);
+            onError?.(error instanceof Error ? error : new Error('Export failed'));
+        }
+    }, [editorRef, getTitle, onSuccess, onError]);
+
     // Copy image to clipboard
     const handleCopyImage = useCallback(async () => {
         try {
@@ -234,6 +260,7 @@ export function useExport({
     return {
         handleExportMarkdown,
         handleExportHTML,
+        handleExportPDF,
         handleCopyImage,
         handleSaveImage,
         handlePrint,
@@ -1,17 +1,21 @@
 import { useCallback } from 'react';
 import { Block, BlockNoteEditor } from '@blocknote/core';
-import { ImportMarkdownFile } from '../../../wailsjs/go/main/App';
+import { ImportMarkdownFile, ImportMarkdownFolder } from '../../../wailsjs/go/main/App';
+import { markdown } from '../../../wailsjs/go/models';
 import { DocumentMeta } from '../../types/document';
 
 interface UseImportProps {
     editorRef: React.MutableRefObject<BlockNoteEditor | null>;
     createDoc: (title?: string) => Promise<DocumentMeta>;
     saveContent: (id: string, content: Block[]) => Promise<void>;
+    addTag: (docId: string, tag: string) => Promise<void>;
     onContentChange?: (content: Block[]) => void;
+    onFolderImported?: (result: markdown.FolderImportResult) => void;
 }
 
 interface UseImportReturn {
     handleImport: () => Promise<void>;
+    handleImportFolder: () => Promise<void>;
 }
 
 /**
@@ -21,23 +25,42 @@ export function useImport({
     editorRef,
     createDoc,
     saveContent,
+    addTag,
     onContentChange,
+    onFolderImported,
 }: UseImportProps): UseImportReturn {
     const handleImport = useCallback(async () => {
         const result = await ImportMarkdownFile();
         if (result && result.content && editorRef.current) {
             try {
                 const blocks = await editorRef.current.tryParseMarkdownToBlocks(result.content);
-                const doc = await createDoc(result.fileName);
+                // frontmatter 中的 title/tags 作为文档标题和标签（如从 Obsidian 迁移）
+                const doc = await createDoc(result.title || result.fileName);
                 await saveContent(doc.id, blocks);
+                for (const tag of result.tags || []) {
+                    await addTag(doc.id, tag);
+                }
                 onContentChange?.(blocks);
             } catch (e) {
                 console.error('Import failed:', e);
             }
         }
-    }, [editorRef, createDoc, saveContent, onContentChange]);
+    }, [editorRef, createDoc, saveContent, addTag, onContentChange]);
+
+    // 批量导入文件夹（由后端转换并创建文档）
+    const handleImportFolder = useCallback(async () => {
+        try {
+            const result = await ImportMarkdownFolder();
+            if (result) {
+                onFolderImported?.(result);
+            }
+        } catch (e) {
+            console.error('Import folder failed:', e);
+        }
+    }, [onFolderImported]);
 
     return {
         handleImport,
+        handleImportFolder,
     };
 }
@@ -1,9 +1,10 @@
 import { useState, useCallback, useEffect, useMemo } from 'react';
 import { SearchResult, DocumentSearchResult } from '../../types/document';
-import { SearchDocuments, SemanticSearchDocuments } from '../../../wailsjs/go/main/App';
+import { SearchDocuments, SemanticSearchWithFilter } from '../../../wailsjs/go/main/App';
 import { useSearchContext } from '../../contexts/SearchContext';
 import { useDocumentContext } from '../../contexts/DocumentContext';
 import { useDebounce } from '../ui/useDebounce';
+import { useSelectedTag } from '../../store/store';
 
 interface UseSearchReturn {
     query: string;
@@ -16,8 +17,11 @@ interface UseSearchReturn {
 }
 
 export function useSearch(): UseSearchReturn {
-    const { query, setQuery: setContextQuery, excludeCurrentDoc } = useSearchContext();
+    const { query, setQuery: setContextQuery, excludeCurrentDoc, scopeToSelectedTag } = useSearchContext();
     const { activeId } = useDocumentContext();
+    // 开启标签范围且选中标签时，语义搜索仅在带该标签的文档内进行
+    const selectedTag = useSelectedTag();
+    const scopeTag = scopeToSelectedTag ? selectedTag : null;
 
     // 精细化的排除ID逻辑：
     // - 只有当 excludeCurrentDoc 开启时，activeId 变化才应触发重新搜索
@@ -31,9 +35,12 @@ export function useSearch(): UseSearchReturn {
     const [isLoadingSemantic, setIsLoadingSemantic] = useState(false);
 
     // Semantic search debounced function
-    const performSemanticSearch = useDebounce(async (searchQuery: string, excludeId: string) => {
+    const performSemanticSearch = useDebounce(async (searchQuery: string, excludeId: string, tag: string | null) => {
         try {
-            const semResults = await SemanticSearchDocuments(searchQuery, 5, excludeId);
+            const semResults = await SemanticSearchWithFilter(searchQuery, 5, {
+                excludeDocId: excludeId || undefined,
+                tags: tag ? [tag] : undefined,
+            });
             setRawSemanticResults(semResults || []);
         } catch (error) {
             console.error('Semantic search failed:', error);
@@ -64,14 +71,14 @@ export function useSearch(): UseSearchReturn {
             // 2. Debounced Semantic Search (Document-level)
             // effectiveExcludeId 已经包含了精细化逻辑
             const currentExcludeId = effectiveExcludeId || "";
-            performSemanticSearch(query, currentExcludeId);
+            performSemanticSearch(query, currentExcludeId, scopeTag);
         } else {
             setRawResults([]);
             setRawSemanticResults([]);
             setIsSearching(false);
             setIsLoadingSemantic(false);
         }
-    }, [query, effectiveExcludeId, performSemanticSearch]);
+    }, [query, effectiveExcludeId, scopeTag, performSemanticSearch]);
     // effectiveExcludeId 精细化依赖：
     // - excludeCurrentDoc=false 时：effectiveExcludeId=null（不变）→ activeId 变化不触发搜索
     // - excludeCurrentDoc=true 时：effectiveExcludeId=activeId → activeId 变化触发搜索
@@ -10,9 +10,10 @@ import { DocumentMeta, TagInfo, TagSuggestion } from '../types/document';
 import { Block } from '@blocknote/core';
 import {
     GetDocumentList,
-    CreateDocument,
+    CreateDocumentWithTags,
     DeleteDocument,
     RenameDocument as RenameDocumentApi,
+    SetDocumentEmbedModel,
     SetActiveDocument,
     LoadDocumentContent,
     SaveDocumentContent,
@@ -55,9 +56,10 @@ interface AppState extends DocumentState, TagState {
     initTags: () => Promise<void>;
 
     // ========== Document Actions ==========
-    createDoc: (title: string, pinnedTagName?: string) => Promise<DocumentMeta>;
+    createDoc: (title: string, tagName?: string) => Promise<DocumentMeta>;
     deleteDoc: (id: string) => Promise<void>;
     renameDoc: (id: string, newTitle: string) => Promise<void>;
+    setDocEmbedModel: (id: string, model: string) => Promise<void>;
     switchDoc: (id: string) => Promise<void>;
     reorderDocuments: (ids: string[]) => Promise<void>;
     refreshDocuments: () => Promise<void>;
@@ -133,12 +135,9 @@ export const useAppStore = create<AppState>((set, get) => ({
 
     // ========== Document Actions ==========
 
-    createDoc: async (title, pinnedTagName) => {
-        const doc = await CreateDocument(title);
-        if (pinnedTagName) {
-            await AddDocumentTag(doc.id, pinnedTagName);
-            doc.tags = [pinnedTagName];
-        }
+    createDoc: async (title, tagName) => {
+        // 标签随文档一起创建（固定标签组或当前筛选的标签）
+        const doc = await CreateDocumentWithTags(title, tagName ? [tagName] : []);
         set((state) => ({
             documents: [doc, ...state.documents],
             activeId: doc.id,
@@ -166,6 +165,16 @@ export const useAppStore = create<AppState>((set, get) => ({
         }));
     },
 
+    setDocEmbedModel: async (id, model) => {
+        const trimmed = model.trim();
+        await SetDocumentEmbedModel(id, trimmed);
+        set((state) => ({
+            documents: state.documents.map((d) =>
+                d.id === id ? { ...d, embedModel: trimmed || undefined } : d
+            ),
+        }));
+    },
+
     switchDoc: async (id) => {
         await SetActiveDocument(id);
         set({ activeId: id });
@@ -416,6 +425,7 @@ export const useDocumentActions = () => useAppStore((state) => ({
     createDoc: state.createDoc,
     deleteDoc: state.deleteDoc,
     renameDoc: state.renameDoc,
+    setDocEmbedModel: state.setDocEmbedModel,
     switchDoc: state.switchDoc,
     reorderDocuments: state.reorderDocuments,
     refreshDocuments: state.refreshDocuments,
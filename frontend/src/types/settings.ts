@@ -8,6 +8,30 @@ export interface EmbeddingConfig {
     apiKey: string;
     maxChunkSize: number;
     overlap: number;
+    shortBlockThreshold: number;
+    maxMergedLength: number;
+    chunkUnit: string;
+    sentenceDelimiters: string;
+    fetchTimeout: number;
+    usePrefixes: boolean;
+    autoReindex: boolean;
+    autoReindexInterval: number;
+    reindexWorkers: number;
+    extractWorkers: number;
+    pdfOcr: boolean;
+    maxExtractBytes: number;
+    csvMaxRows: number;
+    excludedBlockTypes: string[];
+    mmrLambda: number;
+    embedTitles: boolean;
+    titleBoost: number;
+    modelDimensions?: Record<string, number>;
+    retryMaxAttempts: number;
+    retryBaseDelayMs: number;
+    retryJitter: number;
+    preprocessLowercase: boolean;
+    preprocessNormalizeWhitespace: boolean;
+    preprocessStripMarkdown: boolean;
 }
 
 /**
@@ -23,6 +47,20 @@ export interface RAGStatus {
     lastIndexTime: string;
 }
 
+/**
+ * Estimated changes and embedding cost of a reindex (no embeddings are made)
+ */
+export interface ReindexPlan {
+    add: number;
+    update: number;
+    delete: number;
+    unchanged: number;
+    embeddings: number;
+    embedRequests: number;
+    rebuildEmbeddings: number;
+    rebuildEmbedRequests: number;
+}
+
 /**
  * MCP server information
  */
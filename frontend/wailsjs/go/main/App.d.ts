@@ -7,41 +7,76 @@ import {opengraph} from '../models';
 import {tag} from '../models';
 import {rag} from '../models';
 import {markdown} from '../models';
+import {search} from '../models';
 
 export function AddDocumentTag(arg1:string,arg2:string):Promise<void>;
 
 export function ArchiveFile(arg1:string):Promise<handlers.ArchiveResult>;
 
+export function AutoTagDocument(arg1:string):Promise<Array<string>>;
+
+export function CheckArchiveStaleness(arg1:string):Promise<Array<handlers.StaleArchive>>;
+
 export function CheckFileExists(arg1:string):Promise<boolean>;
 
 export function CheckForUpdates():Promise<main.UpdateInfo>;
 
 export function Cleanup():Promise<void>;
 
+export function CleanupTempFiles(arg1:number):Promise<number>;
+
+export function ClearSearchFeedback():Promise<void>;
+
+export function ClearSearchHistory():Promise<void>;
+
+export function CompactIndex():Promise<rag.CompactResult>;
+
 export function CopyFileToStorage(arg1:string):Promise<handlers.FileInfo>;
 
 export function CopyImageToClipboard(arg1:string):Promise<void>;
 
 export function CreateDocument(arg1:string):Promise<document.Meta>;
 
+export function CreateDocumentWithTags(arg1:string,arg2:Array<string>):Promise<document.Meta>;
+
 export function DeleteDocument(arg1:string):Promise<void>;
 
 export function DeleteTag(arg1:string):Promise<void>;
 
+export function EmptyTrash():Promise<number>;
+
+export function ExportDocumentsToSite(arg1:Array<string>,arg2:string):Promise<markdown.SiteExportResult>;
+
 export function ExportHTMLFile(arg1:string,arg2:string):Promise<void>;
 
 export function ExportMarkdownFile(arg1:string,arg2:string):Promise<void>;
 
+export function ExportPDFFile(arg1:string,arg2:string):Promise<string>;
+
+export function ExportSearchResults(query:string,limit:number,format:string):Promise<rag.SearchExportResult>;
+
+export function ExportVault(arg1:string):Promise<markdown.VaultExportResult>;
+
+export function ExportVectorData(arg1:string):Promise<rag.VectorExportResult>;
+
 export function FetchLinkMetadata(arg1:string):Promise<opengraph.LinkMetadata>;
 
+export function FindBrokenFileReferences():Promise<Array<handlers.BrokenRef>>;
+
 export function GetAllTags():Promise<Array<tag.TagInfo>>;
 
 export function GetAppInfo():Promise<main.AppInfo>;
 
-export function GetDocumentGraph(arg1:number):Promise<rag.GraphData>;
+export function GetBacklinks(arg1:string):Promise<Array<handlers.BacklinkRef>>;
+
+export function GetDocumentGraph(arg1:number,arg2:boolean):Promise<rag.GraphData>;
+
+export function GetDocumentGraphANN(arg1:number,arg2:number):Promise<rag.GraphData>;
 
 export function GetDocumentList():Promise<document.Index>;
 
+export function GetDocumentStats(arg1:string):Promise<search.DocStats>;
+
 export function GetDocumentVectors():Promise<rag.VectorGraphData>;
 
 export function GetEffectiveFilePath(arg1:string,arg2:string,arg3:boolean):Promise<string>;
@@ -58,37 +93,67 @@ export function GetRAGConfig():Promise<rag.EmbeddingConfig>;
 
 export function GetRAGStatus():Promise<handlers.RAGStatus>;
 
+export function GetRecentSearches(arg1:number):Promise<Array<search.HistoryEntry>>;
+
 export function GetSettings():Promise<handlers.Settings>;
 
+export function GetStartupValidationReport():Promise<document.ValidationReport>;
+
+export function GetStorageUsage():Promise<handlers.StorageUsage>;
+
 export function GetTagColors():Promise<Record<string, string>>;
 
+export function GetTempDirSize():Promise<number>;
+
+export function GetTopicClusters(arg1:number):Promise<Array<rag.TopicCluster>>;
+
+export function GetTrash():Promise<Array<document.TrashEntry>>;
+
+export function GetUntaggedDocuments():Promise<Array<document.Meta>>;
+
 export function ImportMarkdownFile():Promise<markdown.ImportResult>;
 
-export function IndexBookmarkContent(arg1:string,arg2:string,arg3:string):Promise<void>;
+export function ImportMarkdownFolder():Promise<markdown.FolderImportResult>;
+
+export function IndexBookmarkContent(arg1:string,arg2:string,arg3:string,arg4:number):Promise<void>;
 
 export function IndexFileContent(arg1:string,arg2:string,arg3:string,arg4:string):Promise<void>;
 
 export function IndexFolderContent(arg1:string,arg2:string,arg3:string):Promise<rag.FolderIndexResult>;
 
+export function IsArchiveStale(arg1:string,arg2:string,arg3:number):Promise<boolean>;
+
 export function ListModels(arg1:string,arg2:string,arg3:string):Promise<Array<string>>;
 
+export function ListVectorBackups():Promise<Array<rag.VectorBackup>>;
+
+export function ListVersions(arg1:string):Promise<Array<document.Version>>;
+
 export function LoadDocumentContent(arg1:string):Promise<string>;
 
 export function LoadExternalFile(arg1:string):Promise<string>;
 
+export function MergeDocuments(arg1:Array<string>,arg2:string,arg3:boolean):Promise<handlers.MergeResult>;
+
 export function OpenExternalFile():Promise<handlers.ExternalFile>;
 
 export function OpenFileDialog():Promise<handlers.FileInfo>;
 
 export function OpenFileWithSystem(arg1:string):Promise<void>;
 
+export function OpenURL(arg1:string):Promise<void>;
+
 export function PinTag(arg1:string):Promise<void>;
 
+export function PlanReindex():Promise<rag.ReindexPlan>;
+
 export function PrintHTML(arg1:string,arg2:string):Promise<void>;
 
 export function ReadFileAsBase64(arg1:string):Promise<string>;
 
-export function RebuildIndex():Promise<number>;
+export function RebuildIndex():Promise<handlers.RebuildIndexResult>;
+
+export function RecordSearchFeedback(arg1:string,arg2:string,arg3:boolean):Promise<void>;
 
 export function RemoveDocumentTag(arg1:string,arg2:string):Promise<void>;
 
@@ -100,9 +165,19 @@ export function ReorderDocuments(arg1:Array<string>):Promise<void>;
 
 export function ReorderPinnedTags(arg1:Array<string>):Promise<void>;
 
+export function RepairIndex():Promise<rag.VerifyReport>;
+
+export function RestoreDocument(arg1:string):Promise<document.Meta>;
+
+export function RestoreVectorBackup(arg1:string):Promise<void>;
+
+export function RestoreVersion(arg1:string,arg2:number):Promise<string>;
+
+export function RevealDocument(arg1:string):Promise<void>;
+
 export function RevealInFinder(arg1:string):Promise<void>;
 
-export function SaveDocumentContent(arg1:string,arg2:string):Promise<void>;
+export function SaveDocumentContent(arg1:string,arg2:string):Promise<boolean>;
 
 export function SaveExternalFile(arg1:string,arg2:string):Promise<void>;
 
@@ -112,18 +187,26 @@ export function SaveImage(arg1:string,arg2:string):Promise<string>;
 
 export function SaveImageFile(arg1:string,arg2:string):Promise<void>;
 
-export function SaveRAGConfig(arg1:rag.EmbeddingConfig):Promise<void>;
+export function SaveRAGConfig(arg1:rag.EmbeddingConfig,arg2:rag.EmbeddingConfig):Promise<void>;
 
 export function SaveSettings(arg1:handlers.Settings):Promise<void>;
 
 export function SearchDocuments(arg1:string):Promise<Array<handlers.SearchResult>>;
 
+export function SearchFaceted(arg1:string,arg2:number):Promise<handlers.FacetedSearchResult>;
+
 export function SelectFolderDialog():Promise<string>;
 
 export function SemanticSearchDocuments(arg1:string,arg2:number,arg3:string):Promise<Array<handlers.DocumentSearchResult>>;
 
+export function SemanticSearchDocumentsPaged(arg1:string,arg2:number,arg3:number,arg4:rag.SearchFilter):Promise<handlers.DocumentSearchPage>;
+
+export function SemanticSearchWithFilter(arg1:string,arg2:number,arg3:rag.SearchFilter):Promise<Array<handlers.DocumentSearchResult>>;
+
 export function SetActiveDocument(arg1:string):Promise<void>;
 
+export function SetDocumentEmbedModel(arg1:string,arg2:string):Promise<void>;
+
 export function SetPinnedTagCollapsed(arg1:string,arg2:boolean):Promise<void>;
 
 export function SetTagColor(arg1:string,arg2:string):Promise<void>;
@@ -134,8 +217,12 @@ export function SyncArchivedFile(arg1:string,arg2:string):Promise<handlers.Archi
 
 export function TestConnection(arg1:rag.EmbeddingConfig):Promise<rag.TestConnectionResult>;
 
+export function TestRAGConnection():Promise<rag.ConnectionStatus>;
+
 export function UnarchiveFile(arg1:string):Promise<void>;
 
 export function UnpinTag(arg1:string):Promise<void>;
 
+export function VerifyIndex():Promise<rag.VerifyReport>;
+
 export function WarmupRAG():Promise<void>;
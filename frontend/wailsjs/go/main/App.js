@@ -10,6 +10,14 @@ export function ArchiveFile(arg1) {
   return window['go']['main']['App']['ArchiveFile'](arg1);
 }
 
+export function AutoTagDocument(arg1) {
+  return window['go']['main']['App']['AutoTagDocument'](arg1);
+}
+
+export function CheckArchiveStaleness(arg1) {
+  return window['go']['main']['App']['CheckArchiveStaleness'](arg1);
+}
+
 export function CheckFileExists(arg1) {
   return window['go']['main']['App']['CheckFileExists'](arg1);
 }
@@ -22,6 +30,22 @@ export function Cleanup() {
   return window['go']['main']['App']['Cleanup']();
 }
 
+export function CleanupTempFiles(arg1) {
+  return window['go']['main']['App']['CleanupTempFiles'](arg1);
+}
+
+export function ClearSearchFeedback() {
+  return window['go']['main']['App']['ClearSearchFeedback']();
+}
+
+export function ClearSearchHistory() {
+  return window['go']['main']['App']['ClearSearchHistory']();
+}
+
+export function CompactIndex() {
+  return window['go']['main']['App']['CompactIndex']();
+}
+
 export function CopyFileToStorage(arg1) {
   return window['go']['main']['App']['CopyFileToStorage'](arg1);
 }
@@ -34,6 +58,10 @@ export function CreateDocument(arg1) {
   return window['go']['main']['App']['CreateDocument'](arg1);
 }
 
+export function CreateDocumentWithTags(arg1, arg2) {
+  return window['go']['main']['App']['CreateDocumentWithTags'](arg1, arg2);
+}
+
 export function DeleteDocument(arg1) {
   return window['go']['main']['App']['DeleteDocument'](arg1);
 }
@@ -42,6 +70,14 @@ export function DeleteTag(arg1) {
   return window['go']['main']['App']['DeleteTag'](arg1);
 }
 
+export function EmptyTrash() {
+  return window['go']['main']['App']['EmptyTrash']();
+}
+
+export function ExportDocumentsToSite(arg1, arg2) {
+  return window['go']['main']['App']['ExportDocumentsToSite'](arg1, arg2);
+}
+
 export function ExportHTMLFile(arg1, arg2) {
   return window['go']['main']['App']['ExportHTMLFile'](arg1, arg2);
 }
@@ -50,10 +86,30 @@ export function ExportMarkdownFile(arg1, arg2) {
   return window['go']['main']['App']['ExportMarkdownFile'](arg1, arg2);
 }
 
+export function ExportPDFFile(arg1, arg2) {
+  return window['go']['main']['App']['ExportPDFFile'](arg1, arg2);
+}
+
+export function ExportSearchResults(query, limit, format) {
+  return window['go']['main']['App']['ExportSearchResults'](query, limit, format);
+}
+
+export function ExportVault(arg1) {
+  return window['go']['main']['App']['ExportVault'](arg1);
+}
+
+export function ExportVectorData(arg1) {
+  return window['go']['main']['App']['ExportVectorData'](arg1);
+}
+
 export function FetchLinkMetadata(arg1) {
   return window['go']['main']['App']['FetchLinkMetadata'](arg1);
 }
 
+export function FindBrokenFileReferences() {
+  return window['go']['main']['App']['FindBrokenFileReferences']();
+}
+
 export function GetAllTags() {
   return window['go']['main']['App']['GetAllTags']();
 }
@@ -62,14 +118,26 @@ export function GetAppInfo() {
   return window['go']['main']['App']['GetAppInfo']();
 }
 
-export function GetDocumentGraph(arg1) {
-  return window['go']['main']['App']['GetDocumentGraph'](arg1);
+export function GetBacklinks(arg1) {
+  return window['go']['main']['App']['GetBacklinks'](arg1);
+}
+
+export function GetDocumentGraph(arg1, arg2) {
+  return window['go']['main']['App']['GetDocumentGraph'](arg1, arg2);
+}
+
+export function GetDocumentGraphANN(arg1, arg2) {
+  return window['go']['main']['App']['GetDocumentGraphANN'](arg1, arg2);
 }
 
 export function GetDocumentList() {
   return window['go']['main']['App']['GetDocumentList']();
 }
 
+export function GetDocumentStats(arg1) {
+  return window['go']['main']['App']['GetDocumentStats'](arg1);
+}
+
 export function GetDocumentVectors() {
   return window['go']['main']['App']['GetDocumentVectors']();
 }
@@ -102,20 +170,52 @@ export function GetRAGStatus() {
   return window['go']['main']['App']['GetRAGStatus']();
 }
 
+export function GetRecentSearches(arg1) {
+  return window['go']['main']['App']['GetRecentSearches'](arg1);
+}
+
 export function GetSettings() {
   return window['go']['main']['App']['GetSettings']();
 }
 
+export function GetStartupValidationReport() {
+  return window['go']['main']['App']['GetStartupValidationReport']();
+}
+
+export function GetStorageUsage() {
+  return window['go']['main']['App']['GetStorageUsage']();
+}
+
 export function GetTagColors() {
   return window['go']['main']['App']['GetTagColors']();
 }
 
+export function GetTempDirSize() {
+  return window['go']['main']['App']['GetTempDirSize']();
+}
+
+export function GetTopicClusters(arg1) {
+  return window['go']['main']['App']['GetTopicClusters'](arg1);
+}
+
+export function GetTrash() {
+  return window['go']['main']['App']['GetTrash']();
+}
+
+export function GetUntaggedDocuments() {
+  return window['go']['main']['App']['GetUntaggedDocuments']();
+}
+
 export function ImportMarkdownFile() {
   return window['go']['main']['App']['ImportMarkdownFile']();
 }
 
-export function IndexBookmarkContent(arg1, arg2, arg3) {
-  return window['go']['main']['App']['IndexBookmarkContent'](arg1, arg2, arg3);
+export function ImportMarkdownFolder() {
+  return window['go']['main']['App']['ImportMarkdownFolder']();
+}
+
+export function IndexBookmarkContent(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['IndexBookmarkContent'](arg1, arg2, arg3, arg4);
 }
 
 export function IndexFileContent(arg1, arg2, arg3, arg4) {
@@ -126,10 +226,22 @@ export function IndexFolderContent(arg1, arg2, arg3) {
   return window['go']['main']['App']['IndexFolderContent'](arg1, arg2, arg3);
 }
 
+export function IsArchiveStale(arg1, arg2, arg3) {
+  return window['go']['main']['App']['IsArchiveStale'](arg1, arg2, arg3);
+}
+
 export function ListModels(arg1, arg2, arg3) {
   return window['go']['main']['App']['ListModels'](arg1, arg2, arg3);
 }
 
+export function ListVectorBackups() {
+  return window['go']['main']['App']['ListVectorBackups']();
+}
+
+export function ListVersions(arg1) {
+  return window['go']['main']['App']['ListVersions'](arg1);
+}
+
 export function LoadDocumentContent(arg1) {
   return window['go']['main']['App']['LoadDocumentContent'](arg1);
 }
@@ -138,6 +250,10 @@ export function LoadExternalFile(arg1) {
   return window['go']['main']['App']['LoadExternalFile'](arg1);
 }
 
+export function MergeDocuments(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MergeDocuments'](arg1, arg2, arg3);
+}
+
 export function OpenExternalFile() {
   return window['go']['main']['App']['OpenExternalFile']();
 }
@@ -150,10 +266,18 @@ export function OpenFileWithSystem(arg1) {
   return window['go']['main']['App']['OpenFileWithSystem'](arg1);
 }
 
+export function OpenURL(arg1) {
+  return window['go']['main']['App']['OpenURL'](arg1);
+}
+
 export function PinTag(arg1) {
   return window['go']['main']['App']['PinTag'](arg1);
 }
 
+export function PlanReindex() {
+  return window['go']['main']['App']['PlanReindex']();
+}
+
 export function PrintHTML(arg1, arg2) {
   return window['go']['main']['App']['PrintHTML'](arg1, arg2);
 }
@@ -166,6 +290,10 @@ export function RebuildIndex() {
   return window['go']['main']['App']['RebuildIndex']();
 }
 
+export function RecordSearchFeedback(arg1, arg2, arg3) {
+  return window['go']['main']['App']['RecordSearchFeedback'](arg1, arg2, arg3);
+}
+
 export function RemoveDocumentTag(arg1, arg2) {
   return window['go']['main']['App']['RemoveDocumentTag'](arg1, arg2);
 }
@@ -186,6 +314,26 @@ export function ReorderPinnedTags(arg1) {
   return window['go']['main']['App']['ReorderPinnedTags'](arg1);
 }
 
+export function RepairIndex() {
+  return window['go']['main']['App']['RepairIndex']();
+}
+
+export function RestoreDocument(arg1) {
+  return window['go']['main']['App']['RestoreDocument'](arg1);
+}
+
+export function RestoreVectorBackup(arg1) {
+  return window['go']['main']['App']['RestoreVectorBackup'](arg1);
+}
+
+export function RestoreVersion(arg1, arg2) {
+  return window['go']['main']['App']['RestoreVersion'](arg1, arg2);
+}
+
+export function RevealDocument(arg1) {
+  return window['go']['main']['App']['RevealDocument'](arg1);
+}
+
 export function RevealInFinder(arg1) {
   return window['go']['main']['App']['RevealInFinder'](arg1);
 }
@@ -210,8 +358,8 @@ export function SaveImageFile(arg1, arg2) {
   return window['go']['main']['App']['SaveImageFile'](arg1, arg2);
 }
 
-export function SaveRAGConfig(arg1) {
-  return window['go']['main']['App']['SaveRAGConfig'](arg1);
+export function SaveRAGConfig(arg1, arg2) {
+  return window['go']['main']['App']['SaveRAGConfig'](arg1, arg2);
 }
 
 export function SaveSettings(arg1) {
@@ -222,6 +370,10 @@ export function SearchDocuments(arg1) {
   return window['go']['main']['App']['SearchDocuments'](arg1);
 }
 
+export function SearchFaceted(arg1, arg2) {
+  return window['go']['main']['App']['SearchFaceted'](arg1, arg2);
+}
+
 export function SelectFolderDialog() {
   return window['go']['main']['App']['SelectFolderDialog']();
 }
@@ -230,10 +382,22 @@ export function SemanticSearchDocuments(arg1, arg2, arg3) {
   return window['go']['main']['App']['SemanticSearchDocuments'](arg1, arg2, arg3);
 }
 
+export function SemanticSearchDocumentsPaged(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['SemanticSearchDocumentsPaged'](arg1, arg2, arg3, arg4);
+}
+
+export function SemanticSearchWithFilter(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SemanticSearchWithFilter'](arg1, arg2, arg3);
+}
+
 export function SetActiveDocument(arg1) {
   return window['go']['main']['App']['SetActiveDocument'](arg1);
 }
 
+export function SetDocumentEmbedModel(arg1, arg2) {
+  return window['go']['main']['App']['SetDocumentEmbedModel'](arg1, arg2);
+}
+
 export function SetPinnedTagCollapsed(arg1, arg2) {
   return window['go']['main']['App']['SetPinnedTagCollapsed'](arg1, arg2);
 }
@@ -254,6 +418,10 @@ export function TestConnection(arg1) {
   return window['go']['main']['App']['TestConnection'](arg1);
 }
 
+export function TestRAGConnection() {
+  return window['go']['main']['App']['TestRAGConnection']();
+}
+
 export function UnarchiveFile(arg1) {
   return window['go']['main']['App']['UnarchiveFile'](arg1);
 }
@@ -262,6 +430,10 @@ export function UnpinTag(arg1) {
   return window['go']['main']['App']['UnpinTag'](arg1);
 }
 
+export function VerifyIndex() {
+  return window['go']['main']['App']['VerifyIndex']();
+}
+
 export function WarmupRAG() {
   return window['go']['main']['App']['WarmupRAG']();
 }
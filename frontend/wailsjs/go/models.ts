@@ -5,6 +5,7 @@ export namespace document {
 	    title: string;
 	    folderId?: string;
 	    tags?: string[];
+	    embedModel?: string;
 	    order: number;
 	    createdAt: number;
 	    updatedAt: number;
@@ -19,6 +20,7 @@ export namespace document {
 	        this.title = source["title"];
 	        this.folderId = source["folderId"];
 	        this.tags = source["tags"];
+	        this.embedModel = source["embedModel"];
 	        this.order = source["order"];
 	        this.createdAt = source["createdAt"];
 	        this.updatedAt = source["updatedAt"];
@@ -56,6 +58,98 @@ export namespace document {
 		    return a;
 		}
 	}
+	export class TrashEntry {
+	    id: string;
+	    title: string;
+	    folderId?: string;
+	    tags?: string[];
+	    order: number;
+	    createdAt: number;
+	    updatedAt: number;
+	    deletedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TrashEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.folderId = source["folderId"];
+	        this.tags = source["tags"];
+	        this.order = source["order"];
+	        this.createdAt = source["createdAt"];
+	        this.updatedAt = source["updatedAt"];
+	        this.deletedAt = source["deletedAt"];
+	    }
+	}
+	export class ValidationIssue {
+	    docId: string;
+	    title: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.title = source["title"];
+	        this.error = source["error"];
+	    }
+	}
+	export class ValidationReport {
+	    done: boolean;
+	    checked: number;
+	    invalid: ValidationIssue[];
+	    checkedAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.done = source["done"];
+	        this.checked = source["checked"];
+	        this.invalid = this.convertValues(source["invalid"], ValidationIssue);
+	        this.checkedAt = source["checkedAt"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Version {
+	    timestamp: number;
+	    size: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Version(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.size = source["size"];
+	    }
+	}
 
 }
 
@@ -64,6 +158,7 @@ export namespace handlers {
 	export class ArchiveResult {
 	    archivedPath: string;
 	    archivedAt: number;
+	    refCount: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new ArchiveResult(source);
@@ -73,6 +168,47 @@ export namespace handlers {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.archivedPath = source["archivedPath"];
 	        this.archivedAt = source["archivedAt"];
+	        this.refCount = source["refCount"];
+	    }
+	}
+	export class BacklinkRef {
+	    docId: string;
+	    docTitle: string;
+	    blockId: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BacklinkRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.docTitle = source["docTitle"];
+	        this.blockId = source["blockId"];
+	        this.text = source["text"];
+	    }
+	}
+	export class BrokenRef {
+	    docId: string;
+	    docTitle: string;
+	    blockId: string;
+	    blockType: string;
+	    name: string;
+	    missingPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BrokenRef(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.docTitle = source["docTitle"];
+	        this.blockId = source["blockId"];
+	        this.blockType = source["blockType"];
+	        this.name = source["name"];
+	        this.missingPath = source["missingPath"];
 	    }
 	}
 	export class ChunkMatch {
@@ -101,11 +237,62 @@ export namespace handlers {
 	        this.score = source["score"];
 	    }
 	}
+	export class DirUsage {
+	    bytes: number;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DirUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bytes = source["bytes"];
+	        this.count = source["count"];
+	    }
+	}
+	export class DocumentSearchPage {
+	    results: DocumentSearchResult[];
+	    offset: number;
+	    limit: number;
+	    hasMore: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocumentSearchPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.results = this.convertValues(source["results"], DocumentSearchResult);
+	        this.offset = source["offset"];
+	        this.limit = source["limit"];
+	        this.hasMore = source["hasMore"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class DocumentSearchResult {
 	    docId: string;
 	    docTitle: string;
 	    maxScore: number;
 	    matchedChunks: ChunkMatch[];
+	    tags?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new DocumentSearchResult(source);
@@ -117,6 +304,7 @@ export namespace handlers {
 	        this.docTitle = source["docTitle"];
 	        this.maxScore = source["maxScore"];
 	        this.matchedChunks = this.convertValues(source["matchedChunks"], ChunkMatch);
+	        this.tags = source["tags"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -153,6 +341,38 @@ export namespace handlers {
 	        this.content = source["content"];
 	    }
 	}
+	export class FacetedSearchResult {
+	    total: number;
+	    facets: TagFacet[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FacetedSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.facets = this.convertValues(source["facets"], TagFacet);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class FileInfo {
 	    originalPath: string;
 	    fileName: string;
@@ -181,6 +401,38 @@ export namespace handlers {
 	        this.filePath = source["filePath"];
 	    }
 	}
+	export class MergeResult {
+	    document: document.Meta;
+	    failedDeletes?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new MergeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.document = this.convertValues(source["document"], document.Meta);
+	        this.failedDeletes = source["failedDeletes"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class RAGStatus {
 	    enabled: boolean;
 	    indexedDocs: number;
@@ -205,6 +457,38 @@ export namespace handlers {
 	        this.lastIndexTime = source["lastIndexTime"];
 	    }
 	}
+	export class RebuildIndexResult {
+	    documents: rag.ReindexReport;
+	    external: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RebuildIndexResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.documents = this.convertValues(source["documents"], rag.ReindexReport);
+	        this.external = source["external"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class SearchResult {
 	    id: string;
 	    title: string;
@@ -227,6 +511,9 @@ export namespace handlers {
 	    sidebarWidth: number;
 	    fontSize: number;
 	    writingStyle: string;
+	    defaultDocTemplate: string;
+	    disableSearchHistory: boolean;
+	    versionRetention: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
@@ -239,7 +526,112 @@ export namespace handlers {
 	        this.sidebarWidth = source["sidebarWidth"];
 	        this.fontSize = source["fontSize"];
 	        this.writingStyle = source["writingStyle"];
+	        this.defaultDocTemplate = source["defaultDocTemplate"];
+	        this.disableSearchHistory = source["disableSearchHistory"];
+	        this.versionRetention = source["versionRetention"];
+	    }
+	}
+	export class StaleArchive {
+	    blockId: string;
+	    fileName: string;
+	    originalPath: string;
+	    archivedPath: string;
+	    archivedAt: number;
+	    originalModTime: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StaleArchive(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.blockId = source["blockId"];
+	        this.fileName = source["fileName"];
+	        this.originalPath = source["originalPath"];
+	        this.archivedPath = source["archivedPath"];
+	        this.archivedAt = source["archivedAt"];
+	        this.originalModTime = source["originalModTime"];
+	    }
+	}
+	export class StorageUsage {
+	    documents: DirUsage;
+	    images: DirUsage;
+	    files: DirUsage;
+	    vectorDb: DirUsage;
+	    temp: DirUsage;
+	    backups: DirUsage;
+	    trash: DirUsage;
+	    versions: DirUsage;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StorageUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.documents = this.convertValues(source["documents"], DirUsage);
+	        this.images = this.convertValues(source["images"], DirUsage);
+	        this.files = this.convertValues(source["files"], DirUsage);
+	        this.vectorDb = this.convertValues(source["vectorDb"], DirUsage);
+	        this.temp = this.convertValues(source["temp"], DirUsage);
+	        this.backups = this.convertValues(source["backups"], DirUsage);
+	        this.trash = this.convertValues(source["trash"], DirUsage);
+	        this.versions = this.convertValues(source["versions"], DirUsage);
+	        this.total = source["total"];
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TagFacet {
+	    tag: string;
+	    count: number;
+	    results: DocumentSearchResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TagFacet(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tag = source["tag"];
+	        this.count = source["count"];
+	        this.results = this.convertValues(source["results"], DocumentSearchResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -305,9 +697,29 @@ export namespace main {
 
 export namespace markdown {
 	
+	export class FolderImportResult {
+	    imported: number;
+	    skipped: number;
+	    failed: number;
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FolderImportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.imported = source["imported"];
+	        this.skipped = source["skipped"];
+	        this.failed = source["failed"];
+	        this.errors = source["errors"];
+	    }
+	}
 	export class ImportResult {
 	    content: string;
 	    fileName: string;
+	    title?: string;
+	    tags?: string[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ImportResult(source);
@@ -317,6 +729,44 @@ export namespace markdown {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.content = source["content"];
 	        this.fileName = source["fileName"];
+	        this.title = source["title"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class SiteExportResult {
+	    path: string;
+	    documents: number;
+	    assets: number;
+	    failed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SiteExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.documents = source["documents"];
+	        this.assets = source["assets"];
+	        this.failed = source["failed"];
+	    }
+	}
+	export class VaultExportResult {
+	    path: string;
+	    documents: number;
+	    assets: number;
+	    failed: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new VaultExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.documents = source["documents"];
+	        this.assets = source["assets"];
+	        this.failed = source["failed"];
 	    }
 	}
 
@@ -351,6 +801,100 @@ export namespace opengraph {
 
 export namespace rag {
 	
+	export class ClusterMember {
+	    docId: string;
+	    title: string;
+	    similarity: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ClusterMember(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.title = source["title"];
+	        this.similarity = source["similarity"];
+	    }
+	}
+	export class CompactResult {
+	    orphansRemoved: number;
+	    sizeBefore: number;
+	    sizeAfter: number;
+	    bytesReclaimed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CompactResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.orphansRemoved = source["orphansRemoved"];
+	        this.sizeBefore = source["sizeBefore"];
+	        this.sizeAfter = source["sizeAfter"];
+	        this.bytesReclaimed = source["bytesReclaimed"];
+	    }
+	}
+	export class ConnectionStatus {
+	    reachable: boolean;
+	    modelFound: boolean;
+	    dimension: number;
+	    latencyMs: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConnectionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reachable = source["reachable"];
+	        this.modelFound = source["modelFound"];
+	        this.dimension = source["dimension"];
+	        this.latencyMs = source["latencyMs"];
+	        this.error = source["error"];
+	    }
+	}
+	export class DocIndexError {
+	    docId: string;
+	    title: string;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocIndexError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.title = source["title"];
+	        this.error = source["error"];
+	    }
+	}
+	export class DocReindexPlan {
+	    docId: string;
+	    title: string;
+	    add: number;
+	    update: number;
+	    delete: number;
+	    unchanged: number;
+	    chunks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocReindexPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.title = source["title"];
+	        this.add = source["add"];
+	        this.update = source["update"];
+	        this.delete = source["delete"];
+	        this.unchanged = source["unchanged"];
+	        this.chunks = source["chunks"];
+	    }
+	}
 	export class EmbeddingConfig {
 	    provider: string;
 	    baseUrl: string;
@@ -358,6 +902,30 @@ export namespace rag {
 	    apiKey: string;
 	    maxChunkSize: number;
 	    overlap: number;
+	    chunkUnit: string;
+	    sentenceDelimiters: string;
+	    fetchTimeout: number;
+	    usePrefixes: boolean;
+	    autoReindex: boolean;
+	    autoReindexInterval: number;
+	    reindexWorkers: number;
+	    extractWorkers: number;
+	    mmrLambda: number;
+	    embedTitles: boolean;
+	    titleBoost: number;
+	    modelDimensions?: Record<string, number>;
+	    retryMaxAttempts: number;
+	    retryBaseDelayMs: number;
+	    retryJitter: number;
+	    preprocessLowercase: boolean;
+	    preprocessNormalizeWhitespace: boolean;
+	    preprocessStripMarkdown: boolean;
+	    pdfOcr: boolean;
+	    excludedBlockTypes: string[];
+	    maxExtractBytes: number;
+	    csvMaxRows: number;
+	    shortBlockThreshold: number;
+	    maxMergedLength: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new EmbeddingConfig(source);
@@ -371,6 +939,30 @@ export namespace rag {
 	        this.apiKey = source["apiKey"];
 	        this.maxChunkSize = source["maxChunkSize"];
 	        this.overlap = source["overlap"];
+	        this.chunkUnit = source["chunkUnit"];
+	        this.sentenceDelimiters = source["sentenceDelimiters"];
+	        this.fetchTimeout = source["fetchTimeout"];
+	        this.usePrefixes = source["usePrefixes"];
+	        this.autoReindex = source["autoReindex"];
+	        this.autoReindexInterval = source["autoReindexInterval"];
+	        this.reindexWorkers = source["reindexWorkers"];
+	        this.extractWorkers = source["extractWorkers"];
+	        this.mmrLambda = source["mmrLambda"];
+	        this.embedTitles = source["embedTitles"];
+	        this.titleBoost = source["titleBoost"];
+	        this.modelDimensions = source["modelDimensions"];
+	        this.retryMaxAttempts = source["retryMaxAttempts"];
+	        this.retryBaseDelayMs = source["retryBaseDelayMs"];
+	        this.retryJitter = source["retryJitter"];
+	        this.preprocessLowercase = source["preprocessLowercase"];
+	        this.preprocessNormalizeWhitespace = source["preprocessNormalizeWhitespace"];
+	        this.preprocessStripMarkdown = source["preprocessStripMarkdown"];
+	        this.pdfOcr = source["pdfOcr"];
+	        this.excludedBlockTypes = source["excludedBlockTypes"];
+	        this.maxExtractBytes = source["maxExtractBytes"];
+	        this.csvMaxRows = source["csvMaxRows"];
+	        this.shortBlockThreshold = source["shortBlockThreshold"];
+	        this.maxMergedLength = source["maxMergedLength"];
 	    }
 	}
 	export class ExternalBlockContent {
@@ -382,6 +974,8 @@ export namespace rag {
 	    filePath: string;
 	    title: string;
 	    content: string;
+	    truncated: boolean;
+	    contentHash: string;
 	    extractedAt: number;
 	
 	    static createFrom(source: any = {}) {
@@ -398,6 +992,8 @@ export namespace rag {
 	        this.filePath = source["filePath"];
 	        this.title = source["title"];
 	        this.content = source["content"];
+	        this.truncated = source["truncated"];
+	        this.contentHash = source["contentHash"];
 	        this.extractedAt = source["extractedAt"];
 	    }
 	}
@@ -406,6 +1002,10 @@ export namespace rag {
 	    successCount: number;
 	    failedCount: number;
 	    failedFiles: string[];
+	    added: number;
+	    updated: number;
+	    removed: number;
+	    unchanged: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new FolderIndexResult(source);
@@ -417,6 +1017,10 @@ export namespace rag {
 	        this.successCount = source["successCount"];
 	        this.failedCount = source["failedCount"];
 	        this.failedFiles = source["failedFiles"];
+	        this.added = source["added"];
+	        this.updated = source["updated"];
+	        this.removed = source["removed"];
+	        this.unchanged = source["unchanged"];
 	    }
 	}
 	export class GraphLink {
@@ -497,6 +1101,134 @@ export namespace rag {
 	}
 	
 	
+	export class ReindexPlan {
+	    documents: DocReindexPlan[];
+	    add: number;
+	    update: number;
+	    delete: number;
+	    unchanged: number;
+	    embeddings: number;
+	    embedRequests: number;
+	    embedChars: number;
+	    rebuildEmbeddings: number;
+	    rebuildEmbedRequests: number;
+	    rebuildEmbedChars: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReindexPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.documents = this.convertValues(source["documents"], DocReindexPlan);
+	        this.add = source["add"];
+	        this.update = source["update"];
+	        this.delete = source["delete"];
+	        this.unchanged = source["unchanged"];
+	        this.embeddings = source["embeddings"];
+	        this.embedRequests = source["embedRequests"];
+	        this.embedChars = source["embedChars"];
+	        this.rebuildEmbeddings = source["rebuildEmbeddings"];
+	        this.rebuildEmbedRequests = source["rebuildEmbedRequests"];
+	        this.rebuildEmbedChars = source["rebuildEmbedChars"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReindexReport {
+	    total: number;
+	    indexed: number;
+	    failed: DocIndexError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReindexReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total = source["total"];
+	        this.indexed = source["indexed"];
+	        this.failed = this.convertValues(source["failed"], DocIndexError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SearchExportResult {
+	    path: string;
+	    format: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.format = source["format"];
+	        this.count = source["count"];
+	    }
+	}
+	export class SearchFilter {
+	    docId?: string;
+	    sourceBlockId?: string;
+	    excludeDocId?: string;
+	    docIds?: string[];
+	    tags?: string[];
+	    blockTypes?: string[];
+	    excludeDocIds?: string[];
+	    excludeBookmarks?: boolean;
+	    diversify?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchFilter(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.docId = source["docId"];
+	        this.sourceBlockId = source["sourceBlockId"];
+	        this.excludeDocId = source["excludeDocId"];
+	        this.docIds = source["docIds"];
+	        this.tags = source["tags"];
+	        this.blockTypes = source["blockTypes"];
+	        this.excludeDocIds = source["excludeDocIds"];
+	        this.excludeBookmarks = source["excludeBookmarks"];
+	        this.diversify = source["diversify"];
+	    }
+	}
 	export class TestConnectionResult {
 	    success: boolean;
 	    dimension: number;
@@ -513,6 +1245,76 @@ export namespace rag {
 	        this.error = source["error"];
 	    }
 	}
+	export class TopicCluster {
+	    id: number;
+	    terms: string[];
+	    docs: ClusterMember[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TopicCluster(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.terms = source["terms"];
+	        this.docs = this.convertValues(source["docs"], ClusterMember);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class VectorBackup {
+	    name: string;
+	    size: number;
+	    createdAt: number;
+	    dimension: number;
+	    model: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new VectorBackup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.size = source["size"];
+	        this.createdAt = source["createdAt"];
+	        this.dimension = source["dimension"];
+	        this.model = source["model"];
+	    }
+	}
+	export class VectorExportResult {
+	    path: string;
+	    count: number;
+	    dimension: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VectorExportResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.count = source["count"];
+	        this.dimension = source["dimension"];
+	    }
+	}
 	export class VectorGraphNode {
 	    id: string;
 	    type: string;
@@ -569,6 +1371,73 @@ export namespace rag {
 		    return a;
 		}
 	}
+	export class VerifyReport {
+	    metadata: number;
+	    vectors: number;
+	    orphanedMetadata: number;
+	    orphanedVectors: number;
+	    dimensionMismatch: number;
+	    repaired: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new VerifyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.metadata = source["metadata"];
+	        this.vectors = source["vectors"];
+	        this.orphanedMetadata = source["orphanedMetadata"];
+	        this.orphanedVectors = source["orphanedVectors"];
+	        this.dimensionMismatch = source["dimensionMismatch"];
+	        this.repaired = source["repaired"];
+	    }
+	}
+
+}
+
+export namespace search {
+	
+	export class DocStats {
+	    words: number;
+	    characters: number;
+	    cjkCharacters: number;
+	    blocks: number;
+	    blockTypes: Record<string, number>;
+	    readingMinutes: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DocStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.words = source["words"];
+	        this.characters = source["characters"];
+	        this.cjkCharacters = source["cjkCharacters"];
+	        this.blocks = source["blocks"];
+	        this.blockTypes = source["blockTypes"];
+	        this.readingMinutes = source["readingMinutes"];
+	    }
+	}
+	export class HistoryEntry {
+	    query: string;
+	    mode: string;
+	    resultCount: number;
+	    timestamp: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.query = source["query"];
+	        this.mode = source["mode"];
+	        this.resultCount = source["resultCount"];
+	        this.timestamp = source["timestamp"];
+	    }
+	}
 
 }
 
@@ -15,6 +15,7 @@ require (
 	github.com/xuri/excelize/v2 v2.10.0
 	golang.design/x/clipboard v0.7.1
 	golang.org/x/net v0.46.0
+	golang.org/x/text v0.30.0
 )
 
 require (
@@ -54,7 +55,6 @@ require (
 	golang.org/x/image v0.32.0 // indirect
 	golang.org/x/mobile v0.0.0-20250606033058-a2a15c67f36f // indirect
 	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/text v0.30.0 // indirect
 )
 
 // replace github.com/wailsapp/wails/v2 v2.11.0 => /Users/seven/go/pkg/mod
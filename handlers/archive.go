@@ -1,30 +1,51 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"notion-lite/internal/document"
 )
 
 // ArchiveHandler 文件归档处理器
 type ArchiveHandler struct {
 	*BaseHandler
+	docRepo    *document.Repository
+	docStorage *document.Storage
+	mu         sync.Mutex
+}
+
+// archiveEntry 归档文件引用记录（按内容哈希去重）
+type archiveEntry struct {
+	Filename string `json:"filename"`
+	RefCount int    `json:"refCount"`
 }
 
 // NewArchiveHandler 创建归档处理器
-func NewArchiveHandler(base *BaseHandler) *ArchiveHandler {
-	return &ArchiveHandler{BaseHandler: base}
+func NewArchiveHandler(base *BaseHandler, docRepo *document.Repository, docStorage *document.Storage) *ArchiveHandler {
+	return &ArchiveHandler{
+		BaseHandler: base,
+		docRepo:     docRepo,
+		docStorage:  docStorage,
+	}
 }
 
 // ArchiveResult 归档操作结果
 type ArchiveResult struct {
 	ArchivedPath string `json:"archivedPath"`
 	ArchivedAt   int64  `json:"archivedAt"`
+	RefCount     int    `json:"refCount"`
 }
 
 // ArchiveFile 将文件归档到本地存储
+// 相同内容的文件只保存一份，重复归档时复用已有副本并增加引用计数
 func (h *ArchiveHandler) ArchiveFile(originalPath string) (*ArchiveResult, error) {
 	// 检查源文件是否存在
 	if _, err := os.Stat(originalPath); os.IsNotExist(err) {
@@ -43,8 +64,40 @@ func (h *ArchiveHandler) ArchiveFile(originalPath string) (*ArchiveResult, error
 		return nil, fmt.Errorf("failed to create files directory: %w", err)
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.archiveData(data, filepath.Ext(originalPath))
+}
+
+// archiveData 按内容哈希归档数据（调用方需持有锁）
+func (h *ArchiveHandler) archiveData(data []byte, ext string) (*ArchiveResult, error) {
+	index, err := h.loadArchiveIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := contentHash(data)
+	filesDir := h.Paths().FilesDir()
+
+	// 已存在相同内容的归档副本，复用并增加引用计数
+	if entry, ok := index[hash]; ok {
+		if _, err := os.Stat(filepath.Join(filesDir, entry.Filename)); err == nil {
+			entry.RefCount++
+			if err := h.saveArchiveIndex(index); err != nil {
+				return nil, err
+			}
+			return &ArchiveResult{
+				ArchivedPath: "/files/" + entry.Filename,
+				ArchivedAt:   time.Now().Unix(),
+				RefCount:     entry.RefCount,
+			}, nil
+		}
+		// 物理文件已丢失，重新写入
+		delete(index, hash)
+	}
+
 	// 生成唯一文件名
-	ext := filepath.Ext(originalPath)
 	filename := fmt.Sprintf("%d-%s%s", time.Now().UnixMilli(), randomString(6), ext)
 	archivedPath := filepath.Join(filesDir, filename)
 
@@ -53,18 +106,51 @@ func (h *ArchiveHandler) ArchiveFile(originalPath string) (*ArchiveResult, error
 		return nil, fmt.Errorf("failed to archive file: %w", err)
 	}
 
+	index[hash] = &archiveEntry{Filename: filename, RefCount: 1}
+	if err := h.saveArchiveIndex(index); err != nil {
+		return nil, err
+	}
+
 	return &ArchiveResult{
 		ArchivedPath: "/files/" + filename,
 		ArchivedAt:   time.Now().Unix(),
+		RefCount:     1,
 	}, nil
 }
 
 // UnarchiveFile 删除归档的本地副本
+// 仅当引用计数归零时才物理删除文件
 func (h *ArchiveHandler) UnarchiveFile(archivedPath string) error {
 	if archivedPath == "" {
 		return nil
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.releaseArchive(archivedPath)
+}
+
+// releaseArchive 释放一次归档引用（调用方需持有锁）
+func (h *ArchiveHandler) releaseArchive(archivedPath string) error {
+	index, err := h.loadArchiveIndex()
+	if err != nil {
+		return err
+	}
+
+	// 减少引用计数，仍有引用时保留文件
+	filename := filepath.Base(archivedPath)
+	if hash, entry := findArchiveEntry(index, filename); entry != nil {
+		entry.RefCount--
+		if entry.RefCount > 0 {
+			return h.saveArchiveIndex(index)
+		}
+		delete(index, hash)
+		if err := h.saveArchiveIndex(index); err != nil {
+			return err
+		}
+	}
+
 	// 构建完整路径
 	fullPath := filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(archivedPath, "/"))
 
@@ -81,7 +167,61 @@ func (h *ArchiveHandler) UnarchiveFile(archivedPath string) error {
 	return nil
 }
 
+// RetainDocumentArchives 为文档中引用的每个归档文件增加一次引用计数
+// 用于复制出新文档（如合并）时，使新文档的引用与来源文档独立计数
+func (h *ArchiveHandler) RetainDocumentArchives(docID string) error {
+	content, err := h.docStorage.Load(docID)
+	if err != nil {
+		return fmt.Errorf("failed to load document: %w", err)
+	}
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		return fmt.Errorf("failed to parse document: %w", err)
+	}
+	var archivedPaths []string
+	collectArchivedPaths(blocks, &archivedPaths)
+	if len(archivedPaths) == 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	index, err := h.loadArchiveIndex()
+	if err != nil {
+		return err
+	}
+	for _, archivedPath := range archivedPaths {
+		if _, entry := findArchiveEntry(index, filepath.Base(archivedPath)); entry != nil {
+			entry.RefCount++
+		}
+	}
+	return h.saveArchiveIndex(index)
+}
+
+// collectArchivedPaths 递归收集已归档文件块的归档路径（每个块计一次）
+func collectArchivedPaths(blocks []interface{}, paths *[]string) {
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if blockType, _ := block["type"].(string); blockType == "file" {
+			props, _ := block["props"].(map[string]interface{})
+			archived, _ := props["archived"].(bool)
+			archivedPath, _ := props["archivedPath"].(string)
+			if archived && archivedPath != "" {
+				*paths = append(*paths, archivedPath)
+			}
+		}
+		if children, ok := block["children"].([]interface{}); ok {
+			collectArchivedPaths(children, paths)
+		}
+	}
+}
+
 // SyncArchivedFile 从原始路径同步更新归档副本
+// 归档副本可能被多处引用，内容变化时释放旧引用并按新内容重新归档
 func (h *ArchiveHandler) SyncArchivedFile(originalPath, archivedPath string) (*ArchiveResult, error) {
 	// 检查源文件是否存在
 	if _, err := os.Stat(originalPath); os.IsNotExist(err) {
@@ -94,18 +234,28 @@ func (h *ArchiveHandler) SyncArchivedFile(originalPath, archivedPath string) (*A
 		return nil, fmt.Errorf("failed to read source file: %w", err)
 	}
 
-	// 构建归档文件完整路径
-	fullArchivedPath := filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(archivedPath, "/"))
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	index, err := h.loadArchiveIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// 内容未变化，直接返回
+	if entry, ok := index[contentHash(data)]; ok && entry.Filename == filepath.Base(archivedPath) {
+		return &ArchiveResult{
+			ArchivedPath: archivedPath,
+			ArchivedAt:   time.Now().Unix(),
+			RefCount:     entry.RefCount,
+		}, nil
+	}
 
-	// 覆盖写入
-	if err := os.WriteFile(fullArchivedPath, data, 0644); err != nil {
+	if err := h.releaseArchive(archivedPath); err != nil {
 		return nil, fmt.Errorf("failed to sync archived file: %w", err)
 	}
 
-	return &ArchiveResult{
-		ArchivedPath: archivedPath,
-		ArchivedAt:   time.Now().Unix(),
-	}, nil
+	return h.archiveData(data, filepath.Ext(originalPath))
 }
 
 // CheckFileExists 检查文件是否存在
@@ -126,3 +276,258 @@ func (h *ArchiveHandler) GetEffectiveFilePath(originalPath, archivedPath string,
 	// 回退到原始路径
 	return originalPath
 }
+
+// StaleArchive 原文件已在归档后被修改的文件块
+type StaleArchive struct {
+	BlockID         string `json:"blockId"`
+	FileName        string `json:"fileName"`
+	OriginalPath    string `json:"originalPath"`
+	ArchivedPath    string `json:"archivedPath"`
+	ArchivedAt      int64  `json:"archivedAt"`      // 归档时间戳（秒）
+	OriginalModTime int64  `json:"originalModTime"` // 原文件修改时间戳（秒）
+}
+
+// CheckArchiveStaleness 检查文档中已归档的文件块，返回原文件比归档副本更新的块
+func (h *ArchiveHandler) CheckArchiveStaleness(docID string) ([]StaleArchive, error) {
+	content, err := h.docStorage.Load(docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document: %w", err)
+	}
+	var blocks []interface{}
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	stale := make([]StaleArchive, 0)
+	h.collectStaleArchives(blocks, &stale)
+	return stale, nil
+}
+
+// collectStaleArchives 递归收集过期的归档文件块
+func (h *ArchiveHandler) collectStaleArchives(blocks []interface{}, stale *[]StaleArchive) {
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if blockType, _ := block["type"].(string); blockType == "file" {
+			props, _ := block["props"].(map[string]interface{})
+			archived, _ := props["archived"].(bool)
+			originalPath, _ := props["originalPath"].(string)
+			archivedPath, _ := props["archivedPath"].(string)
+			archivedAt, _ := props["archivedAt"].(float64)
+			if archived {
+				if modTime, ok := h.archiveStaleSince(originalPath, archivedPath, int64(archivedAt)); ok {
+					id, _ := block["id"].(string)
+					fileName, _ := props["fileName"].(string)
+					*stale = append(*stale, StaleArchive{
+						BlockID:         id,
+						FileName:        fileName,
+						OriginalPath:    originalPath,
+						ArchivedPath:    archivedPath,
+						ArchivedAt:      int64(archivedAt),
+						OriginalModTime: modTime,
+					})
+				}
+			}
+		}
+		if children, ok := block["children"].([]interface{}); ok {
+			h.collectStaleArchives(children, stale)
+		}
+	}
+}
+
+// IsArchiveStale 原文件是否在归档后被修改（修改时间晚于归档时间且内容不同）
+func (h *ArchiveHandler) IsArchiveStale(originalPath, archivedPath string, archivedAt int64) bool {
+	_, stale := h.archiveStaleSince(originalPath, archivedPath, archivedAt)
+	return stale
+}
+
+// archiveStaleSince 判断归档副本是否过期，过期时返回原文件修改时间（秒）
+// 先比较修改时间，仅在原文件更新时才计算哈希，避免 touch 等无内容变化的误报
+func (h *ArchiveHandler) archiveStaleSince(originalPath, archivedPath string, archivedAt int64) (int64, bool) {
+	if originalPath == "" || archivedPath == "" {
+		return 0, false
+	}
+	info, err := os.Stat(originalPath)
+	if err != nil || info.ModTime().Unix() <= archivedAt {
+		return 0, false
+	}
+
+	data, err := os.ReadFile(originalPath)
+	if err != nil {
+		return 0, false
+	}
+	if archivedHash, ok := h.archivedHash(archivedPath); ok && archivedHash == contentHash(data) {
+		return 0, false
+	}
+	return info.ModTime().Unix(), true
+}
+
+// archivedHash 获取归档副本的内容哈希（优先从归档索引读取）
+func (h *ArchiveHandler) archivedHash(archivedPath string) (string, bool) {
+	h.mu.Lock()
+	index, err := h.loadArchiveIndex()
+	h.mu.Unlock()
+	if err == nil {
+		if hash, entry := findArchiveEntry(index, filepath.Base(archivedPath)); entry != nil {
+			return hash, true
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(archivedPath, "/")))
+	if err != nil {
+		return "", false
+	}
+	return contentHash(data), true
+}
+
+// BrokenRef 失效的文件/文件夹块引用
+type BrokenRef struct {
+	DocID       string `json:"docId"`
+	DocTitle    string `json:"docTitle"`
+	BlockID     string `json:"blockId"`
+	BlockType   string `json:"blockType"`   // "file" | "folder"
+	Name        string `json:"name"`        // 文件名 / 文件夹名
+	MissingPath string `json:"missingPath"` // 无法访问的路径
+}
+
+// FindBrokenFileReferences 扫描所有文档中的文件/文件夹块，返回引用路径（含归档副本）已不存在的块
+func (h *ArchiveHandler) FindBrokenFileReferences() ([]BrokenRef, error) {
+	index, err := h.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	refs := make([]BrokenRef, 0)
+	for _, doc := range index.Documents {
+		content, err := h.docStorage.Load(doc.ID)
+		if err != nil {
+			continue
+		}
+		var blocks []interface{}
+		if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+			continue
+		}
+		h.collectBrokenRefs(blocks, doc, &refs)
+	}
+	return refs, nil
+}
+
+// collectBrokenRefs 递归检查块树中的文件/文件夹引用
+func (h *ArchiveHandler) collectBrokenRefs(blocks []interface{}, doc document.Meta, refs *[]BrokenRef) {
+	for _, b := range blocks {
+		block, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := block["id"].(string)
+		blockType, _ := block["type"].(string)
+		props, _ := block["props"].(map[string]interface{})
+
+		switch blockType {
+		case "file":
+			originalPath, _ := props["originalPath"].(string)
+			if originalPath == "" {
+				originalPath, _ = props["filePath"].(string) // 兼容旧数据
+			}
+			archivedPath, _ := props["archivedPath"].(string)
+			archived, _ := props["archived"].(bool)
+			if originalPath == "" && archivedPath == "" {
+				break // 尚未选择文件
+			}
+
+			missing := h.GetEffectiveFilePath(originalPath, archivedPath, archived)
+			if missing == "" {
+				missing = archivedPath // 仅有归档路径（原路径未记录）
+			}
+			if h.pathExists(missing) {
+				break
+			}
+			name, _ := props["fileName"].(string)
+			if name == "" {
+				name = filepath.Base(missing)
+			}
+			*refs = append(*refs, BrokenRef{
+				DocID: doc.ID, DocTitle: doc.Title, BlockID: id, BlockType: blockType,
+				Name: name, MissingPath: missing,
+			})
+		case "folder":
+			folderPath, _ := props["folderPath"].(string)
+			if folderPath == "" {
+				break
+			}
+			if info, err := os.Stat(folderPath); err == nil && info.IsDir() {
+				break
+			}
+			name, _ := props["folderName"].(string)
+			if name == "" {
+				name = filepath.Base(folderPath)
+			}
+			*refs = append(*refs, BrokenRef{
+				DocID: doc.ID, DocTitle: doc.Title, BlockID: id, BlockType: blockType,
+				Name: name, MissingPath: folderPath,
+			})
+		}
+
+		if children, ok := block["children"].([]interface{}); ok {
+			h.collectBrokenRefs(children, doc, refs)
+		}
+	}
+}
+
+// pathExists 检查路径是否存在（/files/ 开头的数据目录相对路径按数据目录解析）
+func (h *ArchiveHandler) pathExists(path string) bool {
+	if _, err := os.Stat(path); err == nil {
+		return true
+	}
+	if strings.HasPrefix(path, "/files/") {
+		_, err := os.Stat(filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(path, "/")))
+		return err == nil
+	}
+	return false
+}
+
+// loadArchiveIndex 加载归档引用索引（内容哈希 -> 归档记录）
+func (h *ArchiveHandler) loadArchiveIndex() (map[string]*archiveEntry, error) {
+	index := make(map[string]*archiveEntry)
+	data, err := os.ReadFile(h.Paths().ArchiveIndex())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index: %w", err)
+	}
+	return index, nil
+}
+
+// saveArchiveIndex 保存归档引用索引
+func (h *ArchiveHandler) saveArchiveIndex(index map[string]*archiveEntry) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(h.Paths().ArchiveIndex(), data, 0644); err != nil {
+		return fmt.Errorf("failed to save archive index: %w", err)
+	}
+	return nil
+}
+
+// findArchiveEntry 根据归档文件名查找引用记录
+func findArchiveEntry(index map[string]*archiveEntry, filename string) (string, *archiveEntry) {
+	for hash, entry := range index {
+		if entry.Filename == filename {
+			return hash, entry
+		}
+	}
+	return "", nil
+}
+
+// contentHash 计算内容的 SHA-256 哈希
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/utils"
+)
+
+func newTestArchiveHandler(t *testing.T) *ArchiveHandler {
+	t.Helper()
+	paths := utils.NewPathBuilder(t.TempDir())
+	for _, dir := range []string{paths.DocumentsDir(), paths.FilesDir()} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := NewBaseHandler(paths, nil)
+	return NewArchiveHandler(base, document.NewRepository(paths), document.NewStorage(paths))
+}
+
+func TestRetainDocumentArchives(t *testing.T) {
+	h := newTestArchiveHandler(t)
+
+	// 来源文档归档后引用计数为 1
+	result, err := h.archiveData([]byte("report"), ".txt")
+	if err != nil {
+		t.Fatalf("archiveData failed: %v", err)
+	}
+
+	// 合并文档中包含两个引用该归档的文件块（其中一个嵌套）
+	fileBlock := `{"id": "%s", "type": "file", "props": {"archived": true, "archivedPath": "%s"}}`
+	content := fmt.Sprintf(`[
+		%s,
+		{"id": "p", "type": "paragraph", "children": [%s]},
+		{"id": "u", "type": "file", "props": {"archived": false, "archivedPath": ""}}
+	]`, fmt.Sprintf(fileBlock, "a", result.ArchivedPath), fmt.Sprintf(fileBlock, "b", result.ArchivedPath))
+	if err := h.docStorage.Save("merged", content); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.RetainDocumentArchives("merged"); err != nil {
+		t.Fatalf("RetainDocumentArchives failed: %v", err)
+	}
+
+	index, err := h.loadArchiveIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, entry := findArchiveEntry(index, filepath.Base(result.ArchivedPath))
+	if entry == nil || entry.RefCount != 3 {
+		t.Fatalf("Expected refCount 3 after retaining, got %+v", entry)
+	}
+
+	// 来源文档释放引用后，合并文档的副本应仍然存在
+	fullPath := filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(result.ArchivedPath, "/"))
+	if err := h.UnarchiveFile(result.ArchivedPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fullPath); err != nil {
+		t.Errorf("Expected archived file to survive while still referenced: %v", err)
+	}
+}
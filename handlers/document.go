@@ -1,26 +1,37 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"notion-lite/internal/constant"
 	"notion-lite/internal/document"
 	"notion-lite/internal/rag"
 	"notion-lite/internal/search"
+	"notion-lite/internal/settings"
 	"notion-lite/internal/watcher"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // DocumentHandler 文档操作处理器
 type DocumentHandler struct {
 	*BaseHandler
-	docRepo       *document.Repository
-	docStorage    *document.Storage
-	searchService *search.Service
-	ragService    *rag.Service
+	docRepo         *document.Repository
+	docStorage      *document.Storage
+	searchService   *search.Service
+	ragService      *rag.Service
+	settingsService *settings.Service
+
+	// RAG 索引调度（应用内保存与外部变更共用，按文档合并）
+	indexScheduler *rag.IndexScheduler
 
-	// RAG 索引 debounce
-	indexDebounceMu sync.Mutex
-	indexDebounce   map[string]*time.Timer
+	// 启动时的文档校验结果
+	validationMu     sync.Mutex
+	validationReport document.ValidationReport
 }
 
 // NewDocumentHandler 创建文档处理器
@@ -30,15 +41,57 @@ func NewDocumentHandler(
 	docStorage *document.Storage,
 	searchService *search.Service,
 	ragService *rag.Service,
+	settingsService *settings.Service,
 ) *DocumentHandler {
-	return &DocumentHandler{
-		BaseHandler:   base,
-		docRepo:       docRepo,
-		docStorage:    docStorage,
-		searchService: searchService,
-		ragService:    ragService,
-		indexDebounce: make(map[string]*time.Timer),
+	h := &DocumentHandler{
+		BaseHandler:     base,
+		docRepo:         docRepo,
+		docStorage:      docStorage,
+		searchService:   searchService,
+		ragService:      ragService,
+		settingsService: settingsService,
 	}
+	// 最后一次触发 2 秒后索引
+	h.indexScheduler = rag.NewIndexScheduler(2*time.Second, func(docID string) {
+		if h.ragService != nil {
+			_ = h.ragService.IndexDocument(docID) // 忽略索引错误
+		}
+	})
+	return h
+}
+
+// StartValidation 异步校验所有文档能否解析（由 app.startup 调用，不阻塞启动）
+// 发现损坏的文档时发出 "documents:validation-failed" 事件
+func (h *DocumentHandler) StartValidation() {
+	go func() {
+		report, err := document.Validate(h.docRepo, h.docStorage)
+		if err != nil {
+			fmt.Printf("⚠️ [Document] Startup validation failed: %v\n", err)
+		}
+		report.Done = true
+
+		h.validationMu.Lock()
+		h.validationReport = report
+		h.validationMu.Unlock()
+
+		if len(report.Invalid) > 0 {
+			fmt.Printf("⚠️ [Document] %d of %d documents could not be parsed\n", len(report.Invalid), report.Checked)
+			if ctx := h.Context(); ctx != nil {
+				runtime.EventsEmit(ctx, "documents:validation-failed", report)
+			}
+		}
+	}()
+}
+
+// GetStartupValidationReport 获取启动时的文档校验结果（校验未完成时 Done 为 false）
+func (h *DocumentHandler) GetStartupValidationReport() document.ValidationReport {
+	h.validationMu.Lock()
+	defer h.validationMu.Unlock()
+	report := h.validationReport
+	if report.Invalid == nil {
+		report.Invalid = []document.ValidationIssue{}
+	}
+	return report
 }
 
 // GetDocumentList 获取文档列表
@@ -48,22 +101,48 @@ func (h *DocumentHandler) GetDocumentList() (document.Index, error) {
 
 // CreateDocument 创建新文档
 func (h *DocumentHandler) CreateDocument(title string) (document.Meta, error) {
+	return h.CreateDocumentWithTags(title, nil)
+}
+
+// CreateDocumentWithTags 创建带标签的文档（如在标签筛选视图或固定标签组中新建）
+func (h *DocumentHandler) CreateDocumentWithTags(title string, tags []string) (document.Meta, error) {
 	h.MarkIndexWrite()
-	doc, err := h.docRepo.Create(title)
+	doc, err := h.docRepo.CreateWithTags(title, h.defaultContent(title), tags)
 	if err == nil {
 		h.MarkDocumentWrite(doc.ID)
 	}
 	return doc, err
 }
 
-// DeleteDocument 删除文档
+// defaultContent 渲染设置中的新文档模板；未配置或模板无效时返回 nil（空白文档）
+func (h *DocumentHandler) defaultContent(title string) json.RawMessage {
+	if h.settingsService == nil {
+		return nil
+	}
+	s, err := h.settingsService.Get()
+	if err != nil || s.DefaultDocTemplate == "" {
+		return nil
+	}
+	if title == "" {
+		title = constant.DefaultNewDocTitle
+	}
+	content, err := document.RenderTemplate(s.DefaultDocTemplate, title, time.Now())
+	if err != nil {
+		fmt.Printf("⚠️ Invalid default document template, using empty document: %v\n", err)
+		return nil
+	}
+	return content
+}
+
+// DeleteDocument 删除文档（移入回收站）
 func (h *DocumentHandler) DeleteDocument(id string, cleanupImages func()) error {
 	h.MarkIndexWrite()
 	err := h.docRepo.Delete(id)
 	if err == nil {
 		// 更新搜索索引
 		h.searchService.RemoveIndex(id)
-		// 删除 RAG 向量索引
+		// 取消待执行的索引，删除 RAG 向量索引
+		h.indexScheduler.Cancel(id)
 		if h.ragService != nil {
 			go func() { _ = h.ragService.DeleteDocument(id) }()
 		}
@@ -75,10 +154,27 @@ func (h *DocumentHandler) DeleteDocument(id string, cleanupImages func()) error
 	return err
 }
 
-// RenameDocument 重命名文档
+// RenameDocument 重命名文档（启用标题索引时重新嵌入标题）
 func (h *DocumentHandler) RenameDocument(id string, newTitle string) error {
 	h.MarkIndexWrite()
-	return h.docRepo.Rename(id, newTitle)
+	err := h.docRepo.Rename(id, newTitle)
+	if err == nil {
+		// 未变化的正文块按哈希跳过，只有标题 chunk 会被重新嵌入
+		h.scheduleIndex(id)
+	}
+	return err
+}
+
+// SetDocumentEmbedModel 设置文档的 RAG 嵌入模型覆盖（空字符串恢复默认模型），并重新索引该文档
+// 覆盖模型沿用当前服务商配置，且向量维度必须与索引一致，否则索引时报错
+func (h *DocumentHandler) SetDocumentEmbedModel(id string, model string) error {
+	h.MarkIndexWrite()
+	if err := h.docRepo.SetEmbedModel(id, strings.TrimSpace(model)); err != nil {
+		return err
+	}
+	// 模型名计入块哈希，所有块都会用新模型重新嵌入
+	h.scheduleIndex(id)
+	return nil
 }
 
 // SetActiveDocument 设置当前活动文档
@@ -92,8 +188,16 @@ func (h *DocumentHandler) LoadDocumentContent(id string) (string, error) {
 	return h.docStorage.Load(id)
 }
 
-// SaveDocumentContent 保存指定文档内容
-func (h *DocumentHandler) SaveDocumentContent(id string, content string) error {
+// SaveDocumentContent 保存指定文档内容，返回内容是否有变化
+// 内容与已保存的完全一致时（如切换焦点触发的保存）不写入文件，也不更新时间戳、搜索索引和 RAG 索引
+func (h *DocumentHandler) SaveDocumentContent(id string, content string) (bool, error) {
+	if h.docStorage.Unchanged(id, content) {
+		return false, nil
+	}
+
+	// 覆盖前保留旧内容的快照
+	h.snapshot(id, content, versionSnapshotInterval)
+
 	// 标记文件路径，避免触发自己的文件监听事件
 	h.MarkDocumentWrite(id)
 	h.MarkIndexWrite()                // UpdateTimestamp 会修改 index.json
@@ -105,7 +209,16 @@ func (h *DocumentHandler) SaveDocumentContent(id string, content string) error {
 		// 触发 debounced 异步索引
 		h.scheduleIndex(id)
 	}
-	return err
+	return err == nil, err
+}
+
+// GetDocumentStats 获取文档的字数、字符数、块数与预计阅读时间
+func (h *DocumentHandler) GetDocumentStats(id string) (search.DocStats, error) {
+	content, err := h.docStorage.Load(id)
+	if err != nil {
+		return search.DocStats{}, fmt.Errorf("failed to load document: %w", err)
+	}
+	return search.ComputeStats(content), nil
 }
 
 // ReorderDocuments 重新排序文档
@@ -116,25 +229,7 @@ func (h *DocumentHandler) ReorderDocuments(ids []string) error {
 
 // scheduleIndex 调度 debounced 异步索引
 func (h *DocumentHandler) scheduleIndex(docID string) {
-	h.indexDebounceMu.Lock()
-	defer h.indexDebounceMu.Unlock()
-
-	// 取消之前的定时器
-	if timer, exists := h.indexDebounce[docID]; exists {
-		timer.Stop()
-	}
-
-	// 2 秒后触发索引
-	h.indexDebounce[docID] = time.AfterFunc(2*time.Second, func() {
-		h.indexDebounceMu.Lock()
-		delete(h.indexDebounce, docID)
-		h.indexDebounceMu.Unlock()
-
-		// 异步执行索引
-		if h.ragService != nil {
-			_ = h.ragService.IndexDocument(docID) // 忽略索引错误
-		}
-	})
+	h.indexScheduler.Schedule(docID)
 }
 
 // SetupFileWatcher 设置文件监听器回调（由 app.startup 调用）
@@ -155,7 +250,10 @@ func (h *DocumentHandler) OnExternalFileChange(e watcher.FileChangeEvent) {
 		if err == nil {
 			h.searchService.UpdateIndex(e.DocID, content)
 		}
+		// 与应用内保存共用调度器，同一文档不会重复索引
+		h.scheduleIndex(e.DocID)
 	case "remove":
 		h.searchService.RemoveIndex(e.DocID)
+		h.indexScheduler.Cancel(e.DocID)
 	}
 }
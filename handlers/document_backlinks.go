@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"fmt"
+
+	"notion-lite/internal/rag"
+)
+
+// BacklinkRef 引用目标文档的位置
+type BacklinkRef struct {
+	DocID    string `json:"docId"`    // 引用方文档 ID
+	DocTitle string `json:"docTitle"` // 引用方文档标题
+	BlockID  string `json:"blockId"`  // 包含链接的块 ID
+	Text     string `json:"text"`     // 链接文本
+}
+
+// GetBacklinks 查找链接到指定文档的所有文档与块（nook://doc 链接、文档 ID 或标题链接、[[标题]]）
+// 同一块内多个指向目标的链接只返回一次，文档指向自身的链接不计入
+func (h *DocumentHandler) GetBacklinks(id string) ([]BacklinkRef, error) {
+	index, err := h.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document index: %w", err)
+	}
+
+	title := ""
+	for _, meta := range index.Documents {
+		if meta.ID == id {
+			title = meta.Title
+			break
+		}
+	}
+
+	refs := []BacklinkRef{}
+	for _, meta := range index.Documents {
+		if meta.ID == id {
+			continue
+		}
+		content, err := h.docStorage.Load(meta.ID)
+		if err != nil {
+			fmt.Printf("⚠️ [Document] Failed to load %s for backlinks: %v\n", meta.ID, err)
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, link := range rag.ExtractLinks([]byte(content)) {
+			if seen[link.BlockID] || !link.LinksTo(id, title) {
+				continue
+			}
+			seen[link.BlockID] = true
+			refs = append(refs, BacklinkRef{DocID: meta.ID, DocTitle: meta.Title, BlockID: link.BlockID, Text: link.Text})
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"notion-lite/internal/blocknote"
+	"notion-lite/internal/constant"
+	"notion-lite/internal/markdown"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// FolderImportResult 批量导入结果
+type FolderImportResult = markdown.FolderImportResult
+
+// ImportMarkdownFolder 选择文件夹并将其中的 Markdown 文件批量导入为文档
+// 子目录路径作为标签组（通过 pinTag 固定到侧边栏），相对路径引用的图片复制到 images 目录
+func (h *DocumentHandler) ImportMarkdownFolder(pinTag func(name string) error) (*FolderImportResult, error) {
+	root, err := runtime.OpenDirectoryDialog(h.Context(), runtime.OpenDialogOptions{
+		Title: constant.DialogTitleImportFolder,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == "" {
+		return nil, nil // 用户取消
+	}
+	return h.importMarkdownFolder(root, pinTag)
+}
+
+// importMarkdownFolder 导入 root 下的所有 Markdown 文件
+func (h *DocumentHandler) importMarkdownFolder(root string, pinTag func(name string) error) (*FolderImportResult, error) {
+	files, err := markdown.CollectMarkdownFiles(root, markdown.DefaultFolderImportDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk folder: %w", err)
+	}
+
+	result := &FolderImportResult{Errors: []string{}}
+	copied := make(map[string]string) // 源图片路径 -> /images/ URL（同一图片只复制一次）
+	var groups []string
+	seenGroups := make(map[string]bool)
+
+	for _, path := range files {
+		rel, _ := filepath.Rel(root, path)
+		group := markdown.TagGroupForPath(root, path)
+		imported, err := h.importMarkdownFile(path, root, group, copied)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", filepath.ToSlash(rel), err))
+			continue
+		}
+		if !imported {
+			result.Skipped++
+			continue
+		}
+		result.Imported++
+		if group != "" && !seenGroups[group] {
+			seenGroups[group] = true
+			groups = append(groups, group)
+		}
+	}
+
+	if pinTag != nil {
+		for _, group := range groups {
+			if err := pinTag(group); err != nil {
+				fmt.Printf("⚠️ Failed to pin imported tag group %s: %v\n", group, err)
+			}
+		}
+	}
+	fmt.Printf("✅ Imported %d Markdown files from %s (skipped %d, failed %d)\n", result.Imported, root, result.Skipped, result.Failed)
+	return result, nil
+}
+
+// importMarkdownFile 将单个 Markdown 文件创建为文档；空文件返回 false
+// 只复制位于导入根目录 root 之内的图片
+func (h *DocumentHandler) importMarkdownFile(path, root, group string, copied map[string]string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	frontmatter, body := markdown.ParseFrontmatter(string(data))
+	if strings.TrimSpace(body) == "" {
+		return false, nil
+	}
+
+	body = markdown.RewriteImages(body, filepath.Dir(path), root, func(src string) (string, error) {
+		if url, ok := copied[src]; ok {
+			return url, nil
+		}
+		url, err := h.copyImportedImage(src)
+		if err == nil {
+			copied[src] = url
+		}
+		return url, err
+	})
+	content, err := blocknote.FromMarkdown(body)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert markdown: %w", err)
+	}
+
+	title := frontmatter.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	tags := frontmatter.Tags
+	if group != "" {
+		tags = append([]string{group}, tags...)
+	}
+	h.MarkIndexWrite()
+	doc, err := h.docRepo.CreateWithTags(title, content, tags)
+	if err != nil {
+		return false, fmt.Errorf("failed to create document: %w", err)
+	}
+	h.MarkDocumentWrite(doc.ID)
+
+	h.searchService.UpdateIndex(doc.ID, string(content))
+	h.scheduleIndex(doc.ID)
+	return true, nil
+}
+
+// copyImportedImage 复制图片到 images 目录，返回 /images/ URL
+func (h *DocumentHandler) copyImportedImage(src string) (string, error) {
+	imagesDir := h.Paths().ImagesDir()
+	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create images directory: %w", err)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = in.Close() }()
+
+	filename := fmt.Sprintf("%d-%s%s", time.Now().UnixMilli(), randomString(6), strings.ToLower(filepath.Ext(src)))
+	out, err := os.Create(filepath.Join(imagesDir, filename))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return "/images/" + filename, nil
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+
+	"notion-lite/internal/document"
+)
+
+// MergeResult 合并文档的结果
+type MergeResult struct {
+	Document      document.Meta `json:"document"`
+	FailedDeletes []string      `json:"failedDeletes,omitempty"` // 删除失败、仍然保留的来源文档 ID
+}
+
+// MergeDocuments 合并多个文档为一个新文档
+// 内容按 sourceIDs 顺序拼接，标签取并集；deleteSources 为 true 时删除来源文档，删除失败的来源记录在 FailedDeletes 中。
+// 合并后的块 ID 全部重新生成，外部块（书签/文件/文件夹）在新文档下重新索引；
+// retainArchives 为合并文档中复制出的归档文件块增加引用计数
+func (h *DocumentHandler) MergeDocuments(sourceIDs []string, targetTitle string, deleteSources bool, retainArchives func(docID string) error) (MergeResult, error) {
+	sourceIDs = document.UniqueIDs(sourceIDs)
+	h.MarkIndexWrite()
+	doc, err := document.Merge(h.docRepo, h.docStorage, sourceIDs, targetTitle)
+	if err != nil {
+		return MergeResult{}, err
+	}
+	h.MarkDocumentWrite(doc.ID)
+	if retainArchives != nil {
+		if err := retainArchives(doc.ID); err != nil {
+			fmt.Printf("⚠️ Failed to retain archived files of %s: %v\n", doc.ID, err)
+		}
+	}
+
+	if content, err := h.docStorage.Load(doc.ID); err == nil {
+		h.searchService.UpdateIndex(doc.ID, content)
+	}
+	if h.ragService != nil {
+		go func() {
+			if err := h.ragService.IndexDocument(doc.ID); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to index merged document %s: %v\n", doc.ID, err)
+			}
+			if _, err := h.ragService.ReindexDocumentExternal(doc.ID); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to reindex external blocks of %s: %v\n", doc.ID, err)
+			}
+		}()
+	}
+
+	result := MergeResult{Document: doc}
+	if deleteSources {
+		for _, id := range sourceIDs {
+			// 图片仍被合并后的文档引用，无需清理
+			if err := h.DeleteDocument(id, nil); err != nil {
+				fmt.Printf("⚠️ Failed to delete merged source %s: %v\n", id, err)
+				result.FailedDeletes = append(result.FailedDeletes, id)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+
+	"notion-lite/internal/document"
+)
+
+// GetTrash 获取回收站中的文档（最近删除的在前）
+func (h *DocumentHandler) GetTrash() ([]document.TrashEntry, error) {
+	return h.docRepo.GetTrash()
+}
+
+// RestoreDocument 从回收站恢复文档，并重建搜索与 RAG 索引（删除时已清除）
+func (h *DocumentHandler) RestoreDocument(id string) (document.Meta, error) {
+	h.MarkIndexWrite()
+	doc, err := h.docRepo.Restore(id)
+	if err != nil {
+		return document.Meta{}, err
+	}
+	h.MarkDocumentWrite(doc.ID)
+
+	if content, err := h.docStorage.Load(doc.ID); err == nil {
+		h.searchService.UpdateIndex(doc.ID, content)
+	}
+	if h.ragService != nil {
+		go func() {
+			if err := h.ragService.IndexDocument(doc.ID); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to index restored document %s: %v\n", doc.ID, err)
+			}
+			if _, err := h.ragService.ReindexDocumentExternal(doc.ID); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to reindex external blocks of %s: %v\n", doc.ID, err)
+			}
+		}()
+	}
+	return doc, nil
+}
+
+// LoadTrashedContent 读取回收站中文档的内容
+func (h *DocumentHandler) LoadTrashedContent(id string) (string, error) {
+	return h.docRepo.LoadTrashedContent(id)
+}
+
+// EmptyTrash 永久删除回收站中的文档，返回删除数量
+// 回收站文档引用的图片在清空后才会被清理
+func (h *DocumentHandler) EmptyTrash(cleanupImages func()) (int, error) {
+	count, err := h.docRepo.EmptyTrash()
+	if err == nil && count > 0 && cleanupImages != nil {
+		go cleanupImages()
+	}
+	return count, err
+}
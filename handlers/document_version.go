@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/settings"
+)
+
+// versionSnapshotInterval 应用内保存时两次快照的最小间隔（编辑器频繁自动保存，按时间分桶）
+const versionSnapshotInterval = 10 * time.Minute
+
+// ListVersions 列出文档的历史版本（最新的在前）
+func (h *DocumentHandler) ListVersions(id string) ([]document.Version, error) {
+	return h.docStorage.ListVersions(id)
+}
+
+// RestoreVersion 将文档恢复到指定时间戳的快照，返回恢复后的内容
+// 恢复前会为当前内容强制创建快照，因此恢复操作本身也可以撤销
+func (h *DocumentHandler) RestoreVersion(id string, timestamp int64) (string, error) {
+	content, err := h.docStorage.LoadVersion(id, timestamp)
+	if err != nil {
+		return "", err
+	}
+	h.snapshot(id, content, 0)
+	if _, err := h.SaveDocumentContent(id, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// snapshot 为即将被覆盖的文档内容创建快照（失败只记录日志，不阻止保存）
+func (h *DocumentHandler) snapshot(id string, incoming string, minInterval time.Duration) {
+	keep := settings.DefaultVersionRetention
+	if h.settingsService != nil {
+		if s, err := h.settingsService.Get(); err == nil {
+			keep = s.VersionsToKeep()
+		}
+	}
+	if err := h.docStorage.Snapshot(id, incoming, keep, minInterval); err != nil {
+		fmt.Printf("⚠️ [Document] Failed to snapshot %s: %v\n", id, err)
+	}
+}
@@ -10,10 +10,11 @@ import (
 	"time"
 
 	"notion-lite/internal/constant"
+	"notion-lite/internal/document"
 	"notion-lite/internal/fileextract"
 	"notion-lite/internal/markdown"
 	"notion-lite/internal/opengraph"
-	"notion-lite/internal/utils"
+	"notion-lite/internal/platform"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
@@ -22,16 +23,22 @@ import (
 type FileHandler struct {
 	*BaseHandler
 	markdownService *markdown.Service
+	docRepo         *document.Repository
+	docStorage      *document.Storage
 }
 
 // NewFileHandler 创建文件处理器
 func NewFileHandler(
 	base *BaseHandler,
 	markdownService *markdown.Service,
+	docRepo *document.Repository,
+	docStorage *document.Storage,
 ) *FileHandler {
 	return &FileHandler{
 		BaseHandler:     base,
 		markdownService: markdownService,
+		docRepo:         docRepo,
+		docStorage:      docStorage,
 	}
 }
 
@@ -118,8 +125,72 @@ func (h *FileHandler) PrintHTML(htmlContent string, title string) error {
 		return err
 	}
 
+	// 顺带清理过期的打印文件（刚写入的文件不受影响）
+	_, _ = h.CleanupTempFiles(TempFileMaxAge)
+
 	// 使用系统默认程序打开文件（跨平台）
-	return utils.OpenWithSystemApp(filePath)
+	return platform.OpenPath(filePath)
+}
+
+// TempFileMaxAge 临时文件默认保留时长
+const TempFileMaxAge = 24 * time.Hour
+
+// CleanupTempFiles 删除临时目录中超过指定时长的文件，返回删除数量
+func (h *FileHandler) CleanupTempFiles(olderThan time.Duration) (int, error) {
+	tempDir := h.Paths().TempDir()
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	removed := 0
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := os.Remove(filepath.Join(tempDir, entry.Name())); err == nil {
+				removed++
+			}
+		}
+	}
+
+	return removed, nil
+}
+
+// GetTempDirSize 获取临时目录占用的字节数
+func (h *FileHandler) GetTempDirSize() (int64, error) {
+	entries, err := os.ReadDir(h.Paths().TempDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		size += info.Size()
+	}
+
+	return size, nil
 }
 
 // FetchLinkMetadata 获取链接的 Open Graph 元数据
@@ -251,50 +322,36 @@ func (h *FileHandler) CopyFileToStorage(sourcePath string) (*FileInfo, error) {
 
 // OpenFileWithSystem 使用系统默认应用打开文件
 func (h *FileHandler) OpenFileWithSystem(pathOrRelative string) error {
-	fmt.Println("[OpenFileWithSystem] called with:", pathOrRelative)
-	var fullPath string
-
-	// 检查是否是应用内相对路径（如 /files/xxx, /images/xxx）
-	isAppRelativePath := strings.HasPrefix(pathOrRelative, "/files/") ||
-		strings.HasPrefix(pathOrRelative, "/images/") ||
-		strings.HasPrefix(pathOrRelative, "/temp/")
-
-	if !isAppRelativePath && filepath.IsAbs(pathOrRelative) {
-		// 真正的绝对路径（如 FolderBlock 的 /Users/xxx/folderPath）
-		fullPath = pathOrRelative
-	} else {
-		// 应用内相对路径（如 /files/xxx.md）
-		fullPath = filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(pathOrRelative, "/"))
-	}
-	fmt.Println("[OpenFileWithSystem] resolved fullPath:", fullPath)
-
-	err := utils.OpenWithSystemApp(fullPath)
-	if err != nil {
-		fmt.Println("[OpenFileWithSystem] error:", err)
-	} else {
-		fmt.Println("[OpenFileWithSystem] success")
-	}
-	return err
+	return platform.OpenPath(h.resolveFilePath(pathOrRelative))
 }
 
 // RevealInFinder 在文件管理器中显示文件
 func (h *FileHandler) RevealInFinder(pathOrRelative string) error {
-	var fullPath string
+	return platform.RevealPath(h.resolveFilePath(pathOrRelative))
+}
+
+// RevealDocument 在文件管理器中显示文档的存储文件（文档不存在时返回错误）
+func (h *FileHandler) RevealDocument(id string) error {
+	return platform.RevealPath(h.Paths().Document(id))
+}
+
+// OpenURL 使用系统默认浏览器打开网址（如书签块链接）
+func (h *FileHandler) OpenURL(rawURL string) error {
+	return platform.OpenURL(rawURL)
+}
 
-	// 检查是否是应用内相对路径（如 /files/xxx, /images/xxx）
+// resolveFilePath 将应用内相对路径（如 /files/xxx, /images/xxx）解析为数据目录下的绝对路径，其他绝对路径原样返回
+func (h *FileHandler) resolveFilePath(pathOrRelative string) string {
 	isAppRelativePath := strings.HasPrefix(pathOrRelative, "/files/") ||
 		strings.HasPrefix(pathOrRelative, "/images/") ||
 		strings.HasPrefix(pathOrRelative, "/temp/")
 
 	if !isAppRelativePath && filepath.IsAbs(pathOrRelative) {
 		// 真正的绝对路径（如 FolderBlock 的 /Users/xxx/folderPath）
-		fullPath = pathOrRelative
-	} else {
-		// 应用内相对路径（如 /files/xxx.md）
-		fullPath = filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(pathOrRelative, "/"))
+		return pathOrRelative
 	}
-
-	return utils.RevealInFileManager(fullPath)
+	// 应用内相对路径（如 /files/xxx.md）
+	return filepath.Join(h.Paths().DataPath(), strings.TrimPrefix(pathOrRelative, "/"))
 }
 
 // randomString 生成随机字符串
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"notion-lite/internal/constant"
+	"notion-lite/internal/markdown"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// PDF 导出方式
+const (
+	PDFExportSaved = "saved" // 已直接生成 PDF 文件
+	PDFExportPrint = "print" // 未找到渲染程序，已在浏览器中打开打印对话框
+)
+
+// autoPrintScript 回退到浏览器打印时注入的脚本
+const autoPrintScript = `<script>window.onload = function() { window.print(); };</script>`
+
+// ExportPDFFile 将完整 HTML 导出为 PDF，本地图片以 data URI 内联
+// 优先使用无头 Chrome 或 wkhtmltopdf 直接生成；都不可用时在浏览器中打开并调起系统打印（可另存为 PDF）
+// 返回导出方式，用户取消时返回空字符串
+func (h *FileHandler) ExportPDFFile(content string, defaultName string) (string, error) {
+	if defaultName == "" {
+		defaultName = constant.DefaultExportName
+	}
+	html := markdown.InlineImages(content, h.vaultAssetPath)
+
+	renderer := markdown.FindPDFRenderer()
+	if renderer == nil {
+		fmt.Println("💡 No headless Chrome or wkhtmltopdf found, falling back to print dialog")
+		if i := strings.LastIndex(strings.ToLower(html), "</body>"); i >= 0 {
+			html = html[:i] + autoPrintScript + "\n" + html[i:]
+		} else {
+			html += autoPrintScript
+		}
+		if err := h.PrintHTML(html, defaultName); err != nil {
+			return "", err
+		}
+		return PDFExportPrint, nil
+	}
+
+	filePath, err := runtime.SaveFileDialog(h.Context(), runtime.SaveDialogOptions{
+		Title:           constant.DialogTitleExportPDF,
+		DefaultFilename: defaultName + ".pdf",
+		Filters: []runtime.FileFilter{
+			{DisplayName: constant.FilterPDF, Pattern: "*.pdf"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if filePath == "" {
+		return "", nil // 用户取消
+	}
+	if !strings.HasSuffix(strings.ToLower(filePath), ".pdf") {
+		filePath += ".pdf"
+	}
+
+	tempDir := h.Paths().TempDir()
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	htmlPath := filepath.Join(tempDir, fmt.Sprintf("pdf_%s_%d.html", sanitizeFilename(defaultName), time.Now().UnixMilli()))
+	if err := os.WriteFile(htmlPath, []byte(html), 0644); err != nil {
+		return "", err
+	}
+	defer func() { _ = os.Remove(htmlPath) }()
+
+	if err := renderer.Render(htmlPath, filePath); err != nil {
+		return "", fmt.Errorf("failed to render PDF: %w", err)
+	}
+	fmt.Printf("💾 Exported PDF via %s: %s\n", renderer.Name, filePath)
+	return PDFExportSaved, nil
+}
@@ -2,6 +2,10 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"notion-lite/internal/document"
 	"notion-lite/internal/rag"
@@ -9,11 +13,20 @@ import (
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// RebuildIndexResult 手动重建索引的结果
+type RebuildIndexResult struct {
+	Documents rag.ReindexReport `json:"documents"` // 文档索引结果（含失败文档及原因）
+	External  int               `json:"external"`  // 重新索引的书签和文件块数
+}
+
 // ReindexProgress 重建索引进度信息
 type ReindexProgress struct {
-	Phase   string `json:"phase"`   // "documents" | "external"
-	Current int    `json:"current"` // 当前处理的索引
-	Total   int    `json:"total"`   // 总数
+	Phase        string `json:"phase"`        // "documents" | "external" | "stale"
+	Current      int    `json:"current"`      // 本阶段已完成数
+	Total        int    `json:"total"`        // 本阶段总数
+	Title        string `json:"title"`        // 刚处理完的文档标题（可能为空）
+	OverallDone  int    `json:"overallDone"`  // 所有阶段合计已完成数
+	OverallTotal int    `json:"overallTotal"` // 所有阶段合计总数（为 0 时尚未确定）
 }
 
 // RAGHandler RAG 配置与索引处理器
@@ -21,6 +34,11 @@ type RAGHandler struct {
 	*BaseHandler
 	docRepo    *document.Repository
 	ragService *rag.Service
+	reindexMu  sync.Mutex // 避免手动重建与后台重建并发执行
+
+	reindexCtx    context.Context    // 全量重建的父上下文（应用关闭时取消）
+	runMu         sync.Mutex         // 保护 cancelRebuild
+	cancelRebuild context.CancelFunc // 正在进行的手动重建（修改配置时取消）
 }
 
 // SetContext 设置 Wails 上下文（用于发送事件）
@@ -29,6 +47,35 @@ func (h *RAGHandler) SetContext(ctx context.Context) {
 	h.ragService.SetContext(ctx)
 }
 
+// SetReindexContext 设置全量重建的父上下文，取消后正在进行的重建提前结束
+func (h *RAGHandler) SetReindexContext(ctx context.Context) {
+	h.reindexCtx = ctx
+	h.ragService.SetReindexContext(ctx)
+}
+
+// beginRebuild 为一次手动重建创建可取消的上下文
+func (h *RAGHandler) beginRebuild() context.Context {
+	parent := h.reindexCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	h.runMu.Lock()
+	h.cancelRebuild = cancel
+	h.runMu.Unlock()
+	return ctx
+}
+
+// endRebuild 取消正在进行的手动重建（重建结束或配置变更时调用）
+func (h *RAGHandler) endRebuild() {
+	h.runMu.Lock()
+	defer h.runMu.Unlock()
+	if h.cancelRebuild != nil {
+		h.cancelRebuild()
+		h.cancelRebuild = nil
+	}
+}
+
 // NewRAGHandler 创建 RAG 处理器
 func NewRAGHandler(
 	base *BaseHandler,
@@ -72,12 +119,24 @@ func (h *RAGHandler) GetRAGConfig() (EmbeddingConfig, error) {
 	return *config, nil
 }
 
-// SaveRAGConfig 保存 RAG 配置
-func (h *RAGHandler) SaveRAGConfig(config EmbeddingConfig) error {
-	if err := rag.SaveConfig(h.Paths(), &config); err != nil {
+// SaveRAGConfig 保存 RAG 配置，original 为设置面板加载时的配置，只保存相对它修改过的字段
+func (h *RAGHandler) SaveRAGConfig(original, config EmbeddingConfig) error {
+	// 只合并用户在面板中修改过的字段（面板打开期间可能有新的维度缓存等写入）
+	current, err := rag.LoadConfig(h.Paths())
+	if err != nil {
+		return err
+	}
+	merged, err := rag.MergeConfigChanges(current, &original, &config)
+	if err != nil {
+		return err
+	}
+	if err := rag.SaveConfig(h.Paths(), merged); err != nil {
 		return err
 	}
-	// 重新初始化 RAG 服务
+	// 旧配置下的手动重建已无意义：取消并等待其退出（释放 reindexMu）后再重新初始化 RAG 服务
+	h.endRebuild()
+	h.reindexMu.Lock()
+	defer h.reindexMu.Unlock()
 	return h.ragService.Reinitialize()
 }
 
@@ -100,42 +159,112 @@ func (h *RAGHandler) GetRAGStatus() RAGStatus {
 }
 
 // RebuildIndex 重建 RAG 索引（带进度通知）
-func (h *RAGHandler) RebuildIndex() (int, error) {
-	// 文档索引阶段
-	docCount, err := h.ragService.ReindexAllWithProgress(func(current, total int) {
-		if h.Context() != nil {
-			runtime.EventsEmit(h.Context(), "rag:reindex-progress", ReindexProgress{
-				Phase:   "documents",
-				Current: current,
-				Total:   total,
-			})
-		}
+func (h *RAGHandler) RebuildIndex() (*RebuildIndexResult, error) {
+	return h.RebuildIndexWithEvents(h.Context())
+}
+
+// RebuildIndexWithEvents 重建文档和外部内容的 RAG 索引，通过 eventCtx 发送 "rag:reindex-progress" 事件
+// 事件包含阶段、阶段内进度和刚处理的文档标题；eventCtx 为 nil 时不发送事件
+func (h *RAGHandler) RebuildIndexWithEvents(eventCtx context.Context) (*RebuildIndexResult, error) {
+	h.reindexMu.Lock()
+	defer h.reindexMu.Unlock()
+	ctx := h.beginRebuild()
+	defer h.endRebuild()
+
+	// 预先发送文档总数，前端可立即渲染进度条
+	if index, err := h.docRepo.GetAll(); err == nil {
+		emitReindexProgress(eventCtx, ReindexProgress{Phase: "documents", Total: len(index.Documents)})
+	}
+
+	// 单个文档失败不中断重建，记录在报告中；外部内容未变化的不重新嵌入
+	report, extCount, err := h.ragService.RebuildAll(ctx, false, func(p rag.ReindexProgress) {
+		emitReindexProgress(eventCtx, ReindexProgress{
+			Phase:        p.Phase,
+			Current:      p.Current,
+			Total:        p.Total,
+			Title:        p.Title,
+			OverallDone:  p.OverallDone,
+			OverallTotal: p.OverallTotal,
+		})
 	})
 	if err != nil {
-		return docCount, err
+		return nil, err
 	}
+	return &RebuildIndexResult{Documents: *report, External: extCount}, nil
+}
 
-	// 外部内容索引阶段（书签和文件）
-	extCount, err := h.ragService.ReindexExternalContentWithProgress(func(current, total int) {
-		if h.Context() != nil {
-			runtime.EventsEmit(h.Context(), "rag:reindex-progress", ReindexProgress{
-				Phase:   "external",
-				Current: current,
-				Total:   total,
-			})
+// emitReindexProgress 发送重建索引进度事件（ctx 为 nil 时忽略）
+func emitReindexProgress(ctx context.Context, progress ReindexProgress) {
+	if ctx != nil {
+		runtime.EventsEmit(ctx, "rag:reindex-progress", progress)
+	}
+}
+
+// StartAutoReindex 启动后台定期重建过期文档索引
+// 每轮重新读取配置，设置中的开关和间隔修改无需重启即可生效
+func (h *RAGHandler) StartAutoReindex() {
+	ctx := h.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	go func() {
+		for {
+			interval := rag.DefaultAutoReindexInterval
+			config, err := rag.LoadConfig(h.Paths())
+			if err == nil {
+				interval = config.GetAutoReindexInterval()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+
+			if err == nil && config.AutoReindex {
+				h.reindexStale()
+			}
 		}
+	}()
+}
+
+// reindexStale 执行一轮过期文档重建（正在手动重建时跳过）
+func (h *RAGHandler) reindexStale() {
+	if !h.reindexMu.TryLock() {
+		return
+	}
+	defer h.reindexMu.Unlock()
+
+	count, err := h.ragService.ReindexStaleDocuments(func(current, total int) {
+		emitReindexProgress(h.Context(), ReindexProgress{Phase: "stale", Current: current, Total: total, OverallDone: current, OverallTotal: total})
 	})
+	if errors.Is(err, rag.ErrDimensionChanged) {
+		// 与保存配置相同，在持有 reindexMu 时重新初始化，避免与手动重建并发
+		fmt.Println("⚠️ [RAG] Embedding dimension changed, reinitializing RAG service")
+		if err := h.ragService.Reinitialize(); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to reinitialize RAG service: %v\n", err)
+		}
+		return
+	}
 	if err != nil {
-		return docCount + extCount, err
+		fmt.Printf("⚠️ [RAG] Skipping background reindex: %v\n", err)
+		return
+	}
+	if count > 0 {
+		fmt.Printf("✅ [RAG] Background reindexed %d stale documents\n", count)
+		if h.Context() != nil {
+			runtime.EventsEmit(h.Context(), "rag:status-updated", nil)
+		}
 	}
-
-	return docCount + extCount, nil
 }
 
 // IndexBookmarkContent 索引书签网页内容
-func (h *RAGHandler) IndexBookmarkContent(url, sourceDocID, blockID string) error {
-	err := h.ragService.IndexBookmarkContent(url, sourceDocID, blockID)
-	if err == nil && h.Context() != nil {
+// timeoutSeconds > 0 时覆盖配置中的抓取超时
+func (h *RAGHandler) IndexBookmarkContent(url, sourceDocID, blockID string, timeoutSeconds int) error {
+	err := h.ragService.IndexBookmarkContent(url, sourceDocID, blockID, time.Duration(timeoutSeconds)*time.Second)
+	// 部分 chunk 失败时其余内容已写入索引，同样需要刷新状态
+	if rag.IndexSucceeded(err) && h.Context() != nil {
 		runtime.EventsEmit(h.Context(), "rag:status-updated", nil)
 	}
 	return err
@@ -144,12 +273,22 @@ func (h *RAGHandler) IndexBookmarkContent(url, sourceDocID, blockID string) erro
 // IndexFileContent 索引文件内容
 func (h *RAGHandler) IndexFileContent(filePath, sourceDocID, blockID, fileName string) error {
 	err := h.ragService.IndexFileContent(filePath, sourceDocID, blockID, fileName)
-	if err == nil && h.Context() != nil {
+	if rag.IndexSucceeded(err) && h.Context() != nil {
 		runtime.EventsEmit(h.Context(), "rag:status-updated", nil)
 	}
 	return err
 }
 
+// RecordSearchFeedback 记录用户对搜索结果的反馈（有用/无关）
+func (h *RAGHandler) RecordSearchFeedback(query, blockID string, helpful bool) error {
+	return h.ragService.RecordSearchFeedback(query, blockID, helpful)
+}
+
+// ClearSearchFeedback 清空所有搜索反馈
+func (h *RAGHandler) ClearSearchFeedback() error {
+	return h.ragService.ClearSearchFeedback()
+}
+
 // ExternalBlockContent 外部块完整内容（前端用）
 type ExternalBlockContent = rag.ExternalBlockContent
 
@@ -162,8 +301,14 @@ func (h *RAGHandler) GetExternalBlockContent(docID, blockID string) (*ExternalBl
 type GraphData = rag.GraphData
 
 // GetDocumentGraph 获取文档关系图谱
-func (h *RAGHandler) GetDocumentGraph(threshold float32) (*GraphData, error) {
-	return h.ragService.GetDocumentGraph(threshold)
+// includeUnembedded 为 true 时包含没有向量的文档，它们只通过共同标签连接
+func (h *RAGHandler) GetDocumentGraph(threshold float32, includeUnembedded bool) (*GraphData, error) {
+	return h.ragService.GetDocumentGraphWithOptions(threshold, rag.GraphOptions{IncludeUnembedded: includeUnembedded})
+}
+
+// GetDocumentGraphANN 使用近邻索引获取文档关系图谱（每个节点只与 k 个最近邻比较）
+func (h *RAGHandler) GetDocumentGraphANN(threshold float32, k int) (*GraphData, error) {
+	return h.ragService.GetDocumentGraphANN(threshold, k)
 }
 
 // VectorGraphData 带向量的图谱数据（前端用）
@@ -174,12 +319,97 @@ func (h *RAGHandler) GetDocumentVectors() (*VectorGraphData, error) {
 	return h.ragService.GetDocumentVectors()
 }
 
+// TopicCluster 主题聚类（前端用）
+type TopicCluster = rag.TopicCluster
+
+// GetTopicClusters 获取文档主题聚类（k <= 0 时自动选择聚类数）
+func (h *RAGHandler) GetTopicClusters(k int) ([]TopicCluster, error) {
+	return h.ragService.GetTopicClusters(k)
+}
+
+// VectorExportResult 向量导出结果（前端用）
+type VectorExportResult = rag.VectorExportResult
+
+// ExportVectorData 导出所有 chunk 向量及元数据（path 为空时弹出保存对话框）
+func (h *RAGHandler) ExportVectorData(path string) (*VectorExportResult, error) {
+	if path == "" {
+		selected, err := runtime.SaveFileDialog(h.Context(), runtime.SaveDialogOptions{
+			Title:           "Export Vector Data",
+			DefaultFilename: "nook-vectors.bin",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "Vector Data (*.bin)", Pattern: "*.bin"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if selected == "" {
+			return nil, nil // User cancelled
+		}
+		path = selected
+	}
+	return h.ragService.ExportVectorData(path)
+}
+
+// CompactResult 压缩向量库结果（前端用）
+type CompactResult = rag.CompactResult
+
+// CompactIndex 清理孤儿向量并收缩向量库文件，返回回收的字节数
+func (h *RAGHandler) CompactIndex() (*CompactResult, error) {
+	return h.ragService.CompactIndex()
+}
+
+// ReindexPlan 重建索引预估（前端用）
+type ReindexPlan = rag.ReindexPlan
+
+// PlanReindex 预估重建索引需要的嵌入次数（在付费 API 上重建前查看开销）
+func (h *RAGHandler) PlanReindex() (*ReindexPlan, error) {
+	return h.ragService.PlanReindex()
+}
+
+// VerifyReport 向量库一致性检查结果（前端用）
+type VerifyReport = rag.VerifyReport
+
+// VerifyIndex 检查向量库元数据与向量是否一致（搜索结果异常时自查）
+func (h *RAGHandler) VerifyIndex() (*VerifyReport, error) {
+	return h.ragService.VerifyIndex()
+}
+
+// RepairIndex 删除悬空的元数据和向量
+func (h *RAGHandler) RepairIndex() (*VerifyReport, error) {
+	report, err := h.ragService.RepairIndex()
+	if err == nil && report.Repaired > 0 && h.Context() != nil {
+		runtime.EventsEmit(h.Context(), "rag:status-updated", nil)
+	}
+	return report, err
+}
+
+// VectorBackup 向量库备份信息（前端用）
+type VectorBackup = rag.VectorBackup
+
+// ListVectorBackups 列出向量库备份（切换嵌入维度前自动创建）
+func (h *RAGHandler) ListVectorBackups() ([]VectorBackup, error) {
+	return h.ragService.ListVectorBackups()
+}
+
+// RestoreVectorBackup 从备份恢复向量库
+func (h *RAGHandler) RestoreVectorBackup(name string) error {
+	return h.ragService.RestoreVectorBackup(name)
+}
+
 // FolderIndexResult 文件夹索引结果（前端用）
 type FolderIndexResult = rag.FolderIndexResult
 
-// IndexFolderContent 索引文件夹内容
+// FolderIndexProgress 文件夹索引进度（前端用）
+type FolderIndexProgress = rag.FolderIndexProgress
+
+// IndexFolderContent 索引文件夹内容，过程中发送 rag:folder-index-progress 事件
 func (h *RAGHandler) IndexFolderContent(folderPath, sourceDocID, blockID string) (*FolderIndexResult, error) {
-	result, err := h.ragService.IndexFolderContent(folderPath, sourceDocID, blockID)
+	result, err := h.ragService.IndexFolderContentWithProgress(folderPath, sourceDocID, blockID, func(progress FolderIndexProgress) {
+		if h.Context() != nil {
+			runtime.EventsEmit(h.Context(), "rag:folder-index-progress", progress)
+		}
+	})
 	if err == nil && h.Context() != nil {
 		runtime.EventsEmit(h.Context(), "rag:status-updated", nil)
 	}
@@ -198,3 +428,11 @@ type TestConnectionResult = rag.TestConnectionResult
 func (h *RAGHandler) TestConnection(config EmbeddingConfig) TestConnectionResult {
 	return rag.TestConnection(&config)
 }
+
+// ConnectionStatus 嵌入服务健康状态（前端用）
+type ConnectionStatus = rag.ConnectionStatus
+
+// TestRAGConnection 检查已保存配置的嵌入服务健康状态
+func (h *RAGHandler) TestRAGConnection() ConnectionStatus {
+	return h.ragService.TestConnection()
+}
@@ -1,19 +1,27 @@
 package handlers
 
 import (
+	"path/filepath"
+	"strings"
+
 	"notion-lite/internal/document"
 	"notion-lite/internal/errors"
 	"notion-lite/internal/rag"
 	"notion-lite/internal/search"
+	"notion-lite/internal/settings"
 	"notion-lite/internal/utils"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // SearchHandler 搜索处理器
 type SearchHandler struct {
 	*BaseHandler
-	docRepo       *document.Repository
-	searchService *search.Service
-	ragService    *rag.Service
+	docRepo         *document.Repository
+	searchService   *search.Service
+	ragService      *rag.Service
+	settingsService *settings.Service
+	history         *search.History
 }
 
 // NewSearchHandler 创建搜索处理器
@@ -22,12 +30,15 @@ func NewSearchHandler(
 	docRepo *document.Repository,
 	searchService *search.Service,
 	ragService *rag.Service,
+	settingsService *settings.Service,
 ) *SearchHandler {
 	return &SearchHandler{
-		BaseHandler:   base,
-		docRepo:       docRepo,
-		searchService: searchService,
-		ragService:    ragService,
+		BaseHandler:     base,
+		docRepo:         docRepo,
+		searchService:   searchService,
+		ragService:      ragService,
+		settingsService: settingsService,
+		history:         search.NewHistory(base.Paths()),
 	}
 }
 
@@ -54,16 +65,45 @@ type ChunkMatch struct {
 type DocumentSearchResult struct {
 	DocID         string       `json:"docId"`
 	DocTitle      string       `json:"docTitle"`
+	Tags          []string     `json:"tags,omitempty"`
 	MaxScore      float32      `json:"maxScore"`
 	MatchedChunks []ChunkMatch `json:"matchedChunks"`
 }
 
+// TagFacet 同一标签下的搜索结果（Tag 为空表示无标签的文档）
+type TagFacet struct {
+	Tag     string                 `json:"tag"`
+	Count   int                    `json:"count"`
+	Results []DocumentSearchResult `json:"results"`
+}
+
+// FacetedSearchResult 按标签分组的搜索结果
+type FacetedSearchResult struct {
+	Total  int        `json:"total"`
+	Facets []TagFacet `json:"facets"`
+}
+
+// DocumentSearchPage 分页的文档级搜索结果
+type DocumentSearchPage struct {
+	Results []DocumentSearchResult `json:"results"`
+	Offset  int                    `json:"offset"`
+	Limit   int                    `json:"limit"`
+	HasMore bool                   `json:"hasMore"` // 是否还有下一页
+}
+
+// SearchFilter 语义搜索过滤条件
+type SearchFilter = rag.SearchFilter
+
+// SearchHistoryEntry 最近搜索记录
+type SearchHistoryEntry = search.HistoryEntry
+
 // SearchDocuments 搜索文档
 func (h *SearchHandler) SearchDocuments(query string) ([]SearchResult, error) {
 	results, err := h.searchService.Search(query)
 	if err != nil {
 		return nil, err
 	}
+	h.recordSearch(query, search.HistoryModeKeyword, len(results))
 	// 使用泛型转换为前端兼容的类型
 	return utils.ConvertSlice(results, func(r search.Result) SearchResult {
 		return SearchResult{
@@ -76,6 +116,19 @@ func (h *SearchHandler) SearchDocuments(query string) ([]SearchResult, error) {
 
 // SemanticSearchDocuments 文档级语义搜索（聚合 chunks）
 func (h *SearchHandler) SemanticSearchDocuments(query string, limit int, excludeDocID string) ([]DocumentSearchResult, error) {
+	if h.ragService == nil {
+		return nil, errors.New("RAG service not initialized")
+	}
+	// 构建过滤器
+	var filter SearchFilter
+	if excludeDocID != "" {
+		filter.ExcludeDocID = excludeDocID
+	}
+	return h.SemanticSearchWithFilter(query, limit, filter)
+}
+
+// SemanticSearchWithFilter 带过滤条件的文档级语义搜索（按标签、文档、块类型限定范围）
+func (h *SearchHandler) SemanticSearchWithFilter(query string, limit int, filter SearchFilter) ([]DocumentSearchResult, error) {
 	if h.ragService == nil {
 		return nil, errors.New("RAG service not initialized")
 	}
@@ -83,21 +136,120 @@ func (h *SearchHandler) SemanticSearchDocuments(query string, limit int, exclude
 	if limit <= 0 {
 		limit = 10
 	}
-	// 构建过滤器
-	var filter *rag.SearchFilter
-	if excludeDocID != "" {
-		filter = &rag.SearchFilter{ExcludeDocID: excludeDocID}
+	results, err := h.ragService.SearchDocuments(query, limit, &filter)
+	if err != nil {
+		return nil, err
+	}
+	h.recordSearch(query, search.HistoryModeSemantic, len(results))
+	return toDocumentSearchResults(results), nil
+}
+
+// SearchFaceted 文档级语义搜索，结果按标签分组并附带每个标签的文档数
+func (h *SearchHandler) SearchFaceted(query string, limit int) (*FacetedSearchResult, error) {
+	if h.ragService == nil {
+		return nil, errors.New("RAG service not initialized")
+	}
+	// 分面需要足够的样本，默认 30 条
+	if limit <= 0 {
+		limit = 30
 	}
-	results, err := h.ragService.SearchDocuments(query, limit, filter)
+	result, err := h.ragService.SearchFaceted(query, limit)
 	if err != nil {
 		return nil, err
 	}
+	h.recordSearch(query, search.HistoryModeSemantic, result.Total)
+	return &FacetedSearchResult{
+		Total: result.Total,
+		Facets: utils.ConvertSlice(result.Facets, func(f rag.TagFacet) TagFacet {
+			return TagFacet{Tag: f.Tag, Count: f.Count, Results: toDocumentSearchResults(f.Results)}
+		}),
+	}, nil
+}
 
-	// 使用泛型转换为前端兼容的类型
+// SemanticSearchDocumentsPaged 分页的文档级语义搜索（跳过前 offset 个文档）
+func (h *SearchHandler) SemanticSearchDocumentsPaged(query string, limit, offset int, filter SearchFilter) (*DocumentSearchPage, error) {
+	if h.ragService == nil {
+		return nil, errors.New("RAG service not initialized")
+	}
+	// 默认限制 10 条
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	results, hasMore, err := h.ragService.SearchDocumentsPaged(query, limit, offset, &filter)
+	if err != nil {
+		return nil, err
+	}
+	// 只记录首页，翻页不算新的搜索
+	if offset == 0 {
+		h.recordSearch(query, search.HistoryModeSemantic, len(results))
+	}
+	return &DocumentSearchPage{
+		Results: toDocumentSearchResults(results),
+		Offset:  offset,
+		Limit:   limit,
+		HasMore: hasMore,
+	}, nil
+}
+
+// SearchExportResult 搜索结果导出结果（前端用）
+type SearchExportResult = rag.SearchExportResult
+
+// ExportSearchResults 将语义搜索结果导出为 JSON 或 CSV（弹出保存对话框）
+// format 为空时按所选文件的扩展名决定，默认 JSON；用户取消时返回 nil
+func (h *SearchHandler) ExportSearchResults(query string, limit int, format string) (*SearchExportResult, error) {
+	if h.ragService == nil {
+		return nil, errors.New("RAG service not initialized")
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+	// 默认导出 50 条
+	if limit <= 0 {
+		limit = 50
+	}
+
+	filters := []runtime.FileFilter{
+		{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		{DisplayName: "CSV (*.csv)", Pattern: "*.csv"},
+	}
+	if format == rag.SearchExportCSV {
+		filters[0], filters[1] = filters[1], filters[0]
+	}
+	defaultExt := format
+	if defaultExt == "" {
+		defaultExt = rag.SearchExportJSON
+	}
+	path, err := runtime.SaveFileDialog(h.Context(), runtime.SaveDialogOptions{
+		Title:           "Export Search Results",
+		DefaultFilename: "nook-search-results." + defaultExt,
+		Filters:         filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, nil // User cancelled
+	}
+
+	if format == "" {
+		format = rag.SearchExportJSON
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = rag.SearchExportCSV
+		}
+	}
+	return h.ragService.ExportSearchResults(query, limit, format, path)
+}
+
+// toDocumentSearchResults 使用泛型转换为前端兼容的类型
+func toDocumentSearchResults(results []rag.DocumentSearchResult) []DocumentSearchResult {
 	return utils.ConvertSlice(results, func(r rag.DocumentSearchResult) DocumentSearchResult {
 		return DocumentSearchResult{
 			DocID:    r.DocID,
 			DocTitle: r.DocTitle,
+			Tags:     r.Tags,
 			MaxScore: r.MaxScore,
 			MatchedChunks: utils.ConvertSlice(r.MatchedChunks, func(c rag.ChunkMatch) ChunkMatch {
 				return ChunkMatch{
@@ -112,10 +264,28 @@ func (h *SearchHandler) SemanticSearchDocuments(query string, limit int, exclude
 				}
 			}),
 		}
-	}), nil
+	})
 }
 
 // BuildSearchIndex 异步构建搜索索引（由 app.startup 调用）
 func (h *SearchHandler) BuildSearchIndex() {
 	go h.searchService.BuildIndex()
 }
+
+// GetRecentSearches 获取最近的搜索记录（最新的在前）
+func (h *SearchHandler) GetRecentSearches(limit int) ([]SearchHistoryEntry, error) {
+	return h.history.Recent(limit)
+}
+
+// ClearSearchHistory 清空搜索记录
+func (h *SearchHandler) ClearSearchHistory() error {
+	return h.history.Clear()
+}
+
+// recordSearch 记录搜索历史（用户关闭搜索记录时跳过，记录失败不影响搜索）
+func (h *SearchHandler) recordSearch(query, mode string, resultCount int) {
+	if s, err := h.settingsService.Get(); err == nil && s.DisableSearchHistory {
+		return
+	}
+	_ = h.history.Record(query, mode, resultCount)
+}
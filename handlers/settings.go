@@ -28,6 +28,12 @@ type Settings struct {
 	SidebarWidth int    `json:"sidebarWidth"`
 	FontSize     int    `json:"fontSize"`
 	WritingStyle string `json:"writingStyle"`
+	// 新文档默认内容模板
+	DefaultDocTemplate string `json:"defaultDocTemplate"`
+	// 关闭搜索记录
+	DisableSearchHistory bool `json:"disableSearchHistory"`
+	// 每个文档保留的历史版本数
+	VersionRetention int `json:"versionRetention"`
 }
 
 // GetSettings 获取用户设置
@@ -36,10 +42,10 @@ func (h *SettingsHandler) GetSettings() (Settings, error) {
 	if err != nil {
 		return Settings{Theme: "light", Language: "zh", SidebarWidth: 0, FontSize: 0, WritingStyle: ""}, nil
 	}
-	return Settings{Theme: s.Theme, Language: s.Language, SidebarWidth: s.SidebarWidth, FontSize: s.FontSize, WritingStyle: s.WritingStyle}, nil
+	return Settings{Theme: s.Theme, Language: s.Language, SidebarWidth: s.SidebarWidth, FontSize: s.FontSize, WritingStyle: s.WritingStyle, DefaultDocTemplate: s.DefaultDocTemplate, DisableSearchHistory: s.DisableSearchHistory, VersionRetention: s.VersionRetention}, nil
 }
 
 // SaveSettings 保存用户设置
 func (h *SettingsHandler) SaveSettings(s Settings) error {
-	return h.settingsService.Save(settings.Settings{Theme: s.Theme, Language: s.Language, SidebarWidth: s.SidebarWidth, FontSize: s.FontSize, WritingStyle: s.WritingStyle})
+	return h.settingsService.Save(settings.Settings{Theme: s.Theme, Language: s.Language, SidebarWidth: s.SidebarWidth, FontSize: s.FontSize, WritingStyle: s.WritingStyle, DefaultDocTemplate: s.DefaultDocTemplate, DisableSearchHistory: s.DisableSearchHistory, VersionRetention: s.VersionRetention})
 }
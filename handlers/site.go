@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"notion-lite/internal/constant"
+	"notion-lite/internal/markdown"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ExportDocumentsToSite 将选中的文档导出为只读静态网站（HTML 页面 + 目录页 + 本地化资源）
+// outputDir 为空时弹出目录选择对话框；不允许导出到数据目录内
+func (h *FileHandler) ExportDocumentsToSite(docIDs []string, outputDir string) (*markdown.SiteExportResult, error) {
+	if len(docIDs) == 0 {
+		return nil, fmt.Errorf("no documents selected")
+	}
+	if outputDir == "" {
+		var err error
+		outputDir, err = runtime.OpenDirectoryDialog(h.Context(), runtime.OpenDialogOptions{
+			Title:                constant.DialogTitleExportSite,
+			CanCreateDirectories: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if outputDir == "" {
+			return nil, nil // 用户取消
+		}
+	}
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output directory: %w", err)
+	}
+	dataPath, _ := filepath.Abs(h.Paths().DataPath())
+	if rel, err := filepath.Rel(dataPath, outputDir); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("cannot export into the data directory: %s", outputDir)
+	}
+
+	index, err := h.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document index: %w", err)
+	}
+	selected := make(map[string]bool, len(docIDs))
+	for _, id := range docIDs {
+		selected[id] = true
+	}
+	docs := make([]markdown.SiteDocument, 0, len(docIDs))
+	for _, meta := range index.Documents {
+		if !selected[meta.ID] {
+			continue
+		}
+		content, err := h.docStorage.Load(meta.ID)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to load document %s for export: %v\n", meta.ID, err)
+			continue
+		}
+		docs = append(docs, markdown.SiteDocument{ID: meta.ID, Title: meta.Title, Tags: meta.Tags, Content: []byte(content)})
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("none of the selected documents exist")
+	}
+
+	result, err := markdown.WriteSite(outputDir, docs, h.vaultAssetPath)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("💾 Exported %d documents and %d assets as a site to %s\n", result.Documents, result.Assets, outputDir)
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// storageUsageCacheTTL 存储占用统计的缓存时长
+const storageUsageCacheTTL = 10 * time.Second
+
+// DirUsage 单个目录的占用情况
+type DirUsage struct {
+	Bytes int64 `json:"bytes"`
+	Count int   `json:"count"`
+}
+
+// StorageUsage 存储占用明细
+type StorageUsage struct {
+	Documents DirUsage `json:"documents"`
+	Images    DirUsage `json:"images"`
+	Files     DirUsage `json:"files"`
+	VectorDB  DirUsage `json:"vectorDb"`
+	Temp      DirUsage `json:"temp"`
+	Backups   DirUsage `json:"backups"`
+	Trash     DirUsage `json:"trash"`
+	Versions  DirUsage `json:"versions"`
+	Total     int64    `json:"total"`
+}
+
+// StorageHandler 存储占用统计处理器
+type StorageHandler struct {
+	*BaseHandler
+	mu         sync.Mutex
+	cached     *StorageUsage
+	cachedTime time.Time
+}
+
+// NewStorageHandler 创建存储处理器
+func NewStorageHandler(base *BaseHandler) *StorageHandler {
+	return &StorageHandler{BaseHandler: base}
+}
+
+// GetStorageUsage 获取各数据目录的占用明细（短时缓存）
+func (h *StorageHandler) GetStorageUsage() StorageUsage {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cachedTime) < storageUsageCacheTTL {
+		return *h.cached
+	}
+
+	paths := h.Paths()
+	usage := StorageUsage{
+		Documents: walkDirUsage(paths.DocumentsDir()),
+		Images:    walkDirUsage(paths.ImagesDir()),
+		Files:     walkDirUsage(paths.FilesDir()),
+		VectorDB:  globUsage(paths.RAGDatabase() + "*"), // 包含 -wal/-shm
+		Temp:      walkDirUsage(paths.TempDir()),
+		Backups:   walkDirUsage(paths.BackupsDir()),
+		Trash:     walkDirUsage(paths.TrashDir()),
+		Versions:  walkDirUsage(paths.VersionsDir()),
+	}
+	usage.Total = usage.Documents.Bytes + usage.Images.Bytes + usage.Files.Bytes +
+		usage.VectorDB.Bytes + usage.Temp.Bytes + usage.Backups.Bytes + usage.Trash.Bytes +
+		usage.Versions.Bytes
+
+	h.cached = &usage
+	h.cachedTime = time.Now()
+	return usage
+}
+
+// InvalidateStorageUsage 清除缓存（清理操作后调用）
+func (h *StorageHandler) InvalidateStorageUsage() {
+	h.mu.Lock()
+	h.cached = nil
+	h.mu.Unlock()
+}
+
+// walkDirUsage 遍历目录统计文件大小和数量
+func walkDirUsage(dir string) DirUsage {
+	var usage DirUsage
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		usage.Bytes += info.Size()
+		usage.Count++
+		return nil
+	})
+	return usage
+}
+
+// globUsage 统计匹配模式的文件大小和数量
+func globUsage(pattern string) DirUsage {
+	var usage DirUsage
+	matches, _ := filepath.Glob(pattern)
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		usage.Bytes += info.Size()
+		usage.Count++
+	}
+	return usage
+}
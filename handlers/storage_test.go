@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestGetStorageUsage_TrashAndVersions(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(paths.Document("doc"), "[]")
+	write(paths.TrashDocument("old"), "[{}]")
+	write(paths.DocumentVersion("doc", 1), "[{},{}]")
+
+	usage := NewStorageHandler(NewBaseHandler(paths, nil)).GetStorageUsage()
+	if usage.Trash.Count != 1 || usage.Trash.Bytes != 4 {
+		t.Errorf("Unexpected trash usage: %+v", usage.Trash)
+	}
+	if usage.Versions.Count != 1 || usage.Versions.Bytes != 7 {
+		t.Errorf("Unexpected versions usage: %+v", usage.Versions)
+	}
+	if usage.Total != 2+4+7 {
+		t.Errorf("Expected total to include trash and versions, got %d", usage.Total)
+	}
+}
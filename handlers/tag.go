@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"notion-lite/internal/document"
 	"notion-lite/internal/tag"
 )
 
@@ -105,3 +106,14 @@ func (h *TagHandler) MigrateFoldersToTagGroups() {
 func (h *TagHandler) SuggestTags(docId string) ([]TagSuggestion, error) {
 	return h.tagService.SuggestTags(docId, 5)
 }
+
+// GetUntaggedDocuments 获取没有任何标签的文档
+func (h *TagHandler) GetUntaggedDocuments() ([]document.Meta, error) {
+	return h.tagService.GetUntaggedDocuments()
+}
+
+// AutoTagDocument 根据相似文档自动为文档打标签
+func (h *TagHandler) AutoTagDocument(docId string) ([]string, error) {
+	h.MarkIndexWrite()
+	return h.tagService.AutoTagDocument(docId, 3)
+}
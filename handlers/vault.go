@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"notion-lite/internal/constant"
+	"notion-lite/internal/markdown"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ExportVault 将全部文档导出为 Markdown + 资源的 zip
+// destPath 为空时弹出保存对话框；groups 为标签组名（按侧边栏顺序），对应导出包中的子目录
+func (h *FileHandler) ExportVault(destPath string, groups []string) (*markdown.VaultExportResult, error) {
+	if destPath == "" {
+		var err error
+		destPath, err = runtime.SaveFileDialog(h.Context(), runtime.SaveDialogOptions{
+			Title:           constant.DialogTitleExportVault,
+			DefaultFilename: fmt.Sprintf("Nook-%s.zip", time.Now().Format("2006-01-02")),
+			Filters: []runtime.FileFilter{
+				{DisplayName: constant.FilterZip, Pattern: "*.zip"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if destPath == "" {
+			return nil, nil // 用户取消
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(destPath), ".zip") {
+		destPath += ".zip"
+	}
+
+	index, err := h.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load document index: %w", err)
+	}
+	docs := make([]markdown.VaultDocument, 0, len(index.Documents))
+	for _, meta := range index.Documents {
+		content, err := h.docStorage.Load(meta.ID)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to load document %s for export: %v\n", meta.ID, err)
+			continue
+		}
+		docs = append(docs, markdown.VaultDocument{Title: meta.Title, Tags: meta.Tags, Content: []byte(content)})
+	}
+
+	// 先写临时文件，完成后再替换目标文件，避免中途失败留下损坏的 zip
+	tmpPath := destPath + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	result, err := markdown.WriteVault(f, docs, groups, h.vaultAssetPath)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to save export file: %w", err)
+	}
+
+	result.Path = destPath
+	fmt.Printf("💾 Exported %d documents and %d assets to %s\n", result.Documents, result.Assets, destPath)
+	return result, nil
+}
+
+// vaultAssetPath 将 /images/xxx、/files/xxx 解析为数据目录中的文件路径
+func (h *FileHandler) vaultAssetPath(url string) string {
+	var dir, name string
+	switch {
+	case strings.HasPrefix(url, "/images/"):
+		dir, name = h.Paths().ImagesDir(), strings.TrimPrefix(url, "/images/")
+	case strings.HasPrefix(url, "/files/"):
+		dir, name = h.Paths().FilesDir(), strings.TrimPrefix(url, "/files/")
+	default:
+		return ""
+	}
+	// 只允许数据目录下的文件
+	local := filepath.Join(dir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dir, local); err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return local
+}
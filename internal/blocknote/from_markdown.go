@@ -0,0 +1,400 @@
+package blocknote
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	headingLine  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listLine     = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	checkPrefix  = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	fenceLine    = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#.-]*)")
+	imageLine    = regexp.MustCompile(`^!\[([^\]]*)\]\(<?([^)>\s]+)>?\)$`)
+	dividerLine  = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	quoteLine    = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	defaultProps = map[string]interface{}{"textColor": "default", "backgroundColor": "default", "textAlignment": "left"}
+)
+
+// newBlock 生成的 BlockNote 块（字段顺序与编辑器保存的一致）
+type newBlock struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Props    map[string]interface{} `json:"props"`
+	Content  []interface{}          `json:"content"`
+	Children []*newBlock            `json:"children"`
+}
+
+// textItem 文本 inline content
+type textItem struct {
+	Type   string          `json:"type"`
+	Text   string          `json:"text"`
+	Styles map[string]bool `json:"styles"`
+}
+
+// linkItem 链接 inline content
+type linkItem struct {
+	Type    string        `json:"type"`
+	Href    string        `json:"href"`
+	Content []interface{} `json:"content"`
+}
+
+// listFrame 列表嵌套栈的一层
+type listFrame struct {
+	indent int
+	block  *newBlock
+}
+
+// FromMarkdown 将 Markdown 转换为 BlockNote JSON
+// 支持标题、无序/有序/任务列表（按缩进嵌套）、代码块、引用、分隔线、独立图片，
+// 以及粗体/斜体/删除线/行内代码和链接；每个块生成新的 UUID
+func FromMarkdown(md string) ([]byte, error) {
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+
+	blocks := []*newBlock{}
+	var stack []listFrame // 当前列表嵌套
+	var paragraph []string
+	var quote []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, textBlock("paragraph", nil, strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+	}
+	flushQuote := func() {
+		if len(quote) > 0 {
+			blocks = append(blocks, textBlock("quote", nil, strings.Join(quote, "\n")))
+			quote = nil
+		}
+	}
+	flush := func() {
+		flushParagraph()
+		flushQuote()
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		// 代码块：原样收集到结束围栏
+		if m := fenceLine.FindStringSubmatch(line); m != nil {
+			flush()
+			fence := m[1]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			language := m[2]
+			if language == "" {
+				language = "text"
+			}
+			block := &newBlock{
+				ID:       uuid.New().String(),
+				Type:     "codeBlock",
+				Props:    map[string]interface{}{"language": language},
+				Content:  []interface{}{},
+				Children: []*newBlock{},
+			}
+			if text := strings.Join(code, "\n"); text != "" {
+				block.Content = []interface{}{textItem{Type: "text", Text: text, Styles: map[string]bool{}}}
+			}
+			appendBlock(&blocks, &stack, block, 0)
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+
+		// 列表项（按缩进嵌套）
+		if m := listLine.FindStringSubmatch(line); m != nil && !dividerLine.MatchString(line) {
+			flush()
+			indent := len(strings.ReplaceAll(m[1], "\t", "    "))
+			text := m[3]
+			blockType := "bulletListItem"
+			var props map[string]interface{}
+			if unicode.IsDigit(rune(m[2][0])) {
+				blockType = "numberedListItem"
+			} else if c := checkPrefix.FindStringSubmatch(text); c != nil {
+				blockType = "checkListItem"
+				props = map[string]interface{}{"checked": c[1] != " "}
+				text = text[len(c[0]):]
+			}
+			appendBlock(&blocks, &stack, textBlock(blockType, props, text), indent+1)
+			continue
+		}
+
+		// 列表项的缩进续行
+		if len(stack) > 0 && len(paragraph) == 0 && len(quote) == 0 && line != strings.TrimLeft(line, " \t") {
+			appendText(stack[len(stack)-1].block, "\n"+trimmed)
+			continue
+		}
+		stack = nil
+
+		switch {
+		case headingLine.MatchString(line):
+			flush()
+			m := headingLine.FindStringSubmatch(line)
+			level := min(len(m[1]), 3) // BlockNote 仅支持 1-3 级标题
+			blocks = append(blocks, textBlock("heading", map[string]interface{}{"level": level}, m[2]))
+		case dividerLine.MatchString(line):
+			flush()
+			blocks = append(blocks, &newBlock{ID: uuid.New().String(), Type: "divider", Props: map[string]interface{}{}, Content: []interface{}{}, Children: []*newBlock{}})
+		case quoteLine.MatchString(line):
+			flushParagraph()
+			quote = append(quote, quoteLine.FindStringSubmatch(line)[1])
+		case imageLine.MatchString(trimmed):
+			flush()
+			m := imageLine.FindStringSubmatch(trimmed)
+			blocks = append(blocks, &newBlock{
+				ID:       uuid.New().String(),
+				Type:     "image",
+				Props:    map[string]interface{}{"url": m[2], "caption": m[1], "textAlignment": "left"},
+				Content:  []interface{}{},
+				Children: []*newBlock{},
+			})
+		default:
+			flushQuote()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	return json.Marshal(blocks)
+}
+
+// appendBlock 追加块；indent > 0 的列表项按缩进挂到上一级列表项的 children 下
+func appendBlock(blocks *[]*newBlock, stack *[]listFrame, block *newBlock, indent int) {
+	if indent == 0 {
+		*stack = nil
+		*blocks = append(*blocks, block)
+		return
+	}
+	for len(*stack) > 0 && (*stack)[len(*stack)-1].indent >= indent {
+		*stack = (*stack)[:len(*stack)-1]
+	}
+	if len(*stack) == 0 {
+		*blocks = append(*blocks, block)
+	} else {
+		parent := (*stack)[len(*stack)-1].block
+		parent.Children = append(parent.Children, block)
+	}
+	*stack = append(*stack, listFrame{indent: indent, block: block})
+}
+
+// textBlock 创建带 inline content 的文本块
+func textBlock(blockType string, extraProps map[string]interface{}, text string) *newBlock {
+	props := make(map[string]interface{}, len(defaultProps)+len(extraProps))
+	for k, v := range defaultProps {
+		props[k] = v
+	}
+	for k, v := range extraProps {
+		props[k] = v
+	}
+	return &newBlock{
+		ID:       uuid.New().String(),
+		Type:     blockType,
+		Props:    props,
+		Content:  parseInline(text, nil),
+		Children: []*newBlock{},
+	}
+}
+
+// appendText 为列表项追加续行文本
+func appendText(block *newBlock, text string) {
+	block.Content = append(block.Content, parseInline(text, nil)...)
+}
+
+// parseInline 解析行内 Markdown（行内代码、粗体、斜体、删除线、链接、反斜杠转义）
+func parseInline(text string, styles map[string]bool) []interface{} {
+	items := []interface{}{}
+	var plain strings.Builder
+
+	emit := func(s string, st map[string]bool) {
+		if s == "" {
+			return
+		}
+		// 与前一段样式相同时合并
+		if n := len(items); n > 0 {
+			if prev, ok := items[n-1].(textItem); ok && sameStyles(prev.Styles, st) {
+				prev.Text += s
+				items[n-1] = prev
+				return
+			}
+		}
+		items = append(items, textItem{Type: "text", Text: s, Styles: st})
+	}
+	flushPlain := func() {
+		emit(plain.String(), copyStyles(styles))
+		plain.Reset()
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_~[]()!#>-+|", rune(rest[1])):
+			plain.WriteByte(rest[1])
+			i += 2
+			continue
+		case rest[0] == '`':
+			if end := strings.IndexByte(rest[1:], '`'); end >= 0 {
+				flushPlain()
+				emit(rest[1:end+1], withStyle(styles, "code"))
+				i += end + 2
+				continue
+			}
+		case rest[0] == '[':
+			if label, href, n, ok := parseLink(rest); ok {
+				flushPlain()
+				items = append(items, linkItem{Type: "link", Href: href, Content: parseInline(label, styles)})
+				i += n
+				continue
+			}
+		case rest[0] == '!' && len(rest) > 1 && rest[1] == '[':
+			if label, href, n, ok := parseLink(rest[1:]); ok {
+				flushPlain()
+				if label == "" {
+					label = href
+				}
+				items = append(items, linkItem{Type: "link", Href: href, Content: parseInline(label, styles)})
+				i += n + 1
+				continue
+			}
+		default:
+			if style, delim := emphasis(rest); style != "" && canOpen(text, i, delim) {
+				if end := closingDelimiter(rest[len(delim):], delim); end > 0 {
+					flushPlain()
+					inner := parseInline(rest[len(delim):len(delim)+end], withStyle(styles, style))
+					for _, item := range inner {
+						if t, ok := item.(textItem); ok {
+							emit(t.Text, t.Styles)
+						} else {
+							items = append(items, item)
+						}
+					}
+					i += len(delim)*2 + end
+					continue
+				}
+			}
+		}
+		plain.WriteByte(text[i])
+		i++
+	}
+	flushPlain()
+	return items
+}
+
+// emphasis 识别强调标记（长标记优先）
+func emphasis(s string) (string, string) {
+	for _, d := range []struct{ delim, style string }{
+		{"**", "bold"}, {"__", "bold"}, {"~~", "strike"}, {"*", "italic"}, {"_", "italic"},
+	} {
+		if strings.HasPrefix(s, d.delim) {
+			return d.style, d.delim
+		}
+	}
+	return "", ""
+}
+
+// canOpen 强调标记后需紧跟非空白；下划线不在单词内部（避免 snake_case 被解析为斜体）
+func canOpen(text string, i int, delim string) bool {
+	next := i + len(delim)
+	if next >= len(text) || text[next] == ' ' {
+		return false
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(text[i-1]) {
+		return false
+	}
+	return true
+}
+
+// closingDelimiter 查找结束标记（前面不能是空白，下划线后面不能紧跟单词字符）
+func closingDelimiter(s, delim string) int {
+	for j := 1; j+len(delim) <= len(s); j++ {
+		if !strings.HasPrefix(s[j:], delim) || s[j-1] == ' ' {
+			continue
+		}
+		after := j + len(delim)
+		if after < len(s) && s[after] == delim[0] {
+			if len(delim) == 1 {
+				j++ // 跳过更长的标记（如斜体中的 **）
+			}
+			continue // 结束标记取连续标记的末尾（如 ***text*** 中的粗体）
+		}
+		if delim[0] == '_' && after < len(s) && isWordByte(s[after]) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// parseLink 解析 [label](href)，返回消耗的字节数
+func parseLink(s string) (label, href string, n int, ok bool) {
+	depth := 0
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if j+1 >= len(s) || s[j+1] != '(' {
+					return "", "", 0, false
+				}
+				// 尖括号包裹的地址可以包含空白和括号
+				start := 0
+				if strings.HasPrefix(s[j+2:], "<") {
+					if start = strings.IndexByte(s[j+2:], '>'); start < 0 {
+						return "", "", 0, false
+					}
+				}
+				end := strings.IndexByte(s[j+2+start:], ')')
+				if end < 0 {
+					return "", "", 0, false
+				}
+				end += start
+				href = strings.TrimSpace(s[j+2 : j+2+end])
+				href = strings.TrimSuffix(strings.TrimPrefix(href, "<"), ">")
+				return s[1:j], href, j + 3 + end, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func withStyle(styles map[string]bool, style string) map[string]bool {
+	result := copyStyles(styles)
+	result[style] = true
+	return result
+}
+
+func copyStyles(styles map[string]bool) map[string]bool {
+	result := make(map[string]bool, len(styles))
+	for k, v := range styles {
+		result[k] = v
+	}
+	return result
+}
+
+func sameStyles(a, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
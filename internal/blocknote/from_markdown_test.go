@@ -0,0 +1,85 @@
+package blocknote
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFromMarkdown_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+	}{
+		{"headings", "# Title\n\n## Section\n\n### Sub"},
+		{"paragraph styles", "plain **bold** *italic* `code` ~~strike~~ and ***both***"},
+		{"links", "see [the docs](https://go.dev/doc) and [a file](</Users/me/My Docs/a.pdf>)"},
+		{"bullet list", "- one\n  - nested\n    - deeper\n- two"},
+		{"numbered list", "1. first\n2. second\n   - child"},
+		{"checklist", "- [x] done\n- [ ] todo"},
+		{"code block", "```go\nfunc main() {\n\tprintln(\"**not bold**\")\n}\n```"},
+		{"code block without language", "```\nplain\n```"},
+		{"quote", "> quoted\n> lines"},
+		{"image", "![diagram](https://example.com/a.png)"},
+		{"divider", "above\n\n---\n\nbelow"},
+		{"mixed", "# Notes\n\nIntro with a [link](https://example.com).\n\n- item\n- item2\n\n```python\nprint(1)\n```\n\nEnd."},
+	}
+	for _, tt := range tests {
+		data, err := FromMarkdown(tt.md)
+		if err != nil {
+			t.Errorf("%s: FromMarkdown failed: %v", tt.name, err)
+			continue
+		}
+		got, err := ToMarkdown(data)
+		if err != nil {
+			t.Errorf("%s: ToMarkdown failed: %v", tt.name, err)
+			continue
+		}
+		if got != tt.md {
+			t.Errorf("%s: round trip mismatch\ngot  %q\nwant %q", tt.name, got, tt.md)
+		}
+	}
+}
+
+func TestFromMarkdown_Blocks(t *testing.T) {
+	data, err := FromMarkdown("## Plan\n\n- [x] write\n  - sub\n\nsnake_case and 2 * 3 stay plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var blocks []struct {
+		ID       string                 `json:"id"`
+		Type     string                 `json:"type"`
+		Props    map[string]interface{} `json:"props"`
+		Content  []map[string]interface{}
+		Children []struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d: %s", len(blocks), data)
+	}
+
+	ids := map[string]bool{}
+	for _, b := range blocks {
+		if b.ID == "" || ids[b.ID] {
+			t.Errorf("Expected unique non-empty ID, got %q", b.ID)
+		}
+		ids[b.ID] = true
+	}
+	if blocks[0].Type != "heading" || blocks[0].Props["level"] != float64(2) {
+		t.Errorf("Expected level 2 heading, got %s %v", blocks[0].Type, blocks[0].Props)
+	}
+	if blocks[1].Type != "checkListItem" || blocks[1].Props["checked"] != true {
+		t.Errorf("Expected checked checklist item, got %s %v", blocks[1].Type, blocks[1].Props)
+	}
+	if len(blocks[1].Children) != 1 || blocks[1].Children[0].Type != "bulletListItem" || blocks[1].Children[0].ID == "" {
+		t.Errorf("Expected nested bullet child, got %+v", blocks[1].Children)
+	}
+	if len(blocks[2].Content) != 1 || blocks[2].Content[0]["text"] != "snake_case and 2 * 3 stay plain" {
+		t.Errorf("Expected a single plain text run, got %v", blocks[2].Content)
+	}
+}
@@ -0,0 +1,265 @@
+package blocknote
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ToHTML 将 BlockNote JSON 文档渲染为 HTML 片段（不含 <html>/<body>）
+// rewriteLink 用于改写链接地址（如将 nook://doc/ 链接指向导出的页面），返回空字符串时只输出链接文本；为 nil 时保持原样
+// 链接和媒体地址只输出 http、https、mailto 和相对地址，其他协议只保留文本
+func ToHTML(data []byte, rewriteLink func(href string) string) (string, error) {
+	var blocks []Block
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return "", fmt.Errorf("failed to parse blocks: %w", err)
+	}
+	if rewriteLink == nil {
+		rewriteLink = func(href string) string { return href }
+	}
+	r := htmlRenderer{rewriteLink: rewriteLink}
+	return r.blocks(blocks), nil
+}
+
+// htmlRenderer BlockNote → HTML 渲染器
+type htmlRenderer struct {
+	rewriteLink func(href string) string
+}
+
+// blocks 渲染同级块，相邻的同类列表项合并到同一个 <ul>/<ol>
+func (r htmlRenderer) blocks(blocks []Block) string {
+	var sb strings.Builder
+	for i := 0; i < len(blocks); {
+		tag := listTag(blocks[i].Type)
+		if tag == "" {
+			sb.WriteString(r.block(blocks[i]))
+			i++
+			continue
+		}
+
+		open := "<" + tag + ">"
+		if blocks[i].Type == "numberedListItem" {
+			if start, ok := numberProp(blocks[i].Props, "start"); ok && start > 1 {
+				open = `<ol start="` + strconv.Itoa(start) + `">`
+			}
+		}
+		sb.WriteString(open + "\n")
+		j := i
+		for ; j < len(blocks) && listTag(blocks[j].Type) == tag; j++ {
+			sb.WriteString(r.listItem(blocks[j]))
+		}
+		sb.WriteString("</" + tag + ">\n")
+		i = j
+	}
+	return sb.String()
+}
+
+// block 渲染单个非列表块及其子块
+func (r htmlRenderer) block(block Block) string {
+	text := r.inline(block.inlineContent())
+	props := block.Props
+
+	var out string
+	switch block.Type {
+	case "heading":
+		level, _ := numberProp(props, "level")
+		level = max(1, min(level, 6))
+		tag := "h" + strconv.Itoa(level)
+		out = "<" + tag + idAttr(block.ID) + ">" + text + "</" + tag + ">"
+	case "quote":
+		out = "<blockquote" + idAttr(block.ID) + ">" + text + "</blockquote>"
+	case "codeBlock":
+		class := ""
+		if language := stringProp(props, "language"); language != "" && language != "text" {
+			class = ` class="language-` + html.EscapeString(language) + `"`
+		}
+		out = "<pre" + idAttr(block.ID) + "><code" + class + ">" + html.EscapeString(plainText(block.inlineContent())) + "</code></pre>"
+	case "image":
+		caption := stringProp(props, "caption")
+		out = "<figure" + idAttr(block.ID) + ">"
+		if src := safeURL(stringProp(props, "url")); src != "" {
+			out += `<img src="` + html.EscapeString(src) + `" alt="` +
+				html.EscapeString(firstNonEmpty(caption, stringProp(props, "name"))) + `">`
+		}
+		if caption != "" {
+			out += "<figcaption>" + html.EscapeString(caption) + "</figcaption>"
+		}
+		out += "</figure>"
+	case "video", "audio":
+		src := ""
+		if u := safeURL(stringProp(props, "url")); u != "" {
+			src = ` src="` + html.EscapeString(u) + `"`
+		}
+		out = "<" + block.Type + idAttr(block.ID) + " controls" + src + "></" + block.Type + ">"
+	case "bookmark":
+		href := stringProp(props, "url")
+		out = `<p class="bookmark"` + idAttr(block.ID) + ">" + r.link(html.EscapeString(firstNonEmpty(stringProp(props, "title"), href)), href) + "</p>"
+	case "file":
+		// 只链接已复制到站点内的附件（相对路径），本机绝对路径不写入页面
+		path := firstNonEmpty(stringProp(props, "originalPath"), stringProp(props, "archivedPath"))
+		href := ""
+		if isRelativePath(path) {
+			href = path
+		}
+		out = `<p class="file"` + idAttr(block.ID) + ">" + r.link(html.EscapeString(firstNonEmpty(stringProp(props, "fileName"), baseName(path))), href) + "</p>"
+	case "folder":
+		path := stringProp(props, "folderPath")
+		out = `<p class="folder"` + idAttr(block.ID) + ">" + html.EscapeString(firstNonEmpty(stringProp(props, "folderName"), path)) + "</p>"
+	case "table":
+		out = r.table(block.Content)
+	case "divider":
+		out = "<hr>"
+	default:
+		if text == "" && len(block.Children) > 0 {
+			out = ""
+		} else {
+			out = "<p" + idAttr(block.ID) + ">" + text + "</p>"
+		}
+	}
+	if len(block.Children) > 0 {
+		out += "\n<div class=\"children\">\n" + r.blocks(block.Children) + "</div>"
+	}
+	return out + "\n"
+}
+
+// listItem 渲染列表项（子块嵌套在 <li> 内）
+func (r htmlRenderer) listItem(block Block) string {
+	text := r.inline(block.inlineContent())
+	if block.Type == "checkListItem" {
+		checked := ""
+		if c, _ := block.Props["checked"].(bool); c {
+			checked = " checked"
+		}
+		text = `<input type="checkbox" disabled` + checked + "> " + text
+	}
+	out := "<li" + idAttr(block.ID) + ">" + text
+	if len(block.Children) > 0 {
+		out += "\n" + r.blocks(block.Children)
+	}
+	return out + "</li>\n"
+}
+
+// inline 渲染 inline content，保留粗体/斜体/下划线/删除线/行内代码和链接
+func (r htmlRenderer) inline(content []InlineContent) string {
+	var sb strings.Builder
+	for _, item := range content {
+		if item.Type == "link" {
+			sb.WriteString(r.link(r.inline(item.Content), item.Href))
+			continue
+		}
+		text := strings.ReplaceAll(html.EscapeString(item.Text), "\n", "<br>")
+		if styleOn(item.Styles, "code") {
+			text = "<code>" + text + "</code>"
+		}
+		if styleOn(item.Styles, "strike") {
+			text = "<s>" + text + "</s>"
+		}
+		if styleOn(item.Styles, "underline") {
+			text = "<u>" + text + "</u>"
+		}
+		if styleOn(item.Styles, "italic") {
+			text = "<em>" + text + "</em>"
+		}
+		if styleOn(item.Styles, "bold") {
+			text = "<strong>" + text + "</strong>"
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+// link 渲染链接（改写后无地址时只输出文本）
+func (r htmlRenderer) link(text, href string) string {
+	if href == "" {
+		return text
+	}
+	href = safeURL(r.rewriteLink(href))
+	if href == "" {
+		return text
+	}
+	return `<a href="` + html.EscapeString(href) + `">` + text + "</a>"
+}
+
+// allowedURLSchemes 允许写入页面的链接协议（另外允许相对地址）
+var allowedURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// safeURL 返回可以写入页面的地址：http、https、mailto 或相对地址，其他协议（javascript:、file: 等）返回空字符串
+func safeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme == "" || allowedURLSchemes[strings.ToLower(u.Scheme)] {
+		return raw
+	}
+	return ""
+}
+
+// isRelativePath 判断是否为相对路径（排除 / 或 \ 开头的绝对路径、C: 这类盘符路径和带协议的地址）
+func isRelativePath(path string) bool {
+	if path == "" || strings.HasPrefix(path, "/") || strings.HasPrefix(path, `\`) {
+		return false
+	}
+	if len(path) >= 2 && path[1] == ':' {
+		return false
+	}
+	u, err := url.Parse(path)
+	return err == nil && u.Scheme == ""
+}
+
+// baseName 路径的最后一段（兼容 / 和 \ 分隔符），避免把完整本机路径作为文件名显示
+func baseName(path string) string {
+	if i := strings.LastIndexAny(path, `/\`); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// table 渲染表格（第一行作为表头）
+func (r htmlRenderer) table(raw json.RawMessage) string {
+	var table tableContent
+	if len(raw) == 0 || json.Unmarshal(raw, &table) != nil || len(table.Rows) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("<table>\n")
+	for i, row := range table.Rows {
+		cellTag := "td"
+		if i == 0 {
+			cellTag = "th"
+		}
+		sb.WriteString("<tr>")
+		for _, cell := range row.Cells {
+			sb.WriteString("<" + cellTag + ">" + r.inline(tableCellContent(cell)) + "</" + cellTag + ">")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
+
+// listTag 列表项对应的列表标签（非列表项返回空字符串）
+func listTag(blockType string) string {
+	switch blockType {
+	case "bulletListItem", "checkListItem", "toggleListItem":
+		return "ul"
+	case "numberedListItem":
+		return "ol"
+	}
+	return ""
+}
+
+// idAttr 以块 ID 作为锚点，便于 #blockId 形式的链接定位
+func idAttr(id string) string {
+	if id == "" {
+		return ""
+	}
+	return ` id="` + html.EscapeString(id) + `"`
+}
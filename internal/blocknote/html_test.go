@@ -0,0 +1,85 @@
+package blocknote
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToHTML(t *testing.T) {
+	data := []byte(`[
+		{"id":"h1","type":"heading","props":{"level":2},"content":[{"type":"text","text":"Title"}],"children":[]},
+		{"id":"p1","type":"paragraph","content":[{"type":"text","text":"a < b","styles":{"bold":true}},{"type":"link","href":"nook://doc/x","content":[{"type":"text","text":"X"}]}],"children":[]},
+		{"id":"l1","type":"bulletListItem","content":[{"type":"text","text":"one"}],"children":[]},
+		{"id":"l2","type":"bulletListItem","content":[{"type":"text","text":"two"}],"children":[]},
+		{"id":"n1","type":"numberedListItem","content":[{"type":"text","text":"first"}],"children":[]},
+		{"id":"c1","type":"codeBlock","props":{"language":"go"},"content":[{"type":"text","text":"x := <-ch"}],"children":[]}
+	]`)
+
+	got, err := ToHTML(data, func(href string) string { return "" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<h2 id="h1">Title</h2>`,
+		`<p id="p1"><strong>a &lt; b</strong>X</p>`,
+		"<ul>\n<li id=\"l1\">one</li>\n<li id=\"l2\">two</li>\n</ul>",
+		"<ol>\n<li id=\"n1\">first</li>\n</ol>",
+		`<pre id="c1"><code class="language-go">x := &lt;-ch</code></pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToHTML output missing %q:\n%s", want, got)
+		}
+	}
+
+	got, _ = ToHTML(data, func(href string) string { return "page.html" })
+	if !strings.Contains(got, `<a href="page.html">X</a>`) {
+		t.Errorf("rewritten links should be kept:\n%s", got)
+	}
+
+	if _, err := ToHTML([]byte(`{bad`), nil); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
+
+func TestToHTML_UnsafeURLs(t *testing.T) {
+	data := []byte(`[
+		{"id":"p1","type":"paragraph","content":[
+			{"type":"link","href":"javascript:alert(1)","content":[{"type":"text","text":"js"}]},
+			{"type":"link","href":"https://example.com/a?b=1&c=2","content":[{"type":"text","text":"web"}]},
+			{"type":"link","href":"mailto:me@example.com","content":[{"type":"text","text":"mail"}]},
+			{"type":"link","href":"other.html#h1","content":[{"type":"text","text":"page"}]}
+		]},
+		{"id":"i1","type":"image","props":{"url":"file:///etc/passwd","caption":"cap"}},
+		{"id":"i2","type":"image","props":{"url":"assets/images/a.png"}},
+		{"id":"v1","type":"video","props":{"url":"data:text/html,<script>x</script>"}},
+		{"id":"b1","type":"bookmark","props":{"url":"vbscript:msgbox","title":"bm"}},
+		{"id":"f1","type":"file","props":{"originalPath":"/Users/me/secret/report.pdf","fileName":""}},
+		{"id":"f2","type":"file","props":{"originalPath":"C:\\Users\\me\\report.pdf","fileName":"report.pdf"}},
+		{"id":"f3","type":"file","props":{"originalPath":"assets/files/report.pdf","fileName":"report.pdf"}}
+	]`)
+	got, err := ToHTML(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<a href="https://example.com/a?b=1&amp;c=2">web</a>`,
+		`<a href="mailto:me@example.com">mail</a>`,
+		`<a href="other.html#h1">page</a>`,
+		`<figure id="i1"><figcaption>cap</figcaption></figure>`,
+		`<img src="assets/images/a.png"`,
+		`<video id="v1" controls></video>`,
+		`<p class="bookmark" id="b1">bm</p>`,
+		`<p class="file" id="f1">report.pdf</p>`,
+		`<p class="file" id="f2">report.pdf</p>`,
+		`<a href="assets/files/report.pdf">report.pdf</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToHTML output missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"javascript:", "file:", "data:", "vbscript:", "/Users/me", `C:\`} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("ToHTML output should not contain %q:\n%s", unwanted, got)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package blocknote
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Block BlockNote 块结构
+// content 可能是 inline content 数组，也可能是表格内容对象，因此延迟解析
+type Block struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Props    map[string]interface{} `json:"props"`
+	Content  json.RawMessage        `json:"content"`
+	Children []Block                `json:"children"`
+}
+
+// InlineContent BlockNote 的 inline content（text / link）
+type InlineContent struct {
+	Type    string                 `json:"type"`
+	Text    string                 `json:"text"`
+	Href    string                 `json:"href"`
+	Styles  map[string]interface{} `json:"styles"`
+	Content []InlineContent        `json:"content"` // link 的文本内容
+}
+
+// tableContent 表格内容
+type tableContent struct {
+	Rows []struct {
+		Cells []json.RawMessage `json:"cells"`
+	} `json:"rows"`
+}
+
+// ToMarkdown 将 BlockNote JSON 文档渲染为 Markdown
+// 书签/文件/文件夹块渲染为链接；下划线、颜色等 Markdown 无法表示的样式会被忽略
+func ToMarkdown(data []byte) (string, error) {
+	var blocks []Block
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return "", fmt.Errorf("failed to parse blocks: %w", err)
+	}
+	return strings.TrimSpace(renderBlocks(blocks)), nil
+}
+
+// renderBlocks 渲染同级块：相邻列表项之间单换行，其余块之间空一行
+func renderBlocks(blocks []Block) string {
+	var sb strings.Builder
+	number := 0
+	for i, block := range blocks {
+		if block.Type == "numberedListItem" {
+			number++
+			if number == 1 {
+				if start, ok := numberProp(block.Props, "start"); ok && start > 0 {
+					number = start
+				}
+			}
+		} else {
+			number = 0
+		}
+
+		if i > 0 {
+			if isListItem(blocks[i-1].Type) && isListItem(block.Type) {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		sb.WriteString(renderBlock(block, number))
+	}
+	return sb.String()
+}
+
+// renderBlock 渲染单个块及其子块
+func renderBlock(block Block, number int) string {
+	text := renderInline(block.inlineContent())
+
+	switch block.Type {
+	case "heading":
+		level, _ := numberProp(block.Props, "level")
+		level = max(1, min(level, 6))
+		return strings.Repeat("#", level) + " " + text + childrenBlock(block)
+	case "bulletListItem", "toggleListItem":
+		return listItem("- ", text, block)
+	case "numberedListItem":
+		return listItem(strconv.Itoa(number)+". ", text, block)
+	case "checkListItem":
+		marker := "- [ ] "
+		if checked, _ := block.Props["checked"].(bool); checked {
+			marker = "- [x] "
+		}
+		return listItem(marker, text, block)
+	case "quote":
+		return prefixLines(text, "> ", "> ") + childrenBlock(block)
+	case "codeBlock":
+		language := stringProp(block.Props, "language")
+		if language == "text" {
+			language = ""
+		}
+		return "```" + language + "\n" + plainText(block.inlineContent()) + "\n```" + childrenBlock(block)
+	case "image":
+		alt := firstNonEmpty(stringProp(block.Props, "caption"), stringProp(block.Props, "name"))
+		return "![" + alt + "](" + linkDestination(stringProp(block.Props, "url")) + ")" + childrenBlock(block)
+	case "video", "audio":
+		url := stringProp(block.Props, "url")
+		return link(firstNonEmpty(stringProp(block.Props, "name"), stringProp(block.Props, "caption"), url), url) + childrenBlock(block)
+	case "bookmark":
+		url := stringProp(block.Props, "url")
+		return link(firstNonEmpty(stringProp(block.Props, "title"), url), url) + childrenBlock(block)
+	case "file":
+		path := firstNonEmpty(stringProp(block.Props, "originalPath"), stringProp(block.Props, "archivedPath"))
+		return link(firstNonEmpty(stringProp(block.Props, "fileName"), path), path) + childrenBlock(block)
+	case "folder":
+		path := stringProp(block.Props, "folderPath")
+		return link(firstNonEmpty(stringProp(block.Props, "folderName"), path), path) + childrenBlock(block)
+	case "table":
+		return renderTable(block.Content) + childrenBlock(block)
+	case "divider":
+		return "---" + childrenBlock(block)
+	default:
+		return text + childrenBlock(block)
+	}
+}
+
+// listItem 渲染列表项，续行和子块按标记宽度缩进
+func listItem(marker, text string, block Block) string {
+	indent := strings.Repeat(" ", len(marker))
+	result := prefixLines(text, marker, indent)
+	if len(block.Children) > 0 {
+		result += "\n" + prefixLines(renderBlocks(block.Children), indent, indent)
+	}
+	return result
+}
+
+// childrenBlock 非列表块的子块（BlockNote 的缩进）作为后续段落输出，避免缩进被解析为代码块
+func childrenBlock(block Block) string {
+	if len(block.Children) == 0 {
+		return ""
+	}
+	return "\n\n" + renderBlocks(block.Children)
+}
+
+// inlineContent 解析 inline content（非数组内容返回 nil）
+func (b Block) inlineContent() []InlineContent {
+	var content []InlineContent
+	if len(b.Content) == 0 || json.Unmarshal(b.Content, &content) != nil {
+		return nil
+	}
+	return content
+}
+
+// renderInline 渲染 inline content，保留粗体/斜体/删除线/行内代码和链接
+func renderInline(content []InlineContent) string {
+	var sb strings.Builder
+	for _, item := range content {
+		switch item.Type {
+		case "link":
+			sb.WriteString(link(renderInline(item.Content), item.Href))
+		default:
+			sb.WriteString(styledText(item.Text, item.Styles))
+		}
+	}
+	return sb.String()
+}
+
+// styledText 为文本加上 Markdown 样式标记（首尾空白放在标记外，否则标记不生效）
+func styledText(text string, styles map[string]interface{}) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || len(styles) == 0 {
+		return text
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+
+	if styleOn(styles, "code") {
+		trimmed = "`" + trimmed + "`"
+	}
+	if styleOn(styles, "strike") {
+		trimmed = "~~" + trimmed + "~~"
+	}
+	if styleOn(styles, "italic") {
+		trimmed = "*" + trimmed + "*"
+	}
+	if styleOn(styles, "bold") {
+		trimmed = "**" + trimmed + "**"
+	}
+	return leading + trimmed + trailing
+}
+
+// plainText 拼接 inline content 的纯文本（代码块不加样式）
+func plainText(content []InlineContent) string {
+	var sb strings.Builder
+	for _, item := range content {
+		if item.Type == "link" {
+			sb.WriteString(plainText(item.Content))
+		} else {
+			sb.WriteString(item.Text)
+		}
+	}
+	return sb.String()
+}
+
+// renderTable 渲染 GFM 表格（第一行作为表头）
+func renderTable(raw json.RawMessage) string {
+	var table tableContent
+	if len(raw) == 0 || json.Unmarshal(raw, &table) != nil || len(table.Rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range table.Rows {
+		columns = max(columns, len(row.Cells))
+	}
+
+	var lines []string
+	for i, row := range table.Rows {
+		cells := make([]string, columns)
+		for j, cell := range row.Cells {
+			cells[j] = strings.ReplaceAll(strings.ReplaceAll(renderInline(tableCellContent(cell)), "|", `\|`), "\n", " ")
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableCellContent 解析单元格内容（兼容 inline content 数组和 tableCell 对象两种格式）
+func tableCellContent(raw json.RawMessage) []InlineContent {
+	var content []InlineContent
+	if json.Unmarshal(raw, &content) == nil {
+		return content
+	}
+	var cell struct {
+		Content []InlineContent `json:"content"`
+	}
+	if json.Unmarshal(raw, &cell) == nil {
+		return cell.Content
+	}
+	return nil
+}
+
+// link 渲染 Markdown 链接（无地址时只输出文本）
+func link(text, href string) string {
+	if href == "" {
+		return text
+	}
+	return "[" + text + "](" + linkDestination(href) + ")"
+}
+
+// linkDestination 包含空白或括号的地址（如本地路径）用尖括号包裹
+func linkDestination(href string) string {
+	if strings.ContainsAny(href, " ()") {
+		return "<" + href + ">"
+	}
+	return href
+}
+
+// prefixLines 为首行和后续行添加前缀
+func prefixLines(text, first, rest string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		if line == "" && i > 0 {
+			lines[i] = strings.TrimRight(prefix, " ")
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isListItem(blockType string) bool {
+	switch blockType {
+	case "bulletListItem", "numberedListItem", "checkListItem", "toggleListItem":
+		return true
+	}
+	return false
+}
+
+func styleOn(styles map[string]interface{}, key string) bool {
+	on, _ := styles[key].(bool)
+	return on
+}
+
+func stringProp(props map[string]interface{}, key string) string {
+	value, _ := props[key].(string)
+	return value
+}
+
+// numberProp 读取数字属性（兼容 JSON 数字和数字字符串）
+func numberProp(props map[string]interface{}, key string) (int, bool) {
+	switch v := props[key].(type) {
+	case float64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,130 @@
+package blocknote
+
+import "testing"
+
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{
+			"heading",
+			`[{"type": "heading", "props": {"level": 2}, "content": [{"type": "text", "text": "Title"}]}]`,
+			"## Title",
+		},
+		{
+			"paragraph with styles",
+			`[{"type": "paragraph", "content": [
+				{"type": "text", "text": "plain "},
+				{"type": "text", "text": "bold ", "styles": {"bold": true}},
+				{"type": "text", "text": "code", "styles": {"code": true}},
+				{"type": "text", "text": " and "},
+				{"type": "text", "text": "gone", "styles": {"strike": true, "italic": true}}
+			]}]`,
+			"plain **bold** `code` and *~~gone~~*",
+		},
+		{
+			"inline link",
+			`[{"type": "paragraph", "content": [{"type": "link", "href": "https://example.com", "content": [{"type": "text", "text": "site"}]}]}]`,
+			"[site](https://example.com)",
+		},
+		{
+			"paragraphs separated by blank line",
+			`[{"type": "paragraph", "content": [{"type": "text", "text": "a"}]}, {"type": "paragraph", "content": [{"type": "text", "text": "b"}]}]`,
+			"a\n\nb",
+		},
+		{
+			"bullet list with nested children",
+			`[
+				{"type": "bulletListItem", "content": [{"type": "text", "text": "one"}], "children": [
+					{"type": "bulletListItem", "content": [{"type": "text", "text": "nested"}]}
+				]},
+				{"type": "bulletListItem", "content": [{"type": "text", "text": "two"}]}
+			]`,
+			"- one\n  - nested\n- two",
+		},
+		{
+			"numbered list restarts after other blocks",
+			`[
+				{"type": "numberedListItem", "content": [{"type": "text", "text": "a"}]},
+				{"type": "numberedListItem", "content": [{"type": "text", "text": "b"}]},
+				{"type": "paragraph", "content": [{"type": "text", "text": "break"}]},
+				{"type": "numberedListItem", "content": [{"type": "text", "text": "c"}]}
+			]`,
+			"1. a\n2. b\n\nbreak\n\n1. c",
+		},
+		{
+			"checklist",
+			`[
+				{"type": "checkListItem", "props": {"checked": true}, "content": [{"type": "text", "text": "done"}]},
+				{"type": "checkListItem", "props": {"checked": false}, "content": [{"type": "text", "text": "todo"}]}
+			]`,
+			"- [x] done\n- [ ] todo",
+		},
+		{
+			"code block with language",
+			`[{"type": "codeBlock", "props": {"language": "go"}, "content": [{"type": "text", "text": "x := 1\nfmt.Println(x)", "styles": {"bold": true}}]}]`,
+			"```go\nx := 1\nfmt.Println(x)\n```",
+		},
+		{
+			"quote",
+			`[{"type": "quote", "content": [{"type": "text", "text": "line1\nline2"}]}]`,
+			"> line1\n> line2",
+		},
+		{
+			"image",
+			`[{"type": "image", "props": {"url": "https://example.com/a.png", "caption": "diagram"}, "content": []}]`,
+			"![diagram](https://example.com/a.png)",
+		},
+		{
+			"bookmark",
+			`[{"type": "bookmark", "props": {"url": "https://go.dev", "title": "The Go Programming Language"}}]`,
+			"[The Go Programming Language](https://go.dev)",
+		},
+		{
+			"bookmark without title",
+			`[{"type": "bookmark", "props": {"url": "https://go.dev"}}]`,
+			"[https://go.dev](https://go.dev)",
+		},
+		{
+			"file with spaces in path",
+			`[{"type": "file", "props": {"originalPath": "/Users/me/My Docs/report.pdf", "fileName": "report.pdf"}}]`,
+			"[report.pdf](</Users/me/My Docs/report.pdf>)",
+		},
+		{
+			"folder",
+			`[{"type": "folder", "props": {"folderPath": "/Users/me/notes", "folderName": "notes"}}]`,
+			"[notes](/Users/me/notes)",
+		},
+		{
+			"table",
+			`[{"type": "table", "content": {"type": "tableContent", "rows": [
+				{"cells": [[{"type": "text", "text": "Name"}], [{"type": "text", "text": "Value"}]]},
+				{"cells": [{"type": "tableCell", "content": [{"type": "text", "text": "a|b"}]}, [{"type": "text", "text": "1"}]]}
+			]}}]`,
+			"| Name | Value |\n| --- | --- |\n| a\\|b | 1 |",
+		},
+		{
+			"empty document",
+			`[]`,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		got, err := ToMarkdown([]byte(tt.json))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestToMarkdown_InvalidJSON(t *testing.T) {
+	if _, err := ToMarkdown([]byte(`{"not": "an array"}`)); err == nil {
+		t.Error("Expected error for non-array content")
+	}
+}
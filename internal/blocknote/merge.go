@@ -0,0 +1,54 @@
+package blocknote
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MergeSource 待合并的文档
+type MergeSource struct {
+	Title   string
+	Content []byte // BlockNote JSON
+}
+
+// Merge 按顺序拼接多个文档的块，每个文档前插入以其标题为内容的二级标题
+// 所有块（包括子块）重新生成 ID，避免合并后的文档与来源文档共享块 ID；
+// 块的其余字段原样保留（包括书签、文件等外部块的属性）
+func Merge(sources []MergeSource) ([]byte, error) {
+	merged := []interface{}{}
+	for _, source := range sources {
+		var blocks []map[string]interface{}
+		if len(source.Content) > 0 {
+			if err := json.Unmarshal(source.Content, &blocks); err != nil {
+				return nil, fmt.Errorf("failed to parse %q: %w", source.Title, err)
+			}
+		}
+		merged = append(merged, separatorHeading(source.Title))
+		for _, block := range blocks {
+			RegenerateIDs(block)
+			merged = append(merged, block)
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// separatorHeading 来源文档的分隔标题（标题按纯文本写入，不解析 Markdown 标记）
+func separatorHeading(title string) *newBlock {
+	block := textBlock("heading", map[string]interface{}{"level": 2}, "")
+	block.Content = []interface{}{textItem{Type: "text", Text: title, Styles: map[string]bool{}}}
+	return block
+}
+
+// RegenerateIDs 递归为块及其子块生成新 ID
+// 块 ID 同时是向量索引的主键，复制到其他文档的块必须使用新 ID，否则会覆盖原文档的索引
+func RegenerateIDs(block map[string]interface{}) {
+	block["id"] = uuid.New().String()
+	children, _ := block["children"].([]interface{})
+	for _, child := range children {
+		if childBlock, ok := child.(map[string]interface{}); ok {
+			RegenerateIDs(childBlock)
+		}
+	}
+}
@@ -2,16 +2,22 @@ package constant
 
 const (
 	// Dialog Titles
-	DialogTitleOpenFile   = "Open File"
-	DialogTitleImport     = "Import Markdown File"
-	DialogTitleExport     = "Export as Markdown"
-	DialogTitleExportHTML = "Export as HTML"
+	DialogTitleOpenFile     = "Open File"
+	DialogTitleImport       = "Import Markdown File"
+	DialogTitleImportFolder = "Import Markdown Folder"
+	DialogTitleExport       = "Export as Markdown"
+	DialogTitleExportHTML   = "Export as HTML"
+	DialogTitleExportVault  = "Export All Documents"
+	DialogTitleExportPDF    = "Export as PDF"
+	DialogTitleExportSite   = "Export as Website"
 
 	// File Filters
 	FilterTextAndMarkdown = "Text Files (*.txt, *.md)"
 	FilterMarkdown        = "Markdown Files (*.md)"
 	FilterText            = "Text Files (*.txt)"
 	FilterHTML            = "HTML Files (*.html)"
+	FilterZip             = "Zip Archives (*.zip)"
+	FilterPDF             = "PDF Files (*.pdf)"
 	FilterAll             = "All Files (*.*)"
 
 	// File Block Dialog
@@ -32,16 +38,22 @@ const (
 	LogFailedToStatDroppedPath = "Failed to stat dropped path: "
 
 	// Menu - File
-	MenuFile           = "File"
-	MenuFileNewDoc     = "New Document"
-	MenuFileNewFolder  = "New Folder"
-	MenuFileOpen       = "Open File"
-	MenuFileImport     = "Import Markdown"
-	MenuFileExport     = "Export Markdown"
-	MenuFileExportImg  = "Copy as Image"
-	MenuFileSaveImg    = "Save as Image..."
-	MenuFileExportHTML = "Export HTML"
-	MenuFilePrint      = "Print"
+	MenuFile             = "File"
+	MenuFileNewDoc       = "New Document"
+	MenuFileNewFolder    = "New Folder"
+	MenuFileOpen         = "Open File"
+	MenuFileImport       = "Import Markdown"
+	MenuFileImportFolder = "Import Markdown Folder..."
+	MenuFileExport       = "Export Markdown"
+	MenuFileExportVault  = "Export All as Markdown Zip..."
+	MenuFileExportImg    = "Copy as Image"
+	MenuFileSaveImg      = "Save as Image..."
+	MenuFileExportHTML   = "Export HTML"
+	MenuFileExportPDF    = "Export PDF..."
+	MenuFileExportSearch = "Export Search Results..."
+	MenuFilePrint        = "Print"
+	MenuFileRestoreDoc   = "Restore Last Deleted Document"
+	MenuFileEmptyTrash   = "Empty Trash..."
 
 	// Menu - View
 	MenuView              = "View"
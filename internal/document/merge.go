@@ -0,0 +1,64 @@
+package document
+
+import (
+	"fmt"
+
+	"notion-lite/internal/blocknote"
+)
+
+// Merge 将多个文档合并为一个新文档
+// 按 sourceIDs 顺序拼接内容（每个来源前插入其标题作为分隔），标签取并集；
+// title 为空时使用第一个来源的标题。重复的 ID 只取第一次出现。来源文档保持不变，由调用方决定是否删除
+func Merge(repo *Repository, storage *Storage, sourceIDs []string, title string) (Meta, error) {
+	sourceIDs = UniqueIDs(sourceIDs)
+	if len(sourceIDs) < 2 {
+		return Meta{}, fmt.Errorf("at least two documents are required to merge")
+	}
+	index, err := repo.GetAll()
+	if err != nil {
+		return Meta{}, fmt.Errorf("failed to load document index: %w", err)
+	}
+	metas := make(map[string]Meta, len(index.Documents))
+	for _, meta := range index.Documents {
+		metas[meta.ID] = meta
+	}
+
+	var sources []blocknote.MergeSource
+	var tags []string
+	for _, id := range sourceIDs {
+		meta, ok := metas[id]
+		if !ok {
+			return Meta{}, fmt.Errorf("document not found: %s", id)
+		}
+		content, err := storage.Load(id)
+		if err != nil {
+			return Meta{}, fmt.Errorf("failed to load %s: %w", meta.Title, err)
+		}
+		sources = append(sources, blocknote.MergeSource{Title: meta.Title, Content: []byte(content)})
+		tags = append(tags, meta.Tags...)
+	}
+
+	content, err := blocknote.Merge(sources)
+	if err != nil {
+		return Meta{}, err
+	}
+	if title == "" {
+		title = sources[0].Title
+	}
+	// CreateWithTags 会去除重复标签
+	return repo.CreateWithTags(title, content, tags)
+}
+
+// UniqueIDs 按首次出现的顺序去除重复 ID
+func UniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
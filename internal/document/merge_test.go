@@ -0,0 +1,93 @@
+package document
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestMerge(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository(paths)
+	storage := NewStorage(paths)
+
+	first, err := repo.CreateWithTags("Part 1", nil, []string{"work", "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.CreateWithTags("Part 2", nil, []string{"work", "ideas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Save(first.ID, `[{"id":"a","type":"paragraph","props":{},"content":[{"type":"text","text":"alpha"}],"children":[{"id":"a1","type":"paragraph","content":[],"children":[]}]}]`); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Save(second.ID, `[{"id":"b","type":"bookmark","props":{"url":"https://example.com"},"children":[]}]`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Merge(repo, storage, []string{first.ID}, ""); err == nil {
+		t.Error("Expected error when merging a single document")
+	}
+	if _, err := Merge(repo, storage, []string{first.ID, first.ID}, ""); err == nil {
+		t.Error("Expected error when merging a document with itself")
+	}
+	if _, err := Merge(repo, storage, []string{first.ID, "missing"}, ""); err == nil {
+		t.Error("Expected error for unknown document")
+	}
+
+	merged, err := Merge(repo, storage, []string{first.ID, second.ID}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Title != "Part 1" {
+		t.Errorf("Expected default title from first source, got %q", merged.Title)
+	}
+	if want := []string{"work", "draft", "ideas"}; !reflect.DeepEqual(merged.Tags, want) {
+		t.Errorf("Expected tag union %v, got %v", want, merged.Tags)
+	}
+
+	content, err := storage.Load(merged.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks []struct {
+		ID       string                 `json:"id"`
+		Type     string                 `json:"type"`
+		Props    map[string]interface{} `json:"props"`
+		Children []struct {
+			ID string `json:"id"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b.Type)
+	}
+	if want := []string{"heading", "paragraph", "heading", "bookmark"}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("Expected %v, got %v", want, types)
+	}
+	if blocks[1].ID == "a" || blocks[1].Children[0].ID == "a1" || blocks[3].ID == "b" {
+		t.Error("Expected block IDs (including children) to be regenerated")
+	}
+	if blocks[3].Props["url"] != "https://example.com" {
+		t.Errorf("Expected bookmark props to be carried over, got %v", blocks[3].Props)
+	}
+
+	// 来源文档保持不变
+	index, err := repo.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Documents) != 3 {
+		t.Errorf("Expected sources to be kept, got %d documents", len(index.Documents))
+	}
+}
@@ -1,6 +1,7 @@
 package document
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,13 +13,14 @@ import (
 
 // Meta 文档元数据
 type Meta struct {
-	ID        string   `json:"id"`
-	Title     string   `json:"title"`
-	FolderId  string   `json:"folderId,omitempty"`
-	Tags      []string `json:"tags,omitempty"`
-	Order     int      `json:"order"`
-	CreatedAt int64    `json:"createdAt"`
-	UpdatedAt int64    `json:"updatedAt"`
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	FolderId   string   `json:"folderId,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	EmbedModel string   `json:"embedModel,omitempty"` // 覆盖 RAG 嵌入模型（如代码笔记使用代码模型），空表示使用默认模型
+	Order      int      `json:"order"`
+	CreatedAt  int64    `json:"createdAt"`
+	UpdatedAt  int64    `json:"updatedAt"`
 }
 
 // Index 文档索引
@@ -54,6 +56,17 @@ func (r *Repository) GetAll() (Index, error) {
 
 // Create 创建新文档
 func (r *Repository) Create(title string) (Meta, error) {
+	return r.CreateWithContent(title, nil)
+}
+
+// CreateWithContent 使用初始内容创建新文档（content 为空时创建空文档）
+func (r *Repository) CreateWithContent(title string, content json.RawMessage) (Meta, error) {
+	return r.CreateWithTags(title, content, nil)
+}
+
+// CreateWithTags 使用初始内容和标签创建新文档
+// 标签与文档一起写入索引，不会出现先创建、后打标签之间的中间状态
+func (r *Repository) CreateWithTags(title string, content json.RawMessage, tags []string) (Meta, error) {
 	if title == "" {
 		title = constant.DefaultNewDocTitle
 	}
@@ -61,15 +74,19 @@ func (r *Repository) Create(title string) (Meta, error) {
 	doc := Meta{
 		ID:        uuid.New().String(),
 		Title:     title,
+		Tags:      uniqueTags(tags),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	// 创建空文档文件
+	// 创建文档文件
 	docPath := r.paths.Document(doc.ID)
-	// Empty doc is "[]".
-	emptyContent := make([]interface{}, 0)
-	if err := r.SaveJSON(docPath, emptyContent); err != nil {
+	var initial interface{} = content
+	if len(content) == 0 {
+		// Empty doc is "[]".
+		initial = make([]interface{}, 0)
+	}
+	if err := r.SaveJSON(docPath, initial); err != nil {
 		return Meta{}, err
 	}
 
@@ -121,36 +138,6 @@ func (r *Repository) CreateWithID(id, title string) (Meta, error) {
 	return doc, nil
 }
 
-// Delete 删除文档
-func (r *Repository) Delete(id string) error {
-	// 删除文档文件
-	docPath := r.paths.Document(id)
-	if err := r.DeleteFile(docPath); err != nil {
-		return err
-	}
-
-	// 更新索引
-	index, err := r.GetAll()
-	if err != nil {
-		return err
-	}
-	newDocs := []Meta{}
-	for _, d := range index.Documents {
-		if d.ID != id {
-			newDocs = append(newDocs, d)
-		}
-	}
-	index.Documents = newDocs
-	if index.ActiveID == id {
-		if len(newDocs) > 0 {
-			index.ActiveID = newDocs[0].ID
-		} else {
-			index.ActiveID = ""
-		}
-	}
-	return r.saveIndex(index)
-}
-
 // Rename 重命名文档
 func (r *Repository) Rename(id string, newTitle string) error {
 	index, err := r.GetAll()
@@ -192,6 +179,21 @@ func (r *Repository) UpdateTimestamp(id string) error {
 	return r.saveIndex(index)
 }
 
+// SetEmbedModel 设置文档的嵌入模型覆盖（空字符串表示恢复默认模型）
+func (r *Repository) SetEmbedModel(id string, model string) error {
+	index, err := r.GetAll()
+	if err != nil {
+		return err
+	}
+	for i, d := range index.Documents {
+		if d.ID == id {
+			index.Documents[i].EmbedModel = model
+			break
+		}
+	}
+	return r.saveIndex(index)
+}
+
 // MoveToFolder 将文档移动到指定文件夹
 func (r *Repository) MoveToFolder(docId string, folderId string) error {
 	index, err := r.GetAll()
@@ -233,6 +235,35 @@ func (r *Repository) Reorder(ids []string) error {
 	return r.saveIndex(index)
 }
 
+// GetUntaggedDocuments 获取没有任何标签的文档
+func (r *Repository) GetUntaggedDocuments() ([]Meta, error) {
+	index, err := r.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Meta, 0)
+	for _, doc := range index.Documents {
+		if len(doc.Tags) == 0 {
+			result = append(result, doc)
+		}
+	}
+	return result, nil
+}
+
+// uniqueTags 去除空标签和重复标签（保持顺序），没有标签时返回 nil
+func uniqueTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
 // AddTag 为文档添加标签
 func (r *Repository) AddTag(docId string, tag string) error {
 	if tag == "" {
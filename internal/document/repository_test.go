@@ -0,0 +1,41 @@
+package document
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestRepository_CreateWithTags(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository(paths)
+
+	doc, err := repo.CreateWithTags("Meeting", nil, []string{"work", "", "work", "notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"work", "notes"}; !reflect.DeepEqual(doc.Tags, want) {
+		t.Errorf("Expected deduplicated tags %v, got %v", want, doc.Tags)
+	}
+
+	index, err := repo.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Documents) != 1 || !reflect.DeepEqual(index.Documents[0].Tags, doc.Tags) {
+		t.Errorf("Expected tags to be persisted with the document, got %+v", index.Documents)
+	}
+
+	plain, err := repo.CreateWithTags("", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Tags != nil {
+		t.Errorf("Expected no tags, got %v", plain.Tags)
+	}
+}
@@ -1,18 +1,32 @@
 package document
 
 import (
+	"crypto/sha256"
 	"notion-lite/internal/utils"
 	"os"
+	"sync"
+	"time"
 )
 
 // Storage 文档存储
 type Storage struct {
 	paths *utils.PathBuilder
+
+	// 内容哈希缓存：文件大小和修改时间未变时无需重新读取文件即可判断内容是否变化
+	hashMu sync.Mutex
+	hashes map[string]contentHash
+}
+
+// contentHash 文档内容哈希及写入时的文件状态
+type contentHash struct {
+	sum     [sha256.Size]byte
+	size    int64
+	modTime time.Time
 }
 
 // NewStorage 创建文档存储
 func NewStorage(paths *utils.PathBuilder) *Storage {
-	return &Storage{paths: paths}
+	return &Storage{paths: paths, hashes: make(map[string]contentHash)}
 }
 
 // Load 加载指定文档内容
@@ -31,5 +45,42 @@ func (s *Storage) Load(id string) (string, error) {
 // Save 保存指定文档内容
 func (s *Storage) Save(id string, content string) error {
 	docPath := s.paths.Document(id)
-	return os.WriteFile(docPath, []byte(content), 0644)
+	if err := os.WriteFile(docPath, []byte(content), 0644); err != nil {
+		return err
+	}
+	if info, err := os.Stat(docPath); err == nil {
+		s.rememberHash(id, sha256.Sum256([]byte(content)), info)
+	}
+	return nil
+}
+
+// Unchanged 判断内容是否与磁盘上的文档完全一致（文档不存在时返回 false）
+// 优先使用缓存的哈希；文件被外部修改（大小或修改时间变化）时重新读取
+func (s *Storage) Unchanged(id string, content string) bool {
+	info, err := os.Stat(s.paths.Document(id))
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256([]byte(content))
+
+	s.hashMu.Lock()
+	cached, ok := s.hashes[id]
+	s.hashMu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.sum == sum
+	}
+
+	data, err := os.ReadFile(s.paths.Document(id))
+	if err != nil {
+		return false
+	}
+	s.rememberHash(id, sha256.Sum256(data), info)
+	return string(data) == content
+}
+
+// rememberHash 记录文档内容的哈希和对应的文件状态
+func (s *Storage) rememberHash(id string, sum [sha256.Size]byte, info os.FileInfo) {
+	s.hashMu.Lock()
+	defer s.hashMu.Unlock()
+	s.hashes[id] = contentHash{sum: sum, size: info.Size(), modTime: info.ModTime()}
 }
@@ -0,0 +1,52 @@
+package document
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+func TestStorageUnchanged(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage := NewStorage(paths)
+
+	content := `[{"id":"p1","type":"paragraph"}]`
+	if storage.Unchanged("doc1", content) {
+		t.Fatal("Missing document should not be reported as unchanged")
+	}
+	if err := storage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+	if !storage.Unchanged("doc1", content) {
+		t.Error("Identical content should be reported as unchanged")
+	}
+	if storage.Unchanged("doc1", `[{"id":"p2","type":"paragraph"}]`) {
+		t.Error("Different content should be reported as changed")
+	}
+
+	// 外部修改文件后以磁盘内容为准
+	external := `[{"id":"p1","type":"heading"}]`
+	if err := os.WriteFile(paths.Document("doc1"), []byte(external), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(paths.Document("doc1"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if storage.Unchanged("doc1", content) {
+		t.Error("Content should differ from externally modified file")
+	}
+	if !storage.Unchanged("doc1", external) {
+		t.Error("Content matching the externally modified file should be unchanged")
+	}
+
+	// 未经缓存的新 Storage 实例同样按文件内容判断
+	if !NewStorage(paths).Unchanged("doc1", external) {
+		t.Error("Fresh storage should compare against file content")
+	}
+}
@@ -0,0 +1,66 @@
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"notion-lite/internal/blocknote"
+)
+
+// 新文档模板支持的占位符
+const (
+	TemplateDatePlaceholder  = "{{date}}"  // 创建日期（YYYY-MM-DD）
+	TemplateTitlePlaceholder = "{{title}}" // 文档标题
+)
+
+// RenderTemplate 校验新文档模板（BlockNote 块数组 JSON）并替换其中的占位符
+// 每次渲染都为块及其子块生成新 ID，避免多个文档共用同一批块 ID；
+// 模板为空时返回 nil；模板不是合法的块数组时返回错误
+func RenderTemplate(template, title string, now time.Time) (json.RawMessage, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, nil
+	}
+
+	var blocks []map[string]interface{}
+	if err := json.Unmarshal([]byte(template), &blocks); err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if _, ok := block["type"].(string); !ok {
+			return nil, errors.New("template block is missing a type")
+		}
+	}
+
+	replacer := strings.NewReplacer(
+		TemplateDatePlaceholder, now.Format("2006-01-02"),
+		TemplateTitlePlaceholder, title,
+	)
+	for i, block := range blocks {
+		blocks[i] = replacePlaceholders(block, replacer).(map[string]interface{})
+		blocknote.RegenerateIDs(blocks[i])
+	}
+
+	return json.Marshal(blocks)
+}
+
+// replacePlaceholders 递归替换 JSON 值中所有字符串的占位符
+func replacePlaceholders(v interface{}, replacer *strings.Replacer) interface{} {
+	switch value := v.(type) {
+	case string:
+		return replacer.Replace(value)
+	case []interface{}:
+		for i, item := range value {
+			value[i] = replacePlaceholders(item, replacer)
+		}
+		return value
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = replacePlaceholders(item, replacer)
+		}
+		return value
+	default:
+		return v
+	}
+}
@@ -0,0 +1,86 @@
+package document
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local)
+	template := `[
+		{"type": "heading", "props": {"level": 2}, "content": "{{date}} · {{title}}"},
+		{"type": "checkListItem", "content": [{"type": "text", "text": "待办 \"{{title}}\""}]}
+	]`
+
+	content, err := RenderTemplate(template, `周报 "草稿"`, now)
+	if err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	got := string(content)
+	if !strings.Contains(got, `2024-03-05 · 周报 \"草稿\"`) {
+		t.Errorf("Expected date and escaped title in heading, got %s", got)
+	}
+	if strings.Contains(got, "{{") {
+		t.Errorf("Expected all placeholders replaced, got %s", got)
+	}
+}
+
+func TestRenderTemplate_Invalid(t *testing.T) {
+	now := time.Now()
+	for _, template := range []string{`{"type": "paragraph"}`, `[{"content": "x"}]`, `not json`} {
+		if _, err := RenderTemplate(template, "标题", now); err == nil {
+			t.Errorf("Expected error for template %q", template)
+		}
+	}
+	if content, err := RenderTemplate("  ", "标题", now); err != nil || content != nil {
+		t.Errorf("Expected nil content for empty template, got %s, %v", content, err)
+	}
+}
+
+func TestRenderTemplate_FreshIDs(t *testing.T) {
+	template := `[
+		{"id": "tpl-1", "type": "paragraph", "content": [], "children": [
+			{"id": "tpl-2", "type": "paragraph", "content": []}
+		]}
+	]`
+
+	// collectIDs 递归收集块 ID
+	collectIDs := func(content json.RawMessage) map[string]bool {
+		var blocks []interface{}
+		if err := json.Unmarshal(content, &blocks); err != nil {
+			t.Fatal(err)
+		}
+		ids := map[string]bool{}
+		var walk func([]interface{})
+		walk = func(blocks []interface{}) {
+			for _, b := range blocks {
+				block := b.(map[string]interface{})
+				ids[block["id"].(string)] = true
+				children, _ := block["children"].([]interface{})
+				walk(children)
+			}
+		}
+		walk(blocks)
+		return ids
+	}
+
+	first, err := RenderTemplate(template, "A", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := RenderTemplate(template, "B", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstIDs, secondIDs := collectIDs(first), collectIDs(second)
+	if len(firstIDs) != 2 || len(secondIDs) != 2 {
+		t.Fatalf("Expected 2 ids per document, got %v and %v", firstIDs, secondIDs)
+	}
+	for id := range firstIDs {
+		if secondIDs[id] || id == "tpl-1" || id == "tpl-2" {
+			t.Errorf("Expected fresh ids for each rendering, got %q in both or from the template", id)
+		}
+	}
+}
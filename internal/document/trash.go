@@ -0,0 +1,183 @@
+package document
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// TrashEntry 回收站中的文档（保留删除前的元数据，恢复时原样写回索引）
+type TrashEntry struct {
+	Meta
+	DeletedAt int64 `json:"deletedAt"`
+}
+
+// trashManifest 回收站清单
+type trashManifest struct {
+	Documents []TrashEntry `json:"documents"`
+}
+
+// GetTrash 获取回收站中的文档（最近删除的在前）
+func (r *Repository) GetTrash() ([]TrashEntry, error) {
+	manifest, err := r.loadTrash()
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Documents, nil
+}
+
+// Delete 删除文档（移入回收站，可通过 Restore 恢复）
+func (r *Repository) Delete(id string) error {
+	index, err := r.GetAll()
+	if err != nil {
+		return err
+	}
+	var meta *Meta
+	newDocs := []Meta{}
+	for i, d := range index.Documents {
+		if d.ID == id {
+			meta = &index.Documents[i]
+		} else {
+			newDocs = append(newDocs, d)
+		}
+	}
+
+	if meta != nil {
+		if err := r.moveToTrash(*meta); err != nil {
+			return err
+		}
+	} else if err := r.DeleteFile(r.paths.Document(id)); err != nil {
+		// 不在索引中的孤儿文件直接删除
+		return err
+	}
+
+	index.Documents = newDocs
+	if index.ActiveID == id {
+		if len(newDocs) > 0 {
+			index.ActiveID = newDocs[0].ID
+		} else {
+			index.ActiveID = ""
+		}
+	}
+	return r.saveIndex(index)
+}
+
+// moveToTrash 将文档文件移入回收站并记录到清单
+func (r *Repository) moveToTrash(meta Meta) error {
+	if err := os.MkdirAll(r.paths.TrashDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := os.Rename(r.paths.Document(meta.ID), r.paths.TrashDocument(meta.ID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move document to trash: %w", err)
+	}
+
+	manifest, err := r.loadTrash()
+	if err != nil {
+		return err
+	}
+	entry := TrashEntry{Meta: meta, DeletedAt: time.Now().UnixMilli()}
+	manifest.Documents = append([]TrashEntry{entry}, removeTrashEntry(manifest.Documents, meta.ID)...)
+	return r.SaveJSON(r.paths.TrashManifest(), manifest)
+}
+
+// Restore 从回收站恢复文档，返回恢复后的元数据
+func (r *Repository) Restore(id string) (Meta, error) {
+	manifest, err := r.loadTrash()
+	if err != nil {
+		return Meta{}, err
+	}
+	var entry *TrashEntry
+	for i := range manifest.Documents {
+		if manifest.Documents[i].ID == id {
+			entry = &manifest.Documents[i]
+			break
+		}
+	}
+	if entry == nil {
+		return Meta{}, fmt.Errorf("document not found in trash: %s", id)
+	}
+	meta := entry.Meta
+
+	index, err := r.GetAll()
+	if err != nil {
+		return Meta{}, err
+	}
+	for _, d := range index.Documents {
+		if d.ID == id {
+			return Meta{}, fmt.Errorf("document already exists: %s", id)
+		}
+	}
+
+	// 回收站中的文件丢失时恢复为空文档
+	if err := os.Rename(r.paths.TrashDocument(id), r.paths.Document(id)); err != nil {
+		if !os.IsNotExist(err) {
+			return Meta{}, fmt.Errorf("failed to restore document file: %w", err)
+		}
+		if err := r.SaveJSON(r.paths.Document(id), make([]interface{}, 0)); err != nil {
+			return Meta{}, err
+		}
+	}
+
+	index.Documents = append([]Meta{meta}, index.Documents...)
+	index.ActiveID = meta.ID
+	if err := r.saveIndex(index); err != nil {
+		return Meta{}, err
+	}
+
+	manifest.Documents = removeTrashEntry(manifest.Documents, id)
+	if err := r.SaveJSON(r.paths.TrashManifest(), manifest); err != nil {
+		return Meta{}, err
+	}
+	return meta, nil
+}
+
+// EmptyTrash 永久删除回收站中的所有文档，返回删除数量
+func (r *Repository) EmptyTrash() (int, error) {
+	manifest, err := r.loadTrash()
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range manifest.Documents {
+		if err := r.DeleteFile(r.paths.TrashDocument(entry.ID)); err != nil {
+			return 0, err
+		}
+		// 彻底删除后历史版本也不再需要
+		_ = os.RemoveAll(r.paths.DocumentVersions(entry.ID))
+	}
+	if err := r.DeleteFile(r.paths.TrashManifest()); err != nil {
+		return 0, err
+	}
+	return len(manifest.Documents), nil
+}
+
+// LoadTrashedContent 读取回收站中文档的内容（用于清理未引用资源时保留回收站文档引用的图片）
+func (r *Repository) LoadTrashedContent(id string) (string, error) {
+	data, err := os.ReadFile(r.paths.TrashDocument(id))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// loadTrash 读取回收站清单（不存在时为空）
+func (r *Repository) loadTrash() (trashManifest, error) {
+	var manifest trashManifest
+	if err := r.LoadJSON(r.paths.TrashManifest(), &manifest); err != nil {
+		return trashManifest{}, fmt.Errorf("failed to load trash manifest: %w", err)
+	}
+	if manifest.Documents == nil {
+		manifest.Documents = []TrashEntry{}
+	}
+	return manifest, nil
+}
+
+// removeTrashEntry 从清单中移除指定文档
+func removeTrashEntry(entries []TrashEntry, id string) []TrashEntry {
+	result := make([]TrashEntry, 0, len(entries))
+	for _, e := range entries {
+		if e.ID != id {
+			result = append(result, e)
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package document
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestRepository_TrashAndRestore(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository(paths)
+	storage := NewStorage(paths)
+
+	content := json.RawMessage(`[{"id":"p1","type":"paragraph","content":[{"type":"text","text":"keep me"}]}]`)
+	doc, err := repo.CreateWithTags("Notes", content, []string{"work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := repo.Create("Other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := storage.Load(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(doc.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	index, _ := repo.GetAll()
+	if len(index.Documents) != 1 || index.Documents[0].ID != other.ID {
+		t.Fatalf("Expected deleted document to be hidden from GetAll, got %+v", index.Documents)
+	}
+	if _, err := os.Stat(paths.Document(doc.ID)); !os.IsNotExist(err) {
+		t.Errorf("Expected document file to leave the documents directory, got %v", err)
+	}
+	trash, err := repo.GetTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].Title != "Notes" || trash[0].DeletedAt == 0 {
+		t.Fatalf("Expected trash entry with title and deletedAt, got %+v", trash)
+	}
+
+	restored, err := repo.Restore(doc.ID)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !reflect.DeepEqual(restored, doc) {
+		t.Errorf("Expected metadata to be restored unchanged, got %+v want %+v", restored, doc)
+	}
+	loaded, err := storage.Load(doc.ID)
+	if err != nil || loaded != before {
+		t.Errorf("Expected content to be restored, got %q (%v)", loaded, err)
+	}
+	index, _ = repo.GetAll()
+	if len(index.Documents) != 2 || index.ActiveID != doc.ID {
+		t.Errorf("Expected restored document to be listed and active, got %+v", index)
+	}
+	if trash, _ := repo.GetTrash(); len(trash) != 0 {
+		t.Errorf("Expected trash to be empty after restore, got %+v", trash)
+	}
+	if _, err := repo.Restore(doc.ID); err == nil {
+		t.Error("Expected restoring a document not in the trash to fail")
+	}
+
+	// 清空回收站后无法再恢复
+	if err := repo.Delete(other.ID); err != nil {
+		t.Fatal(err)
+	}
+	count, err := repo.EmptyTrash()
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 document purged, got %d (%v)", count, err)
+	}
+	if _, err := os.Stat(paths.TrashDocument(other.ID)); !os.IsNotExist(err) {
+		t.Errorf("Expected trashed file to be removed, got %v", err)
+	}
+	if _, err := repo.Restore(other.ID); err == nil {
+		t.Error("Expected restore after EmptyTrash to fail")
+	}
+}
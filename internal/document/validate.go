@@ -0,0 +1,63 @@
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ValidationIssue 无法解析的文档
+type ValidationIssue struct {
+	DocID string `json:"docId"`
+	Title string `json:"title"`
+	Error string `json:"error"`
+}
+
+// ValidationReport 文档校验结果
+type ValidationReport struct {
+	Done      bool              `json:"done"`      // 校验是否已完成
+	Checked   int               `json:"checked"`   // 已检查的文档数
+	Invalid   []ValidationIssue `json:"invalid"`   // 无法解析的文档
+	CheckedAt int64             `json:"checkedAt"` // 完成时间（Unix 毫秒）
+}
+
+// Validate 检查索引中的每个文档文件是否为合法的块数组
+// 只做浅层解析（不解析块内容），用于启动时尽早发现损坏的文档
+func Validate(repo *Repository, storage *Storage) (ValidationReport, error) {
+	report := ValidationReport{Invalid: []ValidationIssue{}}
+	index, err := repo.GetAll()
+	if err != nil {
+		return report, fmt.Errorf("failed to load document index: %w", err)
+	}
+
+	for _, meta := range index.Documents {
+		report.Checked++
+		if err := storage.validateFile(meta.ID); err != nil {
+			report.Invalid = append(report.Invalid, ValidationIssue{
+				DocID: meta.ID,
+				Title: meta.Title,
+				Error: err.Error(),
+			})
+		}
+	}
+	report.Done = true
+	report.CheckedAt = time.Now().UnixMilli()
+	return report, nil
+}
+
+// validateFile 校验单个文档文件（文件不存在视为空文档）
+func (s *Storage) validateFile(id string) error {
+	data, err := os.ReadFile(s.paths.Document(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var blocks []json.RawMessage
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return fmt.Errorf("not a valid block array: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package document
+
+import (
+	"os"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestValidate(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewRepository(paths)
+	storage := NewStorage(paths)
+
+	good, err := repo.Create("Good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := repo.Create("Bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Save(good.ID, `[{"id":"p1","type":"paragraph"}]`); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.Document(bad.ID), []byte(`[{"id":"p1",`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Validate(repo, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Done || report.Checked != 2 {
+		t.Fatalf("Expected 2 checked documents, got %+v", report)
+	}
+	if len(report.Invalid) != 1 || report.Invalid[0].DocID != bad.ID || report.Invalid[0].Title != "Bad" {
+		t.Errorf("Expected only %s to be invalid, got %+v", bad.ID, report.Invalid)
+	}
+
+	// 对象而非数组同样视为损坏
+	if err := os.WriteFile(paths.Document(bad.ID), []byte(`{"id":"p1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if report, _ := Validate(repo, storage); len(report.Invalid) != 1 {
+		t.Errorf("Expected non-array content to be invalid, got %+v", report.Invalid)
+	}
+}
@@ -0,0 +1,117 @@
+package document
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version 文档历史版本（快照）
+type Version struct {
+	Timestamp int64 `json:"timestamp"` // 快照时间（Unix 毫秒，同时是文件名）
+	Size      int64 `json:"size"`      // 内容字节数
+}
+
+// Snapshot 在用 incoming 覆盖文档之前为磁盘上的当前内容创建快照，并只保留最近 keep 个
+// 当前内容与 incoming 或最新快照的哈希相同（无实际修改）、或最新快照距今不足 minInterval 时跳过。
+// keep <= 0 表示关闭版本历史；文档尚不存在时不创建快照
+func (s *Storage) Snapshot(id string, incoming string, keep int, minInterval time.Duration) error {
+	if keep <= 0 {
+		return nil
+	}
+	current, err := os.ReadFile(s.paths.Document(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read document: %w", err)
+	}
+	currentSum := sha256.Sum256(current)
+	if currentSum == sha256.Sum256([]byte(incoming)) {
+		return nil
+	}
+
+	versions, err := s.ListVersions(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if len(versions) > 0 {
+		latest := versions[0]
+		if minInterval > 0 && now.Sub(time.UnixMilli(latest.Timestamp)) < minInterval {
+			return nil
+		}
+		if data, err := os.ReadFile(s.paths.DocumentVersion(id, latest.Timestamp)); err == nil &&
+			sha256.Sum256(data) == currentSum {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(s.paths.DocumentVersions(id), 0755); err != nil {
+		return fmt.Errorf("failed to create versions directory: %w", err)
+	}
+	timestamp := now.UnixMilli()
+	if len(versions) > 0 && timestamp <= versions[0].Timestamp {
+		timestamp = versions[0].Timestamp + 1
+	}
+	if err := os.WriteFile(s.paths.DocumentVersion(id, timestamp), current, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	// 清理超出保留数量的旧快照（versions 不含刚写入的快照）
+	if len(versions) >= keep {
+		for _, v := range versions[keep-1:] {
+			_ = os.Remove(s.paths.DocumentVersion(id, v.Timestamp))
+		}
+	}
+	return nil
+}
+
+// ListVersions 列出文档的历史版本（按时间倒序，最新的在前）
+func (s *Storage) ListVersions(id string) ([]Version, error) {
+	entries, err := os.ReadDir(s.paths.DocumentVersions(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Version{}, nil
+		}
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	versions := make([]Version, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		versions = append(versions, Version{Timestamp: timestamp, Size: info.Size()})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Timestamp > versions[j].Timestamp
+	})
+	return versions, nil
+}
+
+// LoadVersion 读取指定时间戳的快照内容
+func (s *Storage) LoadVersion(id string, timestamp int64) (string, error) {
+	data, err := os.ReadFile(s.paths.DocumentVersion(id, timestamp))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("version %d of document %s not found", timestamp, id)
+		}
+		return "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,78 @@
+package document
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+func TestStorageSnapshot(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage := NewStorage(paths)
+
+	// 文档不存在时不创建快照
+	if err := storage.Snapshot("doc1", `["v1"]`, 3, 0); err != nil {
+		t.Fatal(err)
+	}
+	if versions, _ := storage.ListVersions("doc1"); len(versions) != 0 {
+		t.Fatalf("Expected no versions for a missing document, got %d", len(versions))
+	}
+
+	save := func(content string) {
+		t.Helper()
+		if err := storage.Snapshot("doc1", content, 3, 0); err != nil {
+			t.Fatal(err)
+		}
+		if err := storage.Save("doc1", content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(`["v1"]`)
+	save(`["v2"]`)
+	save(`["v2"]`) // 内容未变化，按哈希去重
+	versions, err := storage.ListVersions("doc1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 version after an idle save, got %d", len(versions))
+	}
+	if content, _ := storage.LoadVersion("doc1", versions[0].Timestamp); content != `["v1"]` {
+		t.Errorf("Snapshot content = %s, want the overwritten content", content)
+	}
+
+	// 超出保留数量时删除最旧的快照
+	save(`["v3"]`)
+	save(`["v4"]`)
+	save(`["v5"]`)
+	versions, _ = storage.ListVersions("doc1")
+	if len(versions) != 3 {
+		t.Fatalf("Expected retention to keep 3 versions, got %d", len(versions))
+	}
+	want := []string{`["v4"]`, `["v3"]`, `["v2"]`}
+	for i, v := range versions {
+		if content, _ := storage.LoadVersion("doc1", v.Timestamp); content != want[i] {
+			t.Errorf("versions[%d] = %s, want %s", i, content, want[i])
+		}
+	}
+
+	// 最小间隔内不创建新快照；keep <= 0 表示关闭
+	if err := storage.Snapshot("doc1", `["v6"]`, 3, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Snapshot("doc1", `["v6"]`, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if versions, _ = storage.ListVersions("doc1"); len(versions) != 3 {
+		t.Errorf("Expected throttled or disabled snapshots to be skipped, got %d versions", len(versions))
+	}
+
+	if _, err := storage.LoadVersion("doc1", 1); err == nil {
+		t.Error("Expected an error for a missing version")
+	}
+}
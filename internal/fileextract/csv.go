@@ -0,0 +1,147 @@
+package fileextract
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultCSVMaxRows 默认最多提取的数据行数（避免百万行导出文件撑爆索引）
+const DefaultCSVMaxRows = 10000
+
+// csvMaxRows 配置的数据行上限（<= 0 时使用 DefaultCSVMaxRows）
+var csvMaxRows atomic.Int64
+
+// SetCSVMaxRows 设置 CSV/TSV 最多提取的数据行数（<= 0 时使用 DefaultCSVMaxRows）
+func SetCSVMaxRows(maxRows int) {
+	csvMaxRows.Store(int64(maxRows))
+}
+
+// CSVExtractor handles CSV/TSV extraction
+// 每个数据行输出为 "列名: 值, 列名: 值"，让嵌入捕获字段语义而不是一串逗号
+type CSVExtractor struct {
+	// MaxRows 最多提取的数据行数（<= 0 时使用 SetCSVMaxRows 配置的值，未配置时为 DefaultCSVMaxRows）
+	MaxRows int
+}
+
+func init() {
+	Register(&CSVExtractor{})
+}
+
+func (e *CSVExtractor) SupportedExtensions() []string {
+	return []string{".csv", ".tsv"}
+}
+
+func (e *CSVExtractor) Extract(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	comma := ','
+	if strings.ToLower(filepath.Ext(filePath)) == ".tsv" {
+		comma = '\t'
+	}
+	return e.extract(f, comma)
+}
+
+// extract 逐行读取，达到行数上限后停止读取剩余内容
+func (e *CSVExtractor) extract(r io.Reader, comma rune) (string, error) {
+	maxRows := e.MaxRows
+	if maxRows <= 0 {
+		maxRows = int(csvMaxRows.Load())
+	}
+	if maxRows <= 0 {
+		maxRows = DefaultCSVMaxRows
+	}
+
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1 // 允许行的列数不一致
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("no text content found in CSV")
+		}
+		return "", fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := csvColumnNames(header)
+
+	var buf bytes.Buffer
+	rows := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read CSV row %d: %w", rows+1, err)
+		}
+		line := csvRowText(columns, record)
+		if line == "" {
+			continue
+		}
+		if rows == maxRows {
+			buf.WriteString(fmt.Sprintf("[truncated after %d rows]\n", maxRows))
+			break
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+		rows++
+	}
+
+	// 只有表头时至少保留列名
+	if rows == 0 {
+		buf.WriteString(strings.Join(columns, ", "))
+	}
+
+	result := strings.TrimSpace(buf.String())
+	if result == "" {
+		return "", fmt.Errorf("no text content found in CSV")
+	}
+	return result, nil
+}
+
+// csvColumnNames 整理表头（去掉 BOM，空列名用列序号代替）
+func csvColumnNames(header []string) []string {
+	columns := make([]string, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\uFEFF")
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("column %d", i+1)
+		}
+		columns[i] = name
+	}
+	return columns
+}
+
+// csvRowText 将一行渲染为 "列名: 值" 列表（跳过空值，超出表头的列使用列序号）
+func csvRowText(columns []string, record []string) string {
+	var parts []string
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		name := fmt.Sprintf("column %d", i+1)
+		if i < len(columns) {
+			name = columns[i]
+		}
+		parts = append(parts, name+": "+value)
+	}
+	return strings.Join(parts, ", ")
+}
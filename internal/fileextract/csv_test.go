@@ -0,0 +1,75 @@
+package fileextract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSVExtractor_HeaderAwareRows(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "people.csv")
+	content := "\uFEFFname,city,\n\"Lee, Ann\",Berlin,x\n,,\nBob,,y\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ExtractText(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "name: Lee, Ann, city: Berlin, column 3: x\nname: Bob, column 3: y"
+	if got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	tsvPath := filepath.Join(dir, "scores.tsv")
+	if err := os.WriteFile(tsvPath, []byte("team\tscore\nred\t3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = ExtractText(tsvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "team: red, score: 3" {
+		t.Errorf("Unexpected TSV output: %q", got)
+	}
+}
+
+func TestCSVExtractor_MaxRows(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("id\n")
+	for i := 0; i < 10; i++ {
+		sb.WriteString("row\n")
+	}
+
+	extractor := &CSVExtractor{MaxRows: 3}
+	got, err := extractor.extract(strings.NewReader(sb.String()), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) != 4 || lines[3] != "[truncated after 3 rows]" {
+		t.Errorf("Expected 3 rows and a truncation note, got %q", got)
+	}
+
+	// 恰好达到上限时不应标记截断
+	got, _ = (&CSVExtractor{MaxRows: 10}).extract(strings.NewReader(sb.String()), ',')
+	if strings.Contains(got, "truncated") {
+		t.Errorf("Unexpected truncation note: %q", got)
+	}
+}
+
+func TestCSVExtractor_ConfiguredMaxRows(t *testing.T) {
+	SetCSVMaxRows(2)
+	defer SetCSVMaxRows(0)
+
+	got, err := (&CSVExtractor{}).extract(strings.NewReader("id\n1\n2\n3\n"), ',')
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(got, "[truncated after 2 rows]") {
+		t.Errorf("Expected the configured row cap to apply, got %q", got)
+	}
+}
@@ -57,7 +57,11 @@ func GetMimeType(filename string) string {
 		".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
 		".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		".xls":  "application/vnd.ms-excel",
+		".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		".rtf":  "application/rtf",
 		".epub": "application/epub+zip",
+		".csv":  "text/csv",
+		".tsv":  "text/tab-separated-values",
 		".md":   "text/markdown",
 		".txt":  "text/plain",
 		".html": "text/html",
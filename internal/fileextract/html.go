@@ -61,6 +61,16 @@ func extractTextFromNode(n *html.Node, buf *bytes.Buffer) {
 		return
 	}
 
+	// h1-h3 输出为 Markdown 标题，供分块时识别章节结构
+	if n.Type == html.ElementNode {
+		switch n.Data {
+		case "h1", "h2", "h3":
+			buf.WriteString("\n")
+			buf.WriteString(strings.Repeat("#", int(n.Data[1]-'0')))
+			buf.WriteString(" ")
+		}
+	}
+
 	if n.Type == html.TextNode {
 		text := strings.TrimSpace(n.Data)
 		if text != "" {
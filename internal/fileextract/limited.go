@@ -0,0 +1,138 @@
+package fileextract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxExtractBytes 单个文件默认最多提取的文本量
+const DefaultMaxExtractBytes = 4 * 1024 * 1024
+
+// LimitedExtractor 可在提取过程中限制文本量的提取器（避免超大文件占满内存）
+type LimitedExtractor interface {
+	// ExtractLimited 最多累积 maxBytes 字节的文本，超出部分丢弃并返回 truncated=true
+	ExtractLimited(filePath string, maxBytes int) (text string, truncated bool, err error)
+}
+
+// ContextLimitedExtractor 调用外部进程等耗时操作、可随 ctx 取消的限量提取器
+type ContextLimitedExtractor interface {
+	ExtractLimitedContext(ctx context.Context, filePath string, maxBytes int) (text string, truncated bool, err error)
+}
+
+// ExtractTextLimited 提取文本内容，最多保留 maxBytes 字节（按 UTF-8 字符边界截断）
+// 支持流式提取的格式在达到上限后停止累积；其余格式提取完成后再截断。maxBytes <= 0 表示不限制
+func ExtractTextLimited(filePath string, maxBytes int) (string, bool, error) {
+	return ExtractTextLimitedContext(context.Background(), filePath, maxBytes)
+}
+
+// ExtractTextLimitedContext 同 ExtractTextLimited，ctx 传递给支持取消的提取器（如 PDF 的 OCR 回退）
+func ExtractTextLimitedContext(ctx context.Context, filePath string, maxBytes int) (string, bool, error) {
+	if maxBytes <= 0 {
+		text, err := ExtractText(filePath)
+		return text, false, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+	extractor, ok := GetExtractor(ext)
+	if !ok {
+		return extractGenericTextLimited(filePath, maxBytes)
+	}
+	if limited, ok := extractor.(ContextLimitedExtractor); ok {
+		return limited.ExtractLimitedContext(ctx, filePath, maxBytes)
+	}
+	if limited, ok := extractor.(LimitedExtractor); ok {
+		return limited.ExtractLimited(filePath, maxBytes)
+	}
+
+	text, err := extractor.Extract(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	if len(text) <= maxBytes {
+		return text, false, nil
+	}
+	return truncateUTF8(text, maxBytes), true, nil
+}
+
+// extractGenericTextLimited 通用文本文件的限量提取
+func extractGenericTextLimited(filePath string, maxBytes int) (string, bool, error) {
+	isText, err := IsTextFile(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to check file type: %w", err)
+	}
+	if !isText {
+		return "", false, fmt.Errorf("file appears to be binary, not text")
+	}
+	return readFileLimited(filePath, maxBytes)
+}
+
+// readFileLimited 最多读取 maxBytes 字节（多读 1 字节用于判断是否截断）
+func readFileLimited(filePath string, maxBytes int) (string, bool, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, int64(maxBytes)+1))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) <= maxBytes {
+		return string(data), false, nil
+	}
+	return truncateUTF8(string(data), maxBytes), true, nil
+}
+
+// truncateUTF8 截断到不超过 maxBytes 字节，且不切断多字节字符
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// limitedBuffer 最多保存 max 字节的 io.Writer，超出的写入被丢弃（不返回错误，避免中断写入方）
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.max - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// String 返回已保存的文本（截断时去掉末尾不完整的多字节字符）
+func (b *limitedBuffer) String() string {
+	s := b.buf.String()
+	if !b.truncated {
+		return s
+	}
+	for i := len(s) - 1; i >= 0 && i >= len(s)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(s[i]) {
+			if !utf8.FullRuneInString(s[i:]) {
+				s = s[:i]
+			}
+			break
+		}
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package fileextract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestExtractTextLimited(t *testing.T) {
+	dir := t.TempDir()
+	// 每行 3 + 9 + 1 = 13 字节（"abc" + 三个中文字符 + 换行），边界会落在多字节字符内部
+	line := "abc中文字\n"
+	large := filepath.Join(dir, "large.txt")
+	if err := os.WriteFile(large, []byte(strings.Repeat(line, 200000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	const maxBytes = 1000
+	text, truncated, err := ExtractTextLimited(large, maxBytes)
+	if err != nil {
+		t.Fatalf("ExtractTextLimited: %v", err)
+	}
+	if !truncated {
+		t.Error("expected truncated=true for a file larger than the cap")
+	}
+	if len(text) > maxBytes || len(text) < maxBytes-utf8.UTFMax {
+		t.Errorf("len(text) = %d, want within (%d, %d]", len(text), maxBytes-utf8.UTFMax, maxBytes)
+	}
+	if !utf8.ValidString(text) {
+		t.Error("truncated text is not valid UTF-8")
+	}
+	if !strings.HasPrefix(strings.Repeat(line, 200000), text) {
+		t.Error("truncated text is not a prefix of the file")
+	}
+
+	small := filepath.Join(dir, "small.txt")
+	if err := os.WriteFile(small, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text, truncated, err = ExtractTextLimited(small, maxBytes)
+	if err != nil || truncated || text != line {
+		t.Errorf("small file: got (%q, %v, %v), want (%q, false, nil)", text, truncated, err, line)
+	}
+
+	// 文件恰好等于上限时不算截断
+	exact := filepath.Join(dir, "exact.txt")
+	if err := os.WriteFile(exact, []byte(strings.Repeat("x", maxBytes)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text, truncated, _ = ExtractTextLimited(exact, maxBytes); truncated || len(text) != maxBytes {
+		t.Errorf("exact-size file: len=%d truncated=%v, want %d false", len(text), truncated, maxBytes)
+	}
+
+	// 未注册扩展名走通用文本路径
+	generic := filepath.Join(dir, "large.log")
+	if err := os.WriteFile(generic, []byte(strings.Repeat(line, 1000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	text, truncated, err = ExtractTextLimited(generic, maxBytes)
+	if err != nil || !truncated || len(text) > maxBytes || !utf8.ValidString(text) {
+		t.Errorf("generic file: len=%d truncated=%v err=%v", len(text), truncated, err)
+	}
+
+	// maxBytes <= 0 表示不限制
+	if text, truncated, _ = ExtractTextLimited(generic, 0); truncated || len(text) != len(line)*1000 {
+		t.Errorf("unlimited: len=%d truncated=%v", len(text), truncated)
+	}
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{max: 5}
+	for _, s := range []string{"ab", "c中", "文"} {
+		if n, err := b.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+	if !b.truncated {
+		t.Error("expected truncated after exceeding max")
+	}
+	// "abc" + 中 的前两个字节，末尾不完整字符应被去掉
+	if got := b.String(); got != "abc" {
+		t.Errorf("String() = %q, want %q", got, "abc")
+	}
+}
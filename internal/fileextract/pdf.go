@@ -2,6 +2,7 @@ package fileextract
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -28,18 +29,60 @@ func (e *PDFExtractor) SupportedExtensions() []string {
 }
 
 func (e *PDFExtractor) Extract(filePath string) (string, error) {
+	// 扫描版 PDF 没有文本层，启用后回退到 OCR
+	return withOCRFallback(filePath, e.extractText, ocrWithContext(context.Background()))
+}
+
+// ExtractLimited 提取 PDF 文本，累积到 maxBytes 后丢弃其余内容
+func (e *PDFExtractor) ExtractLimited(filePath string, maxBytes int) (string, bool, error) {
+	return e.ExtractLimitedContext(context.Background(), filePath, maxBytes)
+}
+
+// ExtractLimitedContext 同 ExtractLimited，ctx 取消时终止 OCR 回退
+func (e *PDFExtractor) ExtractLimitedContext(ctx context.Context, filePath string, maxBytes int) (string, bool, error) {
+	truncated := false
+	primary := func(path string) (string, error) {
+		text, t, err := e.extractTextLimited(path, maxBytes)
+		truncated = t
+		return text, err
+	}
+	text, err := withOCRFallback(filePath, primary, ocrWithContext(ctx))
+	if err != nil {
+		return "", false, err
+	}
+	if len(text) > maxBytes { // OCR 结果
+		return truncateUTF8(text, maxBytes), true, nil
+	}
+	return text, truncated, nil
+}
+
+// ocrWithContext 将 ctx 绑定到 OCR 回退
+func ocrWithContext(ctx context.Context) func(string) (string, error) {
+	return func(filePath string) (string, error) {
+		return runPDFOCR(ctx, filePath)
+	}
+}
+
+// extractText 提取 PDF 文本层
+func (e *PDFExtractor) extractText(filePath string) (string, error) {
+	text, _, err := e.extractTextLimited(filePath, 0)
+	return text, err
+}
+
+// extractTextLimited 提取 PDF 文本层，maxBytes > 0 时最多保留 maxBytes 字节
+func (e *PDFExtractor) extractTextLimited(filePath string, maxBytes int) (string, bool, error) {
 	// 优先尝试 pdftotext
 	if e.checkPdftotextAvailable() {
-		result, err := e.extractWithPdftotext(filePath)
+		result, truncated, err := e.extractWithPdftotext(filePath, maxBytes)
 		if err == nil && result != "" {
-			return result, nil
+			return result, truncated, nil
 		}
 		// pdftotext 失败，回退到 Go 库
 		fmt.Printf("⚠️ [PDF] pdftotext failed, falling back to Go library: %v\n", err)
 	}
 
 	// 回退：使用 Go 库
-	return e.extractWithGoLib(filePath)
+	return e.extractWithGoLib(filePath, maxBytes)
 }
 
 // checkPdftotextAvailable 检查系统是否安装了 pdftotext
@@ -62,31 +105,46 @@ func (e *PDFExtractor) checkPdftotextAvailable() bool {
 
 // extractWithPdftotext 使用 pdftotext 命令提取 PDF 文本
 // -layout 参数保留原始布局，对表格友好
-func (e *PDFExtractor) extractWithPdftotext(filePath string) (string, error) {
+// maxBytes > 0 时超出部分的输出被丢弃，不会占用内存
+func (e *PDFExtractor) extractWithPdftotext(filePath string, maxBytes int) (string, bool, error) {
 	// pdftotext -layout file.pdf - (输出到 stdout)
 	cmd := exec.Command("pdftotext", "-layout", "-enc", "UTF-8", filePath, "-")
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("pdftotext failed: %w", err)
+	var output string
+	truncated := false
+	if maxBytes > 0 {
+		stdout := &limitedBuffer{max: maxBytes}
+		cmd.Stdout = stdout
+		if err := cmd.Run(); err != nil {
+			return "", false, fmt.Errorf("pdftotext failed: %w", err)
+		}
+		output, truncated = stdout.String(), stdout.truncated
+	} else {
+		data, err := cmd.Output()
+		if err != nil {
+			return "", false, fmt.Errorf("pdftotext failed: %w", err)
+		}
+		output = string(data)
 	}
 
-	result := strings.TrimSpace(string(output))
+	result := strings.TrimSpace(output)
 	if result == "" {
-		return "", fmt.Errorf("no text content found in PDF")
+		return "", false, fmt.Errorf("no text content found in PDF")
 	}
-	return result, nil
+	return result, truncated, nil
 }
 
 // extractWithGoLib 使用 Go 库提取 PDF 文本（回退方案）
-func (e *PDFExtractor) extractWithGoLib(filePath string) (string, error) {
+// maxBytes > 0 时累积到上限后不再解析后续页面
+func (e *PDFExtractor) extractWithGoLib(filePath string, maxBytes int) (string, bool, error) {
 	f, r, err := pdf.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open PDF: %w", err)
+		return "", false, fmt.Errorf("failed to open PDF: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
 	var buf bytes.Buffer
 	totalPages := r.NumPage()
+	truncated := false
 
 	for pageNum := 1; pageNum <= totalPages; pageNum++ {
 		page := r.Page(pageNum)
@@ -100,11 +158,19 @@ func (e *PDFExtractor) extractWithGoLib(filePath string) (string, error) {
 		}
 		buf.WriteString(text)
 		buf.WriteString("\n")
+		if maxBytes > 0 && buf.Len() > maxBytes {
+			truncated = true
+			break
+		}
 	}
 
-	result := strings.TrimSpace(buf.String())
+	result := buf.String()
+	if truncated {
+		result = truncateUTF8(result, maxBytes)
+	}
+	result = strings.TrimSpace(result)
 	if result == "" {
-		return "", fmt.Errorf("no text content found in PDF")
+		return "", false, fmt.Errorf("no text content found in PDF")
 	}
-	return result, nil
+	return result, truncated, nil
 }
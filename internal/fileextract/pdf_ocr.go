@@ -0,0 +1,128 @@
+package fileextract
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxOCRPages OCR 回退最多处理的页数（每页需要数秒）
+	maxOCRPages = 100
+	// ocrFileTimeout 单个文件 OCR（渲染加识别全部页面）的最长耗时，超时后终止外部进程
+	ocrFileTimeout = 10 * time.Minute
+)
+
+var (
+	// pdfOCREnabled 是否对没有文本层的 PDF 启用 OCR 回退（较慢，默认关闭）
+	pdfOCREnabled atomic.Bool
+
+	ocrAvailable     bool
+	ocrMu            sync.Once
+	ocrHintShown     bool
+	ocrToolsDetected = checkOCRAvailable // 测试中可替换
+)
+
+// SetPDFOCREnabled 设置是否对扫描版（无文本层）PDF 启用 tesseract OCR 回退
+func SetPDFOCREnabled(enabled bool) {
+	pdfOCREnabled.Store(enabled)
+}
+
+// withOCRFallback 主提取结果为空时（扫描版 PDF），在启用且工具可用的情况下改用 OCR
+// OCR 失败时返回主提取的原始错误
+func withOCRFallback(filePath string, primary, ocr func(string) (string, error)) (string, error) {
+	text, err := primary(filePath)
+	if err == nil && strings.TrimSpace(text) != "" {
+		return text, nil
+	}
+	if !pdfOCREnabled.Load() || !ocrToolsDetected() {
+		return text, err
+	}
+
+	fmt.Printf("🔍 [PDF] No text layer found, running OCR: %s\n", filepath.Base(filePath))
+	ocrText, ocrErr := ocr(filePath)
+	if ocrErr != nil {
+		fmt.Printf("⚠️ [PDF] OCR failed: %v\n", ocrErr)
+		return text, err
+	}
+	return ocrText, nil
+}
+
+// checkOCRAvailable 检查系统是否安装了 tesseract 与 pdftoppm（用于将页面渲染为图片）
+func checkOCRAvailable() bool {
+	ocrMu.Do(func() {
+		_, tesseractErr := exec.LookPath("tesseract")
+		_, pdftoppmErr := exec.LookPath("pdftoppm")
+		ocrAvailable = tesseractErr == nil && pdftoppmErr == nil
+
+		if ocrAvailable {
+			fmt.Println("🔍 [PDF] tesseract detected, OCR fallback enabled for scanned PDFs")
+		} else if !ocrHintShown {
+			ocrHintShown = true
+			fmt.Println("💡 [PDF] 提示: 扫描版 PDF 的 OCR 需要安装 tesseract 和 poppler")
+			if tesseractErr != nil {
+				fmt.Println(getInstallHint("tesseract"))
+			}
+			if pdftoppmErr != nil {
+				fmt.Println(getInstallHint("pdftotext"))
+			}
+		}
+	})
+	return ocrAvailable
+}
+
+// runPDFOCR 用 pdftoppm 将页面渲染为图片，再逐页用 tesseract 识别文本
+// ctx 取消或超过 ocrFileTimeout 时终止正在运行的 pdftoppm/tesseract
+func runPDFOCR(ctx context.Context, filePath string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, ocrFileTimeout)
+	defer cancel()
+
+	tmpDir, err := os.MkdirTemp("", "nook-ocr-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	// pdftoppm -r 300 -gray -png -l N file.pdf <prefix>  → <prefix>-01.png ...
+	cmd := exec.CommandContext(ctx, "pdftoppm", "-r", "300", "-gray", "-png", "-l", strconv.Itoa(maxOCRPages), filePath, filepath.Join(tmpDir, "page"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("pdftoppm interrupted: %w", ctx.Err())
+		}
+		return "", fmt.Errorf("pdftoppm failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	pages, err := filepath.Glob(filepath.Join(tmpDir, "page-*.png"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(pages) // pdftoppm 按总页数补零，字典序即页码顺序
+
+	var texts []string
+	for _, page := range pages {
+		// tesseract image stdout（输出到 stdout）
+		output, err := exec.CommandContext(ctx, "tesseract", page, "stdout").Output()
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("tesseract interrupted: %w", ctx.Err())
+			}
+			return "", fmt.Errorf("tesseract failed on %s: %w", filepath.Base(page), err)
+		}
+		if text := strings.TrimSpace(string(output)); text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	if len(texts) == 0 {
+		return "", fmt.Errorf("no text recognized by OCR")
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
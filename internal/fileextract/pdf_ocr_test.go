@@ -0,0 +1,78 @@
+package fileextract
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+)
+
+func TestWithOCRFallback(t *testing.T) {
+	origDetect := ocrToolsDetected
+	defer func() {
+		ocrToolsDetected = origDetect
+		SetPDFOCREnabled(false)
+	}()
+	ocrToolsDetected = func() bool { return true }
+
+	ocrCalls := 0
+	ocr := func(string) (string, error) {
+		ocrCalls++
+		return "识别出的文本", nil
+	}
+	withText := func(string) (string, error) { return "text layer", nil }
+	empty := func(string) (string, error) { return "  \n", nil }
+	failing := func(string) (string, error) { return "", errors.New("no text") }
+
+	SetPDFOCREnabled(true)
+	if got, err := withOCRFallback("a.pdf", withText, ocr); err != nil || got != "text layer" {
+		t.Fatalf("primary text: got %q, %v", got, err)
+	}
+	if ocrCalls != 0 {
+		t.Fatalf("OCR should not run when the text layer is present, calls=%d", ocrCalls)
+	}
+
+	if got, err := withOCRFallback("a.pdf", empty, ocr); err != nil || got != "识别出的文本" {
+		t.Fatalf("empty primary: got %q, %v", got, err)
+	}
+	if got, err := withOCRFallback("a.pdf", failing, ocr); err != nil || got != "识别出的文本" {
+		t.Fatalf("failing primary: got %q, %v", got, err)
+	}
+	if ocrCalls != 2 {
+		t.Fatalf("expected 2 OCR calls, got %d", ocrCalls)
+	}
+
+	// 关闭时不回退，保留原始错误
+	SetPDFOCREnabled(false)
+	if _, err := withOCRFallback("a.pdf", failing, ocr); err == nil {
+		t.Fatal("expected primary error when OCR is disabled")
+	}
+
+	// 工具缺失时不回退
+	SetPDFOCREnabled(true)
+	ocrToolsDetected = func() bool { return false }
+	if _, err := withOCRFallback("a.pdf", failing, ocr); err == nil {
+		t.Fatal("expected primary error when tesseract is unavailable")
+	}
+
+	// OCR 失败时返回原始错误
+	ocrToolsDetected = func() bool { return true }
+	ocrErr := func(string) (string, error) { return "", errors.New("tesseract crashed") }
+	if _, err := withOCRFallback("a.pdf", failing, ocrErr); err == nil || err.Error() != "no text" {
+		t.Fatalf("expected primary error after OCR failure, got %v", err)
+	}
+	if ocrCalls != 2 {
+		t.Fatalf("OCR should not run when disabled or unavailable, calls=%d", ocrCalls)
+	}
+}
+
+func TestRunPDFOCR_Canceled(t *testing.T) {
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		t.Skip("pdftoppm not installed")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := runPDFOCR(ctx, "missing.pdf"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
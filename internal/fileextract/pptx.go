@@ -0,0 +1,137 @@
+package fileextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PPTXExtractor handles PowerPoint text extraction
+type PPTXExtractor struct{}
+
+// drawingMLNamespace DrawingML 命名空间（幻灯片文本位于 <a:t> 标签中）
+const drawingMLNamespace = "http://schemas.openxmlformats.org/drawingml/2006/main"
+
+func init() {
+	Register(&PPTXExtractor{})
+}
+
+func (e *PPTXExtractor) SupportedExtensions() []string {
+	return []string{".pptx"}
+}
+
+func (e *PPTXExtractor) Extract(filePath string) (string, error) {
+	r, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PPTX: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	// 按幻灯片编号排序（slide10 应在 slide9 之后）
+	var slides []*zip.File
+	for _, f := range r.File {
+		if slideNumber(f.Name) > 0 {
+			slides = append(slides, f)
+		}
+	}
+	sort.Slice(slides, func(i, j int) bool {
+		return slideNumber(slides[i].Name) < slideNumber(slides[j].Name)
+	})
+
+	var buf bytes.Buffer
+	for _, f := range slides {
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			continue
+		}
+
+		// 幻灯片之间空一行，分块时保留幻灯片边界
+		text := extractTextFromSlideXML(data)
+		if text != "" {
+			buf.WriteString(text)
+			buf.WriteString("\n\n")
+		}
+	}
+
+	result := strings.TrimSpace(buf.String())
+	if result == "" {
+		return "", fmt.Errorf("no text content found in PPTX")
+	}
+	return result, nil
+}
+
+// slideNumber 解析 ppt/slides/slideN.xml 的编号，非幻灯片文件返回 0
+func slideNumber(name string) int {
+	if path.Dir(name) != "ppt/slides" {
+		return 0
+	}
+	base := path.Base(name)
+	if !strings.HasPrefix(base, "slide") || !strings.HasSuffix(base, ".xml") {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(base, "slide"), ".xml"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// extractTextFromSlideXML 从幻灯片 XML 中提取 <a:t> 文本，每个 <a:p> 段落一行
+func extractTextFromSlideXML(data []byte) string {
+	var lines []string
+	var line strings.Builder
+	inText := false
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != drawingMLNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "br": // 段落内的软换行
+				line.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Space != drawingMLNamespace {
+				continue
+			}
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if text := strings.TrimSpace(line.String()); text != "" {
+					lines = append(lines, text)
+				}
+				line.Reset()
+			}
+		case xml.CharData:
+			if inText {
+				line.Write(t)
+			}
+		}
+	}
+	if text := strings.TrimSpace(line.String()); text != "" {
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,62 @@
+package fileextract
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// slideXML 构造只包含文本框的幻灯片 XML
+func slideXML(paragraphs ...string) string {
+	body := ""
+	for _, p := range paragraphs {
+		body += `<a:p><a:r><a:rPr lang="en-US"/><a:t>` + p + `</a:t></a:r></a:p>`
+	}
+	return `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main">
+<p:cSld><p:spTree><p:sp><p:txBody>` + body + `</p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+}
+
+func TestPPTXExtractor_SlidesInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(file)
+	entries := map[string]string{
+		"ppt/slides/slide10.xml":            slideXML("Ten"),
+		"ppt/slides/slide2.xml":             slideXML("Second", "slide &amp; more"),
+		"ppt/slides/slide1.xml":             slideXML("第一页"),
+		"ppt/slides/_rels/slide1.xml.rels":  `<Relationships/>`,
+		"ppt/slideLayouts/slideLayout1.xml": slideXML("Layout placeholder"),
+		"ppt/notesSlides/notesSlide1.xml":   slideXML("Speaker notes"),
+	}
+	for name, content := range entries {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	extractor, ok := GetExtractor(".pptx")
+	if !ok {
+		t.Fatal("Expected .pptx extractor to be registered")
+	}
+	text, err := extractor.Extract(path)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := "第一页\n\nSecond\nslide & more\n\nTen"
+	if text != want {
+		t.Errorf("got %q\nwant %q", text, want)
+	}
+}
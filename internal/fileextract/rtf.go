@@ -0,0 +1,279 @@
+package fileextract
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// RTFExtractor handles Rich Text Format extraction
+type RTFExtractor struct{}
+
+func init() {
+	Register(&RTFExtractor{})
+}
+
+func (e *RTFExtractor) SupportedExtensions() []string {
+	return []string{".rtf"}
+}
+
+func (e *RTFExtractor) Extract(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "{\\rtf") {
+		return "", fmt.Errorf("not an RTF document")
+	}
+
+	text := parseRTF(data)
+	if text == "" {
+		return "", fmt.Errorf("no text content found in RTF")
+	}
+	return text, nil
+}
+
+// rtfSkipDestinations 不包含正文的目标组（字体表、样式表、图片、页眉页脚等）
+var rtfSkipDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true,
+	"pict": true, "object": true, "fldinst": true, "filetbl": true,
+	"listtable": true, "listoverridetable": true, "revtbl": true, "rsidtbl": true,
+	"generator": true, "xmlnstbl": true, "themedata": true, "colorschememapping": true,
+	"datastore": true, "latentstyles": true, "header": true, "headerl": true,
+	"headerr": true, "headerf": true, "footer": true, "footerl": true,
+	"footerr": true, "footerf": true, "footnote": true,
+}
+
+// rtfSymbols 输出固定文本的控制字
+var rtfSymbols = map[string]string{
+	"par": "\n", "line": "\n", "sect": "\n", "page": "\n", "row": "\n",
+	"tab": "\t", "cell": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+}
+
+// rtfGroup 组的解析状态（进入 { 时继承，离开 } 时恢复）
+type rtfGroup struct {
+	skip bool // 当前组不输出文本
+	uc   int  // \uN 之后需要跳过的替代字符数
+}
+
+// rtfParser RTF 解析状态
+type rtfParser struct {
+	out      strings.Builder
+	pending  []byte // 待按代码页解码的 \'hh 字节（多字节编码的一个字符由多个转义组成）
+	encoding encoding.Encoding
+	group    rtfGroup
+	stack    []rtfGroup
+	skipNext int // 尚未跳过的 \uN 替代字符数
+}
+
+// parseRTF 提取 RTF 正文：去除控制字，解码 \'hh 与 \uN 转义，跳过非正文目标组
+func parseRTF(data []byte) string {
+	p := &rtfParser{encoding: charmap.Windows1252, group: rtfGroup{uc: 1}}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '{':
+			p.flush()
+			p.stack = append(p.stack, p.group)
+		case '}':
+			p.flush()
+			if n := len(p.stack); n > 0 {
+				p.group = p.stack[n-1]
+				p.stack = p.stack[:n-1]
+			}
+			p.skipNext = 0
+		case '\\':
+			i = p.control(data, i)
+		case '\r', '\n':
+			// 原始换行没有意义，段落由 \par 表示
+		default:
+			if p.consumeSkip() {
+				continue
+			}
+			p.flush()
+			if !p.group.skip {
+				p.out.WriteByte(c)
+			}
+		}
+	}
+	p.flush()
+	return normalizeRTFText(p.out.String())
+}
+
+// control 解析从 data[i]（反斜杠）开始的控制符，返回最后消费的字节位置
+func (p *rtfParser) control(data []byte, i int) int {
+	if i+1 >= len(data) {
+		return i
+	}
+	next := data[i+1]
+
+	// 控制符号（反斜杠后跟单个非字母字符）
+	if !isASCIILetter(next) {
+		switch next {
+		case '\'':
+			if i+3 < len(data) {
+				if b, err := strconv.ParseUint(string(data[i+2:i+4]), 16, 8); err == nil {
+					if !p.consumeSkip() && !p.group.skip {
+						p.pending = append(p.pending, byte(b))
+					}
+				}
+			}
+			return i + 3
+		case '\\', '{', '}':
+			if !p.consumeSkip() {
+				p.write(string(next))
+			}
+		case '~':
+			p.write(" ")
+		case '_':
+			p.write("-")
+		case '*':
+			p.group.skip = true // 可忽略的目标组
+		case '\r', '\n':
+			p.write("\n")
+		}
+		return i + 1
+	}
+
+	// 控制字：字母序列 + 可选的带符号数字参数 + 可选的一个空格分隔符
+	j := i + 1
+	for j < len(data) && isASCIILetter(data[j]) {
+		j++
+	}
+	word := string(data[i+1 : j])
+	k := j
+	if k < len(data) && data[k] == '-' {
+		k++
+	}
+	for k < len(data) && data[k] >= '0' && data[k] <= '9' {
+		k++
+	}
+	// 无法解析的参数（如单独的 -、超出 int 范围的数字）视为没有参数
+	param, hasParam := 0, false
+	if k > j {
+		if n, err := strconv.Atoi(string(data[j:k])); err == nil {
+			param, hasParam = n, true
+		}
+	}
+	end := k - 1
+	if k < len(data) && data[k] == ' ' {
+		end = k
+	}
+
+	switch {
+	case rtfSkipDestinations[word]:
+		p.group.skip = true
+	case word == "ansicpg" && hasParam:
+		if enc := rtfCodePage(param); enc != nil {
+			p.encoding = enc
+		}
+	case word == "bin" && param > 0:
+		// \binN 之后是 N 字节原始二进制数据（可能包含 { } \），整体跳过；先比较再相加，避免 N 过大时溢出
+		if param >= len(data)-1-end {
+			return len(data) - 1
+		}
+		return end + param
+	case word == "uc" && hasParam:
+		p.group.uc = param
+	case word == "u" && hasParam:
+		if param < 0 {
+			param += 65536
+		}
+		p.write(string(rune(param)))
+		p.skipNext = p.group.uc
+	default:
+		if s, ok := rtfSymbols[word]; ok {
+			p.write(s)
+		}
+	}
+	return end
+}
+
+// write 输出文本（先解码待处理的 \'hh 字节）
+func (p *rtfParser) write(s string) {
+	p.flush()
+	if !p.group.skip {
+		p.out.WriteString(s)
+	}
+}
+
+// flush 按当前代码页解码累积的 \'hh 字节
+func (p *rtfParser) flush() {
+	if len(p.pending) == 0 {
+		return
+	}
+	if decoded, err := p.encoding.NewDecoder().Bytes(p.pending); err == nil {
+		p.out.Write(decoded)
+	}
+	p.pending = p.pending[:0]
+}
+
+// consumeSkip 跳过 \uN 之后的一个替代字符，返回是否已跳过
+func (p *rtfParser) consumeSkip() bool {
+	if p.skipNext > 0 {
+		p.skipNext--
+		return true
+	}
+	return false
+}
+
+// rtfCodePage \ansicpgN 对应的编码，不支持的代码页返回 nil
+func rtfCodePage(cp int) encoding.Encoding {
+	switch cp {
+	case 936:
+		return simplifiedchinese.GBK
+	case 950:
+		return traditionalchinese.Big5
+	case 932:
+		return japanese.ShiftJIS
+	case 949:
+		return korean.EUCKR
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	case 1253:
+		return charmap.Windows1253
+	case 1254:
+		return charmap.Windows1254
+	case 1255:
+		return charmap.Windows1255
+	case 1256:
+		return charmap.Windows1256
+	case 1257:
+		return charmap.Windows1257
+	case 1258:
+		return charmap.Windows1258
+	}
+	return nil
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+var rtfBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// normalizeRTFText 去除行尾空白并合并多余空行
+func normalizeRTFText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text = strings.Join(lines, "\n")
+	return strings.TrimSpace(rtfBlankLines.ReplaceAllString(text, "\n\n"))
+}
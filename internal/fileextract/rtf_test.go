@@ -0,0 +1,86 @@
+package fileextract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRTFExtractor(t *testing.T) {
+	tests := []struct {
+		name string
+		rtf  string
+		want string
+	}{
+		{
+			"paragraphs and skipped destinations",
+			`{\rtf1\ansi\deff0{\fonttbl{\f0\fswiss Helvetica;}}{\colortbl;\red255\green0\blue0;}` +
+				`{\*\generator Riched20;}{\info{\title Notes}}` + "\n" +
+				`\pard\f0\fs24 Meeting \b notes\b0\par` + "\n" +
+				`Second line with \{braces\} and a back\\slash.\par}`,
+			"Meeting notes\nSecond line with {braces} and a back\\slash.",
+		},
+		{
+			"hex escapes in windows-1252",
+			`{\rtf1\ansi\ansicpg1252 caf\'e9 \'93quoted\'94\par}`,
+			"café “quoted”",
+		},
+		{
+			"unicode escapes skip fallback characters",
+			`{\rtf1\ansi\ansicpg936\uc1 \u20250?\u35758?\'bc\'cd\'d2\'aa\par}`,
+			"会议纪要",
+		},
+		{
+			"nested groups restore state",
+			`{\rtf1{\*\ignored {nested} text}visible{\b bold}\tab end}`,
+			"visiblebold\tend",
+		},
+		{
+			"binary data is skipped",
+			`{\rtf1 before \bin7 }{\x\'9after\par}`,
+			"before after",
+		},
+		{
+			"binary length past the end of the data",
+			`{\rtf1 text\bin4096 }{\x}`,
+			"text",
+		},
+	}
+
+	extractor, ok := GetExtractor(".rtf")
+	if !ok {
+		t.Fatal("Expected .rtf extractor to be registered")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notes.rtf")
+			if err := os.WriteFile(path, []byte(tt.rtf), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := extractor.Extract(path)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRTFExtractor_NotRTF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fake.rtf")
+	if err := os.WriteFile(path, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&RTFExtractor{}).Extract(path); err == nil {
+		t.Error("Expected error for non-RTF content")
+	}
+}
+
+func TestParseRTF_HugeBinLength(t *testing.T) {
+	// 超出 int 范围的 \binN 曾因 end+N 溢出导致越界 panic
+	for _, input := range []string{`\bin9227000000000000000`, `\bin9223372036854775807 x`, `{\rtf1 a\bin-5 b}`} {
+		_ = parseRTF([]byte(input))
+	}
+}
@@ -23,3 +23,8 @@ func (e *TextExtractor) Extract(filePath string) (string, error) {
 	}
 	return string(data), nil
 }
+
+// ExtractLimited 最多读取 maxBytes 字节，不会把超大文件整个读入内存
+func (e *TextExtractor) ExtractLimited(filePath string, maxBytes int) (string, bool, error) {
+	return readFileLimited(filePath, maxBytes)
+}
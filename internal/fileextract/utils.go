@@ -14,6 +14,10 @@ func getInstallHint(tool string) string {
 		macCmd = "brew install poppler"
 		linuxCmd = "sudo apt install poppler-utils"
 		winCmd = "choco install poppler"
+	case "tesseract":
+		macCmd = "brew install tesseract"
+		linuxCmd = "sudo apt install tesseract-ocr"
+		winCmd = "choco install tesseract"
 	case "pandoc":
 		macCmd = "brew install pandoc"
 		linuxCmd = "sudo apt install pandoc"
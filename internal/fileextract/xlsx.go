@@ -19,6 +19,8 @@ func (e *XLSXExtractor) SupportedExtensions() []string {
 	return []string{".xlsx", ".xls"}
 }
 
+// Extract 按工作表输出文本：每个工作表以 "Sheet: <name>" 开头，随后每行一条、单元格以制表符分隔
+// 单元格按其数字格式输出（日期、百分比等与 Excel 中显示一致），空行跳过
 func (e *XLSXExtractor) Extract(filePath string) (string, error) {
 	f, err := excelize.OpenFile(filePath)
 	if err != nil {
@@ -27,25 +29,27 @@ func (e *XLSXExtractor) Extract(filePath string) (string, error) {
 	defer func() { _ = f.Close() }()
 
 	var buf bytes.Buffer
-	sheets := f.GetSheetList()
-
-	for _, sheet := range sheets {
+	for _, sheet := range f.GetSheetList() {
 		rows, err := f.GetRows(sheet)
 		if err != nil {
+			fmt.Printf("⚠️ [XLSX] Failed to read sheet %s: %v\n", sheet, err)
 			continue
 		}
 
-		// 写入工作表名称
-		if len(sheets) > 1 {
-			buf.WriteString(fmt.Sprintf("=== %s ===\n", sheet))
-		}
-
+		var lines []string
 		for _, row := range rows {
-			// 用制表符分隔单元格，保持表格结构
-			buf.WriteString(strings.Join(row, "\t"))
-			buf.WriteString("\n")
+			if line := formatSheetRow(row); line != "" {
+				lines = append(lines, line)
+			}
 		}
-		buf.WriteString("\n")
+		if len(lines) == 0 {
+			continue
+		}
+
+		// 工作表之间空一行，分块时保留工作表边界
+		buf.WriteString("Sheet: " + sheet + "\n")
+		buf.WriteString(strings.Join(lines, "\n"))
+		buf.WriteString("\n\n")
 	}
 
 	result := strings.TrimSpace(buf.String())
@@ -54,3 +58,22 @@ func (e *XLSXExtractor) Extract(filePath string) (string, error) {
 	}
 	return result, nil
 }
+
+// cellWhitespace 单元格内的换行和制表符会破坏行列结构，替换为空格
+var cellWhitespace = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// formatSheetRow 将一行单元格用制表符连接（去掉行尾空单元格），整行为空时返回空字符串
+func formatSheetRow(row []string) string {
+	cells := make([]string, len(row))
+	last := -1
+	for i, cell := range row {
+		cells[i] = strings.TrimSpace(cellWhitespace.Replace(cell))
+		if cells[i] != "" {
+			last = i
+		}
+	}
+	if last < 0 {
+		return ""
+	}
+	return strings.Join(cells[:last+1], "\t")
+}
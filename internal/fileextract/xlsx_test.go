@@ -0,0 +1,72 @@
+package fileextract
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestXLSXExtractor_SheetsAndRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.xlsx")
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", "预算"); err != nil {
+		t.Fatal(err)
+	}
+	cells := map[string]interface{}{
+		"A1": "项目", "B1": "金额", "C1": "日期",
+		"A2": "Rent", "B2": 1200.5, "C2": time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		// 第 3 行留空
+		"A4": "Multi\nline", "B4": 42,
+	}
+	for cell, value := range cells {
+		if err := f.SetCellValue("预算", cell, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dateStyle, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellStyle("预算", "C2", "C2", dateStyle); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewSheet("Notes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCellValue("Notes", "B2", "remember"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.NewSheet("Empty"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	extractor, ok := GetExtractor(".xlsx")
+	if !ok {
+		t.Fatal("Expected .xlsx extractor to be registered")
+	}
+	text, err := extractor.Extract(path)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	want := "Sheet: 预算\n" +
+		"项目\t金额\t日期\n" +
+		"Rent\t1200.5\t03-15-24\n" +
+		"Multi line\t42\n" +
+		"\n" +
+		"Sheet: Notes\n" +
+		"\tremember"
+	if text != want {
+		t.Errorf("got %q\nwant %q", text, want)
+	}
+	if strings.Contains(text, "Empty") {
+		t.Error("sheets without content should be skipped")
+	}
+}
@@ -0,0 +1,92 @@
+package markdown
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"notion-lite/internal/utils"
+)
+
+// DefaultFolderImportDepth 批量导入时的默认最大目录深度
+const DefaultFolderImportDepth = 10
+
+// FolderImportResult 批量导入结果
+type FolderImportResult struct {
+	Imported int      `json:"imported"` // 成功创建的文档数
+	Skipped  int      `json:"skipped"`  // 跳过的文件数（空文件）
+	Failed   int      `json:"failed"`   // 读取或转换失败的文件数
+	Errors   []string `json:"errors"`   // 失败文件的相对路径及原因
+}
+
+// imageRef Markdown 图片引用 ![alt](path "title")
+var imageRef = regexp.MustCompile(`!\[([^\]]*)\]\(<?([^)>\s]+)>?(\s+"[^"]*")?\)`)
+
+// CollectMarkdownFiles 递归收集文件夹中的 Markdown 文件
+func CollectMarkdownFiles(root string, maxDepth int) ([]string, error) {
+	return utils.WalkFiles(root, maxDepth, func(path string) bool {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".md" || ext == ".markdown"
+	})
+}
+
+// TagGroupForPath 由文件相对导入根目录的路径得到标签组名（目录层级以 / 连接）
+// 根目录下的文件返回空字符串
+func TagGroupForPath(root, path string) string {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// RewriteImages 将相对路径引用的本地图片交给 copyImage 复制，并把引用改写为其返回的 URL
+// 路径相对 baseDir 解析，解析后必须仍在导入根目录 root 之内且是图片文件；
+// 远程图片、data URI、应用内路径、越出 root 的路径、非图片文件以及不存在的文件保持原样
+func RewriteImages(content, baseDir, root string, copyImage func(src string) (string, error)) string {
+	return imageRef.ReplaceAllStringFunc(content, func(match string) string {
+		m := imageRef.FindStringSubmatch(match)
+		ref := m[2]
+		if !isRelativeImage(ref) {
+			return match
+		}
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		src := filepath.Join(baseDir, filepath.FromSlash(ref))
+		if !isWithinDir(root, src) || !strings.HasPrefix(utils.GetMimeTypeByExtension(src), "image/") {
+			return match
+		}
+		if info, err := os.Stat(src); err != nil || info.IsDir() {
+			return match
+		}
+		newURL, err := copyImage(src)
+		if err != nil {
+			return match
+		}
+		// 图片标题不保留：BlockNote 的图片块只识别 ![alt](url) 形式
+		return "![" + m[1] + "](" + newURL + ")"
+	})
+}
+
+// isWithinDir 判断 path（已 Clean）是否位于 dir 之内
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// isRelativeImage 是否为相对本地路径的图片引用
+func isRelativeImage(ref string) bool {
+	if strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") {
+		return false
+	}
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" {
+		return false // http(s)://、data:、file:// 等
+	}
+	return !filepath.IsAbs(ref)
+}
@@ -0,0 +1,106 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCollectMarkdownFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, rel := range []string{
+		"index.md",
+		"notes/work/plan.markdown",
+		"notes/readme.txt",
+		".obsidian/workspace.md",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := CollectMarkdownFiles(root, DefaultFolderImportDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []string
+	for _, f := range files {
+		groups = append(groups, TagGroupForPath(root, f))
+	}
+	sort.Strings(groups)
+	if want := []string{"", "notes/work"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected tag groups %v, got %v (files %v)", want, groups, files)
+	}
+}
+
+func TestRewriteImages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "my diagram.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 导入根目录之外的图片和根目录内的非图片文件
+	outside := filepath.Join(filepath.Dir(dir), filepath.Base(dir)+"-outside.png")
+	if err := os.WriteFile(outside, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Remove(outside) })
+	if err := os.WriteFile(filepath.Join(dir, "assets", "notes.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var copied []string
+	content := "![local](assets/my%20diagram.png \"Diagram\")\n" +
+		"![remote](https://example.com/a.png)\n" +
+		"![app](/images/b.png)\n" +
+		"![missing](assets/none.png)\n" +
+		"![escape](../" + filepath.Base(outside) + ")\n" +
+		"![escape2](assets/../../" + filepath.Base(outside) + ")\n" +
+		"![text](assets/notes.txt)\n"
+	got := RewriteImages(content, dir, dir, func(src string) (string, error) {
+		copied = append(copied, src)
+		return "/images/copied.png", nil
+	})
+
+	want := "![local](/images/copied.png)\n" +
+		"![remote](https://example.com/a.png)\n" +
+		"![app](/images/b.png)\n" +
+		"![missing](assets/none.png)\n" +
+		"![escape](../" + filepath.Base(outside) + ")\n" +
+		"![escape2](assets/../../" + filepath.Base(outside) + ")\n" +
+		"![text](assets/notes.txt)\n"
+	if got != want {
+		t.Errorf("RewriteImages =\n%s\nwant\n%s", got, want)
+	}
+	if len(copied) != 1 || copied[0] != filepath.Join(dir, "assets", "my diagram.png") {
+		t.Errorf("Expected only the local image to be copied, got %v", copied)
+	}
+}
+
+func TestIsWithinDir(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "notes")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(root, "a.png"), true},
+		{filepath.Join(root, "sub", "..", "a.png"), true},
+		{filepath.Join(root, "..", "a.png"), false},
+		{filepath.Join(root+"-other", "a.png"), false},
+		{root, true},
+	}
+	for _, tt := range tests {
+		if got := isWithinDir(root, filepath.Clean(tt.path)); got != tt.want {
+			t.Errorf("isWithinDir(%q, %q) = %v, want %v", root, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package markdown
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Frontmatter Markdown 文件头部 YAML frontmatter 中识别的字段
+type Frontmatter struct {
+	Title string
+	Tags  []string
+}
+
+// ParseFrontmatter 拆分 YAML frontmatter 与正文（如 Obsidian 笔记的元数据）
+// 只识别 title 和 tags/tag（支持 [a, b] 行内列表、"- a" 块列表和逗号分隔字符串），其余键忽略；
+// 没有 frontmatter 时原样返回内容
+func ParseFrontmatter(content string) (Frontmatter, string) {
+	var fm Frontmatter
+	text := strings.TrimPrefix(content, "\uFEFF")
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return fm, content
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimSpace(lines[i]); line == "---" || line == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return fm, content
+	}
+
+	key := "" // 当前块列表所属的键
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		// 块列表项（属于上一个值为空的键）
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if key == "tags" {
+				fm.Tags = appendTags(fm.Tags, strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+			}
+			continue
+		}
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			key = ""
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(name))
+		if key == "tag" {
+			key = "tags"
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "title":
+			fm.Title = unquote(value)
+		case "tags":
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+			for _, tag := range strings.Split(value, ",") {
+				fm.Tags = appendTags(fm.Tags, tag)
+			}
+		}
+	}
+
+	body := strings.Join(lines[end+1:], "\n")
+	return fm, strings.TrimLeft(body, "\n")
+}
+
+// appendTags 追加标签（去掉引号和 Obsidian 的 # 前缀，跳过空值和重复值）
+func appendTags(tags []string, raw string) []string {
+	tag := strings.TrimPrefix(unquote(strings.TrimSpace(raw)), "#")
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return tags
+	}
+	for _, existing := range tags {
+		if existing == tag {
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// unquote 去掉 YAML 标量两侧的引号（双引号支持转义，单引号中 ” 表示 '）
+func unquote(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+			return value[1 : len(value)-1]
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontmatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTitle string
+		wantTags  []string
+		wantBody  string
+	}{
+		{
+			"inline tags and title",
+			"---\ntitle: \"Project Plan\"\ntags: [work, '#planning', work]\naliases: [plan]\n---\n\n# Heading\n",
+			"Project Plan",
+			[]string{"work", "planning"},
+			"# Heading\n",
+		},
+		{
+			"block list tags",
+			"---\r\ntags:\r\n  - go\r\n  - \"db\"\r\ncreated: 2024-01-01\r\n---\r\nBody",
+			"",
+			[]string{"go", "db"},
+			"Body",
+		},
+		{
+			"comma separated tag key",
+			"---\ntag: a, b\n---\ntext",
+			"",
+			[]string{"a", "b"},
+			"text",
+		},
+		{
+			"no frontmatter",
+			"# Title\n\n---\n\ntext",
+			"",
+			nil,
+			"# Title\n\n---\n\ntext",
+		},
+		{
+			"unterminated frontmatter is kept as body",
+			"---\ntitle: x\ntext",
+			"",
+			nil,
+			"---\ntitle: x\ntext",
+		},
+	}
+	for _, tt := range tests {
+		fm, body := ParseFrontmatter(tt.content)
+		if fm.Title != tt.wantTitle {
+			t.Errorf("%s: title = %q, want %q", tt.name, fm.Title, tt.wantTitle)
+		}
+		if !reflect.DeepEqual(fm.Tags, tt.wantTags) {
+			t.Errorf("%s: tags = %v, want %v", tt.name, fm.Tags, tt.wantTags)
+		}
+		if body != tt.wantBody {
+			t.Errorf("%s: body = %q, want %q", tt.name, body, tt.wantBody)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package markdown
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+// pdfRenderTimeout 单次 PDF 渲染的超时时间
+const pdfRenderTimeout = 2 * time.Minute
+
+// imgSrcPattern 匹配 <img ... src="..."> 中的 src 属性值
+var imgSrcPattern = regexp.MustCompile(`(<img\b[^>]*?\bsrc=)("([^"]*)"|'([^']*)')`)
+
+// InlineImages 将 HTML 中的本地图片替换为 data URI，使导出的文件不依赖数据目录
+// resolve 返回图片 URL 对应的本地文件路径，返回空字符串表示保持原样
+func InlineImages(html string, resolve func(url string) string) string {
+	return imgSrcPattern.ReplaceAllStringFunc(html, func(match string) string {
+		groups := imgSrcPattern.FindStringSubmatch(match)
+		url := groups[3]
+		if url == "" {
+			url = groups[4]
+		}
+		path := resolve(url)
+		if path == "" {
+			return match
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to inline image %s: %v\n", url, err)
+			return match
+		}
+		dataURI := "data:" + utils.GetMimeTypeByExtension(path) + ";base64," + base64.StdEncoding.EncodeToString(data)
+		return groups[1] + `"` + dataURI + `"`
+	})
+}
+
+// PDFRenderer 可将 HTML 渲染为 PDF 的外部程序
+type PDFRenderer struct {
+	Name string // chrome 或 wkhtmltopdf
+	Path string
+}
+
+// chromeCandidates 按平台列出 Chrome/Chromium/Edge 的可执行文件
+func chromeCandidates() []string {
+	switch goruntime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+		}
+	case "windows":
+		var paths []string
+		for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)", "LocalAppData"} {
+			if dir := os.Getenv(env); dir != "" {
+				paths = append(paths,
+					filepath.Join(dir, "Google", "Chrome", "Application", "chrome.exe"),
+					filepath.Join(dir, "Microsoft", "Edge", "Application", "msedge.exe"),
+				)
+			}
+		}
+		return paths
+	default:
+		return []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "microsoft-edge"}
+	}
+}
+
+// FindPDFRenderer 查找可用的 PDF 渲染程序，优先使用无头 Chrome，其次 wkhtmltopdf
+// 都不可用时返回 nil
+func FindPDFRenderer() *PDFRenderer {
+	for _, candidate := range chromeCandidates() {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return &PDFRenderer{Name: "chrome", Path: path}
+		}
+	}
+	if path, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		return &PDFRenderer{Name: "wkhtmltopdf", Path: path}
+	}
+	return nil
+}
+
+// Render 将 htmlPath 渲染为 pdfPath
+func (r *PDFRenderer) Render(htmlPath, pdfPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pdfRenderTimeout)
+	defer cancel()
+
+	absHTML, err := filepath.Abs(htmlPath)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch r.Name {
+	case "chrome":
+		cmd = exec.CommandContext(ctx, r.Path,
+			"--headless",
+			"--disable-gpu",
+			"--no-pdf-header-footer",
+			"--print-to-pdf="+pdfPath,
+			"file://"+filepath.ToSlash(absHTML),
+		)
+	case "wkhtmltopdf":
+		cmd = exec.CommandContext(ctx, r.Path, "--quiet", "--enable-local-file-access", absHTML, pdfPath)
+	default:
+		return fmt.Errorf("unknown PDF renderer: %s", r.Name)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", r.Name, err, string(output))
+	}
+	if info, err := os.Stat(pdfPath); err != nil || info.Size() == 0 {
+		return fmt.Errorf("%s produced no output", r.Name)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInlineImages(t *testing.T) {
+	dir := t.TempDir()
+	pngPath := filepath.Join(dir, "a.png")
+	if err := os.WriteFile(pngPath, []byte("PNG"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resolve := func(url string) string {
+		switch url {
+		case "/images/a.png":
+			return pngPath
+		case "/images/missing.png":
+			return filepath.Join(dir, "missing.png")
+		}
+		return ""
+	}
+
+	html := `<p><img class="x" src="/images/a.png" alt="a"></p>` +
+		`<img src='/images/a.png'>` +
+		`<img src="https://example.com/b.png">` +
+		`<img src="/images/missing.png">`
+	got := InlineImages(html, resolve)
+
+	dataURI := `src="data:image/png;base64,UE5H"`
+	if strings.Count(got, dataURI) != 2 {
+		t.Errorf("expected both local images inlined, got %s", got)
+	}
+	if !strings.Contains(got, `<img class="x" src="data:`) || !strings.Contains(got, `alt="a"`) {
+		t.Errorf("other attributes should be preserved, got %s", got)
+	}
+	for _, keep := range []string{`src="https://example.com/b.png"`, `src="/images/missing.png"`} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("expected %s unchanged, got %s", keep, got)
+		}
+	}
+}
@@ -13,8 +13,10 @@ import (
 
 // ImportResult 导入结果
 type ImportResult struct {
-	Content  string `json:"content"`
-	FileName string `json:"fileName"`
+	Content  string   `json:"content"`
+	FileName string   `json:"fileName"`
+	Title    string   `json:"title,omitempty"` // frontmatter 中的 title
+	Tags     []string `json:"tags,omitempty"`  // frontmatter 中的 tags
 }
 
 // Service Markdown 导入导出服务
@@ -55,9 +57,13 @@ func (s *Service) Import() (*ImportResult, error) {
 	fileName := filepath.Base(filePath)
 	fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
 
+	// frontmatter 不写入正文，title/tags 交给调用方设置为文档标题和标签
+	frontmatter, body := ParseFrontmatter(string(data))
 	return &ImportResult{
-		Content:  string(data),
+		Content:  body,
 		FileName: fileName,
+		Title:    frontmatter.Title,
+		Tags:     frontmatter.Tags,
 	}, nil
 }
 
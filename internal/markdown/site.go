@@ -0,0 +1,264 @@
+package markdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"notion-lite/internal/blocknote"
+)
+
+// siteMarker 站点导出目录中的标记文件，再次导出到同一目录时据此判断可以安全覆盖
+const siteMarker = ".nook-site"
+
+// siteDocLinkPrefix 文档间链接的前缀（与 MCP 资源 URI 一致）
+const siteDocLinkPrefix = "nook://doc/"
+
+// siteStyle 导出页面的样式
+const siteStyle = `body{max-width:760px;margin:2rem auto;padding:0 1rem;font:16px/1.7 -apple-system,BlinkMacSystemFont,"Segoe UI",sans-serif;color:#1f2328}
+a{color:#0969da}nav{margin-bottom:1.5rem;font-size:.9rem}img{max-width:100%}figure{margin:1rem 0}figcaption{color:#656d76;font-size:.9rem}
+pre{background:#f6f8fa;padding:.75rem 1rem;overflow:auto;border-radius:6px}code{font-family:ui-monospace,SFMono-Regular,Menlo,monospace}
+blockquote{margin:0;padding-left:1rem;border-left:3px solid #d0d7de;color:#656d76}table{border-collapse:collapse}th,td{border:1px solid #d0d7de;padding:.3rem .6rem}
+.children{margin-left:1.5rem}.tags{color:#656d76;font-size:.85rem}ul.index{padding-left:1.2rem}`
+
+// SiteDocument 待导出为网页的文档
+type SiteDocument struct {
+	ID      string
+	Title   string
+	Tags    []string
+	Content []byte // BlockNote JSON
+}
+
+// SiteExportResult 站点导出结果
+type SiteExportResult struct {
+	Path      string   `json:"path"`      // 导出目录
+	Documents int      `json:"documents"` // 导出的页面数
+	Assets    int      `json:"assets"`    // 复制的图片/附件数
+	Failed    []string `json:"failed"`    // 无法转换的文档标题
+}
+
+// WriteSite 将文档导出为可直接托管或打包的静态网站：每个文档一个 HTML 页面，外加 index.html 目录页
+// 指向已导出文档的 nook://doc/ 链接改写为页面间的相对链接，指向未导出文档的链接只保留文本；
+// 引用的 /images/ 和 /files/ 资源复制到 assets/ 下。
+// 先在临时目录生成，完成后再替换 outputDir；outputDir 已存在时必须为空目录或之前导出的站点
+func WriteSite(outputDir string, docs []SiteDocument, assetPath func(url string) string) (*SiteExportResult, error) {
+	outputDir = filepath.Clean(outputDir)
+	if err := validateSiteDir(outputDir); err != nil {
+		return nil, err
+	}
+
+	tmpDir := fmt.Sprintf("%s.tmp-%d", outputDir, time.Now().UnixNano())
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	result, err := writeSiteFiles(tmpDir, docs, assetPath)
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+
+	if err := replaceDir(tmpDir, outputDir); err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	result.Path = outputDir
+	return result, nil
+}
+
+// validateSiteDir 检查导出目录：必须是绝对路径，父目录存在；已存在时必须是空目录或之前导出的站点
+func validateSiteDir(dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("output directory must be an absolute path: %s", dir)
+	}
+	if info, err := os.Stat(filepath.Dir(dir)); err != nil || !info.IsDir() {
+		return fmt.Errorf("parent directory does not exist: %s", filepath.Dir(dir))
+	}
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check output directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output path is not a directory: %s", dir)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, siteMarker)); err != nil {
+		return fmt.Errorf("output directory is not empty and was not created by a site export: %s", dir)
+	}
+	return nil
+}
+
+// writeSiteFiles 在 dir 中生成全部页面、资源和目录页
+func writeSiteFiles(dir string, docs []SiteDocument, assetPath func(url string) string) (*SiteExportResult, error) {
+	result := &SiteExportResult{Failed: []string{}}
+
+	// 先分配页面文件名，文档间链接才能指向尚未生成的页面
+	used := map[string]bool{"index.html": true}
+	pages := make(map[string]string, len(docs)) // 文档 ID -> 页面文件名
+	for _, doc := range docs {
+		pages[doc.ID] = uniqueVaultName(used, "", vaultFileName(doc.Title), ".html")
+	}
+	rewriteLink := func(href string) string {
+		target := href
+		if strings.HasPrefix(target, siteDocLinkPrefix) {
+			target = strings.TrimPrefix(target, siteDocLinkPrefix)
+		} else if _, ok := pages[target]; !ok {
+			return href
+		}
+		fragment := ""
+		if i := strings.Index(target, "#"); i >= 0 {
+			target, fragment = target[:i], target[i:]
+		}
+		page, ok := pages[target]
+		if !ok {
+			return "" // 未导出的文档只保留链接文本
+		}
+		return url.PathEscape(page) + fragment
+	}
+
+	assets := make(map[string]string) // 站点内路径 -> 本地路径
+	var index []SiteDocument
+	for _, doc := range docs {
+		var blocks []blocknote.Block
+		if len(doc.Content) > 0 {
+			if err := json.Unmarshal(doc.Content, &blocks); err != nil {
+				result.Failed = append(result.Failed, doc.Title)
+				continue
+			}
+		}
+		rewriteVaultAssets(blocks, func(u string) (string, bool) {
+			local := assetPath(u)
+			if local == "" {
+				return "", false
+			}
+			if info, err := os.Stat(local); err != nil || info.IsDir() {
+				return "", false
+			}
+			entry := path.Join(vaultAssetsDir, strings.TrimPrefix(u, "/"))
+			assets[entry] = local
+			return entry, true
+		})
+		data, err := json.Marshal(blocks)
+		if err != nil {
+			result.Failed = append(result.Failed, doc.Title)
+			continue
+		}
+		body, err := blocknote.ToHTML(data, rewriteLink)
+		if err != nil {
+			result.Failed = append(result.Failed, doc.Title)
+			continue
+		}
+		page := sitePage(doc.Title, `<nav><a href="index.html">← Index</a></nav>`+"\n"+
+			`<h1 class="title">`+html.EscapeString(doc.Title)+"</h1>\n"+siteTags(doc.Tags)+body)
+		if err := os.WriteFile(filepath.Join(dir, pages[doc.ID]), []byte(page), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", pages[doc.ID], err)
+		}
+		index = append(index, doc)
+		result.Documents++
+	}
+
+	entries := make([]string, 0, len(assets))
+	for entry := range assets {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	for _, entry := range entries {
+		if err := copyFile(assets[entry], filepath.Join(dir, filepath.FromSlash(entry))); err != nil {
+			fmt.Printf("⚠️ Failed to copy %s: %v\n", assets[entry], err)
+			continue
+		}
+		result.Assets++
+	}
+
+	var list strings.Builder
+	list.WriteString("<h1>Index</h1>\n<ul class=\"index\">\n")
+	for _, doc := range index {
+		list.WriteString(`<li><a href="` + url.PathEscape(pages[doc.ID]) + `">` + html.EscapeString(doc.Title) + "</a>" + siteTags(doc.Tags) + "</li>\n")
+	}
+	list.WriteString("</ul>\n")
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(sitePage("Index", list.String())), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index.html: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, siteMarker), []byte(time.Now().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write site marker: %w", err)
+	}
+	return result, nil
+}
+
+// sitePage 生成完整的 HTML 页面
+func sitePage(title, body string) string {
+	return "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n" +
+		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n" +
+		"<title>" + html.EscapeString(title) + "</title>\n<style>" + siteStyle + "</style>\n</head>\n<body>\n" +
+		body + "</body>\n</html>\n"
+}
+
+// siteTags 渲染标签行（没有标签时为空）
+func siteTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	escaped := make([]string, len(tags))
+	for i, tag := range tags {
+		escaped[i] = "#" + html.EscapeString(tag)
+	}
+	return `<p class="tags">` + strings.Join(escaped, " ") + "</p>\n"
+}
+
+// replaceDir 用 src 替换 dst：dst 已存在时先移开，替换成功后再删除旧目录
+func replaceDir(src, dst string) error {
+	if _, err := os.Stat(dst); os.IsNotExist(err) {
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("failed to move export into place: %w", err)
+		}
+		return nil
+	}
+	old := fmt.Sprintf("%s.old-%d", dst, time.Now().UnixNano())
+	if err := os.Rename(dst, old); err != nil {
+		return fmt.Errorf("failed to replace previous export: %w", err)
+	}
+	if err := os.Rename(src, dst); err != nil {
+		_ = os.Rename(old, dst) // 恢复旧导出
+		return fmt.Errorf("failed to move export into place: %w", err)
+	}
+	_ = os.RemoveAll(old)
+	return nil
+}
+
+// copyFile 复制本地文件（自动创建目标目录）
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
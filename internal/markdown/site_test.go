@@ -0,0 +1,89 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteSite(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "diagram.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assetPath := func(url string) string { return filepath.Join(dataDir, filepath.Base(url)) }
+	docs := []SiteDocument{
+		{
+			ID:    "doc-a",
+			Title: "Plan A",
+			Tags:  []string{"work"},
+			Content: []byte(`[{"id":"p1","type":"paragraph","content":[` +
+				`{"type":"link","href":"nook://doc/doc-b#b1","content":[{"type":"text","text":"see B"}]},` +
+				`{"type":"link","href":"nook://doc/missing","content":[{"type":"text","text":"gone"}]}],"children":[]},` +
+				`{"id":"i1","type":"image","props":{"url":"/images/diagram.png"},"children":[]}]`),
+		},
+		{ID: "doc-b", Title: "Plan B", Content: []byte(`[{"id":"b1","type":"paragraph","content":[{"type":"text","text":"<b>"}],"children":[]}]`)},
+		{ID: "doc-c", Title: "Broken", Content: []byte(`{not json`)},
+	}
+
+	out := filepath.Join(t.TempDir(), "site")
+	result, err := WriteSite(out, docs, assetPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Documents != 2 || result.Assets != 1 || len(result.Failed) != 1 || result.Path != out {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	a := read("Plan A.html")
+	for _, want := range []string{
+		`<a href="Plan%20B.html#b1">see B</a>`,
+		`<img src="assets/images/diagram.png"`,
+		`<a href="index.html">`,
+	} {
+		if !strings.Contains(a, want) {
+			t.Errorf("Plan A.html missing %q", want)
+		}
+	}
+	if strings.Contains(a, "nook://") {
+		t.Error("Links to documents outside the export should be dropped")
+	}
+	if b := read("Plan B.html"); !strings.Contains(b, `id="b1"`) || !strings.Contains(b, "&lt;b&gt;") {
+		t.Errorf("Plan B.html missing anchor or escaping:\n%s", b)
+	}
+	if index := read("index.html"); !strings.Contains(index, "Plan%20A.html") || strings.Contains(index, "Broken") {
+		t.Errorf("Unexpected index:\n%s", index)
+	}
+	if read("assets/images/diagram.png") != "png" {
+		t.Error("Image was not copied")
+	}
+
+	// 覆盖之前的导出：旧页面被替换
+	if _, err := WriteSite(out, docs[1:2], assetPath); err != nil {
+		t.Fatalf("Re-export failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "Plan A.html")); !os.IsNotExist(err) {
+		t.Error("Stale page from the previous export was kept")
+	}
+
+	// 拒绝覆盖非导出生成的非空目录和相对路径
+	foreign := t.TempDir()
+	if err := os.WriteFile(filepath.Join(foreign, "notes.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteSite(foreign, docs, assetPath); err == nil {
+		t.Error("Expected an error for a non-empty foreign directory")
+	}
+	if _, err := WriteSite("relative/site", docs, assetPath); err == nil {
+		t.Error("Expected an error for a relative path")
+	}
+}
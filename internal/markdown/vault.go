@@ -0,0 +1,211 @@
+package markdown
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"notion-lite/internal/blocknote"
+)
+
+// vaultAssetsDir 导出包中存放图片和归档文件的目录
+const vaultAssetsDir = "assets"
+
+// VaultDocument 待导出的文档
+type VaultDocument struct {
+	Title   string
+	Tags    []string
+	Content []byte // BlockNote JSON
+}
+
+// VaultExportResult 导出结果
+type VaultExportResult struct {
+	Path      string   `json:"path"`      // 导出的 zip 路径
+	Documents int      `json:"documents"` // 导出的文档数
+	Assets    int      `json:"assets"`    // 打包的图片/附件数
+	Failed    []string `json:"failed"`    // 无法转换的文档标题
+}
+
+// WriteVault 将文档打包为 Markdown + 资源的 zip
+// 每个文档写为 <标题>.md，标题和标签保存在 frontmatter 中；属于标签组（groups，按顺序取第一个匹配）
+// 的文档放在以组名命名的子目录下，与文件夹批量导入互为逆操作。
+// 文档引用的 /images/ 和 /files/ 资源复制到 assets/ 下并改写为相对路径，assetPath 返回资源的本地路径
+func WriteVault(w io.Writer, docs []VaultDocument, groups []string, assetPath func(url string) string) (*VaultExportResult, error) {
+	zw := zip.NewWriter(w)
+	result := &VaultExportResult{Failed: []string{}}
+	assets := make(map[string]string) // zip 内路径 -> 本地路径
+	usedNames := make(map[string]bool)
+
+	for _, doc := range docs {
+		folder := vaultFolder(doc.Tags, groups)
+		prefix := "" // 从文档所在目录回到导出包根目录
+		if folder != "" {
+			prefix = strings.Repeat("../", strings.Count(folder, "/")+1)
+		}
+
+		var blocks []blocknote.Block
+		if len(doc.Content) > 0 {
+			if err := json.Unmarshal(doc.Content, &blocks); err != nil {
+				result.Failed = append(result.Failed, doc.Title)
+				continue
+			}
+		}
+		rewriteVaultAssets(blocks, func(url string) (string, bool) {
+			local := assetPath(url)
+			if local == "" {
+				return "", false
+			}
+			if info, err := os.Stat(local); err != nil || info.IsDir() {
+				return "", false
+			}
+			entry := path.Join(vaultAssetsDir, strings.TrimPrefix(url, "/"))
+			assets[entry] = local
+			return prefix + entry, true
+		})
+		data, err := json.Marshal(blocks)
+		if err != nil {
+			result.Failed = append(result.Failed, doc.Title)
+			continue
+		}
+		body, err := blocknote.ToMarkdown(data)
+		if err != nil {
+			result.Failed = append(result.Failed, doc.Title)
+			continue
+		}
+
+		name := uniqueVaultName(usedNames, folder, vaultFileName(doc.Title), ".md")
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := io.WriteString(f, vaultFrontmatter(doc.Title, doc.Tags)+body+"\n"); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		result.Documents++
+	}
+
+	entries := make([]string, 0, len(assets))
+	for entry := range assets {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	for _, entry := range entries {
+		if err := copyToZip(zw, entry, assets[entry]); err != nil {
+			fmt.Printf("⚠️ Failed to bundle %s: %v\n", assets[entry], err)
+			continue
+		}
+		result.Assets++
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize zip: %w", err)
+	}
+	return result, nil
+}
+
+// vaultFolder 文档所属的标签组目录（不属于任何组时为空）
+func vaultFolder(tags, groups []string) string {
+	for _, group := range groups {
+		for _, tag := range tags {
+			if tag == group {
+				return sanitizeVaultPath(group)
+			}
+		}
+	}
+	return ""
+}
+
+// rewriteVaultAssets 将图片 url 和已归档文件的路径改写为导出包内的相对路径
+func rewriteVaultAssets(blocks []blocknote.Block, register func(url string) (string, bool)) {
+	for i := range blocks {
+		props := blocks[i].Props
+		switch blocks[i].Type {
+		case "image":
+			if url, _ := props["url"].(string); strings.HasPrefix(url, "/images/") {
+				if rel, ok := register(url); ok {
+					props["url"] = rel
+				}
+			}
+		case "file":
+			if url, _ := props["archivedPath"].(string); strings.HasPrefix(url, "/files/") {
+				if rel, ok := register(url); ok {
+					props["originalPath"] = rel // Markdown 链接优先使用 originalPath
+					props["archivedPath"] = rel
+				}
+			}
+		}
+		rewriteVaultAssets(blocks[i].Children, register)
+	}
+}
+
+// vaultFrontmatter 生成保存标题和标签的 frontmatter
+func vaultFrontmatter(title string, tags []string) string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("title: " + strconv.Quote(title) + "\n")
+	if len(tags) > 0 {
+		sb.WriteString("tags:\n")
+		for _, tag := range tags {
+			sb.WriteString("  - " + strconv.Quote(tag) + "\n")
+		}
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// vaultFileName 由标题生成文件名（替换路径中的非法字符）
+func vaultFileName(title string) string {
+	name := strings.TrimSpace(strings.NewReplacer(
+		"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_",
+	).Replace(title))
+	if name == "" || strings.Trim(name, ".") == "" {
+		name = "Untitled"
+	}
+	return name
+}
+
+// sanitizeVaultPath 清理标签组路径的每一级目录名
+func sanitizeVaultPath(group string) string {
+	parts := strings.Split(group, "/")
+	for i, part := range parts {
+		parts[i] = vaultFileName(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// uniqueVaultName 同一目录下重名时追加序号（不区分大小写，兼容大小写不敏感的文件系统）
+func uniqueVaultName(used map[string]bool, folder, name, ext string) string {
+	for n := 1; ; n++ {
+		candidate := name + ext
+		if n > 1 {
+			candidate = fmt.Sprintf("%s (%d)%s", name, n, ext)
+		}
+		full := path.Join(folder, candidate)
+		if !used[strings.ToLower(full)] {
+			used[strings.ToLower(full)] = true
+			return full
+		}
+	}
+}
+
+// copyToZip 将本地文件写入 zip
+func copyToZip(zw *zip.Writer, entry, local string) error {
+	in, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := zw.Create(entry)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,98 @@
+package markdown
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteVault_RoundTripsWithFolderImport(t *testing.T) {
+	dataDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dataDir, "diagram.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	docs := []VaultDocument{
+		{
+			Title:   `Plan: "Q3"`,
+			Tags:    []string{"work/projects", "planning"},
+			Content: []byte(`[{"id":"h","type":"heading","props":{"level":1},"content":[{"type":"text","text":"Plan"}],"children":[]},{"id":"i","type":"image","props":{"url":"/images/diagram.png","caption":"Diagram"},"children":[]}]`),
+		},
+		{Title: "Inbox", Content: []byte(`[{"id":"p","type":"paragraph","content":[{"type":"text","text":"Loose note"}],"children":[]}]`)},
+		{Title: "Inbox", Content: []byte(`[]`)},
+		{Title: "Broken", Content: []byte(`{not json`)},
+	}
+
+	var buf bytes.Buffer
+	result, err := WriteVault(&buf, docs, []string{"work/projects"}, func(url string) string {
+		return filepath.Join(dataDir, filepath.Base(url))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Documents != 3 || result.Assets != 1 || !reflect.DeepEqual(result.Failed, []string{"Broken"}) {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// 解压后按文件夹导入的方式读取
+	root := t.TempDir()
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		target := filepath.Join(root, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			t.Fatal(err)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := CollectMarkdownFiles(root, DefaultFolderImportDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := make(map[string]string) // 相对路径 -> 标签组
+	for _, file := range files {
+		rel, _ := filepath.Rel(root, file)
+		imported[filepath.ToSlash(rel)] = TagGroupForPath(root, file)
+	}
+	want := map[string]string{
+		"work/projects/Plan_ _Q3_.md": "work/projects",
+		"Inbox.md":                    "",
+		"Inbox (2).md":                "",
+	}
+	if !reflect.DeepEqual(imported, want) {
+		t.Fatalf("Unexpected vault layout: %v", imported)
+	}
+
+	planPath := filepath.Join(root, "work", "projects", "Plan_ _Q3_.md")
+	data, err := os.ReadFile(planPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frontmatter, body := ParseFrontmatter(string(data))
+	if frontmatter.Title != `Plan: "Q3"` || !reflect.DeepEqual(frontmatter.Tags, []string{"work/projects", "planning"}) {
+		t.Errorf("Unexpected frontmatter: %+v", frontmatter)
+	}
+	var copied []string
+	RewriteImages(body, filepath.Dir(planPath), root, func(src string) (string, error) {
+		copied = append(copied, src)
+		return "/images/x.png", nil
+	})
+	if len(copied) != 1 || !strings.HasSuffix(filepath.ToSlash(copied[0]), "assets/images/diagram.png") {
+		t.Errorf("Expected bundled image to resolve relative to the document, got %v (body %q)", copied, body)
+	}
+}
@@ -2,6 +2,9 @@ package opengraph
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -11,6 +14,12 @@ import (
 	og "github.com/otiai10/opengraph/v2"
 )
 
+// DefaultFetchTimeout is the default timeout for fetching a web page
+const DefaultFetchTimeout = 10 * time.Second
+
+// ErrTimeout is returned when fetching a URL exceeds the configured timeout
+var ErrTimeout = errors.New("request timed out")
+
 // LinkMetadata represents the metadata extracted from a URL
 type LinkMetadata struct {
 	URL         string `json:"url"`
@@ -81,11 +90,21 @@ type LinkContent struct {
 	Byline      string `json:"byline"`
 }
 
-// FetchContent 使用 go-readability 提取网页正文内容
+// FetchContent 使用 go-readability 提取网页正文内容（默认超时）
 func FetchContent(targetURL string) (*LinkContent, error) {
+	return FetchContentWithTimeout(targetURL, DefaultFetchTimeout)
+}
+
+// FetchContentWithTimeout 使用指定超时提取网页正文内容
+// 超时时返回包装了 ErrTimeout 的错误
+func FetchContentWithTimeout(targetURL string, timeout time.Duration) (*LinkContent, error) {
+	if timeout <= 0 {
+		timeout = DefaultFetchTimeout
+	}
+
 	// 创建带超时的 HTTP 客户端
 	client := &http.Client{
-		Timeout: 30 * time.Second,
+		Timeout: timeout,
 	}
 
 	// 创建请求
@@ -100,7 +119,7 @@ func FetchContent(targetURL string) (*LinkContent, error) {
 	// 发送请求
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, wrapTimeout(err, timeout)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -113,7 +132,7 @@ func FetchContent(targetURL string) (*LinkContent, error) {
 	// 使用 readability 提取正文
 	article, err := readability.FromReader(resp.Body, parsedURL)
 	if err != nil {
-		return nil, err
+		return nil, wrapTimeout(err, timeout)
 	}
 
 	return &LinkContent{
@@ -125,3 +144,20 @@ func FetchContent(targetURL string) (*LinkContent, error) {
 		Byline:      article.Byline,
 	}, nil
 }
+
+// IsTimeout reports whether err was caused by a network or context timeout
+func IsTimeout(err error) bool {
+	if errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// wrapTimeout converts timeout errors into ErrTimeout with the effective duration
+func wrapTimeout(err error, timeout time.Duration) error {
+	if IsTimeout(err) {
+		return fmt.Errorf("%w after %s", ErrTimeout, timeout)
+	}
+	return err
+}
@@ -0,0 +1,302 @@
+package opengraph
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// minReadableChars 正文候选的最小字符数，低于此值时回退到整页文本
+const minReadableChars = 200
+
+// maxReadableBytes 读取网页的最大字节数
+const maxReadableBytes = 5 << 20
+
+// boilerplateTags 直接移除的非正文元素
+var boilerplateTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"nav": true, "footer": true, "aside": true, "header": true,
+	"form": true, "iframe": true, "svg": true, "button": true,
+}
+
+// boilerplatePattern 匹配 class/id 中常见的非正文区域
+var boilerplatePattern = regexp.MustCompile(`(?i)(^|[\s_-])(nav|navbar|menu|footer|sidebar|comment|comments|advert|ads?|promo|sponsor|share|social|related|recommend|cookie|banner|popup|subscribe|newsletter|breadcrumb)([\s_-]|$)`)
+
+// contentPattern 匹配 class/id 中常见的正文区域（优先于 boilerplatePattern）
+var contentPattern = regexp.MustCompile(`(?i)(article|content|main|post|entry|story|body|text)`)
+
+// blockTags 提取文本时需要换行的块级元素
+var blockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"section": true, "article": true, "main": true, "pre": true, "blockquote": true,
+	"ul": true, "ol": true, "table": true, "figure": true, "figcaption": true,
+}
+
+// paragraphTags 参与打分的段落级元素
+var paragraphTags = map[string]bool{
+	"p": true, "pre": true, "blockquote": true, "li": true, "td": true,
+}
+
+// ReadableContent 阅读模式提取结果
+type ReadableContent struct {
+	Title    string `json:"title"`
+	SiteName string `json:"siteName"`
+	Text     string `json:"text"`     // 正文文本（未找到足够正文时等于 Raw）
+	Raw      string `json:"raw"`      // 去除样板元素后的整页文本
+	Readable bool   `json:"readable"` // 是否成功识别出正文
+}
+
+// FetchReadableContent 抓取网页并以阅读模式提取正文（默认超时）
+func FetchReadableContent(targetURL string) (*LinkContent, error) {
+	return FetchReadableContentWithTimeout(targetURL, DefaultFetchTimeout)
+}
+
+// FetchReadableContentWithTimeout 使用指定超时抓取网页并以阅读模式提取正文
+// 超时时返回包装了 ErrTimeout 的错误
+func FetchReadableContentWithTimeout(targetURL string, timeout time.Duration) (*LinkContent, error) {
+	if timeout <= 0 {
+		timeout = DefaultFetchTimeout
+	}
+
+	client := &http.Client{Timeout: timeout}
+	req, err := http.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, wrapTimeout(err, timeout)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	readable, err := ExtractReadable(io.LimitReader(resp.Body, maxReadableBytes))
+	if err != nil {
+		return nil, wrapTimeout(err, timeout)
+	}
+
+	return &LinkContent{
+		URL:         targetURL,
+		Title:       readable.Title,
+		TextContent: readable.Text,
+		Excerpt:     excerpt(readable.Text, 200),
+		SiteName:    readable.SiteName,
+	}, nil
+}
+
+// ExtractReadable 从 HTML 中提取正文
+// 移除导航/页脚/脚本等样板元素后，按文本密度和链接密度为容器节点打分，取得分最高者
+func ExtractReadable(r io.Reader) (*ReadableContent, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReadableContent{
+		Title:    pageTitle(doc),
+		SiteName: metaContent(doc, "og:site_name"),
+	}
+
+	removeBoilerplate(doc)
+
+	body := findElement(doc, "body")
+	if body == nil {
+		body = doc
+	}
+	result.Raw = nodeText(body)
+	result.Text = result.Raw
+
+	if best := bestCandidate(body); best != nil {
+		text := nodeText(best)
+		if utf8.RuneCountInString(text) >= minReadableChars {
+			result.Text = text
+			result.Readable = true
+		}
+	}
+	return result, nil
+}
+
+// removeBoilerplate 移除样板元素（按标签名和 class/id）
+func removeBoilerplate(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && isBoilerplate(c)) {
+			n.RemoveChild(c)
+		} else {
+			removeBoilerplate(c)
+		}
+		c = next
+	}
+}
+
+// isBoilerplate 判断元素是否为非正文区域
+func isBoilerplate(n *html.Node) bool {
+	if boilerplateTags[n.Data] {
+		return true
+	}
+	if n.Data == "body" || n.Data == "article" || n.Data == "main" {
+		return false
+	}
+	hint := attr(n, "class") + " " + attr(n, "id") + " " + attr(n, "role")
+	if strings.TrimSpace(hint) == "" || contentPattern.MatchString(hint) {
+		return false
+	}
+	return boilerplatePattern.MatchString(hint)
+}
+
+// bestCandidate 为段落的父级和祖父级容器累加得分，返回得分最高的容器
+func bestCandidate(root *html.Node) *html.Node {
+	scores := make(map[*html.Node]float64)
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && paragraphTags[n.Data] {
+			text := nodeText(n)
+			length := utf8.RuneCountInString(text)
+			if length >= 25 {
+				// 基础分 + 标点数（中英文逗号）+ 长度奖励（每 100 字 1 分，最多 3 分）
+				score := 1.0 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "。"))
+				score += float64(min(length/100, 3))
+				if parent := n.Parent; parent != nil {
+					scores[parent] += score
+					if grand := parent.Parent; grand != nil {
+						scores[grand] += score / 2
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, score := range scores {
+		// 链接密度高的容器（导航、目录）降权
+		score *= 1 - linkDensity(n)
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	return best
+}
+
+// linkDensity 链接文本占节点文本的比例
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(nodeText(n))
+	if total == 0 {
+		return 0
+	}
+	linkLen := 0
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.ElementNode && c.Data == "a" {
+			linkLen += utf8.RuneCountInString(nodeText(c))
+			return
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return float64(linkLen) / float64(total)
+}
+
+// nodeText 提取节点文本，块级元素之间换行，行内空白折叠
+func nodeText(n *html.Node) string {
+	var buf bytes.Buffer
+	var walk func(c *html.Node)
+	walk = func(c *html.Node) {
+		if c.Type == html.TextNode {
+			buf.WriteString(c.Data)
+			return
+		}
+		if c.Type == html.ElementNode && blockTags[c.Data] {
+			buf.WriteString("\n")
+		}
+		for child := c.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if c.Type == html.ElementNode && blockTags[c.Data] {
+			buf.WriteString("\n")
+		}
+	}
+	walk(n)
+
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pageTitle 获取页面标题（优先 og:title）
+func pageTitle(doc *html.Node) string {
+	if title := metaContent(doc, "og:title"); title != "" {
+		return title
+	}
+	if title := findElement(doc, "title"); title != nil {
+		return strings.TrimSpace(nodeText(title))
+	}
+	return ""
+}
+
+// metaContent 获取 <meta property|name="key" content="..."> 的值
+func metaContent(n *html.Node, key string) string {
+	if n.Type == html.ElementNode && n.Data == "meta" {
+		if attr(n, "property") == key || attr(n, "name") == key {
+			return strings.TrimSpace(attr(n, "content"))
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if value := metaContent(c, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// findElement 深度优先查找第一个指定标签的元素
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr 获取元素属性值
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// excerpt 截取文本开头作为摘要
+func excerpt(text string, maxRunes int) string {
+	text = strings.ReplaceAll(text, "\n", " ")
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "..."
+}
@@ -0,0 +1,85 @@
+package opengraph
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExtractReadable_RemovesBoilerplate(t *testing.T) {
+	// 使用保存的新闻页面验证导航、广告、侧栏、页脚被移除
+	f, err := os.Open("testdata/news_article.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	content, err := ExtractReadable(f)
+	if err != nil {
+		t.Fatalf("ExtractReadable failed: %v", err)
+	}
+
+	if !content.Readable {
+		t.Fatal("Expected main article to be detected")
+	}
+	if content.Title != "City Council Approves New Transit Plan" {
+		t.Errorf("Expected og:title, got %q", content.Title)
+	}
+	if content.SiteName != "Daily Example" {
+		t.Errorf("Expected og:site_name, got %q", content.SiteName)
+	}
+
+	for _, want := range []string{"light rail lines", "Critics, however", "next spring"} {
+		if !strings.Contains(content.Text, want) {
+			t.Errorf("Expected article text to contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Politics", "ADVERTISEMENT", "Most Read", "Related:", "Copyright", "analytics"} {
+		if strings.Contains(content.Text, unwanted) {
+			t.Errorf("Expected boilerplate %q to be removed, got:\n%s", unwanted, content.Text)
+		}
+	}
+}
+
+func TestExtractReadable_FallsBackToRaw(t *testing.T) {
+	// 没有足够正文时回退到整页文本
+	page := `<html><head><title>Short</title></head><body><div>Just a short note.</div></body></html>`
+
+	content, err := ExtractReadable(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("ExtractReadable failed: %v", err)
+	}
+	if content.Readable {
+		t.Error("Expected short page not to be marked readable")
+	}
+	if content.Text != "Just a short note." || content.Text != content.Raw {
+		t.Errorf("Expected raw fallback text, got %q (raw %q)", content.Text, content.Raw)
+	}
+	if content.Title != "Short" {
+		t.Errorf("Expected <title> fallback, got %q", content.Title)
+	}
+}
+
+func TestFetchReadableContent(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/news_article.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(fixture)
+	}))
+	defer server.Close()
+
+	content, err := FetchReadableContent(server.URL)
+	if err != nil {
+		t.Fatalf("FetchReadableContent failed: %v", err)
+	}
+	if !strings.Contains(content.TextContent, "light rail lines") || strings.Contains(content.TextContent, "Most Read") {
+		t.Errorf("Unexpected text content:\n%s", content.TextContent)
+	}
+	if content.Excerpt == "" {
+		t.Error("Expected excerpt to be set")
+	}
+}
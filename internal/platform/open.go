@@ -0,0 +1,117 @@
+package platform
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+)
+
+// 打开文件、在文件管理器中显示、打开网址的跨平台实现（macOS / Windows / Linux）
+// 所有命令都以 Start 启动，不等待外部程序退出
+
+// allowedURLSchemes OpenURL 允许交给系统处理的协议，避免把任意协议（如 file:、javascript:）交给系统打开
+var allowedURLSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// OpenPath 使用系统默认应用打开文件或目录
+func OpenPath(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return start(openCommand(path))
+}
+
+// RevealPath 在系统文件管理器中显示文件（macOS/Windows 选中该文件，Linux 打开所在目录）
+// path 为目录时直接打开该目录
+func RevealPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to reveal %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return start(openCommand(path))
+	}
+	return start(revealCommand(path))
+}
+
+// OpenURL 使用系统默认浏览器（或邮件客户端）打开网址，仅支持 http、https 和 mailto
+func OpenURL(rawURL string) error {
+	rawURL = strings.TrimSpace(rawURL)
+	if err := ValidateURL(rawURL); err != nil {
+		return err
+	}
+	return start(openURLCommand(rawURL))
+}
+
+// ValidateURL 检查网址能否交给 OpenURL 打开
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if !allowedURLSchemes[strings.ToLower(u.Scheme)] {
+		return fmt.Errorf("unsupported URL scheme: %q", u.Scheme)
+	}
+	if !strings.EqualFold(u.Scheme, "mailto") && u.Host == "" {
+		return fmt.Errorf("URL has no host: %s", rawURL)
+	}
+	return nil
+}
+
+// openCommand 用默认应用打开文件或目录的命令
+func openCommand(path string) *exec.Cmd {
+	switch goruntime.GOOS {
+	case "darwin":
+		return exec.Command("open", path)
+	case "windows":
+		// 不经过 cmd /c start：路径中的 &、^、| 会被 cmd 当作元字符解释
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default: // linux and others
+		return exec.Command("xdg-open", path)
+	}
+}
+
+// revealCommand 在文件管理器中显示并选中文件的命令
+func revealCommand(path string) *exec.Cmd {
+	switch goruntime.GOOS {
+	case "darwin":
+		// macOS: open -R 会在 Finder 中显示并选中文件
+		return exec.Command("open", "-R", path)
+	case "windows":
+		// Windows: explorer /select, 会在资源管理器中显示并选中文件
+		return exec.Command("explorer", "/select,", path)
+	default: // linux and others
+		// Linux: 文件管理器没有统一的选中参数，打开文件所在目录
+		return exec.Command("xdg-open", filepath.Dir(path))
+	}
+}
+
+// openURLCommand 用默认浏览器打开网址的命令
+func openURLCommand(rawURL string) *exec.Cmd {
+	switch goruntime.GOOS {
+	case "darwin":
+		return exec.Command("open", rawURL)
+	case "windows":
+		// cmd /c start 会把 URL 中的 & 当作命令分隔符，改用 url.dll
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL)
+	default: // linux and others
+		return exec.Command("xdg-open", rawURL)
+	}
+}
+
+// start 启动外部命令（不等待退出）
+func start(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", filepath.Base(cmd.Path), err)
+	}
+	// 回收子进程，避免留下僵尸进程
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
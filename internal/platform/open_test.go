@@ -0,0 +1,43 @@
+package platform
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateURL(t *testing.T) {
+	valid := []string{
+		"https://example.com/page?a=1&b=2",
+		"http://localhost:8080",
+		"mailto:someone@example.com",
+		" HTTPS://Example.com ",
+	}
+	for _, u := range valid {
+		if err := ValidateURL(u); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", u, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"example.com",
+		"file:///etc/passwd",
+		"javascript:alert(1)",
+		"https://",
+	}
+	for _, u := range invalid {
+		if err := ValidateURL(u); err == nil {
+			t.Errorf("Expected %q to be rejected", u)
+		}
+	}
+}
+
+func TestOpenPath_MissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	if err := OpenPath(missing); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if err := RevealPath(missing); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,215 @@
+package rag
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+const (
+	vectorBackupPrefix     = "vectors.db.bak-"
+	vectorBackupTimeFormat = "20060102-150405"
+	maxVectorBackups       = 3 // 保留的向量库备份数
+)
+
+// VectorBackup 向量库备份信息
+type VectorBackup struct {
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"createdAt"` // 备份时间（Unix 毫秒）
+	Dimension int    `json:"dimension"` // 备份中向量的维度
+	Model     string `json:"model"`     // 生成备份的嵌入模型（provider:model）
+}
+
+// backupVectorDatabase 将向量库备份到备份目录（仅保留最近 maxVectorBackups 份）
+// 使用 VACUUM INTO 生成一致的快照（包含尚未检查点的 WAL 数据），数据库打开时也可调用；数据库不存在时返回空路径
+func backupVectorDatabase(paths *utils.PathBuilder) (string, error) {
+	dbPath := paths.RAGDatabase()
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if err := os.MkdirAll(paths.BackupsDir(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create backups directory: %w", err)
+	}
+	backupPath := filepath.Join(paths.BackupsDir(), vectorBackupPrefix+time.Now().Format(vectorBackupTimeFormat))
+	if err := vacuumInto(dbPath, backupPath); err != nil {
+		_ = os.Remove(backupPath)
+		return "", fmt.Errorf("failed to back up vector database: %w", err)
+	}
+
+	pruneVectorBackups(paths)
+	return backupPath, nil
+}
+
+// backupBeforeDimensionChange 维度变化会清空向量库，清空前先备份
+// 备份失败时返回错误，调用方不应继续删除旧库
+func backupBeforeDimensionChange(paths *utils.PathBuilder) error {
+	backup, err := backupVectorDatabase(paths)
+	if err != nil {
+		return fmt.Errorf("refusing to discard vectors without a backup: %w", err)
+	}
+	if backup != "" {
+		fmt.Printf("💾 [RAG] Backed up vector database to %s (restore it after switching back to the previous model)\n", backup)
+	}
+	return nil
+}
+
+// pruneVectorBackups 删除多余的旧备份
+func pruneVectorBackups(paths *utils.PathBuilder) {
+	names := vectorBackupNames(paths)
+	for i := maxVectorBackups; i < len(names); i++ {
+		if err := os.Remove(filepath.Join(paths.BackupsDir(), names[i])); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to remove old vector backup %s: %v\n", names[i], err)
+		}
+	}
+}
+
+// vectorBackupNames 列出备份文件名（最新的在前）
+func vectorBackupNames(paths *utils.PathBuilder) []string {
+	entries, err := os.ReadDir(paths.BackupsDir())
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), vectorBackupPrefix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names
+}
+
+// ListVectorBackups 列出向量库备份（最新的在前）
+func (s *Service) ListVectorBackups() ([]VectorBackup, error) {
+	backups := []VectorBackup{}
+	for _, name := range vectorBackupNames(s.paths) {
+		path := filepath.Join(s.paths.BackupsDir(), name)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		backup := VectorBackup{Name: name, Size: info.Size(), CreatedAt: info.ModTime().UnixMilli()}
+		if created, err := time.ParseInLocation(vectorBackupTimeFormat, strings.TrimPrefix(name, vectorBackupPrefix), time.Local); err == nil {
+			backup.CreatedAt = created.UnixMilli()
+		}
+		backup.Dimension, backup.Model = readBackupMeta(path)
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// RestoreVectorBackup 用备份替换当前向量库（用于误切换嵌入模型后恢复，无需重新生成向量）
+// 备份的维度和模型必须与当前配置一致，否则恢复后会被立即清空
+func (s *Service) RestoreVectorBackup(name string) error {
+	if name != filepath.Base(name) || !strings.HasPrefix(name, vectorBackupPrefix) {
+		return fmt.Errorf("invalid backup name: %s", name)
+	}
+	backupPath := filepath.Join(s.paths.BackupsDir(), name)
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("backup not found: %s", name)
+	}
+
+	if err := s.init(); err != nil {
+		return err
+	}
+	config, err := LoadConfig(s.paths)
+	if err != nil {
+		return err
+	}
+	dimension, model := readBackupMeta(backupPath)
+	if dimension != s.dimension {
+		return fmt.Errorf("backup dimension %d does not match current model dimension %d; switch the embedding model back first", dimension, s.dimension)
+	}
+	if model != "" && !sameModel(model, modelIdentity(config)) {
+		return fmt.Errorf("backup was built with %s but current model is %s; switch the embedding model back first", model, modelIdentity(config))
+	}
+
+	// 替换文件前必须先停止后台重建并关闭所有连接，否则仍打开的连接会写入被删除的 WAL
+	s.cancelBackgroundReindex()
+	if err := s.store.Close(); err != nil {
+		return fmt.Errorf("failed to close vector database: %w", err)
+	}
+	s.store = nil
+	s.indexer = nil
+	s.searcher = nil
+	s.externalIndexer = nil
+	s.embedder = nil
+
+	// 先复制到临时文件再替换，避免复制中途失败损坏当前数据库
+	dbPath := s.paths.RAGDatabase()
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(backupPath, tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+	// 残留的 WAL 属于被替换的数据库，必须一并删除
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(dbPath + suffix)
+	}
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+	fmt.Printf("✅ [RAG] Restored vector database from %s\n", name)
+
+	return s.Reinitialize()
+}
+
+// vacuumInto 将数据库快照写入 dst（dst 已存在时覆盖）
+func vacuumInto(dbPath, dst string) error {
+	// VACUUM INTO 要求目标文件不存在
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+	_, err = db.Exec("VACUUM INTO ?", dst)
+	return err
+}
+
+// readBackupMeta 读取备份中记录的维度和模型标识
+func readBackupMeta(path string) (int, string) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return 0, ""
+	}
+	defer func() { _ = db.Close() }()
+
+	var model string
+	_ = db.QueryRow("SELECT value FROM vec_config WHERE key = ?", metaKeyModel).Scan(&model)
+	return readStoredDimension(db), model
+}
+
+// copyFile 复制文件
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
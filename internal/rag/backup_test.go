@@ -0,0 +1,123 @@
+package rag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"notion-lite/internal/utils"
+)
+
+func TestBackupVectorDatabase_KeepsLatest(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+
+	// 数据库不存在时不创建备份
+	if backup, err := backupVectorDatabase(paths); err != nil || backup != "" {
+		t.Fatalf("Expected no backup for missing database, got %q (%v)", backup, err)
+	}
+
+	store, err := NewVectorStore(paths.RAGDatabase(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMeta(metaKeyModel, "ollama:model-a"); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	// 预置旧备份，超过上限的最旧备份应被删除
+	if err := os.MkdirAll(paths.BackupsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, stamp := range []string{"20240101-000000", "20240102-000000", "20240103-000000"} {
+		if err := copyFile(paths.RAGDatabase(), filepath.Join(paths.BackupsDir(), vectorBackupPrefix+stamp)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup, err := backupVectorDatabase(paths)
+	if err != nil {
+		t.Fatalf("backupVectorDatabase failed: %v", err)
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Fatalf("Expected backup file: %v", err)
+	}
+
+	service := &Service{paths: paths}
+	backups, err := service.ListVectorBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != maxVectorBackups {
+		t.Fatalf("Expected %d backups, got %d", maxVectorBackups, len(backups))
+	}
+	if backups[0].Name != filepath.Base(backup) {
+		t.Errorf("Expected newest backup first, got %s", backups[0].Name)
+	}
+	if backups[len(backups)-1].Name != vectorBackupPrefix+"20240102-000000" {
+		t.Errorf("Expected oldest backup to be pruned, got %s", backups[len(backups)-1].Name)
+	}
+	if backups[0].Dimension != 3 || backups[0].Model != "ollama:model-a" {
+		t.Errorf("Expected backup meta (3, ollama:model-a), got (%d, %s)", backups[0].Dimension, backups[0].Model)
+	}
+
+	for _, name := range []string{"../vectors.db", "other.db", vectorBackupPrefix + "missing"} {
+		if err := service.RestoreVectorBackup(name); err == nil {
+			t.Errorf("Expected error restoring %q", name)
+		}
+	}
+}
+
+func TestBackupVectorDatabase_IncludesWAL(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	store, err := NewVectorStore(paths.RAGDatabase(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	// 连接保持打开，写入的数据仍在 WAL 中
+	if err := store.Upsert(&BlockVector{ID: "b1", DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if fileSize(paths.RAGDatabase()+"-wal") == 0 {
+		t.Fatal("Expected uncheckpointed data in the WAL")
+	}
+
+	backup, err := backupVectorDatabase(paths)
+	if err != nil {
+		t.Fatalf("backupVectorDatabase failed: %v", err)
+	}
+	restored, err := NewVectorStore(backup, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = restored.Close() }()
+	var count int
+	if err := restored.db.QueryRow("SELECT COUNT(*) FROM vec_blocks").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected the backup to contain 1 vector, got %d", count)
+	}
+}
+
+func TestBackupBeforeDimensionChange_FailsWithoutBackup(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	store, err := NewVectorStore(paths.RAGDatabase(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	// 备份目录被同名文件占用，备份必然失败
+	if err := os.WriteFile(paths.BackupsDir(), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := backupBeforeDimensionChange(paths); err == nil {
+		t.Fatal("Expected an error when the backup cannot be written")
+	}
+	if _, err := os.Stat(paths.RAGDatabase()); err != nil {
+		t.Errorf("Expected the old database to be kept: %v", err)
+	}
+}
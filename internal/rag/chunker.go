@@ -6,14 +6,68 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 分块长度单位
+const (
+	ChunkUnitChars  = "chars"  // 按字节长度计算（默认）
+	ChunkUnitTokens = "tokens" // 按估算的 token 数计算，中英文混排时更贴近模型上下文限制
 )
 
 // ChunkConfig 分块配置
 type ChunkConfig struct {
-	MaxChunkSize        int // 长块分割阈值，默认 800
-	Overlap             int // 重叠字符数，默认 100
-	ShortBlockThreshold int // 短块阈值，低于此长度的块可能被合并，默认 150
-	MaxMergedLength     int // 合并后最大长度，默认 600
+	MaxChunkSize        int             // 长块分割阈值，默认 800
+	Overlap             int             // 重叠长度，默认 100
+	ShortBlockThreshold int             // 短块阈值，低于此长度的块可能被合并，默认 150
+	MaxMergedLength     int             // 合并后最大长度，默认 600
+	Unit                string          // 以上长度的单位："chars"（默认）或 "tokens"
+	SentenceDelimiters  string          // 句子分隔符集合（每个字符都是分隔符），空表示 DefaultSentenceDelimiters
+	ExcludedTypes       map[string]bool // 不参与索引的块类型（如 codeBlock），nil 表示不排除
+}
+
+// size 按配置的单位计算文本长度
+func (c ChunkConfig) size(text string) int {
+	if c.Unit == ChunkUnitTokens {
+		return EstimateTokens(text)
+	}
+	return len(text)
+}
+
+// EstimateTokens 估算文本的 token 数（近似 BPE 分词器）
+// CJK 字符每字计 1 个 token，拉丁字母/数字按每 4 个字符 1 个 token 计，其余标点符号各计 1 个，空白不计
+func EstimateTokens(text string) int {
+	tokens := 0
+	wordLen := 0
+	flushWord := func() {
+		if wordLen > 0 {
+			tokens += (wordLen + 3) / 4
+			wordLen = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			wordLen++
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// isCJK 判断是否为中日韩文字（汉字、假名、谚文）
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
 }
 
 // DefaultChunkConfig 默认分块配置
@@ -24,38 +78,121 @@ var DefaultChunkConfig = ChunkConfig{
 	MaxMergedLength:     600,
 }
 
-// ChunkTextContent 对纯文本进行分块（用于书签等外部内容）
-// 按段落分割，合并短段落，分割长段落
+// markdownHeadingPattern 匹配 Markdown 一至三级标题（与 HTML h1-h3 对应）
+var markdownHeadingPattern = regexp.MustCompile(`^(#{1,3})\s+(.+?)\s*#*\s*$`)
+
+// textSection 按标题划分的文本段
+type textSection struct {
+	headingContext string   // 标题路径，如 "Chapter 3 > Setup"
+	paragraphs     []string // 段内段落（首段为标题文本）
+}
+
+// ChunkTextContent 对纯文本进行分块（用于书签、文件、文件夹等外部内容）
+// 识别 Markdown 标题并按标题划分段落，每个 chunk 的 HeadingContext 为最近的标题路径；
+// 段内合并短段落、分割长段落，不跨标题合并
 func ChunkTextContent(text, headingContext, baseID string, config ChunkConfig) []ExtractedBlock {
 	if strings.TrimSpace(text) == "" {
 		return nil
 	}
 
-	// 1. 按双换行分割为段落
-	paragraphs := strings.Split(text, "\n\n")
-	var cleanParagraphs []string
-	for _, p := range paragraphs {
-		p = strings.TrimSpace(p)
-		if p != "" {
-			cleanParagraphs = append(cleanParagraphs, p)
+	var result []ExtractedBlock
+	index := 0
+	for _, section := range splitTextSections(text, headingContext) {
+		for _, chunk := range chunkParagraphs(section.paragraphs, config) {
+			if chunk == "" {
+				continue
+			}
+			result = append(result, ExtractedBlock{
+				ID:             fmt.Sprintf("%s_chunk_%d", baseID, index),
+				Type:           "bookmark_chunk",
+				Content:        chunk,
+				HeadingContext: section.headingContext,
+			})
+			index++
 		}
 	}
 
-	if len(cleanParagraphs) == 0 {
-		return nil
+	return result
+}
+
+// splitTextSections 按 Markdown 标题和空行把文本划分为段和段落
+// 标题之前的内容使用 defaultHeading；代码块（```）内的 # 行不视为标题
+func splitTextSections(text, defaultHeading string) []textSection {
+	var sections []textSection
+	current := textSection{headingContext: defaultHeading}
+	var headings []string // 各级标题栈，headings[i] 为 i+1 级标题
+	var para []string
+	inFence := false
+
+	flushPara := func() {
+		if p := strings.TrimSpace(strings.Join(para, "\n")); p != "" {
+			current.paragraphs = append(current.paragraphs, p)
+		}
+		para = para[:0]
 	}
 
-	// 2. 合并短段落 + 分割长段落
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if m := markdownHeadingPattern.FindStringSubmatch(trimmed); m != nil {
+				flushPara()
+				if len(current.paragraphs) > 0 {
+					sections = append(sections, current)
+				}
+
+				level := len(m[1])
+				for len(headings) < level {
+					headings = append(headings, "")
+				}
+				headings = append(headings[:level-1], m[2])
+
+				var path []string
+				for _, h := range headings {
+					if h != "" {
+						path = append(path, h)
+					}
+				}
+				current = textSection{
+					headingContext: strings.Join(path, " > "),
+					paragraphs:     []string{m[2]},
+				}
+				continue
+			}
+		}
+
+		if trimmed == "" && !inFence {
+			flushPara()
+			continue
+		}
+		para = append(para, line)
+	}
+	flushPara()
+	if len(current.paragraphs) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+// chunkParagraphs 合并短段落、分割长段落
+func chunkParagraphs(paragraphs []string, config ChunkConfig) []string {
 	var chunks []string
 	var currentChunk strings.Builder
+	currentSize := 0
+
+	for _, para := range paragraphs {
+		paraSize := config.size(para)
 
-	for _, para := range cleanParagraphs {
 		// 如果段落本身就超长，先分割它
-		if len(para) > config.MaxChunkSize {
+		if paraSize > config.MaxChunkSize {
 			// 先保存当前累积的内容
 			if currentChunk.Len() > 0 {
 				chunks = append(chunks, strings.TrimSpace(currentChunk.String()))
 				currentChunk.Reset()
+				currentSize = 0
 			}
 			// 按句子分割长段落
 			splitChunks := splitLongText(para, config)
@@ -64,22 +201,24 @@ func ChunkTextContent(text, headingContext, baseID string, config ChunkConfig) [
 		}
 
 		// 检查是否可以合并到当前 chunk
-		newLen := currentChunk.Len() + len(para)
-		if currentChunk.Len() > 0 {
-			newLen += 2 // 换行符
+		newSize := currentSize + paraSize
+		if currentChunk.Len() > 0 && config.Unit != ChunkUnitTokens {
+			newSize += 2 // 换行符
 		}
 
-		if newLen <= config.MaxMergedLength || currentChunk.Len() == 0 {
+		if newSize <= config.MaxMergedLength || currentChunk.Len() == 0 {
 			// 可以合并
 			if currentChunk.Len() > 0 {
 				currentChunk.WriteString("\n\n")
 			}
 			currentChunk.WriteString(para)
+			currentSize = newSize
 		} else {
 			// 保存当前块，开始新块
 			chunks = append(chunks, strings.TrimSpace(currentChunk.String()))
 			currentChunk.Reset()
 			currentChunk.WriteString(para)
+			currentSize = paraSize
 		}
 	}
 
@@ -87,39 +226,28 @@ func ChunkTextContent(text, headingContext, baseID string, config ChunkConfig) [
 	if currentChunk.Len() > 0 {
 		chunks = append(chunks, strings.TrimSpace(currentChunk.String()))
 	}
-
-	// 3. 转换为 ExtractedBlock
-	var result []ExtractedBlock
-	for i, chunk := range chunks {
-		if chunk == "" {
-			continue
-		}
-		result = append(result, ExtractedBlock{
-			ID:             fmt.Sprintf("%s_chunk_%d", baseID, i),
-			Type:           "bookmark_chunk",
-			Content:        chunk,
-			HeadingContext: headingContext,
-		})
-	}
-
-	return result
+	return chunks
 }
 
 // splitLongText 分割超长文本
 func splitLongText(text string, config ChunkConfig) []string {
-	sentences := splitIntoSentences(text)
+	sentences := splitIntoSentences(text, config.SentenceDelimiters)
 	var result []string
 	var currentChunk strings.Builder
+	currentSize := 0
 
 	for _, sentence := range sentences {
-		if currentChunk.Len() > 0 && currentChunk.Len()+len(sentence) > config.MaxChunkSize {
+		sentenceSize := config.size(sentence)
+		if currentChunk.Len() > 0 && currentSize+sentenceSize > config.MaxChunkSize {
 			result = append(result, strings.TrimSpace(currentChunk.String()))
 			// 应用 overlap
-			overlapContent := getOverlapContent(currentChunk.String(), config.Overlap)
+			overlapContent := getOverlapContent(currentChunk.String(), config)
 			currentChunk.Reset()
 			currentChunk.WriteString(overlapContent)
+			currentSize = config.size(overlapContent)
 		}
 		currentChunk.WriteString(sentence)
+		currentSize += sentenceSize
 	}
 
 	if currentChunk.Len() > 0 {
@@ -147,7 +275,7 @@ func mergeShortBlocks(blocks []ExtractedBlock, config ChunkConfig) []ExtractedBl
 		block := blocks[i]
 
 		// 检查是否可以开始合并
-		if canMergeBlock(block, config.ShortBlockThreshold) {
+		if canMergeBlock(block, config) {
 			// 尝试合并连续的短块
 			merged, nextIndex := tryMergeConsecutiveShortBlocks(blocks, i, config)
 			result = append(result, merged)
@@ -162,7 +290,7 @@ func mergeShortBlocks(blocks []ExtractedBlock, config ChunkConfig) []ExtractedBl
 }
 
 // canMergeBlock 判断一个块是否可以被合并
-func canMergeBlock(block ExtractedBlock, threshold int) bool {
+func canMergeBlock(block ExtractedBlock, config ChunkConfig) bool {
 	// 已聚合的列表块不参与合并
 	if strings.HasPrefix(block.Type, "aggregated_") {
 		return false
@@ -171,8 +299,12 @@ func canMergeBlock(block ExtractedBlock, threshold int) bool {
 	if strings.HasPrefix(block.Type, "heading") {
 		return false
 	}
+	// 引用、标注、批注独立成块，保留其类型
+	if standaloneTypes[block.Type] {
+		return false
+	}
 	// 长块不参与合并
-	if len(block.Content) >= threshold {
+	if config.size(block.Content) >= config.ShortBlockThreshold {
 		return false
 	}
 	return true
@@ -193,7 +325,7 @@ func tryMergeConsecutiveShortBlocks(blocks []ExtractedBlock, startIndex int, con
 		block := blocks[j]
 
 		// 检查是否可以继续合并
-		if !canMergeBlock(block, config.ShortBlockThreshold) {
+		if !canMergeBlock(block, config) {
 			break
 		}
 
@@ -203,8 +335,8 @@ func tryMergeConsecutiveShortBlocks(blocks []ExtractedBlock, startIndex int, con
 		}
 
 		// 检查合并后长度
-		newLength := totalLength + len(block.Content)
-		if totalLength > 0 {
+		newLength := totalLength + config.size(block.Content)
+		if totalLength > 0 && config.Unit != ChunkUnitTokens {
 			newLength += 1 // 换行符
 		}
 		if newLength > config.MaxMergedLength && totalLength > 0 {
@@ -244,22 +376,24 @@ func tryMergeConsecutiveShortBlocks(blocks []ExtractedBlock, startIndex int, con
 // splitLongBlock 分割长块
 func splitLongBlock(block ExtractedBlock, config ChunkConfig) []ExtractedBlock {
 	content := block.Content
-	if len(content) <= config.MaxChunkSize {
+	if config.size(content) <= config.MaxChunkSize {
 		return []ExtractedBlock{block}
 	}
 
 	// 按句子分割
-	sentences := splitIntoSentences(content)
+	sentences := splitIntoSentences(content, config.SentenceDelimiters)
 
 	var result []ExtractedBlock
 	var currentChunk strings.Builder
+	currentSize := 0
 	chunkIndex := 0
 
 	for _, sentence := range sentences {
+		sentenceSize := config.size(sentence)
 		// 如果添加这个句子会超过阈值，保存当前块并开始新块
-		if currentChunk.Len() > 0 && currentChunk.Len()+len(sentence) > config.MaxChunkSize {
+		if currentChunk.Len() > 0 && currentSize+sentenceSize > config.MaxChunkSize {
 			result = append(result, ExtractedBlock{
-				ID:             block.ID + "_chunk_" + string(rune('0'+chunkIndex)),
+				ID:             fmt.Sprintf("%s_chunk_%d", block.ID, chunkIndex),
 				Type:           block.Type + "_chunk",
 				Content:        strings.TrimSpace(currentChunk.String()),
 				HeadingContext: block.HeadingContext,
@@ -267,18 +401,20 @@ func splitLongBlock(block ExtractedBlock, config ChunkConfig) []ExtractedBlock {
 			chunkIndex++
 
 			// 应用 overlap：保留最后一部分内容
-			overlapContent := getOverlapContent(currentChunk.String(), config.Overlap)
+			overlapContent := getOverlapContent(currentChunk.String(), config)
 			currentChunk.Reset()
 			currentChunk.WriteString(overlapContent)
+			currentSize = config.size(overlapContent)
 		}
 
 		currentChunk.WriteString(sentence)
+		currentSize += sentenceSize
 	}
 
 	// 保存最后一个块
 	if currentChunk.Len() > 0 {
 		result = append(result, ExtractedBlock{
-			ID:             block.ID + "_chunk_" + string(rune('0'+chunkIndex)),
+			ID:             fmt.Sprintf("%s_chunk_%d", block.ID, chunkIndex),
 			Type:           block.Type + "_chunk",
 			Content:        strings.TrimSpace(currentChunk.String()),
 			HeadingContext: block.HeadingContext,
@@ -293,34 +429,54 @@ func splitLongBlock(block ExtractedBlock, config ChunkConfig) []ExtractedBlock {
 	return result
 }
 
-// splitIntoSentences 按句子分割文本
-func splitIntoSentences(text string) []string {
-	// 使用中英文句号、问号、感叹号作为分隔符
-	re := regexp.MustCompile(`([。？！.?!]+)`)
-	parts := re.Split(text, -1)
-	delimiters := re.FindAllString(text, -1)
+// getOverlapContent 获取用于重叠的内容（末尾不超过 config.Overlap 长度的部分）
+// 起点总在字符边界上，并尽量对齐到窗口内第一个完整句子的开头；窗口内没有句子边界时退到词边界
+func getOverlapContent(content string, config ChunkConfig) string {
+	if config.size(content) <= config.Overlap {
+		return content
+	}
 
-	var sentences []string
-	for i, part := range parts {
-		if part == "" {
-			continue
+	start := len(content)
+	if config.Unit != ChunkUnitTokens {
+		start = len(content) - config.Overlap
+		for start < len(content) && !utf8.RuneStart(content[start]) {
+			start++
 		}
-		sentence := part
-		if i < len(delimiters) {
-			sentence += delimiters[i]
+	} else {
+		// token 模式：从末尾逐字符向前扩展，直到超过重叠 token 数
+		for start > 0 {
+			_, width := utf8.DecodeLastRuneInString(content[:start])
+			if EstimateTokens(content[start-width:]) > config.Overlap {
+				break
+			}
+			start -= width
 		}
-		sentences = append(sentences, sentence)
 	}
-
-	return sentences
+	return strings.TrimLeftFunc(content[overlapStart(content, start, config.SentenceDelimiters):], unicode.IsSpace)
 }
 
-// getOverlapContent 获取用于重叠的内容
-func getOverlapContent(content string, overlap int) string {
-	if len(content) <= overlap {
-		return content
+// overlapStart 将重叠起点 start 向后调整到窗口内第一个句子开头；
+// 没有可用的句子边界时，若 start 落在英文单词中间则跳到下一个空白处（中文文本保持字符边界）
+func overlapStart(content string, start int, delimiters string) int {
+	offset := 0
+	for _, sentence := range splitIntoSentences(content, delimiters) {
+		if offset >= start && strings.TrimSpace(content[offset:]) != "" {
+			return offset
+		}
+		offset += len(sentence)
+	}
+
+	if start == 0 || start >= len(content) {
+		return start
+	}
+	prev, _ := utf8.DecodeLastRuneInString(content[:start])
+	if prev >= utf8.RuneSelf || !(unicode.IsLetter(prev) || unicode.IsDigit(prev)) {
+		return start
+	}
+	if i := strings.IndexFunc(content[start:], unicode.IsSpace); i >= 0 && start+i < len(content)-1 {
+		return start + i
 	}
-	return content[len(content)-overlap:]
+	return start
 }
 
 // generateAggregatedID 为聚合块生成唯一 ID
@@ -0,0 +1,276 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 4},      // 2 + 2
+		{"向量检索", 4},             // 每个汉字 1 个
+		{"使用 embedding 模型。", 8}, // 2 + 3 + 2 + 1（句号）
+		{"GPT-4", 3},            // GPT + - + 4
+	}
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSplitLongBlock_TokenUnitMixedLanguages(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 20; i++ {
+		b.WriteString("语义搜索依赖嵌入模型生成的向量。")
+		b.WriteString("The embedding model has a limited context window. ")
+	}
+	block := ExtractedBlock{ID: "blk", Type: "paragraph", Content: b.String()}
+
+	config := ChunkConfig{MaxChunkSize: 60, Overlap: 10, Unit: ChunkUnitTokens}
+	chunks := splitLongBlock(block, config)
+	if len(chunks) < 2 {
+		t.Fatalf("Expected the block to be split, got %d chunk(s)", len(chunks))
+	}
+	for _, c := range chunks {
+		if tokens := EstimateTokens(c.Content); tokens > config.MaxChunkSize {
+			t.Errorf("Chunk %s has %d tokens, exceeds limit %d", c.ID, tokens, config.MaxChunkSize)
+		}
+		if !utf8.ValidString(c.Content) {
+			t.Errorf("Chunk %s is not valid UTF-8", c.ID)
+		}
+	}
+
+	// 同样的上限按字节计算时，CJK 文本会被切得远比 token 模式细
+	charChunks := splitLongBlock(block, ChunkConfig{MaxChunkSize: 60, Overlap: 10, Unit: ChunkUnitChars})
+	if len(charChunks) <= len(chunks) {
+		t.Errorf("Expected char mode to produce more chunks than token mode, got %d vs %d", len(charChunks), len(chunks))
+	}
+}
+
+func TestChunkTextContent_TokenUnit(t *testing.T) {
+	paragraphs := []string{
+		"第一段：介绍本地优先的知识库。",
+		"Second paragraph explains how chunks are merged.",
+		strings.Repeat("第三段包含很长的中文内容，需要被进一步切分。", 10),
+	}
+	text := strings.Join(paragraphs, "\n\n")
+
+	config := ChunkConfig{MaxChunkSize: 50, Overlap: 5, MaxMergedLength: 40, Unit: ChunkUnitTokens}
+	chunks := ChunkTextContent(text, "", "doc", config)
+	if len(chunks) < 3 {
+		t.Fatalf("Expected at least 3 chunks, got %d", len(chunks))
+	}
+	// 前两段合计约 30 token，应合并为一个 chunk
+	if !strings.Contains(chunks[0].Content, "Second paragraph") {
+		t.Errorf("Expected first two paragraphs to be merged, got %q", chunks[0].Content)
+	}
+	for _, c := range chunks {
+		if tokens := EstimateTokens(c.Content); tokens > config.MaxChunkSize {
+			t.Errorf("Chunk %s has %d tokens, exceeds limit %d", c.ID, tokens, config.MaxChunkSize)
+		}
+	}
+}
+
+func TestGetOverlapContent_KeepsRunesIntact(t *testing.T) {
+	content := "向量检索很有用"
+	got := getOverlapContent(content, ChunkConfig{Overlap: 7})
+	if !utf8.ValidString(got) {
+		t.Fatalf("Expected valid UTF-8 overlap, got %q", got)
+	}
+	if got != "有用" {
+		t.Errorf("Expected trailing whole characters within 7 bytes, got %q", got)
+	}
+
+	got = getOverlapContent(content, ChunkConfig{Overlap: 3, Unit: ChunkUnitTokens})
+	if got != "很有用" {
+		t.Errorf("Expected last 3 tokens, got %q", got)
+	}
+}
+
+func TestGetOverlapContent_SentenceBoundary(t *testing.T) {
+	content := "第一句话比较长一些。第二句。第三句很短！"
+	// 窗口为末尾 25 字节，起点落在"第二句。"中间，应对齐到"第三句很短！"
+	got := getOverlapContent(content, ChunkConfig{Overlap: 25})
+	if !utf8.ValidString(got) {
+		t.Fatalf("Expected valid UTF-8 overlap, got %q", got)
+	}
+	if got != "第三句很短！" {
+		t.Errorf("Expected overlap to start at a sentence boundary, got %q", got)
+	}
+
+	got = getOverlapContent(content, ChunkConfig{Overlap: 6, Unit: ChunkUnitTokens})
+	if got != "第三句很短！" {
+		t.Errorf("Expected token overlap to start at a sentence boundary, got %q", got)
+	}
+
+	// 窗口内没有句子边界时不截断英文单词
+	got = getOverlapContent("The quick brown fox jumps", ChunkConfig{Overlap: 13})
+	if got != "fox jumps" {
+		t.Errorf("Expected overlap to start at a word boundary, got %q", got)
+	}
+}
+
+func TestChunkTextContent_MarkdownHeadings(t *testing.T) {
+	text := strings.Join([]string{
+		"Preface without heading.",
+		"# Chapter 3",
+		"Intro to the chapter.",
+		"## Setup",
+		"Install the tools.",
+		"```bash",
+		"# not a heading",
+		"make install",
+		"```",
+		"## Usage",
+		"Run the app.",
+		"# Chapter 4",
+		"Next chapter.",
+	}, "\n")
+
+	chunks := ChunkTextContent(text, "report.md", "file", DefaultChunkConfig)
+	want := []string{"report.md", "Chapter 3", "Chapter 3 > Setup", "Chapter 3 > Usage", "Chapter 4"}
+	if len(chunks) != len(want) {
+		t.Fatalf("Expected %d chunks, got %d: %+v", len(want), len(chunks), chunks)
+	}
+	for i, c := range chunks {
+		if c.HeadingContext != want[i] {
+			t.Errorf("Chunk %d HeadingContext = %q, want %q", i, c.HeadingContext, want[i])
+		}
+	}
+	if !strings.Contains(chunks[2].Content, "# not a heading") {
+		t.Errorf("Expected fenced code to stay in the Setup chunk, got %q", chunks[2].Content)
+	}
+	if chunks[4].ID != "file_chunk_4" {
+		t.Errorf("Expected sequential chunk IDs, got %s", chunks[4].ID)
+	}
+}
+
+func TestSplitIntoSentences(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		delimiters string
+		want       []string
+	}{
+		{"decimal", "Version 3.14 is out.", "", []string{"Version 3.14 is out."}},
+		{"abbreviation", "See e.g. the docs. Then run it!", "", []string{"See e.g. the docs.", " Then run it!"}},
+		{"domain", "Visit example.com today. Thanks", "", []string{"Visit example.com today.", " Thanks"}},
+		{"repeated", "真的吗？！好的。", "", []string{"真的吗？！", "好的。"}},
+		{"arabic", "هل أنت بخير؟ نعم", "", []string{"هل أنت بخير؟", " نعم"}},
+		{"hindi", "यह पहला वाक्य है। यह दूसरा है।", "", []string{"यह पहला वाक्य है।", " यह दूसरा है।"}},
+		{"custom", "a;b.c", ";", []string{"a;", "b.c"}},
+		{"decimal mid-sentence", "Pi is 3.14 exactly. Next", "", []string{"Pi is 3.14 exactly.", " Next"}},
+		{"abbreviation mid-sentence", "See e.g. the appendix.", "", []string{"See e.g. the appendix."}},
+		{"mixed cjk and latin", "圆周率约为 3.14。See i.e. below!", "", []string{"圆周率约为 3.14。", "See i.e. below!"}},
+		{"common word before period", "I said no. We left. Plan b. Done", "", []string{"I said no.", " We left.", " Plan b.", " Done"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitIntoSentences(tt.text, tt.delimiters)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitIntoSentences(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLongBlock_ManyChunkIDs(t *testing.T) {
+	block := ExtractedBlock{
+		ID:      "blk",
+		Type:    "paragraph",
+		Content: strings.Repeat("This sentence is repeated to build a very long block. ", 60),
+	}
+	chunks := splitLongBlock(block, ChunkConfig{MaxChunkSize: 120, Overlap: 20})
+	if len(chunks) < 15 {
+		t.Fatalf("Expected at least 15 chunks, got %d", len(chunks))
+	}
+
+	seen := make(map[string]bool, len(chunks))
+	for i, c := range chunks {
+		want := fmt.Sprintf("blk_chunk_%d", i)
+		if c.ID != want {
+			t.Errorf("Chunk %d has ID %q, want %q", i, c.ID, want)
+		}
+		if seen[c.ID] {
+			t.Errorf("Duplicate chunk ID %q", c.ID)
+		}
+		seen[c.ID] = true
+	}
+}
+
+func TestGetChunkConfig_AppliesUserSizes(t *testing.T) {
+	sentence := "Chunk size settings should control how long paragraphs are split. "
+	content := fmt.Sprintf(`[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": %q}]}]`,
+		strings.Repeat(sentence, 40))
+
+	defaultConfig := (&EmbeddingConfig{}).GetChunkConfig()
+	smallConfig := (&EmbeddingConfig{MaxChunkSize: 200, Overlap: 20}).GetChunkConfig()
+	defaultBlocks := ExtractBlocksWithConfig([]byte(content), defaultConfig)
+	smallBlocks := ExtractBlocksWithConfig([]byte(content), smallConfig)
+
+	if len(smallBlocks) <= len(defaultBlocks) {
+		t.Fatalf("Expected MaxChunkSize=200 to produce more chunks than the default, got %d vs %d", len(smallBlocks), len(defaultBlocks))
+	}
+	for _, b := range smallBlocks {
+		// 每个 chunk 最多带上前一个 chunk 的重叠部分
+		if len(b.Content) > smallConfig.MaxChunkSize+smallConfig.Overlap+len(sentence) {
+			t.Errorf("Chunk %s has %d bytes, expected roughly at most %d", b.ID, len(b.Content), smallConfig.MaxChunkSize)
+		}
+	}
+}
+
+func TestGetChunkConfig_ValidatesRanges(t *testing.T) {
+	config := (&EmbeddingConfig{MaxChunkSize: 200, Overlap: 300, ShortBlockThreshold: 500}).GetChunkConfig()
+	if config.Overlap >= config.MaxChunkSize {
+		t.Errorf("Overlap %d should be smaller than MaxChunkSize %d", config.Overlap, config.MaxChunkSize)
+	}
+	if config.MaxMergedLength > config.MaxChunkSize {
+		t.Errorf("MaxMergedLength %d should not exceed MaxChunkSize %d", config.MaxMergedLength, config.MaxChunkSize)
+	}
+	if config.ShortBlockThreshold >= config.MaxMergedLength {
+		t.Errorf("ShortBlockThreshold %d should be smaller than MaxMergedLength %d", config.ShortBlockThreshold, config.MaxMergedLength)
+	}
+
+	defaults := (&EmbeddingConfig{}).GetChunkConfig()
+	if defaults.MaxChunkSize != DefaultChunkConfig.MaxChunkSize || defaults.Overlap != DefaultChunkConfig.Overlap ||
+		defaults.ShortBlockThreshold != DefaultChunkConfig.ShortBlockThreshold || defaults.MaxMergedLength != DefaultChunkConfig.MaxMergedLength {
+		t.Errorf("Unset fields should fall back to DefaultChunkConfig, got %+v", defaults)
+	}
+}
+
+func TestGetChunkConfig_SentenceDelimiters(t *testing.T) {
+	content := `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + strings.Repeat("first part; second part; ", 30) + `"}]}]`
+	config := (&EmbeddingConfig{MaxChunkSize: 100, Overlap: 10, SentenceDelimiters: ";"}).GetChunkConfig()
+	if config.SentenceDelimiters != ";" {
+		t.Fatalf("Expected the configured delimiters to be used, got %q", config.SentenceDelimiters)
+	}
+	for _, b := range ExtractBlocksWithConfig([]byte(content), config) {
+		if !strings.HasSuffix(strings.TrimSpace(b.Content), ";") {
+			t.Errorf("Expected chunk %s to end at a configured delimiter, got %q", b.ID, b.Content)
+		}
+	}
+}
+
+func TestGetChunkConfig_ChunkUnit(t *testing.T) {
+	if unit := (&EmbeddingConfig{ChunkUnit: "tokens"}).GetChunkConfig().Unit; unit != ChunkUnitTokens {
+		t.Errorf("Expected tokens unit, got %q", unit)
+	}
+	if unit := (&EmbeddingConfig{ChunkUnit: "words"}).GetChunkConfig().Unit; unit != ChunkUnitChars {
+		t.Errorf("Expected unknown units to fall back to chars, got %q", unit)
+	}
+
+	// 中文每字 3 字节但只计 1 个 token：同样的上限按 token 计算时块更少
+	content := `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + strings.Repeat("分块长度按估算的词元数计算。", 40) + `"}]}]`
+	chars := ExtractBlocksWithConfig([]byte(content), (&EmbeddingConfig{MaxChunkSize: 300, Overlap: 20}).GetChunkConfig())
+	tokens := ExtractBlocksWithConfig([]byte(content), (&EmbeddingConfig{MaxChunkSize: 300, Overlap: 20, ChunkUnit: "tokens"}).GetChunkConfig())
+	if len(tokens) >= len(chars) {
+		t.Errorf("Expected fewer chunks with the tokens unit, got %d vs %d", len(tokens), len(chars))
+	}
+}
@@ -0,0 +1,335 @@
+package rag
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// TopicCluster 主题聚类（文档平均向量的 k-means 聚类）
+type TopicCluster struct {
+	ID    int             `json:"id"`
+	Terms []string        `json:"terms"` // 代表性词语（来自成员文档的标题和内容）
+	Docs  []ClusterMember `json:"docs"`  // 成员文档（按与聚类中心的相似度降序）
+}
+
+// ClusterMember 聚类成员文档
+type ClusterMember struct {
+	DocID      string  `json:"docId"`
+	Title      string  `json:"title"`
+	Similarity float32 `json:"similarity"` // 与聚类中心的余弦相似度
+}
+
+const (
+	maxClusterIterations = 50 // k-means 最大迭代次数
+	clusterTermCount     = 5  // 每个聚类返回的代表性词语数
+	maxAutoClusters      = 12 // 自动选择 k 时的上限
+	titleTermWeight      = 3  // 标题中的词语权重
+)
+
+// topicClusterCache 主题聚类缓存（store 或其版本变化时失效）
+type topicClusterCache struct {
+	mu       sync.Mutex
+	store    *VectorStore
+	version  int64
+	k        int
+	clusters []TopicCluster
+}
+
+// clusterStopWords 提取代表性词语时忽略的常见英文词
+var clusterStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "are": true,
+	"was": true, "from": true, "have": true, "has": true, "not": true, "but": true, "you": true,
+	"your": true, "can": true, "will": true, "into": true, "about": true, "there": true, "their": true,
+	"they": true, "which": true, "when": true, "what": true, "how": true, "all": true, "any": true,
+	"our": true, "use": true, "using": true, "also": true, "more": true, "one": true, "its": true,
+}
+
+// GetTopicClusters 对文档平均向量做 k-means 聚类，返回各聚类的成员文档和代表性词语
+// k <= 0 时按文档数自动选择（约 sqrt(n/2)），结果在向量未变化时缓存
+func (s *Service) GetTopicClusters(k int) ([]TopicCluster, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+
+	cache := &s.clusterCache
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	version := s.store.Version()
+	if cache.clusters == nil || cache.store != s.store || cache.version != version || cache.k != k {
+		clusters, err := s.computeTopicClusters(k)
+		if err != nil {
+			return nil, err
+		}
+		cache.store, cache.version, cache.k, cache.clusters = s.store, version, k, clusters
+	}
+
+	return s.withCurrentTitles(cache.clusters), nil
+}
+
+// computeTopicClusters 计算主题聚类
+func (s *Service) computeTopicClusters(k int) ([]TopicCluster, error) {
+	index, err := s.docRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var docIDs []string
+	var vectors [][]float32
+	titles := make(map[string]string)
+	for _, doc := range index.Documents {
+		vec, _, err := s.getDocumentAverageVector(doc.ID)
+		if err != nil || vec == nil {
+			continue
+		}
+		docIDs = append(docIDs, doc.ID)
+		vectors = append(vectors, normalizeVector(vec))
+		titles[doc.ID] = doc.Title
+	}
+	if len(vectors) == 0 {
+		return []TopicCluster{}, nil
+	}
+
+	if k <= 0 {
+		k = int(math.Round(math.Sqrt(float64(len(vectors)) / 2)))
+		k = max(1, min(k, maxAutoClusters))
+	}
+	k = min(k, len(vectors))
+
+	assignments, centroids := kMeans(vectors, k)
+
+	// 按聚类收集成员
+	clusters := make([]TopicCluster, k)
+	for i := range clusters {
+		clusters[i] = TopicCluster{Docs: []ClusterMember{}}
+	}
+	for i, c := range assignments {
+		clusters[c].Docs = append(clusters[c].Docs, ClusterMember{
+			DocID:      docIDs[i],
+			Title:      titles[docIDs[i]],
+			Similarity: cosineSimilarity(vectors[i], centroids[c]),
+		})
+	}
+
+	// 代表性词语：聚类内词频 × 逆聚类频率
+	contents := s.documentContents()
+	termCounts := make([]map[string]int, k)
+	clusterFreq := make(map[string]int)
+	for c := range clusters {
+		termCounts[c] = make(map[string]int)
+		for _, member := range clusters[c].Docs {
+			for _, term := range extractTerms(member.Title) {
+				termCounts[c][term] += titleTermWeight
+			}
+			for _, term := range extractTerms(contents[member.DocID]) {
+				termCounts[c][term]++
+			}
+		}
+		for term := range termCounts[c] {
+			clusterFreq[term]++
+		}
+	}
+
+	result := make([]TopicCluster, 0, k)
+	for c := range clusters {
+		if len(clusters[c].Docs) == 0 {
+			continue
+		}
+		sort.Slice(clusters[c].Docs, func(i, j int) bool {
+			return clusters[c].Docs[i].Similarity > clusters[c].Docs[j].Similarity
+		})
+		clusters[c].Terms = topTerms(termCounts[c], clusterFreq, k)
+		result = append(result, clusters[c])
+	}
+
+	// 大聚类在前，重新编号
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Docs) > len(result[j].Docs)
+	})
+	for i := range result {
+		result[i].ID = i
+	}
+	return result, nil
+}
+
+// withCurrentTitles 复制缓存的聚类并使用最新的文档标题（重命名不会使缓存失效）
+func (s *Service) withCurrentTitles(cached []TopicCluster) []TopicCluster {
+	titles := make(map[string]string)
+	if index, err := s.docRepo.GetAll(); err == nil {
+		for _, doc := range index.Documents {
+			titles[doc.ID] = doc.Title
+		}
+	}
+
+	result := make([]TopicCluster, len(cached))
+	for i, cluster := range cached {
+		docs := make([]ClusterMember, 0, len(cluster.Docs))
+		for _, member := range cluster.Docs {
+			if title, ok := titles[member.DocID]; ok {
+				member.Title = title
+			}
+			docs = append(docs, member)
+		}
+		result[i] = TopicCluster{ID: cluster.ID, Terms: cluster.Terms, Docs: docs}
+	}
+	return result
+}
+
+// documentContents 获取每个文档的正文块内容（用于提取词语）
+func (s *Service) documentContents() map[string]string {
+	contents := make(map[string]string)
+	blocks, err := s.store.GetAllBlockMeta()
+	if err != nil {
+		return contents
+	}
+	for _, b := range blocks {
+		if b.SourceType != "" && b.SourceType != "document" {
+			continue
+		}
+		contents[b.DocID] += b.Content + "\n"
+	}
+	return contents
+}
+
+// kMeans 球面 k-means（向量已归一化，按余弦相似度分配），k-means++ 初始化
+// 使用固定随机种子，相同输入得到相同结果
+func kMeans(vectors [][]float32, k int) ([]int, [][]float32) {
+	rng := rand.New(rand.NewSource(1))
+	centroids := make([][]float32, 0, k)
+	centroids = append(centroids, vectors[rng.Intn(len(vectors))])
+
+	// k-means++：按与最近中心的距离平方加权选取后续中心
+	dist := make([]float64, len(vectors))
+	for len(centroids) < k {
+		total := 0.0
+		for i, v := range vectors {
+			best := math.MaxFloat64
+			for _, c := range centroids {
+				d := 1 - float64(cosineSimilarity(v, c))
+				best = math.Min(best, d*d)
+			}
+			dist[i] = best
+			total += best
+		}
+		if total == 0 {
+			break // 剩余向量与已有中心完全相同
+		}
+		target := rng.Float64() * total
+		next := len(vectors) - 1
+		for i, d := range dist {
+			if target -= d; target <= 0 {
+				next = i
+				break
+			}
+		}
+		centroids = append(centroids, vectors[next])
+	}
+
+	assignments := make([]int, len(vectors))
+	for iter := 0; iter < maxClusterIterations; iter++ {
+		changed := iter == 0
+		for i, v := range vectors {
+			best, bestSim := 0, float32(-2)
+			for c, centroid := range centroids {
+				if sim := cosineSimilarity(v, centroid); sim > bestSim {
+					best, bestSim = c, sim
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		// 更新中心为成员平均向量（空聚类保留原中心）
+		members := make([][][]float32, len(centroids))
+		for i, c := range assignments {
+			members[c] = append(members[c], vectors[i])
+		}
+		for c := range centroids {
+			if len(members[c]) > 0 {
+				centroids[c] = normalizeVector(averageVectors(members[c]))
+			}
+		}
+	}
+
+	// k-means++ 提前结束时补齐空中心，保证下标范围一致
+	for len(centroids) < k {
+		centroids = append(centroids, centroids[0])
+	}
+	return assignments, centroids
+}
+
+// extractTerms 提取词语：拉丁字母/数字按单词（小写，至少 3 个字符，去除停用词），CJK 文字按相邻二元组
+func extractTerms(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) >= 3 {
+			if w := strings.ToLower(string(word)); !clusterStopWords[w] {
+				terms = append(terms, w)
+			}
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+// topTerms 按 词频 × log(1 + 聚类数/包含该词的聚类数) 选出代表性词语
+func topTerms(counts map[string]int, clusterFreq map[string]int, clusterCount int) []string {
+	type scored struct {
+		term  string
+		score float64
+	}
+	candidates := make([]scored, 0, len(counts))
+	for term, count := range counts {
+		idf := math.Log(1 + float64(clusterCount)/float64(clusterFreq[term]))
+		candidates = append(candidates, scored{term, float64(count) * idf})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].term < candidates[j].term
+	})
+
+	terms := make([]string, 0, clusterTermCount)
+	for _, c := range candidates {
+		if len(terms) == clusterTermCount {
+			break
+		}
+		terms = append(terms, c.term)
+	}
+	return terms
+}
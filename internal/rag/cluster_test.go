@@ -0,0 +1,108 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetTopicClusters(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+	service := &Service{
+		paths:      indexer.paths,
+		store:      indexer.store,
+		embedder:   &recordingEmbedder{},
+		docRepo:    indexer.docRepo,
+		docStorage: docStorage,
+	}
+
+	// 两组方向明显不同的文档
+	docs := []struct {
+		title   string
+		content string
+		vec     []float32
+	}{
+		{"Golang concurrency", "goroutine channel scheduler", []float32{1, 0.1, 0}},
+		{"Golang generics", "goroutine type parameters", []float32{1, 0, 0.1}},
+		{"Golang testing", "goroutine benchmark table", []float32{0.9, 0.1, 0.1}},
+		{"Pasta recipes", "tomato basil garlic", []float32{0, 1, 0.1}},
+		{"Bread baking", "tomato flour yeast", []float32{0.1, 1, 0}},
+	}
+	ids := make(map[string]string)
+	for _, d := range docs {
+		meta, err := indexer.docRepo.Create(d.title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[meta.ID] = d.title
+		if err := indexer.store.Upsert(&BlockVector{
+			ID: meta.ID + ":b1", SourceType: "document", DocID: meta.ID,
+			Content: d.content, BlockType: "paragraph", Embedding: d.vec,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clusters, err := service.GetTopicClusters(2)
+	if err != nil {
+		t.Fatalf("GetTopicClusters failed: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %d", len(clusters))
+	}
+	if len(clusters[0].Docs) != 3 || len(clusters[1].Docs) != 2 {
+		t.Fatalf("Expected clusters of 3 and 2 docs, got %d and %d", len(clusters[0].Docs), len(clusters[1].Docs))
+	}
+	for _, member := range clusters[0].Docs {
+		if !strings.HasPrefix(ids[member.DocID], "Golang") {
+			t.Errorf("Unexpected member %q in Golang cluster", ids[member.DocID])
+		}
+	}
+	if len(clusters[0].Terms) == 0 || clusters[0].Terms[0] != "golang" {
+		t.Errorf("Expected top term 'golang', got %v", clusters[0].Terms)
+	}
+	if !containsTerm(clusters[1].Terms, "tomato") {
+		t.Errorf("Expected 'tomato' among terms, got %v", clusters[1].Terms)
+	}
+
+	// 重命名不使缓存失效，但返回最新标题
+	cached := service.clusterCache.clusters
+	docID := clusters[1].Docs[0].DocID
+	if err := indexer.docRepo.Rename(docID, "Renamed"); err != nil {
+		t.Fatal(err)
+	}
+	again, err := service.GetTopicClusters(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if &service.clusterCache.clusters[0] != &cached[0] {
+		t.Error("Expected cached clusters to be reused")
+	}
+	if again[1].Docs[0].Title != "Renamed" {
+		t.Errorf("Expected current title, got %q", again[1].Docs[0].Title)
+	}
+
+	// 向量变化后重新计算
+	if err := indexer.store.DeleteByDocID(docID); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := service.GetTopicClusters(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, c := range updated {
+		total += len(c.Docs)
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 clustered docs after deletion, got %d", total)
+	}
+}
+
+func containsTerm(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+	return false
+}
@@ -1,22 +1,78 @@
 package rag
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"notion-lite/internal/opengraph"
 	"notion-lite/internal/utils"
 )
 
 // EmbeddingConfig 嵌入模型配置
 type EmbeddingConfig struct {
-	Provider     string `json:"provider"`     // "ollama" | "openai"
-	BaseURL      string `json:"baseUrl"`      // API 地址
-	Model        string `json:"model"`        // 模型名称
-	APIKey       string `json:"apiKey"`       // API 密钥（OpenAI 需要）
-	MaxChunkSize int    `json:"maxChunkSize"` // 长块分割阈值，默认 800
-	Overlap      int    `json:"overlap"`      // 重叠字符数，默认 100
+	Provider            string         `json:"provider"`                  // "ollama" | "openai"
+	BaseURL             string         `json:"baseUrl"`                   // API 地址
+	Model               string         `json:"model"`                     // 模型名称
+	APIKey              string         `json:"apiKey"`                    // API 密钥（OpenAI 需要）
+	MaxChunkSize        int            `json:"maxChunkSize"`              // 长块分割阈值，默认 800
+	Overlap             int            `json:"overlap"`                   // 重叠字符数，默认 100
+	ShortBlockThreshold int            `json:"shortBlockThreshold"`       // 短块阈值，低于此长度的相邻块会被合并，默认 150
+	MaxMergedLength     int            `json:"maxMergedLength"`           // 短块合并后的最大长度，默认 600
+	ChunkUnit           string         `json:"chunkUnit"`                 // 分块长度单位："chars"（默认）或 "tokens"
+	SentenceDelimiters  string         `json:"sentenceDelimiters"`        // 长文本分句使用的分隔符集合，空表示默认（中英文、阿拉伯文、天城文标点）
+	FetchTimeout        time.Duration  `json:"-"`                         // 书签网页抓取超时，0 表示默认；JSON 中以秒表示（fetchTimeout）
+	UsePrefixes         bool           `json:"usePrefixes"`               // 是否为查询/文档添加 "query: "/"passage: " 前缀（e5/bge 等模型）
+	AutoReindex         bool           `json:"autoReindex"`               // 是否启用后台定期重建过期文档索引
+	AutoReindexInterval int            `json:"autoReindexInterval"`       // 后台重建间隔（分钟），默认 30
+	ReindexWorkers      int            `json:"reindexWorkers"`            // 全量重建并发文档数，0 表示默认 2
+	ExtractWorkers      int            `json:"extractWorkers"`            // 文件夹索引的文本提取并发数，0 表示使用 GOMAXPROCS
+	PDFOCR              bool           `json:"pdfOcr"`                    // 扫描版 PDF 无文本层时使用 tesseract OCR（较慢）
+	MaxExtractBytes     int            `json:"maxExtractBytes"`           // 单个文件最多提取的文本字节数，超出部分不索引，0 表示默认 4MB
+	CSVMaxRows          int            `json:"csvMaxRows"`                // CSV/TSV 文件最多提取的数据行数，0 表示默认 10000
+	MMRLambda           float64        `json:"mmrLambda"`                 // 多样性重排的相关性权重（0~1），默认 0.7
+	EmbedTitles         bool           `json:"embedTitles"`               // 是否将文档标题作为独立 chunk 索引
+	TitleBoost          float64        `json:"titleBoost"`                // 标题 chunk 在文档搜索中的加分，默认 0.1，0 表示不加分
+	ExcludedBlockTypes  []string       `json:"excludedBlockTypes"`        // 不参与索引的块类型（如 codeBlock、divider），默认不排除
+	ModelDimensions     map[string]int `json:"modelDimensions,omitempty"` // 各模型（provider:model）探测到的向量维度缓存
+	RetryConfig                        // 嵌入请求重试配置（字段平铺到 JSON 顶层）
+	PreprocessConfig                   // 嵌入前文本预处理配置（字段平铺到 JSON 顶层）
 }
 
+// RetryConfig 嵌入请求重试配置
+type RetryConfig struct {
+	MaxAttempts int     `json:"retryMaxAttempts"` // 最大尝试次数（含首次），默认 3
+	BaseDelayMs int     `json:"retryBaseDelayMs"` // 指数退避基础延迟（毫秒），默认 500
+	Jitter      float64 `json:"retryJitter"`      // 随机抖动比例（0~1），默认 0.2
+}
+
+// DefaultRetryConfig 默认重试配置
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 3,
+	BaseDelayMs: 500,
+	Jitter:      0.2,
+}
+
+// withDefaults 填充未设置的重试参数
+func (r RetryConfig) withDefaults() RetryConfig {
+	if r.MaxAttempts <= 0 {
+		r.MaxAttempts = DefaultRetryConfig.MaxAttempts
+	}
+	if r.BaseDelayMs <= 0 {
+		r.BaseDelayMs = DefaultRetryConfig.BaseDelayMs
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		r.Jitter = DefaultRetryConfig.Jitter
+	}
+	return r
+}
+
+// DefaultAutoReindexInterval 默认后台重建间隔
+const DefaultAutoReindexInterval = 30 * time.Minute
+
 // DefaultConfig 默认配置（Ollama 本地）
 var DefaultConfig = EmbeddingConfig{
 	Provider:     "ollama",
@@ -24,9 +80,13 @@ var DefaultConfig = EmbeddingConfig{
 	Model:        "nomic-embed-text",
 	MaxChunkSize: 800,
 	Overlap:      100,
+	TitleBoost:   DefaultTitleBoost,
+	RetryConfig:  DefaultRetryConfig,
 }
 
 // GetChunkConfig 获取分块配置
+// 未设置的参数使用默认值，并校正不合理的组合：重叠必须小于分割阈值，
+// 合并后长度不超过分割阈值，短块阈值必须小于合并后长度
 func (c *EmbeddingConfig) GetChunkConfig() ChunkConfig {
 	maxSize := c.MaxChunkSize
 	if maxSize <= 0 {
@@ -36,12 +96,79 @@ func (c *EmbeddingConfig) GetChunkConfig() ChunkConfig {
 	if overlap <= 0 {
 		overlap = DefaultChunkConfig.Overlap
 	}
+	if overlap >= maxSize {
+		overlap = maxSize / 8
+	}
+	maxMerged := c.MaxMergedLength
+	if maxMerged <= 0 {
+		maxMerged = DefaultChunkConfig.MaxMergedLength
+	}
+	if maxMerged > maxSize {
+		maxMerged = maxSize
+	}
+	shortThreshold := c.ShortBlockThreshold
+	if shortThreshold <= 0 {
+		shortThreshold = DefaultChunkConfig.ShortBlockThreshold
+	}
+	if shortThreshold >= maxMerged {
+		shortThreshold = maxMerged / 4
+	}
+	unit := ChunkUnitChars
+	if c.ChunkUnit == ChunkUnitTokens {
+		unit = ChunkUnitTokens
+	}
 	return ChunkConfig{
-		MaxChunkSize: maxSize,
-		Overlap:      overlap,
+		MaxChunkSize:        maxSize,
+		Overlap:             overlap,
+		ShortBlockThreshold: shortThreshold,
+		MaxMergedLength:     maxMerged,
+		Unit:                unit,
+		SentenceDelimiters:  c.SentenceDelimiters,
+		ExcludedTypes:       excludedTypeSet(c.ExcludedBlockTypes),
 	}
 }
 
+// excludedTypeSet 将排除的块类型列表转换为集合，列表为空时返回 nil
+func excludedTypeSet(types []string) map[string]bool {
+	var set map[string]bool
+	for _, t := range types {
+		if t = strings.TrimSpace(t); t != "" {
+			if set == nil {
+				set = make(map[string]bool)
+			}
+			set[t] = true
+		}
+	}
+	return set
+}
+
+// excludedTypesIdentity 排除块类型的规范化标识（去重排序后以逗号连接），用于检测配置变更
+func excludedTypesIdentity(types []string) string {
+	set := excludedTypeSet(types)
+	sorted := make([]string, 0, len(set))
+	for t := range set {
+		sorted = append(sorted, t)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// GetFetchTimeout 获取书签抓取超时时间
+func (c *EmbeddingConfig) GetFetchTimeout() time.Duration {
+	if c.FetchTimeout <= 0 {
+		return opengraph.DefaultFetchTimeout
+	}
+	return c.FetchTimeout
+}
+
+// GetAutoReindexInterval 获取后台重建间隔
+func (c *EmbeddingConfig) GetAutoReindexInterval() time.Duration {
+	if c.AutoReindexInterval <= 0 {
+		return DefaultAutoReindexInterval
+	}
+	return time.Duration(c.AutoReindexInterval) * time.Minute
+}
+
 // LoadConfig 从文件加载配置
 func LoadConfig(paths *utils.PathBuilder) (*EmbeddingConfig, error) {
 	path := paths.RAGConfig()
@@ -54,13 +181,97 @@ func LoadConfig(paths *utils.PathBuilder) (*EmbeddingConfig, error) {
 		}
 		return nil, err
 	}
-	var config EmbeddingConfig
+	// 旧配置文件没有 titleBoost 时沿用默认加分（显式的 0 表示关闭）
+	config := EmbeddingConfig{TitleBoost: DefaultTitleBoost}
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
 	return &config, nil
 }
 
+// embeddingConfigJSON 去掉方法的 EmbeddingConfig，供 MarshalJSON/UnmarshalJSON 使用默认编码（避免递归）
+type embeddingConfigJSON EmbeddingConfig
+
+// MarshalJSON 抓取超时在 JSON（配置文件和前端）中以整数秒表示
+func (c EmbeddingConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		embeddingConfigJSON
+		FetchTimeout int `json:"fetchTimeout"`
+	}{embeddingConfigJSON(c), int(c.FetchTimeout / time.Second)})
+}
+
+// UnmarshalJSON 将 JSON 中以秒表示的抓取超时转换为 time.Duration
+// 已废弃的 maxRetries（重试次数，不含首次请求）迁移为 retryMaxAttempts = maxRetries + 1；
+// 两者同时存在时 maxRetries > 0 优先（与旧版本行为一致），保存后只写出 retryMaxAttempts
+func (c *EmbeddingConfig) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*embeddingConfigJSON
+		FetchTimeout int `json:"fetchTimeout"`
+		MaxRetries   int `json:"maxRetries"` // 已废弃
+	}{embeddingConfigJSON: (*embeddingConfigJSON)(c), FetchTimeout: int(c.FetchTimeout / time.Second)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	c.FetchTimeout = time.Duration(aux.FetchTimeout) * time.Second
+	if aux.MaxRetries > 0 {
+		c.MaxAttempts = aux.MaxRetries + 1
+	}
+	return nil
+}
+
+// MergeConfigChanges 将 edited 相对 original 修改过的字段合并到 current，返回合并后的配置
+// 设置面板打开期间后端可能更新了配置（如维度缓存），面板中未修改的旧值不应覆盖这些更新
+// 按 JSON 字段比较，平铺的 RetryConfig、PreprocessConfig 字段分别比较
+func MergeConfigChanges(current, original, edited *EmbeddingConfig) (*EmbeddingConfig, error) {
+	currentFields, err := configFields(current)
+	if err != nil {
+		return nil, err
+	}
+	originalFields, err := configFields(original)
+	if err != nil {
+		return nil, err
+	}
+	editedFields, err := configFields(edited)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range editedFields {
+		if !bytes.Equal(originalFields[key], value) {
+			currentFields[key] = value
+		}
+	}
+	// omitempty 字段被清空时只出现在 original 中
+	for key := range originalFields {
+		if _, ok := editedFields[key]; !ok {
+			delete(currentFields, key)
+		}
+	}
+
+	data, err := json.Marshal(currentFields)
+	if err != nil {
+		return nil, err
+	}
+	var merged EmbeddingConfig
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// configFields 将配置按 JSON 字段拆分
+func configFields(config *EmbeddingConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
 // SaveConfig 保存配置到文件
 func SaveConfig(paths *utils.PathBuilder, config *EmbeddingConfig) error {
 	path := paths.RAGConfig()
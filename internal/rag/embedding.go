@@ -2,10 +2,13 @@ package rag
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -34,27 +37,120 @@ func IsEmbeddingServiceError(err error) (*EmbeddingServiceError, bool) {
 	return nil, false
 }
 
+// EmbeddingRequestError 嵌入请求未得到服务响应（连接被拒绝、超时等网络错误），可通过 errors.As 识别
+type EmbeddingRequestError struct {
+	Provider string
+	Err      error
+}
+
+func (e *EmbeddingRequestError) Error() string {
+	return fmt.Sprintf("%s request failed: %v", e.Provider, e.Err)
+}
+
+func (e *EmbeddingRequestError) Unwrap() error {
+	return e.Err
+}
+
+// EmbedKind 嵌入输入类型（部分模型对查询和文档使用不同前缀效果更好）
+type EmbedKind int
+
+const (
+	// EmbedKindDocument 文档/段落内容
+	EmbedKindDocument EmbedKind = iota
+	// EmbedKindQuery 检索查询
+	EmbedKindQuery
+)
+
+// prepareInput 对嵌入输入进行预处理并添加类型前缀
+func prepareInput(text string, kind EmbedKind, preprocess PreprocessConfig, usePrefixes bool) string {
+	text = preprocess.Apply(text)
+	if usePrefixes {
+		text = kind.prefix() + text
+	}
+	return text
+}
+
+// prefix 返回该类型对应的输入前缀（e5/bge 风格）
+func (k EmbedKind) prefix() string {
+	if k == EmbedKindQuery {
+		return "query: "
+	}
+	return "passage: "
+}
+
 // EmbeddingClient 嵌入向量生成接口
 type EmbeddingClient interface {
+	// Embed 生成文档类型的嵌入向量，等价于 EmbedWithType(text, EmbedKindDocument)
 	Embed(text string) ([]float32, error)
+	// EmbedWithType 按输入类型生成嵌入向量
+	EmbedWithType(text string, kind EmbedKind) ([]float32, error)
 	EmbedBatch(texts []string) ([][]float32, error)
+	// Dimension 返回已探测的向量维度（未探测时为 0，见 ProbeDimension）
 	Dimension() int
-	// DetectDimension 通过实际嵌入检测维度（用于未知模型）
-	DetectDimension() (int, error)
+	// Ping 检查服务可达且配置的模型可用（不重试）
+	Ping() error
 }
 
+// ErrModelNotFound 服务可达但配置的模型不存在
+var ErrModelNotFound = errors.New("embedding model not found")
+
 // NewEmbeddingClient 根据配置创建客户端
 func NewEmbeddingClient(config *EmbeddingConfig) (EmbeddingClient, error) {
+	return NewEmbeddingClientWithContext(context.Background(), config)
+}
+
+// NewEmbeddingClientWithContext 根据配置创建客户端，ctx 取消时中止请求与重试
+func NewEmbeddingClientWithContext(ctx context.Context, config *EmbeddingConfig) (EmbeddingClient, error) {
 	switch config.Provider {
 	case "ollama":
-		return NewOllamaClient(config.BaseURL, config.Model), nil
+		client := NewOllamaClient(config.BaseURL, config.Model)
+		client.ctx = ctx
+		client.retry = config.RetryConfig
+		client.usePrefixes = config.UsePrefixes
+		client.preprocess = config.PreprocessConfig
+		return client, nil
 	case "openai":
-		return NewOpenAIClient(config.BaseURL, config.Model, config.APIKey), nil
+		client := NewOpenAIClient(config.BaseURL, config.Model, config.APIKey)
+		client.ctx = ctx
+		client.retry = config.RetryConfig
+		client.usePrefixes = config.UsePrefixes
+		client.preprocess = config.PreprocessConfig
+		return client, nil
 	default:
 		return nil, fmt.Errorf("unknown provider: %s", config.Provider)
 	}
 }
 
+// dimensionProbeText 维度探测使用的文本
+const dimensionProbeText = "dimension probe"
+
+// dimensionCache 可缓存探测维度的客户端，ProbeDimension 探测后写入，之后由 Dimension 返回
+type dimensionCache interface {
+	setDimension(dim int)
+}
+
+// ProbeDimension 通过一次探测嵌入确定权威维度，并缓存到客户端
+// 客户端报告的维度与实际返回向量长度不一致时，记录警告并以探测结果为准
+func ProbeDimension(client EmbeddingClient) (int, error) {
+	reported := client.Dimension()
+
+	vec, err := client.EmbedWithType(dimensionProbeText, EmbedKindDocument)
+	if err != nil {
+		return 0, err
+	}
+	if len(vec) == 0 {
+		return 0, fmt.Errorf("embedding service returned an empty vector")
+	}
+
+	if reported > 0 && reported != len(vec) {
+		fmt.Printf("⚠️ [RAG] Reported dimension %d does not match probed dimension %d, using probed value\n", reported, len(vec))
+	}
+	if cache, ok := client.(dimensionCache); ok {
+		cache.setDimension(len(vec))
+	}
+	return len(vec), nil
+}
+
 // TestConnectionResult 连接测试结果
 type TestConnectionResult struct {
 	Success   bool   `json:"success"`
@@ -69,7 +165,7 @@ func TestConnection(config *EmbeddingConfig) TestConnectionResult {
 		return TestConnectionResult{Success: false, Error: err.Error()}
 	}
 
-	dim, err := client.DetectDimension()
+	dim, err := ProbeDimension(client)
 	if err != nil {
 		return TestConnectionResult{Success: false, Error: err.Error()}
 	}
@@ -77,6 +173,42 @@ func TestConnection(config *EmbeddingConfig) TestConnectionResult {
 	return TestConnectionResult{Success: true, Dimension: dim}
 }
 
+// ConnectionStatus 嵌入服务健康检查结果
+type ConnectionStatus struct {
+	Reachable  bool   `json:"reachable"`  // 服务是否有响应
+	ModelFound bool   `json:"modelFound"` // 配置的模型是否可用
+	Dimension  int    `json:"dimension"`  // 探测到的向量维度
+	LatencyMs  int64  `json:"latencyMs"`  // Ping 耗时（毫秒）
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckConnection 对客户端执行 Ping 并探测维度，返回结构化的健康状态
+func CheckConnection(client EmbeddingClient) ConnectionStatus {
+	start := time.Now()
+	err := client.Ping()
+	status := ConnectionStatus{LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		status.Error = err.Error()
+		// 服务返回了 HTTP 响应（如 401、模型不存在）说明网络可达
+		if errors.Is(err, ErrModelNotFound) {
+			status.Reachable = true
+		} else if _, ok := IsEmbeddingServiceError(err); ok {
+			status.Reachable = true
+		}
+		return status
+	}
+	status.Reachable = true
+	status.ModelFound = true
+
+	dim, err := ProbeDimension(client)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Dimension = dim
+	return status
+}
+
 // ========== Ollama 实现 ==========
 
 // OllamaClient Ollama 嵌入客户端
@@ -85,6 +217,10 @@ type OllamaClient struct {
 	model       string
 	client      *http.Client
 	detectedDim int
+	ctx         context.Context
+	retry       RetryConfig
+	usePrefixes bool
+	preprocess  PreprocessConfig
 }
 
 // NewOllamaClient 创建 Ollama 客户端
@@ -95,25 +231,47 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		ctx:   context.Background(),
+		retry: DefaultRetryConfig,
 	}
 }
 
 // Embed 生成单个文本的嵌入向量
 func (c *OllamaClient) Embed(text string) ([]float32, error) {
+	return c.EmbedWithType(text, EmbedKindDocument)
+}
+
+// EmbedWithType 按输入类型生成嵌入向量（失败时按重试配置退避重试）
+func (c *OllamaClient) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	text = prepareInput(text, kind, c.preprocess, c.usePrefixes)
+
+	var embedding []float32
+	err := withRetry(c.ctx, c.retry, func() error {
+		var err error
+		embedding, err = c.embedOnce(text)
+		return err
+	})
+	return embedding, err
+}
+
+// embedOnce 发送单次嵌入请求
+func (c *OllamaClient) embedOnce(text string) ([]float32, error) {
 	reqBody := map[string]interface{}{
 		"model":  c.model,
 		"prompt": text,
 	}
 	body, _ := json.Marshal(reqBody)
 
-	resp, err := c.client.Post(
-		c.baseURL+"/api/embeddings",
-		"application/json",
-		bytes.NewReader(body),
-	)
+	req, err := http.NewRequestWithContext(c.ctx, "POST", c.baseURL+"/api/embeddings", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("ollama request failed: %w", err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, &EmbeddingRequestError{Provider: "ollama", Err: err}
+	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
@@ -139,15 +297,40 @@ func (c *OllamaClient) Embed(text string) ([]float32, error) {
 	return result.Embedding, nil
 }
 
-// EmbedBatch 批量生成嵌入向量（Ollama 不支持批量，逐个处理）
+// ollamaBatchWorkers Ollama 批量嵌入的并发请求数
+const ollamaBatchWorkers = 4
+
+// EmbedBatch 批量生成嵌入向量（Ollama 不支持批量，并发逐个处理，结果保持输入顺序）
 func (c *OllamaClient) EmbedBatch(texts []string) ([][]float32, error) {
 	results := make([][]float32, len(texts))
-	for i, text := range texts {
-		emb, err := c.Embed(text)
+	errs := make([]error, len(texts))
+
+	// Ollama 无原生批量接口，使用小规模并发逐条请求
+	workers := ollamaBatchWorkers
+	if len(texts) < workers {
+		workers = len(texts)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = c.Embed(texts[i])
+			}
+		}()
+	}
+	for i := range texts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		results[i] = emb
 	}
 	return results, nil
 }
@@ -157,14 +340,52 @@ func (c *OllamaClient) Dimension() int {
 	return c.detectedDim
 }
 
-// DetectDimension 通过实际嵌入检测维度
-func (c *OllamaClient) DetectDimension() (int, error) {
-	vec, err := c.Embed("test")
+// setDimension 缓存 ProbeDimension 探测到的维度
+func (c *OllamaClient) setDimension(dim int) {
+	c.detectedDim = dim
+}
+
+// Ping 请求 /api/tags 确认服务可达，并检查配置的模型已拉取
+func (c *OllamaClient) Ping() error {
+	req, err := http.NewRequestWithContext(c.ctx, "GET", c.baseURL+"/api/tags", nil)
 	if err != nil {
-		return 0, err
+		return fmt.Errorf("ollama request failed: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &EmbeddingRequestError{Provider: "ollama", Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return &EmbeddingServiceError{
+			Provider:   "ollama",
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("ollama returned status %d", resp.StatusCode),
+		}
 	}
-	c.detectedDim = len(vec)
-	return c.detectedDim, nil
+
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return &EmbeddingServiceError{
+			Provider:   "ollama",
+			StatusCode: -1,
+			Message:    fmt.Sprintf("failed to decode response: %v", err),
+		}
+	}
+
+	// 未指定 tag 的模型名等价于 :latest
+	for _, m := range result.Models {
+		if m.Name == c.model || strings.TrimSuffix(m.Name, ":latest") == c.model {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrModelNotFound, c.model)
 }
 
 // ========== OpenAI 兼容实现 ==========
@@ -176,6 +397,10 @@ type OpenAIClient struct {
 	apiKey      string
 	client      *http.Client
 	detectedDim int
+	ctx         context.Context
+	retry       RetryConfig
+	usePrefixes bool
+	preprocess  PreprocessConfig
 }
 
 // NewOpenAIClient 创建 OpenAI 兼容客户端
@@ -190,33 +415,68 @@ func NewOpenAIClient(baseURL, model, apiKey string) *OpenAIClient {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		ctx:   context.Background(),
+		retry: DefaultRetryConfig,
 	}
 }
 
 // Embed 生成单个文本的嵌入向量
 func (c *OpenAIClient) Embed(text string) ([]float32, error) {
-	embeddings, err := c.EmbedBatch([]string{text})
+	return c.EmbedWithType(text, EmbedKindDocument)
+}
+
+// EmbedWithType 按输入类型生成嵌入向量
+func (c *OpenAIClient) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	embeddings, err := c.embedBatch([]string{text}, kind)
 	if err != nil {
 		return nil, err
 	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("openai returned no embeddings")
+	}
 	return embeddings[0], nil
 }
 
-// EmbedBatch 批量生成嵌入向量
+// EmbedBatch 批量生成文档类型的嵌入向量
 func (c *OpenAIClient) EmbedBatch(texts []string) ([][]float32, error) {
+	return c.embedBatch(texts, EmbedKindDocument)
+}
+
+// embedBatch 批量生成嵌入向量（失败时按重试配置退避重试）
+func (c *OpenAIClient) embedBatch(texts []string, kind EmbedKind) ([][]float32, error) {
+	prepared := make([]string, len(texts))
+	for i, text := range texts {
+		prepared[i] = prepareInput(text, kind, c.preprocess, c.usePrefixes)
+	}
+	texts = prepared
+
+	var embeddings [][]float32
+	err := withRetry(c.ctx, c.retry, func() error {
+		var err error
+		embeddings, err = c.embedBatchOnce(texts)
+		return err
+	})
+	return embeddings, err
+}
+
+// embedBatchOnce 发送单次批量嵌入请求
+func (c *OpenAIClient) embedBatchOnce(texts []string) ([][]float32, error) {
 	reqBody := map[string]interface{}{
 		"model": c.model,
 		"input": texts,
 	}
 	body, _ := json.Marshal(reqBody)
 
-	req, _ := http.NewRequest("POST", c.baseURL+"/embeddings", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(c.ctx, "POST", c.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("openai request failed: %w", err)
+		return nil, &EmbeddingRequestError{Provider: "openai", Err: err}
 	}
 	defer func() { _ = resp.Body.Close() }()
 
@@ -253,12 +513,22 @@ func (c *OpenAIClient) Dimension() int {
 	return c.detectedDim
 }
 
-// DetectDimension 通过实际嵌入检测维度
-func (c *OpenAIClient) DetectDimension() (int, error) {
-	vec, err := c.Embed("test")
-	if err != nil {
-		return 0, err
+// setDimension 缓存 ProbeDimension 探测到的维度
+func (c *OpenAIClient) setDimension(dim int) {
+	c.detectedDim = dim
+}
+
+// pingProbeText Ping 时嵌入的单 token 文本
+const pingProbeText = "ping"
+
+// Ping 嵌入一个单 token 的探测文本，验证地址、API Key 和模型
+// OpenAI 兼容服务对不存在的模型返回 404
+func (c *OpenAIClient) Ping() error {
+	if _, err := c.embedBatchOnce([]string{pingProbeText}); err != nil {
+		if svcErr, ok := IsEmbeddingServiceError(err); ok && svcErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("%w: %s", ErrModelNotFound, c.model)
+		}
+		return err
 	}
-	c.detectedDim = len(vec)
-	return c.detectedDim, nil
+	return nil
 }
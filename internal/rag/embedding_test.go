@@ -0,0 +1,491 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+func TestOllamaClient_RetryThenSucceed(t *testing.T) {
+	// 前两次返回 500，第三次成功
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"embedding": []float32{0.1, 0.2, 0.3},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewEmbeddingClient(&EmbeddingConfig{
+		Provider:    "ollama",
+		BaseURL:     server.URL,
+		Model:       "test",
+		RetryConfig: RetryConfig{MaxAttempts: 3, BaseDelayMs: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	vec, err := client.Embed("hello")
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if len(vec) != 3 {
+		t.Errorf("Expected 3-dim vector, got %d", len(vec))
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+}
+
+func TestOpenAIClient_NoRetryOnClientError(t *testing.T) {
+	// 401 属于配置错误，不应重试
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{
+		Provider:    "openai",
+		BaseURL:     server.URL,
+		Model:       "test",
+		RetryConfig: RetryConfig{MaxAttempts: 3, BaseDelayMs: 1},
+	})
+
+	if _, err := client.Embed("hello"); err == nil {
+		t.Fatal("Expected error for 401 response")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 request, got %d", got)
+	}
+}
+
+func TestEmbeddingConfig_LegacyMaxRetries(t *testing.T) {
+	// 已废弃的 maxRetries 迁移为 retryMaxAttempts（首次请求 + 4 次重试），且优先于同时存在的 retryMaxAttempts
+	var config EmbeddingConfig
+	if err := json.Unmarshal([]byte(`{"maxRetries": 4, "retryMaxAttempts": 2}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxAttempts != 5 {
+		t.Errorf("Expected 5 attempts migrated from maxRetries, got %d", config.MaxAttempts)
+	}
+
+	// maxRetries 为 0（旧版默认值）时保留 retryMaxAttempts
+	config = EmbeddingConfig{}
+	if err := json.Unmarshal([]byte(`{"maxRetries": 0, "retryMaxAttempts": 2}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.MaxAttempts != 2 {
+		t.Errorf("Expected retryMaxAttempts to be kept, got %d", config.MaxAttempts)
+	}
+
+	// 保存时只写出 retryMaxAttempts
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "maxRetries") {
+		t.Errorf("Expected maxRetries to be dropped on save, got %s", data)
+	}
+}
+
+func TestOllamaClient_NoRetryOnDecodeError(t *testing.T) {
+	// 错误的端点返回 200 和 HTML，属于配置错误，不应重试
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte("<html>not an api</html>"))
+	}))
+	defer server.Close()
+
+	client, err := NewEmbeddingClient(&EmbeddingConfig{
+		Provider:    "ollama",
+		BaseURL:     server.URL,
+		Model:       "test",
+		RetryConfig: RetryConfig{MaxAttempts: 3, BaseDelayMs: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.Embed("hello"); err == nil {
+		t.Fatal("Expected a decode error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 request, got %d", got)
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"network error", &EmbeddingRequestError{Provider: "ollama", Err: errors.New("connection refused")}, true},
+		{"wrapped network error", fmt.Errorf("embed: %w", &EmbeddingRequestError{Provider: "openai", Err: errors.New("timeout")}), true},
+		{"rate limited", &EmbeddingServiceError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &EmbeddingServiceError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"bad request", &EmbeddingServiceError{StatusCode: http.StatusBadRequest}, false},
+		{"decode error", &EmbeddingServiceError{StatusCode: -1}, false},
+		{"other error", errors.New("invalid request"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableError(tt.err); got != tt.want {
+				t.Errorf("isRetryableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbed_RetryCancelledByContext(t *testing.T) {
+	// 上下文取消后应立即停止重试
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client, _ := NewEmbeddingClientWithContext(ctx, &EmbeddingConfig{
+		Provider:    "ollama",
+		BaseURL:     server.URL,
+		Model:       "test",
+		RetryConfig: RetryConfig{MaxAttempts: 5, BaseDelayMs: 10000},
+	})
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := client.Embed("hello"); err == nil {
+		t.Fatal("Expected error after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Retry loop did not respect context cancellation (took %s)", elapsed)
+	}
+}
+
+func TestOllamaClient_EmbedWithTypePrefixes(t *testing.T) {
+	// 开启 UsePrefixes 时，查询和文档分别添加 query:/passage: 前缀
+	var prompts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Prompt)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"embedding": []float32{0.1},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{
+		Provider:    "ollama",
+		BaseURL:     server.URL,
+		Model:       "test",
+		UsePrefixes: true,
+	})
+
+	_, _ = client.EmbedWithType("hello", EmbedKindQuery)
+	_, _ = client.Embed("world")
+
+	expected := []string{"query: hello", "passage: world"}
+	if len(prompts) != len(expected) {
+		t.Fatalf("Expected %d requests, got %d", len(expected), len(prompts))
+	}
+	for i, want := range expected {
+		if prompts[i] != want {
+			t.Errorf("Request %d: expected prompt %q, got %q", i, want, prompts[i])
+		}
+	}
+}
+
+// mismatchedEmbedder 报告的维度与实际返回向量长度不一致的测试替身
+type mismatchedEmbedder struct {
+	recordingEmbedder
+}
+
+func (e *mismatchedEmbedder) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	return make([]float32, 12), nil
+}
+
+func (e *mismatchedEmbedder) Dimension() int { return 10 }
+
+func TestProbeDimension_ReconcilesMismatch(t *testing.T) {
+	dim, err := ProbeDimension(&mismatchedEmbedder{})
+	if err != nil {
+		t.Fatalf("ProbeDimension failed: %v", err)
+	}
+	if dim != 12 {
+		t.Errorf("Expected probed dimension 12, got %d", dim)
+	}
+}
+
+func TestProbeDimension_CachesOnClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"embedding": []float32{0.1, 0.2, 0.3, 0.4},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewEmbeddingClient(&EmbeddingConfig{Provider: "ollama", BaseURL: server.URL, Model: "test"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if client.Dimension() != 0 {
+		t.Fatalf("Expected no dimension before probing, got %d", client.Dimension())
+	}
+	dim, err := ProbeDimension(client)
+	if err != nil {
+		t.Fatalf("ProbeDimension failed: %v", err)
+	}
+	if dim != 4 || client.Dimension() != 4 {
+		t.Errorf("Expected probed dimension 4 cached on the client, got %d (cached %d)", dim, client.Dimension())
+	}
+}
+
+// countingProbeEmbedder 记录探测次数的测试替身
+type countingProbeEmbedder struct {
+	recordingEmbedder
+	dim    int
+	probes int
+}
+
+func (e *countingProbeEmbedder) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	e.probes++
+	return make([]float32, e.dim), nil
+}
+
+func TestResolveDimension_CachedInConfig(t *testing.T) {
+	paths := utils.NewPathBuilder(t.TempDir())
+	service := &Service{paths: paths}
+	config := &EmbeddingConfig{Provider: "ollama", Model: "custom-embed"}
+	if err := SaveConfig(paths, config); err != nil {
+		t.Fatal(err)
+	}
+	embedder := &countingProbeEmbedder{dim: 768}
+
+	dim, err := service.resolveDimension(config, embedder)
+	if err != nil || dim != 768 || embedder.probes != 1 {
+		t.Fatalf("Expected first resolve to probe 768, got dim=%d probes=%d err=%v", dim, embedder.probes, err)
+	}
+
+	// 再次初始化时读取缓存，不再探测
+	saved, err := LoadConfig(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := modelIdentity(config)
+	if saved.ModelDimensions[identity] != 768 {
+		t.Fatalf("Expected cached dimension, got %v", saved.ModelDimensions)
+	}
+	dim, err = service.resolveDimension(saved, embedder)
+	if err != nil || dim != 768 || embedder.probes != 1 {
+		t.Fatalf("Expected cached 768 without probing, got dim=%d probes=%d err=%v", dim, embedder.probes, err)
+	}
+
+	// 后续探测结果不一致时更新缓存
+	service.dimension, service.model = 768, identity
+	if !service.reconcileDimension(1024) {
+		t.Fatal("Expected mismatch to be reported")
+	}
+	saved, _ = LoadConfig(paths)
+	if saved.ModelDimensions[identity] != 1024 {
+		t.Errorf("Expected cache updated to 1024, got %v", saved.ModelDimensions)
+	}
+}
+
+func TestMergeConfigChanges_KeepsBackendUpdates(t *testing.T) {
+	original := DefaultConfig
+	original.TitleBoost = 0.1
+
+	// 面板打开期间后端写入了维度缓存，并由其他入口修改了 MMRLambda
+	current := original
+	current.ModelDimensions = map[string]int{"ollama:nomic-embed-text@http://localhost:11434": 768}
+	current.MMRLambda = 0.5
+
+	// 用户只修改了模型和重试次数
+	edited := original
+	edited.Model = "bge-m3"
+	edited.MaxAttempts = 5
+
+	merged, err := MergeConfigChanges(&current, &original, &edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Model != "bge-m3" || merged.MaxAttempts != 5 {
+		t.Errorf("Expected edited fields to be saved, got model=%q attempts=%d", merged.Model, merged.MaxAttempts)
+	}
+	if merged.MMRLambda != 0.5 || len(merged.ModelDimensions) != 1 {
+		t.Errorf("Expected backend updates to be kept, got lambda=%v dimensions=%v", merged.MMRLambda, merged.ModelDimensions)
+	}
+	if merged.TitleBoost != 0.1 || merged.BaseURL != original.BaseURL {
+		t.Errorf("Expected unchanged fields to be kept, got %+v", merged)
+	}
+}
+
+func TestEmbeddingConfig_FetchTimeoutJSON(t *testing.T) {
+	var config EmbeddingConfig
+	if err := json.Unmarshal([]byte(`{"model": "bge-m3", "fetchTimeout": 15, "retryMaxAttempts": 2}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.FetchTimeout != 15*time.Second || config.GetFetchTimeout() != 15*time.Second {
+		t.Errorf("Expected 15s fetch timeout, got %v", config.FetchTimeout)
+	}
+	if config.Model != "bge-m3" || config.MaxAttempts != 2 {
+		t.Errorf("Expected other fields to decode, got model=%q attempts=%d", config.Model, config.MaxAttempts)
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["fetchTimeout"] != float64(15) || fields["retryMaxAttempts"] != float64(2) {
+		t.Errorf("Expected fetchTimeout in seconds and flattened retry fields, got %s", data)
+	}
+
+	if (&EmbeddingConfig{}).GetFetchTimeout() <= 0 {
+		t.Error("Expected a default fetch timeout when unset")
+	}
+}
+
+func TestIndexFailureError_Partial(t *testing.T) {
+	// 测试部分 chunk 失败时仍视为已索引，全部失败时视为失败
+	partial := &IndexFailureError{Failed: 1, Total: 3, Err: errors.New("timeout")}
+	if !IndexSucceeded(partial) {
+		t.Error("Expected partial failure to count as indexed")
+	}
+	if !strings.Contains(partial.Error(), "1 of 3") {
+		t.Errorf("Expected failure count in message, got %q", partial.Error())
+	}
+
+	total := &IndexFailureError{Failed: 2, Total: 2, Err: errors.New("timeout")}
+	if IndexSucceeded(total) {
+		t.Error("Expected total failure to count as failed")
+	}
+	if IndexSucceeded(errors.New("fetch failed")) {
+		t.Error("Expected plain error to count as failed")
+	}
+}
+
+func TestOllamaClient_EmbedBatchPreservesOrder(t *testing.T) {
+	// 并发请求下结果仍按输入顺序返回
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"embedding": []float32{float32(len(req.Prompt))},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{
+		Provider: "ollama",
+		BaseURL:  server.URL,
+		Model:    "test",
+	})
+
+	texts := make([]string, 20)
+	for i := range texts {
+		texts[i] = strings.Repeat("x", i+1)
+	}
+	vecs, err := client.EmbedBatch(texts)
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	for i, vec := range vecs {
+		if len(vec) != 1 || int(vec[0]) != i+1 {
+			t.Errorf("Result %d out of order: %v", i, vec)
+		}
+	}
+}
+
+func TestCheckConnection_Ollama(t *testing.T) {
+	// /api/tags 列出模型，未带 tag 的配置应匹配 :latest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"models": []map[string]string{{"name": "nomic-embed-text:latest"}},
+			})
+		case "/api/embeddings":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"embedding": []float32{0.1, 0.2, 0.3, 0.4},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{Provider: "ollama", BaseURL: server.URL, Model: "nomic-embed-text"})
+	status := CheckConnection(client)
+	if !status.Reachable || !status.ModelFound || status.Dimension != 4 || status.Error != "" {
+		t.Errorf("Unexpected status for available model: %+v", status)
+	}
+
+	client, _ = NewEmbeddingClient(&EmbeddingConfig{Provider: "ollama", BaseURL: server.URL, Model: "missing"})
+	status = CheckConnection(client)
+	if !status.Reachable || status.ModelFound || status.Error == "" {
+		t.Errorf("Unexpected status for missing model: %+v", status)
+	}
+}
+
+func TestCheckConnection_OpenAI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": []map[string]interface{}{{"embedding": []float32{0.1, 0.2}}},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{Provider: "openai", BaseURL: server.URL, Model: "test", APIKey: "good"})
+	status := CheckConnection(client)
+	if !status.Reachable || !status.ModelFound || status.Dimension != 2 {
+		t.Errorf("Unexpected status for valid key: %+v", status)
+	}
+
+	// 401 说明服务可达，但模型不可用
+	client, _ = NewEmbeddingClient(&EmbeddingConfig{Provider: "openai", BaseURL: server.URL, Model: "test", APIKey: "bad"})
+	status = CheckConnection(client)
+	if !status.Reachable || status.ModelFound || !strings.Contains(status.Error, "401") {
+		t.Errorf("Unexpected status for invalid key: %+v", status)
+	}
+}
+
+func TestCheckConnection_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client, _ := NewEmbeddingClient(&EmbeddingConfig{Provider: "ollama", BaseURL: url, Model: "test"})
+	if status := CheckConnection(client); status.Reachable {
+		t.Errorf("Expected unreachable status, got %+v", status)
+	}
+}
@@ -0,0 +1,128 @@
+package rag
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// VectorExportMagic 向量导出文件头标识
+//
+// 文件格式（小端序）：
+//
+//	[8]byte  magic "NOOKVEC1"
+//	uint32   dimension
+//	uint32   count
+//	uint32   metadata 长度（字节）
+//	[]byte   metadata（JSON 数组，元素为 VectorExportRecord，顺序与向量一致）
+//	float32  count × dimension 的向量矩阵（行优先）
+//
+// 在 Python 中可用 numpy 加载：
+//
+//	np.frombuffer(buf, dtype="<f4", offset=20+meta_len).reshape(count, dim)
+const VectorExportMagic = "NOOKVEC1"
+
+// VectorExportRecord 导出的 chunk 元数据
+type VectorExportRecord struct {
+	ID             string `json:"id"`
+	DocID          string `json:"docId"`
+	DocTitle       string `json:"docTitle"`
+	SourceBlockID  string `json:"sourceBlockId"`
+	SourceType     string `json:"sourceType"`
+	BlockType      string `json:"blockType"`
+	HeadingContext string `json:"headingContext"`
+	Content        string `json:"content"`
+}
+
+// VectorExportResult 向量导出结果
+type VectorExportResult struct {
+	Path      string `json:"path"`
+	Count     int    `json:"count"`
+	Dimension int    `json:"dimension"`
+}
+
+// ExportVectorData 导出所有 chunk 的向量及元数据到文件（供外部聚类/可视化分析）
+func (s *Service) ExportVectorData(path string) (*VectorExportResult, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.store.GetAllBlockMeta()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block metadata: %w", err)
+	}
+
+	titles := make(map[string]string)
+	if index, err := s.docRepo.GetAll(); err == nil {
+		for _, doc := range index.Documents {
+			titles[doc.ID] = doc.Title
+		}
+	}
+
+	records := make([]VectorExportRecord, 0, len(blocks))
+	vectors := make([][]float32, 0, len(blocks))
+	for _, block := range blocks {
+		vec, err := s.store.getVectorByID(block.ID)
+		if err != nil || vec == nil {
+			continue // 跳过缺失向量的块
+		}
+		records = append(records, VectorExportRecord{
+			ID:             block.ID,
+			DocID:          block.DocID,
+			DocTitle:       titles[block.DocID],
+			SourceBlockID:  block.SourceBlockID,
+			SourceType:     block.SourceType,
+			BlockType:      block.BlockType,
+			HeadingContext: block.HeadingContext,
+			Content:        block.Content,
+		})
+		vectors = append(vectors, vec)
+	}
+
+	if err := writeVectorExport(path, s.store.dimension, records, vectors); err != nil {
+		return nil, err
+	}
+
+	return &VectorExportResult{
+		Path:      path,
+		Count:     len(records),
+		Dimension: s.store.dimension,
+	}, nil
+}
+
+// writeVectorExport 按 VectorExportMagic 描述的格式写入导出文件
+func writeVectorExport(path string, dimension int, records []VectorExportRecord, vectors [][]float32) error {
+	meta, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	w := bufio.NewWriter(file)
+	if _, err := w.WriteString(VectorExportMagic); err != nil {
+		return err
+	}
+	header := []uint32{uint32(dimension), uint32(len(records)), uint32(len(meta))}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := w.Write(meta); err != nil {
+		return err
+	}
+	for _, vec := range vectors {
+		if err := binary.Write(w, binary.LittleEndian, vec); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,95 @@
+package rag
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteVectorExport_RoundTrip(t *testing.T) {
+	// 测试导出文件可按文档格式解析回元数据和向量
+	path := filepath.Join(t.TempDir(), "vectors.bin")
+	records := []VectorExportRecord{
+		{ID: "a", DocID: "doc1", BlockType: "paragraph", Content: "第一块"},
+		{ID: "b", DocID: "doc2", BlockType: "heading", Content: "第二块"},
+	}
+	vectors := [][]float32{{0.1, 0.2, 0.3}, {0.4, 0.5, 0.6}}
+
+	if err := writeVectorExport(path, 3, records, vectors); err != nil {
+		t.Fatalf("writeVectorExport failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[:8]) != VectorExportMagic {
+		t.Fatalf("Unexpected magic: %q", data[:8])
+	}
+	dim := binary.LittleEndian.Uint32(data[8:12])
+	count := binary.LittleEndian.Uint32(data[12:16])
+	metaLen := binary.LittleEndian.Uint32(data[16:20])
+	if dim != 3 || count != 2 {
+		t.Fatalf("Expected dim=3 count=2, got dim=%d count=%d", dim, count)
+	}
+
+	var decoded []VectorExportRecord
+	if err := json.Unmarshal(data[20:20+metaLen], &decoded); err != nil {
+		t.Fatalf("Failed to decode metadata: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].Content != "第二块" {
+		t.Errorf("Unexpected metadata: %+v", decoded)
+	}
+
+	matrix := data[20+metaLen:]
+	if len(matrix) != int(count*dim*4) {
+		t.Fatalf("Expected %d vector bytes, got %d", count*dim*4, len(matrix))
+	}
+	last := math.Float32frombits(binary.LittleEndian.Uint32(matrix[len(matrix)-4:]))
+	if last != 0.6 {
+		t.Errorf("Expected last value 0.6, got %v", last)
+	}
+}
+
+func TestWriteSearchExportCSV_Header(t *testing.T) {
+	// CSV 导出以查询和时间开头，内容中的逗号和换行需正确转义
+	path := filepath.Join(t.TempDir(), "results.csv")
+	export := &SearchExport{
+		Query:      "向量检索",
+		ExportedAt: "2024-01-02T03:04:05Z",
+		Results: []SearchExportRecord{
+			{Rank: 1, DocID: "doc1", DocTitle: "笔记", Content: "第一行,\n第二行", Score: 0.87654, SourceLink: "nook://doc/doc1#b1"},
+		},
+	}
+	if err := writeSearchExportCSV(path, export); err != nil {
+		t.Fatalf("writeSearchExportCSV failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	bom := make([]byte, 3)
+	if _, err := file.Read(bom); err != nil || string(bom) != "\uFEFF" {
+		t.Fatalf("Expected UTF-8 BOM, got %q", bom)
+	}
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+
+	if rows[0][1] != "向量检索" || rows[1][1] != "2024-01-02T03:04:05Z" {
+		t.Errorf("Unexpected header rows: %v", rows[:2])
+	}
+	last := rows[len(rows)-1]
+	if last[2] != "第一行,\n第二行" || last[3] != "0.8765" || last[8] != "nook://doc/doc1#b1" {
+		t.Errorf("Unexpected result row: %v", last)
+	}
+}
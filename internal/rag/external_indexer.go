@@ -1,10 +1,18 @@
 package rag
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"notion-lite/internal/document"
@@ -13,6 +21,35 @@ import (
 	"notion-lite/internal/utils"
 )
 
+// IndexFailureError 外部内容分块嵌入失败（全部或部分），携带失败计数
+type IndexFailureError struct {
+	Failed int   // 失败的 chunk 数
+	Total  int   // chunk 总数
+	Err    error // 最后一次失败的原因
+}
+
+func (e *IndexFailureError) Error() string {
+	if e.Failed == e.Total {
+		return fmt.Sprintf("embedding failed: %v", e.Err)
+	}
+	return fmt.Sprintf("embedding failed for %d of %d chunks: %v", e.Failed, e.Total, e.Err)
+}
+
+func (e *IndexFailureError) Unwrap() error {
+	return e.Err
+}
+
+// IsPartial 是否只有部分 chunk 失败（其余已成功写入索引）
+func (e *IndexFailureError) IsPartial() bool {
+	return e.Failed < e.Total
+}
+
+// IndexSucceeded 索引是否至少部分成功（部分 chunk 失败时内容仍可被检索，状态需要刷新）
+func IndexSucceeded(err error) bool {
+	var failure *IndexFailureError
+	return err == nil || (errors.As(err, &failure) && failure.IsPartial())
+}
+
 // ExternalIndexer handles indexing of external content (bookmarks and files)
 type ExternalIndexer struct {
 	store      *VectorStore
@@ -21,6 +58,9 @@ type ExternalIndexer struct {
 	docStorage *document.Storage
 	indexer    *Indexer
 	paths      *utils.PathBuilder
+
+	extractWorkers  int // 文件夹文本提取并发数，<=0 时使用 GOMAXPROCS
+	maxExtractBytes int // 单个文件最多提取的文本字节数，<=0 时使用 fileextract.DefaultMaxExtractBytes
 }
 
 // NewExternalIndexer creates a new external content indexer
@@ -42,10 +82,29 @@ func NewExternalIndexer(
 	}
 }
 
+// fetchTimeout 从 RAG 配置读取书签抓取超时
+func (e *ExternalIndexer) fetchTimeout() time.Duration {
+	config, err := LoadConfig(e.paths)
+	if err != nil {
+		return opengraph.DefaultFetchTimeout
+	}
+	return config.GetFetchTimeout()
+}
+
 // IndexBookmarkContent 索引书签网页内容（分块存储）
-func (e *ExternalIndexer) IndexBookmarkContent(url, sourceDocID, blockID string) error {
-	// 1. 抓取网页内容
-	content, err := opengraph.FetchContent(url)
+// timeout 可选，用于覆盖配置中的抓取超时；抓取内容与上次索引相同时跳过重新嵌入
+func (e *ExternalIndexer) IndexBookmarkContent(url, sourceDocID, blockID string, timeout ...time.Duration) error {
+	fetchTimeout := e.fetchTimeout()
+	if len(timeout) > 0 && timeout[0] > 0 {
+		fetchTimeout = timeout[0]
+	}
+	return e.indexBookmark(url, sourceDocID, blockID, fetchTimeout, false)
+}
+
+// indexBookmark 抓取并索引书签内容，force 为 true 时即使内容未变化也重新嵌入
+func (e *ExternalIndexer) indexBookmark(url, sourceDocID, blockID string, fetchTimeout time.Duration, force bool) error {
+	// 1. 抓取网页内容（阅读模式，去除导航/页脚等样板文本）
+	content, err := opengraph.FetchReadableContentWithTimeout(url, fetchTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to fetch content: %w", err)
 	}
@@ -64,23 +123,16 @@ func (e *ExternalIndexer) IndexBookmarkContent(url, sourceDocID, blockID string)
 	// 4. 生成基础 ID
 	baseID := fmt.Sprintf("%s_%s_bookmark", sourceDocID, blockID)
 
-	// 5. 删除该 bookmark block 的旧 chunks（修复重新索引时的主键冲突）
-	if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
-		fmt.Printf("⚠️ [RAG] Failed to delete old bookmark chunks for %s: %v\n", baseID, err)
+	// 5. 内容与上次成功索引时相同则跳过（避免重复嵌入）
+	textHash := externalContentHash(headingContext, content.TextContent)
+	if !force && e.externalUnchanged(sourceDocID, blockID, baseID, textHash) {
+		fmt.Printf("💡 [RAG] Bookmark content unchanged, skipping re-embed: %s\n", url)
+		return nil
 	}
 
-	// 5.1 保存完整提取内容（供 MCP 工具读取）
-	if err := e.store.SaveExternalContent(&ExternalBlockContent{
-		ID:          fmt.Sprintf("%s_%s", sourceDocID, blockID),
-		DocID:       sourceDocID,
-		BlockID:     blockID,
-		BlockType:   "bookmark",
-		URL:         url,
-		Title:       content.Title,
-		RawContent:  content.TextContent,
-		ExtractedAt: time.Now().Unix(),
-	}); err != nil {
-		fmt.Printf("⚠️ [RAG] Failed to save bookmark content for %s: %v\n", baseID, err)
+	// 5.1 删除该 bookmark block 的旧 chunks（修复重新索引时的主键冲突）
+	if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to delete old bookmark chunks for %s: %v\n", baseID, err)
 	}
 
 	// 6. 对内容进行分块
@@ -119,7 +171,7 @@ func (e *ExternalIndexer) IndexBookmarkContent(url, sourceDocID, blockID string)
 			continue
 		}
 
-		embedding, err := e.embedder.Embed(chunk.Content)
+		embedding, err := e.embedder.EmbedWithType(chunk.Content, EmbedKindDocument)
 		if err != nil {
 			failedCount++
 			lastError = err
@@ -146,18 +198,56 @@ func (e *ExternalIndexer) IndexBookmarkContent(url, sourceDocID, blockID string)
 		}
 	}
 
-	// 如果所有 chunks 都嵌入失败，返回错误
-	if successCount == 0 && failedCount > 0 {
-		return fmt.Errorf("embedding failed: %v", lastError)
+	// 8. 保存完整提取内容（供 MCP 工具读取）；只有全部 chunk 成功时才记录哈希，失败的 chunk 下次会重试
+	saved := &ExternalBlockContent{
+		ID:          fmt.Sprintf("%s_%s", sourceDocID, blockID),
+		DocID:       sourceDocID,
+		BlockID:     blockID,
+		BlockType:   "bookmark",
+		URL:         url,
+		Title:       content.Title,
+		RawContent:  content.TextContent,
+		ExtractedAt: time.Now().Unix(),
+	}
+	if failedCount == 0 {
+		saved.ContentHash = textHash
+	}
+	if err := e.store.SaveExternalContent(saved); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to save bookmark content for %s: %v\n", baseID, err)
+	}
+
+	// 如果有 chunk 嵌入失败，返回包含失败计数的错误
+	if failedCount > 0 {
+		return &IndexFailureError{Failed: failedCount, Total: successCount + failedCount, Err: lastError}
 	}
 
 	return nil
 }
 
+// externalContentHash 外部块提取内容的哈希（包含标题，标题变化也会影响 chunk 的上下文）
+func externalContentHash(headingContext, text string) string {
+	return HashContent(headingContext + "\x00" + text)
+}
+
+// externalUnchanged 外部块内容哈希与上次成功索引时相同，且其 chunks 仍在索引中（切换模型清空向量后需重新嵌入）
+func (e *ExternalIndexer) externalUnchanged(docID, blockID, baseID, contentHash string) bool {
+	stored, err := e.store.GetExternalContent(docID, blockID)
+	if err != nil || stored.ContentHash == "" || stored.ContentHash != contentHash {
+		return false
+	}
+	count, err := e.store.CountBlocksByPrefix(baseID)
+	return err == nil && count > 0
+}
+
 // IndexFileContent 索引文件内容（分块存储）
 // filePath 可以是绝对路径（引用模式）或相对路径（归档模式，如 /files/xxx）
-// fileName 是原始文件名（用于显示），如果为空则从路径提取
+// fileName 是原始文件名（用于显示），如果为空则从路径提取；提取内容与上次索引相同时跳过重新嵌入
 func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileName string) error {
+	return e.indexFile(context.Background(), filePath, sourceDocID, blockID, fileName, false)
+}
+
+// indexFile 提取并索引文件内容，force 为 true 时即使内容未变化也重新嵌入（ctx 取消时终止耗时的提取，如 OCR）
+func (e *ExternalIndexer) indexFile(ctx context.Context, filePath, sourceDocID, blockID, fileName string, force bool) error {
 	// 1. 获取完整文件路径
 	var fullPath string
 	// 检查是否是应用内相对路径（如 /files/xxx, /images/xxx）
@@ -173,11 +263,14 @@ func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileN
 		fullPath = filepath.Join(e.paths.DataPath(), strings.TrimPrefix(filePath, "/"))
 	}
 
-	// 2. 提取文本内容
-	textContent, err := fileextract.ExtractText(fullPath)
+	// 2. 提取文本内容（超大文件只保留前 maxExtractBytes 字节）
+	textContent, truncated, err := fileextract.ExtractTextLimitedContext(ctx, fullPath, e.extractCap())
 	if err != nil {
 		return fmt.Errorf("failed to extract text: %w", err)
 	}
+	if truncated {
+		fmt.Printf("⚠️ [RAG] Extracted text of %s truncated to %d bytes\n", filepath.Base(fullPath), e.extractCap())
+	}
 
 	if textContent == "" {
 		return fmt.Errorf("no text content extracted from file")
@@ -193,23 +286,16 @@ func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileN
 	// 4. 生成基础 ID
 	baseID := fmt.Sprintf("%s_%s_file", sourceDocID, blockID)
 
-	// 5. 删除该 file block 的旧 chunks（修复重新索引时的主键冲突）
-	if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
-		fmt.Printf("⚠️ [RAG] Failed to delete old file chunks for %s: %v\n", baseID, err)
+	// 5. 内容与上次成功索引时相同则跳过（避免重复嵌入）
+	textHash := externalContentHash(headingContext, textContent)
+	if !force && e.externalUnchanged(sourceDocID, blockID, baseID, textHash) {
+		fmt.Printf("💡 [RAG] File content unchanged, skipping re-embed: %s\n", displayName)
+		return nil
 	}
 
-	// 5.1 保存完整提取内容（供 MCP 工具读取）
-	if err := e.store.SaveExternalContent(&ExternalBlockContent{
-		ID:          fmt.Sprintf("%s_%s", sourceDocID, blockID),
-		DocID:       sourceDocID,
-		BlockID:     blockID,
-		BlockType:   "file",
-		FilePath:    filePath,
-		Title:       displayName,
-		RawContent:  textContent,
-		ExtractedAt: time.Now().Unix(),
-	}); err != nil {
-		fmt.Printf("⚠️ [RAG] Failed to save file content for %s: %v\n", baseID, err)
+	// 5.1 删除该 file block 的旧 chunks（修复重新索引时的主键冲突）
+	if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to delete old file chunks for %s: %v\n", baseID, err)
 	}
 
 	// 6. 对内容进行分块
@@ -247,7 +333,7 @@ func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileN
 			continue
 		}
 
-		embedding, err := e.embedder.Embed(chunk.Content)
+		embedding, err := e.embedder.EmbedWithType(chunk.Content, EmbedKindDocument)
 		if err != nil {
 			failedCount++
 			lastError = err
@@ -278,9 +364,28 @@ func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileN
 		}
 	}
 
-	// 如果所有 chunks 都嵌入失败，返回错误
-	if successCount == 0 && failedCount > 0 {
-		return fmt.Errorf("embedding failed: %v", lastError)
+	// 8. 保存完整提取内容（供 MCP 工具读取）；只有全部 chunk 成功时才记录哈希，失败的 chunk 下次会重试
+	saved := &ExternalBlockContent{
+		ID:          fmt.Sprintf("%s_%s", sourceDocID, blockID),
+		DocID:       sourceDocID,
+		BlockID:     blockID,
+		BlockType:   "file",
+		FilePath:    filePath,
+		Title:       displayName,
+		RawContent:  textContent,
+		Truncated:   truncated,
+		ExtractedAt: time.Now().Unix(),
+	}
+	if failedCount == 0 {
+		saved.ContentHash = textHash
+	}
+	if err := e.store.SaveExternalContent(saved); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to save file content for %s: %v\n", baseID, err)
+	}
+
+	// 如果有 chunk 嵌入失败，返回包含失败计数的错误
+	if failedCount > 0 {
+		return &IndexFailureError{Failed: failedCount, Total: successCount + failedCount, Err: lastError}
 	}
 
 	return nil
@@ -289,9 +394,48 @@ func (e *ExternalIndexer) IndexFileContent(filePath, sourceDocID, blockID, fileN
 // FolderIndexResult 文件夹索引结果
 type FolderIndexResult struct {
 	TotalFiles   int      `json:"totalFiles"`
-	SuccessCount int      `json:"successCount"`
+	SuccessCount int      `json:"successCount"` // 当前已索引的文件数（新增 + 更新 + 未变更）
 	FailedCount  int      `json:"failedCount"`
 	FailedFiles  []string `json:"failedFiles"`
+	Added        int      `json:"added"`     // 新增的文件数
+	Updated      int      `json:"updated"`   // 内容变更后重新索引的文件数
+	Removed      int      `json:"removed"`   // 已从文件夹中消失并清理的文件数
+	Unchanged    int      `json:"unchanged"` // 未变更而跳过的文件数
+
+	mu sync.Mutex // 保护 FailedCount/FailedFiles（提取 worker 并发写入）
+}
+
+// recordFailure 记录索引失败的文件（并发安全）
+func (r *FolderIndexResult) recordFailure(fileName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.FailedCount++
+	r.FailedFiles = append(r.FailedFiles, fileName)
+}
+
+// FolderIndexProgress 文件夹索引进度（只统计需要重新提取的文件，未变更的文件不计入）
+type FolderIndexProgress struct {
+	BlockID   string `json:"blockId"`
+	Extracted int    `json:"extracted"` // 已完成文本提取的文件数（并发 worker 汇总）
+	Indexed   int    `json:"indexed"`   // 已完成嵌入存储的文件数（含失败）
+	Total     int    `json:"total"`     // 需要处理的文件数
+}
+
+// folderProgress 汇总提取 worker 与嵌入循环的进度，串行调用回调
+type folderProgress struct {
+	mu         sync.Mutex
+	state      FolderIndexProgress
+	onProgress func(FolderIndexProgress)
+}
+
+// advance 更新进度并通知（onProgress 为 nil 时只计数）
+func (p *folderProgress) advance(update func(*FolderIndexProgress)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.state)
+	if p.onProgress != nil {
+		p.onProgress(p.state)
+	}
 }
 
 // supportedExtensions 支持索引的文件扩展名
@@ -299,16 +443,31 @@ var supportedExtensions = map[string]bool{
 	".pdf":  true,
 	".docx": true,
 	".xlsx": true,
+	".pptx": true,
+	".rtf":  true,
 	".epub": true,
+	".csv":  true,
+	".tsv":  true,
 	".html": true,
 	".htm":  true,
 	".txt":  true,
 	".md":   true,
 }
 
-// IndexFolderContent 索引文件夹内容（全量重建）
+// IndexFolderContent 索引文件夹内容（增量更新）
+// 按文件大小/修改时间/内容哈希判断变更，只重新提取和嵌入变更的文件，并清理已消失文件的 chunks
 // maxDepth 控制递归深度，0 表示只处理当前目录，-1 表示无限深度
 func (e *ExternalIndexer) IndexFolderContent(folderPath, sourceDocID, blockID string, maxDepth int) (*FolderIndexResult, error) {
+	return e.IndexFolderContentWithProgress(folderPath, sourceDocID, blockID, maxDepth, nil)
+}
+
+// IndexFolderContentWithProgress 索引文件夹内容（带进度回调，回调可能来自提取 worker，调用已串行化）
+func (e *ExternalIndexer) IndexFolderContentWithProgress(folderPath, sourceDocID, blockID string, maxDepth int, onProgress func(FolderIndexProgress)) (*FolderIndexResult, error) {
+	return e.indexFolder(context.Background(), folderPath, sourceDocID, blockID, maxDepth, onProgress)
+}
+
+// indexFolder 索引文件夹内容（ctx 取消时终止耗时的文件提取，如 OCR）
+func (e *ExternalIndexer) indexFolder(ctx context.Context, folderPath, sourceDocID, blockID string, maxDepth int, onProgress func(FolderIndexProgress)) (*FolderIndexResult, error) {
 	fmt.Printf("\n📁 [RAG] IndexFolderContent called: folder=%s, docID=%s, blockID=%s\n", folderPath, sourceDocID, blockID)
 
 	// 1. 设置默认深度
@@ -316,15 +475,22 @@ func (e *ExternalIndexer) IndexFolderContent(folderPath, sourceDocID, blockID st
 		maxDepth = 10 // 默认最大 10 层
 	}
 
-	// 2. 生成基础 ID 并删除旧数据
+	// 2. 加载已有文件状态
 	baseID := fmt.Sprintf("%s_%s_folder", sourceDocID, blockID)
-	if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
-		fmt.Printf("⚠️ [RAG] Failed to delete old folder chunks for %s: %v\n", baseID, err)
+	states, err := e.store.GetFolderFileStates(baseID)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to load folder file states for %s: %v\n", baseID, err)
+		states = make(map[string]FolderFileState)
+	}
+	if len(states) == 0 {
+		// 无状态记录（首次索引或旧版按序号命名的 chunks）：清理旧数据后全量索引
+		if err := e.store.DeleteBlocksByPrefix(baseID); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to delete old folder chunks for %s: %v\n", baseID, err)
+		}
 	}
 
 	// 3. 收集文件夹中所有支持的文件
-	var files []string
-	err := e.walkFolder(folderPath, 0, maxDepth, &files)
+	files, err := e.walkFolder(folderPath, maxDepth)
 	if err != nil {
 		fmt.Printf("❌ [RAG] Failed to walk folder: %v\n", err)
 		return nil, fmt.Errorf("failed to walk folder: %w", err)
@@ -337,100 +503,122 @@ func (e *ExternalIndexer) IndexFolderContent(folderPath, sourceDocID, blockID st
 		}
 	}
 
-	if len(files) == 0 {
-		fmt.Printf("📁 [RAG] No supported files found in folder, returning empty result\n")
-		return &FolderIndexResult{
-			TotalFiles:   0,
-			SuccessCount: 0,
-			FailedCount:  0,
-			FailedFiles:  nil,
-		}, nil
-	}
-
-	// 4. 索引每个文件
 	result := &FolderIndexResult{
 		TotalFiles:  len(files),
 		FailedFiles: make([]string, 0),
 	}
-
 	folderName := filepath.Base(folderPath)
 
-	for fileIndex, filePath := range files {
-		// 提取文本内容
-		textContent, err := fileextract.ExtractText(filePath)
+	// 4. 找出新增或变更的文件
+	present := make(map[string]bool, len(files))
+	var tasks []folderFileTask
+	for _, filePath := range files {
+		present[filePath] = true
+		fileName := filepath.Base(filePath)
+
+		info, err := os.Stat(filePath)
 		if err != nil {
-			result.FailedCount++
-			result.FailedFiles = append(result.FailedFiles, filepath.Base(filePath))
-			fmt.Printf("⚠️ [RAG] Failed to extract text from %s: %v\n", filePath, err)
+			result.recordFailure(fileName)
 			continue
 		}
 
-		if textContent == "" {
-			result.FailedCount++
-			result.FailedFiles = append(result.FailedFiles, filepath.Base(filePath))
+		prev, existed := states[filePath]
+		if existed && prev.Size == info.Size() && prev.ModTime == info.ModTime().UnixNano() {
+			result.Unchanged++
+			result.SuccessCount++
 			continue
 		}
 
-		// 构建上下文（文件夹名/文件名）
-		fileName := filepath.Base(filePath)
-		headingContext := fmt.Sprintf("%s/%s", folderName, fileName)
-
-		// 生成文件级别的 ID
-		fileID := fmt.Sprintf("%s_%d", baseID, fileIndex)
-
-		// 对内容进行分块
-		chunks := ChunkTextContent(textContent, headingContext, fileID, e.indexer.chunkConfig)
+		contentHash, err := hashFile(filePath)
+		if err != nil {
+			result.recordFailure(fileName)
+			continue
+		}
 
-		if len(chunks) == 0 {
-			chunks = []ExtractedBlock{{
-				ID:             fileID,
-				Type:           "folder",
-				Content:        textContent,
-				HeadingContext: headingContext,
-			}}
+		state := FolderFileState{
+			FolderID:    baseID,
+			DocID:       sourceDocID,
+			FilePath:    filePath,
+			FileID:      folderFileID(baseID, folderPath, filePath),
+			Size:        info.Size(),
+			ModTime:     info.ModTime().UnixNano(),
+			ContentHash: contentHash,
 		}
 
-		// 为每个 chunk 生成 embedding 并存储
-		fileSuccess := false
-		for _, chunk := range chunks {
-			if chunk.Content == "" {
-				continue
+		// 仅修改时间变化（如 touch）而内容未变：只更新状态
+		if existed && prev.ContentHash == contentHash {
+			state.Truncated = prev.Truncated
+			if err := e.store.SaveFolderFileState(&state); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to save folder file state for %s: %v\n", filePath, err)
 			}
+			result.Unchanged++
+			result.SuccessCount++
+			continue
+		}
 
-			embedding, err := e.embedder.Embed(chunk.Content)
-			if err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to embed folder chunk %s: %v\n", chunk.ID, err)
-				continue
-			}
+		tasks = append(tasks, folderFileTask{state: state, existed: existed})
+	}
 
-			contentHash := HashContent(chunk.Content)
-			if err := e.store.Upsert(&BlockVector{
-				ID:             chunk.ID,
-				SourceBlockID:  blockID,
-				SourceType:     "folder",
-				DocID:          sourceDocID,
-				Content:        chunk.Content,
-				ContentHash:    contentHash,
-				BlockType:      "folder",
-				HeadingContext: chunk.HeadingContext,
-				FilePath:       filePath,
-				Embedding:      embedding,
-			}); err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to upsert folder chunk %s: %v\n", chunk.ID, err)
-			} else {
-				fileSuccess = true
+	// 5. 并发提取文本，按文件顺序依次嵌入存储（避免对嵌入服务并发请求）
+	progress := &folderProgress{
+		state:      FolderIndexProgress{BlockID: blockID, Total: len(tasks)},
+		onProgress: onProgress,
+	}
+	extracted := e.extractFolderFiles(ctx, tasks, result, progress)
+	for i, task := range tasks {
+		filePath := task.state.FilePath
+		fileName := filepath.Base(filePath)
+		file := <-extracted[i]
+		textContent := file.text
+		task.state.Truncated = file.truncated
+		progressDone := func() { progress.advance(func(p *FolderIndexProgress) { p.Indexed++ }) }
+
+		// 删除该文件的旧 chunks 后重新索引
+		if err := e.store.DeleteBlocksByPrefix(task.state.FileID); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to delete old chunks for %s: %v\n", filePath, err)
+		}
+		if textContent == "" || !e.indexFolderFile(filePath, textContent, folderHeadingContext(folderPath, filePath), task.state.FileID, sourceDocID, blockID) {
+			// 失败时移除状态，下次重建时重试（提取失败已在 worker 中记录）
+			_ = e.store.DeleteFolderFileState(baseID, filePath)
+			if textContent != "" {
+				result.recordFailure(fileName)
 			}
+			progressDone()
+			continue
+		}
+		if err := e.store.SaveFolderFileState(&task.state); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to save folder file state for %s: %v\n", filePath, err)
 		}
 
-		if fileSuccess {
-			result.SuccessCount++
+		if task.existed {
+			result.Updated++
 		} else {
-			result.FailedCount++
-			result.FailedFiles = append(result.FailedFiles, fileName)
+			result.Added++
+		}
+		result.SuccessCount++
+		progressDone()
+	}
+
+	// 6. 清理已从文件夹中消失的文件
+	for filePath, state := range states {
+		if present[filePath] {
+			continue
+		}
+		if err := e.store.DeleteBlocksByPrefix(state.FileID); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to delete chunks for removed file %s: %v\n", filePath, err)
 		}
+		if err := e.store.DeleteFolderFileState(baseID, filePath); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to delete folder file state for %s: %v\n", filePath, err)
+		}
+		result.Removed++
 	}
 
-	// 5. 保存文件夹级别元数据
+	// 7. 保存文件夹级别元数据（任一已索引文件被截断时标记为截断，并列出被截断的文件）
+	rawContent := fmt.Sprintf("Folder: %s\nTotal files: %d\nIndexed: %d", folderPath, result.TotalFiles, result.SuccessCount)
+	truncatedFiles := e.truncatedFolderFiles(baseID)
+	if len(truncatedFiles) > 0 {
+		rawContent += fmt.Sprintf("\nTruncated: %d (%s)", len(truncatedFiles), strings.Join(truncatedFiles, ", "))
+	}
 	if err := e.store.SaveExternalContent(&ExternalBlockContent{
 		ID:          fmt.Sprintf("%s_%s", sourceDocID, blockID),
 		DocID:       sourceDocID,
@@ -438,56 +626,202 @@ func (e *ExternalIndexer) IndexFolderContent(folderPath, sourceDocID, blockID st
 		BlockType:   "folder",
 		FilePath:    folderPath,
 		Title:       folderName,
-		RawContent:  fmt.Sprintf("Folder: %s\nTotal files: %d\nIndexed: %d", folderPath, result.TotalFiles, result.SuccessCount),
+		RawContent:  rawContent,
+		Truncated:   len(truncatedFiles) > 0,
 		ExtractedAt: time.Now().Unix(),
 	}); err != nil {
 		fmt.Printf("⚠️ [RAG] Failed to save folder metadata for %s: %v\n", baseID, err)
 	}
 
-	fmt.Printf("✅ [RAG] Folder indexing complete: %d/%d files indexed\n", result.SuccessCount, result.TotalFiles)
+	fmt.Printf("✅ [RAG] Folder indexing complete: %d/%d files indexed (added %d, updated %d, removed %d, unchanged %d)\n",
+		result.SuccessCount, result.TotalFiles, result.Added, result.Updated, result.Removed, result.Unchanged)
 	return result, nil
 }
 
-// walkFolder 递归遍历文件夹，收集支持的文件
-func (e *ExternalIndexer) walkFolder(dir string, currentDepth, maxDepth int, files *[]string) error {
-	if currentDepth > maxDepth {
+// truncatedFolderFiles 文件夹中已索引且文本被截断的文件名（按名称排序）
+func (e *ExternalIndexer) truncatedFolderFiles(baseID string) []string {
+	states, err := e.store.GetFolderFileStates(baseID)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to load folder file states for %s: %v\n", baseID, err)
 		return nil
 	}
-
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return err
+	var names []string
+	for filePath, state := range states {
+		if state.Truncated {
+			names = append(names, filepath.Base(filePath))
+		}
 	}
+	sort.Strings(names)
+	return names
+}
 
-	for _, entry := range entries {
-		fullPath := filepath.Join(dir, entry.Name())
+// folderFileTask 需要重新提取和嵌入的文件夹文件
+type folderFileTask struct {
+	state   FolderFileState
+	existed bool // 是否已有索引（更新而非新增）
+}
 
-		if entry.IsDir() {
-			// 跳过隐藏目录和常见的无关目录
-			name := entry.Name()
-			if strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__" {
-				continue
-			}
-			// 递归处理子目录
-			if err := e.walkFolder(fullPath, currentDepth+1, maxDepth, files); err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to walk subdir %s: %v\n", fullPath, err)
+// extractedFile 提取出的文件文本
+type extractedFile struct {
+	text      string
+	truncated bool // 文本超过提取上限而被截断
+}
+
+// extractFolderFiles 使用有界 worker 池并发提取文件文本
+// 返回与 tasks 一一对应的 channel，调用方按顺序读取即可保持原有处理顺序；提取失败的文件记入 result 并返回空文本
+func (e *ExternalIndexer) extractFolderFiles(ctx context.Context, tasks []folderFileTask, result *FolderIndexResult, progress *folderProgress) []chan extractedFile {
+	extracted := make([]chan extractedFile, len(tasks))
+	for i := range extracted {
+		extracted[i] = make(chan extractedFile, 1)
+	}
+
+	jobs := make(chan int)
+	workers := min(e.extractWorkerCount(), len(tasks))
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				filePath := tasks[i].state.FilePath
+				textContent, truncated, err := fileextract.ExtractTextLimitedContext(ctx, filePath, e.extractCap())
+				if err != nil {
+					fmt.Printf("⚠️ [RAG] Failed to extract text from %s: %v\n", filePath, err)
+					textContent, truncated = "", false
+				} else if truncated {
+					fmt.Printf("⚠️ [RAG] Extracted text of %s truncated to %d bytes\n", filePath, e.extractCap())
+				}
+				if textContent == "" {
+					result.recordFailure(filepath.Base(filePath))
+				}
+				progress.advance(func(p *FolderIndexProgress) { p.Extracted++ })
+				extracted[i] <- extractedFile{text: textContent, truncated: truncated}
 			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range tasks {
+			jobs <- i
+		}
+	}()
+
+	return extracted
+}
+
+// SetExtractWorkers 设置文件夹索引时的文本提取并发数
+func (e *ExternalIndexer) SetExtractWorkers(workers int) {
+	e.extractWorkers = workers
+}
+
+// SetMaxExtractBytes 设置单个文件最多提取的文本字节数（<=0 时使用默认值）
+func (e *ExternalIndexer) SetMaxExtractBytes(maxBytes int) {
+	e.maxExtractBytes = maxBytes
+}
+
+// extractCap 单个文件的文本提取上限
+func (e *ExternalIndexer) extractCap() int {
+	if e.maxExtractBytes <= 0 {
+		return fileextract.DefaultMaxExtractBytes
+	}
+	return e.maxExtractBytes
+}
+
+// extractWorkerCount 文本提取并发数（未配置时使用 GOMAXPROCS，文档解析为 CPU 密集型，配置值不超过 CPU 核数）
+func (e *ExternalIndexer) extractWorkerCount() int {
+	if e.extractWorkers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return min(e.extractWorkers, runtime.NumCPU())
+}
+
+// indexFolderFile 对单个文件的已提取文本分块并嵌入存储，至少一个 chunk 成功时返回 true
+func (e *ExternalIndexer) indexFolderFile(filePath, textContent, headingContext, fileID, sourceDocID, blockID string) bool {
+	// 对内容进行分块
+	chunks := ChunkTextContent(textContent, headingContext, fileID, e.indexer.chunkConfig)
+	if len(chunks) == 0 {
+		chunks = []ExtractedBlock{{
+			ID:             fileID,
+			Type:           "folder",
+			Content:        textContent,
+			HeadingContext: headingContext,
+		}}
+	}
+
+	// 为每个 chunk 生成 embedding 并存储
+	fileSuccess := false
+	for _, chunk := range chunks {
+		if chunk.Content == "" {
+			continue
+		}
+
+		embedding, err := e.embedder.EmbedWithType(chunk.Content, EmbedKindDocument)
+		if err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to embed folder chunk %s: %v\n", chunk.ID, err)
+			continue
+		}
+
+		if err := e.store.Upsert(&BlockVector{
+			ID:             chunk.ID,
+			SourceBlockID:  blockID,
+			SourceType:     "folder",
+			DocID:          sourceDocID,
+			Content:        chunk.Content,
+			ContentHash:    HashContent(chunk.Content),
+			BlockType:      "folder",
+			HeadingContext: chunk.HeadingContext,
+			FilePath:       filePath,
+			Embedding:      embedding,
+		}); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to upsert folder chunk %s: %v\n", chunk.ID, err)
 		} else {
-			// 检查是否是支持的文件类型
-			ext := strings.ToLower(filepath.Ext(entry.Name()))
-			if supportedExtensions[ext] {
-				*files = append(*files, fullPath)
-			}
+			fileSuccess = true
 		}
 	}
+	return fileSuccess
+}
 
-	return nil
+// folderFileID 生成文件级别的稳定 ID（基于相对路径哈希，增删文件不影响其他文件的 ID）
+func folderFileID(baseID, folderPath, filePath string) string {
+	rel, err := filepath.Rel(folderPath, filePath)
+	if err != nil {
+		rel = filePath
+	}
+	return fmt.Sprintf("%s_%s", baseID, HashContent(filepath.ToSlash(rel)))
 }
 
-// ReindexAll 重新索引所有 bookmark 和 file 块
-// 遍历所有文档，提取 bookmark/file 块信息，然后重新抓取和索引
+// folderHeadingContext 文件夹文件 chunk 的标题上下文：文件夹名加文件的相对路径（如 docs/api/auth.md），区分不同子目录中的同名文件
+func folderHeadingContext(folderPath, filePath string) string {
+	rel, err := filepath.Rel(folderPath, filePath)
+	if err != nil {
+		rel = filepath.Base(filePath)
+	}
+	return filepath.Base(folderPath) + "/" + filepath.ToSlash(rel)
+}
+
+// hashFile 计算文件内容哈希
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
 
-func (e *ExternalIndexer) ReindexAll() (int, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// walkFolder 递归遍历文件夹，收集支持的文件
+func (e *ExternalIndexer) walkFolder(dir string, maxDepth int) ([]string, error) {
+	return utils.WalkFiles(dir, maxDepth, func(path string) bool {
+		return supportedExtensions[strings.ToLower(filepath.Ext(path))]
+	})
+}
+
+// ReindexAll 重新索引所有 bookmark 和 file 块
+// 遍历所有文档，提取 bookmark/file 块信息，然后重新抓取和索引；force 为 false 时内容未变化的块不重新嵌入
+// ctx 取消时停止处理后续块，返回已完成数和 ctx.Err()
+func (e *ExternalIndexer) ReindexAll(ctx context.Context, force bool) (int, error) {
 	// 获取所有文档
 	index, err := e.docRepo.GetAll()
 	if err != nil {
@@ -496,75 +830,108 @@ func (e *ExternalIndexer) ReindexAll() (int, error) {
 
 	totalCount := 0
 	for _, doc := range index.Documents {
+		if err := ctx.Err(); err != nil {
+			return totalCount, err
+		}
 		// 加载文档内容
 		content, err := e.docStorage.Load(doc.ID)
 		if err != nil {
 			fmt.Printf("⚠️ [RAG] Failed to load document %s: %v\n", doc.ID, err)
 			continue
 		}
+		totalCount += e.reindexDocumentBlocks(ctx, doc.ID, content, force)
+	}
 
-		// 提取外部块信息
-		externalIDs := ExtractExternalBlockIDs([]byte(content))
+	return totalCount, ctx.Err()
+}
 
-		// 重新索引 bookmark 块
-		for _, bookmark := range externalIDs.BookmarkBlocks {
-			if bookmark.URL == "" {
-				continue
-			}
-			if err := e.IndexBookmarkContent(bookmark.URL, doc.ID, bookmark.BlockID); err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to reindex bookmark %s: %v\n", bookmark.BlockID, err)
-			} else {
-				totalCount++
-				fmt.Printf("✅ [RAG] Reindexed bookmark: %s\n", bookmark.URL)
-			}
-		}
+// ReindexDocument 重新索引单个文档中的所有外部块（如合并文档后块 ID 改变）
+func (e *ExternalIndexer) ReindexDocument(docID string) (int, error) {
+	content, err := e.docStorage.Load(docID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load document: %w", err)
+	}
+	return e.reindexDocumentBlocks(context.Background(), docID, content, false), nil
+}
 
-		// 重新索引 file 块
-		for _, file := range externalIDs.FileBlocks {
-			if file.FilePath == "" {
-				continue
-			}
-			if err := e.IndexFileContent(file.FilePath, doc.ID, file.BlockID, file.FileName); err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to reindex file %s: %v\n", file.BlockID, err)
-			} else {
-				totalCount++
-				fmt.Printf("✅ [RAG] Reindexed file: %s\n", file.FilePath)
-			}
+// reindexDocumentBlocks 重新抓取并索引文档内容中的 bookmark/file/folder 块，返回成功数（ctx 取消后跳过剩余块）
+func (e *ExternalIndexer) reindexDocumentBlocks(ctx context.Context, docID, content string, force bool) int {
+	count := 0
+
+	// 提取外部块信息
+	externalIDs := ExtractExternalBlockIDs([]byte(content))
+
+	// 重新索引 bookmark 块
+	for _, bookmark := range externalIDs.BookmarkBlocks {
+		if bookmark.URL == "" || ctx.Err() != nil {
+			continue
+		}
+		if err := e.indexBookmark(bookmark.URL, docID, bookmark.BlockID, e.fetchTimeout(), force); !IndexSucceeded(err) {
+			fmt.Printf("⚠️ [RAG] Failed to reindex bookmark %s: %v\n", bookmark.BlockID, err)
+		} else {
+			count++
+			fmt.Printf("✅ [RAG] Reindexed bookmark: %s\n", bookmark.URL)
 		}
+	}
 
-		// 重新索引 folder 块
-		for _, folder := range externalIDs.FolderBlocks {
-			if folder.FolderPath == "" {
-				continue
-			}
-			if _, err := e.IndexFolderContent(folder.FolderPath, doc.ID, folder.BlockID, 0); err != nil {
-				fmt.Printf("⚠️ [RAG] Failed to reindex folder %s: %v\n", folder.BlockID, err)
-			} else {
-				totalCount++
-				fmt.Printf("✅ [RAG] Reindexed folder: %s\n", folder.FolderPath)
-			}
+	// 重新索引 file 块
+	for _, file := range externalIDs.FileBlocks {
+		if file.FilePath == "" || ctx.Err() != nil {
+			continue
+		}
+		if err := e.indexFile(ctx, file.FilePath, docID, file.BlockID, file.FileName, force); !IndexSucceeded(err) {
+			fmt.Printf("⚠️ [RAG] Failed to reindex file %s: %v\n", file.BlockID, err)
+		} else {
+			count++
+			fmt.Printf("✅ [RAG] Reindexed file: %s\n", file.FilePath)
 		}
 	}
 
-	return totalCount, nil
+	// 重新索引 folder 块
+	for _, folder := range externalIDs.FolderBlocks {
+		if folder.FolderPath == "" || ctx.Err() != nil {
+			continue
+		}
+		if _, err := e.indexFolder(ctx, folder.FolderPath, docID, folder.BlockID, 0, nil); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to reindex folder %s: %v\n", folder.BlockID, err)
+		} else {
+			count++
+			fmt.Printf("✅ [RAG] Reindexed folder: %s\n", folder.FolderPath)
+		}
+	}
+	return count
 }
 
-// ReindexAllWithProgress 重新索引所有 bookmark 和 file 块（带进度回调）
-func (e *ExternalIndexer) ReindexAllWithProgress(onProgress func(current, total int)) (int, error) {
-	// 获取所有文档并计算外部块总数
-	index, err := e.docRepo.GetAll()
+// ReindexAllWithProgress 重新索引所有 bookmark 和 file 块（带进度回调，ctx 和 force 含义同 ReindexAll）
+func (e *ExternalIndexer) ReindexAllWithProgress(ctx context.Context, force bool, onProgress func(current, total int)) (int, error) {
+	jobs, err := e.collectExternalBlocks()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get documents: %w", err)
+		return 0, err
+	}
+	var progress func(ReindexProgress)
+	if onProgress != nil {
+		progress = func(p ReindexProgress) { onProgress(p.Current, p.Total) }
 	}
+	return e.reindexExternalBlocks(ctx, force, jobs, progress)
+}
+
+// externalBlockJob 全量重建中待处理的外部块（bookmark/file/folder 三选一）
+type externalBlockJob struct {
+	docID    string
+	docTitle string
+	bookmark *BookmarkBlockInfo
+	file     *FileBlockInfo
+	folder   *FolderBlockInfo
+}
 
-	// 先统计总数
-	var allExternalBlocks []struct {
-		docID    string
-		bookmark *BookmarkBlockInfo
-		file     *FileBlockInfo
-		folder   *FolderBlockInfo
+// collectExternalBlocks 遍历所有文档，收集需要重新索引的外部块（用于预先得到总数）
+func (e *ExternalIndexer) collectExternalBlocks() ([]externalBlockJob, error) {
+	index, err := e.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
 	}
 
+	var jobs []externalBlockJob
 	for _, doc := range index.Documents {
 		content, err := e.docStorage.Load(doc.ID)
 		if err != nil {
@@ -573,70 +940,64 @@ func (e *ExternalIndexer) ReindexAllWithProgress(onProgress func(current, total
 		externalIDs := ExtractExternalBlockIDs([]byte(content))
 		for i := range externalIDs.BookmarkBlocks {
 			if externalIDs.BookmarkBlocks[i].URL != "" {
-				allExternalBlocks = append(allExternalBlocks, struct {
-					docID    string
-					bookmark *BookmarkBlockInfo
-					file     *FileBlockInfo
-					folder   *FolderBlockInfo
-				}{docID: doc.ID, bookmark: &externalIDs.BookmarkBlocks[i]})
+				jobs = append(jobs, externalBlockJob{docID: doc.ID, docTitle: doc.Title, bookmark: &externalIDs.BookmarkBlocks[i]})
 			}
 		}
 		for i := range externalIDs.FileBlocks {
 			if externalIDs.FileBlocks[i].FilePath != "" {
-				allExternalBlocks = append(allExternalBlocks, struct {
-					docID    string
-					bookmark *BookmarkBlockInfo
-					file     *FileBlockInfo
-					folder   *FolderBlockInfo
-				}{docID: doc.ID, file: &externalIDs.FileBlocks[i]})
+				jobs = append(jobs, externalBlockJob{docID: doc.ID, docTitle: doc.Title, file: &externalIDs.FileBlocks[i]})
 			}
 		}
 		for i := range externalIDs.FolderBlocks {
 			if externalIDs.FolderBlocks[i].FolderPath != "" {
-				allExternalBlocks = append(allExternalBlocks, struct {
-					docID    string
-					bookmark *BookmarkBlockInfo
-					file     *FileBlockInfo
-					folder   *FolderBlockInfo
-				}{docID: doc.ID, folder: &externalIDs.FolderBlocks[i]})
+				jobs = append(jobs, externalBlockJob{docID: doc.ID, docTitle: doc.Title, folder: &externalIDs.FolderBlocks[i]})
 			}
 		}
 	}
+	return jobs, nil
+}
 
-	total := len(allExternalBlocks)
+// reindexExternalBlocks 依次重新索引收集到的外部块，每个块完成后调用 onProgress（与文档阶段一致），返回成功数
+func (e *ExternalIndexer) reindexExternalBlocks(ctx context.Context, force bool, jobs []externalBlockJob, onProgress func(ReindexProgress)) (int, error) {
+	total := len(jobs)
 	if total == 0 {
 		return 0, nil
 	}
 
+	fetchTimeout := e.fetchTimeout()
 	successCount := 0
-	for i, block := range allExternalBlocks {
-		// 发送进度
-		if onProgress != nil {
-			onProgress(i+1, total)
+	for i, block := range jobs {
+		if err := ctx.Err(); err != nil {
+			fmt.Printf("⚠️ [RAG] External reindex cancelled after %d of %d blocks\n", i, total)
+			return successCount, err
 		}
-
 		if block.bookmark != nil {
-			if err := e.IndexBookmarkContent(block.bookmark.URL, block.docID, block.bookmark.BlockID); err != nil {
+			if err := e.indexBookmark(block.bookmark.URL, block.docID, block.bookmark.BlockID, fetchTimeout, force); !IndexSucceeded(err) {
 				fmt.Printf("⚠️ [RAG] Failed to reindex bookmark %s: %v\n", block.bookmark.BlockID, err)
 			} else {
 				successCount++
 				fmt.Printf("✅ [RAG] Reindexed bookmark: %s\n", block.bookmark.URL)
 			}
 		} else if block.file != nil {
-			if err := e.IndexFileContent(block.file.FilePath, block.docID, block.file.BlockID, block.file.FileName); err != nil {
+			if err := e.indexFile(ctx, block.file.FilePath, block.docID, block.file.BlockID, block.file.FileName, force); !IndexSucceeded(err) {
 				fmt.Printf("⚠️ [RAG] Failed to reindex file %s: %v\n", block.file.BlockID, err)
 			} else {
 				successCount++
 				fmt.Printf("✅ [RAG] Reindexed file: %s\n", block.file.FilePath)
 			}
 		} else if block.folder != nil {
-			if _, err := e.IndexFolderContent(block.folder.FolderPath, block.docID, block.folder.BlockID, 0); err != nil {
+			if _, err := e.indexFolder(ctx, block.folder.FolderPath, block.docID, block.folder.BlockID, 0, nil); err != nil {
 				fmt.Printf("⚠️ [RAG] Failed to reindex folder %s: %v\n", block.folder.BlockID, err)
 			} else {
 				successCount++
 				fmt.Printf("✅ [RAG] Reindexed folder: %s\n", block.folder.FolderPath)
 			}
 		}
+
+		// 发送进度
+		if onProgress != nil {
+			onProgress(ReindexProgress{Phase: "external", Current: i + 1, Total: total, Title: block.docTitle})
+		}
 	}
 
 	return successCount, nil
@@ -2,9 +2,14 @@ package rag
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"strings"
 )
 
+// maxBlockDepth 块嵌套的最大递归深度，超过后停止下钻（防止畸形文档导致栈溢出）
+const maxBlockDepth = 50
+
 // ExtractedBlock 提取的块信息
 type ExtractedBlock struct {
 	ID             string
@@ -21,6 +26,23 @@ var listTypes = map[string]bool{
 	"checkListItem":    true,
 }
 
+// standaloneTypes 独立成 chunk、不与相邻短块合并的块类型
+// 保留原始 block_type，便于按类型过滤搜索（如只搜索引用或批注）
+var standaloneTypes = map[string]bool{
+	"quote":     true,
+	"callout":   true,
+	commentType: true,
+}
+
+// commentType 批注块索引时统一使用的类型
+const commentType = "comment"
+
+// commentTypes 视为批注的块类型；SourceBlockID 指向被批注的块（props.targetBlockId，缺省为父块）
+var commentTypes = map[string]bool{
+	"comment":    true,
+	"annotation": true,
+}
+
 // FileBlockInfo file 块信息（包含 ID 和文件路径）
 type FileBlockInfo struct {
 	BlockID  string // BlockNote 块 ID
@@ -58,12 +80,17 @@ func ExtractExternalBlockIDs(content []byte) ExternalBlockIDs {
 	}
 
 	result := ExternalBlockIDs{}
-	extractExternalIDsRecursive(blocks, &result)
+	extractExternalIDsRecursive(blocks, &result, 0)
 	return result
 }
 
 // extractExternalIDsRecursive 递归提取外部块 ID
-func extractExternalIDsRecursive(blocks []interface{}, result *ExternalBlockIDs) {
+func extractExternalIDsRecursive(blocks []interface{}, result *ExternalBlockIDs, depth int) {
+	if depth >= maxBlockDepth {
+		warnMaxDepth("external block ID extraction")
+		return
+	}
+
 	for _, block := range blocks {
 		if blockMap, ok := block.(map[string]interface{}); ok {
 			if blockType, ok := blockMap["type"].(string); ok {
@@ -128,7 +155,7 @@ func extractExternalIDsRecursive(blocks []interface{}, result *ExternalBlockIDs)
 			}
 			// 递归处理 children
 			if children, ok := blockMap["children"].([]interface{}); ok {
-				extractExternalIDsRecursive(children, result)
+				extractExternalIDsRecursive(children, result, depth+1)
 			}
 		}
 	}
@@ -172,7 +199,7 @@ func ExtractBlocksWithConfig(content []byte, config ChunkConfig) []ExtractedBloc
 
 		// 处理嵌套内容（children）
 		if children, ok := block["children"].([]interface{}); ok && len(children) > 0 {
-			childBlocks := extractNestedBlocks(children, currentHeading, 1)
+			childBlocks := extractNestedBlocks(children, extracted.ID, currentHeading, 1)
 			rawBlocks = append(rawBlocks, childBlocks...)
 		}
 
@@ -181,6 +208,17 @@ func ExtractBlocksWithConfig(content []byte, config ChunkConfig) []ExtractedBloc
 		}
 	}
 
+	// 排除配置中指定的块类型（嵌套在其中的子块不受影响）
+	if len(config.ExcludedTypes) > 0 {
+		kept := rawBlocks[:0]
+		for _, block := range rawBlocks {
+			if !config.ExcludedTypes[block.Type] {
+				kept = append(kept, block)
+			}
+		}
+		rawBlocks = kept
+	}
+
 	// 第二遍：聚合列表块
 	i := 0
 	var afterListAggregation []ExtractedBlock
@@ -212,14 +250,22 @@ func ExtractBlocksWithConfig(content []byte, config ChunkConfig) []ExtractedBloc
 	return result
 }
 
-// extractNestedBlocks 递归提取嵌套块内容
-func extractNestedBlocks(children []interface{}, heading string, depth int) []ExtractedBlock {
+// extractNestedBlocks 递归提取嵌套块内容（parentID 为直接父块 ID，用作批注的默认目标）
+func extractNestedBlocks(children []interface{}, parentID, heading string, depth int) []ExtractedBlock {
+	if depth >= maxBlockDepth {
+		warnMaxDepth("block extraction")
+		return nil
+	}
+
 	var result []ExtractedBlock
 	indent := strings.Repeat("  ", depth)
 
 	for _, child := range children {
 		if childBlock, ok := child.(map[string]interface{}); ok {
 			extracted := extractBlock(childBlock)
+			if extracted.Type == commentType && extracted.SourceBlockID == "" {
+				extracted.SourceBlockID = parentID
+			}
 			if extracted.Content != "" {
 				// 添加缩进以表示层级
 				extracted.Content = indent + extracted.Content
@@ -229,7 +275,7 @@ func extractNestedBlocks(children []interface{}, heading string, depth int) []Ex
 
 			// 递归处理更深层嵌套
 			if grandChildren, ok := childBlock["children"].([]interface{}); ok && len(grandChildren) > 0 {
-				nested := extractNestedBlocks(grandChildren, heading, depth+1)
+				nested := extractNestedBlocks(grandChildren, extracted.ID, heading, depth+1)
 				result = append(result, nested...)
 			}
 		}
@@ -237,6 +283,11 @@ func extractNestedBlocks(children []interface{}, heading string, depth int) []Ex
 	return result
 }
 
+// warnMaxDepth 记录嵌套过深的警告
+func warnMaxDepth(where string) {
+	fmt.Printf("⚠️ [RAG] Block nesting exceeds %d levels during %s, deeper blocks skipped\n", maxBlockDepth, where)
+}
+
 // aggregateListBlocks 聚合连续的同类型列表块
 func aggregateListBlocks(blocks []ExtractedBlock, index *int, listType string, heading string) ExtractedBlock {
 	var contents []string
@@ -326,6 +377,16 @@ func extractBlock(block map[string]interface{}) ExtractedBlock {
 		extracted.Content = extractTextFromContent(content)
 	}
 
+	// 批注统一为 comment 类型，并记录被批注的块
+	if commentTypes[extracted.Type] {
+		extracted.Type = commentType
+		if props, ok := block["props"].(map[string]interface{}); ok {
+			if target, ok := props["targetBlockId"].(string); ok {
+				extracted.SourceBlockID = target
+			}
+		}
+	}
+
 	return extracted
 }
 
@@ -350,3 +411,126 @@ func extractTextFromContent(content []interface{}) string {
 	}
 	return strings.TrimSpace(strings.Join(texts, ""))
 }
+
+// LinkRef 文档中的一个内联链接
+type LinkRef struct {
+	BlockID string // 链接所在块的 ID
+	Href    string // 链接地址（[[标题]] 形式的链接为空）
+	Text    string // 链接文本（[[标题]] 形式为方括号内的标题）
+}
+
+// wikiLinkPattern 匹配正文中的 [[文档标题]]
+var wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// ExtractLinks 提取文档中所有内联链接（link 内联内容与 [[标题]] 文本），包括嵌套块
+func ExtractLinks(content []byte) []LinkRef {
+	var blocks []interface{}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return nil
+	}
+	var links []LinkRef
+	extractLinksRecursive(blocks, &links, 0)
+	return links
+}
+
+// extractLinksRecursive 递归提取块及其子块中的链接
+func extractLinksRecursive(blocks []interface{}, links *[]LinkRef, depth int) {
+	if depth >= maxBlockDepth {
+		warnMaxDepth("link extraction")
+		return
+	}
+	for _, block := range blocks {
+		blockMap, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		blockID, _ := blockMap["id"].(string)
+		switch content := blockMap["content"].(type) {
+		case []interface{}:
+			extractInlineLinks(content, blockID, links)
+		case map[string]interface{}:
+			// 表格块的 content 为 tableContent，逐个单元格提取
+			for _, cell := range tableCells(content) {
+				extractInlineLinks(cell, blockID, links)
+			}
+		}
+		if children, ok := blockMap["children"].([]interface{}); ok {
+			extractLinksRecursive(children, links, depth+1)
+		}
+	}
+}
+
+// extractInlineLinks 从内联内容数组中提取链接
+// 相邻的文本片段（如 [[ 与标题样式不同被拆开）先拼接再匹配 [[标题]]
+func extractInlineLinks(content []interface{}, blockID string, links *[]LinkRef) {
+	var run strings.Builder
+	flush := func() {
+		for _, m := range wikiLinkPattern.FindAllStringSubmatch(run.String(), -1) {
+			*links = append(*links, LinkRef{BlockID: blockID, Text: strings.TrimSpace(m[1])})
+		}
+		run.Reset()
+	}
+	for _, item := range content {
+		textItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch textItem["type"] {
+		case "link":
+			flush()
+			href, _ := textItem["href"].(string)
+			text := ""
+			if linkContent, ok := textItem["content"].([]interface{}); ok {
+				text = extractTextFromContent(linkContent)
+			}
+			*links = append(*links, LinkRef{BlockID: blockID, Href: href, Text: text})
+		case "text":
+			text, _ := textItem["text"].(string)
+			run.WriteString(text)
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// tableCells 返回表格各单元格的内联内容（单元格可能直接是内联数组，也可能是带 content 的 tableCell）
+func tableCells(table map[string]interface{}) [][]interface{} {
+	var cells [][]interface{}
+	rows, _ := table["rows"].([]interface{})
+	for _, row := range rows {
+		rowMap, ok := row.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rowCells, _ := rowMap["cells"].([]interface{})
+		for _, cell := range rowCells {
+			switch c := cell.(type) {
+			case []interface{}:
+				cells = append(cells, c)
+			case map[string]interface{}:
+				if content, ok := c["content"].([]interface{}); ok {
+					cells = append(cells, content)
+				}
+			}
+		}
+	}
+	return cells
+}
+
+// LinksTo 判断链接是否指向指定文档
+// 支持 nook://doc/{id}[#blockId]、直接以文档 ID 为地址，以及地址或 [[标题]] 与文档标题一致（忽略大小写）
+func (l LinkRef) LinksTo(docID, title string) bool {
+	href := strings.TrimSpace(l.Href)
+	if href == "" {
+		return title != "" && strings.EqualFold(l.Text, strings.TrimSpace(title))
+	}
+	if strings.HasPrefix(href, docLinkPrefix) {
+		target := strings.TrimPrefix(href, docLinkPrefix)
+		if i := strings.IndexAny(target, "#?"); i >= 0 {
+			target = target[:i]
+		}
+		return target == docID
+	}
+	return href == docID || (title != "" && strings.EqualFold(href, strings.TrimSpace(title)))
+}
@@ -1,6 +1,8 @@
 package rag
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -215,6 +217,44 @@ func TestExtractBlocks_ShortBlockNotMergeAcrossHeading(t *testing.T) {
 	}
 }
 
+// buildDeepNesting 构造指定深度的嵌套块 JSON，最深处放一个 bookmark 块
+func buildDeepNesting(depth int) []byte {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&sb, `{"id": "b%d", "type": "paragraph", "content": [{"type": "text", "text": "层级%d"}], "children": [`, i, i)
+	}
+	sb.WriteString(`{"id": "deep", "type": "bookmark", "props": {"url": "https://example.com"}}`)
+	for i := 0; i < depth; i++ {
+		sb.WriteString("]}")
+	}
+	sb.WriteString("]")
+	return []byte(sb.String())
+}
+
+func TestExtractBlocks_DeepNesting(t *testing.T) {
+	// 测试极深嵌套不会导致栈溢出，且超过最大深度的块被跳过
+	content := buildDeepNesting(1000)
+
+	blocks := ExtractBlocksWithConfig(content, ChunkConfig{})
+	if len(blocks) == 0 {
+		t.Fatal("Expected blocks within max depth to be extracted")
+	}
+	if len(blocks) > maxBlockDepth+1 {
+		t.Errorf("Expected at most %d blocks, got %d", maxBlockDepth+1, len(blocks))
+	}
+
+	ids := ExtractExternalBlockIDs(content)
+	if len(ids.BookmarkIDs) != 0 {
+		t.Errorf("Expected bookmark beyond max depth to be skipped, got %v", ids.BookmarkIDs)
+	}
+
+	text := extractPlainText(content, 0)
+	if !strings.Contains(text, "层级0") {
+		t.Errorf("Expected top-level text to be extracted, got %q", text)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -227,3 +267,156 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestExtractBlocksWithConfig_ExcludedTypes(t *testing.T) {
+	longText := strings.Repeat("这是一段足够长的正文内容，用于避免被合并为短块。", 8)
+	jsonContent := `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + longText + `"}]},
+		{"id": "c1", "type": "codeBlock", "content": [{"type": "text", "text": "func main() {}"}]},
+		{"id": "q1", "type": "quote", "content": [{"type": "text", "text": "引用"}],
+			"children": [{"id": "n1", "type": "codeBlock", "content": [{"type": "text", "text": "nested()"}]}]}
+	]`
+
+	config := DefaultChunkConfig
+	config.ExcludedTypes = map[string]bool{"codeBlock": true}
+	blocks := ExtractBlocksWithConfig([]byte(jsonContent), config)
+
+	var all []string
+	for _, b := range blocks {
+		all = append(all, b.Content)
+	}
+	joined := strings.Join(all, "\n")
+	if strings.Contains(joined, "func main") || strings.Contains(joined, "nested()") {
+		t.Errorf("excluded code blocks should not be extracted, got %q", joined)
+	}
+	if !strings.Contains(joined, "引用") || !strings.Contains(joined, "足够长的正文") {
+		t.Errorf("other blocks should be kept, got %q", joined)
+	}
+
+	// 默认不排除
+	if joined := fmt.Sprint(ExtractBlocks([]byte(jsonContent))); !strings.Contains(joined, "func main") {
+		t.Errorf("code block should be extracted by default, got %s", joined)
+	}
+}
+
+func TestExtractLinks_ResolvesTargets(t *testing.T) {
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [
+			{"type": "text", "text": "See "},
+			{"type": "link", "href": "nook://doc/target#h2", "content": [{"type": "text", "text": "the spec"}]},
+			{"type": "text", "text": " and [[Roadmap]]"}
+		]},
+		{"id": "p2", "type": "paragraph", "content": [
+			{"type": "link", "href": "https://example.com", "content": [{"type": "text", "text": "site"}]}
+		], "children": [
+			{"id": "c1", "type": "paragraph", "content": [{"type": "link", "href": "target", "content": [{"type": "text", "text": "by id"}]}]}
+		]}
+	]`
+
+	links := ExtractLinks([]byte(content))
+	if len(links) != 4 {
+		t.Fatalf("Expected 4 links, got %d: %+v", len(links), links)
+	}
+
+	targets := map[string]bool{}
+	for _, l := range links {
+		if l.LinksTo("target", "Spec") {
+			targets[l.BlockID+":"+l.Text] = true
+		}
+		if l.LinksTo("roadmap-id", "roadmap") && l.Text != "Roadmap" {
+			t.Errorf("Unexpected link to roadmap: %+v", l)
+		}
+	}
+	if !targets["p1:the spec"] || !targets["c1:by id"] || len(targets) != 2 {
+		t.Errorf("Unexpected links to target: %v", targets)
+	}
+	if !links[1].LinksTo("roadmap-id", "roadmap") {
+		t.Errorf("Expected [[Roadmap]] to match the title case-insensitively, got %+v", links[1])
+	}
+	if links[2].LinksTo("target", "Spec") {
+		t.Error("External URL should not link to a document")
+	}
+}
+
+func TestExtractBlocks_QuoteAndCalloutStandalone(t *testing.T) {
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "短段落一"}]},
+		{"id": "q1", "type": "quote", "content": [{"type": "text", "text": "Premature optimization is the root of all evil."}]},
+		{"id": "p2", "type": "paragraph", "content": [{"type": "text", "text": "短段落二"}]},
+		{"id": "c1", "type": "callout", "content": [{"type": "text", "text": "注意：备份后再升级"}]},
+		{"id": "p3", "type": "paragraph", "content": [{"type": "text", "text": "短段落三"}]}
+	]`
+
+	blocks := ExtractBlocks([]byte(content))
+	byID := map[string]ExtractedBlock{}
+	for _, b := range blocks {
+		byID[b.ID] = b
+	}
+	quote, ok := byID["q1"]
+	if !ok || quote.Type != "quote" || quote.Content != "Premature optimization is the root of all evil." {
+		t.Errorf("Expected quote to be its own chunk with type quote, got %+v", blocks)
+	}
+	callout, ok := byID["c1"]
+	if !ok || callout.Type != "callout" || !strings.Contains(callout.Content, "备份") {
+		t.Errorf("Expected callout to be its own chunk with type callout, got %+v", blocks)
+	}
+	for _, b := range blocks {
+		if b.Type == "merged_short_blocks" && (strings.Contains(b.Content, "Premature") || strings.Contains(b.Content, "备份")) {
+			t.Errorf("Quote/callout should not be merged with paragraphs: %+v", b)
+		}
+	}
+}
+
+func TestExtractBlocks_CommentsReferenceAnnotatedBlock(t *testing.T) {
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "The cache is invalidated on every save."}], "children": [
+			{"id": "n1", "type": "comment", "content": [{"type": "text", "text": "Only when content changes?"}]}
+		]},
+		{"id": "a1", "type": "annotation", "props": {"targetBlockId": "p1"}, "content": [{"type": "text", "text": "Check the hash guard"}]}
+	]`
+
+	var comments []ExtractedBlock
+	for _, b := range ExtractBlocksWithConfig([]byte(content), ChunkConfig{MaxChunkSize: 800}) {
+		if b.Type == "comment" {
+			comments = append(comments, b)
+		}
+	}
+	if len(comments) != 2 {
+		t.Fatalf("Expected 2 comment chunks, got %+v", comments)
+	}
+	for _, c := range comments {
+		if c.SourceBlockID != "p1" {
+			t.Errorf("Comment %s should reference annotated block p1, got %q", c.ID, c.SourceBlockID)
+		}
+	}
+}
+
+func TestExtractLinks_TablesAndSplitRuns(t *testing.T) {
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [
+			{"type": "text", "text": "See [["},
+			{"type": "text", "text": "Road", "styles": {"bold": true}},
+			{"type": "text", "text": "map]]"}
+		]},
+		{"id": "t1", "type": "table", "content": {"type": "tableContent", "rows": [
+			{"cells": [
+				[{"type": "text", "text": "[[Spec]]"}],
+				{"type": "tableCell", "content": [{"type": "link", "href": "nook://doc/target", "content": [{"type": "text", "text": "cell"}]}]}
+			]}
+		]}}
+	]`
+
+	links := ExtractLinks([]byte(content))
+	if len(links) != 3 {
+		t.Fatalf("Expected 3 links, got %d: %+v", len(links), links)
+	}
+	if links[0].BlockID != "p1" || links[0].Text != "Roadmap" {
+		t.Errorf("Expected [[Roadmap]] joined across text runs, got %+v", links[0])
+	}
+	if links[1].BlockID != "t1" || links[1].Text != "Spec" {
+		t.Errorf("Expected [[Spec]] from a table cell, got %+v", links[1])
+	}
+	if !links[2].LinksTo("target", "") {
+		t.Errorf("Expected the table cell link to point at target, got %+v", links[2])
+	}
+}
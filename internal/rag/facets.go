@@ -0,0 +1,60 @@
+package rag
+
+import "sort"
+
+// TagFacet 同一标签下的搜索结果
+type TagFacet struct {
+	Tag     string                 `json:"tag"`     // 标签名，空字符串表示无标签的文档
+	Count   int                    `json:"count"`   // 该标签下匹配的文档数
+	Results []DocumentSearchResult `json:"results"` // 按相关性排序
+}
+
+// FacetedSearchResult 按标签分组的搜索结果
+type FacetedSearchResult struct {
+	Total  int        `json:"total"`  // 匹配的文档总数（多标签文档在每个标签下各计一次，但总数只计一次）
+	Facets []TagFacet `json:"facets"` // 按文档数降序，无标签分组排在最后
+}
+
+// GroupByTag 将文档级搜索结果按标签分组，组内保持原有的相关性顺序
+func GroupByTag(results []DocumentSearchResult) *FacetedSearchResult {
+	facetMap := make(map[string]*TagFacet)
+	var order []string
+	add := func(tag string, r DocumentSearchResult) {
+		facet, ok := facetMap[tag]
+		if !ok {
+			facet = &TagFacet{Tag: tag, Results: []DocumentSearchResult{}}
+			facetMap[tag] = facet
+			order = append(order, tag)
+		}
+		facet.Results = append(facet.Results, r)
+		facet.Count++
+	}
+
+	for _, r := range results {
+		seen := make(map[string]bool, len(r.Tags))
+		for _, tag := range r.Tags {
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			add(tag, r)
+		}
+		if len(seen) == 0 {
+			add("", r)
+		}
+	}
+
+	// order 按首次出现的顺序（即最相关文档的顺序）记录，数量相同时保持该顺序
+	facets := make([]TagFacet, 0, len(order))
+	for _, tag := range order {
+		facets = append(facets, *facetMap[tag])
+	}
+	sort.SliceStable(facets, func(i, j int) bool {
+		if (facets[i].Tag == "") != (facets[j].Tag == "") {
+			return facets[j].Tag == ""
+		}
+		return facets[i].Count > facets[j].Count
+	})
+
+	return &FacetedSearchResult{Total: len(results), Facets: facets}
+}
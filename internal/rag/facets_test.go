@@ -0,0 +1,48 @@
+package rag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupByTag(t *testing.T) {
+	results := []DocumentSearchResult{
+		{DocID: "a", Tags: []string{"Research"}, MaxScore: 0.9},
+		{DocID: "b", Tags: []string{"Work", "Research"}, MaxScore: 0.8},
+		{DocID: "c", MaxScore: 0.7},
+		{DocID: "d", Tags: []string{"Work", "Work"}, MaxScore: 0.6},
+		{DocID: "e", Tags: []string{"Work"}, MaxScore: 0.5},
+	}
+	got := GroupByTag(results)
+
+	if got.Total != 5 {
+		t.Errorf("Expected total 5, got %d", got.Total)
+	}
+	type facet struct {
+		tag  string
+		docs []string
+	}
+	var summary []facet
+	for _, f := range got.Facets {
+		if f.Count != len(f.Results) {
+			t.Errorf("facet %q: count %d does not match %d results", f.Tag, f.Count, len(f.Results))
+		}
+		var ids []string
+		for _, r := range f.Results {
+			ids = append(ids, r.DocID)
+		}
+		summary = append(summary, facet{f.Tag, ids})
+	}
+	want := []facet{
+		{"Work", []string{"b", "d", "e"}},
+		{"Research", []string{"a", "b"}},
+		{"", []string{"c"}},
+	}
+	if !reflect.DeepEqual(summary, want) {
+		t.Errorf("got %+v\nwant %+v", summary, want)
+	}
+
+	if empty := GroupByTag(nil); empty.Total != 0 || len(empty.Facets) != 0 {
+		t.Errorf("Expected no facets for empty results, got %+v", empty)
+	}
+}
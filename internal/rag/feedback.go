@@ -0,0 +1,126 @@
+package rag
+
+import (
+	"fmt"
+)
+
+// 搜索反馈排序参数
+const (
+	feedbackSimilarityThreshold = 0.85 // 查询向量相似度达到此值才视为"相似查询"
+	feedbackWeight              = 0.05 // 单条反馈的最大分数调整
+	feedbackMaxAdjustment       = 0.15 // 单个块的累计调整上限（绝对值）
+)
+
+// SearchFeedback 用户对搜索结果的反馈
+type SearchFeedback struct {
+	Query          string
+	QueryEmbedding []float32
+	BlockID        string
+	Helpful        bool
+}
+
+// AddSearchFeedback 记录搜索反馈
+func (s *VectorStore) AddSearchFeedback(feedback *SearchFeedback) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT INTO search_feedback (query, query_embedding, block_id, helpful)
+		VALUES (?, ?, ?, ?)
+	`, feedback.Query, serializeVector(feedback.QueryEmbedding), feedback.BlockID, feedback.Helpful)
+	return err
+}
+
+// GetSearchFeedback 获取所有搜索反馈
+func (s *VectorStore) GetSearchFeedback() ([]SearchFeedback, error) {
+	rows, err := s.db.Query(`SELECT query, query_embedding, block_id, helpful FROM search_feedback`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var feedback []SearchFeedback
+	for rows.Next() {
+		var f SearchFeedback
+		var vecBytes []byte
+		if err := rows.Scan(&f.Query, &vecBytes, &f.BlockID, &f.Helpful); err != nil {
+			return nil, err
+		}
+		f.QueryEmbedding = deserializeVector(vecBytes, s.dimension)
+		if f.QueryEmbedding == nil {
+			continue // 维度不匹配的旧反馈
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, rows.Err()
+}
+
+// ClearSearchFeedback 清空所有搜索反馈
+func (s *VectorStore) ClearSearchFeedback() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM search_feedback`)
+	return err
+}
+
+// feedbackAdjustments 根据相似查询的历史反馈计算每个块的分数调整
+// 有用 +，无关 -；调整幅度随查询相似度线性增长，并按块累计后截断
+func feedbackAdjustments(queryVec []float32, feedback []SearchFeedback) map[string]float32 {
+	if len(feedback) == 0 {
+		return nil
+	}
+
+	adjustments := make(map[string]float32)
+	for _, f := range feedback {
+		sim := cosineSimilarity(queryVec, f.QueryEmbedding)
+		if sim < feedbackSimilarityThreshold {
+			continue
+		}
+		delta := feedbackWeight * (sim - feedbackSimilarityThreshold) / (1 - feedbackSimilarityThreshold)
+		if !f.Helpful {
+			delta = -delta
+		}
+		adjustments[f.BlockID] += delta
+	}
+
+	for id, adj := range adjustments {
+		if adj > feedbackMaxAdjustment {
+			adjustments[id] = feedbackMaxAdjustment
+		} else if adj < -feedbackMaxAdjustment {
+			adjustments[id] = -feedbackMaxAdjustment
+		}
+	}
+	return adjustments
+}
+
+// RecordSearchFeedback 记录用户对某个搜索结果块的反馈（有用/无关）
+// 反馈仅存储在本地，用于相似查询的排序微调
+func (s *Service) RecordSearchFeedback(query, blockID string, helpful bool) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+	if query == "" || blockID == "" {
+		return fmt.Errorf("query and blockID are required")
+	}
+
+	queryVec, err := s.embedder.EmbedWithType(query, EmbedKindQuery)
+	if err != nil {
+		return fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	return s.store.AddSearchFeedback(&SearchFeedback{
+		Query:          query,
+		QueryEmbedding: queryVec,
+		BlockID:        blockID,
+		Helpful:        helpful,
+	})
+}
+
+// ClearSearchFeedback 清空所有搜索反馈
+func (s *Service) ClearSearchFeedback() error {
+	if err := s.init(); err != nil {
+		return err
+	}
+	return s.store.ClearSearchFeedback()
+}
@@ -0,0 +1,55 @@
+package rag
+
+import "testing"
+
+func TestFeedbackAdjustments(t *testing.T) {
+	query := []float32{1, 0, 0}
+	feedback := []SearchFeedback{
+		{QueryEmbedding: []float32{1, 0, 0}, BlockID: "good", Helpful: true},
+		{QueryEmbedding: []float32{1, 0, 0}, BlockID: "bad", Helpful: false},
+		{QueryEmbedding: []float32{0, 1, 0}, BlockID: "unrelated", Helpful: true}, // 不相似的查询
+	}
+
+	adj := feedbackAdjustments(query, feedback)
+	if adj["good"] <= 0 {
+		t.Errorf("Expected positive adjustment for helpful block, got %v", adj["good"])
+	}
+	if adj["bad"] >= 0 {
+		t.Errorf("Expected negative adjustment for irrelevant block, got %v", adj["bad"])
+	}
+	if _, ok := adj["unrelated"]; ok {
+		t.Error("Expected feedback from dissimilar queries to be ignored")
+	}
+
+	// 重复反馈累计后被截断
+	many := make([]SearchFeedback, 10)
+	for i := range many {
+		many[i] = SearchFeedback{QueryEmbedding: []float32{1, 0, 0}, BlockID: "good", Helpful: true}
+	}
+	if adj := feedbackAdjustments(query, many); adj["good"] > feedbackMaxAdjustment {
+		t.Errorf("Expected adjustment capped at %v, got %v", feedbackMaxAdjustment, adj["good"])
+	}
+}
+
+func TestSearchFeedback_StoreRoundTrip(t *testing.T) {
+	indexer, _ := newTestIndexer(t, &recordingEmbedder{})
+	store := indexer.store
+
+	if err := store.AddSearchFeedback(&SearchFeedback{Query: "共识算法", QueryEmbedding: []float32{1, 0, 0}, BlockID: "b1", Helpful: true}); err != nil {
+		t.Fatalf("AddSearchFeedback failed: %v", err)
+	}
+	feedback, err := store.GetSearchFeedback()
+	if err != nil {
+		t.Fatalf("GetSearchFeedback failed: %v", err)
+	}
+	if len(feedback) != 1 || feedback[0].BlockID != "b1" || !feedback[0].Helpful || len(feedback[0].QueryEmbedding) != 3 {
+		t.Errorf("Unexpected feedback: %+v", feedback)
+	}
+
+	if err := store.ClearSearchFeedback(); err != nil {
+		t.Fatal(err)
+	}
+	if feedback, _ := store.GetSearchFeedback(); len(feedback) != 0 {
+		t.Errorf("Expected feedback to be cleared, got %d", len(feedback))
+	}
+}
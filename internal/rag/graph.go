@@ -1,6 +1,7 @@
 package rag
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -41,50 +42,151 @@ type VectorGraphData struct {
 	Nodes []VectorGraphNode `json:"nodes"`
 }
 
+// GraphOptions 图谱构建选项
+type GraphOptions struct {
+	// IncludeUnembedded 包含没有向量的文档节点（如空文档，Val=0），它们只通过标签（Jaccard 系数）连接
+	IncludeUnembedded bool `json:"includeUnembedded"`
+}
+
 // GetDocumentGraph 获取文档关系图谱（包含所有知识节点：文档、书签、文件、文件夹）
 // threshold: 相似度阈值 (0-1)，低于此值的边不显示
 func (s *Service) GetDocumentGraph(threshold float32) (*GraphData, error) {
+	return s.GetDocumentGraphWithOptions(threshold, GraphOptions{})
+}
+
+// GetDocumentGraphWithOptions 按选项获取文档关系图谱
+func (s *Service) GetDocumentGraphWithOptions(threshold float32, opts GraphOptions) (*GraphData, error) {
 	if err := s.init(); err != nil {
 		return nil, err
 	}
 
+	g, err := s.collectGraphNodes(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 计算两两相似度，构建边
+	links := make([]GraphLink, 0)
+	for i := 0; i < len(g.ids); i++ {
+		for j := i + 1; j < len(g.ids); j++ {
+			if link, ok := g.link(g.ids[i], g.ids[j], threshold); ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	return &GraphData{
+		Nodes: g.nodes(),
+		Links: links,
+	}, nil
+}
+
+// GetDocumentGraphANN 使用 sqlite-vec 近邻索引构建关系图谱（适用于大型知识库）
+// 每个节点只与其 k 个最近邻比较，边是 GetDocumentGraph 结果的子集
+func (s *Service) GetDocumentGraphANN(threshold float32, k int) (*GraphData, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		k = defaultGraphNeighbors
+	}
+
+	g, err := s.collectGraphNodes(GraphOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.store.SyncGraphNodes(g.unitVectors); err != nil {
+		return nil, fmt.Errorf("failed to store graph node vectors: %w", err)
+	}
+
+	links := make([]GraphLink, 0)
+	seen := make(map[[2]string]bool)
+	for _, id := range g.ids {
+		neighbors, err := s.store.NearestGraphNodes(g.unitVectors[id], k+1) // 多取一个，结果中包含节点自身
+		if err != nil {
+			return nil, fmt.Errorf("failed to query graph neighbors: %w", err)
+		}
+		for _, other := range neighbors {
+			if other == id {
+				continue
+			}
+			idA, idB := id, other
+			if idA > idB {
+				idA, idB = idB, idA
+			}
+			pair := [2]string{idA, idB}
+			if seen[pair] {
+				continue
+			}
+			seen[pair] = true
+			if link, ok := g.link(idA, idB, threshold); ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	return &GraphData{
+		Nodes: g.nodes(),
+		Links: links,
+	}, nil
+}
+
+// defaultGraphNeighbors ANN 图谱中每个节点默认比较的近邻数
+const defaultGraphNeighbors = 10
+
+// graphNodes 图谱节点及其预处理后的向量和标签
+type graphNodes struct {
+	ids         []string
+	infos       map[string]GraphNode
+	unitVectors map[string][]float32           // 归一化后的平均向量（点积即余弦相似度）
+	tags        map[string]map[string]struct{} // 文档节点的标签集合
+}
+
+// collectGraphNodes 收集所有有向量的节点（文档、书签、文件、文件夹），按选项包含没有向量的文档
+// 向量预先归一化、标签预先转为集合，避免在 O(n²) 的循环中重复计算
+func (s *Service) collectGraphNodes(opts GraphOptions) (*graphNodes, error) {
 	// 1. 获取所有文档列表
 	index, err := s.docRepo.GetAll()
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 收集所有节点的向量
-	// nodeVectors: nodeID -> 平均向量
-	// nodeInfos: nodeID -> GraphNode
-	nodeVectors := make(map[string][]float32)
-	nodeInfos := make(map[string]GraphNode)
+	g := &graphNodes{
+		infos:       make(map[string]GraphNode),
+		unitVectors: make(map[string][]float32),
+		tags:        make(map[string]map[string]struct{}),
+	}
+	add := func(node GraphNode, vec []float32) {
+		g.ids = append(g.ids, node.ID)
+		g.infos[node.ID] = node
+		if vec != nil {
+			g.unitVectors[node.ID] = normalizeVector(vec)
+		}
+		if node.Type == "document" {
+			g.tags[node.ID] = tagSet(node.Tags)
+		}
+	}
 
-	// 2.1 添加文档节点
+	// 2. 添加文档节点
 	for _, doc := range index.Documents {
 		vec, count, err := s.getDocumentAverageVector(doc.ID)
 		if err != nil || vec == nil {
-			// 即使没有向量（比如空文档），如果有标签，也应该显示在图谱中吗？
-			// 目前逻辑是必须有向量才能计算相似度。
-			// 如果我们想纯靠标签关联，那也需要节点存在。
-			// 为了保持逻辑一致，暂时还是要求有内容（有向量）或者我们可以允许空向量但只由标签连接？
-			// 简单起见，如果只依靠标签连接，我们可以在这里放宽限制，
-			// 但余弦相似度需要向量。
-			// 如果没有向量，我们暂时跳过（通常文档都有内容）。
-			continue
+			// 余弦相似度需要向量，没有内容（没有向量）的文档默认跳过
+			if !opts.IncludeUnembedded {
+				continue
+			}
+			vec, count = nil, 0
 		}
-		nodeID := "doc:" + doc.ID
-		nodeVectors[nodeID] = vec
-		nodeInfos[nodeID] = GraphNode{
-			ID:    nodeID,
+		add(GraphNode{
+			ID:    "doc:" + doc.ID,
 			Type:  "document",
 			Title: doc.Title,
 			Tags:  doc.Tags,
 			Val:   count,
-		}
+		}, vec)
 	}
 
-	// 2.2 添加外部块节点（bookmark/file/folder）
+	// 3. 添加外部块节点（bookmark/file/folder）
 	externalNodes, err := s.store.GetAllExternalBlockNodes()
 	if err == nil {
 		for _, ext := range externalNodes {
@@ -92,84 +194,74 @@ func (s *Service) GetDocumentGraph(threshold float32) (*GraphData, error) {
 			if err != nil || vec == nil {
 				continue
 			}
-			nodeID := ext.BlockType + ":" + ext.DocID + ":" + ext.BlockID
-			nodeVectors[nodeID] = vec
-			nodeInfos[nodeID] = GraphNode{
-				ID:            nodeID,
+			add(GraphNode{
+				ID:            ext.BlockType + ":" + ext.DocID + ":" + ext.BlockID,
 				Type:          ext.BlockType,
 				Title:         ext.Title,
 				Val:           count,
 				ParentDocID:   ext.DocID,
 				ParentBlockID: ext.BlockID,
-			}
+			}, vec)
 		}
 	}
+	return g, nil
+}
 
-	// 3. 转换为节点列表
-	nodes := make([]GraphNode, 0, len(nodeInfos))
-	for _, node := range nodeInfos {
-		nodes = append(nodes, node)
+// nodes 返回节点列表
+func (g *graphNodes) nodes() []GraphNode {
+	nodes := make([]GraphNode, 0, len(g.ids))
+	for _, id := range g.ids {
+		nodes = append(nodes, g.infos[id])
 	}
+	return nodes
+}
 
-	// 4. 计算两两相似度，构建边
-	links := make([]GraphLink, 0)
-	nodeIDs := make([]string, 0, len(nodeVectors))
-	for id := range nodeVectors {
-		nodeIDs = append(nodeIDs, id)
-	}
-
-	// 标签增强因子随 threshold 衰减：threshold 越高，标签影响越小
-	tagFactor := float32(0.4) * (1.2 - threshold)
-
-	for i := 0; i < len(nodeIDs); i++ {
-		for j := i + 1; j < len(nodeIDs); j++ {
-			idA := nodeIDs[i]
-			idB := nodeIDs[j]
-
-			// 基础向量相似度
-			semanticSimilarity := cosineSimilarity(nodeVectors[idA], nodeVectors[idB])
-			finalSimilarity := semanticSimilarity
-
-			hasSemantic := semanticSimilarity >= threshold
-			hasTags := false
-
-			// 标签相似度增强 (仅文档之间，使用 Jaccard + 乘法增强)
-			nodeA := nodeInfos[idA]
-			nodeB := nodeInfos[idB]
-
-			if nodeA.Type == "document" && nodeB.Type == "document" {
-				commonTags := countCommonTags(nodeA.Tags, nodeB.Tags)
-				if commonTags > 0 {
-					// Jaccard 系数：共同标签数 / 并集标签数
-					unionSize := len(nodeA.Tags) + len(nodeB.Tags) - commonTags
-					jaccard := float32(commonTags) / float32(unionSize)
-					// 乘法增强：标签只是放大已有的语义关联
-					finalSimilarity = semanticSimilarity * (1 + jaccard*tagFactor)
-					hasTags = true
-				}
-			}
-
-			// 截断到 1.0
-			if finalSimilarity > 1.0 {
-				finalSimilarity = 1.0
-			}
+// link 计算两个节点之间的边（相似度低于阈值时返回 false）
+func (g *graphNodes) link(idA, idB string, threshold float32) (GraphLink, bool) {
+	vecA, okA := g.unitVectors[idA]
+	vecB, okB := g.unitVectors[idB]
+	if !okA || !okB {
+		return g.tagLink(idA, idB, threshold)
+	}
 
-			if finalSimilarity >= threshold {
-				links = append(links, GraphLink{
-					Source:      idA,
-					Target:      idB,
-					Similarity:  finalSimilarity,
-					HasSemantic: hasSemantic,
-					HasTags:     hasTags,
-				})
-			}
+	// 基础向量相似度（单位向量的点积即余弦相似度）
+	semanticSimilarity := dotProduct(vecA, vecB)
+	finalSimilarity := semanticSimilarity
+
+	hasSemantic := semanticSimilarity >= threshold
+	hasTags := false
+
+	// 标签相似度增强 (仅文档之间，使用 Jaccard + 乘法增强)
+	tagsA, okA := g.tags[idA]
+	tagsB, okB := g.tags[idB]
+	if okA && okB {
+		commonTags, unionSize := countCommonTags(tagsA, tagsB)
+		if commonTags > 0 {
+			// 标签增强因子随 threshold 衰减：threshold 越高，标签影响越小
+			tagFactor := float32(0.4) * (1.2 - threshold)
+			// Jaccard 系数：共同标签数 / 并集标签数
+			jaccard := float32(commonTags) / float32(unionSize)
+			// 乘法增强：标签只是放大已有的语义关联
+			finalSimilarity = semanticSimilarity * (1 + jaccard*tagFactor)
+			hasTags = true
 		}
 	}
 
-	return &GraphData{
-		Nodes: nodes,
-		Links: links,
-	}, nil
+	// 截断到 1.0
+	if finalSimilarity > 1.0 {
+		finalSimilarity = 1.0
+	}
+
+	if finalSimilarity < threshold {
+		return GraphLink{}, false
+	}
+	return GraphLink{
+		Source:      idA,
+		Target:      idB,
+		Similarity:  finalSimilarity,
+		HasSemantic: hasSemantic,
+		HasTags:     hasTags,
+	}, true
 }
 
 // getDocumentAverageVector 获取文档的平均向量（只包含 source_type=document 的块）
@@ -210,18 +302,38 @@ func averageVectors(vectors [][]float32) []float32 {
 	return avgVec
 }
 
-// countCommonTags 计算两个标签列表的共同标签数
-func countCommonTags(tagsA, tagsB []string) int {
-	count := 0
-	for _, tA := range tagsA {
-		for _, tB := range tagsB {
-			if tA == tB {
-				count++
-				break
-			}
+// tagSet 将标签列表转为集合（同一文档内的重复标签只计一次）
+func tagSet(tags []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		set[tag] = struct{}{}
+	}
+	return set
+}
+
+// countCommonTags 计算两个标签集合的交集和并集大小
+func countCommonTags(tagsA, tagsB map[string]struct{}) (common, union int) {
+	if len(tagsA) > len(tagsB) {
+		tagsA, tagsB = tagsB, tagsA
+	}
+	for tag := range tagsA {
+		if _, ok := tagsB[tag]; ok {
+			common++
 		}
 	}
-	return count
+	return common, len(tagsA) + len(tagsB) - common
+}
+
+// dotProduct 计算两个向量的点积（维度不一致时返回 0）
+func dotProduct(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return float32(sum)
 }
 
 // cosineSimilarity 计算两个向量的余弦相似度
@@ -244,6 +356,29 @@ func cosineSimilarity(a, b []float32) float32 {
 	return float32(dotProduct / (math.Sqrt(normA) * math.Sqrt(normB)))
 }
 
+// tagLink 计算缺少向量的节点之间的边：只使用标签的 Jaccard 系数
+func (g *graphNodes) tagLink(idA, idB string, threshold float32) (GraphLink, bool) {
+	tagsA, okA := g.tags[idA]
+	tagsB, okB := g.tags[idB]
+	if !okA || !okB {
+		return GraphLink{}, false
+	}
+	commonTags, unionSize := countCommonTags(tagsA, tagsB)
+	if commonTags == 0 {
+		return GraphLink{}, false
+	}
+	jaccard := float32(commonTags) / float32(unionSize)
+	if jaccard < threshold {
+		return GraphLink{}, false
+	}
+	return GraphLink{
+		Source:     idA,
+		Target:     idB,
+		Similarity: jaccard,
+		HasTags:    true,
+	}, true
+}
+
 // GetDocumentVectors 获取所有节点及其向量（供前端 UMAP 降维使用）
 func (s *Service) GetDocumentVectors() (*VectorGraphData, error) {
 	if err := s.init(); err != nil {
@@ -0,0 +1,206 @@
+package rag
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCountCommonTags_DuplicateTags(t *testing.T) {
+	// 文档 A 中 "go" 重复出现，不应让交集超过实际共同标签数
+	tagsA := tagSet([]string{"go", "go", "db"})
+	tagsB := tagSet([]string{"go", "web"})
+
+	common, union := countCommonTags(tagsA, tagsB)
+	if common != 1 || union != 3 {
+		t.Errorf("Expected common=1 union=3, got common=%d union=%d", common, union)
+	}
+
+	// 交换参数顺序结果应一致
+	if c, u := countCommonTags(tagsB, tagsA); c != common || u != union {
+		t.Errorf("Expected symmetric result, got common=%d union=%d", c, u)
+	}
+
+	if c, u := countCommonTags(tagSet(nil), tagsB); c != 0 || u != 2 {
+		t.Errorf("Expected common=0 union=2 for empty tags, got common=%d union=%d", c, u)
+	}
+}
+
+func TestDotProduct_MatchesCosineForUnitVectors(t *testing.T) {
+	a := []float32{3, 4, 0}
+	b := []float32{1, 2, 2}
+	got := dotProduct(normalizeVector(a), normalizeVector(b))
+	want := cosineSimilarity(a, b)
+	if math.Abs(float64(got-want)) > 1e-6 {
+		t.Errorf("Expected %f, got %f", want, got)
+	}
+	if dotProduct(a, []float32{1}) != 0 {
+		t.Error("Expected 0 for mismatched dimensions")
+	}
+}
+
+func TestGetDocumentGraphANN_SubsetOfExhaustive(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+	service := &Service{
+		paths:      indexer.paths,
+		store:      indexer.store,
+		embedder:   &recordingEmbedder{},
+		docRepo:    indexer.docRepo,
+		docStorage: docStorage,
+	}
+
+	vectors := [][]float32{
+		{1, 0, 0}, {0.95, 0.1, 0}, {0.9, 0.2, 0.1},
+		{0, 1, 0}, {0.1, 0.95, 0}, {0, 0.1, 1},
+	}
+	for i, vec := range vectors {
+		meta, err := indexer.docRepo.Create("doc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 2 {
+			if err := indexer.docRepo.AddTag(meta.ID, "shared"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := indexer.store.Upsert(&BlockVector{
+			ID: meta.ID + ":b1", SourceType: "document", DocID: meta.ID,
+			Content: "content", BlockType: "paragraph", Embedding: vec,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const threshold = 0.5
+	full, err := service.GetDocumentGraph(threshold)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exhaustive := make(map[[2]string]GraphLink)
+	for _, link := range full.Links {
+		exhaustive[graphPair(link)] = link
+	}
+
+	ann, err := service.GetDocumentGraphANN(threshold, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ann.Nodes) != len(vectors) {
+		t.Fatalf("Expected %d nodes, got %d", len(vectors), len(ann.Nodes))
+	}
+	if len(ann.Links) == 0 {
+		t.Fatal("Expected ANN graph to contain edges")
+	}
+	for _, link := range ann.Links {
+		want, ok := exhaustive[graphPair(link)]
+		if !ok {
+			t.Errorf("ANN edge %s-%s not in exhaustive graph", link.Source, link.Target)
+			continue
+		}
+		if math.Abs(float64(want.Similarity-link.Similarity)) > 1e-6 || want.HasTags != link.HasTags {
+			t.Errorf("ANN edge %s-%s differs: got %+v, want %+v", link.Source, link.Target, link, want)
+		}
+	}
+
+	// k 覆盖所有节点时与穷举结果一致
+	all, err := service.GetDocumentGraphANN(threshold, len(vectors))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Links) != len(full.Links) {
+		t.Errorf("Expected %d edges with k=n, got %d", len(full.Links), len(all.Links))
+	}
+}
+
+// graphPair 与方向无关的边标识
+func graphPair(link GraphLink) [2]string {
+	if link.Source > link.Target {
+		return [2]string{link.Target, link.Source}
+	}
+	return [2]string{link.Source, link.Target}
+}
+
+func TestGetDocumentGraph_UnembeddedTagEdges(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+	service := &Service{
+		paths:      indexer.paths,
+		store:      indexer.store,
+		embedder:   &recordingEmbedder{},
+		docRepo:    indexer.docRepo,
+		docStorage: docStorage,
+	}
+
+	var ids []string
+	for _, title := range []string{"Empty A", "Empty B"} {
+		meta, err := indexer.docRepo.Create(title)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range []string{"go", "db"} {
+			if err := indexer.docRepo.AddTag(meta.ID, tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ids = append(ids, meta.ID)
+	}
+
+	// 默认行为不变：没有向量的文档不出现在图谱中
+	graph, err := service.GetDocumentGraph(0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 0 || len(graph.Links) != 0 {
+		t.Fatalf("Expected empty graph by default, got %+v", graph)
+	}
+
+	graph, err = service.GetDocumentGraphWithOptions(0.5, GraphOptions{IncludeUnembedded: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %+v", graph.Nodes)
+	}
+	for _, node := range graph.Nodes {
+		if node.Val != 0 {
+			t.Errorf("Expected Val=0 for unembedded node, got %d", node.Val)
+		}
+	}
+	if len(graph.Links) != 1 {
+		t.Fatalf("Expected 1 tag edge, got %+v", graph.Links)
+	}
+	link := graph.Links[0]
+	if !link.HasTags || link.HasSemantic || link.Similarity != 1 {
+		t.Errorf("Expected tag-only edge with Jaccard 1, got %+v", link)
+	}
+	if graphPair(link) != graphPair(GraphLink{Source: "doc:" + ids[0], Target: "doc:" + ids[1]}) {
+		t.Errorf("Unexpected edge endpoints: %+v", link)
+	}
+}
+
+func TestSyncGraphNodes_SkipsUnchangedVectors(t *testing.T) {
+	indexer, _ := newTestIndexer(t, &recordingEmbedder{})
+	store := indexer.store
+
+	vectors := map[string][]float32{"doc:a": {1, 0, 0}, "doc:b": {0, 1, 0}}
+	if written, err := store.SyncGraphNodes(vectors); err != nil || !written {
+		t.Fatalf("Expected the first sync to write nodes, got written=%v err=%v", written, err)
+	}
+	if written, err := store.SyncGraphNodes(vectors); err != nil || written {
+		t.Fatalf("Expected unchanged vectors to be skipped, got written=%v err=%v", written, err)
+	}
+
+	vectors["doc:b"] = []float32{0, 0, 1}
+	if written, err := store.SyncGraphNodes(vectors); err != nil || !written {
+		t.Fatalf("Expected changed vectors to be rewritten, got written=%v err=%v", written, err)
+	}
+	if ids, err := store.NearestGraphNodes([]float32{0, 0, 1}, 1); err != nil || len(ids) != 1 || ids[0] != "doc:b" {
+		t.Errorf("Expected doc:b nearest to the new vector, got %v (err %v)", ids, err)
+	}
+
+	// 清空索引后指纹随之失效，重新同步时必须重写
+	if err := store.ClearVectors(); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := store.SyncGraphNodes(vectors); err != nil || !written {
+		t.Fatalf("Expected nodes to be rewritten after clearing the index, got written=%v err=%v", written, err)
+	}
+}
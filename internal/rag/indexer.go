@@ -1,10 +1,13 @@
 package rag
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"notion-lite/internal/document"
 	"notion-lite/internal/utils"
@@ -30,6 +33,9 @@ type Indexer struct {
 	docStorage  *document.Storage
 	chunkConfig ChunkConfig
 	paths       *utils.PathBuilder // 数据目录路径，用于删除物理文件
+	workers     int                // 全量重建并发数（<= 0 时使用 CPU 核数）
+	embedTitles bool               // 是否将文档标题作为独立 chunk 索引
+	models      *embedderSet       // 文档级覆盖模型的客户端（nil 时全部使用 embedder）
 }
 
 // NewIndexer 创建索引器
@@ -61,6 +67,73 @@ func (idx *Indexer) SetChunkConfig(config ChunkConfig) {
 	idx.chunkConfig = config
 }
 
+// SetWorkers 设置全量重建并发数
+func (idx *Indexer) SetWorkers(workers int) {
+	idx.workers = workers
+}
+
+// SetEmbedTitles 设置是否索引文档标题
+func (idx *Indexer) SetEmbedTitles(enabled bool) {
+	idx.embedTitles = enabled
+}
+
+// titleBlockType 文档标题 chunk 的块类型
+const titleBlockType = "title"
+
+// SetModelEmbedders 设置文档级覆盖模型的客户端集合
+func (idx *Indexer) SetModelEmbedders(models *embedderSet) {
+	idx.models = models
+}
+
+// docEmbedder 返回文档使用的嵌入客户端及记录到向量库的模型名（未覆盖时为默认客户端和空字符串）
+func (idx *Indexer) docEmbedder(doc document.Meta) (EmbeddingClient, string, error) {
+	if idx.models == nil {
+		return idx.embedder, "", nil
+	}
+	model := idx.models.modelKey(doc.EmbedModel)
+	client, err := idx.models.client(model)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, model, nil
+}
+
+// blockHash 文档块的内容哈希；使用覆盖模型时模型名计入哈希，切换模型后块会被重新嵌入
+func blockHash(block ExtractedBlock, model string) string {
+	if model == "" {
+		return HashContent(block.Content + block.HeadingContext)
+	}
+	return HashContent(block.Content + block.HeadingContext + "\x00" + model)
+}
+
+// extractDocumentBlocks 提取文档的待索引块（启用标题索引时追加标题 chunk）
+func (idx *Indexer) extractDocumentBlocks(doc document.Meta, content string) []ExtractedBlock {
+	blocks := ExtractBlocksWithConfig([]byte(content), idx.chunkConfig)
+	if !idx.embedTitles {
+		return blocks
+	}
+	if title := strings.TrimSpace(doc.Title); title != "" {
+		blocks = append(blocks, ExtractedBlock{
+			ID:      doc.ID + "_" + titleBlockType,
+			Type:    titleBlockType,
+			Content: title,
+		})
+	}
+	return blocks
+}
+
+// lookupDocument 查找文档元数据（标题、嵌入模型覆盖），未找到时只填充 ID
+func (idx *Indexer) lookupDocument(docID string) document.Meta {
+	if index, err := idx.docRepo.GetAll(); err == nil {
+		for _, doc := range index.Documents {
+			if doc.ID == docID {
+				return doc
+			}
+		}
+	}
+	return document.Meta{ID: docID}
+}
+
 // deletePhysicalFiles 删除物理文件
 func (idx *Indexer) deletePhysicalFiles(filePaths []string) {
 	for _, filePath := range filePaths {
@@ -81,11 +154,23 @@ func (idx *Indexer) deletePhysicalFiles(filePaths []string) {
 
 // IndexDocument 索引单个文档（增量更新）
 func (idx *Indexer) IndexDocument(docID string) error {
-	// 1. 加载文档内容
+	return idx.indexDocument(idx.lookupDocument(docID))
+}
+
+// indexDocument 使用已查到的文档元数据增量索引（批量索引时避免逐个文档重新读取文档列表）
+func (idx *Indexer) indexDocument(doc document.Meta) error {
+	docID := doc.ID
+	startedAt := time.Now().UnixMilli()
+
+	// 1. 加载文档内容，确定嵌入模型
 	content, err := idx.docStorage.Load(docID)
 	if err != nil {
 		return fmt.Errorf("failed to load document: %w", err)
 	}
+	embedder, model, err := idx.docEmbedder(doc)
+	if err != nil {
+		return err
+	}
 
 	// 2. 获取现有块的哈希
 	existingHashes, err := idx.store.GetBlockHashes(docID)
@@ -94,7 +179,7 @@ func (idx *Indexer) IndexDocument(docID string) error {
 	}
 
 	// 3. 使用配置提取新块并计算哈希
-	blocks := ExtractBlocksWithConfig([]byte(content), idx.chunkConfig)
+	blocks := idx.extractDocumentBlocks(doc, content)
 	newBlockIDs := make(map[string]bool)
 
 	// 调试输出：显示分块详情
@@ -111,55 +196,33 @@ func (idx *Indexer) IndexDocument(docID string) error {
 		fmt.Println("   ─────────────────────────────────────────────────")
 	}
 
+	var pending []ExtractedBlock
 	for _, block := range blocks {
 		if block.Content == "" {
 			continue
 		}
 		newBlockIDs[block.ID] = true
-		newHash := HashContent(block.Content + block.HeadingContext)
+		newHash := blockHash(block, model)
 
 		// 检查是否需要更新
 		if oldHash, exists := existingHashes[block.ID]; exists && oldHash == newHash {
 			// 内容没变，跳过
 			continue
 		}
+		pending = append(pending, block)
+	}
 
-		// 需要更新：生成新的 Embedding
-		embedding, err := idx.embedder.Embed(block.Content)
-		if err != nil {
-			// 检查是否是不可恢复的错误（5xx 服务端错误）
-			if serviceErr, ok := IsEmbeddingServiceError(err); ok && serviceErr.IsUnrecoverable() {
-				fmt.Printf("❌ [RAG] Embedding service unavailable (status %d), aborting indexing\n", serviceErr.StatusCode)
-				return fmt.Errorf("embedding service unavailable: %w", err)
-			}
-			fmt.Printf("⚠️ [RAG] Failed to embed block %s: %v\n", block.ID, err)
-			continue
-		}
-		// 若 block 本身是聚合/合并块，使用其 SourceBlockID；否则使用 block.ID
-		sourceBlockID := block.SourceBlockID
-		if sourceBlockID == "" {
-			sourceBlockID = block.ID
-		}
-		if err := idx.store.Upsert(&BlockVector{
-			ID:             block.ID,
-			SourceBlockID:  sourceBlockID,
-			SourceType:     "document",
-			DocID:          docID,
-			Content:        block.Content,
-			ContentHash:    newHash,
-			BlockType:      block.Type,
-			HeadingContext: block.HeadingContext,
-			Embedding:      embedding,
-		}); err != nil {
-			fmt.Printf("⚠️ [RAG] Failed to upsert block %s: %v\n", block.ID, err)
-		}
+	// 需要更新：批量生成新的 Embedding
+	_, failedCount, _, err := idx.embedAndStore(docID, pending, embedder, model)
+	if err != nil {
+		return err
 	}
 
 	// 4. 删除已不存在的块（保护 bookmark 和 file 块）
 	var toDelete []string
 	for id := range existingHashes {
 		// 常规块：如果在新的块列表中不存在，且不是 bookmark/file/folder，则删除
-		if !newBlockIDs[id] && !strings.Contains(id, "_bookmark") && !strings.Contains(id, "_file") && !strings.Contains(id, "_folder") {
+		if !newBlockIDs[id] && !isExternalBlockID(id) {
 			toDelete = append(toDelete, id)
 		}
 	}
@@ -184,16 +247,32 @@ func (idx *Indexer) IndexDocument(docID string) error {
 	// 删除孤儿物理文件
 	idx.deletePhysicalFiles(orphanFilePaths)
 
+	// 有块嵌入失败时不记录索引时间，留待后台重建重试
+	if failedCount == 0 {
+		idx.markIndexed(docID, startedAt)
+	}
 	return nil
 }
 
 // ForceReindexDocument 强制重建单个文档索引（删除所有旧块后重新索引）
 func (idx *Indexer) ForceReindexDocument(docID string) error {
-	// 1. 加载文档内容
+	return idx.forceReindexDocument(idx.lookupDocument(docID))
+}
+
+// forceReindexDocument 使用已查到的文档元数据强制重建索引
+func (idx *Indexer) forceReindexDocument(doc document.Meta) error {
+	docID := doc.ID
+	startedAt := time.Now().UnixMilli()
+
+	// 1. 加载文档内容，确定嵌入模型
 	content, err := idx.docStorage.Load(docID)
 	if err != nil {
 		return fmt.Errorf("failed to load document: %w", err)
 	}
+	embedder, model, err := idx.docEmbedder(doc)
+	if err != nil {
+		return err
+	}
 
 	// 2. 清理旧索引
 	// 删除该文档的所有非 bookmark 块
@@ -217,7 +296,7 @@ func (idx *Indexer) ForceReindexDocument(docID string) error {
 	idx.deletePhysicalFiles(orphanFilePaths)
 
 	// 3. 使用新配置提取块
-	blocks := ExtractBlocksWithConfig([]byte(content), idx.chunkConfig)
+	blocks := idx.extractDocumentBlocks(doc, content)
 
 	// 调试输出
 	if debugChunks {
@@ -234,50 +313,9 @@ func (idx *Indexer) ForceReindexDocument(docID string) error {
 	}
 
 	// 4. 为每个块生成 embedding 并存储
-	successCount := 0
-	failedCount := 0
-	var lastError error
-	for _, block := range blocks {
-		if block.Content == "" {
-			continue
-		}
-
-		embedding, err := idx.embedder.Embed(block.Content)
-		if err != nil {
-			// 检查是否是不可恢复的错误（5xx 服务端错误）
-			if serviceErr, ok := IsEmbeddingServiceError(err); ok && serviceErr.IsUnrecoverable() {
-				fmt.Printf("❌ [RAG] Embedding service unavailable (status %d), aborting reindexing\n", serviceErr.StatusCode)
-				return fmt.Errorf("embedding service unavailable: %w", err)
-			}
-			failedCount++
-			lastError = err
-			fmt.Printf("⚠️ [RAG] Failed to embed block %s: %v\n", block.ID, err)
-			continue
-		}
-
-		// 若 block 本身是聚合/合并块，使用其 SourceBlockID；否则使用 block.ID
-		sourceBlockID := block.SourceBlockID
-		if sourceBlockID == "" {
-			sourceBlockID = block.ID
-		}
-
-		newHash := HashContent(block.Content + block.HeadingContext)
-		if err := idx.store.Upsert(&BlockVector{
-			ID:             block.ID,
-			SourceBlockID:  sourceBlockID,
-			SourceType:     "document",
-			DocID:          docID,
-			Content:        block.Content,
-			ContentHash:    newHash,
-			BlockType:      block.Type,
-			HeadingContext: block.HeadingContext,
-			Embedding:      embedding,
-		}); err != nil {
-			fmt.Printf("⚠️ [RAG] Failed to upsert block %s: %v\n", block.ID, err)
-			failedCount++
-		} else {
-			successCount++
-		}
+	successCount, failedCount, lastError, err := idx.embedAndStore(docID, blocks, embedder, model)
+	if err != nil {
+		return err
 	}
 
 	// 如果所有块都嵌入失败，返回错误
@@ -285,76 +323,200 @@ func (idx *Indexer) ForceReindexDocument(docID string) error {
 		return fmt.Errorf("embedding failed: %v", lastError)
 	}
 
+	if failedCount == 0 {
+		idx.markIndexed(docID, startedAt)
+	}
 	return nil
 }
 
-// ReindexAll 重建所有文档索引（强制模式，清除旧数据，清理孤儿块）
-func (idx *Indexer) ReindexAll() (int, error) {
+// markIndexed 记录文档索引完成时间
+// 使用索引开始时间，确保索引期间发生的编辑仍会被判定为过期
+func (idx *Indexer) markIndexed(docID string, startedAt int64) {
+	if err := idx.store.MarkDocIndexed(docID, startedAt); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to record index time for doc %s: %v\n", docID, err)
+	}
+}
+
+// FindStaleDocuments 查找索引过期的文档（最近编辑晚于最近一次索引，或从未完成索引）
+func (idx *Indexer) FindStaleDocuments() ([]string, error) {
 	index, err := idx.docRepo.GetAll()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get documents: %w", err)
+		return nil, fmt.Errorf("failed to get documents: %w", err)
 	}
 
-	// 构建现有文档 ID 集合
-	existingDocIDs := make(map[string]bool)
+	indexTimes, err := idx.store.GetDocIndexTimes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index times: %w", err)
+	}
+
+	var stale []string
 	for _, doc := range index.Documents {
-		existingDocIDs[doc.ID] = true
+		indexedAt, ok := indexTimes[doc.ID]
+		if !ok || doc.UpdatedAt > indexedAt {
+			stale = append(stale, doc.ID)
+		}
 	}
+	return stale, nil
+}
 
-	// 清理已删除文档的孤儿块
-	indexedDocIDs, err := idx.store.GetAllDocIDs()
-	if err == nil {
-		for _, docID := range indexedDocIDs {
-			if !existingDocIDs[docID] {
-				if debugChunks {
-					fmt.Printf("🗑️ [RAG] Cleaning orphan blocks for deleted document: %s\n", docID)
-				}
-				if err := idx.store.DeleteByDocID(docID); err != nil {
-					fmt.Printf("⚠️ [RAG] Failed to delete blocks for doc %s: %v\n", docID, err)
-				}
-			}
+// embedBatchSize 单次批量嵌入请求的块数
+const embedBatchSize = 64
+
+// embedAndStore 使用 embedder 分批生成块的 embedding 并写入存储（model 为记录到向量库的覆盖模型名）
+// 返回成功数、失败数、最后一个块级错误；嵌入服务不可用时返回 fatal 错误并中止
+func (idx *Indexer) embedAndStore(docID string, blocks []ExtractedBlock, embedder EmbeddingClient, model string) (successCount, failedCount int, lastError, fatal error) {
+	var batch []ExtractedBlock
+	for _, block := range blocks {
+		if block.Content != "" {
+			batch = append(batch, block)
 		}
 	}
 
-	// 重建索引
-	count := 0
-	failedCount := 0
-	var lastError error
-	for _, doc := range index.Documents {
-		if err := idx.ForceReindexDocument(doc.ID); err != nil {
-			failedCount++
+	for start := 0; start < len(batch); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		chunk := batch[start:end]
+
+		texts := make([]string, len(chunk))
+		for i, block := range chunk {
+			texts[i] = block.Content
+		}
+
+		embeddings, err := embedder.EmbedBatch(texts)
+		if err == nil && len(embeddings) != len(chunk) {
+			err = fmt.Errorf("embedding count mismatch: got %d, want %d", len(embeddings), len(chunk))
+		}
+		if err != nil {
+			// 检查是否是不可恢复的错误（5xx 服务端错误）
+			if serviceErr, ok := IsEmbeddingServiceError(err); ok && serviceErr.IsUnrecoverable() {
+				fmt.Printf("❌ [RAG] Embedding service unavailable (status %d), aborting indexing\n", serviceErr.StatusCode)
+				return successCount, failedCount, lastError, fmt.Errorf("embedding service unavailable: %w", err)
+			}
+			failedCount += len(chunk)
 			lastError = err
-			continue // 跳过失败的文档
+			fmt.Printf("⚠️ [RAG] Failed to embed %d blocks for doc %s: %v\n", len(chunk), docID, err)
+			continue
+		}
+
+		vectors := make([]*BlockVector, len(chunk))
+		for i, block := range chunk {
+			vectors[i] = idx.newDocumentVector(docID, block, embeddings[i], model)
+		}
+		if err := idx.store.UpsertBatch(vectors); err == nil {
+			successCount += len(vectors)
+			continue
 		}
-		count++
+		// 整批写入失败时逐块重试，只把真正失败的块计为失败
+		for _, vector := range vectors {
+			if err := idx.store.Upsert(vector); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to upsert block %s: %v\n", vector.ID, err)
+				failedCount++
+			} else {
+				successCount++
+			}
+		}
+	}
+
+	return successCount, failedCount, lastError, nil
+}
+
+// newDocumentVector 构建文档块的向量记录
+func (idx *Indexer) newDocumentVector(docID string, block ExtractedBlock, embedding []float32, model string) *BlockVector {
+	// 若 block 本身是聚合/合并块，使用其 SourceBlockID；否则使用 block.ID
+	// 标题 chunk 不对应编辑器中的块，不记录定位 ID
+	sourceBlockID := block.SourceBlockID
+	if sourceBlockID == "" && block.Type != titleBlockType {
+		sourceBlockID = block.ID
+	}
+	return &BlockVector{
+		ID:             block.ID,
+		SourceBlockID:  sourceBlockID,
+		SourceType:     "document",
+		DocID:          docID,
+		Content:        block.Content,
+		ContentHash:    blockHash(block, model),
+		BlockType:      block.Type,
+		HeadingContext: block.HeadingContext,
+		EmbedModel:     model,
+		Embedding:      embedding,
+	}
+}
+
+// DocIndexError 单个文档的索引错误
+type DocIndexError struct {
+	DocID string `json:"docId"`
+	Title string `json:"title"`
+	Error string `json:"error"`
+}
+
+// ReindexReport 全量重建结果
+type ReindexReport struct {
+	Total   int             `json:"total"`   // 文档总数
+	Indexed int             `json:"indexed"` // 成功索引的文档数
+	Failed  []DocIndexError `json:"failed"`  // 失败的文档及原因
+}
+
+// ReindexProgress 全量重建进度
+type ReindexProgress struct {
+	Phase        string `json:"phase"`        // "documents" | "external"
+	Current      int    `json:"current"`      // 本阶段已完成数
+	Total        int    `json:"total"`        // 本阶段总数
+	Title        string `json:"title"`        // 刚处理完的文档标题（外部内容阶段为块所在文档）
+	OverallDone  int    `json:"overallDone"`  // 两个阶段合计已完成数（仅 Service.RebuildAll 填写）
+	OverallTotal int    `json:"overallTotal"` // 两个阶段合计总数（仅 Service.RebuildAll 填写）
+}
+
+// ReindexAll 重建所有文档索引（强制模式，清除旧数据，清理孤儿块），只返回成功数
+// 需要知道哪些文档失败时使用 ReindexAllDocuments
+func (idx *Indexer) ReindexAll(ctx context.Context) (int, error) {
+	return idx.ReindexAllWithCallback(ctx, nil)
+}
+
+// ReindexAllWithCallback 重建所有文档索引（带进度回调，current 为已完成数）
+// ctx 取消时返回已完成的文档数和 ctx.Err()
+func (idx *Indexer) ReindexAllWithCallback(ctx context.Context, onProgress func(current, total int)) (int, error) {
+	var progress func(ReindexProgress)
+	if onProgress != nil {
+		progress = func(p ReindexProgress) { onProgress(p.Current, p.Total) }
+	}
+	report, err := idx.ReindexAllDocuments(ctx, progress)
+	if report == nil {
+		return 0, err
+	}
+	if err != nil {
+		return report.Indexed, err
 	}
 
 	// 如果所有文档都失败了，返回错误
-	if count == 0 && failedCount > 0 {
-		return 0, fmt.Errorf("all documents failed to index: %v", lastError)
+	if report.Indexed == 0 && len(report.Failed) > 0 {
+		return 0, fmt.Errorf("all documents failed to index: %s", report.Failed[len(report.Failed)-1].Error)
 	}
 
-	return count, nil
+	return report.Indexed, nil
 }
 
-// ReindexAllWithCallback 重建所有文档索引（带进度回调）
-func (idx *Indexer) ReindexAllWithCallback(onProgress func(current, total int)) (int, error) {
+// ReindexAllDocuments 使用 worker pool 并行重建所有文档索引，收集每个文档的错误
+// 每完成一个文档调用一次 onProgress（Current 单调递增，串行调用）
+// ctx 取消后不再开始新文档（进行中的文档会完成），返回部分报告和 ctx.Err()
+func (idx *Indexer) ReindexAllDocuments(ctx context.Context, onProgress func(ReindexProgress)) (*ReindexReport, error) {
 	index, err := idx.docRepo.GetAll()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get documents: %w", err)
+		return nil, fmt.Errorf("failed to get documents: %w", err)
 	}
 
-	// 构建现有文档 ID 集合
-	existingDocIDs := make(map[string]bool)
+	// 构建现有文档 ID -> 标题映射
+	existingDocIDs := make(map[string]string)
 	for _, doc := range index.Documents {
-		existingDocIDs[doc.ID] = true
+		existingDocIDs[doc.ID] = doc.Title
 	}
 
 	// 清理已删除文档的孤儿块
 	indexedDocIDs, err := idx.store.GetAllDocIDs()
 	if err == nil {
 		for _, docID := range indexedDocIDs {
-			if !existingDocIDs[docID] {
+			if _, ok := existingDocIDs[docID]; !ok {
 				if debugChunks {
 					fmt.Printf("🗑️ [RAG] Cleaning orphan blocks for deleted document: %s\n", docID)
 				}
@@ -365,29 +527,69 @@ func (idx *Indexer) ReindexAllWithCallback(onProgress func(current, total int))
 		}
 	}
 
-	// 重建索引
-	total := len(index.Documents)
-	count := 0
-	failedCount := 0
-	var lastError error
-	for i, doc := range index.Documents {
-		// 发送进度
-		if onProgress != nil {
-			onProgress(i+1, total)
-		}
+	// 重建索引（嵌入请求并行，存储写入由 VectorStore 串行化）
+	report := &ReindexReport{Total: len(index.Documents), Failed: []DocIndexError{}}
+	workers := idx.workerCount()
+	if workers > report.Total {
+		workers = report.Total
+	}
 
-		if err := idx.ForceReindexDocument(doc.ID); err != nil {
-			failedCount++
-			lastError = err
-			continue // 跳过失败的文档
+	var mu sync.Mutex
+	completed := 0
+	jobs := make(chan document.Meta)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for doc := range jobs {
+				if ctx.Err() != nil {
+					continue // 已取消：排空队列
+				}
+				// 文档元数据（含嵌入模型覆盖）在本次重建开始时读取一次
+				err := idx.forceReindexDocument(doc)
+
+				mu.Lock()
+				if err != nil {
+					fmt.Printf("⚠️ [RAG] Failed to reindex doc %s: %v\n", doc.ID, err)
+					report.Failed = append(report.Failed, DocIndexError{DocID: doc.ID, Title: doc.Title, Error: err.Error()})
+				} else {
+					report.Indexed++
+				}
+				completed++
+				if onProgress != nil {
+					onProgress(ReindexProgress{Phase: "documents", Current: completed, Total: report.Total, Title: doc.Title})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+dispatch:
+	for _, doc := range index.Documents {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- doc:
 		}
-		count++
 	}
+	close(jobs)
+	wg.Wait()
 
-	// 如果所有文档都失败了，返回错误
-	if count == 0 && failedCount > 0 {
-		return 0, fmt.Errorf("all documents failed to index: %v", lastError)
+	if err := ctx.Err(); err != nil {
+		fmt.Printf("⚠️ [RAG] Reindex cancelled after %d of %d documents\n", report.Indexed+len(report.Failed), report.Total)
+		return report, err
 	}
+	return report, nil
+}
 
-	return count, nil
+// defaultReindexWorkers 未配置时的全量重建并发数
+// 瓶颈在嵌入服务而非 CPU：每个文档的 EmbedBatch 本身已并发请求（Ollama 为 ollamaBatchWorkers），按 CPU 核数并发会压垮本地 Ollama
+const defaultReindexWorkers = 2
+
+// workerCount 返回重建索引的并发数
+func (idx *Indexer) workerCount() int {
+	if idx.workers <= 0 {
+		return defaultReindexWorkers
+	}
+	return idx.workers
 }
@@ -0,0 +1,957 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"notion-lite/internal/document"
+	"notion-lite/internal/utils"
+)
+
+// recordingEmbedder 记录批量嵌入请求的测试替身
+type recordingEmbedder struct {
+	batches [][]string
+}
+
+func (e *recordingEmbedder) Embed(text string) ([]float32, error) {
+	return e.EmbedWithType(text, EmbedKindDocument)
+}
+
+func (e *recordingEmbedder) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	return []float32{1, 0, 0}, nil
+}
+
+func (e *recordingEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	e.batches = append(e.batches, append([]string(nil), texts...))
+	result := make([][]float32, len(texts))
+	for i := range texts {
+		result[i] = []float32{1, 0, 0}
+	}
+	return result, nil
+}
+
+func (e *recordingEmbedder) Dimension() int { return 3 }
+
+func (e *recordingEmbedder) Ping() error { return nil }
+
+// newTestIndexer 创建使用临时目录的索引器
+func newTestIndexer(t testing.TB, embedder EmbeddingClient) (*Indexer, *document.Storage) {
+	t.Helper()
+	dir := t.TempDir()
+	paths := utils.NewPathBuilder(dir)
+	if err := os.MkdirAll(paths.DocumentsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewVectorStore(filepath.Join(dir, "vectors.db"), 3)
+	if err != nil {
+		t.Fatalf("Failed to create vector store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	docStorage := document.NewStorage(paths)
+	return NewIndexer(store, embedder, document.NewRepository(paths), docStorage, paths), docStorage
+}
+
+func TestIndexDocument_BatchesOnlyChangedBlocks(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+
+	longA := "第一段内容，足够长以避免被合并为短块。" + "这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引到向量库中去。这里补充更多文字让它超过短块阈值。"
+	longB := "第二段内容，同样需要足够长才不会被合并。这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引到向量库中去。这里补充更多文字让它超过短块阈值。"
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + longA + `"}]},
+		{"id": "p2", "type": "paragraph", "content": [{"type": "text", "text": "` + longB + `"}]}
+	]`
+	if err := docStorage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	if len(embedder.batches) != 1 || len(embedder.batches[0]) != 2 {
+		t.Fatalf("Expected one batch with 2 blocks, got %v", embedder.batches)
+	}
+
+	// 仅修改 p2，重新索引时只有 p2 被发送
+	changedB := longB + "新增的句子。"
+	content = `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + longA + `"}]},
+		{"id": "p2", "type": "paragraph", "content": [{"type": "text", "text": "` + changedB + `"}]}
+	]`
+	if err := docStorage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+
+	embedder.batches = nil
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	if len(embedder.batches) != 1 || len(embedder.batches[0]) != 1 || embedder.batches[0][0] != changedB {
+		t.Errorf("Expected only the changed block to be embedded, got %v", embedder.batches)
+	}
+
+	// 内容不变时不应发送任何请求
+	embedder.batches = nil
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	if len(embedder.batches) != 0 {
+		t.Errorf("Expected no embedding requests for unchanged document, got %v", embedder.batches)
+	}
+}
+
+func TestPlanReindex_DoesNotEmbed(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+
+	longA := "第一段内容，足够长以避免被合并为短块。" + "这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引到向量库中去。这里补充更多文字让它超过短块阈值。"
+	longB := "第二段内容，同样需要足够长才不会被合并。这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引到向量库中去。这里补充更多文字让它超过短块阈值。"
+	longC := "第三段内容，是后来新增的段落，同样需要足够长。这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引到向量库中去。"
+	paragraph := func(id, text string) string {
+		return `{"id": "` + id + `", "type": "paragraph", "content": [{"type": "text", "text": "` + text + `"}]}`
+	}
+
+	meta, err := indexer.docRepo.Create("Plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := docStorage.Save(meta.ID, "["+paragraph("p1", longA)+","+paragraph("p2", longB)+"]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.IndexDocument(meta.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 删除 p1、修改 p2、新增 p3
+	if err := docStorage.Save(meta.ID, "["+paragraph("p2", longB+"新增的句子。")+","+paragraph("p3", longC)+"]"); err != nil {
+		t.Fatal(err)
+	}
+	embedder.batches = nil
+	plan, err := indexer.PlanReindex()
+	if err != nil {
+		t.Fatalf("PlanReindex failed: %v", err)
+	}
+	if len(embedder.batches) != 0 {
+		t.Errorf("Expected no embedding requests, got %v", embedder.batches)
+	}
+	if plan.Add != 1 || plan.Update != 1 || plan.Delete != 1 || plan.Unchanged != 0 {
+		t.Errorf("Expected add=1 update=1 delete=1, got %+v", plan)
+	}
+	if plan.Embeddings != 2 || plan.EmbedRequests != 1 || plan.RebuildEmbeddings != 2 {
+		t.Errorf("Unexpected embedding estimate: %+v", plan)
+	}
+	if len(plan.Documents) != 1 || plan.Documents[0].DocID != meta.ID || plan.Documents[0].Title != "Plan" {
+		t.Errorf("Expected per-document breakdown for %s, got %+v", meta.ID, plan.Documents)
+	}
+
+	// 计划与实际增量索引一致
+	if err := indexer.IndexDocument(meta.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(embedder.batches) != 1 || len(embedder.batches[0]) != plan.Embeddings {
+		t.Errorf("Expected %d embedded chunks, got %v", plan.Embeddings, embedder.batches)
+	}
+	plan, err = indexer.PlanReindex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Embeddings != 0 || len(plan.Documents) != 0 || plan.Unchanged != 2 {
+		t.Errorf("Expected nothing to do after indexing, got %+v", plan)
+	}
+}
+
+func TestFindStaleDocuments(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+
+	if _, err := indexer.docRepo.CreateWithID("doc1", "文档"); err != nil {
+		t.Fatal(err)
+	}
+	content := `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "内容"}]}]`
+	if err := docStorage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+
+	// 从未索引的文档视为过期
+	stale, err := indexer.FindStaleDocuments()
+	if err != nil {
+		t.Fatalf("FindStaleDocuments failed: %v", err)
+	}
+	if len(stale) != 1 || stale[0] != "doc1" {
+		t.Fatalf("Expected doc1 to be stale before indexing, got %v", stale)
+	}
+
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	if stale, _ := indexer.FindStaleDocuments(); len(stale) != 0 {
+		t.Fatalf("Expected no stale documents after indexing, got %v", stale)
+	}
+
+	// 索引后再编辑，应重新视为过期
+	time.Sleep(5 * time.Millisecond)
+	if err := indexer.docRepo.UpdateTimestamp("doc1"); err != nil {
+		t.Fatal(err)
+	}
+	if stale, _ := indexer.FindStaleDocuments(); len(stale) != 1 {
+		t.Errorf("Expected doc1 to be stale after edit, got %v", stale)
+	}
+}
+
+// latencyEmbedder 每次请求带固定延迟的测试替身，模拟 HTTP 往返
+type latencyEmbedder struct {
+	recordingEmbedder
+	latency time.Duration
+}
+
+func (e *latencyEmbedder) Embed(text string) ([]float32, error) {
+	time.Sleep(e.latency)
+	return []float32{1, 0, 0}, nil
+}
+
+func (e *latencyEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	time.Sleep(e.latency)
+	result := make([][]float32, len(texts))
+	for i := range texts {
+		result[i] = []float32{1, 0, 0}
+	}
+	return result, nil
+}
+
+func BenchmarkIndexBlocks_500(b *testing.B) {
+	blocks := make([]ExtractedBlock, 500)
+	for i := range blocks {
+		blocks[i] = ExtractedBlock{
+			ID:      fmt.Sprintf("b%d", i),
+			Type:    "paragraph",
+			Content: fmt.Sprintf("第 %d 个块的内容", i),
+		}
+	}
+
+	// 旧实现：逐块嵌入并写入
+	b.Run("sequential", func(b *testing.B) {
+		embedder := &latencyEmbedder{latency: 200 * time.Microsecond}
+		indexer, _ := newTestIndexer(b, embedder)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			for _, block := range blocks {
+				emb, err := embedder.Embed(block.Content)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := indexer.store.Upsert(indexer.newDocumentVector("doc1", block, emb, "")); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	// 新实现：按 embedBatchSize 分批嵌入
+	b.Run("batched", func(b *testing.B) {
+		embedder := &latencyEmbedder{latency: 200 * time.Microsecond}
+		indexer, _ := newTestIndexer(b, embedder)
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			if _, _, _, err := indexer.embedAndStore("doc1", blocks, indexer.embedder, ""); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// selectiveEmbedder 对包含指定文本的输入返回错误的测试替身
+type selectiveEmbedder struct {
+	latencyEmbedder
+	fail string
+}
+
+func (e *selectiveEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	for _, text := range texts {
+		if strings.Contains(text, e.fail) {
+			return nil, &EmbeddingServiceError{Provider: "test", StatusCode: 400, Message: "bad input"}
+		}
+	}
+	return e.latencyEmbedder.EmbedBatch(texts)
+}
+
+func TestReindexAllDocuments_ParallelCollectsErrors(t *testing.T) {
+	embedder := &selectiveEmbedder{latencyEmbedder: latencyEmbedder{latency: time.Millisecond}, fail: "无法嵌入"}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	indexer.SetWorkers(4)
+
+	content := `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "内容"}]}]`
+	for i := 0; i < 10; i++ {
+		docID := fmt.Sprintf("doc%d", i)
+		if _, err := indexer.docRepo.CreateWithID(docID, docID); err != nil {
+			t.Fatal(err)
+		}
+		if err := docStorage.Save(docID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 嵌入失败的文档应被记录为失败，而不是被静默跳过
+	if _, err := indexer.docRepo.CreateWithID("broken", "broken"); err != nil {
+		t.Fatal(err)
+	}
+	broken := `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "无法嵌入"}]}]`
+	if err := docStorage.Save("broken", broken); err != nil {
+		t.Fatal(err)
+	}
+
+	var lastCurrent, calls int
+	report, err := indexer.ReindexAllDocuments(context.Background(), func(p ReindexProgress) {
+		calls++
+		lastCurrent = p.Current
+		if p.Total != 11 {
+			t.Errorf("Expected total 11, got %d", p.Total)
+		}
+	})
+	if err != nil {
+		t.Fatalf("ReindexAllDocuments failed: %v", err)
+	}
+	if report.Indexed != 10 {
+		t.Errorf("Expected 10 indexed documents, got %d", report.Indexed)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].DocID != "broken" {
+		t.Errorf("Expected only 'broken' to fail, got %+v", report.Failed)
+	}
+	if calls != 11 || lastCurrent != 11 {
+		t.Errorf("Expected 11 progress callbacks ending at 11, got calls=%d last=%d", calls, lastCurrent)
+	}
+}
+
+func TestReindexAllDocuments_ReportsUnloadableDocument(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+	indexer.SetWorkers(2)
+
+	for _, id := range []string{"doc1", "doc2"} {
+		if _, err := indexer.docRepo.CreateWithID(id, id); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf(`[{"id": "%s-p1", "type": "paragraph", "content": [{"type": "text", "text": "内容"}]}]`, id)
+		if err := docStorage.Save(id, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 内容文件无法读取（路径被目录占用）
+	if _, err := indexer.docRepo.CreateWithID("missing", "无法读取的文档"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(indexer.paths.Document("missing")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(indexer.paths.Document("missing"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := indexer.ReindexAllDocuments(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ReindexAllDocuments failed: %v", err)
+	}
+	if report.Total != 3 || report.Indexed != 2 {
+		t.Errorf("Expected 2 of 3 documents indexed, got %d of %d", report.Indexed, report.Total)
+	}
+	if len(report.Failed) != 1 {
+		t.Fatalf("Expected one failure, got %+v", report.Failed)
+	}
+	failure := report.Failed[0]
+	if failure.DocID != "missing" || failure.Title != "无法读取的文档" || !strings.Contains(failure.Error, "failed to load document") {
+		t.Errorf("Unexpected failure entry: %+v", failure)
+	}
+}
+
+// cancellingEmbedder 在第 cancelAt 次批量嵌入后取消上下文的测试替身
+type cancellingEmbedder struct {
+	recordingEmbedder
+	cancelAt int32
+	cancel   context.CancelFunc
+	calls    atomic.Int32
+}
+
+func (e *cancellingEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	if e.calls.Add(1) == e.cancelAt {
+		e.cancel()
+	}
+	return e.recordingEmbedder.EmbedBatch(texts)
+}
+
+func TestReindexAllDocuments_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	embedder := &cancellingEmbedder{cancelAt: 2, cancel: cancel}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	indexer.SetWorkers(1)
+
+	for i := 0; i < 5; i++ {
+		docID := fmt.Sprintf("doc%d", i)
+		if _, err := indexer.docRepo.CreateWithID(docID, docID); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf(`[{"id": "p%d", "type": "paragraph", "content": [{"type": "text", "text": "内容 %d"}]}]`, i, i)
+		if err := docStorage.Save(docID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := indexer.ReindexAllDocuments(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	// 取消后不再发起新的嵌入请求
+	if calls := embedder.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 embed calls before cancellation, got %d", calls)
+	}
+	if report.Indexed > 2 {
+		t.Errorf("Expected at most 2 indexed documents, got %d", report.Indexed)
+	}
+}
+
+func TestServiceReindexAllWithProgress_Monotonic(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	indexer.SetWorkers(2)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+	service := &Service{
+		paths:           indexer.paths,
+		embedder:        embedder,
+		indexer:         indexer,
+		externalIndexer: external,
+		docRepo:         indexer.docRepo,
+		docStorage:      docStorage,
+	}
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("外部文件内容。"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		docID := fmt.Sprintf("doc%d", i)
+		if _, err := indexer.docRepo.CreateWithID(docID, "标题 "+docID); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf(`[{"id": "p%d", "type": "paragraph", "content": [{"type": "text", "text": "内容 %d"}]}]`, i, i)
+		if i == 0 {
+			content = fmt.Sprintf(`[{"id": "f0", "type": "file", "props": {"originalPath": %q, "fileName": "notes.txt"}}]`, file)
+		}
+		if err := docStorage.Save(docID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 合计进度：3 个文档 + 1 个文件块，total 不变，current 单调递增到 total
+	var currents []int
+	count, err := service.ReindexAllWithProgress(context.Background(), func(current, total int) {
+		if total != 4 {
+			t.Errorf("Expected total 4, got %d", total)
+		}
+		currents = append(currents, current)
+	})
+	if err != nil {
+		t.Fatalf("ReindexAllWithProgress failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 successful items, got %d", count)
+	}
+	if len(currents) != 4 {
+		t.Fatalf("Expected 4 progress callbacks, got %v", currents)
+	}
+	for i, current := range currents {
+		if current != i+1 {
+			t.Errorf("Expected progress to increase by one, got %v", currents)
+			break
+		}
+	}
+
+	// 分阶段进度附带文档标题；外部块的进度在索引完成后才报告
+	if err := indexer.store.DeleteExternalContent("doc0", "f0"); err != nil {
+		t.Fatal(err)
+	}
+	var phases []string
+	_, _, err = service.RebuildAll(context.Background(), false, func(p ReindexProgress) {
+		if !strings.HasPrefix(p.Title, "标题 ") {
+			t.Errorf("Expected a document title, got %q", p.Title)
+		}
+		if p.Phase == "external" {
+			if content, _ := indexer.store.GetExternalContent("doc0", "f0"); content == nil {
+				t.Error("Expected external progress to be reported after the block is indexed")
+			}
+		}
+		phases = append(phases, p.Phase)
+	})
+	if err != nil {
+		t.Fatalf("RebuildAll failed: %v", err)
+	}
+	if strings.Join(phases, ",") != "documents,documents,documents,external" {
+		t.Errorf("Unexpected phases: %v", phases)
+	}
+}
+
+func TestReindexStaleDocuments_DimensionChanged(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	service := &Service{
+		paths:      indexer.paths,
+		embedder:   embedder,
+		indexer:    indexer,
+		docRepo:    indexer.docRepo,
+		docStorage: docStorage,
+		model:      "ollama:model",
+		dimension:  8, // 缓存的维度与嵌入服务返回的 3 维不一致
+	}
+	if _, err := indexer.docRepo.CreateWithID("doc1", "标题"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 维度变化时不在后台重新初始化，交由调用方处理，当前的 indexer 保持可用
+	if _, err := service.ReindexStaleDocuments(nil); !errors.Is(err, ErrDimensionChanged) {
+		t.Fatalf("Expected ErrDimensionChanged, got %v", err)
+	}
+	if service.indexer != indexer || service.embedder == nil {
+		t.Error("Expected the service not to be reinitialized in the background")
+	}
+}
+
+func TestReconcileModel_SameDimensionSwitch(t *testing.T) {
+	indexer, _ := newTestIndexer(t, &recordingEmbedder{})
+	store := indexer.store
+
+	// 首次记录模型标识，不触发重建
+	changed, err := reconcileModel(store, "ollama:model-a")
+	if err != nil || changed {
+		t.Fatalf("Expected first record to be unchanged, got changed=%v err=%v", changed, err)
+	}
+
+	if err := store.Upsert(&BlockVector{ID: "b1", DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一模型不触发重建，向量保留
+	if changed, _ := reconcileModel(store, "ollama:model-a"); changed {
+		t.Error("Expected same model to be unchanged")
+	}
+	if count, _ := store.GetIndexedDocCount(); count != 1 {
+		t.Fatalf("Expected vectors to be kept, got %d indexed docs", count)
+	}
+
+	// 切换到同维度的另一个模型：清空旧向量并要求重建
+	changed, err = reconcileModel(store, "ollama:model-b")
+	if err != nil || !changed {
+		t.Fatalf("Expected model switch to be detected, got changed=%v err=%v", changed, err)
+	}
+	if count, _ := store.GetIndexedDocCount(); count != 0 {
+		t.Errorf("Expected old vectors to be cleared, got %d indexed docs", count)
+	}
+	if stored, _ := store.GetMeta(metaKeyModel); stored != "ollama:model-b" {
+		t.Errorf("Expected new model to be recorded, got %q", stored)
+	}
+}
+
+func TestReconcileModel_BaseURL(t *testing.T) {
+	indexer, _ := newTestIndexer(t, &recordingEmbedder{})
+	store := indexer.store
+	upsert := func() {
+		if err := store.Upsert(&BlockVector{ID: "b1", DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 旧版本记录的标识不含服务地址，升级后视为同一模型，向量保留
+	if err := store.SetMeta(metaKeyModel, "openai:embed"); err != nil {
+		t.Fatal(err)
+	}
+	upsert()
+	local := modelIdentity(&EmbeddingConfig{Provider: "openai", Model: "embed", BaseURL: "http://localhost:8080/v1/"})
+	if changed, err := reconcileModel(store, local); err != nil || changed {
+		t.Fatalf("Expected legacy identity to match, got changed=%v err=%v", changed, err)
+	}
+	if count, _ := store.GetIndexedDocCount(); count != 1 {
+		t.Fatalf("Expected vectors to be kept, got %d indexed docs", count)
+	}
+
+	// 同名模型换到另一个服务：清空旧向量
+	remote := modelIdentity(&EmbeddingConfig{Provider: "openai", Model: "embed", BaseURL: "https://api.example.com/v1"})
+	if changed, err := reconcileModel(store, remote); err != nil || !changed {
+		t.Fatalf("Expected base URL switch to be detected, got changed=%v err=%v", changed, err)
+	}
+	if count, _ := store.GetIndexedDocCount(); count != 0 {
+		t.Errorf("Expected old vectors to be cleared, got %d indexed docs", count)
+	}
+}
+
+func TestIndexFolderContent_Incremental(t *testing.T) {
+	embedder := &latencyEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+
+	folder := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "文件 A 的内容")
+	write("b.txt", "文件 B 的内容")
+	write("c.txt", "文件 C 的内容")
+
+	result, err := external.IndexFolderContent(folder, "doc1", "blk1", 0)
+	if err != nil {
+		t.Fatalf("IndexFolderContent failed: %v", err)
+	}
+	if result.Added != 3 || result.SuccessCount != 3 {
+		t.Fatalf("Expected 3 added files on first run, got %+v", result)
+	}
+
+	// 修改 b（内容和修改时间都变化），删除 c
+	write("b.txt", "文件 B 修改后的内容")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(folder, "b.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(folder, "c.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = external.IndexFolderContent(folder, "doc1", "blk1", 0)
+	if err != nil {
+		t.Fatalf("IndexFolderContent failed: %v", err)
+	}
+	if result.Added != 0 || result.Updated != 1 || result.Removed != 1 || result.Unchanged != 1 {
+		t.Errorf("Expected updated=1 removed=1 unchanged=1, got %+v", result)
+	}
+
+	blocks, err := indexer.store.GetAllBlockMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, b := range blocks {
+		contents = append(contents, b.Content)
+	}
+	joined := strings.Join(contents, "|")
+	if strings.Contains(joined, "文件 C") {
+		t.Errorf("Expected chunks of removed file to be deleted, got %v", contents)
+	}
+	if !strings.Contains(joined, "文件 B 修改后的内容") || strings.Contains(joined, "文件 B 的内容") {
+		t.Errorf("Expected b.txt chunks to be replaced, got %v", contents)
+	}
+	if !strings.Contains(joined, "文件 A 的内容") {
+		t.Errorf("Expected unchanged a.txt chunks to be kept, got %v", contents)
+	}
+}
+
+func TestIndexFolderContent_RelativePathHeading(t *testing.T) {
+	embedder := &latencyEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+
+	folder := filepath.Join(t.TempDir(), "notes")
+	for _, dir := range []string{"a", "b"} {
+		if err := os.MkdirAll(filepath.Join(folder, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(folder, dir, "readme.txt"), []byte("内容 "+dir), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := external.IndexFolderContent(folder, "doc1", "blk1", 0); err != nil {
+		t.Fatalf("IndexFolderContent failed: %v", err)
+	}
+
+	blocks, err := indexer.store.GetAllBlockMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	headings := map[string]string{}
+	for _, b := range blocks {
+		headings[b.Content] = b.HeadingContext
+	}
+	if headings["内容 a"] != "notes/a/readme.txt" || headings["内容 b"] != "notes/b/readme.txt" {
+		t.Errorf("Expected relative paths as heading context, got %v", headings)
+	}
+}
+
+func TestIndexFolderContent_RecordsTruncation(t *testing.T) {
+	embedder := &latencyEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+	external.SetMaxExtractBytes(16)
+
+	folder := t.TempDir()
+	if err := os.WriteFile(filepath.Join(folder, "long.txt"), []byte(strings.Repeat("long text ", 10)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "short.txt"), []byte("short"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 第二次为增量重建：未变更文件沿用已记录的截断状态
+	for run := 0; run < 2; run++ {
+		if _, err := external.IndexFolderContent(folder, "doc1", "blk1", 0); err != nil {
+			t.Fatalf("IndexFolderContent failed: %v", err)
+		}
+		content, err := indexer.store.GetExternalContent("doc1", "blk1")
+		if err != nil || content == nil {
+			t.Fatalf("Expected folder content to be saved, got %v", err)
+		}
+		if !content.Truncated {
+			t.Errorf("Run %d: expected folder content to be marked truncated", run)
+		}
+		if !strings.Contains(content.RawContent, "long.txt") || strings.Contains(content.RawContent, "short.txt") {
+			t.Errorf("Run %d: expected only long.txt to be listed as truncated, got %q", run, content.RawContent)
+		}
+	}
+}
+
+// countingEmbedder 统计单条嵌入调用次数的测试替身
+type countingEmbedder struct {
+	recordingEmbedder
+	calls int
+}
+
+func (e *countingEmbedder) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	e.calls++
+	return e.recordingEmbedder.EmbedWithType(text, kind)
+}
+
+func TestIndexFileContent_SkipsUnchanged(t *testing.T) {
+	embedder := &countingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+
+	file := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(file, []byte("第一段内容。第二段内容。"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := external.IndexFileContent(file, "doc1", "blk1", ""); err != nil {
+		t.Fatalf("IndexFileContent failed: %v", err)
+	}
+	if embedder.calls == 0 {
+		t.Fatal("Expected the first index to embed chunks")
+	}
+
+	// 内容未变化：不删除、不重新嵌入
+	embedder.calls = 0
+	if err := external.IndexFileContent(file, "doc1", "blk1", ""); err != nil {
+		t.Fatalf("IndexFileContent failed: %v", err)
+	}
+	if embedder.calls != 0 {
+		t.Errorf("Expected zero embed calls for unchanged content, got %d", embedder.calls)
+	}
+	if count, _ := indexer.store.CountBlocksByPrefix("doc1_blk1_file"); count == 0 {
+		t.Error("Expected chunks of unchanged content to be kept")
+	}
+
+	// force 跳过哈希检查
+	if err := external.indexFile(context.Background(), file, "doc1", "blk1", "", true); err != nil {
+		t.Fatalf("indexFile failed: %v", err)
+	}
+	if embedder.calls == 0 {
+		t.Error("Expected force to re-embed unchanged content")
+	}
+
+	// 向量被清空（如切换模型）后即使内容相同也重新嵌入
+	if err := indexer.store.DeleteBlocksByPrefix("doc1_blk1_file"); err != nil {
+		t.Fatal(err)
+	}
+	embedder.calls = 0
+	if err := external.IndexFileContent(file, "doc1", "blk1", ""); err != nil {
+		t.Fatalf("IndexFileContent failed: %v", err)
+	}
+	if embedder.calls == 0 {
+		t.Error("Expected re-embed when chunks are missing from the index")
+	}
+
+	// 内容变化后重新嵌入
+	if err := os.WriteFile(file, []byte("修改后的内容。"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	embedder.calls = 0
+	if err := external.IndexFileContent(file, "doc1", "blk1", ""); err != nil {
+		t.Fatalf("IndexFileContent failed: %v", err)
+	}
+	if embedder.calls == 0 {
+		t.Error("Expected changed content to be re-embedded")
+	}
+}
+
+func TestIndexFolderContent_ParallelExtraction(t *testing.T) {
+	embedder := &latencyEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	external := NewExternalIndexer(indexer.store, embedder, indexer.docRepo, docStorage, indexer, indexer.paths)
+	external.SetExtractWorkers(4)
+
+	folder := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const validFiles = 12
+	for i := 0; i < validFiles; i++ {
+		write(fmt.Sprintf("note_%02d.txt", i), fmt.Sprintf("第 %d 个文件的内容", i))
+	}
+	// 无法提取或内容为空的文件
+	write("empty.txt", "")
+	write("broken.docx", "not a zip archive")
+	write("broken.pdf", "not a pdf")
+
+	var updates []FolderIndexProgress
+	result, err := external.IndexFolderContentWithProgress(folder, "doc1", "blk1", 0, func(p FolderIndexProgress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Fatalf("IndexFolderContent failed: %v", err)
+	}
+	// 每个文件各一次提取和一次嵌入进度；计数单调递增，嵌入不超过提取
+	if len(updates) != 2*(validFiles+3) {
+		t.Errorf("Expected %d progress updates, got %d", 2*(validFiles+3), len(updates))
+	}
+	for i, p := range updates {
+		if p.Total != validFiles+3 || p.BlockID != "blk1" || p.Indexed > p.Extracted {
+			t.Errorf("Unexpected progress %+v", p)
+		}
+		if i > 0 && p.Extracted+p.Indexed != updates[i-1].Extracted+updates[i-1].Indexed+1 {
+			t.Errorf("Progress is not monotonic at %d: %+v after %+v", i, p, updates[i-1])
+		}
+	}
+	if last := updates[len(updates)-1]; last.Extracted != validFiles+3 || last.Indexed != validFiles+3 {
+		t.Errorf("Expected final progress to be complete, got %+v", last)
+	}
+	if result.TotalFiles != validFiles+3 {
+		t.Errorf("Expected %d files, got %d", validFiles+3, result.TotalFiles)
+	}
+	if result.Added != validFiles || result.SuccessCount != validFiles {
+		t.Errorf("Expected %d added files, got %+v", validFiles, result)
+	}
+
+	failed := append([]string(nil), result.FailedFiles...)
+	sort.Strings(failed)
+	want := []string{"broken.docx", "broken.pdf", "empty.txt"}
+	if result.FailedCount != len(want) || strings.Join(failed, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected failed files %v, got %d %v", want, result.FailedCount, failed)
+	}
+
+	blocks, err := indexer.store.GetAllBlockMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < validFiles; i++ {
+		found := false
+		for _, b := range blocks {
+			if b.Content == fmt.Sprintf("第 %d 个文件的内容", i) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected note_%02d.txt to be indexed", i)
+		}
+	}
+}
+
+func TestIndexDocument_EmbedTitles(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, docStorage := newTestIndexer(t, embedder)
+	indexer.SetEmbedTitles(true)
+
+	doc, err := indexer.docRepo.Create("季度计划")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := "正文内容足够长以避免被合并为短块，这里补充更多文字让它超过短块阈值，确保它作为独立的块被索引。"
+	if err := docStorage.Save(doc.ID, `[{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "`+body+`"}]}]`); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.IndexDocument(doc.ID); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+
+	blocks, err := indexer.store.GetAllBlockMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var title *BlockVector
+	for i := range blocks {
+		if blocks[i].BlockType == titleBlockType {
+			title = &blocks[i]
+		}
+	}
+	if title == nil || title.Content != "季度计划" || title.SourceBlockID != "" {
+		t.Fatalf("Expected title chunk without source block, got %+v", title)
+	}
+
+	// 重命名后只重新嵌入标题
+	if err := indexer.docRepo.Rename(doc.ID, "年度计划"); err != nil {
+		t.Fatal(err)
+	}
+	embedder.batches = nil
+	if err := indexer.IndexDocument(doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(embedder.batches) != 1 || len(embedder.batches[0]) != 1 || embedder.batches[0][0] != "年度计划" {
+		t.Errorf("Expected only the new title to be embedded, got %v", embedder.batches)
+	}
+
+	// 关闭后标题 chunk 被删除
+	indexer.SetEmbedTitles(false)
+	if err := indexer.IndexDocument(doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := indexer.store.CountBlocks(doc.ID); count != 1 {
+		t.Errorf("Expected only the body chunk after disabling titles, got %d", count)
+	}
+}
+
+func TestIndexDocument_ExcludedBlockTypesDropped(t *testing.T) {
+	indexer, docStorage := newTestIndexer(t, &recordingEmbedder{})
+
+	longText := strings.Repeat("这是一段足够长的正文内容，用于避免被合并为短块。", 8)
+	code := strings.Repeat("fmt.Println(\"hello world\") ", 10)
+	content := `[
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "` + longText + `"}]},
+		{"id": "c1", "type": "codeBlock", "content": [{"type": "text", "text": "` + strings.ReplaceAll(code, `"`, `\"`) + `"}]}
+	]`
+	if err := docStorage.Save("doc1", content); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	if hashes, _ := indexer.store.GetBlockHashes("doc1"); hashes["c1"] == "" {
+		t.Fatalf("Expected code block to be indexed by default, got %v", hashes)
+	}
+
+	// 排除配置变更后重新提取：已索引的代码块被删除
+	changed, err := excludedTypesChanged(indexer.store, []string{"codeBlock", " codeBlock "})
+	if err != nil || !changed {
+		t.Fatalf("Expected exclusion change to be detected, got changed=%v err=%v", changed, err)
+	}
+	// 重新提取完成前不记录新配置，中途退出时下次启动仍会检测到变更
+	if changed, _ := excludedTypesChanged(indexer.store, []string{"codeBlock"}); !changed {
+		t.Error("Expected exclusions to stay unrecorded until the refresh completes")
+	}
+	excludedTypesRecorder(indexer.store, []string{"codeBlock"}, true)()
+	if changed, _ := excludedTypesChanged(indexer.store, []string{"codeBlock"}); changed {
+		t.Error("Expected same exclusions to be unchanged after recording")
+	}
+	indexer.SetChunkConfig((&EmbeddingConfig{ExcludedBlockTypes: []string{"codeBlock"}}).GetChunkConfig())
+	if err := indexer.IndexDocument("doc1"); err != nil {
+		t.Fatalf("IndexDocument failed: %v", err)
+	}
+	hashes, _ := indexer.store.GetBlockHashes("doc1")
+	if _, ok := hashes["c1"]; ok {
+		t.Errorf("Expected excluded code block to be removed, got %v", hashes)
+	}
+	if _, ok := hashes["p1"]; !ok {
+		t.Errorf("Expected paragraph to stay indexed, got %v", hashes)
+	}
+}
@@ -2,6 +2,11 @@
 // for semantic search and document indexing.
 package rag
 
+import (
+	"context"
+	"time"
+)
+
 // DocumentIndexer handles document content indexing.
 // Implementations: *Indexer
 type DocumentIndexer interface {
@@ -11,11 +16,11 @@ type DocumentIndexer interface {
 	// ForceReindexDocument rebuilds a document's index from scratch
 	ForceReindexDocument(docID string) error
 
-	// ReindexAll rebuilds all document indexes
-	ReindexAll() (int, error)
+	// ReindexAll rebuilds all document indexes, stopping early when ctx is cancelled
+	ReindexAll(ctx context.Context) (int, error)
 
 	// ReindexAllWithCallback rebuilds all with progress callback
-	ReindexAllWithCallback(onProgress func(current, total int)) (int, error)
+	ReindexAllWithCallback(ctx context.Context, onProgress func(current, total int)) (int, error)
 }
 
 // ChunkSearcher performs semantic search over indexed content.
@@ -32,7 +37,8 @@ type ChunkSearcher interface {
 // Implementations: *ExternalIndexer
 type ExternalContentIndexer interface {
 	// IndexBookmarkContent indexes web page content from a bookmark URL
-	IndexBookmarkContent(url, sourceDocID, blockID string) error
+	// An optional timeout overrides the configured fetch timeout
+	IndexBookmarkContent(url, sourceDocID, blockID string, timeout ...time.Duration) error
 
 	// IndexFileContent indexes file content (PDF, DOCX, etc.)
 	// fileName is the original file name for display (optional, falls back to path basename)
@@ -42,10 +48,12 @@ type ExternalContentIndexer interface {
 	IndexFolderContent(folderPath, sourceDocID, blockID string, maxDepth int) (*FolderIndexResult, error)
 
 	// ReindexAll reindexes all bookmark and file blocks
-	ReindexAll() (int, error)
+	// Unless force is set, blocks whose extracted content is unchanged are not re-embedded
+	// Stops early when ctx is cancelled
+	ReindexAll(ctx context.Context, force bool) (int, error)
 
 	// ReindexAllWithProgress reindexes all with progress callback
-	ReindexAllWithProgress(onProgress func(current, total int)) (int, error)
+	ReindexAllWithProgress(ctx context.Context, force bool, onProgress func(current, total int)) (int, error)
 }
 
 // EmbeddingProvider generates vector embeddings for text.
@@ -0,0 +1,76 @@
+package rag
+
+// DefaultMMRLambda MMR 重排默认的相关性权重（越大越偏向相关性，越小越偏向多样性）
+const DefaultMMRLambda = 0.7
+
+// mmrCandidateMultiplier 启用多样性重排时额外召回的候选倍数
+const mmrCandidateMultiplier = 4
+
+// mmrOrder 使用最大边际相关性（MMR）对候选贪心排序，返回候选下标的新顺序
+// 每一步选择 λ·相关性 − (1−λ)·与已选结果的最大相似度 最高的候选；
+// 缺少向量的候选不参与多样性惩罚，仅按相关性排序
+func mmrOrder(relevance []float32, vectors [][]float32, lambda float32) []int {
+	n := len(relevance)
+	order := make([]int, 0, n)
+	selected := make([]bool, n)
+	// maxSim[i] 为候选 i 与已选结果的最大相似度
+	maxSim := make([]float32, n)
+
+	for len(order) < n {
+		best := -1
+		var bestScore float32
+		for i := 0; i < n; i++ {
+			if selected[i] {
+				continue
+			}
+			score := lambda*relevance[i] - (1-lambda)*maxSim[i]
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+
+		selected[best] = true
+		order = append(order, best)
+		if vectors[best] == nil {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			if selected[i] || vectors[i] == nil {
+				continue
+			}
+			if sim := cosineSimilarity(vectors[i], vectors[best]); sim > maxSim[i] {
+				maxSim[i] = sim
+			}
+		}
+	}
+	return order
+}
+
+// diversify 按 MMR 重排搜索结果（需要候选块的向量）
+func (s *Searcher) diversify(results []SearchResult) ([]SearchResult, error) {
+	if len(results) < 2 {
+		return results, nil
+	}
+
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.BlockID
+	}
+	vecByID, err := s.store.GetVectorsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	relevance := make([]float32, len(results))
+	vectors := make([][]float32, len(results))
+	for i, r := range results {
+		relevance[i] = s.store.Similarity(r.Distance)
+		vectors[i] = vecByID[r.BlockID]
+	}
+
+	reordered := make([]SearchResult, 0, len(results))
+	for _, i := range mmrOrder(relevance, vectors, s.mmrLambda) {
+		reordered = append(reordered, results[i])
+	}
+	return reordered, nil
+}
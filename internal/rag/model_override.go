@@ -0,0 +1,69 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// embedderSet 按模型名创建并缓存嵌入客户端，用于文档级嵌入模型覆盖（document.Meta.EmbedModel）
+// 覆盖模型沿用默认配置的服务商、地址和密钥，且必须与索引维度一致（所有向量共用一张向量表）
+type embedderSet struct {
+	ctx       context.Context
+	config    EmbeddingConfig
+	fallback  EmbeddingClient // 默认模型的客户端
+	dimension int             // 索引维度
+
+	mu      sync.Mutex
+	clients map[string]EmbeddingClient
+}
+
+// newEmbedderSet 创建模型客户端集合
+func newEmbedderSet(ctx context.Context, config *EmbeddingConfig, fallback EmbeddingClient, dimension int) *embedderSet {
+	return &embedderSet{
+		ctx:       ctx,
+		config:    *config,
+		fallback:  fallback,
+		dimension: dimension,
+		clients:   make(map[string]EmbeddingClient),
+	}
+}
+
+// modelKey 覆盖模型在向量库中的记录值：未设置或与默认模型相同时为空字符串
+func (m *embedderSet) modelKey(model string) string {
+	model = strings.TrimSpace(model)
+	if m == nil || model == m.config.Model {
+		return ""
+	}
+	return model
+}
+
+// client 返回模型对应的嵌入客户端（空字符串为默认模型），首次使用覆盖模型时探测并校验维度
+func (m *embedderSet) client(model string) (EmbeddingClient, error) {
+	if model == "" {
+		return m.fallback, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if client, ok := m.clients[model]; ok {
+		return client, nil
+	}
+
+	config := m.config
+	config.Model = model
+	client, err := NewEmbeddingClientWithContext(m.ctx, &config)
+	if err != nil {
+		return nil, err
+	}
+	dimension, err := ProbeDimension(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe embedding model %s: %w", model, err)
+	}
+	if dimension != m.dimension {
+		return nil, fmt.Errorf("embedding model %s produces %d-dimensional vectors, but the index uses %d", model, dimension, m.dimension)
+	}
+	m.clients[model] = client
+	return client, nil
+}
@@ -0,0 +1,91 @@
+package rag
+
+import "testing"
+
+// fixedEmbedder 对任何输入都返回同一向量的测试替身，用于区分不同模型产生的向量
+type fixedEmbedder struct {
+	recordingEmbedder
+	vec []float32
+}
+
+func (e *fixedEmbedder) EmbedWithType(text string, kind EmbedKind) ([]float32, error) {
+	return e.vec, nil
+}
+
+func (e *fixedEmbedder) EmbedBatch(texts []string) ([][]float32, error) {
+	result := make([][]float32, len(texts))
+	for i := range texts {
+		result[i] = e.vec
+	}
+	return result, nil
+}
+
+func TestEmbedModelOverride(t *testing.T) {
+	general := &fixedEmbedder{vec: []float32{1, 0, 0}}
+	code := &fixedEmbedder{vec: []float32{0, 1, 0}}
+	indexer, docStorage := newTestIndexer(t, general)
+	models := &embedderSet{
+		config:    EmbeddingConfig{Model: "general"},
+		fallback:  general,
+		dimension: 3,
+		clients:   map[string]EmbeddingClient{"code": code},
+	}
+	indexer.SetModelEmbedders(models)
+
+	for _, id := range []string{"prose", "snippets"} {
+		if _, err := indexer.docRepo.CreateWithID(id, id); err != nil {
+			t.Fatal(err)
+		}
+		if err := docStorage.Save(id, `[{"id":"`+id+`-p1","type":"paragraph","content":[{"type":"text","text":"`+id+` content"}]}]`); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.docRepo.SetEmbedModel("snippets", "code"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"prose", "snippets"} {
+		if err := indexer.IndexDocument(id); err != nil {
+			t.Fatalf("IndexDocument(%s) failed: %v", id, err)
+		}
+	}
+
+	if got, _ := indexer.store.EmbedModels(); len(got) != 1 || got[0] != "code" {
+		t.Fatalf("EmbedModels() = %v, want [code]", got)
+	}
+
+	// 预估应与增量索引使用相同的哈希：刚索引完的覆盖模型文档不需要重新嵌入
+	plan, err := indexer.PlanReindex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Embeddings != 0 || plan.Update != 0 {
+		t.Errorf("Expected nothing to re-embed right after indexing, got %d embeddings (%d updates)", plan.Embeddings, plan.Update)
+	}
+
+	// 两个文档的查询都应按各自模型嵌入，因此都能精确命中
+	searcher := NewSearcher(indexer.store, general, indexer.docRepo)
+	searcher.SetModelEmbedders(models)
+	matches, err := searcher.SearchChunks("content", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected chunks of both documents, got %d", len(matches))
+	}
+	for _, m := range matches {
+		if m.Score < 0.99 {
+			t.Errorf("Chunk of %s scored %.3f, expected the query to be routed to its model", m.DocID, m.Score)
+		}
+	}
+
+	// 恢复默认模型后块哈希变化，重新用默认模型嵌入
+	if err := indexer.docRepo.SetEmbedModel("snippets", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.IndexDocument("snippets"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := indexer.store.EmbedModels(); len(got) != 0 {
+		t.Errorf("Expected no override models after reverting, got %v", got)
+	}
+}
@@ -0,0 +1,49 @@
+package rag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PreprocessConfig 嵌入前的文本预处理配置（索引与查询对称应用，默认全部关闭）
+type PreprocessConfig struct {
+	Lowercase           bool `json:"preprocessLowercase"`           // 转为小写
+	NormalizeWhitespace bool `json:"preprocessNormalizeWhitespace"` // 合并连续空白
+	StripMarkdown       bool `json:"preprocessStripMarkdown"`       // 去除 Markdown 语法
+}
+
+// Markdown 语法匹配规则
+var (
+	mdImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLinkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdHeadingPattern  = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	mdQuotePattern    = regexp.MustCompile(`(?m)^\s*>\s?`)
+	mdListPattern     = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+[.)])\s+`)
+	mdEmphasisPattern = regexp.MustCompile("(\\*\\*|__|~~|\\*|`)")
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Apply 按配置对文本进行预处理
+func (c PreprocessConfig) Apply(text string) string {
+	if c.StripMarkdown {
+		text = stripMarkdown(text)
+	}
+	if c.Lowercase {
+		text = strings.ToLower(text)
+	}
+	if c.NormalizeWhitespace {
+		text = strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+	}
+	return text
+}
+
+// stripMarkdown 去除常见 Markdown 语法，保留可读文本
+func stripMarkdown(text string) string {
+	text = mdImagePattern.ReplaceAllString(text, "$1")
+	text = mdLinkPattern.ReplaceAllString(text, "$1")
+	text = mdHeadingPattern.ReplaceAllString(text, "")
+	text = mdQuotePattern.ReplaceAllString(text, "")
+	text = mdListPattern.ReplaceAllString(text, "")
+	text = mdEmphasisPattern.ReplaceAllString(text, "")
+	return text
+}
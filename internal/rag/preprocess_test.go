@@ -0,0 +1,18 @@
+package rag
+
+import "testing"
+
+func TestPreprocessConfig_Apply(t *testing.T) {
+	input := "## Hello **World**\n\n- see [Docs](https://example.com)   `code`"
+
+	// 默认关闭时保持原样
+	if got := (PreprocessConfig{}).Apply(input); got != input {
+		t.Errorf("Expected unchanged text, got %q", got)
+	}
+
+	config := PreprocessConfig{Lowercase: true, NormalizeWhitespace: true, StripMarkdown: true}
+	want := "hello world see docs code"
+	if got := config.Apply(input); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
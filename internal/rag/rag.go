@@ -3,11 +3,15 @@ package rag
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"notion-lite/internal/document"
+	"notion-lite/internal/fileextract"
 	"notion-lite/internal/utils"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Service RAG 服务统一入口
@@ -19,8 +23,16 @@ type Service struct {
 	searcher        *Searcher
 	externalIndexer *ExternalIndexer
 	embedder        EmbeddingClient
+	dimension       int    // 探测得到（或配置中缓存）的权威向量维度
+	model           string // 当前嵌入模型标识（provider:model），用于维护维度缓存
 	docRepo         *document.Repository
 	docStorage      *document.Storage
+	clusterCache    topicClusterCache // 主题聚类缓存
+
+	reindexCtx context.Context    // 全量重建的父上下文（应用关闭时取消）
+	bgMu       sync.Mutex         // 保护 bgCancel
+	bgCancel   context.CancelFunc // 正在进行的后台重建（重新初始化时取消）
+	bgWG       sync.WaitGroup     // 后台重建 goroutine，关闭存储前等待其退出
 }
 
 // NewService 创建 RAG 服务
@@ -43,31 +55,192 @@ func (s *Service) init() error {
 		return err
 	}
 
-	embedder, err := NewEmbeddingClient(config)
+	embedder, err := NewEmbeddingClientWithContext(s.context(), config)
 	if err != nil {
 		return err
 	}
 
-	dimension, err := embedder.DetectDimension()
+	dimension, err := s.resolveDimension(config, embedder)
 	if err != nil {
 		return fmt.Errorf("failed to detect embedding dimension: %w", err)
 	}
-	s.embedder = embedder
 
 	dbPath := s.paths.RAGDatabase()
+	storedDimension, err := StoredDimension(dbPath)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to read stored dimension: %v\n", err)
+	}
+	if storedDimension > 0 && storedDimension != dimension {
+		// 打开时维度不匹配会清空向量表，先备份
+		if err := backupBeforeDimensionChange(s.paths); err != nil {
+			return err
+		}
+	}
+
+	s.embedder = embedder
+	s.dimension = dimension
+	s.model = modelIdentity(config)
+
 	store, err := NewVectorStore(dbPath, dimension)
 	if err != nil {
 		return err
 	}
 	s.store = store
 
-	s.indexer = NewIndexer(store, embedder, s.docRepo, s.docStorage, s.paths)
+	s.indexer = NewIndexerWithConfig(store, embedder, s.docRepo, s.docStorage, config.GetChunkConfig(), s.paths)
+	s.indexer.SetWorkers(config.ReindexWorkers)
+	s.indexer.SetEmbedTitles(config.EmbedTitles)
 	s.searcher = NewSearcher(store, embedder, s.docRepo)
+	s.searcher.SetMMRLambda(config.MMRLambda)
+	s.searcher.SetTitleBoost(config.TitleBoost)
+	models := newEmbedderSet(s.context(), config, embedder, dimension)
+	s.indexer.SetModelEmbedders(models)
+	s.searcher.SetModelEmbedders(models)
 	s.externalIndexer = NewExternalIndexer(store, embedder, s.docRepo, s.docStorage, s.indexer, s.paths)
+	s.externalIndexer.SetExtractWorkers(config.ExtractWorkers)
+	s.externalIndexer.SetMaxExtractBytes(config.MaxExtractBytes)
+	fileextract.SetPDFOCREnabled(config.PDFOCR)
+	fileextract.SetCSVMaxRows(config.CSVMaxRows)
+
+	// 配置在应用关闭期间被修改（切换模型或维度）时，启动即重建
+	modelChanged, err := reconcileModel(store, modelIdentity(config))
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to check embedding model: %v\n", err)
+	}
+	excludedChanged, err := excludedTypesChanged(store, config.ExcludedBlockTypes)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to check excluded block types: %v\n", err)
+	}
+	recordExcluded := excludedTypesRecorder(store, config.ExcludedBlockTypes, excludedChanged)
+	switch {
+	case storedDimension > 0 && storedDimension != dimension:
+		s.rebuildInBackground("dimension change", recordExcluded)
+	case modelChanged:
+		s.rebuildInBackground("embedding model change", recordExcluded)
+	case excludedChanged:
+		s.refreshDocumentsInBackground("excluded block types change", recordExcluded)
+	}
 
 	return nil
 }
 
+// resolveDimension 优先使用配置中缓存的模型维度，没有缓存时探测一次并写入配置
+func (s *Service) resolveDimension(config *EmbeddingConfig, embedder EmbeddingClient) (int, error) {
+	identity := modelIdentity(config)
+	if dimension := config.ModelDimensions[identity]; dimension > 0 {
+		return dimension, nil
+	}
+
+	dimension, err := ProbeDimension(embedder)
+	if err != nil {
+		return 0, err
+	}
+	s.cacheDimension(identity, dimension)
+	return dimension, nil
+}
+
+// reconcileDimension 探测结果与当前维度不一致时更新缓存，返回是否不一致
+func (s *Service) reconcileDimension(probed int) bool {
+	if s.model == "" || probed == s.dimension {
+		return false
+	}
+	fmt.Printf("⚠️ [RAG] Cached dimension %d for %s does not match probed dimension %d, updating cache\n", s.dimension, s.model, probed)
+	s.cacheDimension(s.model, probed)
+	return true
+}
+
+// cacheDimension 将模型维度写入配置（重新读取配置后只修改缓存，不覆盖其他字段）
+func (s *Service) cacheDimension(identity string, dimension int) {
+	config, err := LoadConfig(s.paths)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to load config for dimension cache: %v\n", err)
+		return
+	}
+	if config.ModelDimensions[identity] == dimension {
+		return
+	}
+	if config.ModelDimensions == nil {
+		config.ModelDimensions = make(map[string]int)
+	}
+	config.ModelDimensions[identity] = dimension
+	if err := SaveConfig(s.paths, config); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to cache embedding dimension: %v\n", err)
+	}
+}
+
+// metaKeyModel vec_config 中记录生成当前索引的模型标识的键
+const metaKeyModel = "model"
+
+// modelIdentity 嵌入模型标识（provider:model@baseURL），用于检测同维度下的模型切换
+// 不同服务上的同名模型（如两个 OpenAI 兼容端点）可能是不同的模型，标识中包含服务地址
+func modelIdentity(config *EmbeddingConfig) string {
+	return config.Provider + ":" + config.Model + "@" + strings.TrimRight(config.BaseURL, "/")
+}
+
+// sameModel 判断记录的模型标识与当前标识是否指向同一模型
+// 旧版本记录的标识不含服务地址（provider:model），按 provider 和 model 比较
+func sameModel(stored, identity string) bool {
+	if stored == identity {
+		return true
+	}
+	return !strings.Contains(stored, "@") && strings.HasPrefix(identity, stored+"@")
+}
+
+// reconcileModel 检查索引是否由当前模型生成
+// 模型变更时清空旧向量（避免新旧向量混在同一索引中）并返回 true；
+// 首次记录模型标识时视为未变更
+func reconcileModel(store *VectorStore, identity string) (bool, error) {
+	stored, err := store.GetMeta(metaKeyModel)
+	if err != nil {
+		return false, err
+	}
+	if stored == identity {
+		return false, nil
+	}
+
+	changed := stored != "" && !sameModel(stored, identity)
+	if changed {
+		fmt.Printf("🔄 [RAG] Embedding model changed (%s → %s), clearing old vectors...\n", stored, identity)
+		if err := store.ClearVectors(); err != nil {
+			return false, fmt.Errorf("failed to clear vectors: %w", err)
+		}
+	}
+	if err := store.SetMeta(metaKeyModel, identity); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// metaKeyExcludedTypes vec_config 中记录生成当前索引时排除的块类型的键
+const metaKeyExcludedTypes = "excluded_block_types"
+
+// excludedTypesChanged 检查索引是否按当前的块类型排除配置生成，不一致时返回 true
+// 新配置由 excludedTypesRecorder 在重新提取成功后记录，中途取消或失败时下次启动仍会检测到变更
+func excludedTypesChanged(store *VectorStore, types []string) (bool, error) {
+	identity := excludedTypesIdentity(types)
+	stored, err := store.GetMeta(metaKeyExcludedTypes)
+	if err != nil {
+		return false, err
+	}
+	if stored == identity {
+		return false, nil
+	}
+	fmt.Printf("🔄 [RAG] Excluded block types changed (%q → %q)\n", stored, identity)
+	return true, nil
+}
+
+// excludedTypesRecorder 返回记录当前排除配置的回调，在后台重新提取全部文档成功后调用；未变更时返回 nil
+func excludedTypesRecorder(store *VectorStore, types []string, changed bool) func() {
+	if !changed {
+		return nil
+	}
+	return func() {
+		if err := store.SetMeta(metaKeyExcludedTypes, excludedTypesIdentity(types)); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to record excluded block types: %v\n", err)
+		}
+	}
+}
+
 // Warmup 预热初始化（只加载组件，不做实际搜索）
 // 用于在应用空闲时提前初始化，避免首次使用时的冷启动延迟
 func (s *Service) Warmup() error {
@@ -90,6 +263,26 @@ func (s *Service) SearchDocuments(query string, limit int, filter *SearchFilter)
 	return s.searcher.SearchDocuments(query, limit, filter)
 }
 
+// SearchDocumentsPaged 分页的文档级语义搜索，hasMore 表示是否还有下一页
+func (s *Service) SearchDocumentsPaged(query string, limit, offset int, filter *SearchFilter) ([]DocumentSearchResult, bool, error) {
+	if err := s.init(); err != nil {
+		return nil, false, err
+	}
+	return s.searcher.SearchDocumentsPaged(query, limit, offset, filter)
+}
+
+// SearchFaceted 文档级语义搜索，结果按标签分组（用于分面展示）
+func (s *Service) SearchFaceted(query string, limit int) (*FacetedSearchResult, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	results, err := s.searcher.SearchDocuments(query, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	return GroupByTag(results), nil
+}
+
 // SearchChunks 块级语义搜索
 func (s *Service) SearchChunks(query string, limit int, filter *SearchFilter) ([]ChunkMatch, error) {
 	if err := s.init(); err != nil {
@@ -98,12 +291,39 @@ func (s *Service) SearchChunks(query string, limit int, filter *SearchFilter) ([
 	return s.searcher.SearchChunks(query, limit, filter)
 }
 
-// ReindexAll 重建所有文档索引
-func (s *Service) ReindexAll() (int, error) {
+// ReindexAll 重建所有文档索引（ctx 取消时提前返回）
+func (s *Service) ReindexAll(ctx context.Context) (int, error) {
 	if err := s.init(); err != nil {
 		return 0, err
 	}
-	return s.indexer.ReindexAll()
+	return s.indexer.ReindexAll(ctx)
+}
+
+// ForceReindexDocument 强制重建单个文档的索引，返回该文档当前的 chunk 数
+func (s *Service) ForceReindexDocument(docID string) (int, error) {
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	if err := s.indexer.ForceReindexDocument(docID); err != nil {
+		return 0, err
+	}
+	return s.store.CountBlocks(docID)
+}
+
+// ReindexAllDocuments 重建所有文档索引，返回重建报告和索引中的 chunk 总数
+func (s *Service) ReindexAllDocuments(ctx context.Context) (*ReindexReport, int, error) {
+	if err := s.init(); err != nil {
+		return nil, 0, err
+	}
+	report, err := s.indexer.ReindexAllDocuments(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	chunks, err := s.store.CountBlocks("")
+	if err != nil {
+		return report, 0, err
+	}
+	return report, chunks, nil
 }
 
 // SetContext 设置 Wails 上下文（用于发送事件）
@@ -111,12 +331,176 @@ func (s *Service) SetContext(ctx context.Context) {
 	s.ctx = ctx
 }
 
-// ReindexAllWithProgress 重建所有文档索引（带进度回调）
-func (s *Service) ReindexAllWithProgress(onProgress func(current, total int)) (int, error) {
+// SetReindexContext 设置全量重建的父上下文，取消后后台重建提前结束（应用关闭时）
+func (s *Service) SetReindexContext(ctx context.Context) {
+	s.reindexCtx = ctx
+}
+
+// reindexContext 返回全量重建的父上下文（未设置时使用 Background）
+func (s *Service) reindexContext() context.Context {
+	if s.reindexCtx == nil {
+		return context.Background()
+	}
+	return s.reindexCtx
+}
+
+// cancelBackgroundReindex 取消正在进行的后台重建并等待其退出（之后才能安全关闭存储）
+func (s *Service) cancelBackgroundReindex() {
+	s.bgMu.Lock()
+	if s.bgCancel != nil {
+		s.bgCancel()
+		s.bgCancel = nil
+	}
+	s.bgMu.Unlock()
+	s.bgWG.Wait()
+}
+
+// startBackground 在后台 goroutine 中运行 fn，取代之前的后台任务（取消但不等待）
+// cancelBackgroundReindex 会取消 fn 的 ctx 并等待 fn 返回
+func (s *Service) startBackground(fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(s.reindexContext())
+	s.bgMu.Lock()
+	if s.bgCancel != nil {
+		s.bgCancel()
+	}
+	s.bgCancel = cancel
+	s.bgWG.Add(1)
+	s.bgMu.Unlock()
+
+	go func() {
+		defer s.bgWG.Done()
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// context 返回服务上下文（未设置时使用 Background）
+func (s *Service) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// TestConnection 检查当前配置的嵌入服务是否可达、模型是否可用
+// 服务未初始化（例如启动时服务不可达）时按已保存的配置新建客户端
+func (s *Service) TestConnection() ConnectionStatus {
+	client := s.embedder
+	if client == nil {
+		config, err := LoadConfig(s.paths)
+		if err != nil {
+			return ConnectionStatus{Error: err.Error()}
+		}
+		client, err = NewEmbeddingClientWithContext(s.context(), config)
+		if err != nil {
+			return ConnectionStatus{Error: err.Error()}
+		}
+	}
+	status := CheckConnection(client)
+	if client == s.embedder && status.Dimension > 0 {
+		// 只更新缓存，下次初始化时生效
+		s.reconcileDimension(status.Dimension)
+	}
+	return status
+}
+
+// ReindexAllWithProgress 重建所有文档和外部块（书签/文件/文件夹）的索引，返回成功数
+// current/total 为两个阶段的合计进度，total 在开始前确定，current 单调递增直到 total；ctx 取消时提前返回
+func (s *Service) ReindexAllWithProgress(ctx context.Context, onProgress func(current, total int)) (int, error) {
+	var progress func(ReindexProgress)
+	if onProgress != nil {
+		progress = func(p ReindexProgress) { onProgress(p.OverallDone, p.OverallTotal) }
+	}
+	report, extCount, err := s.RebuildAll(ctx, false, progress)
+	if report == nil {
+		return 0, err
+	}
+	return report.Indexed + extCount, err
+}
+
+// RebuildAll 先重建所有文档索引，再重新索引外部块（force 含义同 ReindexExternalContent）
+// 返回文档重建报告（含失败文档及原因）和成功索引的外部块数；进度按阶段报告，并附带合计进度和当前文档标题
+func (s *Service) RebuildAll(ctx context.Context, force bool, onProgress func(ReindexProgress)) (*ReindexReport, int, error) {
+	if err := s.init(); err != nil {
+		return nil, 0, err
+	}
+
+	// 预先收集外部块，合计总数在文档阶段开始前即可确定
+	jobs, err := s.externalIndexer.collectExternalBlocks()
+	if err != nil {
+		return nil, 0, err
+	}
+	var docProgress func(ReindexProgress)
+	if onProgress != nil {
+		docProgress = func(p ReindexProgress) {
+			p.OverallDone, p.OverallTotal = p.Current, p.Total+len(jobs)
+			onProgress(p)
+		}
+	}
+	report, err := s.indexer.ReindexAllDocuments(ctx, docProgress)
+	if err != nil {
+		return report, 0, err
+	}
+
+	var extProgress func(ReindexProgress)
+	if onProgress != nil {
+		extProgress = func(p ReindexProgress) {
+			p.OverallDone, p.OverallTotal = report.Total+p.Current, report.Total+p.Total
+			onProgress(p)
+		}
+	}
+	extCount, err := s.externalIndexer.reindexExternalBlocks(ctx, force, jobs, extProgress)
+	return report, extCount, err
+}
+
+// PlanReindex 预估重建索引的变化和嵌入开销（不调用嵌入服务）
+func (s *Service) PlanReindex() (*ReindexPlan, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	return s.indexer.PlanReindex()
+}
+
+// ErrDimensionChanged 嵌入服务返回的维度与缓存不一致，需要调用方重新初始化 RAG 服务（会清空并重建索引）
+var ErrDimensionChanged = errors.New("embedding dimension changed, reinitialization required")
+
+// ReindexStaleDocuments 重建索引过期的文档（编辑晚于最近一次索引，如防抖索引被崩溃中断）
+// 嵌入服务不可达时直接返回错误，不做任何索引；维度变化时更新缓存并返回 ErrDimensionChanged，
+// 不在此处重新初始化（后台调用时其他索引仍在使用当前的 indexer 和 store）
+func (s *Service) ReindexStaleDocuments(onProgress func(current, total int)) (int, error) {
 	if err := s.init(); err != nil {
 		return 0, err
 	}
-	return s.indexer.ReindexAllWithCallback(onProgress)
+
+	stale, err := s.indexer.FindStaleDocuments()
+	if err != nil {
+		return 0, err
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	dimension, err := ProbeDimension(s.embedder)
+	if err != nil {
+		return 0, fmt.Errorf("embedding service unreachable: %w", err)
+	}
+	// 缓存的维度已过期（如同名模型被替换）：更新缓存，由调用方重新初始化并重建全部文档
+	if s.reconcileDimension(dimension) {
+		return 0, ErrDimensionChanged
+	}
+
+	count := 0
+	for i, docID := range stale {
+		if err := s.indexer.IndexDocument(docID); err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to reindex stale doc %s: %v\n", docID, err)
+		} else {
+			count++
+		}
+		if onProgress != nil {
+			onProgress(i+1, len(stale))
+		}
+	}
+	return count, nil
 }
 
 // DeleteDocument 删除文档的所有向量索引
@@ -129,26 +513,120 @@ func (s *Service) DeleteDocument(docID string) error {
 
 // GetIndexedCount 获取已索引的文档数量
 func (s *Service) GetIndexedCount() (int, error) {
-	if err := s.init(); err != nil {
-		return 0, nil // 初始化失败，返回 0
-	}
-	return s.store.GetIndexedDocCount()
+	count := 0
+	err := s.withIndexStore(func(store *VectorStore) error {
+		var err error
+		count, err = store.GetIndexedDocCount()
+		return err
+	})
+	return count, err
 }
 
 // GetIndexedStats 获取索引统计信息 (文档数, 书签数, 嵌入文件数, 文件夹数)
 func (s *Service) GetIndexedStats() (int, int, int, int, error) {
+	var docs, bookmarks, files, folders int
+	err := s.withIndexStore(func(store *VectorStore) error {
+		var err error
+		docs, bookmarks, files, folders, err = store.GetIndexedStats()
+		return err
+	})
+	return docs, bookmarks, files, folders, err
+}
+
+// withIndexStore 在向量存储上执行只读统计
+// 嵌入模型不可用导致初始化失败时，按数据库中记录的维度临时打开已有索引；没有索引时不执行（统计为 0）
+func (s *Service) withIndexStore(fn func(store *VectorStore) error) error {
+	if err := s.init(); err == nil {
+		return fn(s.store)
+	}
+
+	dbPath := s.paths.RAGDatabase()
+	if dimension, err := StoredDimension(dbPath); err != nil || dimension == 0 {
+		return nil
+	}
+	store, err := NewVectorStore(dbPath, 0)
+	if err != nil {
+		return nil // 初始化失败，返回 0
+	}
+	defer func() { _ = store.Close() }()
+	return fn(store)
+}
+
+// CompactResult 压缩向量库结果
+type CompactResult struct {
+	OrphansRemoved int   `json:"orphansRemoved"` // 删除的孤儿向量数
+	SizeBefore     int64 `json:"sizeBefore"`     // 压缩前文件大小（字节）
+	SizeAfter      int64 `json:"sizeAfter"`      // 压缩后文件大小（字节）
+	BytesReclaimed int64 `json:"bytesReclaimed"` // 回收的字节数
+}
+
+// CompactIndex 清理孤儿向量并收缩向量库文件
+func (s *Service) CompactIndex() (*CompactResult, error) {
 	if err := s.init(); err != nil {
-		return 0, 0, 0, 0, nil // 初始化失败，返回 0
+		return nil, err
 	}
-	return s.store.GetIndexedStats()
+
+	dbPath := s.paths.RAGDatabase()
+	result := &CompactResult{SizeBefore: databaseSize(dbPath)}
+	removed, err := s.store.Compact()
+	result.OrphansRemoved = removed
+	if err != nil {
+		return result, fmt.Errorf("failed to compact vector database: %w", err)
+	}
+	result.SizeAfter = databaseSize(dbPath)
+	result.BytesReclaimed = max(result.SizeBefore-result.SizeAfter, 0)
+	return result, nil
+}
+
+// VerifyIndex 检查向量库元数据与向量是否一致
+func (s *Service) VerifyIndex() (*VerifyReport, error) {
+	var report VerifyReport
+	err := s.withIndexStore(func(store *VectorStore) error {
+		var err error
+		report, err = store.Verify()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify vector database: %w", err)
+	}
+	return &report, nil
+}
+
+// RepairIndex 删除向量库中悬空的元数据和向量
+func (s *Service) RepairIndex() (*VerifyReport, error) {
+	var report VerifyReport
+	err := s.withIndexStore(func(store *VectorStore) error {
+		var err error
+		report, err = store.Repair()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to repair vector database: %w", err)
+	}
+	if report.Repaired > 0 {
+		fmt.Printf("✅ [RAG] Repaired %d inconsistent vector rows\n", report.Repaired)
+	}
+	return &report, nil
+}
+
+// databaseSize 数据库占用的磁盘空间（主文件加上 WAL 附属文件）
+func databaseSize(dbPath string) int64 {
+	return fileSize(dbPath) + fileSize(dbPath+"-wal") + fileSize(dbPath+"-shm")
+}
+
+// fileSize 获取文件大小（不存在时为 0）
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
 }
 
 // Reinitialize 重新初始化（配置变更后调用）
 func (s *Service) Reinitialize() error {
-	oldDimension := 0
-	if s.embedder != nil {
-		oldDimension = s.embedder.Dimension()
-	}
+	// 旧模型的后台重建已无意义，等待其退出后再关闭存储
+	s.cancelBackgroundReindex()
 
 	if s.store != nil {
 		if err := s.store.Close(); err != nil {
@@ -166,80 +644,172 @@ func (s *Service) Reinitialize() error {
 		return err
 	}
 
-	newEmbedder, err := NewEmbeddingClient(config)
+	newEmbedder, err := NewEmbeddingClientWithContext(s.context(), config)
 	if err != nil {
 		return err
 	}
 
-	newDimension, err := newEmbedder.DetectDimension()
+	newDimension, err := s.resolveDimension(config, newEmbedder)
 	if err != nil {
 		return fmt.Errorf("failed to detect embedding dimension: %w", err)
 	}
 
+	// 旧维度以数据库中记录的为准（不依赖内存中的旧嵌入客户端）
+	dbPath := s.paths.RAGDatabase()
+	oldDimension, err := StoredDimension(dbPath)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to read stored dimension: %v\n", err)
+	}
 	dimensionChanged := oldDimension > 0 && oldDimension != newDimension
 
 	if dimensionChanged {
-		dbPath := s.paths.RAGDatabase()
+		// 删除前先备份，误切换模型时可通过 RestoreVectorBackup 恢复；备份失败时保留旧库
+		if err := backupBeforeDimensionChange(s.paths); err != nil {
+			return err
+		}
 		fmt.Printf("🔄 [RAG] Dimension changed (%d → %d), removing old database...\n", oldDimension, newDimension)
-		if err := os.Remove(dbPath); err != nil && !os.IsNotExist(err) {
+		if err := removeDatabaseFiles(dbPath); err != nil {
 			fmt.Printf("⚠️ [RAG] Failed to remove old database: %v\n", err)
 		}
 	}
 
 	s.embedder = newEmbedder
+	s.dimension = newDimension
+	s.model = modelIdentity(config)
 
-	dbPath := s.paths.RAGDatabase()
 	store, err := NewVectorStore(dbPath, newDimension)
 	if err != nil {
 		return err
 	}
 	s.store = store
 
-	s.indexer = NewIndexer(store, s.embedder, s.docRepo, s.docStorage, s.paths)
+	s.indexer = NewIndexerWithConfig(store, s.embedder, s.docRepo, s.docStorage, config.GetChunkConfig(), s.paths)
+	s.indexer.SetWorkers(config.ReindexWorkers)
+	s.indexer.SetEmbedTitles(config.EmbedTitles)
 	s.searcher = NewSearcher(store, s.embedder, s.docRepo)
+	s.searcher.SetMMRLambda(config.MMRLambda)
+	s.searcher.SetTitleBoost(config.TitleBoost)
+	models := newEmbedderSet(s.context(), config, s.embedder, newDimension)
+	s.indexer.SetModelEmbedders(models)
+	s.searcher.SetModelEmbedders(models)
 	s.externalIndexer = NewExternalIndexer(store, s.embedder, s.docRepo, s.docStorage, s.indexer, s.paths)
+	s.externalIndexer.SetExtractWorkers(config.ExtractWorkers)
+	s.externalIndexer.SetMaxExtractBytes(config.MaxExtractBytes)
+	fileextract.SetPDFOCREnabled(config.PDFOCR)
+	fileextract.SetCSVMaxRows(config.CSVMaxRows)
 
-	if dimensionChanged {
-		go func() {
-			fmt.Println("🔄 [RAG] Starting automatic reindex due to dimension change...")
-			if count, err := s.ReindexAll(); err != nil {
-				fmt.Printf("⚠️ [RAG] ReindexAll failed: %v\n", err)
-			} else {
-				fmt.Printf("✅ [RAG] Reindexed %d documents\n", count)
-			}
-			if extCount, err := s.ReindexExternalContent(); err != nil {
-				fmt.Printf("⚠️ [RAG] ReindexExternalContent failed: %v\n", err)
-			} else {
-				fmt.Printf("✅ [RAG] Reindexed %d external blocks (bookmarks + files)\n", extCount)
-			}
-		}()
+	// 同维度切换模型时向量语义不兼容，同样需要清空并重建
+	modelChanged, err := reconcileModel(store, modelIdentity(config))
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to check embedding model: %v\n", err)
+	}
+	excludedChanged, err := excludedTypesChanged(store, config.ExcludedBlockTypes)
+	if err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to check excluded block types: %v\n", err)
+	}
+	recordExcluded := excludedTypesRecorder(store, config.ExcludedBlockTypes, excludedChanged)
+
+	switch {
+	case dimensionChanged:
+		s.rebuildInBackground("dimension change", recordExcluded)
+	case modelChanged:
+		s.rebuildInBackground("embedding model change", recordExcluded)
+	case excludedChanged:
+		s.refreshDocumentsInBackground("excluded block types change", recordExcluded)
 	}
 
 	return nil
 }
 
-// ReindexExternalContent 重新索引所有 bookmark 和 file 块
-func (s *Service) ReindexExternalContent() (int, error) {
+// rebuildInBackground 后台重建全部索引（文档 + 外部内容），再次重新初始化或应用关闭时取消
+// 文档重建成功后调用 onDocsDone（可为 nil）
+func (s *Service) rebuildInBackground(reason string, onDocsDone func()) {
+	s.startBackground(func(ctx context.Context) {
+		fmt.Printf("🔄 [RAG] Starting automatic reindex due to %s...\n", reason)
+		if count, err := s.ReindexAll(ctx); err != nil {
+			fmt.Printf("⚠️ [RAG] ReindexAll failed: %v\n", err)
+		} else {
+			fmt.Printf("✅ [RAG] Reindexed %d documents\n", count)
+			if onDocsDone != nil {
+				onDocsDone()
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if extCount, err := s.ReindexExternalContent(ctx, true); err != nil {
+			fmt.Printf("⚠️ [RAG] ReindexExternalContent failed: %v\n", err)
+		} else {
+			fmt.Printf("✅ [RAG] Reindexed %d external blocks (bookmarks + files)\n", extCount)
+		}
+	})
+}
+
+// refreshDocumentsInBackground 后台增量重新提取所有文档
+// 仅嵌入新出现的块，并删除不再提取的块（如新排除类型的块），不重建外部内容
+// 所有文档都重新提取成功后调用 onDone（可为 nil），取消或有文档失败时不调用
+func (s *Service) refreshDocumentsInBackground(reason string, onDone func()) {
+	indexer := s.indexer
+	s.startBackground(func(ctx context.Context) {
+		fmt.Printf("🔄 [RAG] Re-extracting documents due to %s...\n", reason)
+		index, err := s.docRepo.GetAll()
+		if err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to load document index: %v\n", err)
+			return
+		}
+		count, failed := 0, 0
+		for _, doc := range index.Documents {
+			if ctx.Err() != nil {
+				return
+			}
+			if err := indexer.indexDocument(doc); err != nil {
+				fmt.Printf("⚠️ [RAG] Failed to re-extract doc %s: %v\n", doc.ID, err)
+				failed++
+				continue
+			}
+			count++
+		}
+		if failed > 0 {
+			fmt.Printf("⚠️ [RAG] Re-extracted %d documents, %d failed (will retry on next start)\n", count, failed)
+			return
+		}
+		fmt.Printf("✅ [RAG] Re-extracted %d documents\n", count)
+		if onDone != nil {
+			onDone()
+		}
+	})
+}
+
+// ReindexExternalContent 重新索引所有 bookmark 和 file 块（force 为 false 时跳过内容未变化的块，ctx 取消时提前返回）
+func (s *Service) ReindexExternalContent(ctx context.Context, force bool) (int, error) {
 	if err := s.init(); err != nil {
 		return 0, err
 	}
-	return s.externalIndexer.ReindexAll()
+	return s.externalIndexer.ReindexAll(ctx, force)
 }
 
 // ReindexExternalContentWithProgress 重新索引所有 bookmark 和 file 块（带进度回调）
-func (s *Service) ReindexExternalContentWithProgress(onProgress func(current, total int)) (int, error) {
+func (s *Service) ReindexExternalContentWithProgress(ctx context.Context, force bool, onProgress func(current, total int)) (int, error) {
 	if err := s.init(); err != nil {
 		return 0, err
 	}
-	return s.externalIndexer.ReindexAllWithProgress(onProgress)
+	return s.externalIndexer.ReindexAllWithProgress(ctx, force, onProgress)
+}
+
+// ReindexDocumentExternal 重新索引单个文档中的 bookmark/file/folder 块
+func (s *Service) ReindexDocumentExternal(docID string) (int, error) {
+	if err := s.init(); err != nil {
+		return 0, err
+	}
+	return s.externalIndexer.ReindexDocument(docID)
 }
 
 // IndexBookmarkContent 索引书签网页内容
-func (s *Service) IndexBookmarkContent(url, sourceDocID, blockID string) error {
+func (s *Service) IndexBookmarkContent(url, sourceDocID, blockID string, timeout ...time.Duration) error {
 	if err := s.init(); err != nil {
 		return err
 	}
-	return s.externalIndexer.IndexBookmarkContent(url, sourceDocID, blockID)
+	return s.externalIndexer.IndexBookmarkContent(url, sourceDocID, blockID, timeout...)
 }
 
 // IndexFileContent 索引文件内容
@@ -266,6 +836,14 @@ func (s *Service) IndexFolderContent(folderPath, sourceDocID, blockID string) (*
 	return s.externalIndexer.IndexFolderContent(folderPath, sourceDocID, blockID, 10)
 }
 
+// IndexFolderContentWithProgress 索引文件夹内容（带进度回调）
+func (s *Service) IndexFolderContentWithProgress(folderPath, sourceDocID, blockID string, onProgress func(FolderIndexProgress)) (*FolderIndexResult, error) {
+	if err := s.init(); err != nil {
+		return nil, err
+	}
+	return s.externalIndexer.IndexFolderContentWithProgress(folderPath, sourceDocID, blockID, 10, onProgress)
+}
+
 // SearchSimilarDocuments 搜索与指定文档相似的文档（用于 tag 推荐）
 func (s *Service) SearchSimilarDocuments(docID string, limit int) ([]SimilarDocResult, error) {
 	if err := s.init(); err != nil {
@@ -312,7 +890,7 @@ func extractPlainText(content []byte, maxChars int) string {
 	}
 
 	var texts []string
-	extractTextsFromBlocks(blocks, &texts)
+	extractTextsFromBlocks(blocks, &texts, 0)
 
 	result := strings.Join(texts, " ")
 	if maxChars > 0 && len(result) > maxChars {
@@ -322,7 +900,12 @@ func extractPlainText(content []byte, maxChars int) string {
 }
 
 // extractTextsFromBlocks 递归提取所有块的文本
-func extractTextsFromBlocks(blocks []interface{}, texts *[]string) {
+func extractTextsFromBlocks(blocks []interface{}, texts *[]string, depth int) {
+	if depth >= maxBlockDepth {
+		warnMaxDepth("text extraction")
+		return
+	}
+
 	for _, block := range blocks {
 		blockMap, ok := block.(map[string]interface{})
 		if !ok {
@@ -339,7 +922,7 @@ func extractTextsFromBlocks(blocks []interface{}, texts *[]string) {
 
 		// 递归处理子块
 		if children, ok := blockMap["children"].([]interface{}); ok {
-			extractTextsFromBlocks(children, texts)
+			extractTextsFromBlocks(children, texts, depth+1)
 		}
 	}
 }
@@ -1,9 +1,12 @@
 package rag
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"notion-lite/internal/document"
 	"notion-lite/internal/utils"
@@ -77,3 +80,22 @@ func TestIndexBookmarkContent(t *testing.T) {
 		t.Error("No search results found - indexing may have failed")
 	}
 }
+
+func TestCancelBackgroundReindex_WaitsForExit(t *testing.T) {
+	s := &Service{}
+	var exited atomic.Bool
+	started := make(chan struct{})
+	s.startBackground(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		// 模拟取消后仍在完成的写入
+		time.Sleep(20 * time.Millisecond)
+		exited.Store(true)
+	})
+	<-started
+
+	s.cancelBackgroundReindex()
+	if !exited.Load() {
+		t.Error("Expected cancelBackgroundReindex to wait for the background goroutine to exit")
+	}
+}
@@ -0,0 +1,130 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+
+	"notion-lite/internal/document"
+)
+
+// DocReindexPlan 单个文档的重建预估
+type DocReindexPlan struct {
+	DocID     string `json:"docId"`
+	Title     string `json:"title"`
+	Add       int    `json:"add"`       // 新增的 chunk 数
+	Update    int    `json:"update"`    // 内容变化的 chunk 数
+	Delete    int    `json:"delete"`    // 将被删除的 chunk 数
+	Unchanged int    `json:"unchanged"` // 未变化的 chunk 数
+	Chunks    int    `json:"chunks"`    // 当前提取出的 chunk 总数（强制重建时全部需要嵌入）
+}
+
+// ReindexPlan 重建索引的预估结果（不调用嵌入服务）
+type ReindexPlan struct {
+	Documents []DocReindexPlan `json:"documents"` // 有变化的文档（未变化的文档不列出）
+	Add       int              `json:"add"`
+	Update    int              `json:"update"`
+	Delete    int              `json:"delete"`
+	Unchanged int              `json:"unchanged"`
+	// 增量索引需要的嵌入：新增 + 更新的 chunk 数、批量请求数及字符数
+	Embeddings    int `json:"embeddings"`
+	EmbedRequests int `json:"embedRequests"`
+	EmbedChars    int `json:"embedChars"`
+	// 强制重建（RebuildIndex）需要的嵌入：所有 chunk 重新嵌入
+	RebuildEmbeddings    int `json:"rebuildEmbeddings"`
+	RebuildEmbedRequests int `json:"rebuildEmbedRequests"`
+	RebuildEmbedChars    int `json:"rebuildEmbedChars"`
+}
+
+// PlanReindex 对比现有块哈希与重新提取的 chunks，预估重建索引的变化和嵌入开销
+// 只读取文档和向量库元数据，不调用嵌入服务
+func (idx *Indexer) PlanReindex() (*ReindexPlan, error) {
+	index, err := idx.docRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+
+	plan := &ReindexPlan{Documents: []DocReindexPlan{}}
+	for _, doc := range index.Documents {
+		docPlan, embedChars, rebuildChars, err := idx.planDocument(doc)
+		if err != nil {
+			return nil, err
+		}
+		docPlan.Title = doc.Title
+
+		plan.Add += docPlan.Add
+		plan.Update += docPlan.Update
+		plan.Delete += docPlan.Delete
+		plan.Unchanged += docPlan.Unchanged
+		plan.Embeddings += docPlan.Add + docPlan.Update
+		plan.EmbedRequests += embedRequests(docPlan.Add + docPlan.Update)
+		plan.EmbedChars += embedChars
+		plan.RebuildEmbeddings += docPlan.Chunks
+		plan.RebuildEmbedRequests += embedRequests(docPlan.Chunks)
+		plan.RebuildEmbedChars += rebuildChars
+
+		if docPlan.Add+docPlan.Update+docPlan.Delete > 0 {
+			plan.Documents = append(plan.Documents, docPlan)
+		}
+	}
+	return plan, nil
+}
+
+// planDocument 预估单个文档的变化（与 IndexDocument 的增量判断一致，包括文档级覆盖模型计入哈希）
+// 返回待嵌入 chunk 的字符数和全部 chunk 的字符数
+func (idx *Indexer) planDocument(doc document.Meta) (DocReindexPlan, int, int, error) {
+	docID := doc.ID
+	plan := DocReindexPlan{DocID: docID}
+	// 只需要模型名计算哈希，不创建覆盖模型的客户端
+	model := idx.models.modelKey(doc.EmbedModel)
+
+	content, err := idx.docStorage.Load(docID)
+	if err != nil {
+		return plan, 0, 0, fmt.Errorf("failed to load document %s: %w", docID, err)
+	}
+	existingHashes, err := idx.store.GetBlockHashes(docID)
+	if err != nil {
+		return plan, 0, 0, fmt.Errorf("failed to get block hashes for %s: %w", docID, err)
+	}
+
+	var embedChars, rebuildChars int
+	newBlockIDs := make(map[string]bool)
+	for _, block := range idx.extractDocumentBlocks(doc, content) {
+		if block.Content == "" {
+			continue
+		}
+		newBlockIDs[block.ID] = true
+		plan.Chunks++
+		chars := len([]rune(block.Content))
+		rebuildChars += chars
+
+		oldHash, exists := existingHashes[block.ID]
+		switch {
+		case !exists:
+			plan.Add++
+			embedChars += chars
+		case oldHash != blockHash(block, model):
+			plan.Update++
+			embedChars += chars
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	// bookmark/file/folder 块由外部索引器维护，不计入删除
+	for id := range existingHashes {
+		if !newBlockIDs[id] && !isExternalBlockID(id) {
+			plan.Delete++
+		}
+	}
+	return plan, embedChars, rebuildChars, nil
+}
+
+// isExternalBlockID 判断块 ID 是否属于外部块（bookmark/file/folder）
+func isExternalBlockID(id string) bool {
+	return strings.Contains(id, "_bookmark") || strings.Contains(id, "_file") || strings.Contains(id, "_folder")
+}
+
+// embedRequests 嵌入 n 个 chunk 需要的批量请求数
+func embedRequests(n int) int {
+	return (n + embedBatchSize - 1) / embedBatchSize
+}
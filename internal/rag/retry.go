@@ -0,0 +1,61 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// withRetry 按指数退避重试执行 op，仅对可重试错误（网络错误、429、5xx）重试
+// ctx 取消时立即返回
+func withRetry(ctx context.Context, config RetryConfig, op func() error) error {
+	config = config.withDefaults()
+
+	var err error
+	for attempt := 0; attempt < config.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoffDelay(config, attempt)
+			fmt.Printf("🔄 [RAG] Embedding request failed (%v), retrying in %s (%d/%d)\n", err, delay, attempt+1, config.MaxAttempts)
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err = op()
+		if err == nil || !isRetryableError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// backoffDelay 计算第 attempt 次重试的等待时间（base * 2^(attempt-1) ± jitter）
+func backoffDelay(config RetryConfig, attempt int) time.Duration {
+	delay := time.Duration(config.BaseDelayMs) * time.Millisecond << (attempt - 1)
+	if config.Jitter > 0 {
+		delta := (rand.Float64()*2 - 1) * config.Jitter * float64(delay)
+		delay += time.Duration(delta)
+	}
+	return delay
+}
+
+// isRetryableError 判断错误是否值得重试：网络错误（EmbeddingRequestError）、429 和 5xx
+// 响应格式错误（StatusCode -1）、其他 4xx 以及构造请求失败等错误不重试
+func isRetryableError(err error) bool {
+	var requestErr *EmbeddingRequestError
+	if errors.As(err, &requestErr) {
+		return true
+	}
+	if serviceErr, ok := IsEmbeddingServiceError(err); ok {
+		return serviceErr.StatusCode == http.StatusTooManyRequests || serviceErr.StatusCode >= 500
+	}
+	return false
+}
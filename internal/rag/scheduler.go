@@ -0,0 +1,186 @@
+package rag
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMaxPendingIndexes 默认最多同时等待的文档索引数
+const DefaultMaxPendingIndexes = 256
+
+// IndexScheduler 按文档合并的索引调度器
+// 应用内保存和外部文件变更共用同一个调度器：同一文档在 debounce 窗口内的多次触发只索引一次，
+// 且同一文档的索引不会并发执行（运行期间的新触发会在完成后重新调度）
+type IndexScheduler struct {
+	mu         sync.Mutex
+	delay      time.Duration
+	run        func(docID string)
+	entries    map[string]*scheduledIndex
+	maxPending int             // 等待中的定时器上限，超出时立即执行最早的等待项（批量修改大量文档时限制定时器数量）
+	overflow   []overflowIndex // 因超出上限而提前执行的等待项，由单个 goroutine 依次执行
+	draining   bool            // 是否已有 goroutine 在执行 overflow
+}
+
+// overflowIndex 提前执行的等待项
+type overflowIndex struct {
+	docID string
+	seq   uint64
+}
+
+// scheduledIndex 单个文档的调度状态
+type scheduledIndex struct {
+	timer   *time.Timer
+	since   time.Time // 本轮等待开始的时间（debounce 重置不更新，用于找出最早的等待项）
+	seq     uint64    // 每次调度递增，过期的定时器回调据此忽略
+	running bool
+	rerun   bool // 运行期间又收到了调度请求
+}
+
+// NewIndexScheduler 创建索引调度器，run 在最后一次触发 delay 之后执行
+func NewIndexScheduler(delay time.Duration, run func(docID string)) *IndexScheduler {
+	return &IndexScheduler{
+		delay:      delay,
+		run:        run,
+		entries:    make(map[string]*scheduledIndex),
+		maxPending: DefaultMaxPendingIndexes,
+	}
+}
+
+// SetMaxPending 设置等待中的定时器上限（<= 0 表示不限制）
+func (s *IndexScheduler) SetMaxPending(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPending = n
+}
+
+// Schedule 调度文档索引（重置该文档的 debounce 定时器）
+func (s *IndexScheduler) Schedule(docID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scheduleLocked(docID)
+}
+
+func (s *IndexScheduler) scheduleLocked(docID string) {
+	entry := s.entries[docID]
+	if entry == nil {
+		entry = &scheduledIndex{}
+		s.entries[docID] = entry
+	}
+	if entry.timer != nil {
+		entry.timer.Stop()
+	} else {
+		s.flushOverflowLocked()
+		entry.since = time.Now()
+	}
+	entry.seq++
+	seq := entry.seq
+	entry.timer = time.AfterFunc(s.delay, func() { s.fire(docID, seq) })
+}
+
+// flushOverflowLocked 等待项达到上限时，立即执行最早的等待项，为新的等待项腾出位置
+// 溢出项排队后由单个 goroutine 依次执行，批量修改大量文档时不会同时启动大量索引
+func (s *IndexScheduler) flushOverflowLocked() {
+	if s.maxPending <= 0 {
+		return
+	}
+	pending := 0
+	var oldestID string
+	var oldest *scheduledIndex
+	for id, entry := range s.entries {
+		if entry.timer == nil {
+			continue
+		}
+		pending++
+		if oldest == nil || entry.since.Before(oldest.since) {
+			oldestID, oldest = id, entry
+		}
+	}
+	if pending < s.maxPending || oldest == nil {
+		return
+	}
+
+	oldest.timer.Stop()
+	oldest.timer = nil
+	s.overflow = append(s.overflow, overflowIndex{docID: oldestID, seq: oldest.seq})
+	if !s.draining {
+		s.draining = true
+		go s.drainOverflow()
+	}
+}
+
+// drainOverflow 依次执行排队的溢出项，队列为空时退出
+func (s *IndexScheduler) drainOverflow() {
+	for {
+		s.mu.Lock()
+		if len(s.overflow) == 0 {
+			s.draining = false
+			s.mu.Unlock()
+			return
+		}
+		next := s.overflow[0]
+		s.overflow = s.overflow[1:]
+		s.mu.Unlock()
+
+		s.fire(next.docID, next.seq)
+	}
+}
+
+// fire 定时器到期后执行索引
+func (s *IndexScheduler) fire(docID string, seq uint64) {
+	s.mu.Lock()
+	entry := s.entries[docID]
+	if entry == nil || entry.seq != seq {
+		// 已被新的调度取代或已取消
+		s.mu.Unlock()
+		return
+	}
+	entry.timer = nil
+	if entry.running {
+		entry.rerun = true
+		s.mu.Unlock()
+		return
+	}
+	entry.running = true
+	s.mu.Unlock()
+
+	s.run(docID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.running = false
+	if entry.rerun {
+		entry.rerun = false
+		s.scheduleLocked(docID)
+		return
+	}
+	if entry.timer == nil && s.entries[docID] == entry {
+		delete(s.entries, docID)
+	}
+}
+
+// Cancel 取消文档的待执行索引（文档被删除时调用），不影响正在运行的索引
+func (s *IndexScheduler) Cancel(docID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entries[docID]
+	if entry == nil {
+		return
+	}
+	if entry.timer != nil {
+		entry.timer.Stop()
+		entry.timer = nil
+	}
+	entry.seq++
+	entry.rerun = false
+	if !entry.running {
+		delete(s.entries, docID)
+	}
+}
+
+// Pending 返回有待执行或正在执行索引的文档数
+func (s *IndexScheduler) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
@@ -0,0 +1,175 @@
+package rag
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIndexScheduler_CoalescesTriggerSources(t *testing.T) {
+	// 应用内保存和外部写入几乎同时触发同一文档，只应索引一次
+	var runs int32
+	scheduler := NewIndexScheduler(30*time.Millisecond, func(docID string) {
+		atomic.AddInt32(&runs, 1)
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); scheduler.Schedule("doc1") }() // 应用内保存
+	go func() { defer wg.Done(); scheduler.Schedule("doc1") }() // 外部写入
+	wg.Wait()
+
+	time.Sleep(120 * time.Millisecond)
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("Expected 1 index run, got %d", got)
+	}
+	if scheduler.Pending() != 0 {
+		t.Errorf("Expected no pending entries, got %d", scheduler.Pending())
+	}
+}
+
+func TestIndexScheduler_NoConcurrentRunsPerDocument(t *testing.T) {
+	// 索引运行期间的新触发应在完成后重新执行，而不是并发执行
+	var runs, active, overlap int32
+	release := make(chan struct{})
+	scheduler := NewIndexScheduler(10*time.Millisecond, func(docID string) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.StoreInt32(&overlap, 1)
+		}
+		if atomic.AddInt32(&runs, 1) == 1 {
+			<-release
+		}
+		atomic.AddInt32(&active, -1)
+	})
+
+	scheduler.Schedule("doc1")
+	time.Sleep(40 * time.Millisecond) // 第一次索引正在运行
+	scheduler.Schedule("doc1")
+	time.Sleep(40 * time.Millisecond) // 第二次触发到期时第一次仍在运行
+	close(release)
+	time.Sleep(80 * time.Millisecond)
+
+	if atomic.LoadInt32(&overlap) != 0 {
+		t.Error("Index runs for the same document overlapped")
+	}
+	if got := atomic.LoadInt32(&runs); got != 2 {
+		t.Errorf("Expected 2 index runs, got %d", got)
+	}
+}
+
+func TestIndexScheduler_Cancel(t *testing.T) {
+	var runs int32
+	scheduler := NewIndexScheduler(20*time.Millisecond, func(docID string) {
+		atomic.AddInt32(&runs, 1)
+	})
+
+	scheduler.Schedule("doc1")
+	scheduler.Cancel("doc1")
+	time.Sleep(60 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&runs); got != 0 {
+		t.Errorf("Expected cancelled index not to run, got %d runs", got)
+	}
+}
+
+func TestIndexScheduler_MaxPendingFlushesOldest(t *testing.T) {
+	// 大量文档同时被修改时，等待中的定时器数量不超过上限，溢出的文档立即索引
+	const docs, limit = 200, 16
+	var mu sync.Mutex
+	runs := make(map[string]int)
+	scheduler := NewIndexScheduler(time.Hour, func(docID string) {
+		mu.Lock()
+		runs[docID]++
+		mu.Unlock()
+	})
+	scheduler.SetMaxPending(limit)
+
+	var wg sync.WaitGroup
+	for i := 0; i < docs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			scheduler.Schedule(fmt.Sprintf("doc%d", i))
+		}(i)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		flushed := len(runs)
+		mu.Unlock()
+		if flushed >= docs-limit || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	scheduler.mu.Lock()
+	timers := 0
+	for _, entry := range scheduler.entries {
+		if entry.timer != nil {
+			timers++
+		}
+	}
+	scheduler.mu.Unlock()
+	if timers > limit {
+		t.Errorf("Expected at most %d pending timers, got %d", limit, timers)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) != docs-limit {
+		t.Errorf("Expected %d documents flushed immediately, got %d", docs-limit, len(runs))
+	}
+	for docID, n := range runs {
+		if n != 1 {
+			t.Errorf("Expected %s to be indexed once, got %d", docID, n)
+		}
+	}
+}
+
+func TestIndexScheduler_OverflowRunsSequentially(t *testing.T) {
+	// 溢出的文档依次执行，不会为每个溢出项各启动一个并发索引
+	const docs = 50
+	var mu sync.Mutex
+	active, maxActive, done := 0, 0, 0
+	scheduler := NewIndexScheduler(time.Hour, func(docID string) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		active--
+		done++
+		mu.Unlock()
+	})
+	scheduler.SetMaxPending(1)
+
+	for i := 0; i < docs; i++ {
+		scheduler.Schedule(fmt.Sprintf("doc%d", i))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		finished := done
+		mu.Unlock()
+		if finished >= docs-1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if done != docs-1 {
+		t.Errorf("Expected %d overflow runs, got %d", docs-1, done)
+	}
+	if maxActive != 1 {
+		t.Errorf("Expected overflow runs to be sequential, got %d concurrent", maxActive)
+	}
+}
@@ -0,0 +1,150 @@
+package rag
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// 搜索结果导出格式
+const (
+	SearchExportJSON = "json"
+	SearchExportCSV  = "csv"
+)
+
+// docLinkPrefix 文档链接前缀（与 MCP 资源 URI 一致）
+const docLinkPrefix = "nook://doc/"
+
+// SearchExportRecord 导出的单条搜索结果
+type SearchExportRecord struct {
+	Rank           int     `json:"rank"`
+	DocID          string  `json:"docId"`
+	DocTitle       string  `json:"docTitle"`
+	Content        string  `json:"content"`
+	Score          float32 `json:"score"`
+	HeadingContext string  `json:"headingContext"`
+	BlockType      string  `json:"blockType"`
+	SourceType     string  `json:"sourceType"`
+	SourceTitle    string  `json:"sourceTitle,omitempty"` // 书签标题/文件名
+	SourceLink     string  `json:"sourceLink"`            // nook://doc/{docId}[#blockId]
+}
+
+// SearchExport 搜索结果导出文件内容（JSON 格式）
+type SearchExport struct {
+	Query      string               `json:"query"`
+	ExportedAt string               `json:"exportedAt"` // RFC 3339
+	Results    []SearchExportRecord `json:"results"`
+}
+
+// SearchExportResult 搜索结果导出结果
+type SearchExportResult struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+	Count  int    `json:"count"`
+}
+
+// ExportSearchResults 执行块级语义搜索并将结果（含来源上下文）导出为 JSON 或 CSV
+func (s *Service) ExportSearchResults(query string, limit int, format, path string) (*SearchExportResult, error) {
+	if format != SearchExportJSON && format != SearchExportCSV {
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	matches, err := s.SearchChunks(query, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string)
+	if index, err := s.docRepo.GetAll(); err == nil {
+		for _, doc := range index.Documents {
+			titles[doc.ID] = doc.Title
+		}
+	}
+
+	export := SearchExport{
+		Query:      query,
+		ExportedAt: time.Now().Format(time.RFC3339),
+		Results:    make([]SearchExportRecord, 0, len(matches)),
+	}
+	for i, match := range matches {
+		link := docLinkPrefix + match.DocID
+		if match.SourceBlockId != "" {
+			link += "#" + match.SourceBlockId
+		}
+		export.Results = append(export.Results, SearchExportRecord{
+			Rank:           i + 1,
+			DocID:          match.DocID,
+			DocTitle:       titles[match.DocID],
+			Content:        match.Content,
+			Score:          match.Score,
+			HeadingContext: match.HeadingContext,
+			BlockType:      match.BlockType,
+			SourceType:     match.SourceType,
+			SourceTitle:    match.SourceTitle,
+			SourceLink:     link,
+		})
+	}
+
+	if format == SearchExportCSV {
+		err = writeSearchExportCSV(path, &export)
+	} else {
+		err = writeSearchExportJSON(path, &export)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SearchExportResult{Path: path, Format: format, Count: len(export.Results)}, nil
+}
+
+// writeSearchExportJSON 写入 JSON 导出文件
+func writeSearchExportJSON(path string, export *SearchExport) error {
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode search results: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// writeSearchExportCSV 写入 CSV 导出文件
+// 前两行为查询和导出时间，空一行后是表头和结果；带 UTF-8 BOM 以便 Excel 正确识别中文
+func writeSearchExportCSV(path string, export *SearchExport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := file.WriteString("\uFEFF"); err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	rows := [][]string{
+		{"query", export.Query},
+		{"exportedAt", export.ExportedAt},
+		{},
+		{"rank", "docTitle", "content", "score", "headingContext", "blockType", "sourceType", "sourceTitle", "sourceLink"},
+	}
+	for _, r := range export.Results {
+		rows = append(rows, []string{
+			strconv.Itoa(r.Rank),
+			r.DocTitle,
+			r.Content,
+			strconv.FormatFloat(float64(r.Score), 'f', 4, 32),
+			r.HeadingContext,
+			r.BlockType,
+			r.SourceType,
+			r.SourceTitle,
+			r.SourceLink,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return file.Close()
+}
@@ -1,6 +1,7 @@
 package rag
 
 import (
+	"fmt"
 	"notion-lite/internal/document"
 	"regexp"
 	"sort"
@@ -24,8 +25,9 @@ type ChunkMatch struct {
 type DocumentSearchResult struct {
 	DocID         string       `json:"docId"`
 	DocTitle      string       `json:"docTitle"`
-	MaxScore      float32      `json:"maxScore"`      // 最高相关性分数
-	MatchedChunks []ChunkMatch `json:"matchedChunks"` // 匹配的 chunks（按分数排序）
+	Tags          []string     `json:"tags,omitempty"` // 文档标签
+	MaxScore      float32      `json:"maxScore"`       // 最高相关性分数
+	MatchedChunks []ChunkMatch `json:"matchedChunks"`  // 匹配的 chunks（按分数排序）
 }
 
 // Searcher 语义搜索器
@@ -33,53 +35,175 @@ type Searcher struct {
 	store    *VectorStore
 	embedder EmbeddingClient
 	docRepo  *document.Repository
+
+	mmrLambda  float32      // MMR 重排的相关性权重
+	titleBoost float32      // 标题 chunk 的加分
+	models     *embedderSet // 文档级覆盖模型的客户端（nil 时不按模型路由）
 }
 
 // NewSearcher 创建搜索器
 func NewSearcher(store *VectorStore, embedder EmbeddingClient, docRepo *document.Repository) *Searcher {
 	return &Searcher{
-		store:    store,
-		embedder: embedder,
-		docRepo:  docRepo,
+		store:      store,
+		embedder:   embedder,
+		docRepo:    docRepo,
+		mmrLambda:  DefaultMMRLambda,
+		titleBoost: DefaultTitleBoost,
+	}
+}
+
+// DefaultTitleBoost 标题 chunk 在文档搜索中的默认加分
+const DefaultTitleBoost = 0.1
+
+// SetTitleBoost 设置标题 chunk 的加分（0 表示不加分，负数时使用默认值）
+func (s *Searcher) SetTitleBoost(boost float64) {
+	if boost < 0 {
+		boost = DefaultTitleBoost
 	}
+	s.titleBoost = float32(boost)
+}
+
+// SetMMRLambda 设置 MMR 重排的相关性权重（0~1，超出范围时使用默认值）
+func (s *Searcher) SetMMRLambda(lambda float64) {
+	if lambda <= 0 || lambda > 1 {
+		lambda = DefaultMMRLambda
+	}
+	s.mmrLambda = float32(lambda)
+}
+
+// SetModelEmbedders 设置文档级覆盖模型的客户端集合，用于按模型路由查询
+func (s *Searcher) SetModelEmbedders(models *embedderSet) {
+	s.models = models
+}
+
+// searchVectors 向量检索，按块的嵌入模型路由查询：
+// 默认模型的块用 queryVec 检索；使用覆盖模型的块用该模型重新嵌入查询后单独检索，合并后按距离取前 limit 个
+func (s *Searcher) searchVectors(query string, queryVec []float32, limit int, filter *SearchFilter) ([]SearchResult, error) {
+	var models []string
+	if s.models != nil {
+		models, _ = s.store.EmbedModels()
+	}
+	if len(models) == 0 {
+		return s.store.Search(queryVec, limit, filter)
+	}
+
+	withModel := func(model string) *SearchFilter {
+		routed := SearchFilter{}
+		if filter != nil {
+			routed = *filter
+		}
+		routed.embedModel = &model
+		return &routed
+	}
+	results, err := s.store.Search(queryVec, limit, withModel(""))
+	if err != nil {
+		return nil, err
+	}
+	for _, model := range models {
+		client, err := s.models.client(model)
+		if err != nil {
+			fmt.Printf("⚠️ [RAG] Skipping chunks embedded with %s: %v\n", model, err)
+			continue
+		}
+		vec, err := client.EmbedWithType(query, EmbedKindQuery)
+		if err != nil {
+			fmt.Printf("⚠️ [RAG] Failed to embed query with %s: %v\n", model, err)
+			continue
+		}
+		more, err := s.store.Search(vec, limit, withModel(model))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, more...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
 }
 
 // SearchDocuments 执行文档级语义搜索（聚合 chunks）
 func (s *Searcher) SearchDocuments(query string, limit int, filter *SearchFilter) ([]DocumentSearchResult, error) {
+	results, _, err := s.SearchDocumentsPaged(query, limit, 0, filter)
+	return results, err
+}
+
+// SearchDocumentsPaged 执行分页的文档级语义搜索，跳过前 offset 个文档，hasMore 表示是否还有下一页
+func (s *Searcher) SearchDocumentsPaged(query string, limit, offset int, filter *SearchFilter) ([]DocumentSearchResult, bool, error) {
+	if offset < 0 {
+		offset = 0
+	}
+
+	// 空查询的向量没有语义，返回的只会是任意结果
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []DocumentSearchResult{}, false, nil
+	}
+
 	// 1. 生成查询向量
-	queryVec, err := s.embedder.Embed(query)
+	queryVec, err := s.embedder.EmbedWithType(query, EmbedKindQuery)
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+
+	filter, ok := s.resolveFilter(filter)
+	if !ok {
+		return []DocumentSearchResult{}, false, nil
 	}
 
-	// 2. 扩大召回量以确保覆盖更多文档
+	// 2. 扩大召回量以确保覆盖更多文档（含前面各页，多取一个文档用于判断是否有下一页）
 	// 如果有过滤条件可能需要召回更多
 	multiplier := 5
-	if filter != nil && filter.ExcludeDocID != "" {
+	if filter != nil && (filter.ExcludeDocID != "" || len(filter.ExcludeDocIDs) > 0) {
 		multiplier = 8
 	}
-	expandedLimit := limit * multiplier
+	expandedLimit := (offset + limit + 1) * multiplier
 	if expandedLimit < 30 {
 		expandedLimit = 30
 	}
 
-	results, err := s.store.Search(queryVec, expandedLimit, filter)
+	results, err := s.searchVectors(query, queryVec, expandedLimit, filter)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// 3. 获取文档标题映射
+	// 多样性重排：文档和文档内 chunks 按 MMR 顺序排列，近似重复的内容被后移
+	diversify := filter != nil && filter.Diversify
+	rank := make(map[string]int, len(results))
+	if diversify {
+		if results, err = s.diversify(results); err != nil {
+			return nil, false, err
+		}
+		for i, r := range results {
+			rank[r.BlockID] = i
+		}
+	}
+
+	// 3. 获取文档元数据映射（标题、标签）
 	index, _ := s.docRepo.GetAll()
-	titleMap := make(map[string]string)
+	metaMap := make(map[string]document.Meta, len(index.Documents))
 	for _, doc := range index.Documents {
-		titleMap[doc.ID] = doc.Title
+		metaMap[doc.ID] = doc
+	}
+
+	// 根据相似查询的历史反馈微调块分数（无反馈时为空）
+	var adjustments map[string]float32
+	if feedback, err := s.store.GetSearchFeedback(); err == nil {
+		adjustments = feedbackAdjustments(queryVec, feedback)
 	}
 
 	// 4. 按 DocID 聚合 chunks（过滤已在 store 层完成）
 	docMap := make(map[string]*DocumentSearchResult)
 	for _, r := range results {
 
-		score := 1 - r.Distance // 距离转相似度
+		score := s.store.Similarity(r.Distance) + adjustments[r.BlockID] // 距离转相似度，叠加反馈调整
+		if r.BlockType == titleBlockType {
+			score += s.titleBoost // 标题命中是强相关信号
+		}
 
 		chunk := ChunkMatch{
 			BlockID:        r.BlockID,
@@ -101,7 +225,8 @@ func (s *Searcher) SearchDocuments(query string, limit int, filter *SearchFilter
 		} else {
 			docMap[r.DocID] = &DocumentSearchResult{
 				DocID:         r.DocID,
-				DocTitle:      titleMap[r.DocID],
+				DocTitle:      metaMap[r.DocID].Title,
+				Tags:          metaMap[r.DocID].Tags,
 				MaxScore:      score,
 				MatchedChunks: []ChunkMatch{chunk},
 			}
@@ -111,8 +236,11 @@ func (s *Searcher) SearchDocuments(query string, limit int, filter *SearchFilter
 	// 5. 转换为切片并按 MaxScore 排序
 	output := make([]DocumentSearchResult, 0, len(docMap))
 	for _, doc := range docMap {
-		// 对每个文档内的 chunks 按分数排序
+		// 对每个文档内的 chunks 按分数排序（多样性重排时按 MMR 顺序）
 		sort.Slice(doc.MatchedChunks, func(i, j int) bool {
+			if diversify {
+				return rank[doc.MatchedChunks[i].BlockID] < rank[doc.MatchedChunks[j].BlockID]
+			}
 			return doc.MatchedChunks[i].Score > doc.MatchedChunks[j].Score
 		})
 		// 限制每个文档最多返回 3 个 chunks
@@ -122,32 +250,57 @@ func (s *Searcher) SearchDocuments(query string, limit int, filter *SearchFilter
 		output = append(output, *doc)
 	}
 
-	// 按 MaxScore 降序排序
+	// 按 MaxScore 降序排序（多样性重排时按文档首个 chunk 的 MMR 顺序）
 	sort.Slice(output, func(i, j int) bool {
+		if diversify {
+			return rank[output[i].MatchedChunks[0].BlockID] < rank[output[j].MatchedChunks[0].BlockID]
+		}
 		return output[i].MaxScore > output[j].MaxScore
 	})
 
-	// 限制返回数量
-	if len(output) > limit {
-		output = output[:limit]
+	// 按偏移量和数量切片
+	if offset >= len(output) {
+		return []DocumentSearchResult{}, false, nil
 	}
-
-	return output, nil
+	return output[offset:min(offset+limit, len(output))], len(output) > offset+limit, nil
 }
 
 // SearchChunks 执行块级语义搜索（不聚合）
 func (s *Searcher) SearchChunks(query string, limit int, filter *SearchFilter) ([]ChunkMatch, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []ChunkMatch{}, nil
+	}
+
 	// 1. 生成查询向量
-	queryVec, err := s.embedder.Embed(query)
+	queryVec, err := s.embedder.EmbedWithType(query, EmbedKindQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. 搜索
-	results, err := s.store.Search(queryVec, limit, filter)
+	filter, ok := s.resolveFilter(filter)
+	if !ok {
+		return []ChunkMatch{}, nil
+	}
+
+	// 2. 搜索（多样性重排时多召回候选，重排后再截断）
+	diversify := filter != nil && filter.Diversify
+	k := limit
+	if diversify {
+		k = limit * mmrCandidateMultiplier
+	}
+	results, err := s.searchVectors(query, queryVec, k, filter)
 	if err != nil {
 		return nil, err
 	}
+	if diversify {
+		if results, err = s.diversify(results); err != nil {
+			return nil, err
+		}
+		if len(results) > limit {
+			results = results[:limit]
+		}
+	}
 
 	// 3. 转换结果
 	matches := make([]ChunkMatch, len(results))
@@ -160,7 +313,7 @@ func (s *Searcher) SearchChunks(query string, limit int, filter *SearchFilter) (
 			Content:        r.Content,
 			BlockType:      r.BlockType,
 			HeadingContext: r.HeadingContext,
-			Score:          1 - r.Distance,
+			Score:          s.store.Similarity(r.Distance),
 			DocID:          r.DocID,
 		}
 
@@ -169,6 +322,49 @@ func (s *Searcher) SearchChunks(query string, limit int, filter *SearchFilter) (
 	return matches, nil
 }
 
+// resolveFilter 将标签过滤解析为文档 ID 过滤（标签存储在文档索引中而非向量库）
+// 同时指定 DocIDs 时取交集；没有任何文档匹配时返回 false
+func (s *Searcher) resolveFilter(filter *SearchFilter) (*SearchFilter, bool) {
+	if filter == nil || len(filter.Tags) == 0 {
+		return filter, true
+	}
+
+	index, err := s.docRepo.GetAll()
+	if err != nil {
+		return filter, false
+	}
+
+	wanted := make(map[string]bool, len(filter.Tags))
+	for _, tag := range filter.Tags {
+		wanted[tag] = true
+	}
+	allowed := make(map[string]bool, len(filter.DocIDs))
+	for _, id := range filter.DocIDs {
+		allowed[id] = true
+	}
+
+	var docIDs []string
+	for _, doc := range index.Documents {
+		if len(allowed) > 0 && !allowed[doc.ID] {
+			continue
+		}
+		for _, tag := range doc.Tags {
+			if wanted[tag] {
+				docIDs = append(docIDs, doc.ID)
+				break
+			}
+		}
+	}
+	if len(docIDs) == 0 {
+		return filter, false
+	}
+
+	resolved := *filter
+	resolved.Tags = nil
+	resolved.DocIDs = docIDs
+	return &resolved, true
+}
+
 // uuidPattern 匹配 UUID 格式（支持大小写）
 var uuidPattern = regexp.MustCompile(`(?i)[a-f0-9]{8}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{4}-[a-f0-9]{12}`)
 
@@ -223,6 +419,10 @@ func parseSourceBlockId(blockId string) string {
 // getSourceBlockId 获取原始块 ID 用于定位
 // 优先使用数据库存储的 SourceBlockID，如果为空则回退到解析
 func getSourceBlockId(r SearchResult) string {
+	// 标题 chunk 定位到文档本身
+	if r.BlockType == titleBlockType {
+		return ""
+	}
 	// 优先使用直接存储的 SourceBlockID
 	if r.SourceBlockID != "" {
 		return r.SourceBlockID
@@ -0,0 +1,324 @@
+package rag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSearchDocuments_Filter(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	for _, id := range []string{"doc1", "doc2", "doc3"} {
+		if _, err := indexer.docRepo.CreateWithID(id, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.docRepo.AddTag("doc1", "research"); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.docRepo.AddTag("doc3", "research"); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := []*BlockVector{
+		{ID: "b1", DocID: "doc1", Content: "研究笔记", BlockType: "paragraph", Embedding: []float32{1, 0, 0}},
+		{ID: "b2", DocID: "doc2", Content: "日常记录", BlockType: "paragraph", Embedding: []float32{1, 0, 0}},
+		{ID: "b3", DocID: "doc3", Content: "研究标题", BlockType: "heading", Embedding: []float32{0.9, 0.1, 0}},
+	}
+	for _, b := range blocks {
+		if err := indexer.store.Upsert(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docIDs := func(t *testing.T, filter *SearchFilter) []string {
+		t.Helper()
+		results, err := searcher.SearchDocuments("研究", 10, filter)
+		if err != nil {
+			t.Fatalf("SearchDocuments failed: %v", err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.DocID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	tests := []struct {
+		name   string
+		filter *SearchFilter
+		want   []string
+	}{
+		{"no filter", nil, []string{"doc1", "doc2", "doc3"}},
+		{"by tag", &SearchFilter{Tags: []string{"research"}}, []string{"doc1", "doc3"}},
+		{"tag and doc ids", &SearchFilter{Tags: []string{"research"}, DocIDs: []string{"doc3", "doc2"}}, []string{"doc3"}},
+		{"by block type", &SearchFilter{BlockTypes: []string{"heading"}}, []string{"doc3"}},
+		{"exclude docs", &SearchFilter{ExcludeDocIDs: []string{"doc1", "doc3"}}, []string{"doc2"}},
+		{"unknown tag", &SearchFilter{Tags: []string{"missing"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := docIDs(t, tt.filter)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSearch_WhitespaceQueryReturnsNothing(t *testing.T) {
+	embedder := &countingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	if _, err := indexer.docRepo.CreateWithID("doc1", "doc1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := indexer.store.Upsert(&BlockVector{ID: "b1", DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := searcher.SearchDocuments("   ", 10, nil)
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	if docs == nil || len(docs) != 0 {
+		t.Errorf("Expected an empty document list, got %v", docs)
+	}
+	chunks, err := searcher.SearchChunks(" \t\n", 10, nil)
+	if err != nil {
+		t.Fatalf("SearchChunks failed: %v", err)
+	}
+	if chunks == nil || len(chunks) != 0 {
+		t.Errorf("Expected an empty chunk list, got %v", chunks)
+	}
+	// 空查询不应调用嵌入服务
+	if embedder.calls != 0 {
+		t.Errorf("Expected no embed calls for whitespace queries, got %d", embedder.calls)
+	}
+}
+
+func TestSearchChunks_SingleTagExcludesOtherDocs(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	for _, id := range []string{"research", "journal"} {
+		if _, err := indexer.docRepo.CreateWithID(id, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.docRepo.AddTag("research", "research"); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := []*BlockVector{
+		{ID: "r1", DocID: "research", Content: "论文摘要", BlockType: "paragraph", Embedding: []float32{1, 0, 0}},
+		{ID: "r2", DocID: "research", SourceType: "bookmark", Content: "网页内容", BlockType: "bookmark", Embedding: []float32{1, 0, 0}},
+		{ID: "j1", DocID: "journal", Content: "今天的日记", BlockType: "paragraph", Embedding: []float32{1, 0, 0}},
+		{ID: "j2", DocID: "journal", Content: "明天的计划", BlockType: "paragraph", Embedding: []float32{1, 0, 0}},
+	}
+	for _, b := range blocks {
+		if err := indexer.store.Upsert(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chunks, err := searcher.SearchChunks("论文", 10, &SearchFilter{Tags: []string{"research"}})
+	if err != nil {
+		t.Fatalf("SearchChunks failed: %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Expected 2 chunks from the tagged document, got %d", len(chunks))
+	}
+	for _, c := range chunks {
+		if c.DocID != "research" {
+			t.Errorf("Expected only chunks from tagged document, got %s (%s)", c.BlockID, c.DocID)
+		}
+	}
+
+	chunks, err = searcher.SearchChunks("论文", 10, &SearchFilter{Tags: []string{"research"}, ExcludeBookmarks: true})
+	if err != nil {
+		t.Fatalf("SearchChunks failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].BlockID != "r1" {
+		t.Errorf("Expected only r1 when excluding bookmarks, got %+v", chunks)
+	}
+}
+
+func TestSearchDocumentsPaged(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	// 6 个文档，与查询向量 [1,0,0] 的距离依次增大
+	const docCount = 6
+	for i := 0; i < docCount; i++ {
+		docID := fmt.Sprintf("doc%d", i)
+		if _, err := indexer.docRepo.CreateWithID(docID, docID); err != nil {
+			t.Fatal(err)
+		}
+		if err := indexer.store.Upsert(&BlockVector{
+			ID: docID + "_b", DocID: docID, Content: docID, BlockType: "paragraph",
+			Embedding: []float32{1, float32(i) * 0.2, 0},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type page struct {
+		Results []DocumentSearchResult
+		HasMore bool
+	}
+	paged := func(offset int) (*page, error) {
+		results, hasMore, err := searcher.SearchDocumentsPaged("查询", 2, offset, nil)
+		return &page{Results: results, HasMore: hasMore}, err
+	}
+	first, err := paged(0)
+	if err != nil {
+		t.Fatalf("SearchDocumentsPaged failed: %v", err)
+	}
+	second, err := paged(2)
+	if err != nil {
+		t.Fatalf("SearchDocumentsPaged failed: %v", err)
+	}
+	last, err := paged(4)
+	if err != nil {
+		t.Fatalf("SearchDocumentsPaged failed: %v", err)
+	}
+
+	ids := func(p *page) string {
+		var out []string
+		for _, r := range p.Results {
+			out = append(out, r.DocID)
+		}
+		return strings.Join(out, ",")
+	}
+	if got := ids(first); got != "doc0,doc1" || !first.HasMore {
+		t.Errorf("Expected first page doc0,doc1 with more, got %s (hasMore=%v)", got, first.HasMore)
+	}
+	if got := ids(second); got != "doc2,doc3" || !second.HasMore {
+		t.Errorf("Expected second page doc2,doc3 with more, got %s (hasMore=%v)", got, second.HasMore)
+	}
+	if got := ids(last); got != "doc4,doc5" || last.HasMore {
+		t.Errorf("Expected last page doc4,doc5 without more, got %s (hasMore=%v)", got, last.HasMore)
+	}
+
+	beyond, err := paged(10)
+	if err != nil {
+		t.Fatalf("SearchDocumentsPaged failed: %v", err)
+	}
+	if len(beyond.Results) != 0 || beyond.HasMore {
+		t.Errorf("Expected empty page past the end, got %+v", beyond)
+	}
+}
+
+func TestSearchChunks_Diversify(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	// dup1/dup2 内容相同且与查询最相关；other 相关性略低但方向不同
+	blocks := []*BlockVector{
+		{ID: "dup1", DocID: "doc1", Content: "同一段落", BlockType: "paragraph", Embedding: []float32{0.8, 0.6, 0}},
+		{ID: "dup2", DocID: "doc1", Content: "同一段落", BlockType: "paragraph", Embedding: []float32{0.8, 0.6, 0}},
+		{ID: "other", DocID: "doc2", Content: "另一个观点", BlockType: "paragraph", Embedding: []float32{0.7, -0.714, 0}},
+	}
+	for _, b := range blocks {
+		if err := indexer.store.Upsert(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(t *testing.T, filter *SearchFilter) []string {
+		t.Helper()
+		chunks, err := searcher.SearchChunks("查询", 2, filter)
+		if err != nil {
+			t.Fatalf("SearchChunks failed: %v", err)
+		}
+		var result []string
+		for _, c := range chunks {
+			result = append(result, c.BlockID)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	if got := ids(t, nil); strings.Join(got, ",") != "dup1,dup2" {
+		t.Errorf("Expected both duplicates without diversify, got %v", got)
+	}
+	got := ids(t, &SearchFilter{Diversify: true})
+	if len(got) != 2 || got[1] != "other" {
+		t.Errorf("Expected one duplicate plus other with diversify, got %v", got)
+	}
+
+	// 文档级搜索：doc1 内的重复 chunk 排在另一篇文档之后
+	results, err := searcher.SearchDocuments("查询", 10, &SearchFilter{Diversify: true})
+	if err != nil {
+		t.Fatalf("SearchDocuments failed: %v", err)
+	}
+	if len(results) != 2 || results[0].DocID != "doc1" || results[1].DocID != "doc2" {
+		t.Errorf("Expected doc1 then doc2, got %+v", results)
+	}
+}
+
+func TestMMROrder(t *testing.T) {
+	relevance := []float32{0.9, 0.9, 0.5}
+	vectors := [][]float32{{1, 0}, {1, 0}, {0, 1}}
+
+	order := mmrOrder(relevance, vectors, DefaultMMRLambda)
+	if len(order) != 3 || order[0] != 0 || order[1] != 2 || order[2] != 1 {
+		t.Errorf("Expected [0 2 1], got %v", order)
+	}
+	// λ=1 时退化为纯相关性排序
+	if order := mmrOrder(relevance, vectors, 1); order[1] != 1 {
+		t.Errorf("Expected relevance order with lambda 1, got %v", order)
+	}
+}
+
+func TestSearchDocuments_TitleBoost(t *testing.T) {
+	embedder := &recordingEmbedder{}
+	indexer, _ := newTestIndexer(t, embedder)
+	searcher := NewSearcher(indexer.store, embedder, indexer.docRepo)
+
+	blocks := []*BlockVector{
+		{ID: "b1", DocID: "doc1", Content: "正文", BlockType: "paragraph", Embedding: []float32{1, 0.1, 0}},
+		{ID: "doc2_title", DocID: "doc2", Content: "标题", BlockType: titleBlockType, Embedding: []float32{1, 0.2, 0}},
+	}
+	for _, b := range blocks {
+		if err := indexer.store.Upsert(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := searcher.SearchDocuments("标题", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].DocID != "doc2" {
+		t.Fatalf("Expected title match to rank first, got %+v", results)
+	}
+	if results[0].MatchedChunks[0].SourceBlockId != "" {
+		t.Errorf("Expected title chunk to have no source block, got %q", results[0].MatchedChunks[0].SourceBlockId)
+	}
+
+	// 0 关闭标题加分，负数回退到默认值
+	searcher.SetTitleBoost(0)
+	if searcher.titleBoost != 0 {
+		t.Errorf("Expected a zero boost to disable title boosting, got %v", searcher.titleBoost)
+	}
+	searcher.SetTitleBoost(-1)
+	if searcher.titleBoost != DefaultTitleBoost {
+		t.Errorf("Expected a negative boost to fall back to the default, got %v", searcher.titleBoost)
+	}
+}
@@ -0,0 +1,73 @@
+package rag
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultSentenceDelimiters 默认句子分隔符：中英文句号/问号/感叹号、阿拉伯问号、天城文单/双竖线
+const DefaultSentenceDelimiters = "。？！.?!؟।॥"
+
+// sentenceAbbreviations 以英文句点结尾但不表示句末的常见缩写（小写，不含末尾句点）
+// 不收录同时是常用词的缩写（如 no、co、p），否则 "I said no." 这类句末会被误合并
+var sentenceAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true, "st": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "cf": true, "al": true, "approx": true,
+	"fig": true, "vol": true, "pp": true, "inc": true, "ltd": true,
+}
+
+// splitIntoSentences 按句子分割文本，分隔符附在句末
+// 英文句点在数字之间（3.14）、紧跟字母（example.com）或位于常见缩写之后（e.g.）时不分割
+func splitIntoSentences(text, delimiters string) []string {
+	if delimiters == "" {
+		delimiters = DefaultSentenceDelimiters
+	}
+
+	var sentences []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		if !strings.ContainsRune(delimiters, r) || (r == '.' && !isSentenceEndDot(text, i)) {
+			i += width
+			continue
+		}
+
+		// 连续的分隔符（如 "?!"、"..."）归入同一句
+		end := i + width
+		for end < len(text) {
+			next, w := utf8.DecodeRuneInString(text[end:])
+			if !strings.ContainsRune(delimiters, next) {
+				break
+			}
+			end += w
+		}
+		sentences = append(sentences, text[start:end])
+		start, i = end, end
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// isSentenceEndDot 判断 text[i] 处的英文句点是否表示句末
+func isSentenceEndDot(text string, i int) bool {
+	// 紧跟字母或数字：小数、域名、文件名、缩写中间的句点
+	if i+1 < len(text) {
+		if next, _ := utf8.DecodeRuneInString(text[i+1:]); unicode.IsLetter(next) || unicode.IsDigit(next) {
+			return false
+		}
+	}
+
+	// 句点前的单词是常见缩写
+	wordStart := i
+	for wordStart > 0 {
+		prev, w := utf8.DecodeLastRuneInString(text[:wordStart])
+		if !unicode.IsLetter(prev) && prev != '.' {
+			break
+		}
+		wordStart -= w
+	}
+	return !sentenceAbbreviations[strings.ToLower(text[wordStart:i])]
+}
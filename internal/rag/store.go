@@ -7,6 +7,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
 	_ "github.com/mattn/go-sqlite3"
@@ -27,6 +31,7 @@ type BlockVector struct {
 	BlockType      string    // paragraph, heading, list 等
 	HeadingContext string    // 最近的 heading 文本
 	FilePath       string    // 文件路径（仅 file 类型块使用）
+	EmbedModel     string    // 文档级覆盖的嵌入模型（空表示默认模型）
 	Embedding      []float32 // 向量
 }
 
@@ -45,9 +50,23 @@ type SearchResult struct {
 
 // SearchFilter 搜索过滤条件
 type SearchFilter struct {
-	DocID         string // 限定在某篇文档内搜索
-	SourceBlockID string // 限定在某个块（如 FileBlock/FolderBlock）内搜索
-	ExcludeDocID  string // 排除特定文档
+	DocID            string   `json:"docId,omitempty"`            // 限定在某篇文档内搜索
+	SourceBlockID    string   `json:"sourceBlockId,omitempty"`    // 限定在某个块（如 FileBlock/FolderBlock）内搜索
+	ExcludeDocID     string   `json:"excludeDocId,omitempty"`     // 排除特定文档
+	DocIDs           []string `json:"docIds,omitempty"`           // 限定在这些文档内搜索
+	Tags             []string `json:"tags,omitempty"`             // 限定在带有任一标签的文档内搜索（由 Searcher 解析为文档 ID）
+	BlockTypes       []string `json:"blockTypes,omitempty"`       // 限定块类型（如 paragraph、heading、bookmark）
+	ExcludeDocIDs    []string `json:"excludeDocIds,omitempty"`    // 排除这些文档
+	ExcludeBookmarks bool     `json:"excludeBookmarks,omitempty"` // 排除书签网页内容（只搜索笔记和文件）
+	Diversify        bool     `json:"diversify,omitempty"`        // 按 MMR 多样性重排结果，避免近似重复的 chunks 占满前列（由 Searcher 处理）
+
+	embedModel *string // 仅检索使用该嵌入模型的块（nil 不限，空字符串为默认模型；由 Searcher 按模型路由时设置）
+}
+
+// narrowing 过滤条件是否会显著缩小候选集（需要扩大 KNN 召回量）
+func (f *SearchFilter) narrowing() bool {
+	return f != nil && (f.DocID != "" || f.SourceBlockID != "" || len(f.DocIDs) > 0 || len(f.Tags) > 0 || len(f.BlockTypes) > 0 ||
+		(f.embedModel != nil && *f.embedModel != ""))
 }
 
 // ExternalBlockContent 外部块完整内容（bookmark/file 的提取文本）
@@ -60,23 +79,67 @@ type ExternalBlockContent struct {
 	FilePath    string `json:"filePath"`    // 文件路径（仅 file）
 	Title       string `json:"title"`       // 网页标题 / 文件名
 	RawContent  string `json:"content"`     // 完整提取文本
+	Truncated   bool   `json:"truncated"`   // 文本超过提取上限而被截断
+	ContentHash string `json:"contentHash"` // 上次成功索引时提取内容的哈希（内容未变化时跳过重新嵌入）
 	ExtractedAt int64  `json:"extractedAt"` // 提取时间戳
 }
 
+// DistanceMetric vec0 表的距离度量
+type DistanceMetric string
+
+const (
+	// DistanceCosine 余弦距离（1 - cos），更适合文本相似度
+	DistanceCosine DistanceMetric = "cosine"
+	// DistanceL2 欧氏距离（sqlite-vec 默认）
+	DistanceL2 DistanceMetric = "l2"
+)
+
 // VectorStore 向量存储接口
 type VectorStore struct {
-	db        *sql.DB
-	dimension int
+	db          *sql.DB
+	dimension   int
+	metric      DistanceMetric // 向量写入前统一归一化，两种度量都可换算为余弦相似度
+	accelerated bool           // sqlite-vec 扩展可用；不可用时向量存为普通 BLOB 并在内存中暴力检索
+	writeMu     sync.Mutex     // 串行化写操作（并发重建索引时避免 SQLite 写锁冲突）
+	version     atomic.Int64   // 块向量的修改计数，用于使派生结果（如主题聚类）的缓存失效
+}
+
+// probeVecExtension 检测当前连接是否已加载 sqlite-vec 扩展
+var probeVecExtension = func(db *sql.DB) error {
+	var version string
+	return db.QueryRow("SELECT vec_version()").Scan(&version)
+}
+
+// Version 返回块向量的修改计数（每次写入或删除块后递增）
+func (s *VectorStore) Version() int64 {
+	return s.version.Load()
 }
 
 // NewVectorStore 创建向量存储
+// dimension <= 0 时沿用数据库中记录的维度（用于在没有嵌入模型的情况下打开已有索引）
 func NewVectorStore(dbPath string, dimension int) (*VectorStore, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	// sqlite-vec 的写入不是并发安全的，整个进程只使用一个连接
+	// （同时也保证 initSchema 中设置的连接级 PRAGMA 始终生效）
+	db.SetMaxOpenConns(1)
 
-	store := &VectorStore{db: db, dimension: dimension}
+	if dimension <= 0 {
+		dimension = readStoredDimension(db)
+		if dimension <= 0 {
+			_ = db.Close()
+			return nil, fmt.Errorf("no stored dimension in %s", dbPath)
+		}
+	}
+
+	store := &VectorStore{db: db, dimension: dimension, metric: DistanceCosine, accelerated: true}
+	if err := probeVecExtension(db); err != nil {
+		// 扩展加载失败时降级为纯 Go 暴力检索：语义搜索仍可用，只是更慢
+		store.accelerated = false
+		fmt.Printf("⚠️ [RAG] sqlite-vec extension unavailable (%v), accelerated vector search disabled; falling back to brute-force search\n", err)
+	}
 	if err := store.initSchema(); err != nil {
 		_ = db.Close() // 忽略 Close 错误
 		return nil, fmt.Errorf("failed to init schema: %w", err)
@@ -85,6 +148,18 @@ func NewVectorStore(dbPath string, dimension int) (*VectorStore, error) {
 }
 
 func (s *VectorStore) initSchema() error {
+	// 桌面应用与 MCP 服务器会同时打开同一个数据库：
+	// WAL 允许读写并发，busy_timeout 让写锁冲突时等待而不是直接返回 "database is locked"
+	for _, pragma := range []string{
+		"PRAGMA journal_mode=WAL",
+		"PRAGMA busy_timeout=5000",
+		"PRAGMA synchronous=NORMAL",
+	} {
+		if _, err := s.db.Exec(pragma); err != nil {
+			return fmt.Errorf("failed to set %s: %w", pragma, err)
+		}
+	}
+
 	// 创建元数据表
 	_, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS block_vectors (
@@ -133,18 +208,60 @@ func (s *VectorStore) initSchema() error {
 		return err
 	}
 
+	// 创建文档索引状态表（记录每个文档最近一次完成索引的时间，用于检测过期索引）
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS doc_index_state (
+			doc_id TEXT PRIMARY KEY,
+			indexed_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return err
+	}
+
+	// 创建文件夹文件状态表（记录每个已索引文件的大小/修改时间/内容哈希，用于增量重建）
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS folder_file_state (
+			folder_id TEXT NOT NULL,
+			doc_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			file_id TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			mod_time INTEGER NOT NULL,
+			content_hash TEXT NOT NULL,
+			PRIMARY KEY (folder_id, file_path)
+		);
+		CREATE INDEX IF NOT EXISTS idx_ffs_doc_id ON folder_file_state(doc_id);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// 创建搜索反馈表（用户标记的有用/无关结果，连同查询向量用于相似查询的排序调整）
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS search_feedback (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			query TEXT NOT NULL,
+			query_embedding BLOB NOT NULL,
+			block_id TEXT NOT NULL,
+			helpful INTEGER NOT NULL,
+			created_at INTEGER DEFAULT (strftime('%s', 'now'))
+		);
+		CREATE INDEX IF NOT EXISTS idx_feedback_block_id ON search_feedback(block_id);
+	`)
+	if err != nil {
+		return err
+	}
+
 	// 检查已存储的维度是否与当前模型匹配
-	var storedDimStr string
-	row := s.db.QueryRow("SELECT value FROM vec_config WHERE key = 'dimension'")
-	if err := row.Scan(&storedDimStr); err == nil {
-		var storedDim int
-		_, _ = fmt.Sscanf(storedDimStr, "%d", &storedDim)
-		if storedDim > 0 && storedDim != s.dimension {
-			// 维度不匹配，需要重建向量表
-			fmt.Printf("⚠️ [RAG] Dimension mismatch: stored=%d, model=%d. Rebuilding vector index...\n", storedDim, s.dimension)
-			_, _ = s.db.Exec("DROP TABLE IF EXISTS vec_blocks")
-			_, _ = s.db.Exec("DELETE FROM block_vectors") // 清理元数据
-		}
+	if storedDim := readStoredDimension(s.db); storedDim > 0 && storedDim != s.dimension {
+		// 维度不匹配，需要重建向量表（Service 打开前已通过 backupBeforeDimensionChange 备份）
+		fmt.Printf("⚠️ [RAG] Dimension mismatch: stored=%d, model=%d. Rebuilding vector index...\n", storedDim, s.dimension)
+		_, _ = s.db.Exec("DROP TABLE IF EXISTS vec_blocks")
+		_, _ = s.db.Exec("DROP TABLE IF EXISTS vec_graph_nodes")
+		_, _ = s.db.Exec(clearGraphFingerprintSQL)
+		_, _ = s.db.Exec("DELETE FROM block_vectors") // 清理元数据
+		s.version.Add(1)
 	}
 
 	// 添加新列（如果不存在，忽略错误）
@@ -153,15 +270,24 @@ func (s *VectorStore) initSchema() error {
 	_, _ = s.db.Exec(`ALTER TABLE block_vectors ADD COLUMN source_block_id TEXT`)
 	_, _ = s.db.Exec(`ALTER TABLE block_vectors ADD COLUMN file_path TEXT`)
 	_, _ = s.db.Exec(`ALTER TABLE block_vectors ADD COLUMN source_type TEXT`) // document, bookmark, file, folder
+	_, _ = s.db.Exec(`ALTER TABLE block_vectors ADD COLUMN embed_model TEXT`) // 文档级覆盖的嵌入模型
+	_, _ = s.db.Exec(`ALTER TABLE external_block_content ADD COLUMN truncated INTEGER DEFAULT 0`)
+	_, _ = s.db.Exec(`ALTER TABLE external_block_content ADD COLUMN content_hash TEXT`)
+	_, _ = s.db.Exec(`ALTER TABLE folder_file_state ADD COLUMN truncated INTEGER DEFAULT 0`)
 
-	// 创建 sqlite-vec 虚拟表（使用余弦距离，更适合文本相似度）
-	query := fmt.Sprintf(`
-		CREATE VIRTUAL TABLE IF NOT EXISTS vec_blocks USING vec0(
-			id TEXT PRIMARY KEY,
-			embedding FLOAT[%d] distance_metric=cosine
-		);
-	`, s.dimension)
-	_, err = s.db.Exec(query)
+	// 旧版本创建的向量表使用默认 L2 距离且未归一化，迁移为当前度量
+	if err := s.migrateVectorMetric(); err != nil {
+		return fmt.Errorf("failed to migrate vector distance metric: %w", err)
+	}
+
+	// 创建块向量表（sqlite-vec 虚拟表，显式指定距离度量，默认余弦距离）
+	_, err = s.db.Exec(s.vecBlocksSchema("vec_blocks"))
+	if err != nil {
+		return err
+	}
+
+	// 创建图谱节点向量表（每个节点的平均向量，用于近邻方式构建关系图谱）
+	_, err = s.db.Exec(s.vecBlocksSchema("vec_graph_nodes"))
 	if err != nil {
 		return err
 	}
@@ -171,17 +297,178 @@ func (s *VectorStore) initSchema() error {
 	return err
 }
 
+// vecBlocksSchema 块向量表的建表语句
+// sqlite-vec 不可用时使用普通表存储序列化后的向量，读写语句与虚拟表保持一致
+func (s *VectorStore) vecBlocksSchema(table string) string {
+	if !s.accelerated {
+		return fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id TEXT PRIMARY KEY,
+			embedding BLOB NOT NULL
+		);
+	`, table)
+	}
+	return fmt.Sprintf(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS %s USING vec0(
+			id TEXT PRIMARY KEY,
+			embedding FLOAT[%d] distance_metric=%s
+		);
+	`, table, s.dimension, s.metric)
+}
+
+// migrateVectorMetric 将未声明当前距离度量的 vec_blocks 重建为当前度量，并把已有向量归一化
+// 旧表中的向量没有归一化，直接按余弦/单位向量换算相似度会得到负数或大于 1 的分数
+// 降级模式下创建的普通表在扩展恢复可用后也经此迁移回虚拟表
+func (s *VectorStore) migrateVectorMetric() error {
+	var schema string
+	err := s.db.QueryRow("SELECT sql FROM sqlite_master WHERE name = 'vec_blocks'").Scan(&schema)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	schema = strings.ToLower(schema)
+	isVirtual := strings.Contains(schema, "using vec0")
+	if !s.accelerated {
+		if isVirtual {
+			// 没有扩展时无法读取（也无法删除）vec0 虚拟表
+			return fmt.Errorf("vector index was built with sqlite-vec, which is unavailable in this build; delete the index database to rebuild it")
+		}
+		return nil
+	}
+	if isVirtual && strings.Contains(schema, "distance_metric="+string(s.metric)) {
+		return nil
+	}
+
+	fmt.Printf("🔄 [RAG] Migrating vector index to %s distance...\n", s.metric)
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS vec_blocks_migrate",
+		"CREATE TABLE vec_blocks_migrate AS SELECT id, vec_normalize(embedding) AS embedding FROM vec_blocks",
+		"DROP TABLE vec_blocks",
+		s.vecBlocksSchema("vec_blocks"),
+		"INSERT INTO vec_blocks (id, embedding) SELECT id, embedding FROM vec_blocks_migrate",
+		"DROP TABLE vec_blocks_migrate",
+		"DROP TABLE IF EXISTS vec_graph_nodes", // 派生数据，随后按当前表结构重建
+		clearGraphFingerprintSQL,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Accelerated 返回是否使用 sqlite-vec 加速检索（false 表示降级为暴力检索）
+func (s *VectorStore) Accelerated() bool {
+	return s.accelerated
+}
+
+// Dimension 返回向量维度
+func (s *VectorStore) Dimension() int {
+	return s.dimension
+}
+
+// readStoredDimension 读取 vec_config 中记录的维度（表或记录不存在时返回 0）
+func readStoredDimension(db *sql.DB) int {
+	var value string
+	if err := db.QueryRow("SELECT value FROM vec_config WHERE key = 'dimension'").Scan(&value); err != nil {
+		return 0
+	}
+	var dimension int
+	_, _ = fmt.Sscanf(value, "%d", &dimension)
+	return dimension
+}
+
+// StoredDimension 读取已有数据库记录的向量维度（数据库不存在或未记录时返回 0）
+// 不创建数据库文件，也不修改 schema
+func StoredDimension(dbPath string) (int, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer func() { _ = db.Close() }()
+	return readStoredDimension(db), nil
+}
+
+// Metric 返回距离度量
+func (s *VectorStore) Metric() DistanceMetric {
+	return s.metric
+}
+
+// Similarity 将 KNN 距离换算为 [0,1] 的余弦相似度（向量写入和查询前均已归一化）
+func (s *VectorStore) Similarity(distance float32) float32 {
+	var similarity float32
+	switch s.metric {
+	case DistanceL2:
+		// 单位向量间 ||a-b||² = 2 - 2cos
+		similarity = 1 - distance*distance/2
+	default:
+		similarity = 1 - distance
+	}
+	return min(max(similarity, 0), 1)
+}
+
 // Close 关闭数据库连接
+// 关闭前将 WAL 合并回主文件，使备份/恢复只需复制 vectors.db（其他进程仍在读取时可能失败，忽略）
 func (s *VectorStore) Close() error {
+	_, _ = s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
 	return s.db.Close()
 }
 
+// removeDatabaseFiles 删除数据库文件及其 WAL 附属文件
+func removeDatabaseFiles(dbPath string) error {
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Remove(dbPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // HashContent 计算内容的 SHA256 哈希
 func HashContent(content string) string {
 	hash := sha256.Sum256([]byte(content))
 	return hex.EncodeToString(hash[:])[:16] // 只取前 16 字符
 }
 
+// normalizeVector 归一化为单位向量
+func normalizeVector(vec []float32) []float32 {
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	out := make([]float32, len(vec))
+	for i, v := range vec {
+		out[i] = v * scale
+	}
+	return out
+}
+
 // serializeVector 将 float32 切片序列化为字节
 func serializeVector(vec []float32) []byte {
 	buf := make([]byte, len(vec)*4)
@@ -191,23 +478,47 @@ func serializeVector(vec []float32) []byte {
 	return buf
 }
 
+// ========== 元信息（vec_config） ==========
+
+// GetMeta 读取元信息，不存在时返回空字符串
+func (s *VectorStore) GetMeta(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM vec_config WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetMeta 写入元信息
+func (s *VectorStore) SetMeta(key, value string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec("INSERT OR REPLACE INTO vec_config (key, value) VALUES (?, ?)", key, value)
+	return err
+}
+
 // ========== 外部块内容 CRUD ==========
 
 // SaveExternalContent 保存外部块完整内容
 func (s *VectorStore) SaveExternalContent(content *ExternalBlockContent) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	_, err := s.db.Exec(`
 		INSERT OR REPLACE INTO external_block_content
-		(id, doc_id, block_id, block_type, url, file_path, title, raw_content, extracted_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, doc_id, block_id, block_type, url, file_path, title, raw_content, truncated, content_hash, extracted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, content.ID, content.DocID, content.BlockID, content.BlockType,
-		content.URL, content.FilePath, content.Title, content.RawContent, content.ExtractedAt)
+		content.URL, content.FilePath, content.Title, content.RawContent, content.Truncated, content.ContentHash, content.ExtractedAt)
 	return err
 }
 
 // GetExternalContent 获取外部块完整内容
 func (s *VectorStore) GetExternalContent(docID, blockID string) (*ExternalBlockContent, error) {
 	row := s.db.QueryRow(`
-		SELECT id, doc_id, block_id, block_type, url, file_path, title, raw_content, extracted_at
+		SELECT id, doc_id, block_id, block_type, url, file_path, title, raw_content, COALESCE(truncated, 0), COALESCE(content_hash, ''), extracted_at
 		FROM external_block_content
 		WHERE doc_id = ? AND block_id = ?
 	`, docID, blockID)
@@ -216,7 +527,7 @@ func (s *VectorStore) GetExternalContent(docID, blockID string) (*ExternalBlockC
 	var url, filePath, title sql.NullString
 	err := row.Scan(
 		&content.ID, &content.DocID, &content.BlockID, &content.BlockType,
-		&url, &filePath, &title, &content.RawContent, &content.ExtractedAt,
+		&url, &filePath, &title, &content.RawContent, &content.Truncated, &content.ContentHash, &content.ExtractedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -229,6 +540,9 @@ func (s *VectorStore) GetExternalContent(docID, blockID string) (*ExternalBlockC
 
 // DeleteExternalContent 删除外部块内容
 func (s *VectorStore) DeleteExternalContent(docID, blockID string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	_, err := s.db.Exec(`
 		DELETE FROM external_block_content
 		WHERE doc_id = ? AND block_id = ?
@@ -238,6 +552,9 @@ func (s *VectorStore) DeleteExternalContent(docID, blockID string) error {
 
 // DeleteExternalContentByDoc 删除文档的所有外部块内容
 func (s *VectorStore) DeleteExternalContentByDoc(docID string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
 	_, err := s.db.Exec(`
 		DELETE FROM external_block_content
 		WHERE doc_id = ?
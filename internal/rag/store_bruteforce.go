@@ -0,0 +1,107 @@
+package rag
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// sqlite-vec 扩展不可用时的降级检索：向量以 BLOB 存在普通表中，
+// 查询时读出全部候选向量在内存中计算距离，结果与 vec0 的 KNN 查询一致，只是更慢
+
+// vectorDistance 两个单位向量在当前度量下的距离（与 vec0 返回的 distance 含义一致）
+func (s *VectorStore) vectorDistance(a, b []float32) float32 {
+	dot := dotProduct(a, b)
+	if s.metric == DistanceL2 {
+		// 单位向量的欧氏距离：|a-b|² = 2 - 2·cos
+		return float32(math.Sqrt(math.Max(0, 2-2*float64(dot))))
+	}
+	return 1 - dot
+}
+
+// bruteForceSearch 对满足过滤条件的所有块计算距离并取最近的 limit 个
+func (s *VectorStore) bruteForceSearch(unitVec []float32, limit int, conditions []string, args []interface{}) ([]SearchResult, error) {
+	query := `
+		SELECT v.id, v.embedding, b.doc_id, b.content, b.block_type,
+			COALESCE(b.heading_context, ''), COALESCE(b.source_block_id, ''),
+			COALESCE(b.source_type, 'document'), COALESCE(e.title, '')
+		FROM vec_blocks v
+		JOIN block_vectors b ON v.id = b.id
+		LEFT JOIN external_block_content e ON b.doc_id = e.doc_id AND b.source_block_id = e.block_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search query failed: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var vecBytes []byte
+		if err := rows.Scan(&r.BlockID, &vecBytes, &r.DocID, &r.Content, &r.BlockType, &r.HeadingContext, &r.SourceBlockID, &r.SourceType, &r.SourceTitle); err != nil {
+			return nil, err
+		}
+		vec := deserializeVector(vecBytes, s.dimension)
+		if vec == nil {
+			continue // 维度不符的向量无法比较
+		}
+		r.Distance = s.vectorDistance(unitVec, vec)
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if limit >= 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// bruteForceNearest 返回表中与单位向量最相近的 k 个 ID（按距离升序）
+func (s *VectorStore) bruteForceNearest(table string, unitVec []float32, k int) ([]string, error) {
+	rows, err := s.db.Query("SELECT id, embedding FROM " + table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	type candidate struct {
+		id       string
+		distance float32
+	}
+	var candidates []candidate
+	for rows.Next() {
+		var id string
+		var vecBytes []byte
+		if err := rows.Scan(&id, &vecBytes); err != nil {
+			return nil, err
+		}
+		if vec := deserializeVector(vecBytes, s.dimension); vec != nil {
+			candidates = append(candidates, candidate{id: id, distance: s.vectorDistance(unitVec, vec)})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	if len(candidates) > k {
+		candidates = candidates[:k]
+	}
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.id
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package rag
+
+// FolderFileState 文件夹中单个文件的索引状态（用于增量重建）
+type FolderFileState struct {
+	FolderID    string // 文件夹块的基础 ID：{docID}_{blockID}_folder
+	DocID       string
+	FilePath    string
+	FileID      string // 文件块 ID 前缀（chunk ID = FileID 或 FileID_chunk_N）
+	Size        int64
+	ModTime     int64 // Unix 纳秒
+	ContentHash string
+	Truncated   bool // 文本超过提取上限而被截断
+}
+
+// GetFolderFileStates 获取文件夹内所有文件的索引状态（按文件路径索引）
+func (s *VectorStore) GetFolderFileStates(folderID string) (map[string]FolderFileState, error) {
+	rows, err := s.db.Query(`
+		SELECT folder_id, doc_id, file_path, file_id, size, mod_time, content_hash, COALESCE(truncated, 0)
+		FROM folder_file_state WHERE folder_id = ?
+	`, folderID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	states := make(map[string]FolderFileState)
+	for rows.Next() {
+		var st FolderFileState
+		if err := rows.Scan(&st.FolderID, &st.DocID, &st.FilePath, &st.FileID, &st.Size, &st.ModTime, &st.ContentHash, &st.Truncated); err != nil {
+			return nil, err
+		}
+		states[st.FilePath] = st
+	}
+	return states, rows.Err()
+}
+
+// SaveFolderFileState 保存文件索引状态
+func (s *VectorStore) SaveFolderFileState(state *FolderFileState) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec(`
+		INSERT OR REPLACE INTO folder_file_state (folder_id, doc_id, file_path, file_id, size, mod_time, content_hash, truncated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, state.FolderID, state.DocID, state.FilePath, state.FileID, state.Size, state.ModTime, state.ContentHash, state.Truncated)
+	return err
+}
+
+// DeleteFolderFileState 删除单个文件的索引状态
+func (s *VectorStore) DeleteFolderFileState(folderID, filePath string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM folder_file_state WHERE folder_id = ? AND file_path = ?`, folderID, filePath)
+	return err
+}
+
+// DeleteFolderStatesExcept 删除文档中不在 keepFolderIDs 内的文件夹状态
+func (s *VectorStore) DeleteFolderStatesExcept(docID string, keepFolderIDs map[string]bool) error {
+	rows, err := s.db.Query(`SELECT DISTINCT folder_id FROM folder_file_state WHERE doc_id = ?`, docID)
+	if err != nil {
+		return err
+	}
+	var stale []string
+	for rows.Next() {
+		var folderID string
+		if err := rows.Scan(&folderID); err != nil {
+			continue
+		}
+		if !keepFolderIDs[folderID] {
+			stale = append(stale, folderID)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	for _, folderID := range stale {
+		if _, err := s.db.Exec(`DELETE FROM folder_file_state WHERE folder_id = ?`, folderID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package rag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// metaKeyGraphFingerprint vec_config 中记录 vec_graph_nodes 对应的节点向量指纹的键
+const metaKeyGraphFingerprint = "graph_fingerprint"
+
+// clearGraphFingerprintSQL 清空或重建 vec_graph_nodes 时一并删除指纹，下次构建图谱时重新写入
+const clearGraphFingerprintSQL = "DELETE FROM vec_config WHERE key = '" + metaKeyGraphFingerprint + "'"
+
+// SyncGraphNodes 节点向量指纹与上次写入时不同才重写 vec_graph_nodes，返回是否重写
+// 图谱是只读视图，节点向量未变化时（没有文档被重新索引）跳过整表重写
+func (s *VectorStore) SyncGraphNodes(vectors map[string][]float32) (bool, error) {
+	fingerprint := graphFingerprint(vectors)
+	stored, err := s.GetMeta(metaKeyGraphFingerprint)
+	if err != nil {
+		return false, err
+	}
+	if stored == fingerprint {
+		return false, nil
+	}
+	if err := s.replaceGraphNodes(vectors, fingerprint); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// graphFingerprint 按节点 ID 排序后对 ID 和向量计算 SHA-256
+func graphFingerprint(vectors map[string][]float32) string {
+	ids := make([]string, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		h.Write([]byte(id))
+		h.Write([]byte{0})
+		h.Write(serializeVector(vectors[id]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replaceGraphNodes 用新的节点平均向量替换 vec_graph_nodes 中的全部内容，并在同一事务中记录其指纹
+// 节点向量由块向量派生，整体重写（节点数远少于块数）
+func (s *VectorStore) replaceGraphNodes(vectors map[string][]float32, fingerprint string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM vec_graph_nodes"); err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO vec_graph_nodes (id, embedding) VALUES (?, ?)")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for id, vec := range vectors {
+		if len(vec) != s.dimension {
+			continue
+		}
+		if _, err := stmt.Exec(id, serializeVector(normalizeVector(vec))); err != nil {
+			return fmt.Errorf("failed to insert graph node %s: %w", id, err)
+		}
+	}
+	if _, err := tx.Exec("INSERT OR REPLACE INTO vec_config (key, value) VALUES (?, ?)", metaKeyGraphFingerprint, fingerprint); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// NearestGraphNodes 返回与向量最相近的 k 个图谱节点 ID（按距离升序）
+func (s *VectorStore) NearestGraphNodes(vec []float32, k int) ([]string, error) {
+	if !s.accelerated {
+		return s.bruteForceNearest("vec_graph_nodes", normalizeVector(vec), k)
+	}
+	rows, err := s.db.Query(`
+		SELECT id FROM vec_graph_nodes
+		WHERE embedding MATCH ? AND k = ?
+		ORDER BY distance`, serializeVector(normalizeVector(vec)), k)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
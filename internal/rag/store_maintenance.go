@@ -2,6 +2,7 @@ package rag
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -204,6 +205,11 @@ func (s *VectorStore) DeleteOrphanFolders(docID string, keepFolderBlocks []Folde
 		}
 	}
 
+	// 同步清理已删除文件夹块的文件状态，避免同 ID 块重新出现时被误判为未变更
+	if err := s.DeleteFolderStatesExcept(docID, keepPrefixes); err != nil {
+		fmt.Printf("⚠️ [RAG] Failed to delete orphan folder states for doc %s: %v\n", docID, err)
+	}
+
 	if len(toDelete) > 0 {
 		return s.DeleteBlocks(toDelete)
 	}
@@ -212,6 +218,10 @@ func (s *VectorStore) DeleteOrphanFolders(docID string, keepFolderBlocks []Folde
 
 // DeleteNonBookmarkByDocID 删除文档的所有非 bookmark/file/folder 块（保留外部索引块）
 func (s *VectorStore) DeleteNonBookmarkByDocID(docID string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
@@ -247,3 +257,206 @@ func (s *VectorStore) DeleteNonBookmarkByDocID(docID string) error {
 
 	return tx.Commit()
 }
+
+// Compact 清理孤儿向量（vec_blocks 中没有对应 block_vectors 元数据的行，来自失败的写入）并执行 VACUUM 收缩数据库文件
+// 返回删除的孤儿向量数
+func (s *VectorStore) Compact() (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	rows, err := s.db.Query("SELECT id FROM vec_blocks")
+	if err != nil {
+		return 0, err
+	}
+	var vecIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			continue // 跳过扫描失败的行
+		}
+		vecIDs = append(vecIDs, id)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	live := make(map[string]bool)
+	metaRows, err := s.db.Query("SELECT id FROM block_vectors")
+	if err != nil {
+		return 0, err
+	}
+	for metaRows.Next() {
+		var id string
+		if err := metaRows.Scan(&id); err == nil {
+			live[id] = true
+		}
+	}
+	if err := metaRows.Close(); err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range vecIDs {
+		if live[id] {
+			continue
+		}
+		if _, err := s.db.Exec("DELETE FROM vec_blocks WHERE id = ?", id); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+
+	// VACUUM 不能在事务中执行
+	if _, err := s.db.Exec("VACUUM"); err != nil {
+		return removed, err
+	}
+	// WAL 模式下 VACUUM 的结果先写入 -wal 文件，检查点后主文件才会真正缩小
+	if _, err := s.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
+// VerifyReport 向量库一致性检查结果
+type VerifyReport struct {
+	Metadata          int `json:"metadata"`          // block_vectors 行数
+	Vectors           int `json:"vectors"`           // vec_blocks 行数
+	OrphanedMetadata  int `json:"orphanedMetadata"`  // 没有向量的元数据行（搜索永远命中不到）
+	OrphanedVectors   int `json:"orphanedVectors"`   // 没有元数据的向量（搜索结果中出现无内容的命中）
+	DimensionMismatch int `json:"dimensionMismatch"` // 维度与当前向量库不一致的向量
+	Repaired          int `json:"repaired"`          // Repair 删除的行数
+}
+
+// Healthy 是否没有发现不一致
+func (r *VerifyReport) Healthy() bool {
+	return r.OrphanedMetadata == 0 && r.OrphanedVectors == 0 && r.DimensionMismatch == 0
+}
+
+// storeInconsistencies 不一致的行 ID
+type storeInconsistencies struct {
+	orphanedMetadata  []string
+	orphanedVectors   []string
+	dimensionMismatch []string
+}
+
+// Verify 检查 block_vectors 与 vec_blocks 是否一致（Upsert/DeleteBlocks 分两张表写入，崩溃可能留下半条记录）
+func (s *VectorStore) Verify() (VerifyReport, error) {
+	var report VerifyReport
+	found, err := s.findInconsistencies(&report)
+	if err != nil {
+		return report, err
+	}
+	report.OrphanedMetadata = len(found.orphanedMetadata)
+	report.OrphanedVectors = len(found.orphanedVectors)
+	report.DimensionMismatch = len(found.dimensionMismatch)
+	return report, nil
+}
+
+// Repair 删除悬空的元数据行、孤儿向量和维度不一致的向量，返回修复前的检查结果
+// 被删除的元数据对应的文档需要重新索引才能恢复
+func (s *VectorStore) Repair() (VerifyReport, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
+	var report VerifyReport
+	found, err := s.findInconsistencies(&report)
+	if err != nil {
+		return report, err
+	}
+	report.OrphanedMetadata = len(found.orphanedMetadata)
+	report.OrphanedVectors = len(found.orphanedVectors)
+	report.DimensionMismatch = len(found.dimensionMismatch)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return report, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, id := range found.orphanedMetadata {
+		if _, err := tx.Exec("DELETE FROM block_vectors WHERE id = ?", id); err != nil {
+			return report, err
+		}
+	}
+	for _, id := range append(found.orphanedVectors, found.dimensionMismatch...) {
+		if _, err := tx.Exec("DELETE FROM vec_blocks WHERE id = ?", id); err != nil {
+			return report, err
+		}
+	}
+	// 维度不一致的向量删除后，其元数据也成了悬空行
+	for _, id := range found.dimensionMismatch {
+		if _, err := tx.Exec("DELETE FROM block_vectors WHERE id = ?", id); err != nil {
+			return report, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return report, err
+	}
+
+	report.Repaired = len(found.orphanedMetadata) + len(found.orphanedVectors) + len(found.dimensionMismatch)
+	return report, nil
+}
+
+// findInconsistencies 对比两张表的 ID 并检查向量长度
+// vec0 虚拟表在写入时即拒绝维度不符的向量，只有降级模式（普通表存储 BLOB）需要检查长度
+func (s *VectorStore) findInconsistencies(report *VerifyReport) (*storeInconsistencies, error) {
+	vectors := make(map[string]bool)
+	mismatched := make(map[string]bool)
+	found := &storeInconsistencies{}
+
+	query := "SELECT id, 0 FROM vec_blocks"
+	if !s.accelerated {
+		query = "SELECT id, length(embedding) / 4 FROM vec_blocks" // float32 序列化的 BLOB
+	}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id string
+		var length int
+		if err := rows.Scan(&id, &length); err != nil {
+			return nil, fmt.Errorf("failed to scan vector row: %w", err)
+		}
+		vectors[id] = true
+		if !s.accelerated && length != s.dimension {
+			mismatched[id] = true
+			found.dimensionMismatch = append(found.dimensionMismatch, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.Vectors = len(vectors)
+
+	metadata := make(map[string]bool)
+	metaRows, err := s.db.Query("SELECT id FROM block_vectors")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = metaRows.Close() }()
+	for metaRows.Next() {
+		var id string
+		if err := metaRows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan metadata row: %w", err)
+		}
+		metadata[id] = true
+		if !vectors[id] {
+			found.orphanedMetadata = append(found.orphanedMetadata, id)
+		}
+	}
+	if err := metaRows.Err(); err != nil {
+		return nil, err
+	}
+	report.Metadata = len(metadata)
+
+	for id := range vectors {
+		if !metadata[id] && !mismatched[id] {
+			found.orphanedVectors = append(found.orphanedVectors, id)
+		}
+	}
+	sort.Strings(found.orphanedVectors)
+	return found, nil
+}
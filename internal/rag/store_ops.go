@@ -2,33 +2,65 @@ package rag
 
 import (
 	"database/sql"
+	"fmt"
 	"unsafe"
 )
 
 // Upsert 插入或更新块向量
 func (s *VectorStore) Upsert(block *BlockVector) error {
+	return s.UpsertBatch([]*BlockVector{block})
+}
+
+// UpsertBatch 在一个事务中插入或更新多个块向量（任一块失败则整批回滚）
+func (s *VectorStore) UpsertBatch(blocks []*BlockVector) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
 	}
 	defer func() { _ = tx.Rollback() }()
 
-	// 更新元数据（包含 content_hash, heading_context, source_block_id, file_path 和 source_type）
-	_, err = tx.Exec(`
-		INSERT OR REPLACE INTO block_vectors (id, doc_id, content, content_hash, block_type, heading_context, source_block_id, file_path, source_type)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, block.ID, block.DocID, block.Content, block.ContentHash, block.BlockType, block.HeadingContext, block.SourceBlockID, block.FilePath, block.SourceType)
+	// 预编译语句，批量写入时只解析一次
+	metaStmt, err := tx.Prepare(`
+		INSERT OR REPLACE INTO block_vectors (id, doc_id, content, content_hash, block_type, heading_context, source_block_id, file_path, source_type, embed_model)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
 	if err != nil {
 		return err
 	}
-
-	// 更新向量（sqlite-vec 虚拟表不支持 INSERT OR REPLACE，需要先删除再插入）
-	vecBytes := serializeVector(block.Embedding)
-	_, _ = tx.Exec(`DELETE FROM vec_blocks WHERE id = ?`, block.ID)
-	_, err = tx.Exec(`INSERT INTO vec_blocks (id, embedding) VALUES (?, ?)`, block.ID, vecBytes)
+	defer func() { _ = metaStmt.Close() }()
+	deleteVecStmt, err := tx.Prepare(`DELETE FROM vec_blocks WHERE id = ?`)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = deleteVecStmt.Close() }()
+	insertVecStmt, err := tx.Prepare(`INSERT INTO vec_blocks (id, embedding) VALUES (?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = insertVecStmt.Close() }()
+
+	for _, block := range blocks {
+		// 更新元数据（包含 content_hash, heading_context, source_block_id, file_path, source_type 和 embed_model）
+		if _, err := metaStmt.Exec(block.ID, block.DocID, block.Content, block.ContentHash, block.BlockType, block.HeadingContext, block.SourceBlockID, block.FilePath, block.SourceType, block.EmbedModel); err != nil {
+			return err
+		}
+
+		// 更新向量（sqlite-vec 虚拟表不支持 INSERT OR REPLACE，需要先删除再插入）
+		// 统一归一化为单位向量，使距离可换算为真实的余弦相似度
+		vecBytes := serializeVector(normalizeVector(block.Embedding))
+		_, _ = deleteVecStmt.Exec(block.ID)
+		if _, err := insertVecStmt.Exec(block.ID, vecBytes); err != nil {
+			return fmt.Errorf("failed to insert vector for block %s: %w", block.ID, err)
+		}
+	}
 
 	return tx.Commit()
 }
@@ -57,6 +89,10 @@ func (s *VectorStore) GetBlockHashes(docID string) (map[string]string, error) {
 
 // DeleteBlocks 删除指定的块
 func (s *VectorStore) DeleteBlocks(ids []string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
 	if len(ids) == 0 {
 		return nil
 	}
@@ -76,6 +112,10 @@ func (s *VectorStore) DeleteBlocks(ids []string) error {
 
 // DeleteBlocksByPrefix 删除指定前缀的所有块
 func (s *VectorStore) DeleteBlocksByPrefix(prefix string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
@@ -111,6 +151,10 @@ func (s *VectorStore) DeleteBlocksByPrefix(prefix string) error {
 
 // DeleteByDocID 删除文档的所有块向量
 func (s *VectorStore) DeleteByDocID(docID string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
 	tx, err := s.db.Begin()
 	if err != nil {
 		return err
@@ -140,10 +184,69 @@ func (s *VectorStore) DeleteByDocID(docID string) error {
 		_, _ = tx.Exec("DELETE FROM vec_blocks WHERE id = ?", id)
 		_, _ = tx.Exec("DELETE FROM block_vectors WHERE id = ?", id)
 	}
+	_, _ = tx.Exec("DELETE FROM doc_index_state WHERE doc_id = ?", docID)
+	_, _ = tx.Exec("DELETE FROM folder_file_state WHERE doc_id = ?", docID)
 
 	return tx.Commit()
 }
 
+// ClearVectors 清空所有块向量、索引状态和搜索反馈（保留外部块的提取文本）
+func (s *VectorStore) ClearVectors() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	defer s.version.Add(1)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range []string{
+		"DELETE FROM vec_blocks",
+		"DELETE FROM vec_graph_nodes",
+		clearGraphFingerprintSQL,
+		"DELETE FROM block_vectors",
+		"DELETE FROM doc_index_state",
+		"DELETE FROM search_feedback", // 查询向量随模型失效
+		"DELETE FROM folder_file_state",
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// MarkDocIndexed 记录文档完成索引的时间（Unix 毫秒，与文档 UpdatedAt 对齐）
+func (s *VectorStore) MarkDocIndexed(docID string, indexedAt int64) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO doc_index_state (doc_id, indexed_at) VALUES (?, ?)`, docID, indexedAt)
+	return err
+}
+
+// GetDocIndexTimes 获取所有文档最近一次完成索引的时间（Unix 毫秒）
+func (s *VectorStore) GetDocIndexTimes() (map[string]int64, error) {
+	rows, err := s.db.Query(`SELECT doc_id, indexed_at FROM doc_index_state`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	times := make(map[string]int64)
+	for rows.Next() {
+		var docID string
+		var indexedAt int64
+		if err := rows.Scan(&docID, &indexedAt); err != nil {
+			continue
+		}
+		times[docID] = indexedAt
+	}
+	return times, rows.Err()
+}
+
 // GetAllDocIDs 获取所有已索引的文档 ID
 func (s *VectorStore) GetAllDocIDs() ([]string, error) {
 	rows, err := s.db.Query(`SELECT DISTINCT doc_id FROM block_vectors`)
@@ -200,6 +303,29 @@ func (s *VectorStore) GetDocumentVectors(docID string) ([][]float32, error) {
 	return vectors, nil
 }
 
+// GetAllBlockMeta 获取所有块的元数据（不含向量）
+func (s *VectorStore) GetAllBlockMeta() ([]BlockVector, error) {
+	rows, err := s.db.Query(`
+		SELECT id, doc_id, content, COALESCE(block_type, ''),
+			COALESCE(heading_context, ''), COALESCE(source_block_id, ''), COALESCE(source_type, '')
+		FROM block_vectors ORDER BY doc_id, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var blocks []BlockVector
+	for rows.Next() {
+		var b BlockVector
+		if err := rows.Scan(&b.ID, &b.DocID, &b.Content, &b.BlockType, &b.HeadingContext, &b.SourceBlockID, &b.SourceType); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, rows.Err()
+}
+
 // getVectorByID 根据 ID 获取向量（从 vec_blocks 虚拟表）
 func (s *VectorStore) getVectorByID(id string) ([]float32, error) {
 	row := s.db.QueryRow(`SELECT embedding FROM vec_blocks WHERE id = ?`, id)
@@ -213,6 +339,31 @@ func (s *VectorStore) getVectorByID(id string) ([]float32, error) {
 	return deserializeVector(vecBytes, s.dimension), nil
 }
 
+// GetVectorsByIDs 批量获取指定块的向量，缺失的块不出现在结果中
+func (s *VectorStore) GetVectorsByIDs(ids []string) (map[string][]float32, error) {
+	vectors := make(map[string][]float32, len(ids))
+	if len(ids) == 0 {
+		return vectors, nil
+	}
+
+	rows, err := s.db.Query(`SELECT id, embedding FROM vec_blocks WHERE id IN (`+placeholders(len(ids))+`)`,
+		appendStrings(nil, ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id string
+		var vecBytes []byte
+		if err := rows.Scan(&id, &vecBytes); err != nil {
+			return nil, err
+		}
+		vectors[id] = deserializeVector(vecBytes, s.dimension)
+	}
+	return vectors, rows.Err()
+}
+
 // deserializeVector 将字节反序列化为 float32 切片
 func deserializeVector(buf []byte, dimension int) []float32 {
 	if len(buf) != dimension*4 {
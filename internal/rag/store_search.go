@@ -5,30 +5,55 @@ import (
 	"strings"
 )
 
+// narrowFilterMultiplier 过滤条件较窄时 KNN 召回量的放大倍数
+const narrowFilterMultiplier = 10
+
+// maxFilteredKNN 过滤搜索时 KNN 召回量上限
+const maxFilteredKNN = 2000
+
 // Search 向量相似度搜索（支持过滤条件）
 func (s *VectorStore) Search(queryVec []float32, limit int, filter *SearchFilter) ([]SearchResult, error) {
-	vecBytes := serializeVector(queryVec)
+	return s.SearchPage(queryVec, limit, 0, filter)
+}
 
-	// 构建动态 WHERE 条件
-	var conditions []string
-	var args []interface{}
-	args = append(args, vecBytes, limit)
+// SearchPage 分页的向量相似度搜索，跳过前 offset 条结果
+// vec0 的 KNN 查询不支持原生分页，这里取前 offset+limit 个近邻后在内存中切片
+func (s *VectorStore) SearchPage(queryVec []float32, limit, offset int, filter *SearchFilter) ([]SearchResult, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	results, err := s.searchTopK(queryVec, offset+limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= len(results) {
+		return []SearchResult{}, nil
+	}
+	return results[offset:], nil
+}
 
-	if filter != nil {
-		if filter.DocID != "" {
-			conditions = append(conditions, "b.doc_id = ?")
-			args = append(args, filter.DocID)
-		}
-		if filter.SourceBlockID != "" {
-			conditions = append(conditions, "b.source_block_id = ?")
-			args = append(args, filter.SourceBlockID)
+// searchTopK 取与查询向量最相近的 limit 个块
+func (s *VectorStore) searchTopK(queryVec []float32, limit int, filter *SearchFilter) ([]SearchResult, error) {
+	unitVec := normalizeVector(queryVec)
+	conditions, filterArgs := searchConditions(filter)
+	if !s.accelerated {
+		return s.bruteForceSearch(unitVec, limit, conditions, filterArgs)
+	}
+
+	// KNN 先取 k 个近邻再应用过滤，过滤条件较窄时扩大 k 以免结果被过滤殆尽
+	k := limit
+	if filter.narrowing() {
+		k = limit * narrowFilterMultiplier
+		if k > maxFilteredKNN {
+			k = maxFilteredKNN
 		}
-		if filter.ExcludeDocID != "" {
-			conditions = append(conditions, "b.doc_id != ?")
-			args = append(args, filter.ExcludeDocID)
+		if k < limit {
+			k = limit
 		}
 	}
 
+	args := append([]interface{}{serializeVector(unitVec), k}, filterArgs...)
+
 	// 构建 SQL 查询
 	query := `
 		SELECT v.id, v.distance, b.doc_id, b.content, b.block_type,
@@ -43,7 +68,8 @@ func (s *VectorStore) Search(queryVec []float32, limit int, filter *SearchFilter
 		query += " AND " + strings.Join(conditions, " AND ")
 	}
 
-	query += " ORDER BY v.distance"
+	query += " ORDER BY v.distance LIMIT ?"
+	args = append(args, limit)
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -61,3 +87,57 @@ func (s *VectorStore) Search(queryVec []float32, limit int, filter *SearchFilter
 	}
 	return results, nil
 }
+
+// searchConditions 将过滤条件转换为 WHERE 子句片段及其参数（b 为 block_vectors 别名）
+func searchConditions(filter *SearchFilter) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if filter == nil {
+		return conditions, args
+	}
+	if filter.DocID != "" {
+		conditions = append(conditions, "b.doc_id = ?")
+		args = append(args, filter.DocID)
+	}
+	if filter.SourceBlockID != "" {
+		conditions = append(conditions, "b.source_block_id = ?")
+		args = append(args, filter.SourceBlockID)
+	}
+	if filter.ExcludeDocID != "" {
+		conditions = append(conditions, "b.doc_id != ?")
+		args = append(args, filter.ExcludeDocID)
+	}
+	if len(filter.DocIDs) > 0 {
+		conditions = append(conditions, "b.doc_id IN ("+placeholders(len(filter.DocIDs))+")")
+		args = appendStrings(args, filter.DocIDs)
+	}
+	if len(filter.BlockTypes) > 0 {
+		conditions = append(conditions, "b.block_type IN ("+placeholders(len(filter.BlockTypes))+")")
+		args = appendStrings(args, filter.BlockTypes)
+	}
+	if len(filter.ExcludeDocIDs) > 0 {
+		conditions = append(conditions, "b.doc_id NOT IN ("+placeholders(len(filter.ExcludeDocIDs))+")")
+		args = appendStrings(args, filter.ExcludeDocIDs)
+	}
+	if filter.ExcludeBookmarks {
+		conditions = append(conditions, "COALESCE(b.source_type, 'document') != 'bookmark'")
+	}
+	if filter.embedModel != nil {
+		conditions = append(conditions, "COALESCE(b.embed_model, '') = ?")
+		args = append(args, *filter.embedModel)
+	}
+	return conditions, args
+}
+
+// placeholders 生成 n 个以逗号分隔的 SQL 占位符
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// appendStrings 将字符串切片追加为 SQL 参数
+func appendStrings(args []interface{}, values []string) []interface{} {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
@@ -12,6 +12,49 @@ func (s *VectorStore) GetIndexedDocCount() (int, error) {
 	return count, nil
 }
 
+// CountBlocks 获取已索引的块总数（docID 为空时统计全部文档）
+func (s *VectorStore) CountBlocks(docID string) (int, error) {
+	var count int
+	var err error
+	if docID == "" {
+		err = s.db.QueryRow(`SELECT COUNT(*) FROM block_vectors`).Scan(&count)
+	} else {
+		err = s.db.QueryRow(`SELECT COUNT(*) FROM block_vectors WHERE doc_id = ?`, docID).Scan(&count)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountBlocksByPrefix 获取 ID 以 prefix 开头的块数量（如某个 bookmark/file 块的全部 chunks）
+func (s *VectorStore) CountBlocksByPrefix(prefix string) (int, error) {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM block_vectors WHERE id LIKE ?`, prefix+"%").Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// EmbedModels 获取索引中使用的覆盖嵌入模型（不含默认模型）
+func (s *VectorStore) EmbedModels() ([]string, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT embed_model FROM block_vectors WHERE COALESCE(embed_model, '') != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var models []string
+	for rows.Next() {
+		var model string
+		if err := rows.Scan(&model); err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+	return models, rows.Err()
+}
+
 // GetIndexedStats 获取索引统计信息 (文档数, 书签数, 嵌入文件数, 文件夹数)
 func (s *VectorStore) GetIndexedStats() (int, int, int, int, error) {
 	// Count unique docs that have non-bookmark, non-file, and non-folder blocks
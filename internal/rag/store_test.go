@@ -0,0 +1,517 @@
+package rag
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNewVectorStore_StoredDimension(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "vectors.db")
+
+	// 数据库不存在时返回 0，且不创建文件
+	if dim, err := StoredDimension(dbPath); err != nil || dim != 0 {
+		t.Fatalf("Expected 0 for missing database, got %d (%v)", dim, err)
+	}
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatal("StoredDimension should not create the database")
+	}
+	if _, err := NewVectorStore(dbPath, 0); err == nil {
+		t.Fatal("Expected error opening new database without dimension")
+	}
+	_ = os.Remove(dbPath)
+
+	store, err := NewVectorStore(dbPath, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upsert(&BlockVector{ID: "b1", DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Close()
+
+	if dim, err := StoredDimension(dbPath); err != nil || dim != 4 {
+		t.Fatalf("Expected stored dimension 4, got %d (%v)", dim, err)
+	}
+
+	// 不传维度时沿用已记录的维度，已有向量保持可用
+	reopened, err := NewVectorStore(dbPath, 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen with stored dimension: %v", err)
+	}
+	defer func() { _ = reopened.Close() }()
+	if reopened.Dimension() != 4 {
+		t.Errorf("Expected dimension 4, got %d", reopened.Dimension())
+	}
+	if count, err := reopened.GetIndexedDocCount(); err != nil || count != 1 {
+		t.Errorf("Expected 1 indexed doc, got %d (%v)", count, err)
+	}
+}
+
+func TestCompact_RemovesOrphanVectors(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "vectors.db")
+	store, err := NewVectorStore(dbPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	for _, id := range []string{"b1", "b2"} {
+		if err := store.Upsert(&BlockVector{ID: id, DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 模拟失败写入留下的孤儿向量
+	if _, err := store.db.Exec("DELETE FROM block_vectors WHERE id = 'b2'"); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := store.Compact()
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 orphan removed, got %d", removed)
+	}
+	var count int
+	if err := store.db.QueryRow("SELECT COUNT(*) FROM vec_blocks").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 remaining vector, got %d", count)
+	}
+	// VACUUM 的结果应已检查点写回主文件
+	if size := fileSize(dbPath + "-wal"); size != 0 {
+		t.Errorf("Expected an empty WAL after compaction, got %d bytes", size)
+	}
+}
+
+func TestVerifyAndRepair_Desync(t *testing.T) {
+	store, err := NewVectorStore(filepath.Join(t.TempDir(), "vectors.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	for _, id := range []string{"b1", "b2", "b3"} {
+		if err := store.Upsert(&BlockVector{ID: id, DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 模拟两表写入之间崩溃：b2 只剩向量，b3 只剩元数据
+	if _, err := store.db.Exec("DELETE FROM block_vectors WHERE id = 'b2'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec("DELETE FROM vec_blocks WHERE id = 'b3'"); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := store.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.OrphanedVectors != 1 || report.OrphanedMetadata != 1 || report.DimensionMismatch != 0 || report.Healthy() {
+		t.Errorf("Unexpected verify report: %+v", report)
+	}
+
+	repaired, err := store.Repair()
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if repaired.Repaired != 2 {
+		t.Errorf("Expected 2 rows repaired, got %d", repaired.Repaired)
+	}
+
+	after, err := store.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.Healthy() || after.Metadata != 1 || after.Vectors != 1 {
+		t.Errorf("Expected healthy store with 1 block after repair, got %+v", after)
+	}
+}
+
+func TestVectorStore_ConcurrentStoresSameFile(t *testing.T) {
+	// 模拟桌面应用与 MCP 服务器同时写入同一个向量库
+	dbPath := filepath.Join(t.TempDir(), "vectors.db")
+	app, err := NewVectorStore(dbPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = app.Close() }()
+	mcp, err := NewVectorStore(dbPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = mcp.Close() }()
+
+	var mode string
+	if err := app.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("Expected WAL journal mode, got %q (%v)", mode, err)
+	}
+
+	const perStore = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perStore)
+	for name, store := range map[string]*VectorStore{"app": app, "mcp": mcp} {
+		wg.Add(1)
+		go func(name string, store *VectorStore) {
+			defer wg.Done()
+			for i := 0; i < perStore; i++ {
+				id := fmt.Sprintf("%s-%d", name, i)
+				if err := store.Upsert(&BlockVector{ID: id, DocID: name, Content: id, BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+					errs <- err
+				}
+			}
+		}(name, store)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Interleaved upsert failed: %v", err)
+	}
+
+	report, err := app.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Metadata != 2*perStore || !report.Healthy() {
+		t.Errorf("Expected %d consistent blocks, got %+v", 2*perStore, report)
+	}
+}
+
+func TestVectorStore_CosineSimilarity(t *testing.T) {
+	// 写入时归一化，相同方向的向量相似度约为 1，正交向量约为 0，与向量长度无关
+	store, err := NewVectorStore(filepath.Join(t.TempDir(), "vectors.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if store.Metric() != DistanceCosine {
+		t.Fatalf("Expected cosine metric, got %s", store.Metric())
+	}
+
+	blocks := map[string][]float32{
+		"same":       {3, 0, 0},
+		"orthogonal": {0, 5, 0},
+	}
+	for id, vec := range blocks {
+		if err := store.Upsert(&BlockVector{ID: id, DocID: "doc1", Content: id, BlockType: "paragraph", Embedding: vec}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := store.Search([]float32{0.5, 0, 0}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[string]float32)
+	for _, r := range results {
+		scores[r.BlockID] = store.Similarity(r.Distance)
+	}
+	if math.Abs(float64(scores["same"]-1)) > 1e-4 {
+		t.Errorf("Expected identical vectors to score ~1.0, got %v", scores["same"])
+	}
+	if math.Abs(float64(scores["orthogonal"])) > 1e-4 {
+		t.Errorf("Expected orthogonal vectors to score ~0.0, got %v", scores["orthogonal"])
+	}
+
+	// 存储的是单位向量
+	stored, err := store.getVectorByID("same")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(float64(stored[0]-1)) > 1e-6 {
+		t.Errorf("Expected normalized stored vector, got %v", stored)
+	}
+}
+
+func TestVectorStore_MigratesLegacyL2Table(t *testing.T) {
+	// 旧版本的向量表没有声明距离度量（L2）且存储未归一化的向量
+	dbPath := filepath.Join(t.TempDir(), "vectors.db")
+	legacy, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE VIRTUAL TABLE vec_blocks USING vec0(id TEXT PRIMARY KEY, embedding FLOAT[3])",
+		"CREATE TABLE vec_config (key TEXT PRIMARY KEY, value TEXT)",
+		"INSERT INTO vec_config (key, value) VALUES ('dimension', '3')",
+	} {
+		if _, err := legacy.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for id, vec := range map[string][]float32{"duplicate": {4, 0, 0}, "orthogonal": {0, 0, 7}} {
+		if _, err := legacy.Exec("INSERT INTO vec_blocks (id, embedding) VALUES (?, ?)", id, serializeVector(vec)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = legacy.Close()
+
+	store, err := NewVectorStore(dbPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	for _, id := range []string{"duplicate", "orthogonal"} {
+		if _, err := store.db.Exec("INSERT INTO block_vectors (id, doc_id, content, block_type) VALUES (?, 'doc1', ?, 'paragraph')", id, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := store.Search([]float32{2, 0, 0}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected migrated vectors to be searchable, got %+v", results)
+	}
+	scores := make(map[string]float32)
+	for _, r := range results {
+		scores[r.BlockID] = store.Similarity(r.Distance)
+	}
+	if math.Abs(float64(scores["duplicate"]-1)) > 1e-4 {
+		t.Errorf("Expected duplicate chunk to score ~1.0, got %v", scores["duplicate"])
+	}
+	if math.Abs(float64(scores["orthogonal"])) > 1e-4 {
+		t.Errorf("Expected orthogonal chunk to score ~0.0, got %v", scores["orthogonal"])
+	}
+}
+
+func TestVerify_DimensionMismatchInFallbackMode(t *testing.T) {
+	// 降级模式下向量存为普通 BLOB，写入时不校验维度
+	probe := probeVecExtension
+	probeVecExtension = func(*sql.DB) error { return errors.New("no such function: vec_version") }
+	defer func() { probeVecExtension = probe }()
+
+	store, err := NewVectorStore(filepath.Join(t.TempDir(), "vectors.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	for _, id := range []string{"b1", "b2"} {
+		if err := store.Upsert(&BlockVector{ID: id, DocID: "doc1", Content: "内容", BlockType: "paragraph", Embedding: []float32{1, 0, 0}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.db.Exec("UPDATE vec_blocks SET embedding = ? WHERE id = 'b2'", serializeVector([]float32{1, 0})); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := store.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if report.DimensionMismatch != 1 || report.OrphanedVectors != 0 || report.OrphanedMetadata != 0 {
+		t.Errorf("Unexpected verify report: %+v", report)
+	}
+}
+
+func TestVectorStore_BruteForceFallback(t *testing.T) {
+	// 模拟 sqlite-vec 加载失败：向量存入普通表，搜索在内存中完成
+	probe := probeVecExtension
+	probeVecExtension = func(*sql.DB) error { return errors.New("no such function: vec_version") }
+	defer func() { probeVecExtension = probe }()
+
+	dbPath := filepath.Join(t.TempDir(), "vectors.db")
+	store, err := NewVectorStore(dbPath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if store.Accelerated() {
+		t.Fatal("Expected fallback store to report accelerated=false")
+	}
+
+	blocks := map[string][]float32{
+		"same":       {3, 0, 0},
+		"near":       {3, 1, 0},
+		"orthogonal": {0, 5, 0},
+	}
+	for id, vec := range blocks {
+		if err := store.Upsert(&BlockVector{ID: id, DocID: "doc-" + id, Content: id, BlockType: "paragraph", Embedding: vec}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := store.Search([]float32{0.5, 0, 0}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].BlockID != "same" || results[1].BlockID != "near" {
+		t.Fatalf("Expected [same near], got %+v", results)
+	}
+	if got := store.Similarity(results[0].Distance); math.Abs(float64(got-1)) > 1e-4 {
+		t.Errorf("Expected identical vectors to score ~1.0, got %v", got)
+	}
+
+	filtered, err := store.Search([]float32{0.5, 0, 0}, 5, &SearchFilter{ExcludeDocID: "doc-same"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 2 || filtered[0].BlockID != "near" {
+		t.Fatalf("Expected filter to apply in fallback search, got %+v", filtered)
+	}
+
+	report, err := store.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Healthy() || report.Vectors != 3 {
+		t.Errorf("Expected consistent fallback store, got %+v", report)
+	}
+}
+
+func TestVectorStore_SimilarityL2(t *testing.T) {
+	store := &VectorStore{metric: DistanceL2}
+	// 单位向量：相同距离 0，正交距离 √2，相反距离 2
+	for _, tc := range []struct {
+		distance float32
+		want     float32
+	}{{0, 1}, {float32(math.Sqrt2), 0}, {2, 0}} {
+		if got := store.Similarity(tc.distance); math.Abs(float64(got-tc.want)) > 1e-4 {
+			t.Errorf("Similarity(%v) = %v, want %v", tc.distance, got, tc.want)
+		}
+	}
+}
+
+// testBlockVectors 生成 n 个测试块
+func testBlockVectors(n int) []*BlockVector {
+	blocks := make([]*BlockVector, n)
+	for i := range blocks {
+		content := fmt.Sprintf("块内容 %d", i)
+		blocks[i] = &BlockVector{
+			ID:             fmt.Sprintf("doc1_b%d", i),
+			SourceBlockID:  fmt.Sprintf("b%d", i),
+			SourceType:     "document",
+			DocID:          "doc1",
+			Content:        content,
+			ContentHash:    HashContent(content),
+			BlockType:      "paragraph",
+			HeadingContext: "标题",
+			Embedding:      []float32{float32(i + 1), 1, 0},
+		}
+	}
+	return blocks
+}
+
+// dumpStore 导出块元数据和向量（不含更新时间），用于比较两个存储的内容
+func dumpStore(t *testing.T, store *VectorStore) []string {
+	t.Helper()
+	rows, err := store.db.Query(`
+		SELECT id, doc_id, content, content_hash, block_type, heading_context, source_block_id, COALESCE(file_path, ''), source_type
+		FROM block_vectors ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids, dump []string
+	for rows.Next() {
+		var cols [9]string
+		if err := rows.Scan(&cols[0], &cols[1], &cols[2], &cols[3], &cols[4], &cols[5], &cols[6], &cols[7], &cols[8]); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, cols[0])
+		dump = append(dump, fmt.Sprint(cols))
+	}
+	_ = rows.Close()
+
+	vectors, err := store.GetVectorsByIDs(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		dump = append(dump, fmt.Sprint(id, vectors[id]))
+	}
+	return dump
+}
+
+func TestUpsertBatch_MatchesIndividualUpserts(t *testing.T) {
+	dir := t.TempDir()
+	single, err := NewVectorStore(filepath.Join(dir, "single.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = single.Close() }()
+	batch, err := NewVectorStore(filepath.Join(dir, "batch.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = batch.Close() }()
+
+	// 写两轮，第二轮覆盖已有块，验证 vec0 先删后插的行为一致
+	for round := 0; round < 2; round++ {
+		blocks := testBlockVectors(50)
+		for _, block := range blocks {
+			block.Content += fmt.Sprintf(" r%d", round)
+			if err := single.Upsert(block); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := batch.UpsertBatch(blocks); err != nil {
+			t.Fatalf("UpsertBatch failed: %v", err)
+		}
+	}
+
+	got, want := dumpStore(t, batch), dumpStore(t, single)
+	if len(got) != 100 {
+		t.Fatalf("Expected 50 blocks and 50 vectors, got %d rows", len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Row %d differs:\nbatch  %s\nsingle %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestUpsertBatch_RollsBackOnError(t *testing.T) {
+	store, err := NewVectorStore(filepath.Join(t.TempDir(), "vectors.db"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	blocks := testBlockVectors(3)
+	blocks[2].Embedding = []float32{1, 0} // 维度不匹配
+	if err := store.UpsertBatch(blocks); err == nil {
+		t.Fatal("Expected error for dimension mismatch")
+	}
+	if report, err := store.Verify(); err != nil || report.Metadata != 0 || report.Vectors != 0 {
+		t.Errorf("Expected batch to be rolled back, got %+v (%v)", report, err)
+	}
+}
+
+func benchmarkUpsert(b *testing.B, batched bool) {
+	store, err := NewVectorStore(filepath.Join(b.TempDir(), "vectors.db"), 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	blocks := testBlockVectors(300)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batched {
+			if err := store.UpsertBatch(blocks); err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+		for _, block := range blocks {
+			if err := store.Upsert(block); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkUpsert_Individual300(b *testing.B) { benchmarkUpsert(b, false) }
+
+func BenchmarkUpsert_Batch300(b *testing.B) { benchmarkUpsert(b, true) }
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"notion-lite/internal/repository"
+	"notion-lite/internal/utils"
+)
+
+// 搜索模式
+const (
+	HistoryModeKeyword  = "keyword"
+	HistoryModeSemantic = "semantic"
+)
+
+// MaxHistoryEntries 保存的最近搜索条数上限
+const MaxHistoryEntries = 50
+
+// historyMergeWindow 在此时间内逐字输入的查询合并为一条（避免即时搜索记录每次按键）
+const historyMergeWindow = 30 * time.Second
+
+// HistoryEntry 一条搜索记录
+type HistoryEntry struct {
+	Query       string `json:"query"`
+	Mode        string `json:"mode"`        // "keyword" | "semantic"
+	ResultCount int    `json:"resultCount"` // 结果数量
+	Timestamp   int64  `json:"timestamp"`   // 搜索时间（Unix 毫秒）
+}
+
+// History 最近搜索记录（仅保存在本地）
+type History struct {
+	repository.BaseRepository
+	paths *utils.PathBuilder
+	mu    sync.Mutex
+	now   func() time.Time
+}
+
+// NewHistory 创建搜索记录存储
+func NewHistory(paths *utils.PathBuilder) *History {
+	return &History{paths: paths, now: time.Now}
+}
+
+// Record 记录一次搜索，最新的在前
+// 相同查询会移到最前；短时间内逐字输入的查询（前缀关系）合并为最后一次
+func (h *History) Record(query, mode string, resultCount int) error {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries, err := h.load()
+	if err != nil {
+		return err
+	}
+
+	now := h.now()
+	if len(entries) > 0 {
+		latest := entries[0]
+		typing := now.Sub(time.UnixMilli(latest.Timestamp)) < historyMergeWindow &&
+			(strings.HasPrefix(query, latest.Query) || strings.HasPrefix(latest.Query, query))
+		if latest.Mode == mode && typing {
+			entries = entries[1:]
+		}
+	}
+
+	kept := make([]HistoryEntry, 0, len(entries)+1)
+	kept = append(kept, HistoryEntry{
+		Query:       query,
+		Mode:        mode,
+		ResultCount: resultCount,
+		Timestamp:   now.UnixMilli(),
+	})
+	for _, e := range entries {
+		if e.Query == query && e.Mode == mode {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(kept) > MaxHistoryEntries {
+		kept = kept[:MaxHistoryEntries]
+	}
+
+	return h.SaveJSON(h.paths.SearchHistory(), kept)
+}
+
+// Recent 获取最近的搜索记录（limit <= 0 时返回全部）
+func (h *History) Recent(limit int) ([]HistoryEntry, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries, err := h.load()
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Clear 清空搜索记录
+func (h *History) Clear() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.DeleteFile(h.paths.SearchHistory())
+}
+
+// load 读取已保存的记录（文件不存在时为空）
+func (h *History) load() ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+	if err := h.LoadJSON(h.paths.SearchHistory(), &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
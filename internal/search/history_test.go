@@ -0,0 +1,63 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+func TestHistory_Record(t *testing.T) {
+	history := NewHistory(utils.NewPathBuilder(t.TempDir()))
+	now := time.Unix(1700000000, 0)
+	history.now = func() time.Time { return now }
+
+	// 逐字输入合并为一条
+	for _, q := range []string{"向", "向量", "向量检索"} {
+		if err := history.Record(q, HistoryModeKeyword, 1); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+	// 超过合并窗口的新查询单独记录，重复查询移到最前
+	now = now.Add(time.Minute)
+	_ = history.Record("笔记", HistoryModeSemantic, 3)
+	now = now.Add(time.Minute)
+	_ = history.Record("向量检索", HistoryModeKeyword, 2)
+	_ = history.Record("  ", HistoryModeKeyword, 0)
+
+	entries, err := history.Recent(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Query != "向量检索" || entries[0].ResultCount != 2 || entries[1].Query != "笔记" {
+		t.Fatalf("Unexpected history: %+v", entries)
+	}
+
+	if limited, _ := history.Recent(1); len(limited) != 1 {
+		t.Errorf("Expected 1 entry with limit, got %d", len(limited))
+	}
+
+	if err := history.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := history.Recent(0); len(entries) != 0 {
+		t.Errorf("Expected empty history after clear, got %+v", entries)
+	}
+}
+
+func TestHistory_Cap(t *testing.T) {
+	history := NewHistory(utils.NewPathBuilder(t.TempDir()))
+	now := time.Unix(1700000000, 0)
+	history.now = func() time.Time { return now }
+
+	for i := 0; i < MaxHistoryEntries+10; i++ {
+		now = now.Add(time.Minute)
+		_ = history.Record(fmt.Sprintf("query %d", i), HistoryModeKeyword, i)
+	}
+	entries, _ := history.Recent(0)
+	if len(entries) != MaxHistoryEntries {
+		t.Errorf("Expected %d entries, got %d", MaxHistoryEntries, len(entries))
+	}
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// 阅读速度：英文等按词计，中日文按字计
+const (
+	wordsPerMinute    = 200
+	cjkCharsPerMinute = 300
+)
+
+// DocStats 文档统计信息
+type DocStats struct {
+	Words          int            `json:"words"`          // 词数（中日文每个字计一个词）
+	Characters     int            `json:"characters"`     // 字符数（不含空白）
+	CJKCharacters  int            `json:"cjkCharacters"`  // 中日文字符数
+	Blocks         int            `json:"blocks"`         // 块总数（含嵌套块）
+	BlockTypes     map[string]int `json:"blockTypes"`     // 各类型块的数量
+	ReadingMinutes int            `json:"readingMinutes"` // 预计阅读时间（分钟，向上取整）
+}
+
+// ComputeStats 统计文档的字数、字符数、块数与预计阅读时间
+func ComputeStats(jsonContent string) DocStats {
+	stats := DocStats{BlockTypes: map[string]int{}}
+
+	var blocks []statsBlock
+	if err := json.Unmarshal([]byte(jsonContent), &blocks); err == nil {
+		countBlocks(blocks, &stats)
+	}
+	var text strings.Builder
+	writeBlocksText(blocks, &text)
+
+	latinWords := 0
+	inWord := false
+	for _, r := range text.String() {
+		switch {
+		case unicode.IsSpace(r):
+			inWord = false
+			continue
+		case isCJKChar(r):
+			stats.CJKCharacters++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			if !inWord {
+				latinWords++
+				inWord = true
+			}
+		default:
+			// 标点不计入词，但会结束当前词（连字符、撇号除外）
+			if r != '-' && r != '\'' && r != '’' {
+				inWord = false
+			}
+		}
+		stats.Characters++
+	}
+	stats.Words = latinWords + stats.CJKCharacters
+
+	minutes := float64(latinWords)/wordsPerMinute + float64(stats.CJKCharacters)/cjkCharsPerMinute
+	stats.ReadingMinutes = int(math.Ceil(minutes))
+	return stats
+}
+
+// statsBlock 统计用的块结构：content 保留原始 JSON，表格等非数组 content 不会导致整个文档解析失败
+type statsBlock struct {
+	Type     string          `json:"type"`
+	Content  json.RawMessage `json:"content"`
+	Children []statsBlock    `json:"children"`
+}
+
+// statsContent 行内内容或表格节点：文本、链接（content 为行内数组）、表格（rows/cells）、单元格（content）
+type statsContent struct {
+	Text    string          `json:"text"`
+	Content json.RawMessage `json:"content"`
+	Rows    []struct {
+		Cells []json.RawMessage `json:"cells"`
+	} `json:"rows"`
+}
+
+// writeBlocksText 递归提取块的文字，块之间以空格分隔
+func writeBlocksText(blocks []statsBlock, sb *strings.Builder) {
+	for _, block := range blocks {
+		writeContentText(block.Content, sb)
+		sb.WriteByte(' ')
+		writeBlocksText(block.Children, sb)
+	}
+}
+
+// writeContentText 提取 content 中的文字：同一段内相邻的文本片段（如部分加粗）直接拼接，
+// 链接递归提取其文字，表格逐行逐单元格提取（单元格之间以空格分隔）
+func writeContentText(raw json.RawMessage, sb *strings.Builder) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return
+	}
+	switch raw[0] {
+	case '[':
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) == nil {
+			for _, item := range items {
+				writeContentText(item, sb)
+			}
+		}
+	case '{':
+		var node statsContent
+		if json.Unmarshal(raw, &node) != nil {
+			return
+		}
+		sb.WriteString(node.Text)
+		writeContentText(node.Content, sb)
+		for _, row := range node.Rows {
+			for _, cell := range row.Cells {
+				writeContentText(cell, sb)
+				sb.WriteByte(' ')
+			}
+		}
+	}
+}
+
+// countBlocks 递归统计块数量
+func countBlocks(blocks []statsBlock, stats *DocStats) {
+	for _, block := range blocks {
+		stats.Blocks++
+		stats.BlockTypes[block.Type]++
+		countBlocks(block.Children, stats)
+	}
+}
+
+// isCJKChar 判断是否为逐字计数的中日文字符（汉字、假名；韩文以空格分词，按词计）
+func isCJKChar(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)
+}
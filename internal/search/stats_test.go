@@ -0,0 +1,66 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	content := `[
+		{"id": "h1", "type": "heading", "content": [{"type": "text", "text": "Release notes"}]},
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "We shipped the new editor's toolbar."}], "children": [
+			{"id": "p2", "type": "paragraph", "content": [{"type": "text", "text": "中文测试"}]}
+		]},
+		{"id": "t1", "type": "table", "content": {"type": "tableContent", "rows": []}}
+	]`
+
+	stats := ComputeStats(content)
+	if stats.Blocks != 4 || stats.BlockTypes["paragraph"] != 2 || stats.BlockTypes["heading"] != 1 || stats.BlockTypes["table"] != 1 {
+		t.Errorf("Unexpected block counts: %d %v", stats.Blocks, stats.BlockTypes)
+	}
+
+	text := `[
+		{"id": "h1", "type": "heading", "content": [{"type": "text", "text": "Release notes"}]},
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "We shipped the new editor's toolbar."}], "children": [
+			{"id": "p2", "type": "paragraph", "content": [{"type": "text", "text": "中文测试"}]}
+		]}
+	]`
+	stats = ComputeStats(text)
+	// 英文 2 + 6 个词，每个汉字计一个词
+	if stats.Words != 12 || stats.CJKCharacters != 4 {
+		t.Errorf("Words = %d, CJK = %d, want 12 and 4", stats.Words, stats.CJKCharacters)
+	}
+	if want := len([]rune(strings.ReplaceAll("Releasenotes Weshippedtheneweditor'stoolbar. 中文测试", " ", ""))); stats.Characters != want {
+		t.Errorf("Characters = %d, want %d", stats.Characters, want)
+	}
+	if stats.ReadingMinutes != 1 {
+		t.Errorf("ReadingMinutes = %d, want 1", stats.ReadingMinutes)
+	}
+
+	long := `[{"id": "p", "type": "paragraph", "content": [{"type": "text", "text": "` + strings.Repeat("word ", 450) + `"}]}]`
+	if got := ComputeStats(long).ReadingMinutes; got != 3 {
+		t.Errorf("ReadingMinutes for 450 words = %d, want 3", got)
+	}
+	// 表格单元格（新旧两种格式）和链接文字都计入；部分加粗的单词仍是一个词
+	table := `[
+		{"id": "t1", "type": "table", "content": {"type": "tableContent", "rows": [
+			{"cells": [
+				{"type": "tableCell", "content": [{"type": "text", "text": "alpha"}]},
+				[{"type": "text", "text": "beta"}]
+			]},
+			{"cells": [
+				{"type": "tableCell", "content": [{"type": "link", "href": "https://example.com", "content": [{"type": "text", "text": "gamma delta"}]}]},
+				{"type": "tableCell", "content": [{"type": "text", "text": "bo", "styles": {"bold": true}}, {"type": "text", "text": "ld"}]}
+			]}
+		]}},
+		{"id": "p1", "type": "paragraph", "content": [{"type": "text", "text": "see "}, {"type": "link", "href": "https://example.com", "content": [{"type": "text", "text": "the docs"}]}]}
+	]`
+	stats = ComputeStats(table)
+	if stats.Words != 8 || stats.Blocks != 2 {
+		t.Errorf("Words = %d, Blocks = %d for table document, want 8 and 2", stats.Words, stats.Blocks)
+	}
+
+	if empty := ComputeStats("[]"); empty.Words != 0 || empty.ReadingMinutes != 0 || empty.BlockTypes == nil {
+		t.Errorf("Unexpected stats for empty document: %+v", empty)
+	}
+}
@@ -12,6 +12,26 @@ type Settings struct {
 	SidebarWidth int    `json:"sidebarWidth"` // 侧边栏宽度, 0 表示默认值
 	WritingStyle string `json:"writingStyle"` // 写作风格指南
 	FontSize     int    `json:"fontSize"`     // 字体大小缩放百分比, 0 表示默认值 (100%)
+	// 新文档默认内容模板（BlockNote 块数组 JSON，支持 {{date}}、{{title}} 占位符），空表示空白文档
+	DefaultDocTemplate string `json:"defaultDocTemplate"`
+	// 关闭本地搜索记录（最近搜索）
+	DisableSearchHistory bool `json:"disableSearchHistory"`
+	// 每个文档保留的历史版本数，0 表示默认值，负数表示关闭版本历史
+	VersionRetention int `json:"versionRetention"`
+}
+
+// DefaultVersionRetention 每个文档默认保留的历史版本数
+const DefaultVersionRetention = 20
+
+// VersionsToKeep 每个文档应保留的历史版本数（<=0 表示关闭）
+func (s *Settings) VersionsToKeep() int {
+	if s.VersionRetention == 0 {
+		return DefaultVersionRetention
+	}
+	if s.VersionRetention < 0 {
+		return 0
+	}
+	return s.VersionRetention
 }
 
 // Service 设置服务
@@ -303,3 +303,31 @@ func (s *Service) SuggestTags(docId string, limit int) ([]TagSuggestion, error)
 
 	return suggestions, nil
 }
+
+// autoTagMinCount 自动打标签时，标签至少需要出现在多少个相似文档中
+const autoTagMinCount = 2
+
+// GetUntaggedDocuments 获取没有任何标签的文档
+func (s *Service) GetUntaggedDocuments() ([]document.Meta, error) {
+	return s.docRepo.GetUntaggedDocuments()
+}
+
+// AutoTagDocument 根据相似文档的标签自动为文档打标签，返回实际添加的标签
+func (s *Service) AutoTagDocument(docId string, limit int) ([]string, error) {
+	suggestions, err := s.SuggestTags(docId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if suggestion.Count < autoTagMinCount {
+			continue
+		}
+		if err := s.docRepo.AddTag(docId, suggestion.Name); err != nil {
+			return applied, err
+		}
+		applied = append(applied, suggestion.Name)
+	}
+	return applied, nil
+}
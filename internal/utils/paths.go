@@ -2,6 +2,7 @@ package utils
 
 import (
 	"path/filepath"
+	"strconv"
 )
 
 // PathBuilder helps construct filesystem paths for the application
@@ -36,6 +37,11 @@ func (p *PathBuilder) Settings() string {
 	return filepath.Join(p.dataPath, "settings.json")
 }
 
+// SearchHistory returns the path to the recent searches file
+func (p *PathBuilder) SearchHistory() string {
+	return filepath.Join(p.dataPath, "search_history.json")
+}
+
 // TagStore returns the path to the tag store file
 func (p *PathBuilder) TagStore() string {
 	return filepath.Join(p.dataPath, "tags.json")
@@ -56,6 +62,36 @@ func (p *PathBuilder) Document(id string) string {
 	return filepath.Join(p.DocumentsDir(), id+".json")
 }
 
+// TrashDir returns the path to the trash directory for deleted documents
+func (p *PathBuilder) TrashDir() string {
+	return filepath.Join(p.dataPath, "trash")
+}
+
+// TrashDocument returns the path to a deleted document file in the trash
+func (p *PathBuilder) TrashDocument(id string) string {
+	return filepath.Join(p.TrashDir(), id+".json")
+}
+
+// TrashManifest returns the path to the trash manifest file
+func (p *PathBuilder) TrashManifest() string {
+	return filepath.Join(p.TrashDir(), "trash.json")
+}
+
+// VersionsDir returns the path to the document snapshots directory
+func (p *PathBuilder) VersionsDir() string {
+	return filepath.Join(p.dataPath, "versions")
+}
+
+// DocumentVersions returns the path to the snapshots directory of a document
+func (p *PathBuilder) DocumentVersions(id string) string {
+	return filepath.Join(p.VersionsDir(), id)
+}
+
+// DocumentVersion returns the path to a document snapshot taken at the given Unix millisecond timestamp
+func (p *PathBuilder) DocumentVersion(id string, timestamp int64) string {
+	return filepath.Join(p.DocumentVersions(id), strconv.FormatInt(timestamp, 10)+".json")
+}
+
 // File returns the path to a specific external file
 func (p *PathBuilder) File(name string) string {
 	return filepath.Join(p.FilesDir(), name)
@@ -85,3 +121,13 @@ func (p *PathBuilder) RAGDatabase() string {
 func (p *PathBuilder) RAGConfig() string {
 	return filepath.Join(p.dataPath, "rag_config.json")
 }
+
+// ArchiveIndex returns the path to the archived files reference index
+func (p *PathBuilder) ArchiveIndex() string {
+	return filepath.Join(p.FilesDir(), "archive_index.json")
+}
+
+// BackupsDir returns the path to the backups directory
+func (p *PathBuilder) BackupsDir() string {
+	return filepath.Join(p.dataPath, "backups")
+}
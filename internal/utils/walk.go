@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WalkFiles 递归遍历文件夹，收集 match 返回 true 的文件
+// maxDepth 为最大递归深度（0 表示只处理当前目录）；跳过隐藏目录和常见的无关目录，
+// 子目录读取失败时记录日志并继续
+func WalkFiles(dir string, maxDepth int, match func(path string) bool) ([]string, error) {
+	var files []string
+	if err := walkFiles(dir, 0, maxDepth, match, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func walkFiles(dir string, currentDepth, maxDepth int, match func(path string) bool, files *[]string) error {
+	if currentDepth > maxDepth {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		fullPath := filepath.Join(dir, entry.Name())
+
+		if entry.IsDir() {
+			if skipWalkDir(entry.Name()) {
+				continue
+			}
+			// 递归处理子目录
+			if err := walkFiles(fullPath, currentDepth+1, maxDepth, match, files); err != nil {
+				fmt.Printf("⚠️ Failed to walk subdir %s: %v\n", fullPath, err)
+			}
+		} else if match(fullPath) {
+			*files = append(*files, fullPath)
+		}
+	}
+
+	return nil
+}
+
+// skipWalkDir 是否跳过该目录（隐藏目录和常见的依赖/缓存目录）
+func skipWalkDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "__pycache__"
+}
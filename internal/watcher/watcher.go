@@ -37,6 +37,7 @@ type Service struct {
 
 	// 追踪应用自己的写入，避免触发自己的事件
 	recentWrites map[string]time.Time
+	lastSweep    time.Time // 上次清理过期写入记录的时间
 
 	// Callbacks
 	OnDocumentChanged func(event FileChangeEvent)
@@ -59,11 +60,26 @@ func NewService(paths *utils.PathBuilder) (*Service, error) {
 	}, nil
 }
 
-// MarkWrite 标记文件为应用自己写入（供外部调用）
+// MarkWrite 标记文件为应用自己写入（供外部调用，重复标记只刷新时间）
+// 每隔一个忽略窗口顺带清理过期记录，避免标记后再未被检查的路径（如随即删除的文档）一直占用内存
 func (s *Service) MarkWrite(filePath string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.recentWrites[filePath] = time.Now()
+	now := time.Now()
+	s.recentWrites[filePath] = now
+	if now.Sub(s.lastSweep) > s.ignoreWindow {
+		s.sweepRecentWrites(now)
+	}
+}
+
+// sweepRecentWrites 删除超过忽略窗口的写入记录（调用方需持有 mu）
+func (s *Service) sweepRecentWrites(now time.Time) {
+	for path, writeTime := range s.recentWrites {
+		if now.Sub(writeTime) > s.ignoreWindow {
+			delete(s.recentWrites, path)
+		}
+	}
+	s.lastSweep = now
 }
 
 // isRecentWrite 检查文件是否是应用最近写入的
@@ -218,6 +234,7 @@ func (s *Service) flushEvents() {
 		events = append(events, e)
 	}
 	s.pendingEvents = make(map[string]*FileChangeEvent)
+	s.sweepRecentWrites(time.Now())
 	s.mu.Unlock()
 
 	if s.ctx == nil {
@@ -0,0 +1,41 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"notion-lite/internal/utils"
+)
+
+func TestMarkWriteSweepsExpiredEntries(t *testing.T) {
+	s, err := NewService(utils.NewPathBuilder(t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	s.MarkWrite("/data/documents/a.json")
+	s.MarkWrite("/data/documents/a.json") // 重复标记不新增记录
+	if len(s.recentWrites) != 1 {
+		t.Fatalf("Expected 1 entry after marking the same path twice, got %d", len(s.recentWrites))
+	}
+	if !s.isRecentWrite("/data/documents/a.json") {
+		t.Error("Path marked just now should be treated as a recent write")
+	}
+
+	// 模拟从未被再次检查的过期记录
+	expired := time.Now().Add(-2 * s.ignoreWindow)
+	s.mu.Lock()
+	s.recentWrites["/data/documents/a.json"] = expired
+	s.recentWrites["/data/documents/deleted.json"] = expired
+	s.lastSweep = expired
+	s.mu.Unlock()
+
+	s.MarkWrite("/data/documents/b.json")
+	if len(s.recentWrites) != 1 {
+		t.Errorf("Expected expired entries to be swept, got %v", s.recentWrites)
+	}
+	if _, ok := s.recentWrites["/data/documents/b.json"]; !ok {
+		t.Error("Fresh entry should survive the sweep")
+	}
+}
@@ -128,13 +128,25 @@ func main() {
 	FileMenu.AddText(constant.MenuFileOpen, keys.Combo("o", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:open-external")
 	})
+	FileMenu.AddText(constant.MenuFileRestoreDoc, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:restore-document")
+	})
+	FileMenu.AddText(constant.MenuFileEmptyTrash, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:empty-trash")
+	})
 	FileMenu.AddSeparator()
 	FileMenu.AddText(constant.MenuFileImport, keys.CmdOrCtrl("o"), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:import")
 	})
+	FileMenu.AddText(constant.MenuFileImportFolder, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:import-folder")
+	})
 	FileMenu.AddText(constant.MenuFileExport, keys.Combo("e", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:export")
 	})
+	FileMenu.AddText(constant.MenuFileExportVault, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:export-vault")
+	})
 	FileMenu.AddText(constant.MenuFileExportImg, keys.Combo("c", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:copy-image")
 	})
@@ -144,6 +156,12 @@ func main() {
 	FileMenu.AddText(constant.MenuFileExportHTML, keys.Combo("h", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:export-html")
 	})
+	FileMenu.AddText(constant.MenuFileExportPDF, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:export-pdf")
+	})
+	FileMenu.AddText(constant.MenuFileExportSearch, nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.ctx, "menu:export-search-results")
+	})
 	FileMenu.AddSeparator()
 	FileMenu.AddText(constant.MenuFilePrint, keys.CmdOrCtrl("p"), func(_ *menu.CallbackData) {
 		runtime.EventsEmit(app.ctx, "menu:print")